@@ -9,12 +9,19 @@ import (
 	"getsturdy.com/api/pkg/api"
 	apiModule "getsturdy.com/api/pkg/api/module"
 	"getsturdy.com/api/pkg/banner"
+	"getsturdy.com/api/pkg/ci/runner"
 	xcontext "getsturdy.com/api/pkg/context"
 	"getsturdy.com/api/pkg/db/migrate"
 	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/gitserver"
 )
 
 func main() {
+	// the api is the init process of the sandboxes of ci steps
+	runner.SandboxMain()
+	// and it checks the files changed by pushes in the pre-receive hook of the gitserver
+	gitserver.HookMain()
+
 	// run migrations
 	var migrateService *migrate.Service
 	if err := di.Init(migrate.Module).To(&migrateService); err != nil {
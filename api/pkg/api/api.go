@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 
+	worker_changes "getsturdy.com/api/pkg/changes/worker"
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
 	worker_gc "getsturdy.com/api/pkg/gc/worker"
 	"getsturdy.com/api/pkg/gitserver"
 	httpx "getsturdy.com/api/pkg/http"
+	worker_webhook "getsturdy.com/api/pkg/integrations/webhook/worker"
+	worker_ldap "getsturdy.com/api/pkg/ldap/worker"
 	"getsturdy.com/api/pkg/metrics"
 	"getsturdy.com/api/pkg/pprof"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
@@ -24,6 +27,9 @@ type API struct {
 	snapshotterQueue worker_snapshots.Queue
 	ciBuildQueue     *workers_ci.BuildQueue
 	gcQueue          *worker_gc.Queue
+	ldapWorker       *worker_ldap.Worker
+	webhookWorker    *worker_webhook.Worker
+	changesWorker    *worker_changes.Worker
 	gitsrv           *gitserver.Server
 	pprof            *pprof.Server
 	metrics          *metrics.Server
@@ -34,6 +40,9 @@ func ProvideAPI(
 	snapshotterQueue worker_snapshots.Queue,
 	ciBuildQueue *workers_ci.BuildQueue,
 	gcQueue *worker_gc.Queue,
+	ldapWorker *worker_ldap.Worker,
+	webhookWorker *worker_webhook.Worker,
+	changesWorker *worker_changes.Worker,
 	gitsrv *gitserver.Server,
 	pprof *pprof.Server,
 	metrics *metrics.Server,
@@ -43,6 +52,9 @@ func ProvideAPI(
 		snapshotterQueue: snapshotterQueue,
 		ciBuildQueue:     ciBuildQueue,
 		gcQueue:          gcQueue,
+		ldapWorker:       ldapWorker,
+		webhookWorker:    webhookWorker,
+		changesWorker:    changesWorker,
 		gitsrv:           gitsrv,
 		pprof:            pprof,
 		metrics:          metrics,
@@ -72,7 +84,27 @@ func (a *API) Start(ctx context.Context) error {
 		}
 		return nil
 	})
+	// ldap group sync
+	wg.Go(func() error {
+		if err := a.ldapWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start ldap worker: %w", err)
+		}
+		return nil
+	})
+	// ci webhook deliveries
+	wg.Go(func() error {
+		if err := a.webhookWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start webhook worker: %w", err)
+		}
+		return nil
+	})
 	// Start the git HTTP server
+	wg.Go(func() error {
+		if err := a.changesWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start changes worker: %w", err)
+		}
+		return nil
+	})
 	wg.Go(func() error {
 		if err := a.gitsrv.Start(); err != nil {
 			return fmt.Errorf("failed to start git server: %w", err)
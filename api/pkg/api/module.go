@@ -1,11 +1,14 @@
 package api
 
 import (
+	worker_changes "getsturdy.com/api/pkg/changes/worker"
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
 	"getsturdy.com/api/pkg/di"
 	worker_gc "getsturdy.com/api/pkg/gc/worker"
 	"getsturdy.com/api/pkg/gitserver"
 	"getsturdy.com/api/pkg/http"
+	worker_webhook "getsturdy.com/api/pkg/integrations/webhook/worker"
+	worker_ldap "getsturdy.com/api/pkg/ldap/worker"
 	"getsturdy.com/api/pkg/metrics"
 	"getsturdy.com/api/pkg/pprof"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
@@ -16,6 +19,9 @@ func Module(c *di.Container) {
 	c.Import(worker_snapshots.Module)
 	c.Import(workers_ci.Module)
 	c.Import(worker_gc.Module)
+	c.Import(worker_ldap.Module)
+	c.Import(worker_webhook.Module)
+	c.Import(worker_changes.Module)
 	c.Import(gitserver.Module)
 	c.Import(pprof.Module)
 	c.Import(metrics.Module)
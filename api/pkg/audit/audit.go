@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+)
+
+type Action string
+
+const (
+	ActionACLUpdated                    Action = "acl.updated"
+	ActionOrganizationMemberAdded       Action = "organization.member_added"
+	ActionOrganizationMemberRemoved     Action = "organization.member_removed"
+	ActionOrganizationMemberRoleChanged Action = "organization.member_role_changed"
+	ActionOrganizationTwoFactorChanged  Action = "organization.two_factor_requirement_changed"
+	ActionCodebaseMemberAdded           Action = "codebase.member_added"
+	ActionCodebaseMemberRemoved         Action = "codebase.member_removed"
+	ActionChangeLanded                  Action = "change.landed"
+	ActionServiceTokenCreated           Action = "service_token.created"
+	ActionRemoteUpdated                 Action = "remote.updated"
+	ActionScimTokenCreated              Action = "scim_token.created"
+	ActionScimTokenDeleted              Action = "scim_token.deleted"
+)
+
+type TargetType string
+
+const (
+	TargetACL          TargetType = "acl"
+	TargetOrganization TargetType = "organization"
+	TargetUser         TargetType = "user"
+	TargetChange       TargetType = "change"
+	TargetServiceToken TargetType = "service_token"
+	TargetRemote       TargetType = "remote"
+	TargetScimToken    TargetType = "scim_token"
+)
+
+// Entry is a record of a security relevant action. Entries are never changed or deleted once written.
+type Entry struct {
+	ID             string        `db:"id" json:"id"`
+	OrganizationID *string       `db:"organization_id" json:"organization_id,omitempty"`
+	CodebaseID     *codebases.ID `db:"codebase_id" json:"codebase_id,omitempty"`
+	// ActorType and ActorID are the subject that performed the action. If not set when recording, they are taken
+	// from the context.
+	ActorType  auth.SubjectType `db:"actor_type" json:"actor_type"`
+	ActorID    *string          `db:"actor_id" json:"actor_id,omitempty"`
+	IP         *string          `db:"ip" json:"ip,omitempty"`
+	Action     Action           `db:"action" json:"action"`
+	TargetType TargetType       `db:"target_type" json:"target_type"`
+	TargetID   string           `db:"target_id" json:"target_id"`
+	// Before and After describe the target before and after the action.
+	Before    Metadata  `db:"before" json:"before,omitempty"`
+	After     Metadata  `db:"after" json:"after,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Metadata is stored as JSON.
+type Metadata map[string]any
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+func (m *Metadata) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, m)
+	case string:
+		return json.Unmarshal([]byte(src), m)
+	default:
+		return fmt.Errorf("unsupported metadata type %T", src)
+	}
+}
+
+// Filter limits the entries that are listed. Empty fields are not filtered on.
+type Filter struct {
+	OrganizationID *string
+	CodebaseID     *codebases.ID
+	ActorID        *string
+	Actions        []Action
+	// Since and Until limit entries to the ones created in [Since, Until).
+	Since *time.Time
+	Until *time.Time
+	// Before only matches entries that are listed after the given entry, it's used to page through the log.
+	Before *Entry
+	Limit  int
+}
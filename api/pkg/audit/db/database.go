@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{db: db}
+}
+
+func (d *database) Create(ctx context.Context, entry *audit.Entry) error {
+	if _, err := d.db.NamedExecContext(ctx, `INSERT INTO audit_log
+		(id, organization_id, codebase_id, actor_type, actor_id, ip, action, target_type, target_id, before, after, created_at)
+		VALUES
+		(:id, :organization_id, :codebase_id, :actor_type, :actor_id, :ip, :action, :target_type, :target_id, :before, :after, :created_at)`, entry); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	actions := make([]string, 0, len(filter.Actions))
+	for _, action := range filter.Actions {
+		actions = append(actions, string(action))
+	}
+
+	var beforeCreatedAt *time.Time
+	var beforeID *string
+	if filter.Before != nil {
+		beforeCreatedAt = &filter.Before.CreatedAt
+		beforeID = &filter.Before.ID
+	}
+
+	var entries []*audit.Entry
+	if err := d.db.SelectContext(ctx, &entries, `SELECT id, organization_id, codebase_id, actor_type, actor_id, ip, action, target_type, target_id, before, after, created_at
+		FROM audit_log
+		WHERE ($1::TEXT IS NULL OR organization_id = $1)
+		  AND ($2::TEXT IS NULL OR codebase_id = $2)
+		  AND ($3::TEXT IS NULL OR actor_id = $3)
+		  AND (CARDINALITY($4::TEXT[]) = 0 OR action = ANY($4))
+		  AND ($5::TIMESTAMPTZ IS NULL OR created_at >= $5)
+		  AND ($6::TIMESTAMPTZ IS NULL OR created_at < $6)
+		  AND ($7::TIMESTAMPTZ IS NULL OR (created_at, id) < ($7, $8))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $9`,
+		filter.OrganizationID,
+		filter.CodebaseID,
+		filter.ActorID,
+		pq.StringArray(actions),
+		filter.Since,
+		filter.Until,
+		beforeCreatedAt,
+		beforeID,
+		filter.Limit,
+	); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return entries, nil
+}
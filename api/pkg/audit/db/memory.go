@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/audit"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	mu      sync.RWMutex
+	entries []audit.Entry
+}
+
+func NewMemory() Repository {
+	return &memory{}
+}
+
+func (m *memory) Create(_ context.Context, entry *audit.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *memory) List(_ context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []*audit.Entry
+	for _, entry := range m.entries {
+		if matches(&entry, filter) {
+			entry := entry
+			entries = append(entries, &entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return isBefore(entries[j], entries[i])
+	})
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+func matches(entry *audit.Entry, filter audit.Filter) bool {
+	if filter.OrganizationID != nil && (entry.OrganizationID == nil || *entry.OrganizationID != *filter.OrganizationID) {
+		return false
+	}
+	if filter.CodebaseID != nil && (entry.CodebaseID == nil || *entry.CodebaseID != *filter.CodebaseID) {
+		return false
+	}
+	if filter.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *filter.ActorID) {
+		return false
+	}
+	if len(filter.Actions) > 0 {
+		found := false
+		for _, action := range filter.Actions {
+			if entry.Action == action {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.Since != nil && entry.CreatedAt.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !entry.CreatedAt.Before(*filter.Until) {
+		return false
+	}
+	if filter.Before != nil && !isBefore(entry, filter.Before) {
+		return false
+	}
+	return true
+}
+
+// isBefore returns true if a was created before b, entries created at the same time are ordered by id.
+func isBefore(a, b *audit.Entry) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID < b.ID
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/audit"
+)
+
+// Repository is append-only, entries can not be updated or deleted.
+type Repository interface {
+	Create(context.Context, *audit.Entry) error
+	// List returns entries that match the filter, newest first.
+	List(context.Context, audit.Filter) ([]*audit.Entry, error)
+}
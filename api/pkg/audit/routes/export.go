@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Export streams the audit log of an organization as JSON lines, newest entry first. Only admins of the organization
+// can export the log.
+//
+// The entries can be filtered with the query parameters codebase_id, actor_id, action (can be repeated), since and
+// until. since and until are unix timestamps.
+func Export(
+	logger *zap.Logger,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+	auditService *service_audit.Service,
+) func(c *gin.Context) {
+	logger = logger.Named("auditExport")
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		org, err := organizationService.GetByID(ctx, c.Param("id"))
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		} else if err != nil {
+			logger.Error("failed to get organization", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := authService.CanWrite(ctx, org); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		filter, err := parseFilter(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.OrganizationID = &org.ID
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "audit-log-"+string(org.ShortID)+".jsonl"))
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		if err := auditService.Export(ctx, filter, func(entry *audit.Entry) error {
+			return encoder.Encode(entry)
+		}); err != nil {
+			// the status has already been written, all we can do is to stop writing
+			logger.Error("failed to export audit log", zap.Error(err))
+		}
+	}
+}
+
+func parseFilter(c *gin.Context) (audit.Filter, error) {
+	var filter audit.Filter
+	if codebaseID := c.Query("codebase_id"); codebaseID != "" {
+		id := codebases.ID(codebaseID)
+		filter.CodebaseID = &id
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		filter.ActorID = &actorID
+	}
+	for _, action := range c.QueryArray("action") {
+		filter.Actions = append(filter.Actions, audit.Action(action))
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := parseUnix(since)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since: %w", err)
+		}
+		filter.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := parseUnix(until)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until: %w", err)
+		}
+		filter.Until = &t
+	}
+	return filter, nil
+}
+
+func parseUnix(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
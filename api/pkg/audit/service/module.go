@@ -0,0 +1,13 @@
+package service
+
+import (
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Import(db_audit.Module)
+	c.Import(db_codebases.Module)
+	c.Register(New)
+}
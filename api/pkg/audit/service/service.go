@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	"getsturdy.com/api/pkg/auth"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/ip"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Service struct {
+	repo         db_audit.Repository
+	codebaseRepo db_codebases.CodebaseRepository
+}
+
+func New(
+	repo db_audit.Repository,
+	codebaseRepo db_codebases.CodebaseRepository,
+) *Service {
+	return &Service{
+		repo:         repo,
+		codebaseRepo: codebaseRepo,
+	}
+}
+
+// Record writes the entry to the audit log. The actor and ip address of the entry are taken from the context, unless
+// the actor is already set. Entries about a codebase are recorded in the organization of the codebase.
+//
+// Entries are recorded after the action has been done, callers log failures instead of failing the action.
+func (s *Service) Record(ctx context.Context, entry *audit.Entry) error {
+	entry.ID = uuid.NewString()
+	entry.CreatedAt = time.Now()
+
+	if entry.CodebaseID != nil && entry.OrganizationID == nil {
+		codebase, err := s.codebaseRepo.GetAllowArchived(*entry.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get codebase: %w", err)
+		}
+		entry.OrganizationID = codebase.OrganizationID
+	}
+
+	if entry.ActorID == nil && entry.ActorType == auth.SubjectUndefined {
+		if subject, ok := auth.FromContext(ctx); ok {
+			entry.ActorType = subject.Type
+			if subject.ID != "" {
+				actorID := subject.ID
+				entry.ActorID = &actorID
+			}
+		}
+	}
+
+	if remoteIP, ok := ip.FromContext(ctx); ok && remoteIP != nil && *remoteIP != nil {
+		ipAddress := remoteIP.String()
+		entry.IP = &ipAddress
+	}
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit log entry: %w", err)
+	}
+	return nil
+}
+
+// List returns entries matching the filter, newest first. At most 1000 entries are returned, 100 if the filter
+// doesn't have a limit.
+func (s *Service) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultLimit
+	case filter.Limit > maxLimit:
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Export calls fn with every entry matching the filter, newest first. The limit of the filter is ignored.
+func (s *Service) Export(ctx context.Context, filter audit.Filter, fn func(*audit.Entry) error) error {
+	filter.Limit = maxLimit
+	for {
+		entries, err := s.repo.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < filter.Limit {
+			return nil
+		}
+		filter.Before = entries[len(entries)-1]
+	}
+}
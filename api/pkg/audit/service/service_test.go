@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/ip"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	codebaseRepo := db_codebases.NewMemory()
+	svc := service_audit.New(db_audit.NewMemory(), codebaseRepo)
+
+	orgID := uuid.NewString()
+	codebaseID := codebases.ID(uuid.NewString())
+	require.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: codebaseID, OrganizationID: &orgID}))
+
+	userID := users.ID(uuid.NewString())
+	ctx := auth.NewUserContext(context.Background(), userID)
+	ctx = ip.NewContext(ctx, net.ParseIP("10.0.0.1"))
+
+	entry := &audit.Entry{
+		CodebaseID: &codebaseID,
+		Action:     audit.ActionACLUpdated,
+		TargetType: audit.TargetACL,
+		TargetID:   "acl",
+		After:      audit.Metadata{"policy": "{}"},
+	}
+	require.NoError(t, svc.Record(ctx, entry))
+
+	entries, err := svc.List(context.Background(), audit.Filter{OrganizationID: &orgID})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	got := entries[0]
+	assert.NotEmpty(t, got.ID)
+	assert.False(t, got.CreatedAt.IsZero())
+	assert.Equal(t, auth.SubjectUser, got.ActorType)
+	if assert.NotNil(t, got.ActorID) {
+		assert.Equal(t, userID.String(), *got.ActorID)
+	}
+	if assert.NotNil(t, got.IP) {
+		assert.Equal(t, "10.0.0.1", *got.IP)
+	}
+	if assert.NotNil(t, got.OrganizationID) {
+		assert.Equal(t, orgID, *got.OrganizationID)
+	}
+	assert.Equal(t, audit.Metadata{"policy": "{}"}, got.After)
+}
+
+func TestRecord_explicitActor(t *testing.T) {
+	svc := service_audit.New(db_audit.NewMemory(), nil)
+
+	orgID := uuid.NewString()
+	actorID := uuid.NewString()
+	ctx := auth.NewUserContext(context.Background(), users.ID(uuid.NewString()))
+
+	require.NoError(t, svc.Record(ctx, &audit.Entry{
+		OrganizationID: &orgID,
+		ActorType:      auth.SubjectUser,
+		ActorID:        &actorID,
+		Action:         audit.ActionOrganizationMemberAdded,
+		TargetType:     audit.TargetUser,
+		TargetID:       uuid.NewString(),
+	}))
+
+	entries, err := svc.List(context.Background(), audit.Filter{ActorID: &actorID})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Nil(t, entries[0].IP)
+}
+
+func TestList_filters(t *testing.T) {
+	svc := service_audit.New(db_audit.NewMemory(), nil)
+	ctx := context.Background()
+
+	orgID, otherOrgID := uuid.NewString(), uuid.NewString()
+	record := func(orgID string, action audit.Action) {
+		require.NoError(t, svc.Record(ctx, &audit.Entry{
+			OrganizationID: &orgID,
+			Action:         action,
+			TargetType:     audit.TargetUser,
+			TargetID:       uuid.NewString(),
+		}))
+	}
+	record(orgID, audit.ActionOrganizationMemberAdded)
+	record(orgID, audit.ActionOrganizationMemberRemoved)
+	record(orgID, audit.ActionScimTokenCreated)
+	record(otherOrgID, audit.ActionOrganizationMemberAdded)
+
+	entries, err := svc.List(ctx, audit.Filter{OrganizationID: &orgID})
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].CreatedAt.After(entries[i-1].CreatedAt), "entries are not listed newest first")
+	}
+
+	entries, err = svc.List(ctx, audit.Filter{
+		OrganizationID: &orgID,
+		Actions:        []audit.Action{audit.ActionOrganizationMemberAdded, audit.ActionOrganizationMemberRemoved},
+	})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = svc.List(ctx, audit.Filter{OrganizationID: &orgID, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestExport(t *testing.T) {
+	svc := service_audit.New(db_audit.NewMemory(), nil)
+	ctx := context.Background()
+
+	orgID := uuid.NewString()
+	total := 2500
+	for i := 0; i < total; i++ {
+		require.NoError(t, svc.Record(ctx, &audit.Entry{
+			OrganizationID: &orgID,
+			Action:         audit.ActionChangeLanded,
+			TargetType:     audit.TargetChange,
+			TargetID:       uuid.NewString(),
+		}))
+	}
+
+	seen := make(map[string]bool)
+	var previous *audit.Entry
+	err := svc.Export(ctx, audit.Filter{OrganizationID: &orgID, Limit: 10}, func(entry *audit.Entry) error {
+		assert.False(t, seen[entry.ID], "entry exported twice")
+		seen[entry.ID] = true
+		if previous != nil {
+			assert.False(t, entry.CreatedAt.After(previous.CreatedAt), "entries are not exported newest first")
+		}
+		previous = entry
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, seen, total)
+}
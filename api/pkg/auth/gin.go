@@ -18,8 +18,24 @@ const (
 	ginContextKey = "auth.subject"
 )
 
-func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service) gin.HandlerFunc {
+func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service, tokenAuthenticator TokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if subject, found, err := subjectFromPersonalAccessToken(c.Request, tokenAuthenticator); found {
+			if err != nil && !errors.Is(err, ErrUnauthenticated) {
+				ctxlog.ErrorOrWarn(logger, "failed to authenticate personal access token", err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			if subject == nil {
+				subject = &Subject{Type: SubjectAnonymous}
+			}
+
+			setSubject(c, subject)
+			c.Next()
+			return
+		}
+
 		token, shouldRefresh, err := jwtFromRequest(c.Request, jwtService)
 		if err != nil && !errors.Is(err, ErrUnauthenticated) {
 			ctxlog.ErrorOrWarn(logger, "failed to authenticate user", err)
@@ -33,22 +49,23 @@ func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service) gin.Hand
 			}
 		}
 
-		subject := subjectFromToken(token)
-
-		c.Set(ginContextKey, subject)
-		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), subject))
-
+		setSubject(c, subjectFromToken(token))
 		c.Next()
 	}
 }
 
+func setSubject(c *gin.Context, subject *Subject) {
+	c.Set(ginContextKey, subject)
+	c.Request = c.Request.WithContext(NewContext(c.Request.Context(), subject))
+}
+
 func refreshToken(c *gin.Context, token *jwt.Token, jwtService *service_jwt.Service) error {
 	token, err := jwtService.IssueToken(c.Request.Context(), token.Subject, oneMonth, token.Type)
 	if err != nil {
 		return fmt.Errorf("failed to issue new token: %w", err)
 	}
 
-	isSecure := c.Request.URL.Scheme == "https"
+	isSecure := c.Request.TLS != nil
 	setAuthCookie(c.Writer, isSecure, token.Token)
 	return nil
 }
@@ -59,7 +76,7 @@ func SetAuthCookieForUser(c *gin.Context, userID users.ID, jwtService *service_j
 		return fmt.Errorf("failed to issue new token: %w", err)
 	}
 
-	isSecure := c.Request.URL.Scheme == "https"
+	isSecure := c.Request.TLS != nil
 	setAuthCookie(c.Writer, isSecure, token.Token)
 	return nil
 }
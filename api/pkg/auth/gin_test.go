@@ -3,6 +3,7 @@ package auth_test
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/jwt"
 	db_jwt_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_jwt_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 
 	"github.com/gin-gonic/gin"
@@ -25,13 +27,13 @@ var (
 )
 
 func TestGinMiddleware__shouldAllowCIAuthInHeader(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeCI)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -58,13 +60,13 @@ func TestGinMiddleware__shouldAllowCIAuthInHeader(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldAllowUserAuthInHeader(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -91,13 +93,13 @@ func TestGinMiddleware__shouldAllowUserAuthInHeader(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldNotRefreshExpiringHeaderToken(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -124,13 +126,13 @@ func TestGinMiddleware__shouldNotRefreshExpiringHeaderToken(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldRefreshExpiringCookie(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -163,14 +165,46 @@ func TestGinMiddleware__shouldRefreshExpiringCookie(t *testing.T) {
 	}
 }
 
+func TestSetAuthCookieForUser__shouldBeSecureOverTLS(t *testing.T) {
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+
+	router := gin.New()
+	router.GET("/login", func(c *gin.Context) {
+		assert.NoError(t, auth.SetAuthCookieForUser(c, "id", jwtTokenService))
+	})
+
+	for _, tc := range []struct {
+		name   string
+		tls    *tls.ConnectionState
+		secure bool
+	}{
+		{name: "http", tls: nil, secure: false},
+		{name: "https", tls: &tls.ConnectionState{}, secure: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/login", nil)
+			assert.NoError(t, err)
+			req.TLS = tc.tls
+
+			router.ServeHTTP(w, req)
+
+			if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+				assert.Equal(t, "auth", cookies[0].Name)
+				assert.Equal(t, tc.secure, cookies[0].Secure)
+			}
+		})
+	}
+}
+
 func TestGinMiddleware__shouldAllowUserAuthInCookie(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -200,10 +234,10 @@ func TestGinMiddleware__shouldAllowUserAuthInCookie(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldAllowNoAuth(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, nil))
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
@@ -218,3 +252,47 @@ func TestGinMiddleware__shouldAllowNoAuth(t *testing.T) {
 	assert.Len(t, w.Result().Cookies(), 0)
 	assert.Equal(t, "pong", w.Body.String())
 }
+
+type tokenAuthenticatorFunc func(context.Context, string) (*auth.Subject, error)
+
+func (f tokenAuthenticatorFunc) Authenticate(ctx context.Context, token string) (*auth.Subject, error) {
+	return f(ctx, token)
+}
+
+func TestGinMiddleware__shouldAllowPersonalAccessTokenInHeader(t *testing.T) {
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+
+	tokenAuthenticator := tokenAuthenticatorFunc(func(_ context.Context, token string) (*auth.Subject, error) {
+		if token != auth.PersonalAccessTokenPrefix+"valid" {
+			return nil, auth.ErrUnauthenticated
+		}
+		return &auth.Subject{ID: "id", Type: auth.SubjectUser, Scopes: []auth.Scope{auth.ScopeReadCodebase}}, nil
+	})
+
+	router := gin.New()
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, tokenAuthenticator))
+	router.GET("/ping", func(c *gin.Context) {
+		subject, found := auth.FromContext(c.Request.Context())
+		if assert.True(t, found) {
+			c.String(http.StatusOK, "%s %s %v", subject.Type, subject.ID, subject.Scopes)
+		}
+	})
+
+	for _, tc := range []struct {
+		token    string
+		expected string
+	}{
+		{token: auth.PersonalAccessTokenPrefix + "valid", expected: "user id [read_codebase]"},
+		{token: auth.PersonalAccessTokenPrefix + "invalid", expected: "anonymous  []"},
+	} {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/ping", nil)
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", fmt.Sprintf("bearer %s", tc.token))
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tc.expected, w.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -40,7 +41,24 @@ var (
 	})
 )
 
-func SubjectFromRequest(r *http.Request, jwtService *service_jwt.Service) (*Subject, error) {
+// PersonalAccessTokenPrefix is the prefix of all personal access tokens, it's used to tell them apart from JWTs.
+const PersonalAccessTokenPrefix = "sturdy_pat_"
+
+// TokenAuthenticator authenticates personal access tokens. If the token is not valid, an error wrapping
+// ErrUnauthenticated is returned.
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*Subject, error)
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+func SubjectFromRequest(r *http.Request, jwtService *service_jwt.Service, tokenAuthenticator TokenAuthenticator) (*Subject, error) {
+	if subject, found, err := subjectFromPersonalAccessToken(r, tokenAuthenticator); found {
+		return subject, err
+	}
+
 	jwt, _, err := jwtFromRequest(r, jwtService)
 	if err != nil {
 		return nil, err
@@ -69,6 +87,27 @@ func jwtFromRequest(r *http.Request, jwtService *service_jwt.Service) (*jwt.Toke
 	return jwtToken, shouldRefresh, nil
 }
 
+// subjectFromPersonalAccessToken authenticates the personal access token from the request headers. If the request
+// doesn't have a personal access token, found is false.
+func subjectFromPersonalAccessToken(r *http.Request, tokenAuthenticator TokenAuthenticator) (*Subject, bool, error) {
+	if tokenAuthenticator == nil {
+		return nil, false, nil
+	}
+
+	token, fromHeader := tokenFromHeaders(r.Header)
+	if !fromHeader || !IsPersonalAccessToken(token) {
+		return nil, false, nil
+	}
+
+	subject, err := tokenAuthenticator.Authenticate(r.Context(), token)
+	if errors.Is(err, ErrUnauthenticated) {
+		return nil, true, ErrUnauthenticated
+	} else if err != nil {
+		return nil, true, fmt.Errorf("failed to authenticate token: %w", err)
+	}
+	return subject, true, nil
+}
+
 var (
 	oneDay           = time.Hour * 24
 	oneMonth         = 30 * oneDay
@@ -0,0 +1,55 @@
+package auth
+
+type Scope string
+
+const (
+	// ScopeReadCodebase allows reading codebases, workspaces and changes.
+	ScopeReadCodebase Scope = "read_codebase"
+	// ScopeWriteWorkspace allows creating and changing workspaces, and everything ScopeReadCodebase allows.
+	ScopeWriteWorkspace Scope = "write_workspace"
+	// ScopeLand allows landing changes, and everything ScopeReadCodebase allows.
+	ScopeLand Scope = "land"
+	// ScopeAdmin allows everything, including administrating organizations and access tokens.
+	ScopeAdmin Scope = "admin"
+)
+
+var scopes = map[Scope]bool{
+	ScopeReadCodebase:   true,
+	ScopeWriteWorkspace: true,
+	ScopeLand:           true,
+	ScopeAdmin:          true,
+}
+
+func (s Scope) String() string {
+	return string(s)
+}
+
+func (s Scope) Valid() bool {
+	return scopes[s]
+}
+
+// includes returns true if the scope grants everything that the other scope grants.
+func (s Scope) includes(other Scope) bool {
+	switch s {
+	case ScopeAdmin:
+		return true
+	case ScopeWriteWorkspace, ScopeLand:
+		return other == s || other == ScopeReadCodebase
+	default:
+		return other == s
+	}
+}
+
+// HasScope returns true if the subject has been granted the scope. Subjects that are not restricted to any
+// scopes, such as users logged in with a session, have all scopes.
+func (s *Subject) HasScope(scope Scope) bool {
+	if s.Scopes == nil {
+		return true
+	}
+	for _, granted := range s.Scopes {
+		if granted.includes(scope) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubject_HasScope(t *testing.T) {
+	unrestricted := &auth.Subject{Type: auth.SubjectUser}
+	for _, scope := range []auth.Scope{auth.ScopeReadCodebase, auth.ScopeWriteWorkspace, auth.ScopeLand, auth.ScopeAdmin} {
+		assert.True(t, unrestricted.HasScope(scope))
+	}
+
+	cases := []struct {
+		granted  []auth.Scope
+		scope    auth.Scope
+		expected bool
+	}{
+		{granted: []auth.Scope{}, scope: auth.ScopeReadCodebase, expected: false},
+		{granted: []auth.Scope{auth.ScopeReadCodebase}, scope: auth.ScopeReadCodebase, expected: true},
+		{granted: []auth.Scope{auth.ScopeReadCodebase}, scope: auth.ScopeWriteWorkspace, expected: false},
+		{granted: []auth.Scope{auth.ScopeWriteWorkspace}, scope: auth.ScopeReadCodebase, expected: true},
+		{granted: []auth.Scope{auth.ScopeWriteWorkspace}, scope: auth.ScopeLand, expected: false},
+		{granted: []auth.Scope{auth.ScopeLand}, scope: auth.ScopeReadCodebase, expected: true},
+		{granted: []auth.Scope{auth.ScopeLand}, scope: auth.ScopeAdmin, expected: false},
+		{granted: []auth.Scope{auth.ScopeReadCodebase, auth.ScopeLand}, scope: auth.ScopeLand, expected: true},
+		{granted: []auth.Scope{auth.ScopeAdmin}, scope: auth.ScopeLand, expected: true},
+	}
+
+	for _, tc := range cases {
+		subject := &auth.Subject{Type: auth.SubjectUser, Scopes: tc.granted}
+		assert.Equal(t, tc.expected, subject.HasScope(tc.scope), "%v has %s", tc.granted, tc.scope)
+	}
+}
+
+func TestUnscopedUserID(t *testing.T) {
+	ctx := auth.NewUserContext(context.Background(), "user-id")
+	userID, err := auth.UnscopedUserID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, users.ID("user-id"), userID)
+
+	ctx = auth.NewContext(context.Background(), &auth.Subject{ID: "user-id", Type: auth.SubjectUser, Scopes: []auth.Scope{auth.ScopeAdmin}})
+	_, err = auth.UnscopedUserID(ctx)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = auth.UnscopedUserID(context.Background())
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestAdminUserID(t *testing.T) {
+	ctx := auth.NewUserContext(context.Background(), "user-id")
+	userID, err := auth.AdminUserID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, users.ID("user-id"), userID)
+
+	ctx = auth.NewContext(context.Background(), &auth.Subject{ID: "user-id", Type: auth.SubjectUser, Scopes: []auth.Scope{auth.ScopeAdmin}})
+	userID, err = auth.AdminUserID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, users.ID("user-id"), userID)
+
+	ctx = auth.NewContext(context.Background(), &auth.Subject{ID: "user-id", Type: auth.SubjectUser, Scopes: []auth.Scope{auth.ScopeWriteWorkspace}})
+	_, err = auth.AdminUserID(ctx)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = auth.AdminUserID(context.Background())
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
@@ -21,7 +21,17 @@ var (
 	noneAllowed, _ = unidiff.NewAllower()
 )
 
+// GetAllower returns the files that the subject can read in the given object.
 func (s *Service) GetAllower(ctx context.Context, obj any) (*unidiff.Allower, error) {
+	return s.getAllower(ctx, acl.ActionRead, obj)
+}
+
+// GetWriteAllower returns the files that the subject can write to in the given object.
+func (s *Service) GetWriteAllower(ctx context.Context, obj any) (*unidiff.Allower, error) {
+	return s.getAllower(ctx, acl.ActionWrite, obj)
+}
+
+func (s *Service) getAllower(ctx context.Context, action acl.Action, obj any) (*unidiff.Allower, error) {
 	if obj == nil {
 		return noneAllowed, nil
 	}
@@ -37,30 +47,30 @@ func (s *Service) GetAllower(ctx context.Context, obj any) (*unidiff.Allower, er
 		// TODO: mutagen request should be authenticated
 		switch object := obj.(type) {
 		case *codebases.Codebase:
-			return s.getUserCodebaseAllower(ctx, subjectID, object)
+			return s.getUserCodebaseAllower(ctx, action, subjectID, object)
 		case codebases.Codebase:
-			return s.getUserCodebaseAllower(ctx, subjectID, &object)
+			return s.getUserCodebaseAllower(ctx, action, subjectID, &object)
 		}
 
 	case auth.SubjectUser:
 		subjectID := users.ID(subject.ID)
 		switch object := obj.(type) {
 		case *codebases.Codebase:
-			return s.getUserCodebaseAllower(ctx, subjectID, object)
+			return s.getUserCodebaseAllower(ctx, action, subjectID, object)
 		case codebases.Codebase:
-			return s.getUserCodebaseAllower(ctx, subjectID, &object)
+			return s.getUserCodebaseAllower(ctx, action, subjectID, &object)
 		case changes.Change:
-			return s.getUserChangeAllower(ctx, subjectID, &object)
+			return s.getUserChangeAllower(ctx, action, subjectID, &object)
 		case *changes.Change:
-			return s.getUserChangeAllower(ctx, subjectID, object)
+			return s.getUserChangeAllower(ctx, action, subjectID, object)
 		case workspaces.Workspace:
-			return s.getUserWorkspaceAllower(ctx, subjectID, &object)
+			return s.getUserWorkspaceAllower(ctx, action, subjectID, &object)
 		case *workspaces.Workspace:
-			return s.getUserWorkspaceAllower(ctx, subjectID, object)
+			return s.getUserWorkspaceAllower(ctx, action, subjectID, object)
 		case suggestions.Suggestion:
-			return s.getUserSuggestionAllower(ctx, subjectID, &object)
+			return s.getUserSuggestionAllower(ctx, action, subjectID, &object)
 		case *suggestions.Suggestion:
-			return s.getUserSuggestionAllower(ctx, subjectID, object)
+			return s.getUserSuggestionAllower(ctx, action, subjectID, object)
 		}
 
 	case auth.SubjectCI:
@@ -78,48 +88,48 @@ func (s *Service) GetAllower(ctx context.Context, obj any) (*unidiff.Allower, er
 	case auth.SubjectAnonymous:
 		switch object := obj.(type) {
 		case *changes.Change:
-			return s.getAnonymousChangeAllower(ctx, object)
+			return s.getAnonymousChangeAllower(ctx, action, object)
 		case changes.Change:
-			return s.getAnonymousChangeAllower(ctx, &object)
+			return s.getAnonymousChangeAllower(ctx, action, &object)
 		case workspaces.Workspace:
-			return s.getAnonymousWorkspaceAllower(ctx, &object)
+			return s.getAnonymousWorkspaceAllower(ctx, action, &object)
 		case *workspaces.Workspace:
-			return s.getAnonymousWorkspaceAllower(ctx, object)
+			return s.getAnonymousWorkspaceAllower(ctx, action, object)
 		case *codebases.Codebase:
-			return s.getAnonymousCodebaseAllower(ctx, object)
+			return s.getAnonymousCodebaseAllower(ctx, action, object)
 		case codebases.Codebase:
-			return s.getAnonymousCodebaseAllower(ctx, &object)
+			return s.getAnonymousCodebaseAllower(ctx, action, &object)
 		}
 	}
 
 	return noneAllowed, nil
 }
 
-func (s *Service) getUserChangeAllower(ctx context.Context, userID users.ID, change *changes.Change) (*unidiff.Allower, error) {
+func (s *Service) getUserChangeAllower(ctx context.Context, action acl.Action, userID users.ID, change *changes.Change) (*unidiff.Allower, error) {
 	cb, err := s.codebaseService.GetByID(ctx, change.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
-	return s.getUserCodebaseAllower(ctx, userID, cb)
+	return s.getUserCodebaseAllower(ctx, action, userID, cb)
 }
 
-func (s *Service) getUserWorkspaceAllower(ctx context.Context, userID users.ID, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
+func (s *Service) getUserWorkspaceAllower(ctx context.Context, action acl.Action, userID users.ID, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
 	cb, err := s.codebaseService.GetByID(ctx, workspace.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
-	return s.getUserCodebaseAllower(ctx, userID, cb)
+	return s.getUserCodebaseAllower(ctx, action, userID, cb)
 }
 
-func (s *Service) getUserSuggestionAllower(ctx context.Context, userID users.ID, suggestion *suggestions.Suggestion) (*unidiff.Allower, error) {
+func (s *Service) getUserSuggestionAllower(ctx context.Context, action acl.Action, userID users.ID, suggestion *suggestions.Suggestion) (*unidiff.Allower, error) {
 	cb, err := s.codebaseService.GetByID(ctx, suggestion.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
-	return s.getUserCodebaseAllower(ctx, userID, cb)
+	return s.getUserCodebaseAllower(ctx, action, userID, cb)
 }
 
-func (s *Service) getUserCodebaseAllower(ctx context.Context, userID users.ID, codebase *codebases.Codebase) (*unidiff.Allower, error) {
+func (s *Service) getUserCodebaseAllower(ctx context.Context, action acl.Action, userID users.ID, codebase *codebases.Codebase) (*unidiff.Allower, error) {
 	aclPolicy, err := s.aclProvider.GetByCodebaseID(ctx, codebase.ID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return noneAllowed, nil
@@ -134,19 +144,17 @@ func (s *Service) getUserCodebaseAllower(ctx context.Context, userID users.ID, c
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	allowedByEmail := aclPolicy.Policy.List(
-		acl.Identity{Type: acl.Users, ID: user.Email},
-		acl.ActionWrite,
-		acl.Files,
-	)
+	identities, err := s.UserIdentities(ctx, user, codebase)
+	if err != nil {
+		return nil, err
+	}
 
-	allowedByID := aclPolicy.Policy.List(
-		acl.Identity{Type: acl.Users, ID: user.ID.String()},
-		acl.ActionWrite,
-		acl.Files,
-	)
+	var allowed []string
+	for _, identity := range identities {
+		allowed = append(allowed, aclPolicy.Policy.List(identity, action, acl.Files)...)
+	}
 
-	return unidiff.NewAllower(append(allowedByEmail, allowedByID...)...)
+	return unidiff.NewAllower(allowed...)
 }
 
 func (s *Service) getCIWorkspaceAllower(ctx context.Context, workspaceID string, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
@@ -163,22 +171,22 @@ func (s *Service) getCIChangeAllower(ctx context.Context, changeID string, chang
 	return allAllowed, nil
 }
 
-func (s *Service) getAnonymousWorkspaceAllower(ctx context.Context, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
+func (s *Service) getAnonymousWorkspaceAllower(ctx context.Context, action acl.Action, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
 	cb, err := s.codebaseService.GetByID(ctx, workspace.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
-	return s.getAnonymousCodebaseAllower(ctx, cb)
+	return s.getAnonymousCodebaseAllower(ctx, action, cb)
 }
-func (s *Service) getAnonymousChangeAllower(ctx context.Context, change *changes.Change) (*unidiff.Allower, error) {
+func (s *Service) getAnonymousChangeAllower(ctx context.Context, action acl.Action, change *changes.Change) (*unidiff.Allower, error) {
 	cb, err := s.codebaseService.GetByID(ctx, change.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
-	return s.getAnonymousCodebaseAllower(ctx, cb)
+	return s.getAnonymousCodebaseAllower(ctx, action, cb)
 }
 
-func (s *Service) getAnonymousCodebaseAllower(ctx context.Context, cb *codebases.Codebase) (*unidiff.Allower, error) {
+func (s *Service) getAnonymousCodebaseAllower(ctx context.Context, action acl.Action, cb *codebases.Codebase) (*unidiff.Allower, error) {
 	if !cb.IsPublic {
 		// if codebase is not public, then anonymous users can't see any files.
 		return noneAllowed, nil
@@ -195,7 +203,7 @@ func (s *Service) getAnonymousCodebaseAllower(ctx context.Context, cb *codebases
 
 	allowedByID := aclPolicy.Policy.List(
 		acl.Identity{Type: acl.Users, ID: "anonymous"},
-		acl.ActionWrite,
+		action,
 		acl.Files,
 	)
 
@@ -5,7 +5,11 @@ import (
 	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	service_codebases "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/di"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
 	service_organizations "getsturdy.com/api/pkg/organization/service"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	service_users "getsturdy.com/api/pkg/users/service"
 	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
 )
@@ -17,5 +21,9 @@ func Module(c *di.Container) {
 	c.Import(service_workspaces.Module)
 	c.Import(service_organizations.Module)
 	c.Import(provider_acl.Module)
+	c.Import(service_oidc.Module)
+	c.Import(service_scim.Module)
+	c.Import(service_ldap.Module)
+	c.Import(service_twofactor.Module)
 	c.Register(New)
 }
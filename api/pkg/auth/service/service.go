@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"getsturdy.com/api/pkg/activity"
@@ -9,14 +11,19 @@ import (
 	"getsturdy.com/api/pkg/changes"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
 	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
 	"getsturdy.com/api/pkg/organization"
 	service_organization "getsturdy.com/api/pkg/organization/service"
 	"getsturdy.com/api/pkg/review"
+	service_scim "getsturdy.com/api/pkg/scim/service"
 	"getsturdy.com/api/pkg/suggestions"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
 	"getsturdy.com/api/pkg/views"
@@ -31,6 +38,10 @@ type Service struct {
 	workspaceService    *service_workspace.Service
 	aclProvider         *provider_acl.Provider
 	organizationService *service_organization.Service
+	oidcService         *service_oidc.Service
+	scimService         *service_scim.Service
+	ldapService         *service_ldap.Service
+	twoFactorService    *service_twofactor.Service
 }
 
 func New(
@@ -40,6 +51,10 @@ func New(
 	workspaceService *service_workspace.Service,
 	aclProvider *provider_acl.Provider,
 	organizationService *service_organization.Service,
+	oidcService *service_oidc.Service,
+	scimService *service_scim.Service,
+	ldapService *service_ldap.Service,
+	twoFactorService *service_twofactor.Service,
 ) *Service {
 	return &Service{
 		codebaseService:     codebaseService,
@@ -48,6 +63,10 @@ func New(
 		workspaceService:    workspaceService,
 		aclProvider:         aclProvider,
 		organizationService: organizationService,
+		oidcService:         oidcService,
+		scimService:         scimService,
+		ldapService:         ldapService,
+		twoFactorService:    twoFactorService,
 	}
 }
 
@@ -58,8 +77,20 @@ const (
 	accessTypeUnknown accessType = iota
 	accessTypeRead
 	accessTypeWrite
+	accessTypeLand
+	accessTypeReview
+	accessTypeAdmin
 )
 
+// aclActions are the codebase acl actions that are checked for each access type
+var aclActions = map[accessType]acl.Action{
+	accessTypeRead:   acl.ActionRead,
+	accessTypeWrite:  acl.ActionWrite,
+	accessTypeLand:   acl.ActionLand,
+	accessTypeReview: acl.ActionReview,
+	accessTypeAdmin:  acl.ActionAdmin,
+}
+
 // CanRead checks if the user has the read permission on the given object.
 func (s *Service) CanRead(ctx context.Context, obj any) error {
 	return s.hasAccess(ctx, accessTypeRead, obj)
@@ -70,6 +101,22 @@ func (s *Service) CanWrite(ctx context.Context, obj any) error {
 	return s.hasAccess(ctx, accessTypeWrite, obj)
 }
 
+// CanLand checks if the user has the land permission on the given object.
+func (s *Service) CanLand(ctx context.Context, obj any) error {
+	return s.hasAccess(ctx, accessTypeLand, obj)
+}
+
+// CanReview checks if the user has the review permission on the given object.
+func (s *Service) CanReview(ctx context.Context, obj any) error {
+	return s.hasAccess(ctx, accessTypeReview, obj)
+}
+
+// CanAdmin checks if the user has the admin permission on the given object, which is needed to change the settings
+// and the integrations of codebases.
+func (s *Service) CanAdmin(ctx context.Context, obj any) error {
+	return s.hasAccess(ctx, accessTypeAdmin, obj)
+}
+
 // hasAccess checks if the user has the given permission on the given object.
 //nolint:cyclop
 func (s *Service) hasAccess(ctx context.Context, at accessType, obj any) error {
@@ -84,6 +131,10 @@ func (s *Service) hasAccess(ctx context.Context, at accessType, obj any) error {
 
 	switch subject.Type {
 	case auth.SubjectUser:
+		if scope := requiredScope(at, obj); !subject.HasScope(scope) {
+			return fmt.Errorf("missing the %s scope: %w", scope, auth.ErrForbidden)
+		}
+
 		subjectID := users.ID(subject.ID)
 		switch object := obj.(type) {
 		case review.Review:
@@ -196,6 +247,29 @@ func (s *Service) hasAccess(ctx context.Context, at accessType, obj any) error {
 	}
 }
 
+// requiredScope returns the scope that a user needs to have to access the object. Users that are not restricted to
+// any scopes have all of them.
+func requiredScope(at accessType, obj any) auth.Scope {
+	switch obj.(type) {
+	case organization.Organization, *organization.Organization:
+		if at == accessTypeRead {
+			return auth.ScopeReadCodebase
+		}
+		return auth.ScopeAdmin
+	}
+
+	switch at {
+	case accessTypeRead:
+		return auth.ScopeReadCodebase
+	case accessTypeLand:
+		return auth.ScopeLand
+	case accessTypeAdmin:
+		return auth.ScopeAdmin
+	default:
+		return auth.ScopeWriteWorkspace
+	}
+}
+
 func (s *Service) canCIAccessWorkspace(ctx context.Context, workspaceID string, workspace *workspaces.Workspace) error {
 	if workspaceID != workspace.ID {
 		return fmt.Errorf("ci doesn't have access to the workspace: %w", auth.ErrForbidden)
@@ -241,27 +315,108 @@ func (s *Service) canUserAccessCodebase(ctx context.Context, userID users.ID, at
 		return nil
 	}
 
+	if codebase.OrganizationID != nil {
+		org, err := s.organizationService.GetByID(ctx, *codebase.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		if err := s.requireTwoFactor(ctx, userID, org); err != nil {
+			return err
+		}
+	}
+
 	accessAllowed, err := s.codebaseService.CanAccess(ctx, userID, codebase.ID)
 	if err != nil {
 		return fmt.Errorf("failed to check if user can access codebase: %w", err)
 	}
 
-	if accessAllowed {
+	// members of the organization can access all of it's codebases, guests only the ones they are members of
+	if !accessAllowed && codebase.OrganizationID != nil {
+		accessAllowed, err = s.organizationService.HasRole(ctx, *codebase.OrganizationID, userID, organization.RoleMember)
+		if err != nil {
+			return fmt.Errorf("failed to check if user can access codebase: %w", err)
+		}
+	}
+
+	if !accessAllowed {
+		return fmt.Errorf("user doesn't have acces to the codebase: %w", auth.ErrForbidden)
+	}
+
+	return s.canUserCodebaseACL(ctx, userID, at, codebase)
+}
+
+// canUserCodebaseACL checks the acl of the codebase. Actions are only enforced if the acl has rules for them on
+// codebases, members of the codebase are allowed everything by default.
+func (s *Service) canUserCodebaseACL(ctx context.Context, userID users.ID, at accessType, codebase *codebases.Codebase) error {
+	action, ok := aclActions[at]
+	if !ok {
+		return fmt.Errorf("unknown access type: %w", auth.ErrForbidden)
+	}
+
+	aclPolicy, err := s.aclProvider.GetByCodebaseID(ctx, codebase.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
 		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get acl policy: %w", err)
+	}
+
+	if !aclPolicy.Policy.Restricts(action, acl.Codebases) {
+		return nil
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	identities, err := s.UserIdentities(ctx, user, codebase)
+	if err != nil {
+		return err
+	}
+
+	resource := acl.Identity{Type: acl.Codebases, ID: codebase.ID.String()}
+	for _, identity := range identities {
+		if aclPolicy.Policy.Assert(identity, action, resource) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("acl doesn't allow the user to %s the codebase: %w", action, auth.ErrForbidden)
+}
+
+// UserIdentities returns the identities that the user has in the acl of the codebase. Users can be referred to by
+// their id and email, and by the groups they are in at the identity provider, or in the organization of the codebase.
+func (s *Service) UserIdentities(ctx context.Context, user *users.User, codebase *codebases.Codebase) ([]acl.Identity, error) {
+	identities := []acl.Identity{
+		{Type: acl.Users, ID: user.ID.String()},
+		{Type: acl.Users, ID: user.Email},
+	}
+
+	groups, err := s.oidcService.Groups(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
 	}
 
 	if codebase.OrganizationID != nil {
-		org, err := s.organizationService.GetByID(ctx, *codebase.OrganizationID)
+		scimGroups, err := s.scimService.Groups(ctx, *codebase.OrganizationID, user.ID)
 		if err != nil {
-			return fmt.Errorf("failed to check if user can access codebase: %w", err)
+			return nil, fmt.Errorf("failed to get scim groups: %w", err)
 		}
+		groups = append(groups, scimGroups...)
+	}
 
-		if err := s.canUserAccessOrganization(ctx, userID, accessTypeWrite, org); err == nil {
-			return nil
-		}
+	ldapGroups, err := s.ldapService.Groups(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ldap groups: %w", err)
+	}
+	groups = append(groups, ldapGroups...)
+
+	for _, group := range groups {
+		identities = append(identities, acl.Identity{Type: acl.Groups, ID: group})
 	}
 
-	return fmt.Errorf("user doesn't have acces to the codebase: %w", auth.ErrForbidden)
+	return identities, nil
 }
 
 func (s *Service) canAnonymousAccessCodebase(ctx context.Context, at accessType, codebase *codebases.Codebase) error {
@@ -420,7 +575,7 @@ func (s *Service) canAnonymousAccessGitHubRepo(ctx context.Context, at accessTyp
 }
 
 func (s *Service) canAnonymousAccessView(ctx context.Context, at accessType, v *views.View) error {
-	if at == accessTypeWrite {
+	if at != accessTypeRead {
 		return fmt.Errorf("anonymous users can only read views: %w", auth.ErrForbidden)
 	}
 	// user can access a view if they can access the codebase it's in
@@ -466,10 +621,21 @@ func (s *Service) canAnonymousAccessReview(ctx context.Context, at accessType, r
 }
 
 func (s *Service) canUserAccessOrganization(ctx context.Context, userID users.ID, at accessType, org *organization.Organization) error {
-	// user can access a organization if they are a member of it
-	_, err := s.organizationService.GetMemberByUserIDAndOrganizationID(ctx, userID, org.ID)
+	// members without two-factor authentication can still read the organization, so that they can see why they can't
+	// access it
+	if at != accessTypeRead {
+		if err := s.requireTwoFactor(ctx, userID, org); err != nil {
+			return err
+		}
+	}
+
+	// user can read a organization if they are a member of it, and write to it if they are an admin
+	member, err := s.organizationService.GetMemberByUserIDAndOrganizationID(ctx, userID, org.ID)
 	if err == nil {
-		return nil
+		if at == accessTypeRead || member.Role.AtLeast(organization.RoleAdmin) {
+			return nil
+		}
+		return fmt.Errorf("only admins can change the organization: %w", auth.ErrForbidden)
 	}
 
 	// user can read (but not write) a organization if they are a member of any of it's codebases
@@ -486,6 +652,22 @@ func (s *Service) canUserAccessOrganization(ctx context.Context, userID users.ID
 	return fmt.Errorf("user does not have access to organization: %w", auth.ErrForbidden)
 }
 
+// requireTwoFactor returns an error if the organization requires two-factor authentication, and the user doesn't have
+// it enabled.
+func (s *Service) requireTwoFactor(ctx context.Context, userID users.ID, org *organization.Organization) error {
+	if !org.RequireTwoFactor {
+		return nil
+	}
+	enabled, err := s.twoFactorService.IsEnabled(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if !enabled {
+		return fmt.Errorf("the organization requires two-factor authentication: %w", auth.ErrForbidden)
+	}
+	return nil
+}
+
 func (s *Service) canAnonymousAccessOrganization(ctx context.Context, at accessType, org *organization.Organization) error {
 	return fmt.Errorf("anonymous users can't access organizations: %w", auth.ErrForbidden)
 }
@@ -3,20 +3,42 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
 	"getsturdy.com/api/pkg/analytics/disabled"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
+	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	db_codebase "getsturdy.com/api/pkg/codebases/db"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/ldap"
+	configuration_ldap "getsturdy.com/api/pkg/ldap/configuration"
+	db_ldap "getsturdy.com/api/pkg/ldap/db"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	"getsturdy.com/api/pkg/oidc"
+	configuration_oidc "getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
 	"getsturdy.com/api/pkg/organization"
 	db_organization "getsturdy.com/api/pkg/organization/db"
 	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/scim"
+	db_scim "getsturdy.com/api/pkg/scim/db"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users"
+	db_user "getsturdy.com/api/pkg/users/db"
+	service_user "getsturdy.com/api/pkg/users/service"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -66,13 +88,19 @@ func TestCanWrite_codebase(t *testing.T) {
 
 	codebaseRepo := db_codebase.NewMemory()
 	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
 
 	authService := service_auth.New(
 		codebaseService,
 		nil,
 		nil,
 		nil,
+		aclProvider,
+		nil,
+		nil,
+		nil,
 		nil,
 		nil,
 	)
@@ -81,6 +109,7 @@ func TestCanWrite_codebase(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			cb := codebases.Codebase{ID: codebases.ID(uuid.NewString()), IsPublic: tc.codebaseIsPublic}
 			assert.NoError(t, codebaseRepo.Create(cb))
+			assert.NoError(t, aclRepo.Create(context.Background(), acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: "{}"}))
 
 			userID := users.ID(uuid.NewString())
 
@@ -118,6 +147,7 @@ func TestCanRead_codebase(t *testing.T) {
 		codebaseIsPublic bool
 
 		isMemberOfOrganization bool
+		organizationRole       organization.Role
 
 		expected bool
 	}{
@@ -154,7 +184,17 @@ func TestCanRead_codebase(t *testing.T) {
 
 		{
 			name:            "user-can-read-private-codebase-member-of-organization",
-			isAuthenticated: true, isMember: false, isMemberOfOrganization: true, codebaseIsPublic: false,
+			isAuthenticated: true, isMember: false, isMemberOfOrganization: true, organizationRole: organization.RoleMember, codebaseIsPublic: false,
+			expected: true,
+		},
+		{
+			name:            "user-can-not-read-private-codebase-guest-of-organization",
+			isAuthenticated: true, isMember: false, isMemberOfOrganization: true, organizationRole: organization.RoleGuest, codebaseIsPublic: false,
+			expected: false,
+		},
+		{
+			name:            "user-can-read-private-codebase-guest-of-organization-is-member",
+			isAuthenticated: true, isMember: true, isMemberOfOrganization: true, organizationRole: organization.RoleGuest, codebaseIsPublic: false,
 			expected: true,
 		},
 	}
@@ -162,19 +202,26 @@ func TestCanRead_codebase(t *testing.T) {
 	codebaseRepo := db_codebase.NewMemory()
 	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
 	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil, nil, nil)
 
 	organizationRepo := db_organization.NewInMemoryOrganizationRepo()
 	organizationMemberRepo := db_organization.NewInMemoryOrganizationMemberRepository()
-	organizationService := service_organization.New(zap.NewNop(), nil, organizationRepo, organizationMemberRepo, analyticsService, nil)
+	organizationService := service_organization.New(zap.NewNop(), nil, organizationRepo, organizationMemberRepo, analyticsService, nil, service_audit.New(db_audit.NewMemory(), nil))
+
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
 
 	authService := service_auth.New(
 		codebaseService,
 		nil,
 		nil,
 		nil,
-		nil,
+		aclProvider,
 		organizationService,
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	for _, tc := range cases {
@@ -188,6 +235,7 @@ func TestCanRead_codebase(t *testing.T) {
 			}
 
 			assert.NoError(t, codebaseRepo.Create(cb))
+			assert.NoError(t, aclRepo.Create(context.Background(), acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: "{}"}))
 
 			userID := users.ID(uuid.NewString())
 
@@ -199,7 +247,7 @@ func TestCanRead_codebase(t *testing.T) {
 			if tc.isMemberOfOrganization {
 				org := organization.Organization{ID: orgID}
 				assert.NoError(t, organizationRepo.Create(context.Background(), org))
-				orgmember := &organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID}
+				orgmember := &organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID, Role: tc.organizationRole}
 				assert.NoError(t, organizationMemberRepo.Create(context.Background(), orgmember))
 			}
 
@@ -227,18 +275,40 @@ func TestCanReadWrite_organization(t *testing.T) {
 
 		isAuthenticated    bool
 		isMember           bool
+		role               organization.Role
 		isMemberOfCodebase bool
 
 		expectedCanRead  bool
 		expectedCanWrite bool
 	}{
 		{
-			name:            "member-can-access",
-			isAuthenticated: true, isMember: true, isMemberOfCodebase: false,
+			name:            "admin-can-access",
+			isAuthenticated: true, isMember: true, role: organization.RoleAdmin, isMemberOfCodebase: false,
+
+			expectedCanRead:  true,
+			expectedCanWrite: true,
+		},
+		{
+			name:            "owner-can-access",
+			isAuthenticated: true, isMember: true, role: organization.RoleOwner, isMemberOfCodebase: false,
 
 			expectedCanRead:  true,
 			expectedCanWrite: true,
 		},
+		{
+			name:            "member-can-read-only",
+			isAuthenticated: true, isMember: true, role: organization.RoleMember, isMemberOfCodebase: false,
+
+			expectedCanRead:  true,
+			expectedCanWrite: false,
+		},
+		{
+			name:            "guest-can-read-only",
+			isAuthenticated: true, isMember: true, role: organization.RoleGuest, isMemberOfCodebase: false,
+
+			expectedCanRead:  true,
+			expectedCanWrite: false,
+		},
 		{
 			name:            "non-member-can-not-access",
 			isAuthenticated: true, isMember: false, isMemberOfCodebase: false,
@@ -247,8 +317,8 @@ func TestCanReadWrite_organization(t *testing.T) {
 			expectedCanWrite: false,
 		},
 		{
-			name:            "member-can-access-if-member-of-codebase",
-			isAuthenticated: true, isMember: true, isMemberOfCodebase: true,
+			name:            "admin-can-access-if-member-of-codebase",
+			isAuthenticated: true, isMember: true, role: organization.RoleAdmin, isMemberOfCodebase: true,
 
 			expectedCanRead:  true,
 			expectedCanWrite: true,
@@ -265,12 +335,12 @@ func TestCanReadWrite_organization(t *testing.T) {
 	codebaseRepo := db_codebase.NewMemory()
 	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
 	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil, nil, nil)
 
 	organizationRepo := db_organization.NewInMemoryOrganizationRepo()
 	organizationMemberRepo := db_organization.NewInMemoryOrganizationMemberRepository()
 
-	organizationService := service_organization.New(zap.NewNop(), nil, organizationRepo, organizationMemberRepo, analyticsService, nil)
+	organizationService := service_organization.New(zap.NewNop(), nil, organizationRepo, organizationMemberRepo, analyticsService, nil, service_audit.New(db_audit.NewMemory(), nil))
 
 	authService := service_auth.New(
 		codebaseService,
@@ -279,6 +349,10 @@ func TestCanReadWrite_organization(t *testing.T) {
 		nil,
 		nil,
 		organizationService,
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	for _, tc := range cases {
@@ -291,7 +365,7 @@ func TestCanReadWrite_organization(t *testing.T) {
 			userID := users.ID(uuid.NewString())
 
 			if tc.isMember {
-				orgmember := &organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID}
+				orgmember := &organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID, Role: tc.role}
 				assert.NoError(t, organizationMemberRepo.Create(bgCtx, orgmember))
 			}
 
@@ -327,3 +401,297 @@ func TestCanReadWrite_organization(t *testing.T) {
 		})
 	}
 }
+
+func TestCanRead_codebase_groups(t *testing.T) {
+	cases := []struct {
+		name string
+
+		groups     []string
+		scimGroups []string
+		ldapGroups []string
+
+		expected bool
+	}{
+		{name: "in-group-has-access", groups: []string{"engineering"}, expected: true},
+		{name: "in-other-group-no-access", groups: []string{"sales"}, expected: false},
+		{name: "in-scim-group-has-access", scimGroups: []string{"engineering"}, expected: true},
+		{name: "in-other-scim-group-no-access", scimGroups: []string{"sales"}, expected: false},
+		{name: "in-ldap-group-has-access", ldapGroups: []string{"engineering"}, expected: true},
+		{name: "in-other-ldap-group-no-access", ldapGroups: []string{"sales"}, expected: false},
+		{name: "no-groups-no-access", expected: false},
+	}
+
+	codebaseRepo := db_codebase.NewMemory()
+	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
+
+	userRepo := db_user.NewMemory()
+	userService := service_user.New(zap.NewNop(), userRepo, nil)
+
+	oidcConfig := &configuration_oidc.Configuration{IssuerURL: "https://sso.example.com", GroupsClaim: "groups"}
+	identityRepo := db_oidc.NewMemory()
+	oidcService := service_oidc.New(zap.NewNop(), oidcConfig, identityRepo, userService, nil)
+
+	groupRepo := db_scim.NewMemoryGroupRepository()
+	scimService := service_scim.New(zap.NewNop(), nil, groupRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	ldapConfig := &configuration_ldap.Configuration{URL: "ldap://ldap.example.com", GroupBaseDN: "ou=groups,dc=example,dc=com"}
+	ldapGroupRepo := db_ldap.NewMemoryGroupRepository()
+	ldapService := service_ldap.New(zap.NewNop(), ldapConfig, nil, nil, ldapGroupRepo, nil, nil)
+
+	authService := service_auth.New(
+		codebaseService,
+		nil,
+		userService,
+		nil,
+		aclProvider,
+		nil,
+		oidcService,
+		scimService,
+		ldapService,
+		nil,
+	)
+
+	policy := `{
+		"rules": [
+			{
+				"action": "read",
+				"principals": ["groups::engineering"],
+				"resources": ["codebases::*"]
+			}
+		]
+	}`
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			orgID := uuid.NewString()
+			cb := codebases.Codebase{ID: codebases.ID(uuid.NewString()), OrganizationID: &orgID}
+			assert.NoError(t, codebaseRepo.Create(cb))
+			assert.NoError(t, aclRepo.Create(ctx, acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: policy}))
+
+			user := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com"}
+			assert.NoError(t, userRepo.Create(user))
+			assert.NoError(t, codebaseUserRepo.Create(codebases.CodebaseUser{ID: uuid.NewString(), CodebaseID: cb.ID, UserID: user.ID}))
+			assert.NoError(t, identityRepo.Create(ctx, &oidc.Identity{
+				ID:      uuid.NewString(),
+				Issuer:  oidcConfig.IssuerURL,
+				Subject: uuid.NewString(),
+				UserID:  user.ID,
+				Groups:  tc.groups,
+			}))
+			for _, name := range tc.scimGroups {
+				group := &scim.Group{ID: uuid.NewString(), OrganizationID: orgID, DisplayName: name}
+				assert.NoError(t, groupRepo.Create(ctx, group))
+				assert.NoError(t, groupRepo.AddMember(ctx, group.ID, user.ID))
+			}
+			for _, name := range tc.ldapGroups {
+				group := &ldap.Group{ID: uuid.NewString(), DN: "cn=" + name + "," + ldapConfig.GroupBaseDN, Name: name}
+				assert.NoError(t, ldapGroupRepo.Create(ctx, group))
+				assert.NoError(t, ldapGroupRepo.AddMember(ctx, group.ID, user.ID))
+			}
+
+			ctx = auth.NewContext(ctx, &auth.Subject{ID: user.ID.String(), Type: auth.SubjectUser})
+
+			hasAccessErr := authService.CanRead(ctx, cb)
+			if tc.expected {
+				assert.NoError(t, hasAccessErr)
+			} else {
+				assert.Error(t, hasAccessErr)
+			}
+		})
+	}
+}
+
+func TestCanAdmin_codebase(t *testing.T) {
+	codebaseRepo := db_codebase.NewMemory()
+	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
+
+	userRepo := db_user.NewMemory()
+	userService := service_user.New(zap.NewNop(), userRepo, nil)
+	oidcService := service_oidc.New(zap.NewNop(), &configuration_oidc.Configuration{}, db_oidc.NewMemory(), userService, nil)
+	ldapService := service_ldap.New(zap.NewNop(), &configuration_ldap.Configuration{}, nil, nil, db_ldap.NewMemoryGroupRepository(), nil, nil)
+
+	authService := service_auth.New(codebaseService, nil, userService, nil, aclProvider, nil, oidcService, nil, ldapService, nil)
+
+	policy := `{
+		"rules": [
+			{
+				"action": "admin",
+				"principals": ["users::admin@example.com"],
+				"resources": ["codebases::*"]
+			},
+			{
+				"action": "write",
+				"principals": ["users::writer@example.com"],
+				"resources": ["codebases::*"]
+			}
+		]
+	}`
+
+	cases := []struct {
+		name   string
+		email  string
+		policy string
+		scopes []auth.Scope
+
+		canWrite, canAdmin bool
+	}{
+		{name: "admin", email: "admin@example.com", policy: policy, canWrite: true, canAdmin: true},
+		{name: "writer", email: "writer@example.com", policy: policy, canWrite: true, canAdmin: false},
+		{name: "admin-without-admin-scope", email: "admin@example.com", policy: policy, scopes: []auth.Scope{auth.ScopeWriteWorkspace}, canWrite: true, canAdmin: false},
+		{name: "admin-not-restricted", email: "writer@example.com", policy: "{}", canWrite: true, canAdmin: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			cb := codebases.Codebase{ID: codebases.ID(uuid.NewString())}
+			assert.NoError(t, codebaseRepo.Create(cb))
+			assert.NoError(t, aclRepo.Create(ctx, acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: tc.policy}))
+
+			user := &users.User{ID: users.ID(uuid.NewString()), Email: tc.email}
+			assert.NoError(t, userRepo.Create(user))
+			assert.NoError(t, codebaseUserRepo.Create(codebases.CodebaseUser{ID: uuid.NewString(), CodebaseID: cb.ID, UserID: user.ID}))
+
+			ctx = auth.NewContext(ctx, &auth.Subject{ID: user.ID.String(), Type: auth.SubjectUser, Scopes: tc.scopes})
+
+			if err := authService.CanWrite(ctx, cb); tc.canWrite {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, auth.ErrForbidden)
+			}
+			if err := authService.CanAdmin(ctx, cb); tc.canAdmin {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, auth.ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestCanAccess_codebase_scopes(t *testing.T) {
+	codebaseRepo := db_codebase.NewMemory()
+	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
+
+	authService := service_auth.New(codebaseService, nil, nil, nil, aclProvider, nil, nil, nil, nil, nil)
+
+	userID := users.ID(uuid.NewString())
+	cb := codebases.Codebase{ID: codebases.ID(uuid.NewString())}
+	assert.NoError(t, codebaseRepo.Create(cb))
+	assert.NoError(t, aclRepo.Create(context.Background(), acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: "{}"}))
+	assert.NoError(t, codebaseUserRepo.Create(codebases.CodebaseUser{ID: uuid.NewString(), CodebaseID: cb.ID, UserID: userID}))
+
+	cases := []struct {
+		name   string
+		scopes []auth.Scope
+
+		canRead, canWrite, canLand bool
+	}{
+		{name: "session", scopes: nil, canRead: true, canWrite: true, canLand: true},
+		{name: "no-scopes", scopes: []auth.Scope{}},
+		{name: "read", scopes: []auth.Scope{auth.ScopeReadCodebase}, canRead: true},
+		{name: "write", scopes: []auth.Scope{auth.ScopeWriteWorkspace}, canRead: true, canWrite: true},
+		{name: "land", scopes: []auth.Scope{auth.ScopeLand}, canRead: true, canLand: true},
+		{name: "admin", scopes: []auth.Scope{auth.ScopeAdmin}, canRead: true, canWrite: true, canLand: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := auth.NewContext(context.Background(), &auth.Subject{ID: userID.String(), Type: auth.SubjectUser, Scopes: tc.scopes})
+
+			for _, check := range []struct {
+				fn       func(context.Context, any) error
+				expected bool
+			}{
+				{fn: authService.CanRead, expected: tc.canRead},
+				{fn: authService.CanWrite, expected: tc.canWrite},
+				{fn: authService.CanLand, expected: tc.canLand},
+			} {
+				if err := check.fn(ctx, cb); check.expected {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorIs(t, err, auth.ErrForbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestCanAccess_organization_requires_two_factor(t *testing.T) {
+	codebaseRepo := db_codebase.NewMemory()
+	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
+	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil, nil, nil)
+
+	organizationRepo := db_organization.NewInMemoryOrganizationRepo()
+	organizationMemberRepo := db_organization.NewInMemoryOrganizationMemberRepository()
+	organizationService := service_organization.New(zap.NewNop(), nil, organizationRepo, organizationMemberRepo, analyticsService, nil, service_audit.New(db_audit.NewMemory(), nil))
+
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
+
+	enrollmentRepo := db_twofactor.NewEnrollmentMemory()
+	twoFactorService := service_twofactor.New(zap.NewNop(), enrollmentRepo, db_twofactor.NewRecoveryCodeMemory(), nil, analyticsService)
+
+	authService := service_auth.New(codebaseService, nil, nil, nil, aclProvider, organizationService, nil, nil, nil, twoFactorService)
+
+	cases := []struct {
+		name             string
+		requireTwoFactor bool
+		twoFactorEnabled bool
+
+		canReadOrganization, canWriteOrganization, canReadCodebase bool
+	}{
+		{name: "not-required", canReadOrganization: true, canWriteOrganization: true, canReadCodebase: true},
+		{name: "required-enabled", requireTwoFactor: true, twoFactorEnabled: true, canReadOrganization: true, canWriteOrganization: true, canReadCodebase: true},
+		{name: "required-not-enabled", requireTwoFactor: true, canReadOrganization: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bgCtx := context.Background()
+			userID := users.ID(uuid.NewString())
+
+			org := organization.Organization{ID: uuid.NewString(), RequireTwoFactor: tc.requireTwoFactor}
+			assert.NoError(t, organizationRepo.Create(bgCtx, org))
+			assert.NoError(t, organizationMemberRepo.Create(bgCtx, &organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID, Role: organization.RoleOwner}))
+
+			cb := codebases.Codebase{ID: codebases.ID(uuid.NewString()), OrganizationID: &org.ID}
+			assert.NoError(t, codebaseRepo.Create(cb))
+			assert.NoError(t, aclRepo.Create(bgCtx, acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: "{}"}))
+
+			if tc.twoFactorEnabled {
+				now := time.Now()
+				assert.NoError(t, enrollmentRepo.Create(bgCtx, &twofactor.Enrollment{UserID: userID, Secret: "SECRET", CreatedAt: now, ConfirmedAt: &now}))
+			}
+
+			ctx := auth.NewContext(bgCtx, &auth.Subject{ID: userID.String(), Type: auth.SubjectUser})
+
+			for _, check := range []struct {
+				err      error
+				expected bool
+			}{
+				{err: authService.CanRead(ctx, &org), expected: tc.canReadOrganization},
+				{err: authService.CanWrite(ctx, &org), expected: tc.canWriteOrganization},
+				{err: authService.CanRead(ctx, cb), expected: tc.canReadCodebase},
+			} {
+				if check.expected {
+					assert.NoError(t, check.err)
+				} else {
+					assert.ErrorIs(t, check.err, auth.ErrForbidden)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package signed authenticates requests from the ssh server. The ssh server authenticates users with their public
+// keys, and makes requests on their behalf, signed with a secret that is shared between the ssh server and the API.
+// The secret never leaves the ssh server, requests from mutagen agents are signed by the ssh server as well.
+package signed
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+const (
+	UserIDHeader    = "X-Sturdy-User-Id"
+	TimestampHeader = "X-Sturdy-Timestamp"
+	SignatureHeader = "X-Sturdy-Signature"
+
+	maxClockSkew = 5 * time.Minute
+	// maxBodySize is the largest body that is read to verify a signature
+	maxBodySize = 32 << 20
+)
+
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpiredSignature = errors.New("expired signature")
+)
+
+// Signature returns the signature of a request made on behalf of userID. The signature covers the method, the path
+// and query of the request, and the body.
+func Signature(secret []byte, userID, timestamp, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", userID, timestamp, method, requestURI, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign signs the request on behalf of userID. The body of the request is read, and replaced.
+func Sign(r *http.Request, secret []byte, userID string, now time.Time) error {
+	body, err := readBody(r, -1)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	r.Header.Set(UserIDHeader, userID)
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(SignatureHeader, Signature(secret, userID, timestamp, r.Method, r.URL.RequestURI(), body))
+	return nil
+}
+
+// Verify returns the id of the user that a signed request is made on behalf of. The body of the request is read, and
+// replaced.
+func Verify(r *http.Request, secret []byte, now time.Time) (users.ID, error) {
+	userID := r.Header.Get(UserIDHeader)
+	timestamp := r.Header.Get(TimestampHeader)
+	signature := r.Header.Get(SignatureHeader)
+	if userID == "" || timestamp == "" || signature == "" {
+		return "", ErrMissingSignature
+	}
+
+	body, err := readBody(r, maxBodySize)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	expected := Signature(secret, userID, timestamp, r.Method, r.URL.RequestURI(), body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return "", ErrExpiredSignature
+	}
+
+	return users.ID(userID), nil
+}
+
+// readBody reads the body of the request, and replaces it so that it can be read again. If limit is not negative,
+// bodies larger than limit are an error.
+func readBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	reader := io.Reader(r.Body)
+	if limit >= 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if limit >= 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("body is larger than %d bytes", limit)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
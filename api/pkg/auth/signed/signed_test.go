@@ -0,0 +1,150 @@
+package signed
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1650000000, 0)
+	path := "/v3/mutagen/views/view-id/allows?limit=10"
+	body := `{"state":"ok"}`
+
+	tests := []struct {
+		name        string
+		userID      string
+		timestamp   time.Time
+		method      string
+		path        string
+		body        string
+		secret      []byte
+		expectedErr error
+	}{
+		{
+			name:      "valid",
+			userID:    "user-id",
+			timestamp: now,
+			method:    "POST",
+			path:      path,
+			body:      body,
+			secret:    secret,
+		},
+		{
+			name:        "missing user",
+			timestamp:   now,
+			method:      "POST",
+			path:        path,
+			body:        body,
+			secret:      secret,
+			expectedErr: ErrMissingSignature,
+		},
+		{
+			name:        "wrong secret",
+			userID:      "user-id",
+			timestamp:   now,
+			method:      "POST",
+			path:        path,
+			body:        body,
+			secret:      []byte("wrong"),
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "other method",
+			userID:      "user-id",
+			timestamp:   now,
+			method:      "DELETE",
+			path:        path,
+			body:        body,
+			secret:      secret,
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "other query",
+			userID:      "user-id",
+			timestamp:   now,
+			method:      "POST",
+			path:        "/v3/mutagen/views/view-id/allows?limit=1000",
+			body:        body,
+			secret:      secret,
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "other body",
+			userID:      "user-id",
+			timestamp:   now,
+			method:      "POST",
+			path:        path,
+			body:        `{"state":"failed"}`,
+			secret:      secret,
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "other path",
+			userID:      "user-id",
+			timestamp:   now,
+			method:      "POST",
+			path:        "/v3/mutagen/views/other-view-id/allows?limit=10",
+			body:        body,
+			secret:      secret,
+			expectedErr: ErrInvalidSignature,
+		},
+		{
+			name:        "expired",
+			userID:      "user-id",
+			timestamp:   now.Add(-time.Hour),
+			method:      "POST",
+			path:        path,
+			body:        body,
+			secret:      secret,
+			expectedErr: ErrExpiredSignature,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			timestamp := strconv.FormatInt(tc.timestamp.Unix(), 10)
+
+			r := httptest.NewRequest("POST", path, strings.NewReader(body))
+			r.Header.Set(UserIDHeader, tc.userID)
+			r.Header.Set(TimestampHeader, timestamp)
+			r.Header.Set(SignatureHeader, Signature(tc.secret, tc.userID, timestamp, tc.method, tc.path, []byte(tc.body)))
+
+			userID, err := Verify(r, secret, now)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.userID, userID.String())
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1650000000, 0)
+
+	r := httptest.NewRequest("POST", "/v3/mutagen/sync-transitions", strings.NewReader(`{"paths":["a"]}`))
+	assert.NoError(t, Sign(r, secret, "user-id", now))
+
+	userID, err := Verify(r, secret, now.Add(time.Minute))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user-id", userID.String())
+	}
+
+	// the body can still be read by the handler
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"paths":["a"]}`, string(body))
+
+	_, err = Verify(r, []byte("wrong"), now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
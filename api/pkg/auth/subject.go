@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"fmt"
 
 	"getsturdy.com/api/pkg/jwt"
 	"getsturdy.com/api/pkg/users"
@@ -24,6 +25,9 @@ func (st SubjectType) String() string {
 type Subject struct {
 	ID   string
 	Type SubjectType
+	// Scopes restricts what the subject is allowed to do, it's set for subjects authenticated with a personal
+	// access token. If nil, the subject is not restricted.
+	Scopes []Scope
 }
 
 var (
@@ -77,3 +81,31 @@ func UserID(ctx context.Context) (users.ID, error) {
 	}
 	return users.ID(s.ID), nil
 }
+
+// UnscopedUserID returns the authenticated user's id from the context, like UserID, but returns ErrForbidden if the
+// subject is restricted to scopes. It's used where credentials are issued or managed, as they would not be restricted
+// to the scopes of the personal access token that was used to get them.
+func UnscopedUserID(ctx context.Context) (users.ID, error) {
+	userID, err := UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if s, _ := FromContext(ctx); s.Scopes != nil {
+		return "", fmt.Errorf("scoped subjects can not manage credentials: %w", ErrForbidden)
+	}
+	return userID, nil
+}
+
+// AdminUserID returns the authenticated user's id from the context, like UserID, but returns ErrForbidden if the
+// subject is restricted to scopes that don't include ScopeAdmin. It's used where accounts, credentials or access
+// control are changed.
+func AdminUserID(ctx context.Context) (users.ID, error) {
+	userID, err := UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if s, _ := FromContext(ctx); !s.HasScope(ScopeAdmin) {
+		return "", fmt.Errorf("missing the %s scope: %w", ScopeAdmin, ErrForbidden)
+	}
+	return userID, nil
+}
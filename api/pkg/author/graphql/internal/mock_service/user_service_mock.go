@@ -66,6 +66,21 @@ func (mr *MockServiceMockRecorder) CreateShadow(arg0, arg1, arg2, arg3 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShadow", reflect.TypeOf((*MockService)(nil).CreateShadow), arg0, arg1, arg2, arg3)
 }
 
+// CreateVerified mocks base method.
+func (m *MockService) CreateVerified(arg0 context.Context, arg1, arg2 string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVerified", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVerified indicates an expected call of CreateVerified.
+func (mr *MockServiceMockRecorder) CreateVerified(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerified", reflect.TypeOf((*MockService)(nil).CreateVerified), arg0, arg1, arg2)
+}
+
 // CreateWithPassword mocks base method.
 func (m *MockService) CreateWithPassword(arg0 context.Context, arg1, arg2, arg3 string) (*users.User, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +96,20 @@ func (mr *MockServiceMockRecorder) CreateWithPassword(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithPassword", reflect.TypeOf((*MockService)(nil).CreateWithPassword), arg0, arg1, arg2, arg3)
 }
 
+// Deactivate mocks base method.
+func (m *MockService) Deactivate(arg0 context.Context, arg1 *users.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockServiceMockRecorder) Deactivate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockService)(nil).Deactivate), arg0, arg1)
+}
+
 // GetAsAuthor mocks base method.
 func (m *MockService) GetAsAuthor(arg0 context.Context, arg1 users.ID) (*author.Author, error) {
 	m.ctrl.T.Helper()
@@ -175,6 +204,20 @@ func (mr *MockServiceMockRecorder) Inherit(arg0, arg1, arg2 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inherit", reflect.TypeOf((*MockService)(nil).Inherit), arg0, arg1, arg2)
 }
 
+// Reactivate mocks base method.
+func (m *MockService) Reactivate(arg0 context.Context, arg1 *users.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockServiceMockRecorder) Reactivate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockService)(nil).Reactivate), arg0, arg1)
+}
+
 // UsersCount mocks base method.
 func (m *MockService) UsersCount(arg0 context.Context) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -1,7 +1,18 @@
 package blobs
 
+import "strings"
+
 type ID string
 
+// CILogPrefix is the prefix of the logs of ci steps. They are only served by a route that checks access to the
+// codebase, not by the public blobs route.
+const CILogPrefix = "ci-"
+
+// IsPublic returns true if the blob can be served to anyone that knows its id.
+func (id ID) IsPublic() bool {
+	return !strings.HasPrefix(string(id), CILogPrefix)
+}
+
 type Blob struct {
 	ID   ID     `db:"id"`
 	Data []byte `db:"data"`
@@ -19,6 +19,10 @@ func Register(rg *gin.RouterGroup, logger *zap.Logger, blobService *service_blob
 func Get(logger *zap.Logger, blobService *service_blob.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		key := path.Base(r.URL.Path)
+		if !blobs.ID(key).IsPublic() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 
 		blob, err := blobService.Fetch(r.Context(), blobs.ID(key))
 		if errors.Is(err, service_blob.ErrNotFound) {
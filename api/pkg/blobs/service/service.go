@@ -50,3 +50,19 @@ func (s *Service) Store(ctx context.Context, id blobs.ID, reader io.Reader) erro
 	}
 	return nil
 }
+
+// Append appends data to the blob, the blob is created if it does not exist.
+func (s *Service) Append(ctx context.Context, id blobs.ID, data []byte) error {
+	if data == nil {
+		// nil is stored as NULL, and NULL can't be appended to
+		data = []byte{}
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO blobs (id, data)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = blobs.data || EXCLUDED.data
+	`, id, data); err != nil {
+		return fmt.Errorf("failed to append to blob: %w", err)
+	}
+	return nil
+}
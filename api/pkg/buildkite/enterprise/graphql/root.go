@@ -127,7 +127,7 @@ func (root *rootResolver) updateConfiguration(ctx context.Context, existingCfg *
 }
 
 func (root *rootResolver) CreateOrUpdateBuildkiteIntegration(ctx context.Context, args resolvers.CreateOrUpdateBuildkiteIntegrationArgs) (resolvers.IntegrationResolver, error) {
-	if err := root.authService.CanWrite(ctx, &codebases.Codebase{ID: codebases.ID(args.Input.CodebaseID)}); err != nil {
+	if err := root.authService.CanAdmin(ctx, &codebases.Codebase{ID: codebases.ID(args.Input.CodebaseID)}); err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -41,3 +41,12 @@ type Change struct {
 	// Is null for the first change in a codebase, or if the changes parent hasn't been imported to Sturdy yet.
 	ParentChangeID *ID `db:"parent_change_id"`
 }
+
+// Path is a path that has been changed by a change. Paths are indexed to be able to search the changelog by path.
+type Path struct {
+	ChangeID   ID           `db:"change_id"`
+	CodebaseID codebases.ID `db:"codebase_id"`
+	Path       string       `db:"path"`
+	// OldPath is set if the file was renamed (or moved), and is the path of the file before the change.
+	OldPath *string `db:"old_path"`
+}
@@ -3,18 +3,24 @@ package db
 import (
 	"context"
 	"database/sql"
+	"sort"
+	"strings"
+	"time"
 
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
 )
 
 type inMemoryChangeRepo struct {
-	changes map[changes.ID]*changes.Change
+	changes      map[changes.ID]*changes.Change
+	paths        []changes.Path
+	pathsIndexed map[changes.ID]bool
 }
 
 func NewInMemoryRepo() Repository {
 	return &inMemoryChangeRepo{
-		changes: make(map[changes.ID]*changes.Change),
+		changes:      make(map[changes.ID]*changes.Change),
+		pathsIndexed: make(map[changes.ID]bool),
 	}
 }
 
@@ -62,3 +68,147 @@ func (r *inMemoryChangeRepo) GetByParentChangeID(_ context.Context, id changes.I
 	}
 	return nil, sql.ErrNoRows
 }
+
+func (r *inMemoryChangeRepo) InsertPaths(_ context.Context, paths ...changes.Path) error {
+	r.paths = append(r.paths, paths...)
+	return nil
+}
+
+func (r *inMemoryChangeRepo) SetPathsIndexed(_ context.Context, id changes.ID) error {
+	r.pathsIndexed[id] = true
+	return nil
+}
+
+func (r *inMemoryChangeRepo) ListWithoutIndexedPaths(_ context.Context, after *changes.ID, limit int) ([]*changes.Change, error) {
+	var res []*changes.Change
+	for _, ch := range r.changes {
+		if r.pathsIndexed[ch.ID] || ch.CommitID == nil || (after != nil && ch.ID <= *after) {
+			continue
+		}
+		res = append(res, ch)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (r *inMemoryChangeRepo) ListRenames(_ context.Context, codebaseID codebases.ID, filter PathFilter) ([]*Rename, error) {
+	var res []*Rename
+	for _, p := range r.paths {
+		if p.CodebaseID != codebaseID || p.OldPath == nil || !filter.Matches(p.Path) {
+			continue
+		}
+		ch, ok := r.changes[p.ChangeID]
+		if !ok {
+			continue
+		}
+		changedAt := changedAt(ch)
+		if filter.ChangedBefore != nil && !changedAt.Before(*filter.ChangedBefore) {
+			continue
+		}
+		res = append(res, &Rename{ChangeID: p.ChangeID, Path: p.Path, OldPath: *p.OldPath, ChangedAt: changedAt})
+	}
+	return res, nil
+}
+
+func (r *inMemoryChangeRepo) Search(_ context.Context, codebaseID codebases.ID, opts SearchOptions) ([]*changes.Change, error) {
+	var before *changes.Change
+	if opts.Before != nil {
+		var ok bool
+		if before, ok = r.changes[*opts.Before]; !ok {
+			return nil, nil
+		}
+	}
+
+	var res []*changes.Change
+	for _, ch := range r.changes {
+		if ch.CodebaseID != codebaseID {
+			continue
+		}
+		if len(opts.Paths) > 0 && !r.matchesPaths(ch, opts.Paths) {
+			continue
+		}
+		if opts.UserID != nil && (ch.UserID == nil || *ch.UserID != *opts.UserID) {
+			continue
+		}
+		if opts.CreatedAfter != nil && changedAt(ch).Before(*opts.CreatedAfter) {
+			continue
+		}
+		if opts.CreatedBefore != nil && !changedAt(ch).Before(*opts.CreatedBefore) {
+			continue
+		}
+		if opts.Query != nil && !matchesQuery(ch, *opts.Query) {
+			continue
+		}
+		if before != nil && !isOlder(ch, before) {
+			continue
+		}
+		res = append(res, ch)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return isOlder(res[j], res[i])
+	})
+
+	if len(res) > opts.Limit {
+		res = res[:opts.Limit]
+	}
+
+	return res, nil
+}
+
+func (r *inMemoryChangeRepo) matchesPaths(ch *changes.Change, filters []PathFilter) bool {
+	for _, p := range r.paths {
+		if p.ChangeID != ch.ID {
+			continue
+		}
+		for _, filter := range filters {
+			if filter.ChangedBefore != nil && !changedAt(ch).Before(*filter.ChangedBefore) {
+				continue
+			}
+			if filter.Matches(p.Path) || (p.OldPath != nil && filter.Matches(*p.OldPath)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesQuery(ch *changes.Change, query string) bool {
+	var text string
+	if ch.Title != nil {
+		text = *ch.Title
+	}
+	text = strings.ToLower(text + " " + ch.UpdatedDescription)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func changedAt(ch *changes.Change) time.Time {
+	if ch.CreatedAt != nil {
+		return *ch.CreatedAt
+	}
+	if ch.GitCreatedAt != nil {
+		return *ch.GitCreatedAt
+	}
+	return time.Time{}
+}
+
+// isOlder returns true if a comes before b in the changelog
+func isOlder(a, b *changes.Change) bool {
+	if changedAt(a).Equal(changedAt(b)) {
+		return a.ID < b.ID
+	}
+	return changedAt(a).Before(changedAt(b))
+}
@@ -2,9 +2,12 @@ package db
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
 )
 
 type Repository interface {
@@ -14,4 +17,48 @@ type Repository interface {
 	Insert(ctx context.Context, ch changes.Change) error
 	Update(ctx context.Context, ch changes.Change) error
 	GetByParentChangeID(context.Context, changes.ID) (*changes.Change, error)
+
+	InsertPaths(ctx context.Context, paths ...changes.Path) error
+	// SetPathsIndexed marks the paths of the change as indexed, after they have been inserted
+	SetPathsIndexed(ctx context.Context, id changes.ID) error
+	// ListWithoutIndexedPaths lists landed changes whose paths have not been indexed, ordered by id, starting after the
+	// given id.
+	ListWithoutIndexedPaths(ctx context.Context, after *changes.ID, limit int) ([]*changes.Change, error)
+	ListRenames(ctx context.Context, codebaseID codebases.ID, filter PathFilter) ([]*Rename, error)
+	Search(ctx context.Context, codebaseID codebases.ID, opts SearchOptions) ([]*changes.Change, error)
+}
+
+// PathFilter matches changes that have changed Path, or any of the files in the directory Path.
+type PathFilter struct {
+	Path string
+	// ChangedBefore is used when following renames, the file had another name before it was renamed.
+	ChangedBefore *time.Time
+}
+
+// Matches returns true if path is the filtered path, or is in the filtered directory.
+func (f PathFilter) Matches(path string) bool {
+	return path == f.Path || strings.HasPrefix(path, f.Path+"/")
+}
+
+// Rename is a file that was renamed by a change.
+type Rename struct {
+	ChangeID  changes.ID `db:"change_id"`
+	Path      string     `db:"path"`
+	OldPath   string     `db:"old_path"`
+	ChangedAt time.Time  `db:"changed_at"`
+}
+
+// SearchOptions are used to filter the changelog. All options are optional, and changes must match all of the set ones.
+type SearchOptions struct {
+	// Paths matches changes that match any of the filters
+	Paths         []PathFilter
+	UserID        *users.ID
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Query is a full text search over the title and the description
+	Query *string
+
+	// Before is used for pagination, only changes that are older than Before are returned
+	Before *changes.ID
+	Limit  int
 }
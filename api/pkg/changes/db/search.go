@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+)
+
+func (r *repo) InsertPaths(ctx context.Context, paths ...changes.Path) error {
+	for _, path := range paths {
+		if _, err := r.db.NamedExecContext(ctx, `INSERT INTO change_paths
+			(change_id, codebase_id, path, old_path)
+			VALUES(:change_id, :codebase_id, :path, :old_path)
+			ON CONFLICT (change_id, path) DO NOTHING`, path); err != nil {
+			return fmt.Errorf("failed to insert path: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *repo) SetPathsIndexed(ctx context.Context, id changes.ID) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE changes SET paths_indexed_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) ListWithoutIndexedPaths(ctx context.Context, after *changes.ID, limit int) ([]*changes.Change, error) {
+	var res []*changes.Change
+	if err := r.db.SelectContext(ctx, &res, `
+		SELECT
+			id, codebase_id, title, updated_description, user_id, git_creator_name, git_creator_email, created_at, git_created_at, commit_id, parent_change_id, workspace_id
+		FROM
+			changes
+		WHERE
+			paths_indexed_at IS NULL
+			AND commit_id IS NOT NULL
+			AND ($1::TEXT IS NULL OR id > $1)
+		ORDER BY id
+		LIMIT $2
+	`, after, limit); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
+
+func (r *repo) ListRenames(ctx context.Context, codebaseID codebases.ID, filter PathFilter) ([]*Rename, error) {
+	var res []*Rename
+	if err := r.db.SelectContext(ctx, &res, `
+		SELECT
+			p.change_id, p.path, p.old_path, COALESCE(c.created_at, c.git_created_at) AS changed_at
+		FROM
+			change_paths p
+			JOIN changes c ON c.id = p.change_id
+		WHERE
+			p.codebase_id = $1
+			AND p.old_path IS NOT NULL
+			AND (p.path = $2 OR LEFT(p.path, LENGTH($2) + 1) = $2 || '/')
+			AND ($3::TIMESTAMP WITH TIME ZONE IS NULL OR COALESCE(c.created_at, c.git_created_at) < $3)
+	`, codebaseID, filter.Path, filter.ChangedBefore); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
+
+func (r *repo) Search(ctx context.Context, codebaseID codebases.ID, opts SearchOptions) ([]*changes.Change, error) {
+	args := []interface{}{codebaseID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := []string{"c.codebase_id = $1"}
+
+	if len(opts.Paths) > 0 {
+		var pathConditions []string
+		for _, filter := range opts.Paths {
+			path := arg(filter.Path)
+			condition := fmt.Sprintf("(p.path = %[1]s OR LEFT(p.path, LENGTH(%[1]s) + 1) = %[1]s || '/' OR p.old_path = %[1]s OR LEFT(p.old_path, LENGTH(%[1]s) + 1) = %[1]s || '/')", path)
+			if filter.ChangedBefore != nil {
+				condition = fmt.Sprintf("(%s AND COALESCE(c.created_at, c.git_created_at) < %s)", condition, arg(*filter.ChangedBefore))
+			}
+			pathConditions = append(pathConditions, condition)
+		}
+		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM change_paths p WHERE p.change_id = c.id AND (%s))", strings.Join(pathConditions, " OR ")))
+	}
+
+	if opts.UserID != nil {
+		where = append(where, "c.user_id = "+arg(*opts.UserID))
+	}
+
+	if opts.CreatedAfter != nil {
+		where = append(where, "COALESCE(c.created_at, c.git_created_at) >= "+arg(*opts.CreatedAfter))
+	}
+
+	if opts.CreatedBefore != nil {
+		where = append(where, "COALESCE(c.created_at, c.git_created_at) < "+arg(*opts.CreatedBefore))
+	}
+
+	if opts.Query != nil {
+		where = append(where, "to_tsvector('english', COALESCE(c.title, '') || ' ' || c.updated_description) @@ plainto_tsquery('english', "+arg(*opts.Query)+")")
+	}
+
+	if opts.Before != nil {
+		where = append(where, "(COALESCE(c.created_at, c.git_created_at), c.id) < (SELECT COALESCE(created_at, git_created_at), id FROM changes WHERE id = "+arg(*opts.Before)+")")
+	}
+
+	var res []*changes.Change
+	if err := r.db.SelectContext(ctx, &res, `
+		SELECT
+			c.id, c.codebase_id, c.title, c.updated_description, c.user_id, c.git_creator_name, c.git_creator_email, c.created_at, c.git_created_at, c.commit_id, c.parent_change_id, c.workspace_id
+		FROM
+			changes c
+		WHERE
+			`+strings.Join(where, " AND ")+`
+		ORDER BY
+			COALESCE(c.created_at, c.git_created_at) DESC, c.id DESC
+		LIMIT `+arg(opts.Limit), args...); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
@@ -3,6 +3,7 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/changes"
@@ -11,9 +12,9 @@ import (
 	db_comments "getsturdy.com/api/pkg/comments/db"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/users"
 	"getsturdy.com/api/vcs/executor"
 
-	"github.com/graph-gophers/graphql-go"
 	"go.uber.org/zap"
 )
 
@@ -77,14 +78,32 @@ func NewResolver(
 	}
 }
 
-func (r *ChangeRootResolver) InternalListChanges(ctx context.Context, codebaseID codebases.ID, limit int, before *graphql.ID) ([]resolvers.ChangeResolver, error) {
+func (r *ChangeRootResolver) InternalListChanges(ctx context.Context, codebaseID codebases.ID, limit int, input *resolvers.CodebaseChangesInput) ([]resolvers.ChangeResolver, error) {
 	var beforeChange *changes.ID
-	if before != nil {
-		changeID := changes.ID(*before)
-		beforeChange = &changeID
+	var filter service.ChangelogFilter
+	if input != nil {
+		if input.Before != nil {
+			changeID := changes.ID(*input.Before)
+			beforeChange = &changeID
+		}
+
+		filter.Path = input.Path
+		filter.Query = input.Search
+		if input.AuthorID != nil {
+			userID := users.ID(*input.AuthorID)
+			filter.UserID = &userID
+		}
+		if input.CreatedAfter != nil {
+			createdAfter := time.Unix(int64(*input.CreatedAfter), 0)
+			filter.CreatedAfter = &createdAfter
+		}
+		if input.CreatedBefore != nil {
+			createdBefore := time.Unix(int64(*input.CreatedBefore), 0)
+			filter.CreatedBefore = &createdBefore
+		}
 	}
 
-	changes, err := r.svc.Changelog(ctx, codebaseID, limit, beforeChange)
+	changes, err := r.svc.SearchChangelog(ctx, codebaseID, limit, beforeChange, filter)
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
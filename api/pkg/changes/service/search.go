@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/changes"
+	db_change "getsturdy.com/api/pkg/changes/db"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/vcs"
+
+	git "github.com/libgit2/git2go/v33"
+	"go.uber.org/zap"
+)
+
+// ChangelogFilter is used to search the changelog, changes must match all of the set fields.
+type ChangelogFilter struct {
+	// Path matches changes that changed the file, or any of the files in the directory. Renames are followed, so
+	// that changes made to a file before it got it's current name are also found.
+	Path          *string
+	UserID        *users.ID
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Query is a full text search over the titles and descriptions of the changes
+	Query *string
+}
+
+func (f ChangelogFilter) IsEmpty() bool {
+	return f.Path == nil && f.UserID == nil && f.CreatedAfter == nil && f.CreatedBefore == nil && f.Query == nil
+}
+
+// maxFollowedRenames is the max number of renamed paths to follow when searching the changelog by path
+const maxFollowedRenames = 100
+
+// SearchChangelog returns a list of changes for the given codebaseID that match the filter, in the descending order.
+//
+// Only changes that have been indexed (see IndexPaths) can be found by path.
+func (svc *Service) SearchChangelog(ctx context.Context, codebaseID codebases.ID, limit int, before *changes.ID, filter ChangelogFilter) ([]*changes.Change, error) {
+	if filter.IsEmpty() {
+		return svc.Changelog(ctx, codebaseID, limit, before)
+	}
+
+	opts := db_change.SearchOptions{
+		UserID:        filter.UserID,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Query:         filter.Query,
+		Before:        before,
+		Limit:         limit,
+	}
+
+	if filter.Path != nil {
+		if path := strings.Trim(*filter.Path, "/"); path != "" {
+			pathFilters, err := svc.followRenames(ctx, codebaseID, path)
+			if err != nil {
+				return nil, err
+			}
+			opts.Paths = pathFilters
+		}
+	}
+
+	res, err := svc.changeRepo.Search(ctx, codebaseID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search changes: %w", err)
+	}
+
+	return res, nil
+}
+
+// followRenames returns the filters to find all changes of path, including changes made under a previous name.
+func (svc *Service) followRenames(ctx context.Context, codebaseID codebases.ID, path string) ([]db_change.PathFilter, error) {
+	filters := []db_change.PathFilter{{Path: path}}
+	for i := 0; i < len(filters) && len(filters) < maxFollowedRenames; i++ {
+		renames, err := svc.changeRepo.ListRenames(ctx, codebaseID, filters[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to list renames: %w", err)
+		}
+		for _, rename := range renames {
+			if filters[i].Matches(rename.OldPath) {
+				// moved within the same directory
+				continue
+			}
+			changedAt := rename.ChangedAt
+			filters = append(filters, db_change.PathFilter{Path: rename.OldPath, ChangedBefore: &changedAt})
+		}
+	}
+	return filters, nil
+}
+
+// IndexPaths saves the paths that have been changed by the change, it's called when a change is landed or imported.
+func (svc *Service) IndexPaths(ctx context.Context, ch *changes.Change) error {
+	if ch.CommitID == nil {
+		return nil
+	}
+
+	var paths []changes.Path
+	if err := svc.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		details, err := repo.GetCommitDetails(*ch.CommitID)
+		if err != nil {
+			return fmt.Errorf("could not get commit details: %w", err)
+		}
+
+		var diff *git.Diff
+		if len(details.Parents) > 0 {
+			diff, err = repo.DiffCommits(details.Parents[0], *ch.CommitID)
+		} else {
+			diff, err = repo.DiffCommitToRoot(*ch.CommitID)
+		}
+		if err != nil {
+			return fmt.Errorf("could not get diff: %w", err)
+		}
+		defer diff.Free()
+
+		numDeltas, err := diff.NumDeltas()
+		if err != nil {
+			return fmt.Errorf("could not get number of deltas: %w", err)
+		}
+
+		for i := 0; i < numDeltas; i++ {
+			delta, err := diff.Delta(i)
+			if err != nil {
+				return fmt.Errorf("could not get delta: %w", err)
+			}
+			path := changes.Path{ChangeID: ch.ID, CodebaseID: ch.CodebaseID, Path: delta.NewFile.Path}
+			switch delta.Status {
+			case git.DeltaDeleted:
+				path.Path = delta.OldFile.Path
+			case git.DeltaRenamed:
+				oldPath := delta.OldFile.Path
+				path.OldPath = &oldPath
+			}
+			paths = append(paths, path)
+		}
+		return nil
+	}).ExecTrunk(ch.CodebaseID, "changeService.IndexPaths"); err != nil {
+		return err
+	}
+
+	if err := svc.changeRepo.InsertPaths(ctx, paths...); err != nil {
+		return fmt.Errorf("failed to save paths: %w", err)
+	}
+
+	if err := svc.changeRepo.SetPathsIndexed(ctx, ch.ID); err != nil {
+		return fmt.Errorf("failed to mark paths as indexed: %w", err)
+	}
+
+	return nil
+}
+
+// indexAllPathsBatchSize is the number of changes that are listed at a time by IndexAllPaths
+const indexAllPathsBatchSize = 100
+
+// IndexAllPaths indexes the paths of the changes that have not been indexed yet, such as the changes that were landed
+// before paths were indexed. Changes that can't be indexed are logged and skipped, and are retried on the next call.
+func (svc *Service) IndexAllPaths(ctx context.Context) error {
+	var after *changes.ID
+	for {
+		batch, err := svc.changeRepo.ListWithoutIndexedPaths(ctx, after, indexAllPathsBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		for _, ch := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := svc.IndexPaths(ctx, ch); err != nil {
+				svc.logger.Warn("failed to index paths of change", zap.String("change_id", string(ch.ID)), zap.Error(err))
+			}
+		}
+
+		if len(batch) < indexAllPathsBatchSize {
+			return nil
+		}
+		after = &batch[len(batch)-1].ID
+	}
+}
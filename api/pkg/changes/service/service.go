@@ -338,6 +338,11 @@ func (svc *Service) importCommitToChange(ctx context.Context, codebaseID codebas
 		return nil, fmt.Errorf("could not write new change to db: %w", err)
 	}
 
+	if err := svc.IndexPaths(ctx, &ch); err != nil {
+		svc.logger.Error("failed to index paths of imported change", zap.Error(err))
+		// don't fail
+	}
+
 	return &ch, nil
 }
 
@@ -4,22 +4,28 @@ import (
 	"context"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/dig"
 	"go.uber.org/zap"
 
 	module_api "getsturdy.com/api/pkg/api/module"
+	"getsturdy.com/api/pkg/changes"
+	db_change "getsturdy.com/api/pkg/changes/db"
 	"getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/configuration"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/internal/dbtest"
 	queue "getsturdy.com/api/pkg/queue/module"
+	"getsturdy.com/api/pkg/users"
 	"getsturdy.com/api/vcs"
 	"getsturdy.com/api/vcs/executor"
 	"getsturdy.com/api/vcs/provider"
+	"getsturdy.com/api/vcs/testutil"
 )
 
 func module(t *testing.T) di.Module {
@@ -174,3 +180,113 @@ func Test_Parent_Child_navigation(t *testing.T) {
 	_, firstChangeChildErr := svc.ChildChange(ctx, firstChange)
 	assert.Equal(t, firstChangeChildErr, service.ErrNotFound)
 }
+
+func TestSearchChangelog(t *testing.T) {
+	ctx := context.Background()
+	changeRepo := db_change.NewInMemoryRepo()
+	svc := service.New(changeRepo, nil, zap.NewNop(), nil, nil)
+
+	codebaseID := codebases.ID(uuid.NewString())
+	userID := users.ID(uuid.NewString())
+	start := time.Now().Add(-time.Hour)
+
+	create := func(title string, createdAt time.Time, paths ...changes.Path) *changes.Change {
+		ch := changes.Change{
+			ID:                 changes.ID(uuid.NewString()),
+			CodebaseID:         codebaseID,
+			Title:              &title,
+			UpdatedDescription: title,
+			UserID:             &userID,
+			CreatedAt:          &createdAt,
+		}
+		assert.NoError(t, changeRepo.Insert(ctx, ch))
+		for i := range paths {
+			paths[i].ChangeID = ch.ID
+			paths[i].CodebaseID = codebaseID
+		}
+		assert.NoError(t, changeRepo.InsertPaths(ctx, paths...))
+		return &ch
+	}
+	str := func(s string) *string { return &s }
+
+	createOld := create("create old", start, changes.Path{Path: "old/a.txt"})
+	updateOld := create("update old", start.Add(time.Minute), changes.Path{Path: "old/a.txt"}, changes.Path{Path: "other.txt"})
+	move := create("move to new", start.Add(2*time.Minute), changes.Path{Path: "new/a.txt", OldPath: str("old/a.txt")})
+	recreateOld := create("recreate old", start.Add(3*time.Minute), changes.Path{Path: "old/a.txt"})
+	updateNew := create("update new", start.Add(4*time.Minute), changes.Path{Path: "new/a.txt"})
+
+	ids := func(cc []*changes.Change) []changes.ID {
+		var res []changes.ID
+		for _, c := range cc {
+			res = append(res, c.ID)
+		}
+		return res
+	}
+
+	t.Run("path with renames", func(t *testing.T) {
+		res, err := svc.SearchChangelog(ctx, codebaseID, 10, nil, service.ChangelogFilter{Path: str("new")})
+		assert.NoError(t, err)
+		assert.Equal(t, []changes.ID{updateNew.ID, move.ID, updateOld.ID, createOld.ID}, ids(res))
+	})
+
+	t.Run("path with pagination", func(t *testing.T) {
+		res, err := svc.SearchChangelog(ctx, codebaseID, 2, &move.ID, service.ChangelogFilter{Path: str("/new/a.txt")})
+		assert.NoError(t, err)
+		assert.Equal(t, []changes.ID{updateOld.ID, createOld.ID}, ids(res))
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		after, before := start.Add(time.Minute), start.Add(3*time.Minute)
+		res, err := svc.SearchChangelog(ctx, codebaseID, 10, nil, service.ChangelogFilter{CreatedAfter: &after, CreatedBefore: &before})
+		assert.NoError(t, err)
+		assert.Equal(t, []changes.ID{move.ID, updateOld.ID}, ids(res))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		res, err := svc.SearchChangelog(ctx, codebaseID, 10, nil, service.ChangelogFilter{Query: str("Old")})
+		assert.NoError(t, err)
+		assert.Equal(t, []changes.ID{recreateOld.ID, updateOld.ID, createOld.ID}, ids(res))
+	})
+}
+
+func TestIndexAllPaths(t *testing.T) {
+	ctx := context.Background()
+	changeRepo := db_change.NewInMemoryRepo()
+	repoProvider := testutil.TestingRepoProvider(t)
+	executorProvider := executor.NewProvider(zap.NewNop(), repoProvider)
+	svc := service.New(changeRepo, nil, zap.NewNop(), executorProvider, nil)
+
+	codebaseID := codebases.ID(uuid.NewString())
+	_, err := vcs.CreateBareRepoWithRootCommit(repoProvider.TrunkPath(codebaseID))
+	require.NoError(t, err)
+
+	var commitID string
+	require.NoError(t, executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		commitID, err = repo.CreateCommitWithFiles([]vcs.FileContents{{Path: "a.txt", Contents: []byte("hello\n")}}, "sturdytrunk")
+		return err
+	}).ExecTrunk(codebaseID, "TestIndexAllPaths"))
+
+	// a change that was landed before paths were indexed, and one that can't be indexed
+	now := time.Now()
+	landed := changes.Change{ID: changes.ID(uuid.NewString()), CodebaseID: codebaseID, CommitID: &commitID, CreatedAt: &now}
+	missingCommitID := "0000000000000000000000000000000000000000"
+	missing := changes.Change{ID: changes.ID(uuid.NewString()), CodebaseID: codebaseID, CommitID: &missingCommitID, CreatedAt: &now}
+	require.NoError(t, changeRepo.Insert(ctx, landed))
+	require.NoError(t, changeRepo.Insert(ctx, missing))
+
+	require.NoError(t, svc.IndexAllPaths(ctx))
+
+	path := "a.txt"
+	res, err := svc.SearchChangelog(ctx, codebaseID, 10, nil, service.ChangelogFilter{Path: &path})
+	require.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, landed.ID, res[0].ID)
+	}
+
+	// the change that failed is retried
+	notIndexed, err := changeRepo.ListWithoutIndexedPaths(ctx, nil, 10)
+	require.NoError(t, err)
+	if assert.Len(t, notIndexed, 1) {
+		assert.Equal(t, missing.ID, notIndexed[0].ID)
+	}
+}
@@ -0,0 +1,13 @@
+package worker
+
+import (
+	service_changes "getsturdy.com/api/pkg/changes/service"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(service_changes.Module)
+	c.Register(New)
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+
+	service_changes "getsturdy.com/api/pkg/changes/service"
+
+	"go.uber.org/zap"
+)
+
+// Worker indexes the paths of the changes that were landed before paths were indexed, so that they can be found when
+// searching the changelog by path. It runs once when the api starts.
+type Worker struct {
+	logger        *zap.Logger
+	changeService *service_changes.Service
+}
+
+func New(
+	logger *zap.Logger,
+	changeService *service_changes.Service,
+) *Worker {
+	return &Worker{
+		logger:        logger.Named("change_paths_worker"),
+		changeService: changeService,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	w.logger.Info("starting")
+
+	if err := w.changeService.IndexAllPaths(ctx); err != nil && ctx.Err() == nil {
+		w.logger.Error("failed to index paths of changes", zap.Error(err))
+	}
+
+	w.logger.Info("done")
+	return nil
+}
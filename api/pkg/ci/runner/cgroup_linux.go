@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// cpuPeriod is the period of the cpu quota of a cgroup, in microseconds
+const cpuPeriod = 100000
+
+// newCgroup creates a cgroup for a step in parent, with the memory, process and cpu limits of the step. The
+// controllers are enabled in parent, so parent can't have any processes of its own.
+func newCgroup(parent string, limits Limits) (string, error) {
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +pids +cpu"), 0); err != nil {
+		return "", fmt.Errorf("failed to enable cgroup controllers: %w", err)
+	}
+
+	cgroup, err := os.MkdirTemp(parent, "step-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	files := map[string]string{}
+	if limits.MemoryLimit > 0 {
+		files["memory.max"] = fmt.Sprint(limits.MemoryLimit * 1024 * 1024)
+		// the limit can't be avoided by swapping
+		files["memory.swap.max"] = "0"
+	}
+	if limits.MaxProcesses > 0 {
+		files["pids.max"] = fmt.Sprint(limits.MaxProcesses)
+	}
+	if limits.CPUs > 0 {
+		files["cpu.max"] = fmt.Sprintf("%d %d", int(limits.CPUs*cpuPeriod), cpuPeriod)
+	}
+	for name, value := range files {
+		if err := os.WriteFile(filepath.Join(cgroup, name), []byte(value), 0); err != nil {
+			if name == "memory.swap.max" && errors.Is(err, os.ErrNotExist) {
+				// swap accounting is disabled
+				continue
+			}
+			removeCgroup(cgroup)
+			return "", fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+
+	return cgroup, nil
+}
+
+// removeCgroup kills the processes that are left in the cgroup, and removes it.
+func removeCgroup(cgroup string) {
+	// cgroup.kill is not supported by older kernels, the processes of the sandbox are killed with its init process
+	// anyway, so the cgroup is empty once they have exited
+	_ = os.WriteFile(filepath.Join(cgroup, "cgroup.kill"), []byte("1"), 0)
+
+	for i := 0; i < 100; i++ {
+		if err := syscall.Rmdir(cgroup); !errors.Is(err, syscall.EBUSY) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
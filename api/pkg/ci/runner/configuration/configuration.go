@@ -0,0 +1,30 @@
+package configuration
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/configuration/flags"
+)
+
+type Configuration struct {
+	Enabled     bool   `long:"enabled" description:"Run the pipelines of codebases with the runner integration on this server"`
+	ScratchPath string `long:"scratch-path" description:"Directory that runs are checked out to, the temporary directory is used if it's not set"`
+	Concurrency int    `long:"concurrency" description:"Maximum number of runs at the same time" default:"2"`
+	User        string `long:"user" description:"User that steps run as, required if the runner is enabled, the api must run as root to sandbox the steps"`
+
+	HiddenPaths []string `long:"hide-path" description:"Paths that are hidden from steps, they are replaced with empty directories" default:"/var/data" default:"/run" default:"/tmp"`
+	Network     bool     `long:"network" description:"Give steps access to the network of the host, including the local network"`
+	Cgroup      string   `long:"cgroup" description:"cgroup v2 directory that steps run in, each step gets a cgroup of its own in it that limits its memory, processes and cpus, for example /sys/fs/cgroup/sturdy-ci"`
+
+	StepTimeout     time.Duration `long:"step-timeout" description:"Timeout of steps that don't set one" default:"10m"`
+	MaxStepTimeout  time.Duration `long:"max-step-timeout" description:"Maximum timeout of a step" default:"1h"`
+	PipelineTimeout time.Duration `long:"pipeline-timeout" description:"Maximum duration of a run" default:"2h"`
+	MemoryLimit     int           `long:"memory-limit" description:"Maximum virtual memory of a step in megabytes (0 is unlimited)" default:"8192"`
+	MaxOpenFiles    int           `long:"max-open-files" description:"Maximum number of open files of a step (0 is unlimited)" default:"4096"`
+	MaxProcesses    int           `long:"max-processes" description:"Maximum number of processes of the runner user, and of a step if it runs in a cgroup (0 is unlimited)" default:"1024"`
+	CPUTime         time.Duration `long:"cpu-time" description:"Maximum cpu time of each process of a step (0 is unlimited)" default:"1h"`
+	CPUs            float64       `long:"cpus" description:"Maximum number of cpus of a step, if it runs in a cgroup (0 is unlimited)" default:"2"`
+
+	MaxLogSize int       `long:"max-log-size" description:"Maximum size of the log of a step in bytes" default:"10485760"`
+	LogsURL    flags.URL `long:"logs-url" description:"Base url of the api, used to link to the logs of steps" default:"http://127.0.0.1:3000/"`
+}
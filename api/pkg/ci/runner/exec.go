@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrTimeout = errors.New("timed out")
+
+// Limits are the limits of a step.
+type Limits struct {
+	Timeout time.Duration
+	// MemoryLimit is the maximum virtual memory of the step in megabytes, 0 is unlimited
+	MemoryLimit int
+	// MaxOpenFiles is the maximum number of open files of each process, 0 is unlimited
+	MaxOpenFiles int
+	// MaxProcesses is the maximum number of processes of the user that the step runs as, and of the cgroup of the
+	// step if it has one, 0 is unlimited
+	MaxProcesses int
+	// CPUTime is the maximum cpu time of each process, 0 is unlimited
+	CPUTime time.Duration
+	// CPUs is the maximum number of cpus that the step can use, if it has a cgroup, 0 is unlimited
+	CPUs float64
+	// Sandbox isolates the step, the step runs as the current user, without isolation, if it's nil
+	Sandbox *Sandbox
+}
+
+// prelude returns the commands that set the limits of the shell, and of the processes started by it. ulimit sets both
+// the soft and the hard limit, so the limits can't be raised by the step.
+func (l Limits) prelude() string {
+	var b strings.Builder
+	if l.MemoryLimit > 0 {
+		fmt.Fprintf(&b, "ulimit -v %d\n", l.MemoryLimit*1024)
+	}
+	if l.MaxOpenFiles > 0 {
+		fmt.Fprintf(&b, "ulimit -n %d\n", l.MaxOpenFiles)
+	}
+	if l.MaxProcesses > 0 {
+		// dash calls the limit -p
+		fmt.Fprintf(&b, "ulimit -u %d 2>/dev/null || ulimit -p %d\n", l.MaxProcesses, l.MaxProcesses)
+	}
+	if l.CPUTime > 0 {
+		// rounded up, a limit of 0 seconds is unlimited
+		fmt.Fprintf(&b, "ulimit -t %d\n", int((l.CPUTime+time.Second-1)/time.Second))
+	}
+	return b.String()
+}
+
+// Exec runs script with sh -e in dir, with only the given environment. The output is written to out.
+//
+// The step runs in a process group of its own, and in the sandbox if it's set. If the sandbox has a cgroup, the step
+// runs in a cgroup of its own in it, which is removed when the step exits. When the timeout is reached, or ctx is
+// canceled, the whole group is killed, and ErrTimeout or the error of ctx is returned. If the script fails, the
+// *exec.ExitError is returned.
+func Exec(ctx context.Context, script, dir string, env []string, limits Limits, out io.Writer) error {
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	var cmd *exec.Cmd
+	if limits.Sandbox != nil {
+		var cgroup string
+		if limits.Sandbox.Cgroup != "" {
+			var err error
+			if cgroup, err = newCgroup(limits.Sandbox.Cgroup, limits); err != nil {
+				return err
+			}
+			defer removeCgroup(cgroup)
+		}
+		var err error
+		if cmd, err = limits.Sandbox.command(limits.prelude()+script, dir, env, cgroup); err != nil {
+			return err
+		}
+	} else {
+		cmd = exec.Command("/bin/sh", "-e", "-c", limits.prelude()+script)
+		cmd.Dir = dir
+		// a nil environment would inherit the environment of the api
+		cmd.Env = append([]string{}, env...)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+
+	// the output is copied until all processes that have inherited it have exited, so background processes that are
+	// left behind by the step keep it running until it's killed
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrTimeout
+		}
+		return ctx.Err()
+	}
+}
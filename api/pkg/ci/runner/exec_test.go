@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExec(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	err := Exec(context.Background(), "echo \"$GREETING\"\npwd\necho error >&2", dir, []string{"GREETING=hello"}, Limits{Timeout: time.Minute}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n"+dir+"\nerror\n", out.String())
+}
+
+func TestExec_environment_is_not_inherited(t *testing.T) {
+	t.Setenv("STURDY_SECRET", "secret")
+
+	var out bytes.Buffer
+	require.NoError(t, Exec(context.Background(), "echo \"secret=${STURDY_SECRET:-}\"", t.TempDir(), nil, Limits{}, &out))
+	assert.Equal(t, "secret=\n", out.String())
+}
+
+func TestExec_failure(t *testing.T) {
+	var out bytes.Buffer
+	err := Exec(context.Background(), "false\necho unreachable", t.TempDir(), nil, Limits{}, &out)
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 1, exitErr.ExitCode())
+	assert.Empty(t, out.String())
+}
+
+func TestExec_timeout(t *testing.T) {
+	t0 := time.Now()
+	// the background process keeps the output open, it must be killed as well
+	err := Exec(context.Background(), "sleep 30 &\nsleep 30", t.TempDir(), nil, Limits{Timeout: 100 * time.Millisecond}, &bytes.Buffer{})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Less(t, time.Since(t0), 10*time.Second)
+}
+
+func TestExec_limits(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Exec(context.Background(), "ulimit -n", t.TempDir(), nil, Limits{MaxOpenFiles: 64}, &out))
+	assert.Equal(t, "64\n", out.String())
+
+	// the limit can't be raised by the step
+	err := Exec(context.Background(), "ulimit -n 128", t.TempDir(), nil, Limits{MaxOpenFiles: 64}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestExec_limits_memory(t *testing.T) {
+	// dd allocates a buffer of the block size
+	var out bytes.Buffer
+	err := Exec(context.Background(), "dd if=/dev/zero of=/dev/null bs=128M count=1", t.TempDir(), []string{"PATH=/usr/bin:/bin"}, Limits{MemoryLimit: 64}, &out)
+	assert.Error(t, err, out.String())
+
+	require.NoError(t, Exec(context.Background(), "dd if=/dev/zero of=/dev/null bs=16M count=1", t.TempDir(), []string{"PATH=/usr/bin:/bin"}, Limits{MemoryLimit: 64}, &bytes.Buffer{}))
+}
+
+func TestExec_limits_cpuTime(t *testing.T) {
+	t0 := time.Now()
+	err := Exec(context.Background(), "while :; do :; done", t.TempDir(), nil, Limits{CPUTime: time.Second, Timeout: time.Minute}, &bytes.Buffer{})
+
+	// the process is killed when it reaches the limit, long before the timeout
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), err)
+	assert.False(t, exitErr.Success())
+	assert.Less(t, time.Since(t0), 30*time.Second)
+}
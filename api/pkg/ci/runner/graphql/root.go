@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/ci/runner"
+	service_runner "getsturdy.com/api/pkg/ci/runner/service"
+	service_ci "getsturdy.com/api/pkg/ci/service"
+	"getsturdy.com/api/pkg/codebases"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/integrations"
+	"getsturdy.com/api/pkg/integrations/providers"
+
+	"github.com/google/uuid"
+)
+
+type rootResolver struct {
+	authService                    *service_auth.Service
+	runnerService                  *service_runner.Service
+	instantIntegrationService      *service_ci.Service
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver
+}
+
+func New(
+	authService *service_auth.Service,
+	runnerService *service_runner.Service,
+	instantIntegrationService *service_ci.Service,
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver,
+) resolvers.RunnerInstantIntegrationRootResolver {
+	return &rootResolver{
+		authService:                    authService,
+		runnerService:                  runnerService,
+		instantIntegrationService:      instantIntegrationService,
+		instantIntegrationRootResolver: instantIntegrationRootResolver,
+	}
+}
+
+func (r *rootResolver) CreateRunnerIntegration(ctx context.Context, args resolvers.CreateRunnerIntegrationArgs) (resolvers.IntegrationResolver, error) {
+	codebaseID := codebases.ID(args.Input.CodebaseID)
+	if err := r.authService.CanAdmin(ctx, &codebases.Codebase{ID: codebaseID}); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if !r.runnerService.Enabled() {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "the runner is not enabled on this server")
+	}
+
+	// the runner has no configuration, so a codebase has at most one runner integration
+	existing, err := r.instantIntegrationService.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	for _, integration := range existing {
+		if integration.Provider == providers.ProviderNameRunner {
+			return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+		}
+	}
+
+	integration := &integrations.Integration{
+		ID:           uuid.NewString(),
+		CodebaseID:   codebaseID,
+		Provider:     providers.ProviderNameRunner,
+		ProviderType: providers.ProviderTypeBuild,
+		SeedFiles:    runner.PipelinePaths,
+		CreatedAt:    time.Now(),
+	}
+	if err := r.instantIntegrationService.CreateIntegration(ctx, integration); err != nil {
+		return nil, gqlerrors.Error(fmt.Errorf("failed to create integration: %w", err))
+	}
+
+	return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+}
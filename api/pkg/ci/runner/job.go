@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+
+	"getsturdy.com/api/pkg/codebases"
+)
+
+// PipelineTitle is the title of the status of a run, the statuses of steps are prefixed with it.
+const PipelineTitle = "Sturdy CI"
+
+// CodeDir is the directory in the checkout of the ci commit that the contents of the commit are checked out to, and
+// that steps run in. It's the same directory that the download script of the ci commit extracts to.
+const CodeDir = "tmp_output"
+
+// Job is a run of the pipeline of a codebase.
+type Job struct {
+	ID            string       `json:"id"`
+	CodebaseID    codebases.ID `json:"codebase_id"`
+	IntegrationID string       `json:"integration_id"`
+	// CiRepoCommitSHA is the commit in the ci repository, it has the pipeline definition
+	CiRepoCommitSHA string `json:"ci_repo_commit_sha"`
+	// CommitSHA is the commit in trunk that is built, the statuses of the run are reported on it
+	CommitSHA string `json:"commit_sha"`
+	Title     string `json:"title"`
+}
+
+// Message is published to the ci trigger queue to run a job.
+type Message struct {
+	Run *Job `json:"run,omitempty"`
+}
+
+func StepTitle(step *Step) string {
+	return fmt.Sprintf("%s: %s", PipelineTitle, step.Name)
+}
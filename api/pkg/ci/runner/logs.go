@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/blobs"
+	"getsturdy.com/api/pkg/codebases"
+)
+
+// LogID returns the id of the blob with the log of a step. Logs are not public, they are served by a route that checks
+// that the user can read the codebase.
+func LogID(codebaseID codebases.ID, jobID string, step int) blobs.ID {
+	return blobs.ID(fmt.Sprintf("%s%s-%d.log", logPrefix(codebaseID), jobID, step))
+}
+
+// IsLogOf returns true if the blob is a log of a step of a run in the codebase.
+func IsLogOf(id blobs.ID, codebaseID codebases.ID) bool {
+	return strings.HasPrefix(string(id), logPrefix(codebaseID))
+}
+
+func logPrefix(codebaseID codebases.ID) string {
+	return fmt.Sprintf("%s%s-", blobs.CILogPrefix, codebaseID)
+}
+
+type Appender interface {
+	Append(context.Context, blobs.ID, []byte) error
+}
+
+// LogWriter is the log of a step. It's appended to a blob every interval, so that the log can be followed while the
+// step is running. At most maxSize bytes are kept, output above it is dropped.
+type LogWriter struct {
+	ctx      context.Context
+	appender Appender
+	id       blobs.ID
+	maxSize  int
+
+	guard     sync.Mutex
+	buf       bytes.Buffer
+	size      int
+	truncated bool
+	err       error
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewLogWriter(ctx context.Context, appender Appender, id blobs.ID, maxSize int, interval time.Duration) *LogWriter {
+	w := &LogWriter{
+		ctx:      ctx,
+		appender: appender,
+		id:       id,
+		maxSize:  maxSize,
+		stop:     make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.flush()
+			}
+		}
+	}()
+
+	return w
+}
+
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.guard.Lock()
+	defer w.guard.Unlock()
+
+	if w.truncated {
+		return len(p), nil
+	}
+
+	if w.maxSize > 0 && w.size+len(p) > w.maxSize {
+		w.buf.Write(p[:w.maxSize-w.size])
+		fmt.Fprintf(&w.buf, "\n[log truncated, it's larger than %d bytes]\n", w.maxSize)
+		w.size = w.maxSize
+		w.truncated = true
+		return len(p), nil
+	}
+
+	w.buf.Write(p)
+	w.size += len(p)
+	return len(p), nil
+}
+
+func (w *LogWriter) flush() {
+	w.guard.Lock()
+	if w.buf.Len() == 0 {
+		w.guard.Unlock()
+		return
+	}
+	data := make([]byte, w.buf.Len())
+	copy(data, w.buf.Bytes())
+	w.buf.Reset()
+	w.guard.Unlock()
+
+	if err := w.appender.Append(w.ctx, w.id, data); err != nil {
+		w.guard.Lock()
+		if w.err == nil {
+			w.err = fmt.Errorf("failed to append log: %w", err)
+		}
+		w.guard.Unlock()
+	}
+}
+
+// Close writes the rest of the log, and returns the first error that appending the log failed with.
+func (w *LogWriter) Close() error {
+	close(w.stop)
+	w.wg.Wait()
+	w.flush()
+
+	w.guard.Lock()
+	defer w.guard.Unlock()
+	return w.err
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/blobs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAppender struct {
+	guard   sync.Mutex
+	blobs   map[blobs.ID]*bytes.Buffer
+	appends int
+	err     error
+}
+
+func (m *memoryAppender) Append(_ context.Context, id blobs.ID, data []byte) error {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if m.blobs == nil {
+		m.blobs = map[blobs.ID]*bytes.Buffer{}
+	}
+	if m.blobs[id] == nil {
+		m.blobs[id] = &bytes.Buffer{}
+	}
+	m.blobs[id].Write(data)
+	m.appends++
+	return nil
+}
+
+func (m *memoryAppender) get(id blobs.ID) string {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+	if m.blobs[id] == nil {
+		return ""
+	}
+	return m.blobs[id].String()
+}
+
+func TestLogWriter(t *testing.T) {
+	appender := &memoryAppender{}
+	w := NewLogWriter(context.Background(), appender, "log", 0, time.Millisecond)
+
+	_, err := fmt.Fprintln(w, "first")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return appender.get("log") == "first\n" }, time.Second, time.Millisecond)
+
+	_, err = fmt.Fprintln(w, "second")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "first\nsecond\n", appender.get("log"))
+}
+
+func TestLogWriter_truncates(t *testing.T) {
+	appender := &memoryAppender{}
+	w := NewLogWriter(context.Background(), appender, "log", 10, time.Hour)
+
+	n, err := w.Write([]byte("0123456789abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 13, n)
+	_, err = w.Write([]byte("dropped"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	log := appender.get("log")
+	assert.True(t, strings.HasPrefix(log, "0123456789\n[log truncated"), log)
+	assert.NotContains(t, log, "dropped")
+}
+
+func TestLogWriter_error(t *testing.T) {
+	appender := &memoryAppender{err: errors.New("database is down")}
+	w := NewLogWriter(context.Background(), appender, "log", 0, time.Hour)
+
+	_, err := w.Write([]byte("output"))
+	require.NoError(t, err)
+	assert.Error(t, w.Close())
+}
+
+func TestLogID(t *testing.T) {
+	id := LogID("codebase", "job", 1)
+	assert.True(t, IsLogOf(id, "codebase"))
+	assert.False(t, IsLogOf(id, "other"))
+	assert.False(t, id.IsPublic(), "logs must not be served by the public blobs route")
+	assert.True(t, blobs.ID("avatar.png").IsPublic())
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PipelinePaths are the paths that the pipeline definition is read from, the first one that exists is used. They are
+// the seed files of the integration, so the definition is copied from trunk to the ci commit.
+var PipelinePaths = []string{
+	".sturdy/ci.yaml",
+	".sturdy/ci.yml",
+}
+
+const (
+	maxSteps          = 50
+	maxStepNameLength = 100
+)
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Pipeline is the definition of a pipeline, for example:
+//
+//	env:
+//	  GOFLAGS: -mod=readonly
+//	steps:
+//	  - name: test
+//	    run: go test ./...
+//	    timeout: 15m
+//
+// Steps run one after the other, in the directory with the contents of the commit. If a step fails, the following
+// steps are not run.
+type Pipeline struct {
+	Env   map[string]string `yaml:"env"`
+	Steps []*Step           `yaml:"steps"`
+}
+
+type Step struct {
+	Name string `yaml:"name"`
+	// Run is the script of the step, it's run with sh -e
+	Run string `yaml:"run"`
+	// Timeout is the maximum duration of the step, the default timeout of the runner is used if it's not set
+	Timeout time.Duration     `yaml:"timeout"`
+	Env     map[string]string `yaml:"env"`
+}
+
+// ParsePipeline parses and validates a pipeline definition.
+func ParsePipeline(data []byte) (*Pipeline, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	pipeline := &Pipeline{}
+	if err := decoder.Decode(pipeline); err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+
+	if err := pipeline.validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+	return pipeline, nil
+}
+
+func (p *Pipeline) validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("no steps")
+	}
+	if len(p.Steps) > maxSteps {
+		return fmt.Errorf("too many steps, at most %d are allowed", maxSteps)
+	}
+	if err := validateEnv(p.Env); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(p.Steps))
+	for i, step := range p.Steps {
+		if step == nil {
+			return fmt.Errorf("step %d: empty step", i+1)
+		}
+		step.Name = strings.TrimSpace(step.Name)
+		switch {
+		case step.Name == "":
+			return fmt.Errorf("step %d: name is required", i+1)
+		case len(step.Name) > maxStepNameLength:
+			return fmt.Errorf("step %d: name is longer than %d characters", i+1, maxStepNameLength)
+		case names[step.Name]:
+			return fmt.Errorf("step %d: duplicate name %q", i+1, step.Name)
+		case strings.TrimSpace(step.Run) == "":
+			return fmt.Errorf("step %q: run is required", step.Name)
+		case step.Timeout < 0:
+			return fmt.Errorf("step %q: timeout must not be negative", step.Name)
+		}
+		if err := validateEnv(step.Env); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		names[step.Name] = true
+	}
+	return nil
+}
+
+func validateEnv(env map[string]string) error {
+	for name := range env {
+		if !envNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	return nil
+}
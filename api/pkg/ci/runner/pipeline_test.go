@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipeline(t *testing.T) {
+	pipeline, err := ParsePipeline([]byte(`
+env:
+  GOFLAGS: -mod=readonly
+steps:
+  - name: " lint "
+    run: go vet ./...
+  - name: test
+    run: |
+      go build ./...
+      go test ./...
+    timeout: 15m
+    env:
+      CGO_ENABLED: "0"
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"GOFLAGS": "-mod=readonly"}, pipeline.Env)
+	require.Len(t, pipeline.Steps, 2)
+	assert.Equal(t, "lint", pipeline.Steps[0].Name)
+	assert.Equal(t, "go vet ./...", pipeline.Steps[0].Run)
+	assert.Equal(t, time.Duration(0), pipeline.Steps[0].Timeout)
+	assert.Equal(t, "test", pipeline.Steps[1].Name)
+	assert.Equal(t, "go build ./...\ngo test ./...\n", pipeline.Steps[1].Run)
+	assert.Equal(t, 15*time.Minute, pipeline.Steps[1].Timeout)
+	assert.Equal(t, map[string]string{"CGO_ENABLED": "0"}, pipeline.Steps[1].Env)
+}
+
+func TestParsePipeline_invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":           ``,
+		"no steps":        `steps: []`,
+		"unknown field":   "steps:\n  - name: test\n    command: make\n",
+		"missing name":    "steps:\n  - run: make\n",
+		"missing run":     "steps:\n  - name: test\n",
+		"duplicate name":  "steps:\n  - name: test\n    run: make\n  - name: test\n    run: make\n",
+		"invalid timeout": "steps:\n  - name: test\n    run: make\n    timeout: soon\n",
+		"invalid env":     "env:\n  NOT-VALID: 1\nsteps:\n  - name: test\n    run: make\n",
+	}
+	for name, definition := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePipeline([]byte(definition))
+			assert.Error(t, err)
+		})
+	}
+}
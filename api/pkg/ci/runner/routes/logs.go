@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/blobs"
+	service_blobs "getsturdy.com/api/pkg/blobs/service"
+	"getsturdy.com/api/pkg/ci/runner"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Log serves the log of a step of a run in the codebase. Only users that can read the codebase can read its logs.
+func Log(
+	logger *zap.Logger,
+	codebaseService *service_codebase.Service,
+	authService *service_auth.Service,
+	blobsService *service_blobs.Service,
+) func(c *gin.Context) {
+	logger = logger.Named("ciLogs")
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		cb, err := codebaseService.GetByID(ctx, codebases.ID(c.Param("id")))
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		} else if err != nil {
+			logger.Error("failed to get codebase", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := authService.CanRead(ctx, cb); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		logID := blobs.ID(c.Param("key"))
+		if !runner.IsLogOf(logID, cb.ID) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		blob, err := blobsService.Fetch(ctx, logID)
+		if errors.Is(err, service_blobs.ErrNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		} else if err != nil {
+			logger.Error("failed to fetch log", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", blob.Data)
+	}
+}
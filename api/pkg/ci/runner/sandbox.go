@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+)
+
+// sandboxArg is the argument that the api is started with, when it's started as the init process of a sandbox.
+const sandboxArg = "sturdy-ci-sandbox"
+
+// Sandbox isolates a step from the api, and from the host that it runs on.
+//
+// Steps run in namespaces of their own, as a user without privileges. They can't see the processes of the host, the
+// hidden paths are replaced with empty directories, and if the network is not enabled, they only have a loopback
+// interface of their own. When the step exits, or is killed, all processes that it started are killed with it.
+//
+// Sandboxes can only be created by root, on Linux.
+type Sandbox struct {
+	Uid uint32
+	Gid uint32
+
+	// HiddenPaths are replaced with empty directories in the sandbox, for example the data directory of the api
+	HiddenPaths []string
+	// VisiblePaths stay visible in the sandbox, even if they are in one of the hidden paths
+	VisiblePaths []string
+	// Network gives the step access to the network of the host, including the local network
+	Network bool
+	// Cgroup is a cgroup v2 directory, such as /sys/fs/cgroup/sturdy-ci. If it's set, each step runs in a cgroup of
+	// its own in it, that limits the memory, the processes and the cpus of the step.
+	Cgroup string
+}
+
+// sandboxSpec is passed to the init process of the sandbox on its stdin.
+type sandboxSpec struct {
+	Sandbox
+
+	Script string
+	Dir    string
+	Env    []string
+	// CgroupPath is the cgroup of the step, that the init process moves itself to
+	CgroupPath string
+}
+
+// SandboxMain runs the init process of a sandbox, if the process has been started as one. It must be called first
+// thing in main, and it does not return in the sandbox.
+func SandboxMain() {
+	if len(os.Args) < 2 || os.Args[1] != sandboxArg {
+		return
+	}
+	if err := runSandbox(); err != nil {
+		fmt.Fprintf(os.Stderr, "[Sturdy CI] Failed to start the sandbox: %s\n", err)
+		os.Exit(125)
+	}
+}
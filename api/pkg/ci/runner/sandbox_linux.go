@@ -0,0 +1,168 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// prSetNoNewPrivs is PR_SET_NO_NEW_PRIVS, it's not defined by syscall on all architectures
+const prSetNoNewPrivs = 38
+
+// command returns the command that starts the init process of the sandbox. The init process sets up the sandbox, and
+// replaces itself with sh, running the script. If cgroup is set, the init process moves itself to it first.
+func (s *Sandbox) command(script, dir string, env []string, cgroup string) (*exec.Cmd, error) {
+	spec, err := json.Marshal(&sandboxSpec{
+		Sandbox:    *s,
+		Script:     script,
+		Dir:        dir,
+		Env:        env,
+		CgroupPath: cgroup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sandbox: %w", err)
+	}
+
+	cloneFlags := syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+	if !s.Network {
+		cloneFlags |= syscall.CLONE_NEWNET
+	}
+
+	cmd := exec.Command("/proc/self/exe", sandboxArg)
+	cmd.Env = []string{}
+	cmd.Stdin = bytes.NewReader(spec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Cloneflags: uintptr(cloneFlags),
+		// don't leave steps running if the api dies
+		Pdeathsig: syscall.SIGKILL,
+	}
+	return cmd, nil
+}
+
+// runSandbox sets up the sandbox. It runs as root, as pid 1 of the namespaces that the sandbox was started in.
+func runSandbox() error {
+	// the namespaces and credentials belong to the thread
+	runtime.LockOSThread()
+
+	var spec sandboxSpec
+	if err := json.NewDecoder(os.Stdin).Decode(&spec); err != nil {
+		return fmt.Errorf("failed to decode sandbox: %w", err)
+	}
+	if _, err := io.Copy(io.Discard, os.Stdin); err != nil {
+		return fmt.Errorf("failed to read sandbox: %w", err)
+	}
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Dup3(int(devNull.Fd()), 0, 0); err != nil {
+		return fmt.Errorf("failed to replace stdin: %w", err)
+	}
+	devNull.Close()
+
+	// processes that are started by the step inherit the cgroup, "0" is the process that writes it
+	if spec.CgroupPath != "" {
+		if err := os.WriteFile(filepath.Join(spec.CgroupPath, "cgroup.procs"), []byte("0"), 0); err != nil {
+			return fmt.Errorf("failed to join cgroup: %w", err)
+		}
+	}
+
+	// don't propagate the mounts of the sandbox to the host
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+
+	// open the visible paths before they are hidden, so that they can be mounted back from the open files
+	visible := make([]*os.File, 0, len(spec.VisiblePaths))
+	for _, p := range spec.VisiblePaths {
+		f, err := os.Open(p)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", p, err)
+		}
+		defer f.Close()
+		visible = append(visible, f)
+	}
+
+	for _, p := range spec.HiddenPaths {
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			continue
+		}
+		if err := syscall.Mount("tmpfs", p, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777"); err != nil {
+			return fmt.Errorf("failed to hide %s: %w", p, err)
+		}
+	}
+
+	for i, f := range visible {
+		p := spec.VisiblePaths[i]
+		if err := os.MkdirAll(p, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", p, err)
+		}
+		if err := syscall.Mount(fmt.Sprintf("/proc/self/fd/%d", f.Fd()), p, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("failed to mount %s: %w", p, err)
+		}
+	}
+
+	// only show the processes of the sandbox
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("failed to mount /proc: %w", err)
+	}
+
+	if !spec.Network {
+		if err := loopbackUp(); err != nil {
+			return err
+		}
+	}
+
+	if err := syscall.Setgroups([]int{}); err != nil {
+		return fmt.Errorf("failed to drop groups: %w", err)
+	}
+	if err := syscall.Setgid(int(spec.Gid)); err != nil {
+		return fmt.Errorf("failed to set gid: %w", err)
+	}
+	if err := syscall.Setuid(int(spec.Uid)); err != nil {
+		return fmt.Errorf("failed to set uid: %w", err)
+	}
+	// setuid binaries, such as sudo, can't be used to get the privileges back
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+
+	if err := os.Chdir(spec.Dir); err != nil {
+		return fmt.Errorf("failed to change directory: %w", err)
+	}
+
+	// sh becomes pid 1 of the sandbox, when it exits, all processes in the sandbox are killed
+	return syscall.Exec("/bin/sh", []string{"/bin/sh", "-e", "-c", spec.Script}, spec.Env)
+}
+
+// loopbackUp brings up the loopback interface of the network namespace, it's down in new namespaces.
+func loopbackUp() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	var ifr struct {
+		name  [syscall.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(ifr.name[:], "lo")
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return fmt.Errorf("failed to get loopback flags: %w", errno)
+	}
+	ifr.flags |= syscall.IFF_UP
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return fmt.Errorf("failed to bring up loopback: %w", errno)
+	}
+	return nil
+}
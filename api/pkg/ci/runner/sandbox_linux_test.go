@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// the test binary is the init process of the sandboxes
+	SandboxMain()
+	os.Exit(m.Run())
+}
+
+const nobody = 65534
+
+// sandboxDir returns a directory that the sandbox user can use.
+func sandboxDir(t *testing.T) string {
+	dir := t.TempDir()
+	for p := dir; p != os.TempDir(); p = filepath.Dir(p) {
+		require.NoError(t, os.Chmod(p, 0o777))
+	}
+	return dir
+}
+
+func newSandbox(t *testing.T, dir string) *Sandbox {
+	if os.Geteuid() != 0 {
+		t.Skip("sandboxes can only be created by root")
+	}
+	sandbox := &Sandbox{Uid: nobody, Gid: nobody, VisiblePaths: []string{dir}}
+	if err := Exec(context.Background(), "true", dir, nil, Limits{Sandbox: sandbox}, &bytes.Buffer{}); err != nil {
+		t.Skipf("sandboxes are not supported: %s", err)
+	}
+	return sandbox
+}
+
+func TestExec_sandbox(t *testing.T) {
+	hidden := sandboxDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(hidden, "secret"), []byte("secret"), 0o644))
+	dir := filepath.Join(hidden, "run")
+	require.NoError(t, os.Mkdir(dir, 0o777))
+	require.NoError(t, os.Chmod(dir, 0o777))
+
+	sandbox := newSandbox(t, dir)
+	sandbox.HiddenPaths = []string{hidden}
+
+	var out bytes.Buffer
+	err := Exec(context.Background(), "id -u\npwd\nls -A "+hidden+"\necho ok > file", dir, []string{"PATH=/usr/bin:/bin"}, Limits{Sandbox: sandbox}, &out)
+	require.NoError(t, err, out.String())
+	assert.Equal(t, "65534\n"+dir+"\nrun\n", out.String(), "only the visible path is left of the hidden path")
+
+	contents, err := os.ReadFile(filepath.Join(dir, "file"))
+	require.NoError(t, err, "the visible path is writable")
+	assert.Equal(t, "ok\n", string(contents))
+}
+
+func TestExec_sandbox_network(t *testing.T) {
+	dir := sandboxDir(t)
+	sandbox := newSandbox(t, dir)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// the loopback interface of the sandbox is its own
+	script := "exec 3<>/dev/tcp/127.0.0.1/" + strconv.Itoa(port)
+	err = Exec(context.Background(), "bash -c '"+script+"'", dir, []string{"PATH=/usr/bin:/bin"}, Limits{Sandbox: sandbox}, &bytes.Buffer{})
+	assert.Error(t, err, "the network of the host can't be reached")
+}
+
+func TestExec_sandbox_limits_processes(t *testing.T) {
+	dir := sandboxDir(t)
+	sandbox := newSandbox(t, dir)
+
+	// the limit is enforced for the user that the step runs as, root is exempt from it
+	script := "for i in $(seq 32); do sleep 5 & done\nwait"
+	err := Exec(context.Background(), script, dir, []string{"PATH=/usr/bin:/bin"}, Limits{Sandbox: sandbox, MaxProcesses: 16, Timeout: time.Minute}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestExec_sandbox_cgroup(t *testing.T) {
+	dir := sandboxDir(t)
+	sandbox := newSandbox(t, dir)
+
+	controllers, err := os.ReadFile("/sys/fs/cgroup/cgroup.controllers")
+	if err != nil || !strings.Contains(string(controllers), "memory") || !strings.Contains(string(controllers), "pids") {
+		t.Skip("cgroup v2 with the memory and pids controllers is not available")
+	}
+	parent, err := os.MkdirTemp("/sys/fs/cgroup", "sturdy-ci-test-")
+	if err != nil {
+		t.Skipf("cgroups can't be created: %s", err)
+	}
+	t.Cleanup(func() { _ = syscall.Rmdir(parent) })
+	sandbox.Cgroup = parent
+
+	limits := Limits{Sandbox: sandbox, MemoryLimit: 64, MaxProcesses: 16, CPUs: 0.5, Timeout: time.Minute}
+	var out bytes.Buffer
+	script := "cgroup=/sys/fs/cgroup$(sed -n 's/^0:://p' /proc/self/cgroup)\ncat $cgroup/memory.max $cgroup/pids.max $cgroup/cpu.max"
+	require.NoError(t, Exec(context.Background(), script, dir, []string{"PATH=/usr/bin:/bin"}, limits, &out), out.String())
+	assert.Equal(t, "67108864\n16\n50000 100000\n", out.String())
+
+	// more processes than the limit can't be started
+	err = Exec(context.Background(), "for i in $(seq 32); do sleep 5 & done\nwait", dir, []string{"PATH=/usr/bin:/bin"}, limits, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	// the cgroups of the steps are removed when they exit
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, entry.IsDir(), "the cgroup %s is left behind", entry.Name())
+	}
+}
+
+func TestExec_sandbox_kills_daemons(t *testing.T) {
+	dir := sandboxDir(t)
+	sandbox := newSandbox(t, dir)
+
+	// the daemon leaves the process group of the step, and keeps running after the step has exited
+	err := Exec(context.Background(), "setsid sleep 300 </dev/null >/dev/null 2>&1 &\nsleep 1", dir, []string{"PATH=/usr/bin:/bin"}, Limits{Sandbox: sandbox, Timeout: time.Minute}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	// the daemon has a pid of the namespace of the sandbox, look for it by its command line instead
+	matches, err := filepath.Glob("/proc/[0-9]*/cmdline")
+	require.NoError(t, err)
+	for _, m := range matches {
+		cmdline, err := os.ReadFile(m)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		assert.NotEqual(t, "sleep\x00300\x00", string(cmdline), "the daemon must be killed with the step")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+// +build !linux
+
+package runner
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var errSandboxUnsupported = errors.New("sandboxes are only supported on linux")
+
+func (s *Sandbox) command(string, string, []string, string) (*exec.Cmd, error) {
+	return nil, errSandboxUnsupported
+}
+
+func newCgroup(string, Limits) (string, error) {
+	return "", errSandboxUnsupported
+}
+
+func removeCgroup(string) {}
+
+func runSandbox() error {
+	return errSandboxUnsupported
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	service_blobs "getsturdy.com/api/pkg/blobs/service"
+	configuration "getsturdy.com/api/pkg/configuration/module"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+	queue "getsturdy.com/api/pkg/queue/module"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
+	"getsturdy.com/api/vcs/executor"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(configuration.Module)
+	c.Import(executor.Module)
+	c.Import(service_statuses.Module)
+	c.Import(service_blobs.Module)
+	c.Import(queue.Module)
+	c.Register(New)
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"getsturdy.com/api/pkg/blobs"
+	service_blobs "getsturdy.com/api/pkg/blobs/service"
+	"getsturdy.com/api/pkg/ci/runner"
+	"getsturdy.com/api/pkg/ci/runner/configuration"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+	"getsturdy.com/api/pkg/statuses"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrDisabled = errors.New("the runner is not enabled")
+
+const (
+	logFlushInterval = 2 * time.Second
+	defaultPath      = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+)
+
+type Service struct {
+	logger           *zap.Logger
+	cfg              *configuration.Configuration
+	executorProvider executor.Provider
+	statusesService  *service_statuses.Service
+	blobsService     *service_blobs.Service
+	queue            queue.Queue
+
+	sandbox *runner.Sandbox
+	logsURL string
+	runs    chan struct{}
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *configuration.Configuration,
+	executorProvider executor.Provider,
+	statusesService *service_statuses.Service,
+	blobsService *service_blobs.Service,
+	queue queue.Queue,
+) (*Service, error) {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	s := &Service{
+		logger:           logger.Named("ciRunner"),
+		cfg:              cfg,
+		executorProvider: executorProvider,
+		statusesService:  statusesService,
+		blobsService:     blobsService,
+		queue:            queue,
+		runs:             make(chan struct{}, concurrency),
+	}
+
+	if !cfg.Enabled {
+		return s, nil
+	}
+
+	scheme, host := cfg.LogsURL.Scheme, cfg.LogsURL.Host
+	if (scheme == "" || host == "") && scheme+host != "" {
+		return nil, fmt.Errorf("invalid logs url: must provide either a full URL, or an absolute path")
+	} else if scheme != "" {
+		s.logsURL = fmt.Sprintf("%s://%s", scheme, host)
+	}
+	s.logsURL += path.Join(cfg.LogsURL.Path, "/v3/codebases")
+
+	// steps run code from the codebase, they must not be able to reach the data or the secrets of the api
+	if cfg.User == "" {
+		return nil, fmt.Errorf("the runner needs a dedicated user to run steps as, set --ci.runner.user")
+	}
+	if os.Geteuid() != 0 {
+		return nil, fmt.Errorf("the runner must run as root to run steps in a sandbox")
+	}
+	sandbox, err := lookupSandbox(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := runner.Exec(context.Background(), "true", os.TempDir(), nil, runner.Limits{Sandbox: sandbox, Timeout: time.Minute}, io.Discard); err != nil {
+		return nil, fmt.Errorf("failed to start a sandbox, the runner needs privileges to create namespaces and cgroups: %w", err)
+	}
+	s.sandbox = sandbox
+
+	return s, nil
+}
+
+func lookupSandbox(cfg *configuration.Configuration) (*runner.Sandbox, error) {
+	u, err := user.Lookup(cfg.User)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up runner user: %w", err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid: %w", err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid: %w", err)
+	}
+	if uid == 0 || gid == 0 {
+		return nil, fmt.Errorf("the runner user must not be root")
+	}
+	return &runner.Sandbox{
+		Uid:         uint32(uid),
+		Gid:         uint32(gid),
+		HiddenPaths: cfg.HiddenPaths,
+		Network:     cfg.Network,
+		Cgroup:      cfg.Cgroup,
+	}, nil
+}
+
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// Enqueue reports the run as pending, and publishes it to the ci trigger queue.
+func (s *Service) Enqueue(ctx context.Context, job *runner.Job) (*statuses.Status, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrDisabled
+	}
+
+	status, err := s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypePending, "Queued", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.queue.Publish(ctx, names.CITriggerQueue, &runner.Message{Run: job}); err != nil {
+		return nil, fmt.Errorf("failed to publish run: %w", err)
+	}
+
+	return status, nil
+}
+
+// Run checks out the ci commit and the commit that is built, and runs the steps of the pipeline. Every step is
+// reported as a status on the commit, with a link to its log. At most --ci.runner.concurrency jobs run at the same
+// time, Run waits until the job can start.
+//
+// An error is only returned if the job could not be run, failing steps and invalid pipelines are reported as failing
+// statuses.
+func (s *Service) Run(ctx context.Context, job *runner.Job) error {
+	if !s.cfg.Enabled {
+		return ErrDisabled
+	}
+
+	select {
+	case s.runs <- struct{}{}:
+		defer func() { <-s.runs }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// statuses and logs are reported with ctx, so that they are reported when the run has timed out
+	runCtx := ctx
+	if s.cfg.PipelineTimeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, s.cfg.PipelineTimeout)
+		defer cancel()
+	}
+
+	logger := s.logger.With(zap.String("job_id", job.ID), zap.Stringer("codebase_id", job.CodebaseID))
+	logger.Info("starting run")
+
+	dir, err := os.MkdirTemp(s.cfg.ScratchPath, "run-")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Error("failed to remove scratch directory", zap.Error(err))
+		}
+	}()
+
+	if _, err := s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypePending, "Checking out", nil); err != nil {
+		return err
+	}
+
+	if err := s.checkout(job, dir); err != nil {
+		if _, statusErr := s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypeFailing, "Failed to check out the commit", nil); statusErr != nil {
+			logger.Error("failed to set status", zap.Error(statusErr))
+		}
+		return fmt.Errorf("failed to check out: %w", err)
+	}
+
+	pipeline, err := loadPipeline(dir)
+	if err != nil {
+		_, err := s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypeFailing, err.Error(), nil)
+		return err
+	}
+
+	if err := chown(dir, s.sandbox); err != nil {
+		return fmt.Errorf("failed to change owner of scratch directory: %w", err)
+	}
+
+	logURLs := make([]string, len(pipeline.Steps))
+	for i, step := range pipeline.Steps {
+		logID := runner.LogID(job.CodebaseID, job.ID, i)
+		// create the log, so that the link works before the step has any output
+		if err := s.blobsService.Append(ctx, logID, []byte{}); err != nil {
+			return fmt.Errorf("failed to create log: %w", err)
+		}
+		logURLs[i] = s.logsURL + "/" + path.Join(job.CodebaseID.String(), "ci-logs", string(logID))
+		if _, err := s.setStatus(ctx, job, runner.StepTitle(step), statuses.TypePending, "Waiting", &logURLs[i]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypePending, "Running", nil); err != nil {
+		return err
+	}
+
+	t0 := time.Now()
+	failed := false
+	for i, step := range pipeline.Steps {
+		if failed {
+			if _, err := s.setStatus(ctx, job, runner.StepTitle(step), statuses.TypeFailing, "Skipped, an earlier step failed", nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		passed, err := s.runStep(ctx, runCtx, job, pipeline, i, dir, logURLs[i])
+		if err != nil {
+			return err
+		}
+		failed = !passed
+	}
+
+	if failed {
+		_, err = s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypeFailing, fmt.Sprintf("Failed after %s", since(t0)), nil)
+	} else {
+		_, err = s.setStatus(ctx, job, runner.PipelineTitle, statuses.TypeHealthy, fmt.Sprintf("Passed in %s", since(t0)), nil)
+	}
+	logger.Info("run completed", zap.Bool("failed", failed))
+	return err
+}
+
+func (s *Service) runStep(ctx, runCtx context.Context, job *runner.Job, pipeline *runner.Pipeline, index int, dir, logURL string) (bool, error) {
+	step := pipeline.Steps[index]
+	title := runner.StepTitle(step)
+	if _, err := s.setStatus(ctx, job, title, statuses.TypePending, "Running", &logURL); err != nil {
+		return false, err
+	}
+
+	log := runner.NewLogWriter(ctx, s.blobsService, blobs.ID(path.Base(logURL)), s.cfg.MaxLogSize, logFlushInterval)
+	fmt.Fprintf(log, "[Sturdy CI] Running %q\n", step.Name)
+
+	t0 := time.Now()
+	limits := s.limits(step, dir)
+	execErr := runner.Exec(runCtx, step.Run, filepath.Join(dir, runner.CodeDir), env(job, dir, pipeline, step), limits, log)
+
+	var exitErr *exec.ExitError
+	statusType, description, startFailed := statuses.TypeFailing, "", false
+	switch {
+	case execErr == nil:
+		statusType, description = statuses.TypeHealthy, fmt.Sprintf("Passed in %s", since(t0))
+	case runCtx.Err() != nil:
+		// the step is killed with ErrTimeout when the run times out as well
+		description = "The run was canceled or timed out"
+	case errors.Is(execErr, runner.ErrTimeout):
+		description = fmt.Sprintf("Timed out after %s", limits.Timeout)
+	case errors.As(execErr, &exitErr):
+		description = fmt.Sprintf("Failed after %s (%s)", since(t0), exitErr)
+	default:
+		description, startFailed = "Failed to start", true
+	}
+	fmt.Fprintf(log, "[Sturdy CI] %s\n", description)
+
+	if err := log.Close(); err != nil {
+		s.logger.Error("failed to write log", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	if _, err := s.setStatus(ctx, job, title, statusType, description, &logURL); err != nil {
+		return false, err
+	}
+
+	if startFailed {
+		return false, fmt.Errorf("failed to run step: %w", execErr)
+	}
+	return statusType == statuses.TypeHealthy, nil
+}
+
+func (s *Service) limits(step *runner.Step, dir string) runner.Limits {
+	timeout := s.cfg.StepTimeout
+	if step.Timeout > 0 {
+		timeout = step.Timeout
+	}
+	if s.cfg.MaxStepTimeout > 0 && (timeout <= 0 || timeout > s.cfg.MaxStepTimeout) {
+		timeout = s.cfg.MaxStepTimeout
+	}
+	limits := runner.Limits{
+		Timeout:      timeout,
+		MemoryLimit:  s.cfg.MemoryLimit,
+		MaxOpenFiles: s.cfg.MaxOpenFiles,
+		MaxProcesses: s.cfg.MaxProcesses,
+		CPUTime:      s.cfg.CPUTime,
+		CPUs:         s.cfg.CPUs,
+	}
+	if s.sandbox != nil {
+		sandbox := *s.sandbox
+		// the scratch directory can be in one of the hidden paths
+		sandbox.VisiblePaths = []string{dir}
+		limits.Sandbox = &sandbox
+	}
+	return limits
+}
+
+// checkout checks out the ci commit to dir, and the commit that is built to runner.CodeDir in it. The git directories
+// are removed, so that the steps can't reach the repositories of the codebase through them.
+func (s *Service) checkout(job *runner.Job, dir string) error {
+	if err := s.executorProvider.New().Read(func(repo vcs.RepoReader) error {
+		ciRepo, err := vcs.CloneRepo(repo.Path(), dir)
+		if err != nil {
+			return fmt.Errorf("failed to clone ci repository: %w", err)
+		}
+		if err := ciRepo.ResetHard(job.CiRepoCommitSHA); err != nil {
+			return fmt.Errorf("failed to checkout ci commit: %w", err)
+		}
+		return nil
+	}).ExecView(job.CodebaseID, "ci", "checkoutCiCommit"); err != nil {
+		return err
+	}
+
+	codeDir := filepath.Join(dir, runner.CodeDir)
+	if err := s.executorProvider.New().Read(func(repo vcs.RepoReader) error {
+		codeRepo, err := vcs.CloneRepo(repo.Path(), codeDir)
+		if err != nil {
+			return fmt.Errorf("failed to clone trunk: %w", err)
+		}
+		if err := codeRepo.ResetHard(job.CommitSHA); err != nil {
+			return fmt.Errorf("failed to checkout commit: %w", err)
+		}
+		return nil
+	}).ExecTrunk(job.CodebaseID, "checkoutCommit"); err != nil {
+		return err
+	}
+
+	for _, gitDir := range []string{filepath.Join(dir, ".git"), filepath.Join(codeDir, ".git")} {
+		if err := os.RemoveAll(gitDir); err != nil {
+			return fmt.Errorf("failed to remove git directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadPipeline loads the pipeline definition from the checkout of the ci commit. The returned errors are shown to
+// users.
+func loadPipeline(dir string) (*runner.Pipeline, error) {
+	for _, p := range runner.PipelinePaths {
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("Failed to read %s", p)
+		}
+
+		pipeline, err := runner.ParsePipeline(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", p, err)
+		}
+		return pipeline, nil
+	}
+	return nil, fmt.Errorf("No pipeline found, add one to %s", runner.PipelinePaths[0])
+}
+
+func chown(dir string, sandbox *runner.Sandbox) error {
+	return filepath.Walk(dir, func(p string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, int(sandbox.Uid), int(sandbox.Gid))
+	})
+}
+
+// env returns the environment of a step. The environment of the api is not passed on, as it has its secrets.
+func env(job *runner.Job, dir string, pipeline *runner.Pipeline, step *runner.Step) []string {
+	pathEnv := os.Getenv("PATH")
+	if pathEnv == "" {
+		pathEnv = defaultPath
+	}
+
+	vars := map[string]string{
+		"PATH":               pathEnv,
+		"HOME":               dir,
+		"CI":                 "true",
+		"STURDY_CI":          "true",
+		"STURDY_CODEBASE_ID": job.CodebaseID.String(),
+		"STURDY_COMMIT_SHA":  job.CommitSHA,
+		"STURDY_TITLE":       job.Title,
+	}
+	for k, v := range pipeline.Env {
+		vars[k] = v
+	}
+	for k, v := range step.Env {
+		vars[k] = v
+	}
+
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+func since(t time.Time) time.Duration {
+	return time.Since(t).Round(time.Second)
+}
+
+func (s *Service) setStatus(ctx context.Context, job *runner.Job, title string, statusType statuses.Type, description string, detailsURL *string) (*statuses.Status, error) {
+	status := &statuses.Status{
+		ID:          uuid.NewString(),
+		CommitSHA:   job.CommitSHA,
+		CodebaseID:  job.CodebaseID,
+		Type:        statusType,
+		Title:       title,
+		DetailsURL:  detailsURL,
+		Description: &description,
+		Timestamp:   time.Now(),
+	}
+	if err := s.statusesService.Set(ctx, status); err != nil {
+		return nil, fmt.Errorf("failed to set status: %w", err)
+	}
+	return status, nil
+}
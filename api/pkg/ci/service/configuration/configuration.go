@@ -1,5 +1,7 @@
 package configuration
 
 type Configuration struct {
-	PublicAPIHostname string `long:"public-api-hostname" description:"Public API hostname. Used to fetch codebases from CI"`
+	PublicAPIHostname    string `long:"public-api-hostname" description:"Public API hostname. Used to fetch codebases from CI"`
+	PublicGitURL         string `long:"public-git-url" description:"Public url of the git server. Sent to CI systems that are triggered by webhooks"`
+	AllowPrivateWebhooks bool   `long:"allow-private-webhooks" description:"Allow webhooks and GitLab pipeline triggers to be sent to loopback, link-local and private addresses, for CI systems in the same network as Sturdy"`
 }
@@ -3,10 +3,13 @@ package service
 import (
 	service_buildkite "getsturdy.com/api/pkg/buildkite/service/module"
 	db_ci "getsturdy.com/api/pkg/ci/db"
+	service_runner "getsturdy.com/api/pkg/ci/runner/service"
 	configuration "getsturdy.com/api/pkg/configuration/module"
 	"getsturdy.com/api/pkg/di"
 	service_github "getsturdy.com/api/pkg/github/service/module"
+	service_gitlab "getsturdy.com/api/pkg/gitlab/service/module"
 	db_integrations "getsturdy.com/api/pkg/integrations/db"
+	service_webhook "getsturdy.com/api/pkg/integrations/webhook/service"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/logger"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
@@ -25,5 +28,8 @@ func Module(c *di.Container) {
 	c.Import(service_snapshots.Module)
 	c.Import(service_buildkite.Module)
 	c.Import(service_github.Module)
+	c.Import(service_gitlab.Module)
+	c.Import(service_webhook.Module)
+	c.Import(service_runner.Module)
 	c.Register(New)
 }
@@ -15,12 +15,17 @@ import (
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/ci"
 	db_ci "getsturdy.com/api/pkg/ci/db"
+	"getsturdy.com/api/pkg/ci/runner"
+	service_runner "getsturdy.com/api/pkg/ci/runner/service"
 	"getsturdy.com/api/pkg/ci/service/configuration"
 	"getsturdy.com/api/pkg/codebases"
 	service_github "getsturdy.com/api/pkg/github/service"
+	service_gitlab "getsturdy.com/api/pkg/gitlab/service"
 	"getsturdy.com/api/pkg/integrations"
 	db_integrations "getsturdy.com/api/pkg/integrations/db"
 	"getsturdy.com/api/pkg/integrations/providers"
+	"getsturdy.com/api/pkg/integrations/webhook"
+	service_webhook "getsturdy.com/api/pkg/integrations/webhook/service"
 	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/snapshots"
@@ -38,6 +43,8 @@ import (
 
 const (
 	oneDay = 24 * time.Hour
+	// webhookTokenTTL is the lifetime of the token that is sent in webhook payloads
+	webhookTokenTTL = time.Hour
 )
 
 // TODO: refactor to have a more generic trigger method
@@ -50,8 +57,12 @@ type Service struct {
 
 	buildkiteService service_buildkite.Service
 	githubService    service_github.Service
+	gitlabService    service_gitlab.Service
+	webhookService   *service_webhook.Service
+	runnerService    *service_runner.Service
 
 	publicApiHostname string
+	publicGitURL      string
 	statusService     *svc_statuses.Service
 	jwtService        *service_jwt.Service
 	snapshotter       *service_snaphsotter.Service
@@ -66,6 +77,9 @@ func New(
 
 	buildkiteService service_buildkite.Service,
 	githubService service_github.Service,
+	gitlabService service_gitlab.Service,
+	webhookService *service_webhook.Service,
+	runnerService *service_runner.Service,
 
 	cfg *configuration.Configuration,
 	statusService *svc_statuses.Service,
@@ -81,8 +95,12 @@ func New(
 
 		buildkiteService: buildkiteService,
 		githubService:    githubService,
+		gitlabService:    gitlabService,
+		webhookService:   webhookService,
+		runnerService:    runnerService,
 
 		publicApiHostname: cfg.PublicAPIHostname,
+		publicGitURL:      cfg.PublicGitURL,
 		statusService:     statusService,
 		jwtService:        jwtService,
 		snapshotter:       snapshotter,
@@ -424,6 +442,67 @@ func (svc *Service) TriggerWorkspace(ctx context.Context, workspace *workspaces.
 
 			ss = append(ss, status)
 
+		case providers.ProviderNameGitLab:
+			build, err := svc.gitlabService.CreateBuild(ctx, config.ID, commitID, workspace.NameOrFallback())
+			if err != nil {
+				return nil, fmt.Errorf("failed to trigger gitlab pipeline: %w", err)
+			}
+
+			status := &statuses.Status{
+				ID:          uuid.NewString(),
+				CommitSHA:   snapshot.CommitSHA,
+				CodebaseID:  snapshot.CodebaseID,
+				Type:        statuses.TypePending,
+				Title:       build.Name,
+				Description: build.Description,
+				DetailsURL:  &build.URL,
+				Timestamp:   time.Now(),
+			}
+
+			if err := svc.statusService.Set(ctx, status); err != nil {
+				return nil, fmt.Errorf("failed to set status: %w", err)
+			}
+
+			ss = append(ss, status)
+
+		case providers.ProviderNameRunner:
+			status, err := svc.runnerService.Enqueue(ctx, &runner.Job{
+				ID:              uuid.NewString(),
+				CodebaseID:      snapshot.CodebaseID,
+				IntegrationID:   config.ID,
+				CiRepoCommitSHA: commitID,
+				CommitSHA:       snapshot.CommitSHA,
+				Title:           workspace.NameOrFallback(),
+			})
+			switch {
+			case errors.Is(err, service_runner.ErrDisabled):
+				svc.logger.Warn("the codebase has a runner integration, but the runner is not enabled", zap.Stringer("codebase_id", snapshot.CodebaseID))
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("failed to enqueue run: %w", err)
+			}
+
+			ss = append(ss, status)
+
+		case providers.ProviderNameWebhook:
+			// the ci system reports statuses through the statuses api
+			jwt, err := svc.jwtService.IssueToken(ctx, workspace.ID, webhookTokenTTL, jwt.TokenTypeCI)
+			if err != nil {
+				return nil, fmt.Errorf("failed to issue token: %w", err)
+			}
+			if _, err := svc.webhookService.Enqueue(ctx, config.ID, &webhook.Payload{
+				Event:       webhook.EventBuildRequested,
+				CodebaseID:  workspace.CodebaseID,
+				WorkspaceID: &workspace.ID,
+				SnapshotID:  &snapshot.ID,
+				Title:       workspace.NameOrFallback(),
+				GitURL:      svc.publicGitURL,
+				CommitSHA:   commitID,
+				JWT:         jwt.Token,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to enqueue webhook build: %w", err)
+			}
+
 		default:
 			return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 		}
@@ -499,6 +578,63 @@ func (svc *Service) TriggerChange(ctx context.Context, ch *changes.Change, opts
 			}
 
 			ss = append(ss, status)
+		case providers.ProviderNameGitLab:
+			build, err := svc.gitlabService.CreateBuild(ctx, config.ID, commitID, title)
+			if err != nil {
+				return nil, fmt.Errorf("failed to trigger gitlab pipeline: %w", err)
+			}
+
+			status := &statuses.Status{
+				ID:          uuid.NewString(),
+				CommitSHA:   *ch.CommitID,
+				CodebaseID:  ch.CodebaseID,
+				Type:        statuses.TypePending,
+				Title:       build.Name,
+				Description: build.Description,
+				DetailsURL:  &build.URL,
+				Timestamp:   time.Now(),
+			}
+
+			if err := svc.statusService.Set(ctx, status); err != nil {
+				return nil, fmt.Errorf("failed to set status: %w", err)
+			}
+
+			ss = append(ss, status)
+		case providers.ProviderNameRunner:
+			status, err := svc.runnerService.Enqueue(ctx, &runner.Job{
+				ID:              uuid.NewString(),
+				CodebaseID:      ch.CodebaseID,
+				IntegrationID:   config.ID,
+				CiRepoCommitSHA: commitID,
+				CommitSHA:       *ch.CommitID,
+				Title:           title,
+			})
+			switch {
+			case errors.Is(err, service_runner.ErrDisabled):
+				svc.logger.Warn("the codebase has a runner integration, but the runner is not enabled", zap.Stringer("codebase_id", ch.CodebaseID))
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("failed to enqueue run: %w", err)
+			}
+
+			ss = append(ss, status)
+		case providers.ProviderNameWebhook:
+			// the ci system reports statuses through the statuses api
+			jwt, err := svc.jwtService.IssueToken(ctx, string(ch.ID), webhookTokenTTL, jwt.TokenTypeCI)
+			if err != nil {
+				return nil, fmt.Errorf("failed to issue token: %w", err)
+			}
+			if _, err := svc.webhookService.Enqueue(ctx, config.ID, &webhook.Payload{
+				Event:      webhook.EventBuildRequested,
+				CodebaseID: ch.CodebaseID,
+				ChangeID:   &ch.ID,
+				Title:      title,
+				GitURL:     svc.publicGitURL,
+				CommitSHA:  commitID,
+				JWT:        jwt.Token,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to enqueue webhook build: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 		}
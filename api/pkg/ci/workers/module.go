@@ -1,6 +1,7 @@
 package workers
 
 import (
+	service_runner "getsturdy.com/api/pkg/ci/runner/service"
 	service_ci "getsturdy.com/api/pkg/ci/service"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/logger"
@@ -11,5 +12,6 @@ func Module(c *di.Container) {
 	c.Import(logger.Module)
 	c.Import(queue.Module)
 	c.Import(service_ci.Module)
+	c.Import(service_runner.Module)
 	c.Register(New)
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/ci/runner"
+	service_runner "getsturdy.com/api/pkg/ci/runner/service"
 	service_ci "getsturdy.com/api/pkg/ci/service"
 	"getsturdy.com/api/pkg/queue"
 	"getsturdy.com/api/pkg/queue/names"
@@ -19,18 +21,26 @@ type BuildQueue struct {
 	queue queue.Queue
 	name  names.IncompleteQueueName
 
-	ciService *service_ci.Service
+	ciService     *service_ci.Service
+	runnerService *service_runner.Service
 }
 
-func New(logger *zap.Logger, queue queue.Queue, ciService *service_ci.Service) *BuildQueue {
+func New(logger *zap.Logger, queue queue.Queue, ciService *service_ci.Service, runnerService *service_runner.Service) *BuildQueue {
 	return &BuildQueue{
-		logger:    logger.Named("ciRunnerQueue"),
-		queue:     queue,
-		name:      names.CITriggerQueue,
-		ciService: ciService,
+		logger:        logger.Named("ciRunnerQueue"),
+		queue:         queue,
+		name:          names.CITriggerQueue,
+		ciService:     ciService,
+		runnerService: runnerService,
 	}
 }
 
+// message is a message on the queue, it's either a change to trigger builds for, or a job of the runner.
+type message struct {
+	*changes.Change
+	runner.Message
+}
+
 func (r *BuildQueue) EnqueueChange(ctx context.Context, ch *changes.Change) error {
 	if err := r.queue.Publish(ctx, r.name, ch); err != nil {
 		return fmt.Errorf("failed to publish to queue: %w", err)
@@ -48,13 +58,19 @@ func (r *BuildQueue) Start(ctx context.Context) error {
 			}
 		}()
 		for msg := range messages {
-			ch := &changes.Change{}
-			if err := msg.As(ch); err != nil {
+			m := &message{Change: &changes.Change{}}
+			if err := msg.As(m); err != nil {
 				r.logger.Error("failed to decode message", zap.Error(err), zap.Any("message", msg))
 				continue
 			}
 
-			if err := r.trigger(ctx, ch); err != nil {
+			if m.Run != nil {
+				// runs can take a long time, don't block the queue while they are running
+				go r.run(ctx, m.Run, msg)
+				continue
+			}
+
+			if err := r.trigger(ctx, m.Change); err != nil {
 				r.logger.Error("failed to build", zap.Error(err), zap.Any("message", msg))
 				continue
 			}
@@ -88,3 +104,21 @@ func (r *BuildQueue) trigger(ctx context.Context, ch *changes.Change) error {
 
 	return nil
 }
+
+// run runs a job of the runner. The message is acked even if the run fails, the failure is reported as a status.
+func (r *BuildQueue) run(ctx context.Context, job *runner.Job, msg queue.Message) {
+	logger := r.logger.With(zap.String("job_id", job.ID), zap.Stringer("codebase_id", job.CodebaseID))
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Error("panic in run", zap.String("panic", fmt.Sprintf("%v", rec)))
+		}
+	}()
+
+	if err := r.runnerService.Run(ctx, job); err != nil {
+		logger.Error("failed to run", zap.Error(err))
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.Error("failed to ack message", zap.Error(err))
+	}
+}
@@ -0,0 +1,44 @@
+package acl
+
+// CodeOwners is a set of principals that own a set of files. Changes to owned files can only be landed after
+// they have been approved by at least one of the owners.
+type CodeOwners struct {
+	ID         string        `json:"id,omitempty"`
+	Principals []*Identifier `json:"principals,omitempty"`
+	Resources  []*Identifier `json:"resources,omitempty"`
+}
+
+// Owns returns true if the file at path is owned by the code owners.
+func (o *CodeOwners) Owns(path string, groups []*Group) bool {
+	file := Identity{Type: Files, ID: path}
+	for _, r := range resolveGroups(o.Resources, groups) {
+		if r.Matches(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes returns true if principal is one of the code owners.
+func (o *CodeOwners) Includes(principal Identity, groups []*Group) bool {
+	for _, p := range resolveGroups(o.Principals, groups) {
+		if p.Matches(principal) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeOwnersOf returns the code owners that own at least one of the paths.
+func (p Policy) CodeOwnersOf(paths ...string) []*CodeOwners {
+	var owners []*CodeOwners
+	for _, o := range p.CodeOwners {
+		for _, path := range paths {
+			if o.Owns(path, p.Groups) {
+				owners = append(owners, o)
+				break
+			}
+		}
+	}
+	return owners
+}
@@ -3,6 +3,7 @@ package graphql
 import (
 	"context"
 
+	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/codebases/acl"
 	"getsturdy.com/api/pkg/codebases/acl/access"
@@ -66,6 +67,10 @@ func (r *ACLRootResolver) InternalACLByCodebaseID(ctx context.Context, codebaseI
 }
 
 func (r *ACLRootResolver) UpdateACL(ctx context.Context, args resolvers.UpdateACLArgs) (resolvers.ACLResolver, error) {
+	if _, err := auth.AdminUserID(ctx); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
 	a, err := r.aclProvider.GetByCodebaseID(ctx, codebases.ID(args.Input.CodebaseID))
 	if err != nil {
 		return nil, gqlerrors.Error(err)
@@ -30,6 +30,14 @@ func (i *Identifier) MarshalJSON() ([]byte, error) {
 	return json.Marshal(fmt.Sprintf("%s::%s", i.Type, i.Pattern))
 }
 
+// String returns the identifier in the same format as it's written in the policy.
+func (i *Identifier) String() string {
+	if i.Type == Users || i.Type == "" {
+		return i.Pattern
+	}
+	return fmt.Sprintf("%s::%s", i.Type, i.Pattern)
+}
+
 // UnmarshalJSON implements encoding/json.UnmarshalJSON to parse source JSON in a different way.
 func (i *Identifier) UnmarshalJSON(v []byte) error {
 	s := new(string)
@@ -5,9 +5,10 @@ import (
 )
 
 type Policy struct {
-	Rules  []*Rule  `json:"rules,omitempty"`
-	Groups []*Group `json:"groups,omitempty"`
-	Tests  []*Test  `json:"tests,omitempty"`
+	Rules      []*Rule       `json:"rules,omitempty"`
+	Groups     []*Group      `json:"groups,omitempty"`
+	Tests      []*Test       `json:"tests,omitempty"`
+	CodeOwners []*CodeOwners `json:"codeowners,omitempty"`
 }
 
 // List return a list of _typ_ resources that _principal_ can _action_ on.
@@ -20,7 +21,7 @@ type Policy struct {
 func (p Policy) List(principal Identity, action Action, typ identityType) []string {
 	allowedPatterns := []string{}
 	for _, rule := range p.Rules {
-		if rule.Action != action {
+		if !rule.Action.Grants(action) {
 			continue
 		}
 
@@ -46,6 +47,7 @@ func (p Policy) List(principal Identity, action Action, typ identityType) []stri
 	return allowedPatterns
 }
 
+// Assert returns true if _principal_ can _action_ on _resource_.
 func (p Policy) Assert(principal Identity, action Action, resource Identity) bool {
 	for _, acl := range p.Rules {
 		if acl.Assert(principal, action, resource, p.Groups) {
@@ -55,6 +57,24 @@ func (p Policy) Assert(principal Identity, action Action, resource Identity) boo
 	return false
 }
 
+// Restricts returns true if there is at least one rule that grants exactly _action_ on resources of type _typ_.
+//
+// Actions that were added after the policies were first introduced (read, land and review on codebases) are only
+// enforced when the policy restricts them, so that existing policies keep working.
+func (p Policy) Restricts(action Action, typ identityType) bool {
+	for _, rule := range p.Rules {
+		if rule.Action != action {
+			continue
+		}
+		for _, resource := range resolveGroups(rule.Resources, p.Groups) {
+			if resource.Type == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var (
 	ErrTestFails               = fmt.Errorf("test fails")
 	ErrSubgroupsForbidden      = fmt.Errorf("groups can't have other groups as memebers")
@@ -62,7 +82,7 @@ var (
 	ErrTestMustHaveCondition   = fmt.Errorf("test must have either 'allow' or 'deny' condition")
 	ErrUnsupportedActionType   = fmt.Errorf("unsupported action type")
 	ErrACLTestMissing          = func(id string) error {
-		return fmt.Errorf("at least one 'allow write' test must exist for 'acls::%s' resource", id)
+		return fmt.Errorf("at least one 'allow write' or 'allow admin' test must exist for 'acls::%s' resource", id)
 	}
 )
 
@@ -77,19 +97,23 @@ func (p Policy) Errors(aclID string) map[string]error {
 			errs[fmt.Sprintf("tests[\"%s\"]", test.ID)] = ErrTestMustHaveCondition
 		}
 
-		if test.Resource.Type == ACLs && test.Allow != nil && test.Resource.ID == aclID {
+		if test.Resource.Type == ACLs && test.Allow != nil && test.Allow.Grants(ActionWrite) && test.Resource.ID == aclID {
 			aclTest = test
 		}
 
 		// tests must pass
-		if test.Allow != nil && *test.Allow == ActionWrite {
-			if !p.Assert(test.Principal, *test.Allow, test.Resource) {
+		if test.Allow != nil {
+			if !test.Allow.IsValid() {
+				errs[fmt.Sprintf("tests[\"%s\"].allow", test.ID)] = ErrUnsupportedActionType
+			} else if !p.Assert(test.Principal, *test.Allow, test.Resource) {
 				errs[fmt.Sprintf("tests[\"%s\"]", test.ID)] = ErrTestFails
 			}
 		}
 
 		if test.Deny != nil {
-			if p.Assert(test.Principal, *test.Deny, test.Resource) {
+			if !test.Deny.IsValid() {
+				errs[fmt.Sprintf("tests[\"%s\"].deny", test.ID)] = ErrUnsupportedActionType
+			} else if p.Assert(test.Principal, *test.Deny, test.Resource) {
 				errs[fmt.Sprintf("tests[\"%s\"]", test.ID)] = ErrTestFails
 			}
 		}
@@ -133,6 +157,23 @@ func (p Policy) Errors(aclID string) map[string]error {
 		}
 	}
 
+	// code owners are users or groups of users, and they can only own files
+	for _, owners := range p.CodeOwners {
+		for _, p := range owners.Principals {
+			if p.Type != Users && p.Type != Groups {
+				bytes, _ := p.MarshalJSON()
+				errs[fmt.Sprintf("codeowners[\"%s\"].principals[%s]", owners.ID, string(bytes))] = ErrUnsupportedIdentityType
+			}
+		}
+
+		for _, r := range owners.Resources {
+			if r.Type != Files && r.Type != Groups {
+				bytes, _ := r.MarshalJSON()
+				errs[fmt.Sprintf("codeowners[\"%s\"].resources[%s]", owners.ID, string(bytes))] = ErrUnsupportedIdentityType
+			}
+		}
+	}
+
 	return errs
 }
 
@@ -173,7 +214,7 @@ type Rule struct {
 }
 
 func (a *Rule) Assert(principal Identity, action Action, resource Identity, groups []*Group) bool {
-	if a.Action != action {
+	if !a.Action.Grants(action) {
 		return false
 	}
 	return a.assertPrincipal(principal, groups) && a.assertResource(resource, groups)
@@ -199,12 +240,41 @@ func (a *Rule) assertResource(resource Identity, groups []*Group) bool {
 
 type Action string
 
-var supportedActions = map[Action]bool{ActionWrite: true}
+var supportedActions = map[Action]bool{
+	ActionRead:   true,
+	ActionWrite:  true,
+	ActionLand:   true,
+	ActionReview: true,
+	ActionAdmin:  true,
+}
 
 func (a Action) IsValid() bool {
 	return supportedActions[a]
 }
 
+// Grants returns true if a rule with action _a_ allows _action_. Admin allows everything, and write allows read.
+func (a Action) Grants(action Action) bool {
+	switch {
+	case a == action:
+		return true
+	case a == ActionAdmin:
+		return supportedActions[action]
+	case a == ActionWrite:
+		return action == ActionRead
+	default:
+		return false
+	}
+}
+
 const (
+	// ActionRead allows to see files in diffs, downloads and the file browser, and to see codebases
+	ActionRead Action = "read"
+	// ActionWrite allows to make changes to files, workspaces in codebases and acls
 	ActionWrite Action = "write"
+	// ActionLand allows to land changes to codebases
+	ActionLand Action = "land"
+	// ActionReview allows to approve or reject changes in codebases
+	ActionReview Action = "review"
+	// ActionAdmin allows everything, and to change the settings, integrations and required checks of codebases
+	ActionAdmin Action = "admin"
 )
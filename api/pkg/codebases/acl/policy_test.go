@@ -398,3 +398,214 @@ func Test_Policy_Errors_test_group_contains_invalid_resource(t *testing.T) {
 		assert.ErrorIs(t, errs["groups[\"test\"].members[\"invalid\"]"], ErrUnsupportedIdentityType)
 	}
 }
+
+func Test_Policy_write_grants_read(t *testing.T) {
+	p := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "everyone can write all files",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Users, Pattern: "*"}},
+				Resources:  []*Identifier{{Type: Files, Pattern: "*"}},
+			},
+		},
+	}
+
+	user := Identity{Type: Users, ID: "user-1"}
+	file := Identity{Type: Files, ID: "README.md"}
+	assert.True(t, p.Assert(user, ActionRead, file))
+	assert.True(t, p.Assert(user, ActionWrite, file))
+	assert.False(t, p.Assert(user, ActionLand, file))
+	assert.False(t, p.Assert(user, ActionReview, file))
+	assert.False(t, p.Assert(user, ActionAdmin, file))
+	assert.Equal(t, []string{"*"}, p.List(user, ActionRead, Files))
+}
+
+func Test_Policy_admin_grants_everything(t *testing.T) {
+	p := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "user-1 is admin of codebase-1",
+				Action:     ActionAdmin,
+				Principals: []*Identifier{{Type: Users, Pattern: "user-1"}},
+				Resources:  []*Identifier{{Type: Codebases, Pattern: "codebase-1"}},
+			},
+		},
+	}
+
+	user := Identity{Type: Users, ID: "user-1"}
+	codebase := Identity{Type: Codebases, ID: "codebase-1"}
+	for action := range supportedActions {
+		assert.True(t, p.Assert(user, action, codebase), action)
+	}
+	assert.False(t, p.Assert(user, Action("delete"), codebase))
+}
+
+func Test_Policy_Restricts(t *testing.T) {
+	p := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "releasers can land",
+				Action:     ActionLand,
+				Principals: []*Identifier{{Type: Groups, Pattern: "releasers"}},
+				Resources:  []*Identifier{{Type: Codebases, Pattern: "*"}},
+			},
+			adminsCanWriteACLsRule,
+		},
+	}
+
+	assert.True(t, p.Restricts(ActionLand, Codebases))
+	assert.False(t, p.Restricts(ActionLand, Files))
+	assert.False(t, p.Restricts(ActionReview, Codebases))
+	assert.False(t, p.Restricts(ActionRead, ACLs))
+	assert.True(t, p.Restricts(ActionWrite, ACLs))
+}
+
+func Test_Policy_Errors_new_actions(t *testing.T) {
+	actionRead, actionLand, actionReview := ActionRead, ActionLand, ActionReview
+	p := Policy{
+		Rules: []*Rule{
+			adminsCanWriteACLsRule,
+			{
+				ID:         "contractors can read codebase-1",
+				Action:     ActionRead,
+				Principals: []*Identifier{{Type: Groups, Pattern: "contractors"}},
+				Resources:  []*Identifier{{Type: Codebases, Pattern: "codebase-1"}},
+			},
+			{
+				ID:         "admins can land codebase-1",
+				Action:     ActionLand,
+				Principals: []*Identifier{{Type: Groups, Pattern: "admins"}},
+				Resources:  []*Identifier{{Type: Codebases, Pattern: "codebase-1"}},
+			},
+		},
+		Groups: []*Group{
+			adminsGroup,
+			{ID: "contractors", Members: []*Identifier{{Type: Users, Pattern: "user-2"}}},
+		},
+		Tests: []*Test{
+			adminsCanWriteACLsTest,
+			{
+				ID:        "user-2 can read codebase-1",
+				Principal: Identity{Type: Users, ID: "user-2"},
+				Allow:     &actionRead,
+				Resource:  Identity{Type: Codebases, ID: "codebase-1"},
+			},
+			{
+				ID:        "user-2 can not land codebase-1",
+				Principal: Identity{Type: Users, ID: "user-2"},
+				Deny:      &actionLand,
+				Resource:  Identity{Type: Codebases, ID: "codebase-1"},
+			},
+			{
+				ID:        "user-1 can land codebase-1",
+				Principal: Identity{Type: Users, ID: "user-1"},
+				Allow:     &actionLand,
+				Resource:  Identity{Type: Codebases, ID: "codebase-1"},
+			},
+			{
+				ID:        "user-1 can review codebase-1",
+				Principal: Identity{Type: Users, ID: "user-1"},
+				Allow:     &actionReview,
+				Resource:  Identity{Type: Codebases, ID: "codebase-1"},
+			},
+		},
+	}
+
+	if errs := p.Errors(aclID); assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs["tests[\"user-1 can review codebase-1\"]"], ErrTestFails)
+	}
+}
+
+func Test_Policy_Errors_admin_acl_test(t *testing.T) {
+	actionAdmin := ActionAdmin
+	p := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "admins can administer acls",
+				Action:     ActionAdmin,
+				Principals: []*Identifier{{Type: Groups, Pattern: "admins"}},
+				Resources:  []*Identifier{{Type: ACLs, Pattern: "*"}},
+			},
+		},
+		Groups: []*Group{adminsGroup},
+		Tests: []*Test{
+			{
+				ID:        "user-1 can administer acls",
+				Principal: Identity{Type: Users, ID: "user-1"},
+				Allow:     &actionAdmin,
+				Resource:  Identity{Type: ACLs, ID: aclID},
+			},
+		},
+	}
+
+	assert.Len(t, p.Errors(aclID), 0)
+}
+
+func Test_Policy_Errors_unsupported_test_action(t *testing.T) {
+	actionDelete := Action("delete")
+	p := Policy{
+		Rules:  []*Rule{adminsCanWriteACLsRule},
+		Groups: []*Group{adminsGroup},
+		Tests: []*Test{
+			adminsCanWriteACLsTest,
+			{
+				ID:        "user-1 can delete codebase-1",
+				Principal: Identity{Type: Users, ID: "user-1"},
+				Allow:     &actionDelete,
+				Resource:  Identity{Type: Codebases, ID: "codebase-1"},
+			},
+		},
+	}
+
+	if errs := p.Errors(aclID); assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs["tests[\"user-1 can delete codebase-1\"].allow"], ErrUnsupportedActionType)
+	}
+}
+
+func Test_Policy_CodeOwnersOf(t *testing.T) {
+	backend := &CodeOwners{
+		ID:         "backend owns the api",
+		Principals: []*Identifier{{Type: Groups, Pattern: "backend"}},
+		Resources:  []*Identifier{{Type: Files, Pattern: "api/*"}},
+	}
+	frontend := &CodeOwners{
+		ID:         "user-3 owns the web",
+		Principals: []*Identifier{{Type: Users, Pattern: "user-3"}},
+		Resources:  []*Identifier{{Type: Files, Pattern: "web/*"}},
+	}
+	p := Policy{
+		Groups: []*Group{
+			{ID: "backend", Members: []*Identifier{{Type: Users, Pattern: "user-1"}, {Type: Users, Pattern: "user-2"}}},
+		},
+		CodeOwners: []*CodeOwners{backend, frontend},
+	}
+
+	assert.Equal(t, []*CodeOwners{backend}, p.CodeOwnersOf("api/main.go", "README.md"))
+	assert.Equal(t, []*CodeOwners{backend, frontend}, p.CodeOwnersOf("web/index.html", "api/main.go"))
+	assert.Empty(t, p.CodeOwnersOf("README.md"))
+
+	assert.True(t, backend.Includes(Identity{Type: Users, ID: "user-2"}, p.Groups))
+	assert.False(t, backend.Includes(Identity{Type: Users, ID: "user-3"}, p.Groups))
+	assert.True(t, frontend.Includes(Identity{Type: Users, ID: "user-3"}, p.Groups))
+}
+
+func Test_Policy_Errors_code_owners_invalid_identities(t *testing.T) {
+	p := Policy{
+		Rules:  []*Rule{adminsCanWriteACLsRule},
+		Groups: []*Group{adminsGroup},
+		Tests:  []*Test{adminsCanWriteACLsTest},
+		CodeOwners: []*CodeOwners{
+			{
+				ID:         "codebase owns files",
+				Principals: []*Identifier{{Type: Codebases, Pattern: "*"}},
+				Resources:  []*Identifier{{Type: ACLs, Pattern: "*"}},
+			},
+		},
+	}
+
+	if errs := p.Errors(aclID); assert.Len(t, errs, 2) {
+		assert.ErrorIs(t, errs["codeowners[\"codebase owns files\"].principals[\"codebases::*\"]"], ErrUnsupportedIdentityType)
+		assert.ErrorIs(t, errs["codeowners[\"codebase owns files\"].resources[\"acls::*\"]"], ErrUnsupportedIdentityType)
+	}
+}
@@ -1,15 +1,19 @@
 package provider
 
 import (
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
 	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
 	service_users "getsturdy.com/api/pkg/users/service/module"
 )
 
 func Module(c *di.Container) {
+	c.Import(logger.Module)
 	c.Import(db_acl.Module)
 	c.Import(db_codebases.Module)
 	c.Import(service_users.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 }
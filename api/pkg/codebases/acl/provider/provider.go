@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"time"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/codebases/acl"
 	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
@@ -16,23 +18,30 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/tailscale/hujson"
+	"go.uber.org/zap"
 )
 
 type Provider struct {
+	logger         *zap.Logger
 	aclDB          db_acl.ACLRepository
 	codebaseUserDB db_codebases.CodebaseUserRepository
 	usersService   service_users.Service
+	auditService   *service_audit.Service
 }
 
 func New(
+	logger *zap.Logger,
 	aclRepo db_acl.ACLRepository,
 	codebaseUserDB db_codebases.CodebaseUserRepository,
 	usersService service_users.Service,
+	auditService *service_audit.Service,
 ) *Provider {
 	return &Provider{
+		logger:         logger,
 		aclDB:          aclRepo,
 		codebaseUserDB: codebaseUserDB,
 		usersService:   usersService,
+		auditService:   auditService,
 	}
 }
 
@@ -107,5 +116,26 @@ func (p *Provider) getUserEmailsForCodebase(ctx context.Context, codebaseID code
 }
 
 func (p *Provider) Update(ctx context.Context, a acl.ACL) error {
-	return p.aclDB.Update(ctx, a)
+	previous, err := p.aclDB.GetByCodebaseID(ctx, a.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get acl: %w", err)
+	}
+
+	if err := p.aclDB.Update(ctx, a); err != nil {
+		return err
+	}
+
+	if err := p.auditService.Record(ctx, &audit.Entry{
+		CodebaseID: &a.CodebaseID,
+		Action:     audit.ActionACLUpdated,
+		TargetType: audit.TargetACL,
+		TargetID:   string(a.ID),
+		Before:     audit.Metadata{"policy": previous.RawPolicy},
+		After:      audit.Metadata{"policy": a.RawPolicy},
+	}); err != nil {
+		p.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
+	return nil
 }
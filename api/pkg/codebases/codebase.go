@@ -7,6 +7,7 @@ import (
 	"getsturdy.com/api/pkg/users"
 
 	"github.com/gosimple/slug"
+	"github.com/lib/pq"
 )
 
 type ShortCodebaseID string
@@ -35,6 +36,9 @@ type Codebase struct {
 	IsReady              bool `json:"is_ready" db:"is_ready"`
 	IsPublic             bool `json:"is_public" db:"is_public"`
 	RequireHealthyStatus bool `json:"-" db:"require_healthy_status"`
+	// RequiredStatusChecks are the titles, or globs of titles, of the statuses that must be healthy before a workspace
+	// can be landed. If it's empty, and RequireHealthyStatus is set, all statuses must be healthy.
+	RequiredStatusChecks pq.StringArray `json:"-" db:"required_status_checks"`
 
 	// Use through ChangeService.HeadChange()
 	CalculatedHeadChangeID bool    `json:"-" db:"calculated_head_change_id"`
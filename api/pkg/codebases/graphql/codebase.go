@@ -24,6 +24,7 @@ import (
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	service_organization "getsturdy.com/api/pkg/organization/service"
 	service_remote "getsturdy.com/api/pkg/remote/service"
+	"getsturdy.com/api/pkg/statuses"
 	"getsturdy.com/api/pkg/users"
 	db_user "getsturdy.com/api/pkg/users/db"
 	"getsturdy.com/api/pkg/views"
@@ -197,12 +198,12 @@ func (r *CodebaseRootResolver) CreateCodebase(ctx context.Context, args resolver
 		o := string(*args.Input.OrganizationID)
 		orgID = &o
 
-		// Verify access to organization
+		// Verify access to organization, the role of the user is checked when the codebase is created
 		org, err := r.organizationService.GetByID(ctx, o)
 		if err != nil {
 			return nil, gqlerrors.Error(err)
 		}
-		if err := r.authService.CanWrite(ctx, org); err != nil {
+		if err := r.authService.CanRead(ctx, org); err != nil {
 			return nil, gqlerrors.Error(err)
 		}
 	}
@@ -272,7 +273,7 @@ func (r *CodebaseRootResolver) UpdateCodebase(ctx context.Context, args resolver
 	}
 
 	// Auth
-	if err := r.authService.CanWrite(ctx, cb); err != nil {
+	if err := r.authService.CanAdmin(ctx, cb); err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -304,6 +305,21 @@ func (r *CodebaseRootResolver) UpdateCodebase(ctx context.Context, args resolver
 	if args.Input.RequireHealthyStatus != nil {
 		cb.RequireHealthyStatus = *args.Input.RequireHealthyStatus
 	}
+	if args.Input.RequiredStatusChecks != nil {
+		checks := make([]string, 0, len(*args.Input.RequiredStatusChecks))
+		seen := make(map[string]bool, len(*args.Input.RequiredStatusChecks))
+		for _, check := range *args.Input.RequiredStatusChecks {
+			check = strings.TrimSpace(check)
+			if err := statuses.ValidateCheckPattern(check); err != nil {
+				return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "requiredStatusChecks", err.Error())
+			}
+			if !seen[check] {
+				seen[check] = true
+				checks = append(checks, check)
+			}
+		}
+		cb.RequiredStatusChecks = checks
+	}
 
 	if err := r.codebaseService.Update(ctx, cb); err != nil {
 		return nil, gqlerrors.Error(fmt.Errorf("failed to update codebase: %w", err))
@@ -584,17 +600,17 @@ func (r *CodebaseResolver) ACL(ctx context.Context) (resolvers.ACLResolver, erro
 func (r *CodebaseResolver) Changes(ctx context.Context, args *resolvers.CodebaseChangesArgs) ([]resolvers.ChangeResolver, error) {
 	const defaultLimit int = 100
 	var (
-		limit  = defaultLimit
-		before *graphql.ID
+		limit = defaultLimit
+		input *resolvers.CodebaseChangesInput
 	)
 	if args != nil && args.Input != nil {
 		if args.Input.Limit != nil && *args.Input.Limit <= 100 {
 			limit = int(*args.Input.Limit)
 		}
 
-		before = args.Input.Before
+		input = args.Input
 	}
-	return r.root.changeRootResolver.InternalListChanges(ctx, r.c.ID, limit, before)
+	return r.root.changeRootResolver.InternalListChanges(ctx, r.c.ID, limit, input)
 }
 
 func (r *CodebaseResolver) Readme(ctx context.Context) (resolvers.FileResolver, error) {
@@ -671,6 +687,13 @@ func (r *CodebaseResolver) RequireHealthyStatus() bool {
 	return r.c.RequireHealthyStatus
 }
 
+func (r *CodebaseResolver) RequiredStatusChecks() []string {
+	if r.c.RequiredStatusChecks == nil {
+		return []string{}
+	}
+	return r.c.RequiredStatusChecks
+}
+
 func (r *CodebaseResolver) Writeable(ctx context.Context) bool {
 	if err := r.root.authService.CanWrite(ctx, r.c); err == nil {
 		return true
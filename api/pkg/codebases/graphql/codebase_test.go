@@ -7,6 +7,9 @@ import (
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
+	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	db_codebase "getsturdy.com/api/pkg/codebases/db"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/graphql/resolvers"
@@ -21,8 +24,10 @@ import (
 func TestCodebaseAccess(t *testing.T) {
 	codebaseRepo := db_codebase.NewMemory()
 	codebaseUserRepo := db_codebase.NewInMemoryCodebaseUserRepo()
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
-	authService := service_auth.New(codebaseService, nil, nil, nil, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	aclRepo := db_acl.NewInMemoryAclRepo()
+	aclProvider := provider_acl.New(zap.NewNop(), aclRepo, codebaseUserRepo, nil, nil)
+	authService := service_auth.New(codebaseService, nil, nil, nil, aclProvider, nil, nil, nil, nil, nil)
 	resolver := NewCodebaseRootResolver(
 		codebaseRepo,
 		codebaseUserRepo,
@@ -57,6 +62,10 @@ func TestCodebaseAccess(t *testing.T) {
 	publicCodebase := codebases.Codebase{ID: codebases.ID(uuid.NewString()), ShortCodebaseID: "short-public", IsPublic: true}
 	assert.NoError(t, codebaseRepo.Create(publicCodebase))
 
+	for _, cb := range []codebases.Codebase{privateCodebase, publicCodebase} {
+		assert.NoError(t, aclRepo.Create(context.Background(), acl.ACL{ID: acl.ID(uuid.NewString()), CodebaseID: cb.ID, RawPolicy: "{}"}))
+	}
+
 	userID := users.ID(uuid.NewString())
 
 	// Add member to both codebases
@@ -3,11 +3,13 @@ package service
 import (
 	sender_notifications "getsturdy.com/api/pkg/activity/sender"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/events"
 	"getsturdy.com/api/pkg/logger"
+	service_organization "getsturdy.com/api/pkg/organization/service"
 	service_users "getsturdy.com/api/pkg/users/service/module"
 	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
 	"getsturdy.com/api/vcs/executor"
@@ -23,5 +25,7 @@ func Module(c *di.Container) {
 	c.Import(service_analytics.Module)
 	c.Import(service_changes.Module)
 	c.Import(sender_notifications.Module)
+	c.Import(service_organization.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 }
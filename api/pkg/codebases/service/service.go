@@ -12,6 +12,9 @@ import (
 
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/auth"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
@@ -19,6 +22,8 @@ import (
 	"getsturdy.com/api/pkg/events"
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/notification/sender"
+	"getsturdy.com/api/pkg/organization"
+	service_organization "getsturdy.com/api/pkg/organization/service"
 	"getsturdy.com/api/pkg/shortid"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
@@ -30,8 +35,9 @@ type Service struct {
 	repo             db_codebases.CodebaseRepository
 	codebaseUserRepo db_codebases.CodebaseUserRepository
 
-	workspaceService *service_workspace.Service
-	userService      service_user.Service
+	workspaceService    *service_workspace.Service
+	userService         service_user.Service
+	organizationService *service_organization.Service
 
 	logger             *zap.Logger
 	executorProvider   executor.Provider
@@ -39,6 +45,7 @@ type Service struct {
 	notificationSender sender.NotificationSender
 	analyticsService   *service_analytics.Service
 	changeService      *service_changes.Service
+	auditService       *service_audit.Service
 }
 
 func New(
@@ -54,13 +61,16 @@ func New(
 	analyticsService *service_analytics.Service,
 	notificationSender sender.NotificationSender,
 	changeService *service_changes.Service,
+	organizationService *service_organization.Service,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
 		repo:             repo,
 		codebaseUserRepo: codebaseUserRepo,
 
-		workspaceService: workspaceService,
-		userService:      userService,
+		workspaceService:    workspaceService,
+		userService:         userService,
+		organizationService: organizationService,
 
 		logger:             logger,
 		executorProvider:   executorProvider,
@@ -68,6 +78,7 @@ func New(
 		notificationSender: notificationSender,
 		analyticsService:   analyticsService,
 		changeService:      changeService,
+		auditService:       auditService,
 	}
 }
 
@@ -191,7 +202,19 @@ func (svc *Service) Update(ctx context.Context, cb *codebases.Codebase) error {
 	return nil
 }
 
+// Create creates a new codebase, and adds the user as it's first member. Codebases can only be created in
+// organizations by members of it, guests are not allowed to.
 func (svc *Service) Create(ctx context.Context, userID users.ID, name string, organizationID *string) (*codebases.Codebase, error) {
+	if organizationID != nil {
+		isMember, err := svc.organizationService.HasRole(ctx, *organizationID, userID, organization.RoleMember)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check organization role: %w", err)
+		}
+		if !isMember {
+			return nil, fmt.Errorf("only members can create codebases in the organization: %w", auth.ErrForbidden)
+		}
+	}
+
 	codebaseID := codebases.ID(uuid.NewString())
 	t := time.Now()
 
@@ -294,6 +317,18 @@ func (svc *Service) AddUser(ctx context.Context, codebaseID codebases.ID, user *
 		return nil, fmt.Errorf("could not add user: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		CodebaseID: &codebaseID,
+		ActorType:  auth.SubjectUser,
+		ActorID:    (*string)(&addedBy),
+		Action:     audit.ActionCodebaseMemberAdded,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID.String(),
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
 	// Send events
 	if err := svc.eventsSender.Codebase(codebaseID, events.CodebaseUpdated, codebaseID.String()); err != nil {
 		svc.logger.Error("failed to send events", zap.Error(err))
@@ -327,6 +362,16 @@ func (svc *Service) RemoveUser(ctx context.Context, codebaseID codebases.ID, use
 		return fmt.Errorf("failed to delete: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		CodebaseID: &codebaseID,
+		Action:     audit.ActionCodebaseMemberRemoved,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
 	// Send events
 	if err := svc.eventsSender.Codebase(codebaseID, events.CodebaseUpdated, codebaseID.String()); err != nil {
 		svc.logger.Error("failed to send events", zap.Error(err))
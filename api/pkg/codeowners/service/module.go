@@ -0,0 +1,25 @@
+package service
+
+import (
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+	service_review "getsturdy.com/api/pkg/review/service"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	service_users "getsturdy.com/api/pkg/users/service/module"
+	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(provider_acl.Module)
+	c.Import(service_auth.Module)
+	c.Import(db_codebases.Module)
+	c.Import(service_users.Module)
+	c.Import(service_workspaces.Module)
+	c.Import(service_snapshots.Module)
+	c.Import(service_review.Module)
+	c.Register(New)
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases/acl"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/review"
+	service_review "getsturdy.com/api/pkg/review/service"
+	"getsturdy.com/api/pkg/snapshots"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/service"
+	"getsturdy.com/api/pkg/workspaces"
+	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
+
+	"go.uber.org/zap"
+)
+
+// Service enforces the code owners of the codebase acl policies. A workspace that changes files owned by a set of
+// code owners needs an approving review from at least one of them before it can be landed.
+type Service struct {
+	logger *zap.Logger
+
+	aclProvider      *provider_acl.Provider
+	authService      *service_auth.Service
+	codebaseRepo     db_codebases.CodebaseRepository
+	codebaseUserRepo db_codebases.CodebaseUserRepository
+	userService      service_users.Service
+	workspaceService *service_workspaces.Service
+	snapshotService  *service_snapshots.Service
+	reviewService    *service_review.Service
+}
+
+func New(
+	logger *zap.Logger,
+	aclProvider *provider_acl.Provider,
+	authService *service_auth.Service,
+	codebaseRepo db_codebases.CodebaseRepository,
+	codebaseUserRepo db_codebases.CodebaseUserRepository,
+	userService service_users.Service,
+	workspaceService *service_workspaces.Service,
+	snapshotService *service_snapshots.Service,
+	reviewService *service_review.Service,
+) *Service {
+	return &Service{
+		logger:           logger.Named("codeOwnersService"),
+		aclProvider:      aclProvider,
+		authService:      authService,
+		codebaseRepo:     codebaseRepo,
+		codebaseUserRepo: codebaseUserRepo,
+		userService:      userService,
+		workspaceService: workspaceService,
+		snapshotService:  snapshotService,
+		reviewService:    reviewService,
+	}
+}
+
+// MissingApprovals returns the code owners of the files changed in the workspace that have not approved it. An
+// approval doesn't count if the files owned by the code owners have changed since it was given.
+func (s *Service) MissingApprovals(ctx context.Context, ws *workspaces.Workspace) ([]*acl.CodeOwners, error) {
+	_, missing, err := s.missingApprovals(ctx, ws)
+	return missing, err
+}
+
+func (s *Service) missingApprovals(ctx context.Context, ws *workspaces.Workspace) (acl.Policy, []*acl.CodeOwners, error) {
+	policy, owners, err := s.codeOwners(ctx, ws)
+	if err != nil || len(owners) == 0 {
+		return policy, nil, err
+	}
+
+	reviews, err := s.reviewService.ListLatestByWorkspace(ctx, ws.ID)
+	if err != nil {
+		return policy, nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	approvals := make([]*review.Review, 0, len(reviews))
+	approverIDs := make([]users.ID, 0, len(reviews))
+	for _, r := range reviews {
+		// the author can not approve their own workspace
+		if r.Grade == review.ReviewGradeApprove && r.UserID != ws.UserID {
+			approvals = append(approvals, r)
+			approverIDs = append(approverIDs, r.UserID)
+		}
+	}
+
+	approvers, err := s.identities(ctx, ws, approverIDs...)
+	if err != nil {
+		return policy, nil, err
+	}
+
+	changedPaths := make(map[snapshots.ID][]string)
+	missing := make([]*acl.CodeOwners, 0, len(owners))
+	for _, o := range owners {
+		approved := false
+		for _, a := range approvals {
+			if !includesAny(policy, o, approvers[a.UserID]) {
+				continue
+			}
+			stale, err := s.isStale(ctx, ws, policy, o, a, changedPaths)
+			if err != nil {
+				return policy, nil, err
+			}
+			if !stale {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			missing = append(missing, o)
+		}
+	}
+	return policy, missing, nil
+}
+
+// isStale returns true if files owned by the code owners have changed since the approval was given. Approvals are of
+// the latest snapshot of the workspace at the time, the paths that have changed since are cached in changedPaths, by
+// the id of that snapshot.
+func (s *Service) isStale(ctx context.Context, ws *workspaces.Workspace, policy acl.Policy, owners *acl.CodeOwners, approval *review.Review, changedPaths map[snapshots.ID][]string) (bool, error) {
+	switch {
+	case approval.SnapshotID == nil && ws.LatestSnapshotID == nil:
+		return false, nil
+	case approval.SnapshotID == nil || ws.LatestSnapshotID == nil:
+		// there is no way to tell what was approved
+		return true, nil
+	case *approval.SnapshotID == *ws.LatestSnapshotID:
+		return false, nil
+	}
+
+	paths, ok := changedPaths[*approval.SnapshotID]
+	if !ok {
+		approved, err := s.snapshotService.GetByID(ctx, *approval.SnapshotID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get approved snapshot: %w", err)
+		}
+		latest, err := s.snapshotService.GetByID(ctx, *ws.LatestSnapshotID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get latest snapshot: %w", err)
+		}
+		paths, err = s.snapshotService.ChangedPaths(ctx, approved, latest)
+		if err != nil {
+			return false, err
+		}
+		changedPaths[*approval.SnapshotID] = paths
+	}
+
+	for _, path := range paths {
+		if owners.Owns(path, policy.Groups) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RequestReviews requests a review from the code owners that have not approved the workspace yet.
+func (s *Service) RequestReviews(ctx context.Context, ws *workspaces.Workspace) error {
+	policy, missing, err := s.missingApprovals(ctx, ws)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	members, err := s.codebaseUserRepo.GetByCodebase(ws.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get codebase members: %w", err)
+	}
+
+	memberIDs := make([]users.ID, 0, len(members))
+	for _, m := range members {
+		if m.UserID != ws.UserID {
+			memberIDs = append(memberIDs, m.UserID)
+		}
+	}
+
+	candidates, err := s.identities(ctx, ws, memberIDs...)
+	if err != nil {
+		return err
+	}
+
+	for _, memberID := range memberIDs {
+		identities, ok := candidates[memberID]
+		if !ok {
+			continue
+		}
+		for _, o := range missing {
+			if !includesAny(policy, o, identities) {
+				continue
+			}
+			if _, err := s.reviewService.RequestReview(ctx, ws, ws.UserID, memberID); err != nil {
+				return fmt.Errorf("failed to request review: %w", err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// codeOwners returns the code owners of the files that are changed in the workspace
+func (s *Service) codeOwners(ctx context.Context, ws *workspaces.Workspace) (acl.Policy, []*acl.CodeOwners, error) {
+	a, err := s.aclProvider.GetByCodebaseID(ctx, ws.CodebaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return acl.Policy{}, nil, nil
+	case err != nil:
+		return acl.Policy{}, nil, fmt.Errorf("failed to get acl: %w", err)
+	case len(a.Policy.CodeOwners) == 0:
+		return a.Policy, nil, nil
+	}
+
+	diffs, _, err := s.workspaceService.Diffs(ctx, ws.ID)
+	if err != nil {
+		return acl.Policy{}, nil, fmt.Errorf("failed to get diffs: %w", err)
+	}
+
+	paths := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		paths = append(paths, diff.PreferredName)
+		if diff.IsMoved {
+			paths = append(paths, diff.OrigName)
+		}
+	}
+
+	return a.Policy, a.Policy.CodeOwnersOf(paths...), nil
+}
+
+// identities returns the identities that each of the users has in the acl of the codebase of the workspace, by the
+// id of the user. Users can be code owners by their id or email, or by the groups they are in at the identity provider.
+func (s *Service) identities(ctx context.Context, ws *workspaces.Workspace, ids ...users.ID) (map[users.ID][]acl.Identity, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	codebase, err := s.codebaseRepo.Get(ws.CodebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	uu, err := s.userService.GetByIDs(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	res := make(map[users.ID][]acl.Identity, len(uu))
+	for _, u := range uu {
+		identities, err := s.authService.UserIdentities(ctx, u, codebase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get identities: %w", err)
+		}
+		res[u.ID] = identities
+	}
+	return res, nil
+}
+
+// includesAny returns true if any of the identities is one of the code owners
+func includesAny(policy acl.Policy, owners *acl.CodeOwners, identities []acl.Identity) bool {
+	for _, identity := range identities {
+		if owners.Includes(identity, policy.Groups) {
+			return true
+		}
+	}
+	return false
+}
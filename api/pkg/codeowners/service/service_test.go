@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	module_api "getsturdy.com/api/pkg/api/module"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
+	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/internal/dbtest"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	queue "getsturdy.com/api/pkg/queue/module"
+	"getsturdy.com/api/pkg/review"
+	db_review "getsturdy.com/api/pkg/review/db"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	"getsturdy.com/api/pkg/snapshots"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	"getsturdy.com/api/pkg/users"
+	db_user "getsturdy.com/api/pkg/users/db"
+	service_view "getsturdy.com/api/pkg/views/service"
+	"getsturdy.com/api/pkg/workspaces"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+)
+
+func module(t *testing.T) di.Module {
+	return func(c *di.Container) {
+		// TODO: reduce scope
+		c.Import(module_api.Module)
+		c.ImportWithForce(configuration.TestModule)
+		c.ImportWithForce(queue.TestModule(t))
+		c.Register(func() *testing.T { return t })
+		c.RegisterWithForce(dbtest.DB)
+	}
+}
+
+type deps struct {
+	dig.In
+	UserRepo            db_user.Repository
+	ReviewRepo          db_review.ReviewRepository
+	AclProvider         *provider_acl.Provider
+	CodeOwnersService   *service_codeowners.Service
+	CodebaseService     *service_codebase.Service
+	OrganizationService *service_organization.Service
+	ScimService         *service_scim.Service
+	SnapshotService     *service_snapshots.Service
+	WorkspaceService    *service_workspace.Service
+	ViewService         *service_view.Service
+	ExecutorProvider    executor.Provider
+}
+
+type testCase struct {
+	deps
+
+	ctx        context.Context
+	author     *users.User
+	owner      *users.User
+	codebaseID codebases.ID
+}
+
+// setup creates a codebase, where the files in owned/ are owned by a member other than the author of the workspaces
+func setup(t *testing.T) *testCase {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	tc := &testCase{}
+	require.NoError(t, di.Init(module(t)).To(&tc.deps))
+
+	tc.author = tc.createUser(t)
+	tc.owner = tc.createUser(t)
+	tc.ctx = auth.NewContext(context.Background(), &auth.Subject{Type: auth.SubjectUser, ID: tc.author.ID.String()})
+
+	cb, err := tc.CodebaseService.Create(tc.ctx, tc.author.ID, "test", nil)
+	require.NoError(t, err)
+	tc.codebaseID = cb.ID
+
+	_, err = tc.CodebaseService.AddUser(tc.ctx, tc.codebaseID, tc.owner, tc.author.ID)
+	require.NoError(t, err)
+
+	tc.setCodeOwners(t, &acl.Identifier{Type: acl.Users, Pattern: tc.owner.Email})
+
+	return tc
+}
+
+// setCodeOwners makes the principal the code owner of the files in owned/
+func (tc *testCase) setCodeOwners(t *testing.T, principal *acl.Identifier) {
+	a, err := tc.AclProvider.GetByCodebaseID(tc.ctx, tc.codebaseID)
+	require.NoError(t, err)
+	a.Policy.CodeOwners = []*acl.CodeOwners{{
+		ID:         "owned",
+		Principals: []*acl.Identifier{principal},
+		Resources:  []*acl.Identifier{{Type: acl.Files, Pattern: "owned/*"}},
+	}}
+	policy, err := json.Marshal(a.Policy)
+	require.NoError(t, err)
+	a.RawPolicy = string(policy)
+	require.NoError(t, tc.AclProvider.Update(tc.ctx, a))
+}
+
+func (tc *testCase) createUser(t *testing.T) *users.User {
+	user := &users.User{ID: users.ID(uuid.NewString()), Name: "Test", Email: uuid.NewString() + "@getsturdy.com"}
+	require.NoError(t, tc.UserRepo.Create(user))
+	return user
+}
+
+// workspaceWithChanges returns a workspace of the author, with the file changed in its view
+func (tc *testCase) workspaceWithChanges(t *testing.T, filename string) *workspaces.Workspace {
+	ws, err := tc.WorkspaceService.Create(tc.ctx, service_workspace.CreateWorkspaceRequest{UserID: tc.author.ID, CodebaseID: tc.codebaseID})
+	require.NoError(t, err)
+
+	vw, err := tc.ViewService.Create(tc.ctx, tc.author.ID, ws, nil, nil)
+	require.NoError(t, err)
+
+	tc.write(t, vw.ID, filename, "hello\n")
+
+	ws, err = tc.WorkspaceService.GetByID(tc.ctx, ws.ID)
+	require.NoError(t, err)
+	return ws
+}
+
+func (tc *testCase) write(t *testing.T, viewID, filename, contents string) {
+	require.NoError(t, tc.ExecutorProvider.New().Write(func(repo vcs.RepoWriter) error {
+		if err := os.MkdirAll(path.Join(repo.Path(), path.Dir(filename)), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path.Join(repo.Path(), filename), []byte(contents), 0o644)
+	}).ExecView(tc.codebaseID, viewID, "write"))
+}
+
+// snapshot changes the file in the view of the workspace, and returns the workspace with the change in its latest
+// snapshot
+func (tc *testCase) snapshot(t *testing.T, ws *workspaces.Workspace, filename, contents string) *workspaces.Workspace {
+	tc.write(t, *ws.ViewID, filename, contents)
+
+	_, err := tc.SnapshotService.Snapshot(tc.ctx, tc.codebaseID, ws.ID, snapshots.Action("testing"),
+		service_snapshots.WithOnView(*ws.ViewID),
+		service_snapshots.WithMarkAsLatestInWorkspace(),
+	)
+	require.NoError(t, err)
+
+	ws, err = tc.WorkspaceService.GetByID(tc.ctx, ws.ID)
+	require.NoError(t, err)
+	return ws
+}
+
+func (tc *testCase) approve(t *testing.T, ws *workspaces.Workspace, user *users.User) {
+	require.NoError(t, tc.ReviewRepo.Create(tc.ctx, review.Review{
+		ID:          uuid.NewString(),
+		UserID:      user.ID,
+		CodebaseID:  ws.CodebaseID,
+		WorkspaceID: ws.ID,
+		SnapshotID:  ws.LatestSnapshotID,
+		Grade:       review.ReviewGradeApprove,
+		CreatedAt:   time.Now(),
+	}))
+}
+
+func TestMissingApprovals(t *testing.T) {
+	tc := setup(t)
+
+	ws := tc.workspaceWithChanges(t, "owned/a.txt")
+
+	missing, err := tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, "owned", missing[0].ID)
+	}
+
+	// the author can't approve their own changes
+	tc.approve(t, ws, tc.author)
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Len(t, missing, 1)
+
+	tc.approve(t, ws, tc.owner)
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestMissingApprovals_directoryGroup(t *testing.T) {
+	tc := setup(t)
+
+	// the codebase is in an organization, where the owner is in a group that is provisioned over scim
+	org, err := tc.OrganizationService.Create(tc.ctx, tc.owner.ID, "org")
+	require.NoError(t, err)
+	cb, err := tc.CodebaseService.Create(tc.ctx, tc.author.ID, "test", &org.ID)
+	require.NoError(t, err)
+	tc.codebaseID = cb.ID
+	_, err = tc.CodebaseService.AddUser(tc.ctx, tc.codebaseID, tc.owner, tc.author.ID)
+	require.NoError(t, err)
+
+	_, token, err := tc.ScimService.CreateToken(tc.ctx, org.ID, tc.owner.ID)
+	require.NoError(t, err)
+	_, err = tc.ScimService.CreateGroup(tc.ctx, token, "reviewers", nil, []users.ID{tc.owner.ID})
+	require.NoError(t, err)
+
+	tc.setCodeOwners(t, &acl.Identifier{Type: acl.Groups, Pattern: "reviewers"})
+
+	ws := tc.workspaceWithChanges(t, "owned/a.txt")
+
+	missing, err := tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Len(t, missing, 1)
+
+	tc.approve(t, ws, tc.owner)
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestMissingApprovals_changedAfterApproval(t *testing.T) {
+	tc := setup(t)
+
+	ws := tc.workspaceWithChanges(t, "owned/a.txt")
+	ws = tc.snapshot(t, ws, "owned/a.txt", "hello\n")
+
+	tc.approve(t, ws, tc.owner)
+	missing, err := tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	// changes to files that are not owned keep the approval
+	ws = tc.snapshot(t, ws, "b.txt", "not owned\n")
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	// changes to owned files need to be approved again
+	ws = tc.snapshot(t, ws, "owned/a.txt", "changed\n")
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, "owned", missing[0].ID)
+	}
+
+	tc.approve(t, ws, tc.owner)
+	missing, err = tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestMissingApprovals_notOwned(t *testing.T) {
+	tc := setup(t)
+
+	ws := tc.workspaceWithChanges(t, "a.txt")
+
+	missing, err := tc.CodeOwnersService.MissingApprovals(tc.ctx, ws)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestRequestReviews(t *testing.T) {
+	tc := setup(t)
+
+	ws := tc.workspaceWithChanges(t, "owned/a.txt")
+
+	require.NoError(t, tc.CodeOwnersService.RequestReviews(tc.ctx, ws))
+
+	reviews, err := tc.ReviewRepo.ListLatestByWorkspace(tc.ctx, ws.ID)
+	require.NoError(t, err)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, tc.owner.ID, reviews[0].UserID)
+		assert.Equal(t, review.ReviewGradeRequested, reviews[0].Grade)
+	}
+}
@@ -0,0 +1,10 @@
+package codesearch
+
+// Match is a line in a file on the trunk that matches a code search query
+type Match struct {
+	Path string
+	// Line is the 1-indexed line number of the match
+	Line int
+	// Snippet is the matching line, long lines are truncated
+	Snippet string
+}
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/codesearch"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+type rootResolver struct {
+	authService       *service_auth.Service
+	codebaseService   *service_codebase.Service
+	codesearchService *service_codesearch.Service
+}
+
+func New(
+	authService *service_auth.Service,
+	codebaseService *service_codebase.Service,
+	codesearchService *service_codesearch.Service,
+) resolvers.CodeSearchRootResolver {
+	return &rootResolver{
+		authService:       authService,
+		codebaseService:   codebaseService,
+		codesearchService: codesearchService,
+	}
+}
+
+func (r *rootResolver) SearchCode(ctx context.Context, args resolvers.SearchCodeArgs) ([]resolvers.CodeSearchMatchResolver, error) {
+	cb, err := r.codebaseService.GetByID(ctx, codebases.ID(args.CodebaseID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanRead(ctx, cb); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	allower, err := r.authService.GetAllower(ctx, cb)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	opts := service_codesearch.SearchOptions{PathGlob: args.PathGlob}
+	if args.Regex != nil {
+		opts.Regex = *args.Regex
+	}
+	if args.Limit != nil {
+		opts.Limit = int(*args.Limit)
+	}
+
+	matches, err := r.codesearchService.Search(ctx, cb.ID, allower, args.Query, opts)
+	switch {
+	case errors.Is(err, service_codesearch.ErrInvalidQuery):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", err.Error())
+	case errors.Is(err, service_codesearch.ErrInvalidPathGlob):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "Invalid path glob")
+	case err != nil:
+		return nil, gqlerrors.Error(fmt.Errorf("failed to search code: %w", err))
+	}
+
+	res := make([]resolvers.CodeSearchMatchResolver, 0, len(matches))
+	for _, match := range matches {
+		res = append(res, &matchResolver{match: match})
+	}
+	return res, nil
+}
+
+type matchResolver struct {
+	match codesearch.Match
+}
+
+func (r *matchResolver) Path() string {
+	return r.match.Path
+}
+
+func (r *matchResolver) Line() int32 {
+	return int32(r.match.Line)
+}
+
+func (r *matchResolver) Snippet() string {
+	return r.match.Snippet
+}
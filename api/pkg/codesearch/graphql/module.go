@@ -0,0 +1,15 @@
+package graphql
+
+import (
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Import(service_auth.Module)
+	c.Import(service_codebase.Module)
+	c.Import(service_codesearch.Module)
+	c.Register(New)
+}
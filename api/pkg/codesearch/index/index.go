@@ -0,0 +1,213 @@
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxFileSize is the largest file that is indexed, larger files are never returned as candidates.
+	MaxFileSize = 1 << 20
+
+	// binarySniffLen is the number of bytes looked at to tell if a file is binary
+	binarySniffLen = 8000
+)
+
+type trigram uint32
+
+// Index is a trigram index over the files of a tree.
+//
+// Trigrams are case folded, so the candidates returned for a literal are a superset of the files that contain it
+// case-sensitively. Candidates must always be verified against the contents of the file.
+type Index struct {
+	// CommitID is the commit that the index was last updated to
+	CommitID string
+
+	files    map[string][]trigram
+	postings map[trigram]map[string]struct{}
+}
+
+func New() *Index {
+	return &Index{
+		files:    make(map[string][]trigram),
+		postings: make(map[trigram]map[string]struct{}),
+	}
+}
+
+// Add adds the file to the index, replacing it if it's already indexed. Binary and very large files are not indexed.
+func (i *Index) Add(path string, contents []byte) {
+	i.Remove(path)
+
+	if !isIndexable(contents) {
+		return
+	}
+
+	i.add(path, trigramsOf(bytes.ToLower(contents)))
+}
+
+func (i *Index) add(path string, trigrams []trigram) {
+	i.files[path] = trigrams
+	for _, t := range trigrams {
+		paths, ok := i.postings[t]
+		if !ok {
+			paths = make(map[string]struct{})
+			i.postings[t] = paths
+		}
+		paths[path] = struct{}{}
+	}
+}
+
+// Remove removes the file from the index
+func (i *Index) Remove(path string) {
+	trigrams, ok := i.files[path]
+	if !ok {
+		return
+	}
+	for _, t := range trigrams {
+		delete(i.postings[t], path)
+		if len(i.postings[t]) == 0 {
+			delete(i.postings, t)
+		}
+	}
+	delete(i.files, path)
+}
+
+// Len returns the number of indexed files
+func (i *Index) Len() int {
+	return len(i.files)
+}
+
+// Candidates returns the sorted paths of the files that might contain all of the literals. Literals that are
+// shorter than three bytes do not narrow down the result.
+func (i *Index) Candidates(literals ...string) []string {
+	var want []trigram
+	for _, literal := range literals {
+		want = append(want, trigramsOf([]byte(strings.ToLower(literal)))...)
+	}
+
+	var result []string
+	if len(want) == 0 {
+		result = make([]string, 0, len(i.files))
+		for path := range i.files {
+			result = append(result, path)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	// start with the rarest trigram, to intersect as few paths as possible
+	sort.Slice(want, func(a, b int) bool {
+		return len(i.postings[want[a]]) < len(i.postings[want[b]])
+	})
+
+	for path := range i.postings[want[0]] {
+		matchesAll := true
+		for _, t := range want[1:] {
+			if _, ok := i.postings[t][path]; !ok {
+				matchesAll = false
+				break
+			}
+		}
+		if matchesAll {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+type encodedIndex struct {
+	CommitID string
+	Files    map[string][]trigram
+}
+
+// Encode writes the index to w, it can be read back with Decode
+func (i *Index) Encode(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(encodedIndex{CommitID: i.CommitID, Files: i.files}); err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an index written by Encode
+func Decode(r io.Reader) (*Index, error) {
+	var encoded encodedIndex
+	if err := gob.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+
+	i := New()
+	i.CommitID = encoded.CommitID
+	for path, trigrams := range encoded.Files {
+		i.add(path, trigrams)
+	}
+	return i, nil
+}
+
+// RequiredLiterals returns literal strings that all matches of the regular expression must contain.
+// An empty result means that the expression can not be narrowed down, and that all files have to be searched.
+func RequiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			// the index only folds the case of ascii
+			for _, r := range re.Rune {
+				if r > unicode.MaxASCII {
+					return nil
+				}
+			}
+			return []string{strings.ToLower(string(re.Rune))}
+		}
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return RequiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return RequiredLiterals(re.Sub[0])
+		}
+		return nil
+	case syntax.OpConcat:
+		var literals []string
+		for _, sub := range re.Sub {
+			literals = append(literals, RequiredLiterals(sub)...)
+		}
+		return literals
+	default:
+		return nil
+	}
+}
+
+func isIndexable(contents []byte) bool {
+	if len(contents) > MaxFileSize {
+		return false
+	}
+	sniff := contents
+	if len(sniff) > binarySniffLen {
+		sniff = sniff[:binarySniffLen]
+	}
+	// same heuristic as git, files with a NUL byte are binary
+	return bytes.IndexByte(sniff, 0) == -1
+}
+
+func trigramsOf(b []byte) []trigram {
+	if len(b) < 3 {
+		return nil
+	}
+	seen := make(map[trigram]struct{})
+	var result []trigram
+	for i := 0; i+3 <= len(b); i++ {
+		t := trigram(b[i])<<16 | trigram(b[i+1])<<8 | trigram(b[i+2])
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		result = append(result, t)
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package index
+
+import (
+	"bytes"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandidates(t *testing.T) {
+	idx := New()
+	idx.Add("main.go", []byte("package main\n\nfunc main() {}\n"))
+	idx.Add("README.md", []byte("# Hello World\n"))
+	idx.Add("image.png", []byte("\x89PNG\x00\x00main"))
+	idx.Add("a", []byte("ab"))
+
+	assert.Equal(t, 3, idx.Len(), "binary files are not indexed")
+	assert.Equal(t, []string{"main.go"}, idx.Candidates("func main"))
+	assert.Equal(t, []string{"README.md"}, idx.Candidates("hello world"), "trigrams are case folded")
+	assert.Empty(t, idx.Candidates("main", "hello"))
+	assert.Equal(t, []string{"README.md", "a", "main.go"}, idx.Candidates("ab"), "short literals match all files")
+
+	idx.Add("main.go", []byte("package main\n"))
+	assert.Empty(t, idx.Candidates("func"))
+
+	idx.Remove("README.md")
+	assert.Empty(t, idx.Candidates("hello"))
+	assert.Equal(t, 2, idx.Len())
+}
+
+func TestEncodeDecode(t *testing.T) {
+	idx := New()
+	idx.CommitID = "abc"
+	idx.Add("main.go", []byte("package main\n"))
+	idx.Add("empty", nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.Encode(&buf))
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", decoded.CommitID)
+	assert.Equal(t, 2, decoded.Len())
+	assert.Equal(t, []string{"main.go"}, decoded.Candidates("package"))
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: `func \w+\(`, expected: []string{"func ", "("}},
+		{expr: `(?i)hello`, expected: []string{"hello"}},
+		{expr: `(foo)+bar`, expected: []string{"foo", "bar"}},
+		{expr: `foo|bar`, expected: nil},
+		{expr: `(foo)?bar`, expected: []string{"bar"}},
+		{expr: `.*`, expected: nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			re, err := syntax.Parse(tc.expr, syntax.Perl)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, RequiredLiterals(re))
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	configuration "getsturdy.com/api/pkg/configuration/module"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+	"getsturdy.com/api/vcs/executor"
+)
+
+func Module(c *di.Container) {
+	c.Import(configuration.Module)
+	c.Import(logger.Module)
+	c.Import(executor.Module)
+	c.Register(New)
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+
+	doublestar "github.com/bmatcuk/doublestar/v4"
+	lru "github.com/hashicorp/golang-lru"
+	git "github.com/libgit2/git2go/v33"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codesearch"
+	"getsturdy.com/api/pkg/codesearch/index"
+	"getsturdy.com/api/pkg/unidiff"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+	provider "getsturdy.com/api/vcs/provider/configuration"
+)
+
+var (
+	ErrInvalidQuery    = errors.New("invalid query")
+	ErrInvalidPathGlob = errors.New("invalid path glob")
+)
+
+const (
+	indexCacheSize = 64
+
+	// indexFileName is the name of the index file, it's stored next to the trunk of the codebase
+	indexFileName = "codesearch.idx"
+
+	defaultLimit     = 100
+	maxLimit         = 1000
+	maxSnippetLength = 300
+)
+
+type Service struct {
+	logger           *zap.Logger
+	executorProvider executor.Provider
+	reposPath        string
+
+	indexes *lru.Cache
+
+	locksMu sync.Mutex
+	locks   map[codebases.ID]*sync.Mutex
+}
+
+func New(
+	logger *zap.Logger,
+	executorProvider executor.Provider,
+	vcsConfiguration *provider.Configuration,
+) *Service {
+	// only fails if the size is not positive
+	indexes, _ := lru.New(indexCacheSize)
+	return &Service{
+		logger:           logger.Named("codesearch"),
+		executorProvider: executorProvider,
+		reposPath:        vcsConfiguration.ReposPath,
+		indexes:          indexes,
+		locks:            make(map[codebases.ID]*sync.Mutex),
+	}
+}
+
+type SearchOptions struct {
+	// PathGlob limits the search to files matching the glob, for example "src/**/*.go"
+	PathGlob *string
+	// Regex makes the query a regular expression, instead of a literal string
+	Regex bool
+	Limit int
+}
+
+// Search searches the files on the trunk of the codebase, and returns the lines that match the query.
+// Files that are not allowed by the allower are never searched.
+func (s *Service) Search(ctx context.Context, codebaseID codebases.ID, allower *unidiff.Allower, query string, opts SearchOptions) ([]codesearch.Match, error) {
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is empty", ErrInvalidQuery)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	var literals []string
+	var re *regexp.Regexp
+	if opts.Regex {
+		parsed, err := syntax.Parse(query, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+		}
+		if re, err = regexp.Compile(query); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+		}
+		literals = index.RequiredLiterals(parsed)
+	} else {
+		literals = []string{query}
+	}
+
+	if opts.PathGlob != nil && !doublestar.ValidatePattern(*opts.PathGlob) {
+		return nil, ErrInvalidPathGlob
+	}
+
+	unlock := s.lock(codebaseID)
+	idx, err := s.update(codebaseID)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	commitID := idx.CommitID
+	candidates := idx.Candidates(literals...)
+	unlock()
+
+	if commitID == "" {
+		// the trunk is empty
+		return nil, nil
+	}
+
+	matchLine := func(line []byte) bool {
+		if re != nil {
+			return re.Match(line)
+		}
+		return bytes.Contains(line, []byte(query))
+	}
+
+	var matches []codesearch.Match
+	if err := s.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		for _, candidate := range candidates {
+			if opts.PathGlob != nil {
+				if ok, _ := doublestar.Match(*opts.PathGlob, candidate); !ok {
+					continue
+				}
+			}
+			if !allower.IsAllowed(candidate, false) {
+				continue
+			}
+
+			contents, err := repo.FileContentsAtCommit(commitID, candidate)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", candidate, err)
+			}
+
+			for lineIdx, line := range bytes.Split(contents, []byte("\n")) {
+				line = bytes.TrimSuffix(line, []byte("\r"))
+				if !matchLine(line) {
+					continue
+				}
+				matches = append(matches, codesearch.Match{
+					Path:    candidate,
+					Line:    lineIdx + 1,
+					Snippet: snippet(line),
+				})
+				if len(matches) >= limit {
+					return nil
+				}
+			}
+		}
+		return nil
+	}).ExecTrunk(codebaseID, "codesearchService.Search"); err != nil {
+		return nil, fmt.Errorf("failed to search files: %w", err)
+	}
+
+	return matches, nil
+}
+
+func snippet(line []byte) string {
+	if len(line) > maxSnippetLength {
+		line = line[:maxSnippetLength]
+	}
+	return strings.ToValidUTF8(string(line), "")
+}
+
+// Update brings the index of the codebase up to date with the trunk. Only the files that have changed since the
+// last update are indexed.
+func (s *Service) Update(codebaseID codebases.ID) error {
+	unlock := s.lock(codebaseID)
+	defer unlock()
+	_, err := s.update(codebaseID)
+	return err
+}
+
+// UpdateInBackground updates the index of the codebase without blocking, it's called whenever the trunk moves.
+func (s *Service) UpdateInBackground(codebaseID codebases.ID) {
+	go func() {
+		if err := s.Update(codebaseID); err != nil {
+			s.logger.Error("failed to update index", zap.Stringer("codebase_id", codebaseID), zap.Error(err))
+		}
+	}()
+}
+
+func (s *Service) lock(codebaseID codebases.ID) func() {
+	s.locksMu.Lock()
+	mu, ok := s.locks[codebaseID]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.locks[codebaseID] = mu
+	}
+	s.locksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// update must be called while holding the lock of the codebase
+func (s *Service) update(codebaseID codebases.ID) (*index.Index, error) {
+	idx, err := s.load(codebaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated bool
+	if err := s.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		head, err := repo.HeadCommit()
+		switch {
+		case errors.Is(err, vcs.ErrNotFound):
+			// nothing has been landed yet
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to get head commit: %w", err)
+		}
+		defer head.Free()
+
+		headID := head.Id().String()
+		if idx.CommitID == headID {
+			return nil
+		}
+
+		var diff *git.Diff
+		if idx.CommitID != "" {
+			diff, err = repo.DiffCommits(idx.CommitID, headID)
+			if err != nil {
+				// the indexed commit might be gone if the trunk was force pushed by a remote, start over
+				s.logger.Warn("failed to diff from indexed commit, rebuilding index", zap.Stringer("codebase_id", codebaseID), zap.Error(err))
+				idx = index.New()
+			}
+		}
+		if diff == nil {
+			if diff, err = repo.DiffCommitToRoot(headID); err != nil {
+				return fmt.Errorf("failed to get diff: %w", err)
+			}
+		}
+		defer diff.Free()
+
+		if err := applyDiff(repo, idx, headID, diff); err != nil {
+			return err
+		}
+
+		idx.CommitID = headID
+		updated = true
+		return nil
+	}).ExecTrunk(codebaseID, "codesearchService.update"); err != nil {
+		// the index might be partially updated, don't keep it around
+		s.indexes.Remove(codebaseID)
+		return nil, fmt.Errorf("failed to update index: %w", err)
+	}
+
+	s.indexes.Add(codebaseID, idx)
+
+	if updated {
+		if err := s.save(codebaseID, idx); err != nil {
+			return nil, err
+		}
+	}
+
+	return idx, nil
+}
+
+func applyDiff(repo vcs.RepoGitReader, idx *index.Index, commitID string, diff *git.Diff) error {
+	numDeltas, err := diff.NumDeltas()
+	if err != nil {
+		return fmt.Errorf("could not get number of deltas: %w", err)
+	}
+
+	for i := 0; i < numDeltas; i++ {
+		delta, err := diff.Delta(i)
+		if err != nil {
+			return fmt.Errorf("could not get delta: %w", err)
+		}
+
+		switch delta.Status {
+		case git.DeltaDeleted:
+			idx.Remove(delta.OldFile.Path)
+			continue
+		case git.DeltaRenamed:
+			idx.Remove(delta.OldFile.Path)
+		}
+
+		if git.Filemode(delta.NewFile.Mode) == git.FilemodeCommit {
+			// submodules have no contents
+			continue
+		}
+
+		blob, err := repo.FileBlobAtCommit(commitID, delta.NewFile.Path)
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", delta.NewFile.Path, err)
+		}
+		if blob.Size() > index.MaxFileSize {
+			idx.Remove(delta.NewFile.Path)
+		} else {
+			idx.Add(delta.NewFile.Path, blob.Contents())
+		}
+		blob.Free()
+	}
+
+	return nil
+}
+
+func (s *Service) indexPath(codebaseID codebases.ID) string {
+	return path.Join(s.reposPath, codebaseID.String(), indexFileName)
+}
+
+func (s *Service) load(codebaseID codebases.ID) (*index.Index, error) {
+	if cached, ok := s.indexes.Get(codebaseID); ok {
+		return cached.(*index.Index), nil
+	}
+
+	fp, err := os.Open(s.indexPath(codebaseID))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return index.New(), nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to open index: %w", err)
+	}
+	defer fp.Close()
+
+	idx, err := index.Decode(fp)
+	if err != nil {
+		// the index is rebuilt from scratch
+		s.logger.Warn("failed to read index", zap.Stringer("codebase_id", codebaseID), zap.Error(err))
+		return index.New(), nil
+	}
+	return idx, nil
+}
+
+func (s *Service) save(codebaseID codebases.ID, idx *index.Index) error {
+	indexPath := s.indexPath(codebaseID)
+	fp, err := os.CreateTemp(path.Dir(indexPath), indexFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer os.Remove(fp.Name())
+
+	if err := idx.Encode(fp); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+
+	// replace the index atomically
+	if err := os.Rename(fp.Name(), indexPath); err != nil {
+		return fmt.Errorf("failed to replace index file: %w", err)
+	}
+	return nil
+}
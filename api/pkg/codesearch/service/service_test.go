@@ -0,0 +1,54 @@
+package service
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"getsturdy.com/api/pkg/codesearch/index"
+	"getsturdy.com/api/vcs"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test",
+		"-c", "user.email=test@getsturdy.com",
+	}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+func TestApplyDiffSkipsSubmodules(t *testing.T) {
+	repoPath := t.TempDir()
+	runGit(t, repoPath, "init")
+	require.NoError(t, os.WriteFile(path.Join(repoPath, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	runGit(t, repoPath, "add", "main.go")
+	// a gitlink entry, pointing to a commit that is not in the repository
+	runGit(t, repoPath, "update-index", "--add", "--cacheinfo", "160000,0123456789abcdef0123456789abcdef01234567,vendor/lib")
+	runGit(t, repoPath, "commit", "-m", "initial")
+	commitID := runGit(t, repoPath, "rev-parse", "HEAD")
+	commitID = commitID[:len(commitID)-1]
+
+	repo, err := vcs.OpenRepo(repoPath)
+	require.NoError(t, err)
+
+	diff, err := repo.DiffCommitToRoot(commitID)
+	require.NoError(t, err)
+	defer diff.Free()
+
+	numDeltas, err := diff.NumDeltas()
+	require.NoError(t, err)
+	require.Equal(t, 2, numDeltas, "the submodule is part of the diff")
+
+	idx := index.New()
+	require.NoError(t, applyDiff(repo, idx, commitID, diff))
+
+	assert.Equal(t, 1, idx.Len())
+	assert.Equal(t, []string{"main.go"}, idx.Candidates("func main"))
+}
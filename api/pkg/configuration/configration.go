@@ -6,15 +6,20 @@ import (
 	"os"
 
 	proxy "getsturdy.com/api/pkg/analytics/proxy/configuration"
+	runner "getsturdy.com/api/pkg/ci/runner/configuration"
 	service_ci "getsturdy.com/api/pkg/ci/service/configuration"
 	db "getsturdy.com/api/pkg/db/configuration"
 	"getsturdy.com/api/pkg/di"
 	gitserver "getsturdy.com/api/pkg/gitserver/configuration"
 	http "getsturdy.com/api/pkg/http/configuration"
+	ldap "getsturdy.com/api/pkg/ldap/configuration"
 	logger "getsturdy.com/api/pkg/logger/configuration"
 	metrics "getsturdy.com/api/pkg/metrics/configuration"
+	mutagen "getsturdy.com/api/pkg/mutagen/configuration"
+	oidc "getsturdy.com/api/pkg/oidc/configuration"
 	pprof "getsturdy.com/api/pkg/pprof/configuration"
 	uploader "getsturdy.com/api/pkg/users/avatars/uploader/configuration"
+	executor "getsturdy.com/api/vcs/executor/configuration"
 	provider "getsturdy.com/api/vcs/provider/configuration"
 
 	"github.com/jessevdk/go-flags"
@@ -24,13 +29,18 @@ type Base struct {
 	di.Out
 
 	Provider *provider.Configuration   `flags-group:"vcs" namespace:"vcs"`
+	Executor *executor.Configuration   `flags-group:"executor" namespace:"vcs.executor"`
 	DB       *db.Configuration         `flags-group:"db" namespace:"db"`
 	CI       *service_ci.Configuration `flags-group:"ci" namespace:"ci"`
+	Runner   *runner.Configuration     `flags-group:"ci-runner" namespace:"ci.runner"`
 	HTTP     *http.Configuration       `flags-group:"http" namespace:"http"`
 	Git      *gitserver.Configuration  `flags-group:"git" namespace:"git"`
 	Pprof    *pprof.Configuration      `flags-group:"pprof" namespace:"pprof"`
 	Metrics  *metrics.Configuration    `flags-group:"metrics" namespace:"metrics"`
 	Logger   *logger.Configuration     `flags-group:"logger" namespace:"logger"`
+	OIDC     *oidc.Configuration       `flags-group:"oidc" namespace:"oidc"`
+	Mutagen  *mutagen.Configuration    `flags-group:"mutagen" namespace:"mutagen"`
+	LDAP     *ldap.Configuration       `flags-group:"ldap" namespace:"ldap"`
 }
 
 type Configuration struct {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	proxy "getsturdy.com/api/pkg/analytics/proxy/configuration"
+	runner "getsturdy.com/api/pkg/ci/runner/configuration"
 	service_ci "getsturdy.com/api/pkg/ci/service/configuration"
 	"getsturdy.com/api/pkg/configuration/flags"
 	db "getsturdy.com/api/pkg/db/configuration"
@@ -12,10 +13,14 @@ import (
 	gitserver "getsturdy.com/api/pkg/gitserver/configuration"
 	http "getsturdy.com/api/pkg/http/configuration"
 	"getsturdy.com/api/pkg/internal/sturdytest"
+	ldap "getsturdy.com/api/pkg/ldap/configuration"
 	logger "getsturdy.com/api/pkg/logger/configuration"
 	metrics "getsturdy.com/api/pkg/metrics/configuration"
+	mutagen "getsturdy.com/api/pkg/mutagen/configuration"
+	oidc "getsturdy.com/api/pkg/oidc/configuration"
 	pprof "getsturdy.com/api/pkg/pprof/configuration"
 	uploader "getsturdy.com/api/pkg/users/avatars/uploader/configuration"
+	executor "getsturdy.com/api/vcs/executor/configuration"
 	provider "getsturdy.com/api/vcs/provider/configuration"
 )
 
@@ -61,11 +66,13 @@ func TestModule(c *di.Container) {
 					ReposPath: tmpPath,
 					LFS:       &provider.GitLFSConfiguration{Addr: lfsAddr},
 				},
+				Executor: &executor.Configuration{Locks: executor.LocksLocal},
 				DB: &db.Configuration{
 					URL:            dbURL,
 					ConnectTimeout: time.Second,
 				},
 				CI:      &service_ci.Configuration{PublicAPIHostname: "localhost"},
+				Runner:  &runner.Configuration{},
 				HTTP:    &http.Configuration{Addr: httpAddr},
 				Git:     &gitserver.Configuration{},
 				Pprof:   &pprof.Configuration{Addr: pprofAddr},
@@ -73,6 +80,9 @@ func TestModule(c *di.Container) {
 				Logger: &logger.Configuration{
 					Level: "INFO",
 				},
+				OIDC:    &oidc.Configuration{},
+				Mutagen: &mutagen.Configuration{},
+				LDAP:    &ldap.Configuration{},
 			},
 
 			Analytics: &proxy.Configuration{Disable: true},
@@ -0,0 +1,4 @@
+DROP INDEX workspaces_parent_workspace_id_idx;
+
+ALTER TABLE workspaces
+    DROP COLUMN parent_workspace_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX changes_search_idx;
+
+DROP TABLE change_paths;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE change_paths
+(
+    change_id   TEXT NOT NULL,
+    codebase_id TEXT NOT NULL,
+    path        TEXT NOT NULL,
+    old_path    TEXT,
+    PRIMARY KEY (change_id, path)
+);
+
+CREATE INDEX change_paths_codebase_id_path_idx ON change_paths (codebase_id, path);
+CREATE INDEX change_paths_codebase_id_old_path_idx ON change_paths (codebase_id, old_path);
+
+CREATE INDEX changes_search_idx ON changes USING GIN (to_tsvector('english', COALESCE(title, '') || ' ' || updated_description));
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE organization_members
+    DROP COLUMN role;
\ No newline at end of file
@@ -0,0 +1,22 @@
+ALTER TABLE organization_members
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+-- everyone used to be able to manage their organizations, keep it that way for existing members
+UPDATE organization_members
+SET role = 'admin';
+
+UPDATE organization_members m
+SET role = 'owner'
+FROM organizations o
+WHERE m.organization_id = o.id
+  AND m.user_id = o.created_by;
+
+-- organizations where the creator is no longer a member are owned by all members
+UPDATE organization_members m
+SET role = 'owner'
+WHERE m.deleted_at IS NULL
+  AND NOT EXISTS(SELECT 1
+                 FROM organization_members o
+                 WHERE o.organization_id = m.organization_id
+                   AND o.role = 'owner'
+                   AND o.deleted_at IS NULL);
\ No newline at end of file
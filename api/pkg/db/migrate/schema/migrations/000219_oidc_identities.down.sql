@@ -0,0 +1 @@
+DROP TABLE oidc_identities;
\ No newline at end of file
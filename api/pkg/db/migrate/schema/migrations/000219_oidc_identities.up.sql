@@ -0,0 +1,13 @@
+CREATE TABLE oidc_identities
+(
+    id            TEXT        NOT NULL PRIMARY KEY,
+    issuer        TEXT        NOT NULL,
+    subject       TEXT        NOT NULL,
+    user_id       TEXT        NOT NULL,
+    groups        TEXT[]      NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL,
+    last_login_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX oidc_identities_issuer_subject_idx ON oidc_identities (issuer, subject);
+CREATE INDEX oidc_identities_user_id_idx ON oidc_identities (user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE jwt_revocations;
\ No newline at end of file
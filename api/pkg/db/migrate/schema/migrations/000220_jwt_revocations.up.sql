@@ -0,0 +1,5 @@
+CREATE TABLE jwt_revocations
+(
+    subject    TEXT        NOT NULL PRIMARY KEY,
+    revoked_at TIMESTAMPTZ NOT NULL
+);
\ No newline at end of file
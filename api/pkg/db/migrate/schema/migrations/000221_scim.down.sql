@@ -0,0 +1,3 @@
+DROP TABLE scim_group_members;
+DROP TABLE scim_groups;
+DROP TABLE scim_tokens;
\ No newline at end of file
@@ -0,0 +1,32 @@
+CREATE TABLE scim_tokens
+(
+    id              TEXT        NOT NULL PRIMARY KEY,
+    organization_id TEXT        NOT NULL,
+    hash            BYTEA       NOT NULL,
+    created_by      TEXT        NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    last_used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX scim_tokens_organization_id_idx ON scim_tokens (organization_id);
+
+CREATE TABLE scim_groups
+(
+    id              TEXT        NOT NULL PRIMARY KEY,
+    organization_id TEXT        NOT NULL,
+    display_name    TEXT        NOT NULL,
+    external_id     TEXT,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX scim_groups_organization_id_display_name_idx ON scim_groups (organization_id, display_name);
+
+CREATE TABLE scim_group_members
+(
+    group_id TEXT NOT NULL,
+    user_id  TEXT NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX scim_group_members_user_id_idx ON scim_group_members (user_id);
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE personal_access_tokens
+(
+    id           TEXT        NOT NULL PRIMARY KEY,
+    user_id      TEXT        NOT NULL,
+    name         TEXT        NOT NULL,
+    hash         BYTEA       NOT NULL,
+    scopes       TEXT[]      NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX personal_access_tokens_user_id_idx ON personal_access_tokens (user_id);
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE audit_log
+(
+    id              TEXT        NOT NULL PRIMARY KEY,
+    organization_id TEXT,
+    codebase_id     TEXT,
+    actor_type      TEXT        NOT NULL,
+    actor_id        TEXT,
+    ip              TEXT,
+    action          TEXT        NOT NULL,
+    target_type     TEXT        NOT NULL,
+    target_id       TEXT        NOT NULL,
+    before          JSONB,
+    after           JSONB,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX audit_log_organization_id_created_at_idx ON audit_log (organization_id, created_at);
+CREATE INDEX audit_log_codebase_id_created_at_idx ON audit_log (codebase_id, created_at);
+
+-- the audit log is append-only
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE organizations DROP COLUMN require_two_factor;
+
+DROP TABLE user_two_factor_recovery_codes;
+
+DROP TABLE user_two_factor;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE user_two_factor
+(
+    user_id         TEXT        NOT NULL PRIMARY KEY,
+    secret          TEXT        NOT NULL,
+    last_used_step  BIGINT      NOT NULL DEFAULT 0,
+    failed_attempts INTEGER     NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL,
+    confirmed_at    TIMESTAMPTZ
+);
+
+CREATE TABLE user_two_factor_recovery_codes
+(
+    id         TEXT        NOT NULL PRIMARY KEY,
+    user_id    TEXT        NOT NULL,
+    hash       BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX user_two_factor_recovery_codes_user_id_idx ON user_two_factor_recovery_codes (user_id);
+
+ALTER TABLE organizations
+    ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE ldap_syncs;
+DROP TABLE ldap_group_members;
+DROP TABLE ldap_groups;
+DROP TABLE ldap_identities;
\ No newline at end of file
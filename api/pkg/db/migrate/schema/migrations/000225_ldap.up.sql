@@ -0,0 +1,46 @@
+CREATE TABLE ldap_identities
+(
+    id            TEXT        NOT NULL PRIMARY KEY,
+    dn            TEXT        NOT NULL,
+    user_id       TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL,
+    last_login_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX ldap_identities_dn_idx ON ldap_identities (dn);
+CREATE INDEX ldap_identities_user_id_idx ON ldap_identities (user_id);
+
+CREATE TABLE ldap_groups
+(
+    id                TEXT        NOT NULL PRIMARY KEY,
+    dn                TEXT        NOT NULL,
+    name              TEXT        NOT NULL,
+    unmatched_members TEXT[]      NOT NULL DEFAULT '{}',
+    added_user_ids    TEXT[]      NOT NULL DEFAULT '{}',
+    removed_user_ids  TEXT[]      NOT NULL DEFAULT '{}',
+    created_at        TIMESTAMPTZ NOT NULL,
+    synced_at         TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX ldap_groups_dn_idx ON ldap_groups (dn);
+
+CREATE TABLE ldap_group_members
+(
+    group_id TEXT NOT NULL,
+    user_id  TEXT NOT NULL,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX ldap_group_members_user_id_idx ON ldap_group_members (user_id);
+
+CREATE TABLE ldap_syncs
+(
+    id             TEXT        NOT NULL PRIMARY KEY,
+    started_at     TIMESTAMPTZ NOT NULL,
+    finished_at    TIMESTAMPTZ,
+    error          TEXT,
+    added_groups   TEXT[]      NOT NULL DEFAULT '{}',
+    removed_groups TEXT[]      NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX ldap_syncs_started_at_idx ON ldap_syncs (started_at);
\ No newline at end of file
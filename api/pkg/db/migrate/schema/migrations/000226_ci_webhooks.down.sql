@@ -0,0 +1,2 @@
+DROP TABLE ci_webhook_deliveries;
+DROP TABLE ci_configurations_webhook;
\ No newline at end of file
@@ -0,0 +1,26 @@
+CREATE TABLE ci_configurations_webhook
+(
+    id             TEXT        NOT NULL PRIMARY KEY,
+    codebase_id    TEXT        NOT NULL,
+    integration_id TEXT        NOT NULL UNIQUE,
+    url            TEXT        NOT NULL,
+    secret         TEXT        NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL,
+    updated_at     TIMESTAMPTZ
+);
+
+CREATE TABLE ci_webhook_deliveries
+(
+    id             TEXT        NOT NULL PRIMARY KEY,
+    integration_id TEXT        NOT NULL,
+    codebase_id    TEXT        NOT NULL,
+    event          TEXT        NOT NULL,
+    commit_sha     TEXT        NOT NULL,
+    attempts       INTEGER     NOT NULL,
+    status_code    INTEGER,
+    error          TEXT,
+    created_at     TIMESTAMPTZ NOT NULL,
+    finished_at    TIMESTAMPTZ
+);
+
+CREATE INDEX ci_webhook_deliveries_integration_id_created_at_idx ON ci_webhook_deliveries (integration_id, created_at);
\ No newline at end of file
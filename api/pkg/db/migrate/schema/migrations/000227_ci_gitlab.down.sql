@@ -0,0 +1,2 @@
+DROP TABLE ci_gitlab_pipelines;
+DROP TABLE ci_configurations_gitlab;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE ci_configurations_gitlab
+(
+    id             TEXT        NOT NULL PRIMARY KEY,
+    codebase_id    TEXT        NOT NULL,
+    integration_id TEXT        NOT NULL UNIQUE,
+    instance_url   TEXT        NOT NULL,
+    project_id     TEXT        NOT NULL,
+    ref            TEXT        NOT NULL,
+    trigger_token  TEXT        NOT NULL,
+    webhook_token  TEXT        NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL,
+    updated_at     TIMESTAMPTZ
+);
+
+CREATE TABLE ci_gitlab_pipelines
+(
+    id                TEXT        NOT NULL PRIMARY KEY,
+    integration_id    TEXT        NOT NULL,
+    gitlab_id         BIGINT      NOT NULL,
+    gitlab_project_id BIGINT      NOT NULL,
+    ci_repo_commit_id TEXT        NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX ci_gitlab_pipelines_gitlab_project_id_gitlab_id_idx ON ci_gitlab_pipelines (gitlab_project_id, gitlab_id);
\ No newline at end of file
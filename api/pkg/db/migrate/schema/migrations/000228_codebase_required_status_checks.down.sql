@@ -0,0 +1,2 @@
+ALTER TABLE codebases
+    DROP COLUMN required_status_checks;
\ No newline at end of file
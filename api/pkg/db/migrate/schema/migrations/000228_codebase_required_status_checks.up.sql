@@ -0,0 +1,2 @@
+ALTER TABLE codebases
+    ADD COLUMN required_status_checks TEXT[];
\ No newline at end of file
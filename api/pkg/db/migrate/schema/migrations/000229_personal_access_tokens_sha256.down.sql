@@ -0,0 +1 @@
+-- Nah
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Tokens created before the secrets were hashed with SHA-256 have bcrypt hashes, and can't be verified anymore
+UPDATE personal_access_tokens
+SET revoked_at = NOW()
+WHERE revoked_at IS NULL
+  AND octet_length(hash) <> 32;
\ No newline at end of file
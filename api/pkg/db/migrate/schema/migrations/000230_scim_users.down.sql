@@ -0,0 +1 @@
+DROP TABLE scim_users;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE scim_users
+(
+    organization_id TEXT        NOT NULL,
+    user_id         TEXT        NOT NULL,
+    active          BOOLEAN     NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
\ No newline at end of file
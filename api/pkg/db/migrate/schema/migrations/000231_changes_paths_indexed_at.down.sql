@@ -0,0 +1,3 @@
+DROP INDEX changes_paths_not_indexed_idx;
+
+ALTER TABLE changes DROP COLUMN paths_indexed_at;
\ No newline at end of file
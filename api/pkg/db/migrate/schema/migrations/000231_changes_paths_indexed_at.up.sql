@@ -0,0 +1,6 @@
+ALTER TABLE changes ADD COLUMN paths_indexed_at TIMESTAMP WITH TIME ZONE;
+
+-- changes that have been indexed since change_paths was added
+UPDATE changes SET paths_indexed_at = NOW() WHERE id IN (SELECT DISTINCT change_id FROM change_paths);
+
+CREATE INDEX changes_paths_not_indexed_idx ON changes (id) WHERE paths_indexed_at IS NULL AND commit_id IS NOT NULL;
\ No newline at end of file
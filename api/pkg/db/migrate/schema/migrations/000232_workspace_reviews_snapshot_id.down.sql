@@ -0,0 +1 @@
+ALTER TABLE workspace_reviews DROP COLUMN snapshot_id;
\ No newline at end of file
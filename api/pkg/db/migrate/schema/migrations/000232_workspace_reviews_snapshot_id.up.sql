@@ -0,0 +1 @@
+ALTER TABLE workspace_reviews ADD COLUMN snapshot_id TEXT;
\ No newline at end of file
@@ -11,6 +11,7 @@ func NewFeaturesRootResolver() resolvers.FeaturesRootResolver {
 func (r *FeaturesRootResolver) Features() []resolvers.Feature {
 	return []resolvers.Feature{
 		resolvers.FeatureBuildkite,
+		resolvers.FeatureGitLab,
 		resolvers.FeatureRemote,
 		resolvers.FeatureGitHub,
 		resolvers.FeatureMultiTenancy,
@@ -1,5 +1,7 @@
 package file
 
+import "getsturdy.com/api/pkg/changes"
+
 type Type string
 
 const (
@@ -8,3 +10,11 @@ const (
 	BinaryType  Type = "binary"
 	ImageType   Type = "image"
 )
+
+// BlameRange is a range of lines in a file, that were last changed by the same change.
+type BlameRange struct {
+	// StartLine is the first line of the range, starting at 1.
+	StartLine int
+	Lines     int
+	Change    *changes.Change
+}
@@ -1,11 +1,14 @@
 package graphql
 
 import (
+	"context"
 	"fmt"
 	"path"
 
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/file"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 
 	"github.com/graph-gophers/graphql-go"
@@ -14,6 +17,7 @@ import (
 type fileResolver struct {
 	root       *fileRootResolver
 	codebaseID codebases.ID
+	commitID   string
 	path       string
 	contents   []byte
 	change     *changes.Change
@@ -67,3 +71,37 @@ func (r *fileResolver) MimeType() string {
 func (r *fileResolver) Info() resolvers.FileInfoResolver {
 	return r.root.InternalFileInfoOnChange(r.ID(), r.path, r.change, true)
 }
+
+func (r *fileResolver) Blame(ctx context.Context) ([]resolvers.BlameRangeResolver, error) {
+	ranges, err := r.root.fileService.Blame(ctx, r.codebaseID, r.commitID, r.path)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.BlameRangeResolver, 0, len(ranges))
+	for _, blameRange := range ranges {
+		res = append(res, &blameRangeResolver{root: r.root, blameRange: blameRange})
+	}
+	return res, nil
+}
+
+type blameRangeResolver struct {
+	root       *fileRootResolver
+	blameRange file.BlameRange
+}
+
+func (r *blameRangeResolver) StartLine() int32 {
+	return int32(r.blameRange.StartLine)
+}
+
+func (r *blameRangeResolver) EndLine() int32 {
+	return int32(r.blameRange.StartLine + r.blameRange.Lines - 1)
+}
+
+func (r *blameRangeResolver) Change(ctx context.Context) (resolvers.ChangeResolver, error) {
+	if r.blameRange.Change == nil {
+		return nil, nil
+	}
+	id := graphql.ID(r.blameRange.Change.ID)
+	return (*r.root.changeRootResolver).Change(ctx, resolvers.ChangeArgs{ID: &id})
+}
@@ -20,6 +20,12 @@ import (
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
 	service_file "getsturdy.com/api/pkg/file/service"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	configuration_ldap "getsturdy.com/api/pkg/ldap/configuration"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	configuration_oidc "getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
 	db_users "getsturdy.com/api/pkg/users/db"
 	service_user "getsturdy.com/api/pkg/users/service"
 	"getsturdy.com/api/vcs"
@@ -55,11 +61,16 @@ func TestFileResolver(t *testing.T) {
 	)
 
 	aclProvider := provider_acl.New(
+		zap.NewNop(),
 		aclRepo,
 		nil,
 		nil,
+		nil,
 	)
 
+	oidcService := service_oidc.New(zap.NewNop(), &configuration_oidc.Configuration{}, db_oidc.NewMemory(), userService, nil)
+	ldapService := service_ldap.New(zap.NewNop(), &configuration_ldap.Configuration{}, nil, nil, nil, nil, nil)
+
 	authService := service_auth.New(
 		nil,
 		nil,
@@ -67,16 +78,20 @@ func TestFileResolver(t *testing.T) {
 		nil,
 		aclProvider,
 		nil,
+		oidcService,
+		nil,
+		ldapService,
+		nil,
 	)
 
-	fileService := service_file.New(executorProvider, nil, nil)
-
 	changeRepo := db_change.NewInMemoryRepo()
 
 	codebaseRepo := db_codebases.NewMemory()
 
 	changeService := service_change.New(changeRepo, codebaseRepo, logger, executorProvider, nil)
 
+	fileService := service_file.New(executorProvider, nil, nil, changeService)
+
 	aclID := uuid.NewString()
 	userID := uuid.NewString()
 	restrictedUserReadmeUserID := uuid.NewString()
@@ -158,7 +173,8 @@ func TestFileResolver(t *testing.T) {
 
 	ctx := auth.NewContext(context.Background(), &auth.Subject{ID: userID, Type: auth.SubjectUser})
 
-	root := NewFileRootResolver(executorProvider, authService, fileService, changeService)
+	changeRootResolver := new(resolvers.ChangeRootResolver)
+	root := NewFileRootResolver(executorProvider, authService, fileService, changeService, changeRootResolver)
 	fileResolver, err := root.InternalFile(ctx, &codebases.Codebase{ID: codebaseID}, "README.md", "README.markdown")
 	assert.Error(t, err, gqlerrors.ErrNotFound)
 	assert.Nil(t, fileResolver)
@@ -188,6 +204,21 @@ func TestFileResolver(t *testing.T) {
 
 	})
 
+	t.Run("blame readme", func(t *testing.T) {
+		fileResolver, err = root.InternalFile(ctx, &codebases.Codebase{ID: codebaseID}, "README.md")
+		assert.NoError(t, err)
+		if assert.NotNil(t, fileResolver) {
+			fileResolver, ok := fileResolver.ToFile()
+			assert.True(t, ok)
+			blame, err := fileResolver.Blame(ctx)
+			assert.NoError(t, err)
+			if assert.Len(t, blame, 1) {
+				assert.Equal(t, int32(1), blame[0].StartLine())
+				assert.Equal(t, int32(1), blame[0].EndLine())
+			}
+		}
+	})
+
 	t.Run("list root not see not_allowed.txt", func(t *testing.T) {
 		fileResolver, err = root.InternalFile(ctx, &codebases.Codebase{ID: codebaseID}, "/")
 		assert.NoError(t, err)
@@ -27,6 +27,8 @@ type fileRootResolver struct {
 	authService      *service_auth.Service
 	fileService      *service_file.Service
 	changeService    *service_change.Service
+
+	changeRootResolver *resolvers.ChangeRootResolver
 }
 
 func NewFileRootResolver(
@@ -34,12 +36,15 @@ func NewFileRootResolver(
 	authService *service_auth.Service,
 	fileService *service_file.Service,
 	changeService *service_change.Service,
+	changeRootResolver *resolvers.ChangeRootResolver,
 ) resolvers.FileRootResolver {
 	return &fileRootResolver{
 		executorProvider: executorProvider,
 		authService:      authService,
 		fileService:      fileService,
 		changeService:    changeService,
+
+		changeRootResolver: changeRootResolver,
 	}
 }
 
@@ -80,6 +85,7 @@ func (r *fileRootResolver) InternalFile(ctx context.Context, codebase *codebases
 					resolver = &fileResolver{
 						root:       r,
 						codebaseID: codebase.ID,
+						commitID:   headCommit.Id().String(),
 						path:       variantName,
 						contents:   contents,
 						change:     headChange,
@@ -5,6 +5,7 @@ import (
 	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/di"
 	service_file "getsturdy.com/api/pkg/file/service"
+	"getsturdy.com/api/pkg/graphql/resolvers"
 	"getsturdy.com/api/vcs/executor"
 )
 
@@ -13,5 +14,6 @@ func Module(c *di.Container) {
 	c.Import(service_auth.Module)
 	c.Import(service_change.Module)
 	c.Import(service_file.Module)
+	c.Import(resolvers.Module)
 	c.Register(NewFileRootResolver)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases/acl/provider"
 	"getsturdy.com/api/pkg/di"
 	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
@@ -11,5 +12,6 @@ func Module(c *di.Container) {
 	c.Import(executor.Module)
 	c.Import(provider.Module)
 	c.Import(db_snapshots.Module)
+	c.Import(service_change.Module)
 	c.Register(New)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -13,13 +14,16 @@ import (
 	"strings"
 
 	"github.com/h2non/filetype"
+	lru "github.com/hashicorp/golang-lru"
 
 	"getsturdy.com/api/pkg/changes"
+	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/comments/live"
 	"getsturdy.com/api/pkg/file"
 	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
 	"getsturdy.com/api/pkg/workspaces"
+	"getsturdy.com/api/vcs"
 	"getsturdy.com/api/vcs/executor"
 	provider "getsturdy.com/api/vcs/provider/configuration"
 )
@@ -28,17 +32,27 @@ type Service struct {
 	executorProvider executor.Provider
 	snapshotsRepo    db_snapshots.Repository
 	vcsConfiguration *provider.Configuration
+	changeService    *service_change.Service
+
+	blameCache *lru.Cache
 }
 
+const blameCacheSize = 1024
+
 func New(
 	executorProvider executor.Provider,
 	snapshotsRepo db_snapshots.Repository,
 	vcsConfiguration *provider.Configuration,
+	changeService *service_change.Service,
 ) *Service {
+	// only fails if the size is not positive
+	blameCache, _ := lru.New(blameCacheSize)
 	return &Service{
 		executorProvider: executorProvider,
 		snapshotsRepo:    snapshotsRepo,
 		vcsConfiguration: vcsConfiguration,
+		changeService:    changeService,
+		blameCache:       blameCache,
 	}
 }
 
@@ -185,3 +199,55 @@ func (s *Service) checksum(fp fs.File) (string, error) {
 	sum = sh.Sum(sum)
 	return fmt.Sprintf("%x", sum), nil
 }
+
+type blameCacheKey struct {
+	codebaseID codebases.ID
+	commitID   string
+	filePath   string
+}
+
+// Blame returns the changes that last changed each of the lines in the file, as it looks on the trunk at commitID.
+// The change of a range is nil if the lines were last changed in a commit that is not a change.
+//
+// Blaming a file is expensive, the results are cached per trunk commit.
+func (s *Service) Blame(ctx context.Context, codebaseID codebases.ID, commitID, filePath string) ([]file.BlameRange, error) {
+	key := blameCacheKey{codebaseID: codebaseID, commitID: commitID, filePath: filePath}
+
+	var hunks []vcs.BlameHunk
+	if cached, ok := s.blameCache.Get(key); ok {
+		hunks = cached.([]vcs.BlameHunk)
+	} else {
+		if err := s.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+			var err error
+			hunks, err = repo.BlameFile(commitID, filePath)
+			return err
+		}).ExecTrunk(codebaseID, "blameFile"); err != nil {
+			return nil, fmt.Errorf("failed to blame file: %w", err)
+		}
+		s.blameCache.Add(key, hunks)
+	}
+
+	changesByCommitID := make(map[string]*changes.Change)
+	ranges := make([]file.BlameRange, 0, len(hunks))
+	for _, hunk := range hunks {
+		ch, ok := changesByCommitID[hunk.CommitID]
+		if !ok {
+			var err error
+			ch, err = s.changeService.GetByCommitAndCodebase(ctx, hunk.CommitID, codebaseID)
+			switch {
+			case errors.Is(err, service_change.ErrNotFound):
+				// the commit is not a change, for example the root commit
+			case err != nil:
+				return nil, fmt.Errorf("failed to get change for commit %s: %w", hunk.CommitID, err)
+			}
+			changesByCommitID[hunk.CommitID] = ch
+		}
+		ranges = append(ranges, file.BlameRange{
+			StartLine: hunk.StartLine,
+			Lines:     hunk.Lines,
+			Change:    ch,
+		})
+	}
+
+	return ranges, nil
+}
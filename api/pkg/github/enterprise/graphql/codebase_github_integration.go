@@ -104,7 +104,7 @@ func (r *codebaseGitHubIntegrationRootResolver) UpdateCodebaseGitHubIntegration(
 		return nil, gqlerrors.Error(err)
 	}
 
-	if err := r.authService.CanWrite(ctx, repo); err != nil {
+	if err := r.authService.CanAdmin(ctx, repo); err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
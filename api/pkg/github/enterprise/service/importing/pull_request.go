@@ -307,6 +307,11 @@ func (svc *Service) mergePullRequest(
 		return fmt.Errorf("failed to create change: %w", err)
 	}
 
+	if err := svc.changeService.IndexPaths(ctx, ch); err != nil {
+		svc.logger.Error("failed to index paths of change", zap.Error(err))
+		// do not fail
+	}
+
 	svc.analyticsService.Capture(ctx, "pull request merged",
 		analytics.DistinctID(ws.UserID.String()),
 		analytics.CodebaseID(ws.CodebaseID),
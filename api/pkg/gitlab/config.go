@@ -0,0 +1,39 @@
+package gitlab
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+)
+
+// PipelineTitle is the title of the status of a pipeline, the statuses of jobs are prefixed with it.
+const PipelineTitle = "GitLab"
+
+type Config struct {
+	ID            string       `db:"id"`
+	CodebaseID    codebases.ID `db:"codebase_id"`
+	IntegrationID string       `db:"integration_id"`
+
+	// InstanceURL is the url of the GitLab instance, such as https://gitlab.com
+	InstanceURL string `db:"instance_url"`
+	// ProjectID is the numeric id, or the full path, of the project that runs the pipelines
+	ProjectID string `db:"project_id"`
+	// Ref is the branch in the project that has the pipeline configuration
+	Ref          string    `db:"ref"`
+	TriggerToken string    `db:"trigger_token"`
+	WebhookToken string    `db:"webhook_token"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// Pipeline is a pipeline that has been triggered by Sturdy, it's used to find the commit that pipeline and job
+// events are for.
+type Pipeline struct {
+	ID              string `db:"id"`
+	IntegrationID   string `db:"integration_id"`
+	GitLabID        int64  `db:"gitlab_id"`
+	GitLabProjectID int64  `db:"gitlab_project_id"`
+	// CiRepoCommitSHA is the commit in the ci repository that the pipeline builds
+	CiRepoCommitSHA string    `db:"ci_repo_commit_id"`
+	CreatedAt       time.Time `db:"created_at"`
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{db: db}
+}
+
+func (d *database) Create(ctx context.Context, cfg *gitlab.Config) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO ci_configurations_gitlab
+			(id, codebase_id, integration_id, instance_url, project_id, ref, trigger_token, webhook_token, created_at)
+		VALUES
+			(:id, :codebase_id, :integration_id, :instance_url, :project_id, :ref, :trigger_token, :webhook_token, :created_at)
+	`, cfg); err != nil {
+		return fmt.Errorf("failed to insert ci_configurations_gitlab: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, cfg *gitlab.Config) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE ci_configurations_gitlab
+		SET
+			instance_url = :instance_url,
+			project_id = :project_id,
+			ref = :ref,
+			trigger_token = :trigger_token,
+			webhook_token = :webhook_token,
+			updated_at = :updated_at
+		WHERE
+			id = :id
+	`, cfg); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) GetConfigsByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*gitlab.Config, error) {
+	var cfgs []*gitlab.Config
+	if err := d.db.SelectContext(ctx, &cfgs, `
+		SELECT
+			id, codebase_id, integration_id, instance_url, project_id, ref, trigger_token, webhook_token, created_at
+		FROM ci_configurations_gitlab
+		WHERE codebase_id = $1
+	`, codebaseID); err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	return cfgs, nil
+}
+
+func (d *database) GetConfigByIntegrationID(ctx context.Context, integrationID string) (*gitlab.Config, error) {
+	var cfg gitlab.Config
+	if err := d.db.GetContext(ctx, &cfg, `
+		SELECT
+			id, codebase_id, integration_id, instance_url, project_id, ref, trigger_token, webhook_token, created_at
+		FROM ci_configurations_gitlab
+		WHERE integration_id = $1
+	`, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	return &cfg, nil
+}
+
+var _ PipelineRepository = &pipelineDatabase{}
+
+type pipelineDatabase struct {
+	db *sqlx.DB
+}
+
+func NewPipelineDatabase(db *sqlx.DB) PipelineRepository {
+	return &pipelineDatabase{db: db}
+}
+
+func (d *pipelineDatabase) Create(ctx context.Context, pipeline *gitlab.Pipeline) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO ci_gitlab_pipelines
+			(id, integration_id, gitlab_id, gitlab_project_id, ci_repo_commit_id, created_at)
+		VALUES
+			(:id, :integration_id, :gitlab_id, :gitlab_project_id, :ci_repo_commit_id, :created_at)
+	`, pipeline); err != nil {
+		return fmt.Errorf("failed to insert ci_gitlab_pipelines: %w", err)
+	}
+	return nil
+}
+
+func (d *pipelineDatabase) ListByGitLabID(ctx context.Context, gitLabProjectID, gitLabID int64) ([]*gitlab.Pipeline, error) {
+	var pipelines []*gitlab.Pipeline
+	if err := d.db.SelectContext(ctx, &pipelines, `
+		SELECT
+			id, integration_id, gitlab_id, gitlab_project_id, ci_repo_commit_id, created_at
+		FROM ci_gitlab_pipelines
+		WHERE gitlab_project_id = $1 AND gitlab_id = $2
+	`, gitLabProjectID, gitLabID); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	return pipelines, nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	byID            map[string]*gitlab.Config
+	byIntegrationID map[string]*gitlab.Config
+}
+
+func NewInMemory() *memory {
+	return &memory{
+		byID:            make(map[string]*gitlab.Config),
+		byIntegrationID: make(map[string]*gitlab.Config),
+	}
+}
+
+func (m *memory) Create(ctx context.Context, cfg *gitlab.Config) error {
+	m.byID[cfg.ID] = cfg
+	m.byIntegrationID[cfg.IntegrationID] = cfg
+	return nil
+}
+
+func (m *memory) Update(ctx context.Context, cfg *gitlab.Config) error {
+	m.byID[cfg.ID] = cfg
+	m.byIntegrationID[cfg.IntegrationID] = cfg
+	return nil
+}
+
+func (m *memory) GetConfigsByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*gitlab.Config, error) {
+	var res []*gitlab.Config
+	for _, v := range m.byID {
+		if v.CodebaseID == codebaseID {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+func (m *memory) GetConfigByIntegrationID(ctx context.Context, integrationID string) (*gitlab.Config, error) {
+	cfg, found := m.byIntegrationID[integrationID]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return cfg, nil
+}
+
+var _ PipelineRepository = &pipelineMemory{}
+
+type pipelineMemory struct {
+	pipelines []*gitlab.Pipeline
+}
+
+func NewPipelineInMemory() *pipelineMemory {
+	return &pipelineMemory{}
+}
+
+func (m *pipelineMemory) Create(ctx context.Context, pipeline *gitlab.Pipeline) error {
+	m.pipelines = append(m.pipelines, pipeline)
+	return nil
+}
+
+func (m *pipelineMemory) ListByGitLabID(ctx context.Context, gitLabProjectID, gitLabID int64) ([]*gitlab.Pipeline, error) {
+	var res []*gitlab.Pipeline
+	for _, p := range m.pipelines {
+		if p.GitLabProjectID == gitLabProjectID && p.GitLabID == gitLabID {
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
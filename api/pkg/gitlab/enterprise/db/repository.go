@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+type Repository interface {
+	Create(context.Context, *gitlab.Config) error
+	Update(context.Context, *gitlab.Config) error
+	GetConfigsByCodebaseID(context.Context, codebases.ID) ([]*gitlab.Config, error)
+	GetConfigByIntegrationID(ctx context.Context, integrationID string) (*gitlab.Config, error)
+}
+
+type PipelineRepository interface {
+	Create(context.Context, *gitlab.Pipeline) error
+	// ListByGitLabID returns the pipelines with the given ids. Ids are only unique within a GitLab instance, so
+	// pipelines from different instances can have the same ids.
+	ListByGitLabID(ctx context.Context, gitLabProjectID, gitLabID int64) ([]*gitlab.Pipeline, error)
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"github.com/graph-gophers/graphql-go"
+
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+type configurationResolver struct {
+	cfg *gitlab.Config
+}
+
+func (r *configurationResolver) ID() graphql.ID {
+	return graphql.ID(r.cfg.ID)
+}
+
+func (r *configurationResolver) InstanceURL() string {
+	return r.cfg.InstanceURL
+}
+
+func (r *configurationResolver) ProjectID() string {
+	return r.cfg.ProjectID
+}
+
+func (r *configurationResolver) Ref() string {
+	return r.cfg.Ref
+}
+
+func (r *configurationResolver) TriggerToken() string {
+	return r.cfg.TriggerToken
+}
+
+func (r *configurationResolver) WebhookToken() string {
+	return r.cfg.WebhookToken
+}
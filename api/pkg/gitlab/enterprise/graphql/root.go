@@ -0,0 +1,179 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_ci "getsturdy.com/api/pkg/ci/service"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+	service_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/service"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/integrations"
+	"getsturdy.com/api/pkg/integrations/providers"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInstanceURL = "https://gitlab.com"
+	defaultRef         = "main"
+)
+
+var seedFiles = []string{
+	".gitlab-ci.yml",
+}
+
+type rootResolver struct {
+	authService                    *service_auth.Service
+	gitLabService                  *service_gitlab.Service
+	instantIntegrationService      *service_ci.Service
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver
+}
+
+func New(
+	authService *service_auth.Service,
+	gitLabService *service_gitlab.Service,
+	instantIntegrationService *service_ci.Service,
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver,
+) resolvers.GitLabInstantIntegrationRootResolver {
+	return &rootResolver{
+		authService:                    authService,
+		gitLabService:                  gitLabService,
+		instantIntegrationService:      instantIntegrationService,
+		instantIntegrationRootResolver: instantIntegrationRootResolver,
+	}
+}
+
+func (r *rootResolver) CreateOrUpdateGitLabIntegration(ctx context.Context, args resolvers.CreateOrUpdateGitLabIntegrationArgs) (resolvers.IntegrationResolver, error) {
+	codebaseID := codebases.ID(args.Input.CodebaseID)
+	if err := r.authService.CanAdmin(ctx, &codebases.Codebase{ID: codebaseID}); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	instanceURL := defaultInstanceURL
+	if args.Input.InstanceURL != nil {
+		instanceURL = strings.TrimSuffix(strings.TrimSpace(*args.Input.InstanceURL), "/")
+	}
+	if u, err := url.Parse(instanceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "instanceURL", "must be a http or https url")
+	}
+
+	ref := defaultRef
+	if args.Input.Ref != nil {
+		ref = strings.TrimSpace(*args.Input.Ref)
+	}
+	if ref == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "ref", "must not be empty")
+	}
+
+	projectID := strings.Trim(strings.TrimSpace(args.Input.ProjectID), "/")
+	if projectID == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "projectID", "must not be empty")
+	}
+	if args.Input.TriggerToken == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "triggerToken", "must not be empty")
+	}
+	if args.Input.WebhookToken == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "webhookToken", "must not be empty")
+	}
+
+	cfg := &gitlab.Config{
+		CodebaseID:   codebaseID,
+		InstanceURL:  instanceURL,
+		ProjectID:    projectID,
+		Ref:          ref,
+		TriggerToken: args.Input.TriggerToken,
+		WebhookToken: args.Input.WebhookToken,
+	}
+
+	if args.Input.IntegrationID == nil {
+		integration, err := r.createConfiguration(ctx, cfg)
+		if err != nil {
+			return nil, gqlerrors.Error(fmt.Errorf("failed to create new configuration: %w", err))
+		}
+		return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+	}
+
+	integration, err := r.instantIntegrationService.GetByID(ctx, string(*args.Input.IntegrationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if integration.CodebaseID != codebaseID || integration.Provider != providers.ProviderNameGitLab {
+		return nil, gqlerrors.ErrNotFound
+	}
+
+	if err := r.updateConfiguration(ctx, integration, cfg); err != nil {
+		return nil, gqlerrors.Error(fmt.Errorf("failed to update existing configuration: %w", err))
+	}
+	return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+}
+
+func (r *rootResolver) createConfiguration(ctx context.Context, cfg *gitlab.Config) (*integrations.Integration, error) {
+	integration := &integrations.Integration{
+		ID:           uuid.NewString(),
+		CodebaseID:   cfg.CodebaseID,
+		Provider:     providers.ProviderNameGitLab,
+		ProviderType: providers.ProviderTypeBuild,
+		SeedFiles:    seedFiles,
+		CreatedAt:    time.Now(),
+	}
+	if err := r.instantIntegrationService.CreateIntegration(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	cfg.ID = uuid.NewString()
+	cfg.IntegrationID = integration.ID
+	cfg.CreatedAt = time.Now()
+	if err := r.gitLabService.CreateIntegration(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("failed to create configuration: %w", err)
+	}
+
+	return integration, nil
+}
+
+func (r *rootResolver) updateConfiguration(ctx context.Context, integration *integrations.Integration, newCfg *gitlab.Config) error {
+	cfg, err := r.gitLabService.GetConfigurationByIntegrationID(ctx, integration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	configChanged := cfg.InstanceURL != newCfg.InstanceURL ||
+		cfg.ProjectID != newCfg.ProjectID ||
+		cfg.Ref != newCfg.Ref ||
+		cfg.TriggerToken != newCfg.TriggerToken ||
+		cfg.WebhookToken != newCfg.WebhookToken
+	if !configChanged {
+		return nil
+	}
+
+	now := time.Now()
+	cfg.InstanceURL = newCfg.InstanceURL
+	cfg.ProjectID = newCfg.ProjectID
+	cfg.Ref = newCfg.Ref
+	cfg.TriggerToken = newCfg.TriggerToken
+	cfg.WebhookToken = newCfg.WebhookToken
+	cfg.UpdatedAt = now
+	if err := r.gitLabService.UpdateIntegration(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+
+	integration.UpdatedAt = now
+	if err := r.instantIntegrationService.UpdateIntegration(ctx, integration); err != nil {
+		return fmt.Errorf("failed to update integration: %w", err)
+	}
+	return nil
+}
+
+func (r *rootResolver) InternalGitLabConfigurationByIntegrationID(ctx context.Context, integrationID string) (resolvers.GitLabConfigurationResolver, error) {
+	cfg, err := r.gitLabService.GetConfigurationByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &configurationResolver{cfg: cfg}, nil
+}
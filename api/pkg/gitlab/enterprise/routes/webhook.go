@@ -0,0 +1,203 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	svc_ci "getsturdy.com/api/pkg/ci/service"
+	"getsturdy.com/api/pkg/gitlab"
+	service_gitlab_enterprise "getsturdy.com/api/pkg/gitlab/enterprise/service"
+	"getsturdy.com/api/pkg/statuses"
+	svc_statuses "getsturdy.com/api/pkg/statuses/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	eventPipeline = "Pipeline Hook"
+	eventJob      = "Job Hook"
+)
+
+// Valid statuses: created, waiting_for_resource, preparing, pending, running, success, failed, canceled, skipped,
+// manual, scheduled
+var gitLabStatusToType = map[string]statuses.Type{
+	"created":              statuses.TypePending,
+	"waiting_for_resource": statuses.TypePending,
+	"preparing":            statuses.TypePending,
+	"pending":              statuses.TypePending,
+	"running":              statuses.TypePending,
+	"manual":               statuses.TypePending,
+	"scheduled":            statuses.TypePending,
+
+	"success": statuses.TypeHealthy,
+	"skipped": statuses.TypeHealthy,
+
+	"failed":   statuses.TypeFailing,
+	"canceled": statuses.TypeFailing,
+}
+
+// WebhookHandler handles pipeline and job events from GitLab. Events for pipelines that have not been triggered by
+// Sturdy are ignored. The webhook is authenticated with the secret token of the webhook, which is sent in the
+// X-Gitlab-Token header.
+func WebhookHandler(
+	logger *zap.Logger,
+	statusesService *svc_statuses.Service,
+	ciService *svc_ci.Service,
+	gitLabService *service_gitlab_enterprise.Service,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		requestBody, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			logger.Error("failed to read body", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		event, err := parseEvent(c.GetHeader("X-Gitlab-Event"), requestBody)
+		if errors.Is(err, errUnsupportedEvent) {
+			// Short-circuit events that we're not interested in
+			c.AbortWithStatus(http.StatusOK)
+			return
+		} else if err != nil {
+			logger.Error("failed to parse payload", zap.Error(err))
+			c.AbortWithError(http.StatusBadRequest, fmt.Errorf("failed to parse payload"))
+			return
+		}
+
+		logger := logger.With(
+			zap.String("x-gitlab-event", c.GetHeader("X-Gitlab-Event")),
+			zap.Int64("gitlab_project_id", event.ProjectID),
+			zap.Int64("gitlab_pipeline_id", event.PipelineID),
+		)
+
+		pipeline, cfg, err := gitLabService.GetPipeline(c.Request.Context(), event.ProjectID, event.PipelineID, c.GetHeader("X-Gitlab-Token"))
+		switch {
+		case errors.Is(err, service_gitlab_enterprise.ErrUnknownPipeline):
+			// the pipeline was not triggered by Sturdy
+			c.AbortWithStatus(http.StatusOK)
+			return
+		case errors.Is(err, service_gitlab_enterprise.ErrInvalidToken):
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		case err != nil:
+			logger.Error("failed to get pipeline", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		trunkCommitSHA, err := ciService.GetTrunkCommitSHA(c.Request.Context(), cfg.CodebaseID, pipeline.CiRepoCommitSHA)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithError(http.StatusBadRequest, fmt.Errorf("unknown commit: %s", pipeline.CiRepoCommitSHA))
+			return
+		} else if err != nil {
+			logger.Error("could not find trunk commit", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		statusType, ok := gitLabStatusToType[event.Status]
+		if !ok {
+			logger.Error("invalid status from gitlab", zap.String("status", event.Status))
+			c.AbortWithError(http.StatusBadRequest, fmt.Errorf("invalid status: %s", event.Status))
+			return
+		}
+
+		status := &statuses.Status{
+			ID:         uuid.NewString(),
+			CommitSHA:  trunkCommitSHA,
+			CodebaseID: cfg.CodebaseID,
+			Type:       statusType,
+			Title:      event.Title,
+			Timestamp:  time.Now(),
+		}
+		if event.WebURL != "" {
+			status.DetailsURL = &event.WebURL
+		}
+		if err := statusesService.Set(c, status); err != nil {
+			logger.Error("failed to update status", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("got webhook from gitlab", zap.Stringer("codebase_id", cfg.CodebaseID))
+	}
+}
+
+var errUnsupportedEvent = errors.New("unsupported event")
+
+// event is the common parts of pipeline and job events.
+type event struct {
+	ProjectID  int64
+	PipelineID int64
+	Status     string
+	Title      string
+	WebURL     string
+}
+
+func parseEvent(eventType string, body []byte) (*event, error) {
+	switch eventType {
+	case eventPipeline:
+		var payload pipelinePayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		return &event{
+			ProjectID:  payload.Project.ID,
+			PipelineID: payload.ObjectAttributes.ID,
+			Status:     payload.ObjectAttributes.Status,
+			Title:      gitlab.PipelineTitle,
+			WebURL:     webURL(payload.Project.WebURL, "pipelines", payload.ObjectAttributes.ID),
+		}, nil
+	case eventJob:
+		var payload jobPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		return &event{
+			ProjectID:  payload.ProjectID,
+			PipelineID: payload.PipelineID,
+			Status:     payload.BuildStatus,
+			Title:      fmt.Sprintf("%s: %s", gitlab.PipelineTitle, strings.TrimSpace(payload.BuildName)),
+			WebURL:     webURL(payload.Repository.Homepage, "jobs", payload.BuildID),
+		}, nil
+	default:
+		return nil, errUnsupportedEvent
+	}
+}
+
+func webURL(projectURL, kind string, id int64) string {
+	if projectURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/-/%s/%d", strings.TrimSuffix(projectURL, "/"), kind, id)
+}
+
+type pipelinePayload struct {
+	ObjectAttributes struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	} `json:"object_attributes"`
+	Project struct {
+		ID     int64  `json:"id"`
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+}
+
+type jobPayload struct {
+	BuildID     int64  `json:"build_id"`
+	BuildName   string `json:"build_name"`
+	BuildStatus string `json:"build_status"`
+	PipelineID  int64  `json:"pipeline_id"`
+	ProjectID   int64  `json:"project_id"`
+	Repository  struct {
+		Homepage string `json:"homepage"`
+	} `json:"repository"`
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent_pipeline(t *testing.T) {
+	body := `{
+		"object_kind": "pipeline",
+		"object_attributes": {"id": 31, "status": "success", "ref": "main"},
+		"project": {"id": 7, "web_url": "https://gitlab.example.com/org/repo"}
+	}`
+
+	event, err := parseEvent("Pipeline Hook", []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), event.ProjectID)
+	assert.Equal(t, int64(31), event.PipelineID)
+	assert.Equal(t, "success", event.Status)
+	assert.Equal(t, "GitLab", event.Title)
+	assert.Equal(t, "https://gitlab.example.com/org/repo/-/pipelines/31", event.WebURL)
+}
+
+func TestParseEvent_job(t *testing.T) {
+	body := `{
+		"object_kind": "build",
+		"build_id": 1977,
+		"build_name": "test",
+		"build_status": "failed",
+		"pipeline_id": 31,
+		"project_id": 7,
+		"repository": {"homepage": "https://gitlab.example.com/org/repo"}
+	}`
+
+	event, err := parseEvent("Job Hook", []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), event.ProjectID)
+	assert.Equal(t, int64(31), event.PipelineID)
+	assert.Equal(t, "failed", event.Status)
+	assert.Equal(t, "GitLab: test", event.Title)
+	assert.Equal(t, "https://gitlab.example.com/org/repo/-/jobs/1977", event.WebURL)
+}
+
+func TestParseEvent_unsupported(t *testing.T) {
+	_, err := parseEvent("Push Hook", []byte(`{}`))
+	assert.ErrorIs(t, err, errUnsupportedEvent)
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	configuration "getsturdy.com/api/pkg/configuration/module"
+	"getsturdy.com/api/pkg/di"
+	db_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/db"
+	"getsturdy.com/api/pkg/gitlab/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(configuration.Module)
+	c.Import(db_gitlab.Module)
+	c.Register(New)
+	c.Register(func(svc *Service) service.Service { return svc })
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/ci/service/configuration"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+	db_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/db"
+	"getsturdy.com/api/pkg/gitlab/service"
+	"getsturdy.com/api/pkg/integrations/dialer"
+
+	"github.com/google/uuid"
+)
+
+var _ service.Service = &Service{}
+
+var (
+	ErrUnknownPipeline = errors.New("unknown pipeline")
+	ErrInvalidToken    = errors.New("invalid webhook token")
+)
+
+// Variables that are passed to triggered pipelines.
+const (
+	VariableCommitSHA = "STURDY_COMMIT_SHA"
+	VariableTitle     = "STURDY_TITLE"
+	VariableGitURL    = "STURDY_GIT_URL"
+)
+
+type Service struct {
+	configRepo   db_gitlab.Repository
+	pipelineRepo db_gitlab.PipelineRepository
+	publicGitURL string
+	client       *http.Client
+}
+
+func New(
+	configRepo db_gitlab.Repository,
+	pipelineRepo db_gitlab.PipelineRepository,
+	cfg *configuration.Configuration,
+) *Service {
+	return &Service{
+		configRepo:   configRepo,
+		pipelineRepo: pipelineRepo,
+		publicGitURL: cfg.PublicGitURL,
+		// the instance url is set by users, so the internal network can't be reached unless private addresses are allowed
+		client: dialer.NewClient(30*time.Second, cfg.AllowPrivateWebhooks),
+	}
+}
+
+func (s *Service) CreateIntegration(ctx context.Context, cfg *gitlab.Config) error {
+	return s.configRepo.Create(ctx, cfg)
+}
+
+func (s *Service) UpdateIntegration(ctx context.Context, cfg *gitlab.Config) error {
+	return s.configRepo.Update(ctx, cfg)
+}
+
+func (s *Service) GetConfigurationsByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*gitlab.Config, error) {
+	return s.configRepo.GetConfigsByCodebaseID(ctx, codebaseID)
+}
+
+func (s *Service) GetConfigurationByIntegrationID(ctx context.Context, integrationID string) (*gitlab.Config, error) {
+	return s.configRepo.GetConfigByIntegrationID(ctx, integrationID)
+}
+
+// CreateBuild triggers a pipeline on the configured ref of the project, using the pipeline trigger api. The pipeline
+// is expected to fetch ciCommitID from the Sturdy CI git remote, which is passed to it in variables.
+func (s *Service) CreateBuild(ctx context.Context, integrationID, ciCommitID, title string) (*service.Build, error) {
+	cfg, err := s.configRepo.GetConfigByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config by integration id: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("token", cfg.TriggerToken)
+	form.Set("ref", cfg.Ref)
+	form.Set("variables["+VariableCommitSHA+"]", ciCommitID)
+	form.Set("variables["+VariableTitle+"]", title)
+	if s.publicGitURL != "" {
+		form.Set("variables["+VariableGitURL+"]", s.publicGitURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, triggerURL(cfg), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trigger request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make trigger request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	resContents, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contents: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		// the response is not included, as the error is shown to users, and the instance url is set by them
+		return nil, fmt.Errorf("failed to trigger pipeline: unexpected status code %d", resp.StatusCode)
+	}
+
+	var parsedRes triggerPipelineRes
+	if err := json.Unmarshal(resContents, &parsedRes); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if parsedRes.ID == 0 {
+		return nil, fmt.Errorf("unexpected response, id not set")
+	}
+
+	if err := s.pipelineRepo.Create(ctx, &gitlab.Pipeline{
+		ID:              uuid.NewString(),
+		IntegrationID:   cfg.IntegrationID,
+		GitLabID:        parsedRes.ID,
+		GitLabProjectID: parsedRes.ProjectID,
+		CiRepoCommitSHA: ciCommitID,
+		CreatedAt:       time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save pipeline: %w", err)
+	}
+
+	return &service.Build{
+		Name: gitlab.PipelineTitle,
+		URL:  parsedRes.WebURL,
+	}, nil
+}
+
+// GetPipeline returns a pipeline that has been triggered by Sturdy, and the configuration of the integration that
+// triggered it. The token is the token of the webhook that the pipeline event was received from, it's validated
+// against the configuration.
+func (s *Service) GetPipeline(ctx context.Context, gitLabProjectID, gitLabID int64, token string) (*gitlab.Pipeline, *gitlab.Config, error) {
+	pipelines, err := s.pipelineRepo.ListByGitLabID(ctx, gitLabProjectID, gitLabID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+	if len(pipelines) == 0 {
+		return nil, nil, ErrUnknownPipeline
+	}
+
+	for _, pipeline := range pipelines {
+		cfg, err := s.configRepo.GetConfigByIntegrationID(ctx, pipeline.IntegrationID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get config by integration id: %w", err)
+		}
+		if cfg.WebhookToken != "" && subtle.ConstantTimeCompare([]byte(cfg.WebhookToken), []byte(token)) == 1 {
+			return pipeline, cfg, nil
+		}
+	}
+
+	return nil, nil, ErrInvalidToken
+}
+
+func triggerURL(cfg *gitlab.Config) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/trigger/pipeline", strings.TrimSuffix(cfg.InstanceURL, "/"), url.PathEscape(cfg.ProjectID))
+}
+
+type triggerPipelineRes struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	WebURL    string `json:"web_url"`
+	Status    string `json:"status"`
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/ci/service/configuration"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+	db_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/db"
+	"getsturdy.com/api/pkg/integrations/dialer"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T, handler http.HandlerFunc) (*Service, *gitlab.Config) {
+	// the test server listens on 127.0.0.1
+	return setupWithConfiguration(t, &configuration.Configuration{
+		PublicGitURL:         "https://git.getsturdy.com",
+		AllowPrivateWebhooks: true,
+	}, handler)
+}
+
+func setupWithConfiguration(t *testing.T, ciCfg *configuration.Configuration, handler http.HandlerFunc) (*Service, *gitlab.Config) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	svc := New(db_gitlab.NewInMemory(), db_gitlab.NewPipelineInMemory(), ciCfg)
+
+	cfg := &gitlab.Config{
+		ID:            uuid.NewString(),
+		CodebaseID:    codebases.ID(uuid.NewString()),
+		IntegrationID: uuid.NewString(),
+		InstanceURL:   server.URL,
+		ProjectID:     "org/repo",
+		Ref:           "main",
+		TriggerToken:  "trigger-token",
+		WebhookToken:  "webhook-token",
+		CreatedAt:     time.Now(),
+	}
+	require.NoError(t, svc.CreateIntegration(context.Background(), cfg))
+	return svc, cfg
+}
+
+func TestCreateBuild(t *testing.T) {
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v4/projects/org%2Frepo/trigger/pipeline", r.URL.EscapedPath())
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "trigger-token", r.PostForm.Get("token"))
+		assert.Equal(t, "main", r.PostForm.Get("ref"))
+		assert.Equal(t, "abc123", r.PostForm.Get("variables[STURDY_COMMIT_SHA]"))
+		assert.Equal(t, "Fix the thing", r.PostForm.Get("variables[STURDY_TITLE]"))
+		assert.Equal(t, "https://git.getsturdy.com", r.PostForm.Get("variables[STURDY_GIT_URL]"))
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 31, "project_id": 7, "status": "created", "web_url": "https://gitlab.example.com/org/repo/-/pipelines/31"}`))
+	})
+
+	build, err := svc.CreateBuild(context.Background(), cfg.IntegrationID, "abc123", "Fix the thing")
+	require.NoError(t, err)
+	assert.Equal(t, gitlab.PipelineTitle, build.Name)
+	assert.Equal(t, "https://gitlab.example.com/org/repo/-/pipelines/31", build.URL)
+
+	pipeline, pipelineCfg, err := svc.GetPipeline(context.Background(), 7, 31, "webhook-token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", pipeline.CiRepoCommitSHA)
+	assert.Equal(t, cfg.IntegrationID, pipelineCfg.IntegrationID)
+
+	_, _, err = svc.GetPipeline(context.Background(), 7, 31, "wrong-token")
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	_, _, err = svc.GetPipeline(context.Background(), 7, 32, "webhook-token")
+	assert.True(t, errors.Is(err, ErrUnknownPipeline))
+}
+
+func TestCreateBuild_error(t *testing.T) {
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "404 Not Found"}`))
+	})
+
+	_, err := svc.CreateBuild(context.Background(), cfg.IntegrationID, "abc123", "Fix the thing")
+	assert.Error(t, err)
+}
+
+func TestCreateBuild_errorDoesNotIncludeResponse(t *testing.T) {
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`internal details`))
+	})
+
+	_, err := svc.CreateBuild(context.Background(), cfg.IntegrationID, "abc123", "Fix the thing")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "internal details")
+}
+
+func TestCreateBuild_privateAddressesAreForbidden(t *testing.T) {
+	var requests int32
+	svc, cfg := setupWithConfiguration(t, &configuration.Configuration{}, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	_, err := svc.CreateBuild(context.Background(), cfg.IntegrationID, "abc123", "Fix the thing")
+	assert.True(t, errors.Is(err, dialer.ErrForbiddenAddress))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
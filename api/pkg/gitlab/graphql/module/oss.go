@@ -0,0 +1,12 @@
+//go:build !cloud && !enterprise
+
+package graphql
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/gitlab/graphql"
+)
+
+func Module(c *di.Container) {
+	c.Register(graphql.New)
+}
@@ -0,0 +1,22 @@
+package graphql
+
+import (
+	"context"
+
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+type rootResolver struct{}
+
+func New() resolvers.GitLabInstantIntegrationRootResolver {
+	return &rootResolver{}
+}
+
+func (r *rootResolver) CreateOrUpdateGitLabIntegration(ctx context.Context, args resolvers.CreateOrUpdateGitLabIntegrationArgs) (resolvers.IntegrationResolver, error) {
+	return nil, gqlerrors.ErrNotImplemented
+}
+
+func (r *rootResolver) InternalGitLabConfigurationByIntegrationID(ctx context.Context, integrationID string) (resolvers.GitLabConfigurationResolver, error) {
+	return nil, gqlerrors.ErrNotImplemented
+}
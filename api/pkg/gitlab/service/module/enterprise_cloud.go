@@ -0,0 +1,13 @@
+//go:build enterprise || cloud
+// +build enterprise cloud
+
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	service_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(service_gitlab.Module)
+}
@@ -0,0 +1,13 @@
+//go:build !enterprise && !cloud
+// +build !enterprise,!cloud
+
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/gitlab/service"
+)
+
+func Module(c *di.Container) {
+	c.Register(service.New, new(service.Service))
+}
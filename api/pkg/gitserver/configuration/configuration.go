@@ -3,5 +3,6 @@ package configuration
 import "getsturdy.com/api/pkg/configuration/flags"
 
 type Configuration struct {
-	Addr flags.Addr `long:"addr" description:"listen address" default:"127.0.0.1:3002"`
+	Addr      flags.Addr `long:"addr" description:"listen address" default:"127.0.0.1:3002"`
+	SSHSecret string     `long:"ssh-secret" description:"secret shared with the ssh server to sign git over ssh requests, git over ssh is disabled if empty"`
 }
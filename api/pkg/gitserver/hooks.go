@@ -0,0 +1,111 @@
+package gitserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	"getsturdy.com/api/pkg/unidiff"
+)
+
+const (
+	writableRefsEnv = "STURDY_WRITABLE_REFS"
+	// writeAllowerEnv has the patterns of the files that the user can write to, as json. It's not set if the user can
+	// write to all files.
+	writeAllowerEnv = "STURDY_WRITE_ALLOWER"
+	// executableEnv is the path to the api binary, that implements checkPathsArg
+	executableEnv = "STURDY_GITSERVER_EXECUTABLE"
+	checkPathsArg = "sturdy-gitserver-check-paths"
+
+	// emptyTreeSHA is the tree that new refs are compared to
+	emptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+)
+
+// preReceiveHook rejects all ref updates, except updates to the refs listed in $STURDY_WRITABLE_REFS. If
+// $STURDY_WRITE_ALLOWER is set, updates that change files that the user can't write to are rejected as well.
+//
+// Git passes the refs to the hook without the namespace prefix, the prefix is stripped anyway to be safe.
+const preReceiveHook = `#!/bin/sh
+zero=0000000000000000000000000000000000000000
+while read -r old new ref; do
+	ref="${ref#refs/namespaces/*/}"
+	case " $` + writableRefsEnv + ` " in
+	*" $ref "*) ;;
+	*)
+		echo "sturdy: $ref is not a workspace that you can push to"
+		exit 1
+		;;
+	esac
+	if [ "$new" = "$zero" ]; then
+		echo "sturdy: workspaces can not be deleted with git, archive $ref in Sturdy instead"
+		exit 1
+	fi
+	if [ -n "$` + writeAllowerEnv + `" ]; then
+		if [ "$old" = "$zero" ]; then
+			old=` + emptyTreeSHA + `
+		fi
+		"$` + executableEnv + `" ` + checkPathsArg + ` "$old" "$new" || exit 1
+	fi
+done
+`
+
+// installHooks writes the hooks used by the workspace receive-pack to a temporary directory, and returns the path
+// to it. The path is meant to be used as core.hooksPath.
+func installHooks() (string, error) {
+	dir, err := os.MkdirTemp("", "sturdy-gitserver-hooks-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	if err := os.WriteFile(path.Join(dir, "pre-receive"), []byte(preReceiveHook), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write pre-receive hook: %w", err)
+	}
+
+	return dir, nil
+}
+
+// HookMain runs the part of the pre-receive hook that checks the changed paths, if the process has been started as
+// it. It must be called first thing in main, and it does not return in the hook.
+func HookMain() {
+	if len(os.Args) != 4 || os.Args[1] != checkPathsArg {
+		return
+	}
+	if err := checkPaths(os.Args[2], os.Args[3]); err != nil {
+		fmt.Fprintf(os.Stderr, "sturdy: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// checkPaths checks that the files that are changed between the commits can be written to. It runs in the
+// pre-receive hook, so the pushed objects can be read from the repository.
+func checkPaths(oldSHA, newSHA string) error {
+	var patterns []string
+	if err := json.Unmarshal([]byte(os.Getenv(writeAllowerEnv)), &patterns); err != nil {
+		return fmt.Errorf("failed to decode allowed files: %w", err)
+	}
+	allower, err := unidiff.NewAllower(patterns...)
+	if err != nil {
+		return fmt.Errorf("failed to parse allowed files: %w", err)
+	}
+
+	cmd := exec.Command("git", "diff", "--name-only", "--no-renames", "-z", oldSHA, newSHA)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to diff %s..%s: %w", oldSHA, newSHA, err)
+	}
+
+	for _, p := range bytes.Split(output, []byte{0}) {
+		if len(p) == 0 {
+			continue
+		}
+		if !allower.IsAllowed(string(p), false) {
+			return fmt.Errorf("you are not allowed to change %s", p)
+		}
+	}
+	return nil
+}
@@ -1,12 +1,18 @@
 package gitserver
 
 import (
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	configuration "getsturdy.com/api/pkg/configuration/module"
 	"getsturdy.com/api/pkg/di"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/logger"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	service_users "getsturdy.com/api/pkg/users/service/module"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
 	"getsturdy.com/api/vcs/executor"
 )
 
@@ -15,7 +21,13 @@ func Module(c *di.Container) {
 	c.Import(configuration.Module)
 	c.Import(service_servicetokens.Module)
 	c.Import(service_jwt.Module)
+	c.Import(service_personaltokens.Module)
 	c.Import(service_codebase.Module)
+	c.Import(service_auth.Module)
+	c.Import(service_users.Module)
+	c.Import(service_twofactor.Module)
+	c.Import(service_workspace.Module)
+	c.Import(service_snapshots.Module)
 	c.Import(executor.Module)
 	c.Register(New)
 }
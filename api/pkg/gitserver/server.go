@@ -2,43 +2,65 @@ package gitserver
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/gitserver/configuration"
 	"getsturdy.com/api/pkg/gitserver/pack"
+	"getsturdy.com/api/pkg/ip"
 	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	"getsturdy.com/api/pkg/servicetokens"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/unidiff"
 	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/service"
 	"getsturdy.com/api/pkg/version"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
 	"getsturdy.com/api/vcs"
 	"getsturdy.com/api/vcs/executor"
 
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Server struct {
 	logger *zap.Logger
 	cfg    *configuration.Configuration
 
-	serviceTokensService *service_servicetokens.Service
-	jwtTokensService     *service_jwt.Service
-	codebaseService      *service_codebase.Service
-	executorProvider     executor.Provider
-
-	router *gin.Engine
+	serviceTokensService  *service_servicetokens.Service
+	jwtTokensService      *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
+	codebaseService       *service_codebase.Service
+	authService           *service_auth.Service
+	userService           service_users.Service
+	twoFactorService      *service_twofactor.Service
+	workspaceService      *service_workspace.Service
+	snapshotsService      *service_snapshots.Service
+	executorProvider      executor.Provider
+
+	router     *gin.Engine
+	hooksPath  string
+	executable string
 }
 
 func ginMode() string {
@@ -53,7 +75,13 @@ func New(
 	cfg *configuration.Configuration,
 	serviceTokensService *service_servicetokens.Service,
 	jwtTokensService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 	codebaeService *service_codebase.Service,
+	authService *service_auth.Service,
+	userService service_users.Service,
+	twoFactorService *service_twofactor.Service,
+	workspaceService *service_workspace.Service,
+	snapshotsService *service_snapshots.Service,
 	executorProvider executor.Provider,
 ) *Server {
 	gin.SetMode(ginMode())
@@ -63,16 +91,43 @@ func New(
 		logger: logger,
 		cfg:    cfg,
 
-		serviceTokensService: serviceTokensService,
-		jwtTokensService:     jwtTokensService,
-		codebaseService:      codebaeService,
-		executorProvider:     executorProvider,
+		serviceTokensService:  serviceTokensService,
+		jwtTokensService:      jwtTokensService,
+		personalTokensService: personalTokensService,
+		codebaseService:       codebaeService,
+		authService:           authService,
+		userService:           userService,
+		twoFactorService:      twoFactorService,
+		workspaceService:      workspaceService,
+		snapshotsService:      snapshotsService,
+		executorProvider:      executorProvider,
 
 		router: ginRouter,
 	}
 }
 
 func (h *Server) Start() error {
+	if err := h.setup(); err != nil {
+		return err
+	}
+
+	h.logger.Info("starting gitserver", zap.Stringer("addr", h.cfg.Addr))
+
+	if err := h.router.Run(h.cfg.Addr.String()); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to run the server: %w", err)
+	}
+
+	return nil
+}
+
+// setup installs the hooks, and registers the routes of the server.
+func (h *Server) setup() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable: %w", err)
+	}
+	h.executable = executable
+
 	h.router.Use(ginzap.Ginzap(h.logger, time.RFC3339, true))
 	h.router.Use(ginzap.RecoveryWithZap(h.logger, true))
 
@@ -80,25 +135,178 @@ func (h *Server) Start() error {
 	ciIntegrationGroup.GET("/info/refs", h.handleInfoRefs)
 	ciIntegrationGroup.POST("/git-upload-pack", h.handleGitUploadPack)
 
-	importGroup := h.router.Group("/:codebaseId").Use(h.jwtTokenAuth)
-	importGroup.GET("/info/refs", h.handleInfoRefs)
-	importGroup.POST("/git-receive-pack", h.handleGitReceivePack)
+	hooksPath, err := installHooks()
+	if err != nil {
+		return fmt.Errorf("failed to install hooks: %w", err)
+	}
+	h.hooksPath = hooksPath
 
-	h.logger.Info("starting gitserver", zap.Stringer("addr", h.cfg.Addr))
+	codebaseGroup := h.router.Group("/:codebaseId").Use(h.codebaseAuth)
+	codebaseGroup.GET("/info/refs", h.handleCodebaseInfoRefs)
+	codebaseGroup.POST("/git-upload-pack", h.handleWorkspacesUploadPack)
+	codebaseGroup.POST("/git-receive-pack", h.handleCodebaseReceivePack)
 
-	if err := h.router.Run(h.cfg.Addr.String()); !errors.Is(err, http.ErrServerClosed) {
-		return fmt.Errorf("failed to run the server: %w", err)
-	}
+	sshGroup := h.router.Group("/ssh/:codebaseId").Use(h.sshAuth)
+	sshGroup.GET("/git-upload-pack", h.handleSSHUploadPack)
+	sshGroup.GET("/git-receive-pack", h.handleSSHReceivePack)
 
 	return nil
 }
 
 const (
-	tokenKey  = "token"
-	userIDKey = "user_id"
-	ciRepo    = "ci"
+	tokenKey    = "token"
+	userIDKey   = "user_id"
+	codebaseKey = "codebase"
+	ciRepo      = "ci"
+
+	importUsername = "import"
 )
 
+// codebaseAuth authenticates requests to a codebase. Requests from "sturdy import" use the "import" username and an
+// auth token as the password. All other requests are made on behalf of a user, either with their email and password,
+// or with an auth token or a personal access token as the password. Requests without credentials can only read public
+// codebases.
+func (h *Server) codebaseAuth(c *gin.Context) {
+	username, password, ok := c.Request.BasicAuth()
+	if ok && username == importUsername {
+		if !isReceivePack(c) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		h.jwtTokenAuth(c)
+		return
+	}
+
+	subject := &auth.Subject{Type: auth.SubjectAnonymous}
+	if ok {
+		ctx := ip.NewContext(c.Request.Context(), net.ParseIP(c.ClientIP()))
+		var err error
+		subject, err = h.authenticateUser(ctx, username, password)
+		if err != nil {
+			c.Header("WWW-Authenticate", "Basic realm=Authorization Required")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), subject))
+
+	h.authorizeCodebase(c)
+}
+
+// authorizeCodebase checks that the subject of the request can read from, or for pushes write to, the codebase.
+//
+// Git can't serve parts of a repository, so only subjects that can read all files in the codebase can use it. Pushes
+// are checked against the files that the subject can write to when they are received.
+func (h *Server) authorizeCodebase(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	subject, ok := auth.FromContext(ctx)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	codebase, err := h.codebaseService.GetByID(ctx, codebases.ID(c.Param("codebaseId")))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.logger.Error("failed to get codebase", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if isReceivePack(c) {
+		err = h.authService.CanWrite(ctx, codebase)
+	} else {
+		err = h.authService.CanRead(ctx, codebase)
+	}
+	if err == nil {
+		err = h.canReadAll(ctx, codebase)
+	}
+	switch {
+	case err == nil:
+		c.Set(codebaseKey, codebase)
+	case errors.Is(err, auth.ErrForbidden) && subject.Type == auth.SubjectAnonymous:
+		c.Header("WWW-Authenticate", "Basic realm=Authorization Required")
+		c.AbortWithStatus(http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrForbidden):
+		c.AbortWithStatus(http.StatusForbidden)
+	default:
+		h.logger.Error("failed to check access", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
+
+// canReadAll returns auth.ErrForbidden if there are files in the codebase that the subject can't read.
+func (h *Server) canReadAll(ctx context.Context, codebase *codebases.Codebase) error {
+	allower, err := h.authService.GetAllower(ctx, codebase)
+	if err != nil {
+		return fmt.Errorf("failed to get allower: %w", err)
+	}
+	if !allower.AllowsAll() {
+		return fmt.Errorf("%w: some files can't be read", auth.ErrForbidden)
+	}
+	return nil
+}
+
+// writeAllower returns the files in the codebase that the subject of the request can push changes to.
+func (h *Server) writeAllower(c *gin.Context) (*unidiff.Allower, error) {
+	allower, err := h.authService.GetWriteAllower(c.Request.Context(), c.MustGet(codebaseKey).(*codebases.Codebase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allower: %w", err)
+	}
+	return allower, nil
+}
+
+// authenticateUser returns the user with the given credentials. The password is either the users password, an auth
+// token, or a personal access token, in which case the username is ignored and the subject is restricted to the
+// scopes of the token.
+//
+// Git can't ask for a second factor, so users with two-factor authentication enabled can't use their password.
+func (h *Server) authenticateUser(ctx context.Context, username, password string) (*auth.Subject, error) {
+	if auth.IsPersonalAccessToken(password) {
+		return h.personalTokensService.Authenticate(ctx, password)
+	}
+
+	if token, err := h.jwtTokensService.Verify(ctx, password, jwt.TokenTypeAuth); err == nil {
+		return &auth.Subject{ID: token.Subject, Type: auth.SubjectUser}, nil
+	}
+
+	user, err := h.userService.GetByEmail(ctx, strings.TrimSpace(username))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, fmt.Errorf("invalid password: %w", err)
+	}
+
+	if user.Status == users.StatusDeactivated {
+		return nil, fmt.Errorf("user is deactivated: %w", auth.ErrUnauthenticated)
+	}
+
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if twoFactorEnabled {
+		return nil, errors.New("two-factor authentication is enabled, a token must be used instead of the password")
+	}
+
+	return &auth.Subject{ID: user.ID.String(), Type: auth.SubjectUser}, nil
+}
+
+func isReceivePack(c *gin.Context) bool {
+	return strings.HasSuffix(c.FullPath(), "/git-receive-pack") || getServiceName(c.Request) == "receive-pack"
+}
+
+func isImport(c *gin.Context) bool {
+	_, ok := c.Get(userIDKey)
+	return ok
+}
+
 func (h *Server) jwtTokenAuth(c *gin.Context) {
 	username, password, ok := c.Request.BasicAuth()
 	if !ok {
@@ -323,3 +531,137 @@ func (h *Server) handleInfoRefs(c *gin.Context) {
 		}
 	}
 }
+
+// handleCodebaseInfoRefs advertises the refs of a codebase. Imports are served trunk as is, everyone else is served the
+// workspaces of the codebase.
+func (h *Server) handleCodebaseInfoRefs(c *gin.Context) {
+	if isImport(c) {
+		h.handleInfoRefs(c)
+		return
+	}
+
+	codebaseID := codebases.ID(c.Param("codebaseId"))
+	serviceName := getServiceName(c.Request)
+	if serviceName != "upload-pack" && serviceName != "receive-pack" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	c.Header("Content-Type", fmt.Sprintf("application/x-git-%s-advertisement", serviceName))
+	c.Header("Cache-Control", "no-cache")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	str := fmt.Sprintf("# service=git-%s", serviceName)
+	fmt.Fprintf(c.Writer, "%.4x%s\n", len(str)+5, str)
+	fmt.Fprintf(c.Writer, "0000")
+
+	// the refs are synced again, under the write lock, when a push is received
+	refs := &workspaceRefs{}
+	if err := h.syncWorkspaceRefsForRead(c.Request.Context(), codebaseID, refs); err != nil {
+		h.logger.Error("failed to sync workspace refs", zap.Error(err))
+		return
+	}
+
+	if err := h.executorProvider.New().
+		Read(func(repo vcs.RepoReader) error {
+			cmd := exec.Command("git", "--namespace="+workspacesNamespace, serviceName, "--stateless-rpc", "--advertise-refs", repo.Path())
+			cmd.Stdout = c.Writer
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("failed to advertise refs: %w", err)
+			}
+			return nil
+		}).ExecTrunk(codebaseID, "gitserverWorkspacesInfoRefs"); err != nil {
+		h.logger.Error("failed to handle info refs", zap.Error(err))
+		return
+	}
+}
+
+// handleWorkspacesUploadPack serves clones and fetches of the workspaces of a codebase.
+func (h *Server) handleWorkspacesUploadPack(c *gin.Context) {
+	codebaseID := codebases.ID(c.Param("codebaseId"))
+
+	body, err := requestBody(c.Request)
+	if err != nil {
+		h.logger.Error("upload-pack failed to read request", zap.Error(err))
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", "application/x-git-upload-pack-result")
+	c.Header("Cache-Control", "no-cache")
+
+	if err := h.executorProvider.New().
+		Read(uploadPack(body, c.Writer, "--stateless-rpc")).
+		ExecTrunk(codebaseID, "gitserverWorkspacesUploadPack"); err != nil {
+		h.logger.Error("failed to handle git upload pack", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleCodebaseReceivePack receives pushes to a codebase. Imports are pushed to trunk, everyone else can push to the
+// workspaces of the codebase. Every updated workspace is snapshotted once the push is received.
+func (h *Server) handleCodebaseReceivePack(c *gin.Context) {
+	if isImport(c) {
+		h.handleGitReceivePack(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	codebaseID := codebases.ID(c.Param("codebaseId"))
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.userService.GetByID(ctx, userID)
+	if err != nil {
+		h.logger.Error("receive-pack failed to get user", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	allower, err := h.writeAllower(c)
+	if err != nil {
+		h.logger.Error("receive-pack failed to get allower", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	body, err := requestBody(c.Request)
+	if err != nil {
+		h.logger.Error("receive-pack failed to read request", zap.Error(err))
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	// The output is buffered, so that the client doesn't consider the push done until the workspaces are snapshotted.
+	var output bytes.Buffer
+	refs := &workspaceRefs{}
+	if err := h.executorProvider.New().
+		GitWrite(h.syncWorkspaceRefs(ctx, codebaseID, refs)).
+		FileReadGitWrite(h.receivePack(ctx, refs, user, allower, body, &output, "--stateless-rpc")).
+		ExecTrunk(codebaseID, "gitserverWorkspacesReceivePack"); err != nil {
+		h.logger.Error("failed to handle git receive pack", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-git-receive-pack-result")
+	c.Header("Cache-Control", "no-cache")
+	if _, err := io.Copy(c.Writer, &output); err != nil {
+		h.logger.Error("receive-pack failed to write response", zap.Error(err))
+	}
+}
+
+// requestBody returns the body of a git request, git compresses large requests with gzip.
+func requestBody(r *http.Request) (io.ReadCloser, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return r.Body, nil
+	}
+	return gzip.NewReader(r.Body)
+}
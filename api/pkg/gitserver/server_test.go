@@ -0,0 +1,368 @@
+package gitserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
+	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/di"
+	configuration_gitserver "getsturdy.com/api/pkg/gitserver/configuration"
+	db_installations "getsturdy.com/api/pkg/installations/db"
+	"getsturdy.com/api/pkg/jwt"
+	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	configuration_ldap "getsturdy.com/api/pkg/ldap/configuration"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	"getsturdy.com/api/pkg/logger"
+	configuration_oidc "getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+	module_queue "getsturdy.com/api/pkg/queue/module"
+	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	db_statuses "getsturdy.com/api/pkg/statuses/db"
+	db_suggestions "getsturdy.com/api/pkg/suggestions/db"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+	db_view "getsturdy.com/api/pkg/views/db"
+	service_view "getsturdy.com/api/pkg/views/service"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	"getsturdy.com/api/vcs/executor"
+	"getsturdy.com/api/vcs/testutil"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	// the test binary runs the pre-receive hook
+	HookMain()
+	os.Exit(m.Run())
+}
+
+func testModule(t *testing.T) di.Module {
+	return func(c *di.Container) {
+		c.Import(service_snapshots.Module)
+		c.Import(service_codebase.Module)
+		c.Import(service_workspace.Module)
+		c.Import(service_view.Module)
+
+		c.ImportWithForce(db_snapshots.TestModule)
+		c.ImportWithForce(db_view.TestModule)
+		c.ImportWithForce(db_workspaces.TestModule)
+		c.ImportWithForce(db_suggestions.TestModule)
+		c.ImportWithForce(db_codebases.TestModule)
+		c.ImportWithForce(db_installations.TestModule)
+		c.ImportWithForce(db_statuses.TestModule)
+		c.ImportWithForce(module_queue.TestModule(t))
+		c.ImportWithForce(configuration.TestModule)
+		c.RegisterWithForce(logger.NewTest)
+		c.RegisterWithForce(db_users.NewMemory)
+
+		c.RegisterWithForce(func() *sqlx.DB { return nil })
+		c.Register(func() *testing.T { return t })
+		c.RegisterWithForce(testutil.TestingRepoProvider)
+	}
+}
+
+const password = "password"
+
+type testServer struct {
+	url string
+
+	userRepo         db_users.Repository
+	aclRepo          db_acl.ACLRepository
+	codebaseUserRepo db_codebases.CodebaseUserRepository
+	workspaceService *service_workspace.Service
+	snapshotsService *service_snapshots.Service
+	jwtService       *service_jwt.Service
+	enrollmentRepo   db_twofactor.EnrollmentRepository
+
+	owner       *users.User
+	codebaseID  codebases.ID
+	workspaceID string
+}
+
+func setup(t *testing.T) *testServer {
+	ts := &testServer{
+		aclRepo:        db_acl.NewInMemoryAclRepo(),
+		jwtService:     service_jwt.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory()),
+		enrollmentRepo: db_twofactor.NewEnrollmentMemory(),
+	}
+
+	var (
+		codebaseService  *service_codebase.Service
+		userService      service_users.Service
+		executorProvider executor.Provider
+	)
+	require.NoError(t, di.Init(testModule(t)).To(
+		&ts.userRepo, &ts.codebaseUserRepo, &ts.workspaceService, &ts.snapshotsService,
+		&codebaseService, &userService, &executorProvider,
+	))
+
+	oidcService := service_oidc.New(zap.NewNop(), &configuration_oidc.Configuration{}, db_oidc.NewMemory(), userService, nil)
+	ldapService := service_ldap.New(zap.NewNop(), &configuration_ldap.Configuration{}, nil, nil, nil, nil, nil)
+	aclProvider := provider_acl.New(zap.NewNop(), ts.aclRepo, ts.codebaseUserRepo, userService, nil)
+	authService := service_auth.New(codebaseService, nil, userService, ts.workspaceService, aclProvider, nil, oidcService, nil, ldapService, nil)
+	twoFactorService := service_twofactor.New(zap.NewNop(), ts.enrollmentRepo, db_twofactor.NewRecoveryCodeMemory(), userService, nil)
+
+	server := New(
+		zap.NewNop(),
+		&configuration_gitserver.Configuration{},
+		nil,
+		ts.jwtService,
+		nil,
+		codebaseService,
+		authService,
+		userService,
+		twoFactorService,
+		ts.workspaceService,
+		ts.snapshotsService,
+		executorProvider,
+	)
+	require.NoError(t, server.setup())
+
+	httpServer := httptest.NewServer(server.router)
+	t.Cleanup(httpServer.Close)
+	ts.url = httpServer.URL
+
+	ctx := context.Background()
+	ts.owner = ts.createUser(t)
+
+	cb, err := codebaseService.Create(ctx, ts.owner.ID, "test", nil)
+	require.NoError(t, err)
+	ts.codebaseID = cb.ID
+
+	ws, err := ts.workspaceService.Create(ctx, service_workspace.CreateWorkspaceRequest{UserID: ts.owner.ID, CodebaseID: cb.ID})
+	require.NoError(t, err)
+	ts.workspaceID = ws.ID
+
+	return ts
+}
+
+func (ts *testServer) createUser(t *testing.T) *users.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	id := users.ID(uuid.NewString())
+	user := &users.User{
+		ID:           id,
+		Name:         "Test",
+		Email:        id.String() + "@getsturdy.com",
+		PasswordHash: string(hash),
+		Status:       users.StatusActive,
+	}
+	require.NoError(t, ts.userRepo.Create(user))
+	return user
+}
+
+func (ts *testServer) addMember(t *testing.T, user *users.User) {
+	require.NoError(t, ts.codebaseUserRepo.Create(codebases.CodebaseUser{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		CodebaseID: ts.codebaseID,
+	}))
+}
+
+// setFilesPolicy sets the policy of the codebase, everyone can read the readable files and write to the writable files.
+func (ts *testServer) setFilesPolicy(t *testing.T, readable, writable string) {
+	policy := fmt.Sprintf(`{
+  "groups": [{"id": "everyone", "members": ["*"]}],
+  "rules": [
+    {"id": "read", "principals": ["groups::everyone"], "action": "read", "resources": ["files::%s"]},
+    {"id": "write", "principals": ["groups::everyone"], "action": "write", "resources": ["files::%s"]},
+  ],
+}`, readable, writable)
+
+	ctx := context.Background()
+	if existing, err := ts.aclRepo.GetByCodebaseID(ctx, ts.codebaseID); err == nil {
+		existing.RawPolicy = policy
+		require.NoError(t, ts.aclRepo.Update(ctx, existing))
+		return
+	}
+	require.NoError(t, ts.aclRepo.Create(ctx, acl.ACL{
+		ID:         acl.ID(uuid.NewString()),
+		CodebaseID: ts.codebaseID,
+		CreatedAt:  time.Now(),
+		RawPolicy:  policy,
+	}))
+}
+
+func (ts *testServer) remote(user *users.User) string {
+	u, _ := url.Parse(ts.url + "/" + ts.codebaseID.String())
+	u.User = url.UserPassword(user.Email, password)
+	return u.String()
+}
+
+func runGit(t *testing.T, dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+t.TempDir(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@getsturdy.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@getsturdy.com",
+	)
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+func mustGit(t *testing.T, dir string, args ...string) string {
+	output, err := runGit(t, dir, args...)
+	require.NoError(t, err, output)
+	return output
+}
+
+// clone clones the codebase, and checks out the workspace.
+func (ts *testServer) clone(t *testing.T, user *users.User) string {
+	dir := filepath.Join(t.TempDir(), "clone")
+	mustGit(t, "", "clone", ts.remote(user), dir)
+	mustGit(t, dir, "checkout", ts.workspaceID)
+	return dir
+}
+
+func (ts *testServer) commitFile(t *testing.T, dir, name, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644))
+	mustGit(t, dir, "add", name)
+	mustGit(t, dir, "commit", "-m", "update "+name)
+}
+
+// infoRefs returns the status code of a ref advertisement, made with the given credentials.
+func (ts *testServer) infoRefs(t *testing.T, username, password string) int {
+	req, err := http.NewRequest(http.MethodGet, ts.url+"/"+ts.codebaseID.String()+"/info/refs?service=git-upload-pack", nil)
+	require.NoError(t, err)
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	return res.StatusCode
+}
+
+func TestAuth(t *testing.T) {
+	ts := setup(t)
+
+	member := ts.createUser(t)
+	ts.addMember(t, member)
+	outsider := ts.createUser(t)
+
+	assert.Equal(t, http.StatusUnauthorized, ts.infoRefs(t, "", ""), "anonymous")
+	assert.Equal(t, http.StatusUnauthorized, ts.infoRefs(t, member.Email, "wrong"), "wrong password")
+	assert.Equal(t, http.StatusForbidden, ts.infoRefs(t, outsider.Email, password), "not a member")
+	assert.Equal(t, http.StatusOK, ts.infoRefs(t, member.Email, password), "member")
+
+	// git can't serve parts of the codebase
+	ts.setFilesPolicy(t, "src/**", "src/**")
+	assert.Equal(t, http.StatusForbidden, ts.infoRefs(t, member.Email, password), "restricted read access")
+}
+
+func TestAuth_twoFactor(t *testing.T) {
+	ts := setup(t)
+
+	now := time.Now()
+	require.NoError(t, ts.enrollmentRepo.Create(context.Background(), &twofactor.Enrollment{
+		UserID:      ts.owner.ID,
+		Secret:      "JBSWY3DPEHPK3PXP",
+		CreatedAt:   now,
+		ConfirmedAt: &now,
+	}))
+
+	assert.Equal(t, http.StatusUnauthorized, ts.infoRefs(t, ts.owner.Email, password), "the password can't be used without the second factor")
+
+	token, err := ts.jwtService.IssueToken(context.Background(), ts.owner.ID.String(), time.Minute, jwt.TokenTypeAuth)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, ts.infoRefs(t, ts.owner.Email, token.Token), "tokens can be used")
+}
+
+func TestAuth_deactivated(t *testing.T) {
+	ts := setup(t)
+
+	ts.owner.Status = users.StatusDeactivated
+	require.NoError(t, ts.userRepo.Update(ts.owner))
+
+	assert.Equal(t, http.StatusUnauthorized, ts.infoRefs(t, ts.owner.Email, password))
+}
+
+func TestFetchAndPush(t *testing.T) {
+	ts := setup(t)
+
+	dir := ts.clone(t, ts.owner)
+	ts.commitFile(t, dir, "hello.txt", "hello")
+	mustGit(t, dir, "push", "origin", "HEAD:"+ts.workspaceID)
+
+	ws, err := ts.workspaceService.GetByID(context.Background(), ts.workspaceID)
+	require.NoError(t, err)
+	require.NotNil(t, ws.LatestSnapshotID, "the push is snapshotted")
+	_, err = ts.snapshotsService.GetByID(context.Background(), *ws.LatestSnapshotID)
+	require.NoError(t, err)
+
+	// a new clone gets the pushed workspace
+	other := ts.clone(t, ts.owner)
+	assert.Equal(t, "hello", mustGit(t, other, "show", "HEAD:hello.txt"))
+
+	// fetching again does not change the workspace
+	mustGit(t, other, "fetch", "origin")
+	assert.Equal(t,
+		mustGit(t, dir, "rev-parse", "HEAD"),
+		mustGit(t, other, "rev-parse", "origin/"+ts.workspaceID),
+		"the history of the push is kept",
+	)
+
+	// trunk can't be pushed to
+	_, err = runGit(t, dir, "push", "origin", "HEAD:"+trunkBranchName)
+	assert.Error(t, err)
+}
+
+func TestPush_writeACL(t *testing.T) {
+	ts := setup(t)
+	ts.setFilesPolicy(t, "*", "allowed/**")
+
+	dir := ts.clone(t, ts.owner)
+	before, err := ts.workspaceService.GetByID(context.Background(), ts.workspaceID)
+	require.NoError(t, err)
+
+	ts.commitFile(t, dir, "secret.txt", "secret")
+	output, err := runGit(t, dir, "push", "origin", "HEAD:"+ts.workspaceID)
+	assert.Error(t, err)
+	assert.Contains(t, output, "you are not allowed to change secret.txt")
+
+	after, err := ts.workspaceService.GetByID(context.Background(), ts.workspaceID)
+	require.NoError(t, err)
+	assert.Equal(t, before.LatestSnapshotID, after.LatestSnapshotID, "the rejected push is not snapshotted")
+
+	mustGit(t, dir, "reset", "--hard", "HEAD~")
+	ts.commitFile(t, dir, "allowed/file.txt", "allowed")
+	mustGit(t, dir, "push", "origin", "HEAD:"+ts.workspaceID)
+
+	remote := mustGit(t, dir, "ls-remote", "origin", branchRef(ts.workspaceID))
+	assert.True(t, strings.HasPrefix(remote, strings.TrimSpace(mustGit(t, dir, "rev-parse", "HEAD"))))
+}
@@ -0,0 +1,135 @@
+package gitserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/auth/signed"
+	"getsturdy.com/api/pkg/codebases"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Git over ssh is served by the ssh server, which authenticates users with their public keys. The ssh server forwards
+// git-upload-pack and git-receive-pack sessions to the gitserver as an upgraded http connection, signed with a secret
+// that is shared between the two servers.
+const sshUpgradeProtocol = "git"
+
+func (h *Server) sshAuth(c *gin.Context) {
+	if h.cfg.SSHSecret == "" {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	userID, err := signed.Verify(c.Request, []byte(h.cfg.SSHSecret), time.Now())
+	if err != nil {
+		h.logger.Warn("ssh request verification failed", zap.Error(err))
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), &auth.Subject{
+		ID:   userID.String(),
+		Type: auth.SubjectUser,
+	}))
+
+	h.authorizeCodebase(c)
+}
+
+func (h *Server) handleSSHUploadPack(c *gin.Context) {
+	ctx := c.Request.Context()
+	codebaseID := codebases.ID(c.Param("codebaseId"))
+
+	if !strings.EqualFold(c.GetHeader("Upgrade"), sshUpgradeProtocol) {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	conn, rw, err := upgrade(c)
+	if err != nil {
+		h.logger.Error("upload-pack failed to upgrade connection", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	refs := &workspaceRefs{}
+	if err := h.syncWorkspaceRefsForRead(ctx, codebaseID, refs); err != nil {
+		h.logger.Error("failed to sync workspace refs", zap.Error(err))
+		return
+	}
+
+	if err := h.executorProvider.New().
+		Read(uploadPack(rw, conn)).
+		ExecTrunk(codebaseID, "gitserverSSHUploadPack"); err != nil {
+		h.logger.Error("failed to handle ssh upload pack", zap.Error(err))
+		return
+	}
+}
+
+func (h *Server) handleSSHReceivePack(c *gin.Context) {
+	ctx := c.Request.Context()
+	codebaseID := codebases.ID(c.Param("codebaseId"))
+
+	if !strings.EqualFold(c.GetHeader("Upgrade"), sshUpgradeProtocol) {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.userService.GetByID(ctx, userID)
+	if err != nil {
+		h.logger.Error("receive-pack failed to get user", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	allower, err := h.writeAllower(c)
+	if err != nil {
+		h.logger.Error("receive-pack failed to get allower", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := upgrade(c)
+	if err != nil {
+		h.logger.Error("receive-pack failed to upgrade connection", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	refs := &workspaceRefs{}
+	if err := h.executorProvider.New().
+		GitWrite(h.syncWorkspaceRefs(ctx, codebaseID, refs)).
+		FileReadGitWrite(h.receivePack(ctx, refs, user, allower, rw, conn)).
+		ExecTrunk(codebaseID, "gitserverSSHReceivePack"); err != nil {
+		h.logger.Error("failed to handle ssh receive pack", zap.Error(err))
+		return
+	}
+}
+
+// upgrade hijacks the connection of the request, and switches it to the raw git protocol.
+func upgrade(c *gin.Context) (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := c.Writer.Hijack()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: %s\r\n\r\n", sshUpgradeProtocol)
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to write response: %w", err)
+	}
+
+	return conn, rw, nil
+}
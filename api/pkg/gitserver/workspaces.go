@@ -0,0 +1,387 @@
+package gitserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/snapshots"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	"getsturdy.com/api/pkg/unidiff"
+	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/pkg/workspaces"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+
+	"github.com/google/uuid"
+	git "github.com/libgit2/git2go/v33"
+	"go.uber.org/zap"
+)
+
+// Workspaces are served from the trunk repository, using a git namespace. Every workspace in the codebase is a branch
+// named after the workspace id, pointing to the latest snapshot of the workspace. Trunk is available as a read-only
+// sturdytrunk branch.
+const (
+	workspacesNamespace = "workspaces"
+	trunkBranchName     = "sturdytrunk"
+)
+
+func namespacedRef(name string) string {
+	return fmt.Sprintf("refs/namespaces/%s/%s", workspacesNamespace, name)
+}
+
+func branchRef(branchName string) string {
+	return "refs/heads/" + branchName
+}
+
+// workspaceRefs keeps track of the workspace branches in the namespace.
+type workspaceRefs struct {
+	workspaces map[string]*workspaces.Workspace
+}
+
+// Workspace returns the workspace of a namespaced ref, or nil if the ref is not a workspace branch.
+func (r *workspaceRefs) Workspace(namespacedName string) *workspaces.Workspace {
+	return r.workspaces[namespacedName]
+}
+
+// Writable returns the (not namespaced) refs that can be pushed to. Workspaces that are connected to a view are read-only,
+// as the contents of the view is the source of truth for them.
+func (r *workspaceRefs) Writable() []string {
+	var refs []string
+	for _, ws := range r.workspaces {
+		if ws.ViewID != nil {
+			continue
+		}
+		refs = append(refs, branchRef(ws.ID))
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// refUpdates are the changes to the branches in the workspaces namespace that make them match the workspaces of the
+// codebase.
+type refUpdates struct {
+	create map[string]string
+	delete []string
+}
+
+func (u *refUpdates) empty() bool {
+	return len(u.create) == 0 && len(u.delete) == 0
+}
+
+// workspaceRefUpdates returns the updates that syncWorkspaceRefs would make, without making them.
+//
+// If a branch already points to a commit with the same tree as the latest snapshot (which is the case right after a push),
+// it's left as is, so that the history of the pushed commits is preserved for the client.
+func (h *Server) workspaceRefUpdates(ctx context.Context, repo vcs.RepoGitReader, codebaseID codebases.ID, refs *workspaceRefs) (*refUpdates, error) {
+	wss, err := h.workspaceService.ListByCodebaseID(ctx, codebaseID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	existing, err := repo.References(namespacedRef("refs/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	wanted := map[string]string{}
+	if trunkCommitSHA, err := repo.BranchCommitID(trunkBranchName); err == nil {
+		wanted[namespacedRef(branchRef(trunkBranchName))] = trunkCommitSHA
+	}
+
+	refs.workspaces = map[string]*workspaces.Workspace{}
+	for _, ws := range wss {
+		commitSHA, err := h.workspaceCommitSHA(ctx, repo, ws)
+		if err != nil {
+			h.logger.Warn("skipping workspace without commit", zap.String("workspace_id", ws.ID), zap.Error(err))
+			continue
+		}
+
+		ref := namespacedRef(branchRef(ws.ID))
+		refs.workspaces[ref] = ws
+
+		if current, found := existing[ref]; found && current != commitSHA {
+			if same, err := sameTree(repo, current, commitSHA); err != nil {
+				return nil, fmt.Errorf("failed to compare trees: %w", err)
+			} else if same {
+				commitSHA = current
+			}
+		}
+		wanted[ref] = commitSHA
+	}
+
+	updates := &refUpdates{create: map[string]string{}}
+	for ref, commitSHA := range wanted {
+		if existing[ref] != commitSHA {
+			updates.create[ref] = commitSHA
+		}
+	}
+	for ref := range existing {
+		if _, found := wanted[ref]; !found {
+			updates.delete = append(updates.delete, ref)
+		}
+	}
+	return updates, nil
+}
+
+// syncWorkspaceRefs updates the branches in the workspaces namespace to match the workspaces of the codebase.
+func (h *Server) syncWorkspaceRefs(ctx context.Context, codebaseID codebases.ID, refs *workspaceRefs) executor.GitWriteFunc {
+	return func(repo vcs.RepoGitWriter) error {
+		updates, err := h.workspaceRefUpdates(ctx, repo, codebaseID, refs)
+		if err != nil {
+			return err
+		}
+		if updates.empty() {
+			return nil
+		}
+
+		for ref, commitSHA := range updates.create {
+			if err := repo.CreateRef(ref, commitSHA); err != nil {
+				return fmt.Errorf("failed to create ref %s: %w", ref, err)
+			}
+		}
+
+		for _, ref := range updates.delete {
+			if err := repo.DeleteRef(ref); err != nil {
+				return fmt.Errorf("failed to delete ref %s: %w", ref, err)
+			}
+		}
+
+		if err := repo.CreateSymbolicRef(namespacedRef("HEAD"), namespacedRef(branchRef(trunkBranchName))); err != nil {
+			return fmt.Errorf("failed to create HEAD: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// syncWorkspaceRefsForRead updates the branches in the workspaces namespace for fetches. The repository is only
+// locked for writing if the branches have changed since they were last synced, so that fetches don't block each other.
+func (h *Server) syncWorkspaceRefsForRead(ctx context.Context, codebaseID codebases.ID, refs *workspaceRefs) error {
+	changed := false
+	if err := h.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		updates, err := h.workspaceRefUpdates(ctx, repo, codebaseID, refs)
+		if err != nil {
+			return err
+		}
+		changed = !updates.empty()
+		return nil
+	}).ExecTrunk(codebaseID, "gitserverWorkspaceRefUpdates"); err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return h.executorProvider.New().
+		GitWrite(h.syncWorkspaceRefs(ctx, codebaseID, refs)).
+		ExecTrunk(codebaseID, "gitserverSyncWorkspaceRefs")
+}
+
+// uploadPack serves the workspace branches with git upload-pack. The refs must have been synced by
+// syncWorkspaceRefsForRead beforehand.
+func uploadPack(stdin io.Reader, stdout io.Writer, args ...string) executor.FileReadFunc {
+	return func(repo vcs.RepoReader) error {
+		args := append([]string{"--namespace=" + workspacesNamespace, "upload-pack"}, args...)
+		cmd := exec.Command("git", append(args, repo.Path())...)
+		cmd.Stdout = stdout
+		if err := runWithStdin(cmd, stdin); err != nil {
+			return fmt.Errorf("failed to upload pack: %w", err)
+		}
+		return nil
+	}
+}
+
+// receivePack receives pushes to the workspace branches with git receive-pack, and snapshots all workspaces that have
+// been pushed to. Pushes that change files that the user can't write to, according to allower, are rejected. The refs
+// must have been synced by syncWorkspaceRefs beforehand.
+func (h *Server) receivePack(ctx context.Context, refs *workspaceRefs, user *users.User, allower *unidiff.Allower, stdin io.Reader, stdout io.Writer, args ...string) executor.FileReadGitWriteFunc {
+	return func(repo vcs.RepoReaderGitWriter) error {
+		env := append(os.Environ(), writableRefsEnv+"="+strings.Join(refs.Writable(), " "))
+		if !allower.AllowsAll() {
+			patterns, err := json.Marshal(allower.Patterns)
+			if err != nil {
+				return fmt.Errorf("failed to encode allowed files: %w", err)
+			}
+			env = append(env, writeAllowerEnv+"="+string(patterns), executableEnv+"="+h.executable)
+		}
+
+		before, err := repo.References(namespacedRef("refs/"))
+		if err != nil {
+			return fmt.Errorf("failed to list references: %w", err)
+		}
+
+		args := append([]string{
+			"--namespace=" + workspacesNamespace,
+			"-c", "core.hooksPath=" + h.hooksPath,
+			"receive-pack",
+		}, args...)
+		cmd := exec.Command("git", append(args, repo.Path())...)
+		cmd.Env = env
+		cmd.Stdout = stdout
+		if err := runWithStdin(cmd, stdin); err != nil {
+			return fmt.Errorf("failed to receive pack: %w", err)
+		}
+
+		after, err := repo.References(namespacedRef("refs/"))
+		if err != nil {
+			return fmt.Errorf("failed to list references: %w", err)
+		}
+
+		return h.snapshotPushedRefs(ctx, repo, refs, before, after, user)
+	}
+}
+
+// runWithStdin runs the command with stdin copied to it. Unlike cmd.Run with cmd.Stdin set, it returns as soon as the
+// command exits, without waiting for stdin to be closed. Ssh clients keep the connection open until git is done.
+func runWithStdin(cmd *exec.Cmd, stdin io.Reader) error {
+	pipe, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdin: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	go func() {
+		_, _ = io.Copy(pipe, stdin)
+		_ = pipe.Close()
+	}()
+
+	return cmd.Wait()
+}
+
+// workspaceCommitSHA returns the commit with the current contents of the workspace.
+func (h *Server) workspaceCommitSHA(ctx context.Context, repo vcs.RepoGitReader, ws *workspaces.Workspace) (string, error) {
+	if ws.LatestSnapshotID == nil {
+		return repo.BranchCommitID(ws.ID)
+	}
+
+	snapshot, err := h.snapshotsService.GetByID(ctx, *ws.LatestSnapshotID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	return snapshot.CommitSHA, nil
+}
+
+// workspaceBaseCommitSHA returns the trunk commit that the workspace is based on.
+func (h *Server) workspaceBaseCommitSHA(ctx context.Context, repo vcs.RepoGitReader, ws *workspaces.Workspace) (string, error) {
+	if ws.LatestSnapshotID == nil {
+		return repo.BranchCommitID(ws.ID)
+	}
+
+	snapshot, err := h.snapshotsService.GetByID(ctx, *ws.LatestSnapshotID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	parents, err := repo.GetCommitParents(snapshot.CommitSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot parents: %w", err)
+	}
+	if len(parents) != 1 {
+		return "", fmt.Errorf("unexpected number of snapshot parents: %d, expected %d", len(parents), 1)
+	}
+	return parents[0], nil
+}
+
+// snapshotPushedCommit creates a new snapshot of the workspace with the contents of the pushed commit.
+//
+// Snapshots are always a single commit on top of the workspace base, so if the pushed commit is not, a new commit with
+// the same tree is created.
+func (h *Server) snapshotPushedCommit(ctx context.Context, repo vcs.RepoReaderGitWriter, ws *workspaces.Workspace, commitSHA string, user *users.User) (*snapshots.Snapshot, error) {
+	baseCommitSHA, err := h.workspaceBaseCommitSHA(ctx, repo, ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base commit: %w", err)
+	}
+
+	parents, err := repo.GetCommitParents(commitSHA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit parents: %w", err)
+	}
+
+	snapshotCommitSHA := commitSHA
+	if len(parents) != 1 || parents[0] != baseCommitSHA {
+		signature := git.Signature{
+			Name:  user.Name,
+			Email: user.Email,
+			When:  time.Now(),
+		}
+		branchName := "push-" + uuid.NewString()
+		snapshotCommitSHA, err = repo.CreateNewCommitBasedOnCommitWithParent(branchName, commitSHA, baseCommitSHA, signature, "Snapshot of "+ws.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create snapshot commit: %w", err)
+		}
+		if err := repo.DeleteBranch(branchName); err != nil {
+			return nil, fmt.Errorf("failed to delete temporary branch: %w", err)
+		}
+	}
+
+	snapshot, err := h.snapshotsService.Snapshot(ctx,
+		ws.CodebaseID,
+		ws.ID,
+		snapshots.ActionGitPush,
+		service_snapshots.WithOnTemporaryView(),
+		service_snapshots.WithMarkAsLatestInWorkspace(),
+		service_snapshots.WithOnExistingCommit(snapshotCommitSHA),
+		service_snapshots.WithOnRepo(repo), // Re-use repo context
+		service_snapshots.WithUser(user),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// snapshotPushedRefs snapshots all workspaces whose branches have been updated between before and after.
+func (h *Server) snapshotPushedRefs(ctx context.Context, repo vcs.RepoReaderGitWriter, refs *workspaceRefs, before, after map[string]string, user *users.User) error {
+	for ref, commitSHA := range after {
+		if before[ref] == commitSHA {
+			continue
+		}
+
+		ws := refs.Workspace(ref)
+		if ws == nil {
+			// should not happen, the pre-receive hook only accepts updates to workspace branches
+			h.logger.Warn("unexpected ref pushed", zap.String("ref", ref))
+			continue
+		}
+
+		snapshot, err := h.snapshotPushedCommit(ctx, repo, ws, commitSHA, user)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot workspace %s: %w", ws.ID, err)
+		}
+
+		h.logger.Info("workspace pushed",
+			zap.String("workspace_id", ws.ID),
+			zap.Stringer("snapshot_id", snapshot.ID),
+			zap.Stringer("user_id", user.ID),
+		)
+	}
+	return nil
+}
+
+func sameTree(repo vcs.RepoGitReader, firstCommitSHA, secondCommitSHA string) (bool, error) {
+	first, err := repo.Commit(firstCommitSHA)
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", firstCommitSHA, err)
+	}
+	defer first.Free()
+
+	second, err := repo.Commit(secondCommitSHA)
+	if err != nil {
+		return false, fmt.Errorf("failed to get commit %s: %w", secondCommitSHA, err)
+	}
+	defer second.Free()
+
+	return first.TreeId().Equal(second.TreeId()), nil
+}
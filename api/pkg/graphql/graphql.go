@@ -16,6 +16,7 @@ import (
 	"getsturdy.com/api/pkg/graphql/schema"
 	"getsturdy.com/api/pkg/ip"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/graph-gophers/graphql-go"
@@ -37,40 +38,48 @@ type RootResolver struct {
 	resolvers.ChangeRootResolver
 	resolvers.CodebaseGitHubIntegrationRootResolver
 	resolvers.CodebaseRootResolver
+	resolvers.CodeSearchRootResolver
 	resolvers.CommentRootResolver
 	resolvers.CryptoRootResolver
 	resolvers.FeaturesRootResolver
 	resolvers.GitHubAppRootResolver
 	resolvers.GitHubPullRequestRootResolver
 	resolvers.GitHubRootResolver
+	resolvers.GitLabInstantIntegrationRootResolver
 	resolvers.InstallationsRootResolver
 	resolvers.IntegrationRootResolver
 	resolvers.LicenseRootResolver
 	resolvers.NotificationRootResolver
 	resolvers.OnboardingRootResolver
 	resolvers.OrganizationRootResolver
+	resolvers.PersonalAccessTokensRootResolver
 	resolvers.PKIRootResolver
 	resolvers.PresenceRootResolver
 	resolvers.RemoteRootResolver
 	resolvers.ReviewRootResolver
+	resolvers.RunnerInstantIntegrationRootResolver
 	resolvers.ServiceTokensRootResolver
 	resolvers.StatusesRootResolver
 	resolvers.SuggestionRootResolver
+	resolvers.TwoFactorRootResolver
 	resolvers.UserRootResolver
 	resolvers.ViewRootResolver
+	resolvers.WebhookInstantIntegrationRootResolver
 	resolvers.WorkspaceRootResolver
 	resolvers.WorkspaceWatcherRootResolver
 	resolvers.LandRootResovler
 	resolvers.SnapshotsRootResolver
 
-	schema     *graphql.Schema
-	jwtService *service_jwt.Service
-	logger     *zap.Logger
+	schema                *graphql.Schema
+	jwtService            *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
+	logger                *zap.Logger
 }
 
 func NewRootResolver(
 	logger *zap.Logger,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 
 	aclRootResolver resolvers.ACLRootResolver,
 	activityRootResolver resolvers.ActivityRootResolver,
@@ -79,35 +88,42 @@ func NewRootResolver(
 	changeRootResolver resolvers.ChangeRootResolver,
 	codebaseGitHubIntegrationRootResolver resolvers.CodebaseGitHubIntegrationRootResolver,
 	codebaseRootResolver resolvers.CodebaseRootResolver,
+	codeSearchRootResolver resolvers.CodeSearchRootResolver,
 	commentsRootResolver resolvers.CommentRootResolver,
 	cryptoRootResolver resolvers.CryptoRootResolver,
 	featuresRootResolver resolvers.FeaturesRootResolver,
 	gitHubRootResolver resolvers.GitHubRootResolver,
 	githubAppRootResolver resolvers.GitHubAppRootResolver,
+	gitLabRootResolver resolvers.GitLabInstantIntegrationRootResolver,
 	instantIntegrationRootResolver resolvers.IntegrationRootResolver,
 	licenseRootResolver resolvers.LicenseRootResolver,
 	notificationRootResolver resolvers.NotificationRootResolver,
 	onboardingRootResolver resolvers.OnboardingRootResolver,
 	organizationRootResolver resolvers.OrganizationRootResolver,
+	personalAccessTokensRootResolver resolvers.PersonalAccessTokensRootResolver,
 	pkiRootResolver resolvers.PKIRootResolver,
 	gitHubPullRequestRootResolver resolvers.GitHubPullRequestRootResolver,
 	presenceRootResolver resolvers.PresenceRootResolver,
 	remoteRootResolver resolvers.RemoteRootResolver,
 	reviewRootResolver resolvers.ReviewRootResolver,
+	runnerRootResolver resolvers.RunnerInstantIntegrationRootResolver,
 	installationsRootResolver resolvers.InstallationsRootResolver,
 	serviceTokensRootResolver resolvers.ServiceTokensRootResolver,
 	statusRootResolver resolvers.StatusesRootResolver,
 	suggestionRootResolver resolvers.SuggestionRootResolver,
+	twoFactorRootResolver resolvers.TwoFactorRootResolver,
 	userRootResolver resolvers.UserRootResolver,
 	viewRootResolver resolvers.ViewRootResolver,
+	webhookRootResolver resolvers.WebhookInstantIntegrationRootResolver,
 	workspaceRootResolver resolvers.WorkspaceRootResolver,
 	workspaceWatcherRootResolver resolvers.WorkspaceWatcherRootResolver,
 	landRootResolver resolvers.LandRootResovler,
 	snapshotsRootResolver resolvers.SnapshotsRootResolver,
 ) *RootResolver {
 	r := &RootResolver{
-		jwtService: jwtService,
-		logger:     logger,
+		jwtService:            jwtService,
+		personalTokensService: personalTokensService,
+		logger:                logger,
 
 		ACLRootResolver:                         aclRootResolver,
 		ActivityRootResolver:                    activityRootResolver,
@@ -116,27 +132,33 @@ func NewRootResolver(
 		ChangeRootResolver:                      changeRootResolver,
 		CodebaseGitHubIntegrationRootResolver:   codebaseGitHubIntegrationRootResolver,
 		CodebaseRootResolver:                    codebaseRootResolver,
+		CodeSearchRootResolver:                  codeSearchRootResolver,
 		CommentRootResolver:                     commentsRootResolver,
 		CryptoRootResolver:                      cryptoRootResolver,
 		FeaturesRootResolver:                    featuresRootResolver,
 		GitHubAppRootResolver:                   githubAppRootResolver,
 		GitHubPullRequestRootResolver:           gitHubPullRequestRootResolver,
 		GitHubRootResolver:                      gitHubRootResolver,
+		GitLabInstantIntegrationRootResolver:    gitLabRootResolver,
 		InstallationsRootResolver:               installationsRootResolver,
 		IntegrationRootResolver:                 instantIntegrationRootResolver,
 		LicenseRootResolver:                     licenseRootResolver,
 		NotificationRootResolver:                notificationRootResolver,
 		OnboardingRootResolver:                  onboardingRootResolver,
 		OrganizationRootResolver:                organizationRootResolver,
+		PersonalAccessTokensRootResolver:        personalAccessTokensRootResolver,
 		PKIRootResolver:                         pkiRootResolver,
 		PresenceRootResolver:                    presenceRootResolver,
 		RemoteRootResolver:                      remoteRootResolver,
 		ReviewRootResolver:                      reviewRootResolver,
+		RunnerInstantIntegrationRootResolver:    runnerRootResolver,
 		ServiceTokensRootResolver:               serviceTokensRootResolver,
 		StatusesRootResolver:                    statusRootResolver,
 		SuggestionRootResolver:                  suggestionRootResolver,
+		TwoFactorRootResolver:                   twoFactorRootResolver,
 		UserRootResolver:                        userRootResolver,
 		ViewRootResolver:                        viewRootResolver,
+		WebhookInstantIntegrationRootResolver:   webhookRootResolver,
 		WorkspaceRootResolver:                   workspaceRootResolver,
 		WorkspaceWatcherRootResolver:            workspaceWatcherRootResolver,
 		LandRootResovler:                        landRootResolver,
@@ -181,11 +203,12 @@ func (r *RootResolver) UnauthenticatedHttpHandler(logger *zap.Logger) gin.Handle
 }
 
 type websocketContextBuilder struct {
-	jwtService *service_jwt.Service
+	jwtService            *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
 }
 
 func (c *websocketContextBuilder) BuildContext(ctx context.Context, r *http.Request) (context.Context, error) {
-	subject, err := auth.SubjectFromRequest(r, c.jwtService)
+	subject, err := auth.SubjectFromRequest(r, c.jwtService, c.personalTokensService)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +223,8 @@ func (r *RootResolver) WebsocketHandler() gin.HandlerFunc {
 	h := graphqlws.NewHandlerFunc(r.schema, &relay.Handler{
 		Schema: r.schema,
 	}, graphqlws.WithContextGenerator(&websocketContextBuilder{
-		jwtService: r.jwtService,
+		jwtService:            r.jwtService,
+		personalTokensService: r.personalTokensService,
 	}))
 
 	return func(c *gin.Context) {
@@ -52,6 +52,7 @@ type UpdateCodebaseInput struct {
 	Archive              *bool
 	IsPublic             *bool
 	RequireHealthyStatus *bool
+	RequiredStatusChecks *[]string
 }
 
 type CodebaseResolver interface {
@@ -79,6 +80,7 @@ type CodebaseResolver interface {
 	Organization(ctx context.Context) (OrganizationResolver, error)
 	Remote(context.Context) (RemoteResolver, error)
 	RequireHealthyStatus() bool
+	RequiredStatusChecks() []string
 
 	Writeable(context.Context) bool
 }
@@ -90,6 +92,12 @@ type CodebaseChangesArgs struct {
 type CodebaseChangesInput struct {
 	Before *graphql.ID
 	Limit  *int32
+
+	Path          *string
+	AuthorID      *graphql.ID
+	CreatedAfter  *int32
+	CreatedBefore *int32
+	Search        *string
 }
 
 type CodebaseFileArgs struct {
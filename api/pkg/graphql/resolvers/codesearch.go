@@ -0,0 +1,25 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type CodeSearchRootResolver interface {
+	SearchCode(context.Context, SearchCodeArgs) ([]CodeSearchMatchResolver, error)
+}
+
+type SearchCodeArgs struct {
+	CodebaseID graphql.ID
+	Query      string
+	PathGlob   *string
+	Regex      *bool
+	Limit      *int32
+}
+
+type CodeSearchMatchResolver interface {
+	Path() string
+	Line() int32
+	Snippet() string
+}
@@ -9,4 +9,13 @@ type ConflictingFileResolver interface {
 	Path() string
 	WorkspaceDiff() (FileDiffResolver, error)
 	TrunkDiff() (FileDiffResolver, error)
+	Hunks() []ConflictHunkResolver
+}
+
+type ConflictHunkResolver interface {
+	ID() graphql.ID
+	Index() int32
+	Base() string
+	Workspace() string
+	Trunk() string
 }
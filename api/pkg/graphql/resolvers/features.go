@@ -8,6 +8,7 @@ type Feature string
 
 const (
 	FeatureBuildkite Feature = "Buildkite"
+	FeatureGitLab    Feature = "GitLab"
 	FeatureRemote    Feature = "Remote"
 
 	FeatureGitHub              Feature = "GitHub"              // If the GitHub feature is available and ready to use
@@ -28,6 +28,13 @@ type FileResolver interface {
 	Contents() string
 	MimeType() string
 	Info() FileInfoResolver
+	Blame(context.Context) ([]BlameRangeResolver, error)
+}
+
+type BlameRangeResolver interface {
+	StartLine() int32
+	EndLine() int32
+	Change(context.Context) (ChangeResolver, error)
 }
 
 type DirectoryResolver interface {
@@ -0,0 +1,38 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type GitLabInstantIntegrationRootResolver interface {
+	// mutations
+	CreateOrUpdateGitLabIntegration(context.Context, CreateOrUpdateGitLabIntegrationArgs) (IntegrationResolver, error)
+
+	// internal
+	InternalGitLabConfigurationByIntegrationID(context.Context, string) (GitLabConfigurationResolver, error)
+}
+
+type CreateOrUpdateGitLabIntegrationArgs struct {
+	Input CreateOrUpdateGitLabIntegrationInput
+}
+
+type CreateOrUpdateGitLabIntegrationInput struct {
+	CodebaseID    graphql.ID
+	IntegrationID *graphql.ID
+	InstanceURL   *string
+	ProjectID     string
+	Ref           *string
+	TriggerToken  string
+	WebhookToken  string
+}
+
+type GitLabConfigurationResolver interface {
+	ID() graphql.ID
+	InstanceURL() string
+	ProjectID() string
+	Ref() string
+	TriggerToken() string
+	WebhookToken() string
+}
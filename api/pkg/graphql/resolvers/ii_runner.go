@@ -0,0 +1,20 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type RunnerInstantIntegrationRootResolver interface {
+	// mutations
+	CreateRunnerIntegration(context.Context, CreateRunnerIntegrationArgs) (IntegrationResolver, error)
+}
+
+type CreateRunnerIntegrationArgs struct {
+	Input CreateRunnerIntegrationInput
+}
+
+type CreateRunnerIntegrationInput struct {
+	CodebaseID graphql.ID
+}
@@ -0,0 +1,49 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type WebhookInstantIntegrationRootResolver interface {
+	// mutations
+	CreateOrUpdateWebhookIntegration(context.Context, CreateOrUpdateWebhookIntegrationArgs) (IntegrationResolver, error)
+
+	// internal
+	InternalWebhookConfigurationByIntegrationID(context.Context, string) (WebhookConfigurationResolver, error)
+	InternalWebhookDeliveriesByIntegrationID(context.Context, string, WebhookDeliveriesArgs) ([]WebhookDeliveryResolver, error)
+}
+
+type CreateOrUpdateWebhookIntegrationArgs struct {
+	Input CreateOrUpdateWebhookIntegrationInput
+}
+
+type CreateOrUpdateWebhookIntegrationInput struct {
+	CodebaseID    graphql.ID
+	IntegrationID *graphql.ID
+	URL           string
+	Secret        string
+}
+
+type WebhookDeliveriesArgs struct {
+	First *int32
+}
+
+type WebhookConfigurationResolver interface {
+	ID() graphql.ID
+	URL() string
+	Secret() string
+}
+
+type WebhookDeliveryResolver interface {
+	ID() graphql.ID
+	Event() string
+	CommitSHA() string
+	Attempts() int32
+	StatusCode() *int32
+	Error() *string
+	Succeeded() bool
+	CreatedAt() int32
+	FinishedAt() *int32
+}
@@ -53,8 +53,28 @@ type BuildkiteIntegration interface {
 	Configuration(context.Context) (BuildkiteConfigurationResolver, error)
 }
 
+type WebhookIntegration interface {
+	commonIntegrationResolver
+
+	Configuration(context.Context) (WebhookConfigurationResolver, error)
+	Deliveries(context.Context, WebhookDeliveriesArgs) ([]WebhookDeliveryResolver, error)
+}
+
+type GitLabIntegration interface {
+	commonIntegrationResolver
+
+	Configuration(context.Context) (GitLabConfigurationResolver, error)
+}
+
+type RunnerIntegration interface {
+	commonIntegrationResolver
+}
+
 type IntegrationResolver interface {
 	ToBuildkiteIntegration() (BuildkiteIntegration, bool)
+	ToWebhookIntegration() (WebhookIntegration, bool)
+	ToGitLabIntegration() (GitLabIntegration, bool)
+	ToRunnerIntegration() (RunnerIntegration, bool)
 
 	commonIntegrationResolver
 }
@@ -64,4 +84,7 @@ type InstantIntegrationProviderType string
 const (
 	InstantIntegrationProviderUndefined InstantIntegrationProviderType = ""
 	InstantIntegrationProviderBuildkite InstantIntegrationProviderType = "Buildkite"
+	InstantIntegrationProviderWebhook   InstantIntegrationProviderType = "Webhook"
+	InstantIntegrationProviderGitLab    InstantIntegrationProviderType = "GitLab"
+	InstantIntegrationProviderRunner    InstantIntegrationProviderType = "Runner"
 )
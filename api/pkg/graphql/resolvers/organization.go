@@ -15,6 +15,10 @@ type OrganizationRootResolver interface {
 	UpdateOrganization(context.Context, UpdateOrganizationArgs) (OrganizationResolver, error)
 	AddUserToOrganization(context.Context, AddUserToOrganizationArgs) (OrganizationResolver, error)
 	RemoveUserFromOrganization(context.Context, RemoveUserFromOrganizationArgs) (OrganizationResolver, error)
+	UpdateOrganizationMemberRole(context.Context, UpdateOrganizationMemberRoleArgs) (OrganizationResolver, error)
+	CreateScimToken(context.Context, CreateScimTokenArgs) (ScimTokenResolver, error)
+	DeleteScimToken(context.Context, DeleteScimTokenArgs) (OrganizationResolver, error)
+	UpdateOrganizationTwoFactorRequirement(context.Context, UpdateOrganizationTwoFactorRequirementArgs) (OrganizationResolver, error)
 
 	// Subscription
 	UpdatedOrganization(context.Context, UpdatedOrganizationArgs) (<-chan OrganizationResolver, error)
@@ -25,7 +29,12 @@ type OrganizationResolver interface {
 	ShortID() graphql.ID
 	Name() string
 	Members(context.Context) ([]AuthorResolver, error)
+	Memberships(context.Context) ([]OrganizationMemberResolver, error)
 	Codebases(context.Context) ([]CodebaseResolver, error)
+	ScimTokens(context.Context) ([]ScimTokenResolver, error)
+	AuditLog(context.Context, AuditLogArgs) ([]AuditLogEntryResolver, error)
+	RequireTwoFactor() bool
+	LdapSync(context.Context) (LdapSyncResolver, error)
 
 	Licenses(context.Context) ([]LicenseResolver, error)
 
@@ -75,3 +84,109 @@ type RemoveUserFromOrganizationInput struct {
 	OrganizationID graphql.ID
 	UserID         graphql.ID
 }
+
+type UpdateOrganizationMemberRoleArgs struct {
+	Input UpdateOrganizationMemberRoleInput
+}
+
+type UpdateOrganizationMemberRoleInput struct {
+	OrganizationID graphql.ID
+	UserID         graphql.ID
+	Role           OrganizationMemberRole
+}
+
+type OrganizationMemberRole string
+
+const (
+	OrganizationMemberRoleUndefined OrganizationMemberRole = ""
+	OrganizationMemberRoleOwner     OrganizationMemberRole = "Owner"
+	OrganizationMemberRoleAdmin     OrganizationMemberRole = "Admin"
+	OrganizationMemberRoleMember    OrganizationMemberRole = "Member"
+	OrganizationMemberRoleGuest     OrganizationMemberRole = "Guest"
+)
+
+type OrganizationMemberResolver interface {
+	ID() graphql.ID
+	Author(context.Context) (AuthorResolver, error)
+	Role() (OrganizationMemberRole, error)
+}
+
+type ScimTokenResolver interface {
+	ID() graphql.ID
+	Token() *string
+	CreatedBy(context.Context) (AuthorResolver, error)
+	CreatedAt() int32
+	LastUsedAt() *int32
+}
+
+type LdapSyncResolver interface {
+	LastStartedAt() *int32
+	LastFinishedAt() *int32
+	Error() *string
+	AddedGroups() []string
+	RemovedGroups() []string
+	Groups(context.Context) ([]LdapGroupResolver, error)
+}
+
+type LdapGroupResolver interface {
+	ID() graphql.ID
+	Name() string
+	Dn() string
+	Members(context.Context) ([]AuthorResolver, error)
+	UnmatchedMembers() []string
+	AddedMembers(context.Context) ([]AuthorResolver, error)
+	RemovedMembers(context.Context) ([]AuthorResolver, error)
+	SyncedAt() int32
+}
+
+type CreateScimTokenArgs struct {
+	Input CreateScimTokenInput
+}
+
+type CreateScimTokenInput struct {
+	OrganizationID graphql.ID
+}
+
+type DeleteScimTokenArgs struct {
+	Input DeleteScimTokenInput
+}
+
+type DeleteScimTokenInput struct {
+	ID graphql.ID
+}
+
+type UpdateOrganizationTwoFactorRequirementArgs struct {
+	Input UpdateOrganizationTwoFactorRequirementInput
+}
+
+type UpdateOrganizationTwoFactorRequirementInput struct {
+	OrganizationID   graphql.ID
+	RequireTwoFactor bool
+}
+
+type AuditLogArgs struct {
+	Input *AuditLogInput
+}
+
+type AuditLogInput struct {
+	CodebaseID *graphql.ID
+	ActorID    *graphql.ID
+	Actions    *[]string
+	Since      *int32
+	Until      *int32
+	Limit      *int32
+}
+
+type AuditLogEntryResolver interface {
+	ID() graphql.ID
+	CreatedAt() int32
+	Action() string
+	ActorType() string
+	Actor(context.Context) (AuthorResolver, error)
+	IP() *string
+	CodebaseID() *graphql.ID
+	TargetType() string
+	TargetID() graphql.ID
+	Before() (*string, error)
+	After() (*string, error)
+}
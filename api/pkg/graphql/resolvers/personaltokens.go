@@ -0,0 +1,55 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type PersonalAccessTokensRootResolver interface {
+	PersonalAccessTokens(context.Context) ([]PersonalAccessTokenResolver, error)
+
+	CreatePersonalAccessToken(context.Context, CreatePersonalAccessTokenArgs) (PersonalAccessTokenResolver, error)
+	RevokePersonalAccessToken(context.Context, RevokePersonalAccessTokenArgs) (PersonalAccessTokenResolver, error)
+}
+
+type CreatePersonalAccessTokenArgs struct {
+	Input CreatePersonalAccessTokenInput
+}
+
+type CreatePersonalAccessTokenInput struct {
+	Name      string
+	Scopes    []PersonalAccessTokenScope
+	ExpiresAt *int32
+}
+
+type RevokePersonalAccessTokenArgs struct {
+	Input RevokePersonalAccessTokenInput
+}
+
+type RevokePersonalAccessTokenInput struct {
+	ID graphql.ID
+}
+
+type PersonalAccessTokenScope string
+
+const (
+	PersonalAccessTokenScopeUndefined      PersonalAccessTokenScope = ""
+	PersonalAccessTokenScopeReadCodebase   PersonalAccessTokenScope = "ReadCodebase"
+	PersonalAccessTokenScopeWriteWorkspace PersonalAccessTokenScope = "WriteWorkspace"
+	PersonalAccessTokenScopeLand           PersonalAccessTokenScope = "Land"
+	PersonalAccessTokenScopeAdmin          PersonalAccessTokenScope = "Admin"
+)
+
+type PersonalAccessTokenResolver interface {
+	ID() graphql.ID
+	Name() string
+	Scopes() ([]PersonalAccessTokenScope, error)
+	CreatedAt() int32
+	ExpiresAt() *int32
+	LastUsedAt() *int32
+	LastUsedIP() *string
+	RevokedAt() *int32
+
+	Token() *string
+}
@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+
 	"github.com/graph-gophers/graphql-go"
 )
 
@@ -11,6 +12,6 @@ type RebaseStatusRootResolver interface {
 
 type RebaseStatusResolver interface {
 	ID() graphql.ID
-	IsRebasing() bool
-	ConflictingFiles() ([]ConflictingFileResolver, error)
+	IsRebasing(context.Context) (bool, error)
+	ConflictingFiles(context.Context) ([]ConflictingFileResolver, error)
 }
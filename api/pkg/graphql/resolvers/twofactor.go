@@ -0,0 +1,32 @@
+package resolvers
+
+import (
+	"context"
+)
+
+type TwoFactorRootResolver interface {
+	TwoFactor(context.Context) (TwoFactorResolver, error)
+
+	EnrollTwoFactor(context.Context) (TwoFactorEnrollmentResolver, error)
+	ConfirmTwoFactor(context.Context, TwoFactorCodeArgs) ([]string, error)
+	DisableTwoFactor(context.Context, TwoFactorCodeArgs) (TwoFactorResolver, error)
+	RegenerateTwoFactorRecoveryCodes(context.Context, TwoFactorCodeArgs) ([]string, error)
+}
+
+type TwoFactorCodeArgs struct {
+	Input TwoFactorCodeInput
+}
+
+type TwoFactorCodeInput struct {
+	Code string
+}
+
+type TwoFactorResolver interface {
+	Enabled() bool
+	RecoveryCodesLeft() int32
+}
+
+type TwoFactorEnrollmentResolver interface {
+	Secret() string
+	URI() string
+}
@@ -58,6 +58,7 @@ type CreateWorkspaceInput struct {
 	CodebaseID              graphql.ID
 	OnTopOfChange           *graphql.ID
 	OnTopOfChangeWithRevert *graphql.ID
+	OnTopOfWorkspace        *graphql.ID
 }
 
 type RemovePatchesArgs struct {
@@ -82,6 +83,31 @@ type SetWorkspaceSnapshotInput struct {
 	SnapshotID  graphql.ID
 }
 
+type ResolveWorkspaceConflictsArgs struct {
+	Input ResolveWorkspaceConflictsInput
+}
+
+type ResolveWorkspaceConflictsInput struct {
+	WorkspaceID graphql.ID
+	Files       []ResolveConflictingFileInput
+}
+
+type ResolveConflictingFileInput struct {
+	Path     string
+	Version  string
+	Hunks    *[]string
+	Contents *string
+}
+
+type CherryPickChangeArgs struct {
+	Input CherryPickChangeInput
+}
+
+type CherryPickChangeInput struct {
+	WorkspaceID graphql.ID
+	ChangeID    graphql.ID
+}
+
 type WorkspaceRootResolver interface {
 	// internal
 	InternalWorkspace(*workspaces.Workspace) WorkspaceResolver
@@ -97,6 +123,8 @@ type WorkspaceRootResolver interface {
 	ExtractWorkspace(ctx context.Context, args ExtractWorkspaceArgs) (WorkspaceResolver, error)
 	RemovePatches(context.Context, RemovePatchesArgs) (WorkspaceResolver, error)
 	SetWorkspaceSnapshot(context.Context, SetWorkspaceSnapshotArgs) (WorkspaceResolver, error)
+	ResolveWorkspaceConflicts(context.Context, ResolveWorkspaceConflictsArgs) (WorkspaceResolver, error)
+	CherryPickChange(context.Context, CherryPickChangeArgs) (WorkspaceResolver, error)
 
 	// Subscriptions
 	UpdatedWorkspace(ctx context.Context, args UpdatedWorkspaceArgs) (<-chan WorkspaceResolver, error)
@@ -124,21 +152,48 @@ type WorkspaceResolver interface {
 	HeadChange(ctx context.Context) (ChangeResolver, error)
 	Activity(ctx context.Context, args ActivityArgs) ([]ActivityResolver, error)
 	Reviews(ctx context.Context) ([]ReviewResolver, error)
+	MissingCodeOwnerApprovals(ctx context.Context) ([]CodeOwnersResolver, error)
 	Presence(ctx context.Context) ([]PresenceResolver, error)
 	Suggestions(context.Context) ([]SuggestionResolver, error)
 	Statuses(context.Context) ([]WorkspaceStatusResolver, error)
+	RequiredStatusChecks(context.Context) ([]RequiredStatusCheckResolver, error)
+	StatusChecksReason(context.Context) (*string, error)
 	Watchers(context.Context) ([]WorkspaceWatcherResolver, error)
 	Suggestion(context.Context) (SuggestionResolver, error)
 	SuggestingViews() []ViewResolver
 	DiffsCount(context.Context) *int32
 	Diffs(context.Context) ([]FileDiffResolver, error)
 	Change(context.Context) (ChangeResolver, error)
+	ParentWorkspace(context.Context) (WorkspaceResolver, error)
+	ChildWorkspaces(context.Context) ([]WorkspaceResolver, error)
 	RebaseStatus(context.Context) (RebaseStatusResolver, error)
 	DownloadTarGz(context.Context, DownloadArchiveArgs) (ContentsDownloadUrlResolver, error)
 	DownloadZip(context.Context, DownloadArchiveArgs) (ContentsDownloadUrlResolver, error)
 	Snapshot(context.Context) (SnapshotResolver, error)
 }
 
+type RequiredStatusCheckResolver interface {
+	Name() string
+	State() (RequiredStatusCheckState, error)
+	Statuses() []WorkspaceStatusResolver
+}
+
+type RequiredStatusCheckState string
+
+const (
+	RequiredStatusCheckStateUndefined RequiredStatusCheckState = ""
+	RequiredStatusCheckStatePassed    RequiredStatusCheckState = "Passed"
+	RequiredStatusCheckStatePending   RequiredStatusCheckState = "Pending"
+	RequiredStatusCheckStateFailing   RequiredStatusCheckState = "Failing"
+	RequiredStatusCheckStateMissing   RequiredStatusCheckState = "Missing"
+)
+
+type CodeOwnersResolver interface {
+	ID() string
+	Principals() []string
+	Resources() []string
+}
+
 type DownloadArchiveArgs struct {
 	Input *DownloadArchiveInput
 }
@@ -11,6 +11,7 @@ import (
 	service_licenses "getsturdy.com/api/pkg/licenses/enterprise/cloud/service"
 	service_validations "getsturdy.com/api/pkg/licenses/enterprise/cloud/validations/service"
 	routes_v3_logger "getsturdy.com/api/pkg/logger/enterprise/cloud/routes"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	routes_v3_user "getsturdy.com/api/pkg/users/enterprise/cloud/routes"
 	service_user "getsturdy.com/api/pkg/users/enterprise/cloud/service"
 
@@ -29,10 +30,11 @@ func ProvideHandler(
 	serviceStatistics *service_statistics.Service,
 	sentryClient *sentry.Client,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 	userService *service_user.Service,
 ) *gin.Engine {
 	auth := enterpriseEngine.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService))
 	auth.POST("/v3/users/verify-email", routes_v3_user.SendEmailVerification(logger, userService)) // Used by the web (2021-11-14)
 
 	publ := enterpriseEngine.Group("")
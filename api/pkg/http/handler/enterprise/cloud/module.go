@@ -11,6 +11,7 @@ import (
 	service_licenses "getsturdy.com/api/pkg/licenses/enterprise/cloud/service"
 	service_validations "getsturdy.com/api/pkg/licenses/enterprise/cloud/validations/service"
 	"getsturdy.com/api/pkg/logger"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	service_user "getsturdy.com/api/pkg/users/service/module"
 )
 
@@ -22,6 +23,7 @@ func Module(c *di.Container) {
 	c.Import(service_validations.Module)
 	c.Import(service_statistics.Module)
 	c.Import(service_jwt.Module)
+	c.Import(service_personaltokens.Module)
 	c.Import(service_user.Module)
 	c.Register(ProvideHandler, new(http.Handler))
 }
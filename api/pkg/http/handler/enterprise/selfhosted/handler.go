@@ -12,8 +12,10 @@ import (
 	routes_v3_ghapp "getsturdy.com/api/pkg/github/enterprise/routes"
 	service_github "getsturdy.com/api/pkg/github/enterprise/service"
 	webhooks_github "getsturdy.com/api/pkg/github/enterprise/webhooks"
+	service_gitlab_enterprise "getsturdy.com/api/pkg/gitlab/enterprise/service"
 	"getsturdy.com/api/pkg/http/handler"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	routes_remote "getsturdy.com/api/pkg/remote/enterprise/routes"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
 	routes_ci "getsturdy.com/api/pkg/statuses/enterprise/routes"
@@ -30,21 +32,24 @@ func ProvideHandler(
 	gitHubAppConfig *config.GitHubAppConfig,
 	statusesService *service_statuses.Service,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 	gitHubService *service_github.Service,
 	ciService *service_ci.Service,
 	serviceTokensService *service_servicetokens.Service,
 	enterpriseBuildkiteService *service_buildkite_enterprise.Service,
+	enterpriseGitLabService *service_gitlab_enterprise.Service,
 	ossEngine *handler.Engine,
 	gitHubWebhooksQueue *webhooks_github.Queue,
 	triggerSyncCodebaseWebhookHandler routes_remote.TriggerSyncCodebaseWebhookHandler,
 ) *Engine {
 	auth := ossEngine.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService))
 	auth.POST("/v3/github/oauth", routes_v3_ghapp.Oauth(logger, gitHubAppConfig, userRepo, gitHubUserRepo, gitHubService))
 
 	publ := ossEngine.Group("")
 	publ.POST("/v3/github/webhook", routes_v3_ghapp.Webhook(logger, gitHubWebhooksQueue))
-	publ.POST("/v3/statuses/webhook", routes_ci.WebhookHandler(logger, statusesService, ciService, serviceTokensService, enterpriseBuildkiteService))
+	publ.POST("/v3/statuses", routes_ci.SetStatus(logger, statusesService, ciService, serviceTokensService))
+	publ.POST("/v3/statuses/webhook", routes_ci.WebhookHandler(logger, statusesService, ciService, serviceTokensService, enterpriseBuildkiteService, enterpriseGitLabService))
 
 	// Using Any to give friendly error messages if sent a non-POST request
 	publ.Any("/v3/remotes/webhook/sync-codebase/:id", gin.HandlerFunc(triggerSyncCodebaseWebhookHandler))
@@ -6,9 +6,11 @@ import (
 	db_github "getsturdy.com/api/pkg/github/enterprise/db"
 	service_github "getsturdy.com/api/pkg/github/enterprise/service"
 	webhooks_github "getsturdy.com/api/pkg/github/enterprise/webhooks"
+	service_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/service"
 	"getsturdy.com/api/pkg/http/handler"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/logger"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	routes_remote "getsturdy.com/api/pkg/remote/enterprise/routes"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
 	service_statuses "getsturdy.com/api/pkg/statuses/service"
@@ -21,9 +23,11 @@ func Module(c *di.Container) {
 	c.Import(db_github.Module)
 	c.Import(service_statuses.Module)
 	c.Import(service_jwt.Module)
+	c.Import(service_personaltokens.Module)
 	c.Import(service_github.Module)
 	c.Import(service_servicetokens.Module)
 	c.Import(service_buildkite.Module)
+	c.Import(service_gitlab.Module)
 	c.Import(handler.Module)
 	c.Import(webhooks_github.Module)
 	c.Import(routes_remote.Module)
@@ -7,9 +7,12 @@ import (
 	"time"
 
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	routes_audit "getsturdy.com/api/pkg/audit/routes"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	authz "getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	routes_blobs "getsturdy.com/api/pkg/blobs/routes"
+	routes_ci_runner "getsturdy.com/api/pkg/ci/runner/routes"
 	service_blobs "getsturdy.com/api/pkg/blobs/service"
 	db_change "getsturdy.com/api/pkg/changes/db"
 	routes_v3_change "getsturdy.com/api/pkg/changes/routes"
@@ -25,18 +28,27 @@ import (
 	"getsturdy.com/api/pkg/http/configuration"
 	"getsturdy.com/api/pkg/ip"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
 	"getsturdy.com/api/pkg/metrics/ginprometheus"
+	configuration_mutagen "getsturdy.com/api/pkg/mutagen/configuration"
 	db_mutagen "getsturdy.com/api/pkg/mutagen/db"
 	routes_v3_mutagen "getsturdy.com/api/pkg/mutagen/routes"
 	db_newsletter "getsturdy.com/api/pkg/newsletter/db"
 	routes_v3_newsletter "getsturdy.com/api/pkg/newsletter/routes"
+	routes_oidc "getsturdy.com/api/pkg/oidc/routes"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	db_pki "getsturdy.com/api/pkg/pki/db"
 	routes_v3_pki "getsturdy.com/api/pkg/pki/routes"
 	service_presence "getsturdy.com/api/pkg/presence/service"
+	routes_scim "getsturdy.com/api/pkg/scim/routes"
+	service_scim "getsturdy.com/api/pkg/scim/service"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
 	service_suggestion "getsturdy.com/api/pkg/suggestions/service"
 	routes_v3_sync "getsturdy.com/api/pkg/sync/routes"
 	service_sync "getsturdy.com/api/pkg/sync/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users/avatars/uploader"
 	db_user "getsturdy.com/api/pkg/users/db"
 	routes_v3_user "getsturdy.com/api/pkg/users/routes"
@@ -73,6 +85,7 @@ type Engine gin.Engine
 func ProvideHandler(
 	logger *zap.Logger,
 	config *configuration.Configuration,
+	mutagenConfig *configuration_mutagen.Configuration,
 	userRepo db_user.Repository,
 	analyticsService *service_analytics.Service,
 	waitingListRepo waitinglist.WaitingListRepository,
@@ -105,6 +118,13 @@ func ProvideHandler(
 	uploader uploader.Uploader,
 	viewService *service_view.Service,
 	getFileRoute routes_file.GetFileRoute,
+	oidcService *service_oidc.Service,
+	scimService *service_scim.Service,
+	ldapService *service_ldap.Service,
+	personalTokensService *service_personaltokens.Service,
+	organizationService *service_organization.Service,
+	auditService *service_audit.Service,
+	twoFactorService *service_twofactor.Service,
 ) *Engine {
 	logger = logger.With(zap.String("component", "http"))
 	allowOrigins := []string{
@@ -144,7 +164,7 @@ func ProvideHandler(
 	ginprom := ginprometheus.NewPrometheus("gin", logger)
 	ginprom.ReqCntURLLabelMappingFn = metricsMapper
 	ginprom.Use(r)
-	graphql := r.Group("/graphql", sturdygrapql.CorsMiddleware(allowOrigins), authz.GinMiddleware(logger, jwtService))
+	graphql := r.Group("/graphql", sturdygrapql.CorsMiddleware(allowOrigins), authz.GinMiddleware(logger, jwtService, personalTokensService))
 	graphql.OPTIONS("", func(c *gin.Context) { c.Status(http.StatusOK) })
 	graphql.OPTIONS("ws", func(c *gin.Context) { c.Status(http.StatusOK) })
 	graphql.POST("", grapqhlResolver.HttpHandler())
@@ -154,10 +174,14 @@ func ProvideHandler(
 	publ := r.Group("")
 	// Private endpoints, requires a valid auth cookie
 	auth := r.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
-	publ.POST("/v3/auth", routes_v3_user.Login(logger, userService, analyticsService, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService))
+	publ.POST("/v3/auth", routes_v3_user.Login(logger, userService, analyticsService, jwtService, twoFactorService))
+	publ.POST("/v3/auth/two-factor", routes_v3_user.LoginTwoFactor(logger, userService, analyticsService, jwtService, twoFactorService))
 	publ.POST("/v3/users", routes_v3_user.Signup(logger, userService, jwtService, analyticsService))
 	publ.POST("/v3/auth/destroy", routes_v3_user.AuthDestroy)
+	publ.POST("/v3/auth/ldap", routes_v3_user.LoginLDAP(logger, ldapService, analyticsService, jwtService, twoFactorService))
+	publ.GET("/v3/auth/oidc", routes_oidc.Login(logger, oidcService))
+	publ.GET("/v3/auth/oidc/callback", routes_oidc.Callback(logger, oidcService, jwtService))
 	auth.POST("/v3/auth/client-token", routes_v3_user.ClientToken(userRepo, jwtService))
 	auth.POST("/v3/auth/renew-token", routes_v3_user.RenewToken(logger, userRepo, jwtService))
 	auth.POST("/v3/user/update-avatar", routes_v3_user.UpdateAvatar(logger, userRepo, uploader))                                                        // Used by the web (2021-10-04)
@@ -186,16 +210,21 @@ func ProvideHandler(
 	publ.POST("/v3/instant-integration", instantintegration.Insert(logger, analyticsService, instantIntegrationInterestRepo))                                                    // Used by the web (2021-10-27)
 	auth.POST("/v3/pki/add-public-key", routes_v3_pki.AddPublicKey(userPublicKeyRepo))                                                                                           // Used by the command line client
 	publ.POST("/v3/pki/verify", routes_v3_pki.Verify(userPublicKeyRepo))                                                                                                         // Used by the command line client
-	publ.POST("/v3/mutagen/validate-view", routes_v3_mutagen.ValidateView(logger, viewRepo, analyticsService, eventSenderV2))                                                    // Called from server-side mutagen
-	publ.POST("/v3/mutagen/sync-transitions", routes_v3_mutagen.SyncTransitions(logger, snapshotterQueue, viewRepo, gcQueue, presenceService, suggestionService, eventSenderV2)) // Called from server-side mutagen
-	publ.GET("/v3/mutagen/views/:id/allows", routes_v3_mutagen.ListAllows(logger, viewRepo, authService))                                                                        // Called form server-side mutagen
-	publ.POST("/v3/mutagen/update-status", routes_v3_mutagen.UpdateStatus(logger, viewStatusRepo, viewRepo, eventSenderV2))                                                      // Called from client-side mutagen
+	// Called from server-side mutagen, signed by the ssh server
+	mutagenServer := r.Group("/v3/mutagen", routes_v3_mutagen.ServerAuth(logger, mutagenConfig))
+	mutagenServer.POST("/validate-view", routes_v3_mutagen.ValidateView(logger, viewRepo, userRepo, analyticsService, eventSenderV2))
+	mutagenServer.POST("/sync-transitions", routes_v3_mutagen.SyncTransitions(logger, snapshotterQueue, viewRepo, gcQueue, presenceService, suggestionService, eventSenderV2))
+	mutagenServer.GET("/views/:id/allows", routes_v3_mutagen.ListAllows(logger, viewRepo, authService))
+	auth.POST("/v3/mutagen/update-status", routes_v3_mutagen.UpdateStatus(logger, viewStatusRepo, viewRepo, eventSenderV2))                                                      // Called from client-side mutagen
 	auth.GET("/v3/mutagen/get-view/:id", routes_v3_mutagen.GetView(logger, viewRepo, codebaseUserRepo, codebaseRepo))                                                            // Called from client-side sturdy-cli
 	publ.POST("/v3/unsubscribe", routes_v3_newsletter.Unsubscribe(logger, userRepo, notificationSettingsRepo))
 
 	auth.GET("/v3/file", gin.HandlerFunc(getFileRoute))
+	auth.GET("/v3/organizations/:id/audit-log", routes_audit.Export(logger, organizationService, authService, auditService))
+	auth.GET("/v3/codebases/:id/ci-logs/:key", routes_ci_runner.Log(logger, codebaseService, authService, blobsService))
 
 	routes_blobs.Register(publ.Group("/v3/blobs"), logger, blobsService)
+	routes_scim.Register(publ.Group("/scim/v2"), logger, scimService)
 	return (*Engine)(r)
 }
 
@@ -4,6 +4,7 @@ import (
 	sender_activity "getsturdy.com/api/pkg/activity/sender"
 	service_activity "getsturdy.com/api/pkg/activity/service"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	service_blobs "getsturdy.com/api/pkg/blobs/service"
 	db_changes "getsturdy.com/api/pkg/changes/db"
@@ -15,13 +16,19 @@ import (
 	routes_file "getsturdy.com/api/pkg/file/routes"
 	worker_gc "getsturdy.com/api/pkg/gc/worker"
 	"getsturdy.com/api/pkg/graphql"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
 	"getsturdy.com/api/pkg/logger"
 	db_mutagen "getsturdy.com/api/pkg/mutagen/db"
 	service_notifications "getsturdy.com/api/pkg/notification/service"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	db_pki "getsturdy.com/api/pkg/pki/db"
 	service_presence "getsturdy.com/api/pkg/presence/service"
+	service_scim "getsturdy.com/api/pkg/scim/service"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
 	service_sync "getsturdy.com/api/pkg/sync/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	uploader_avatars "getsturdy.com/api/pkg/users/avatars/uploader"
 	db_users "getsturdy.com/api/pkg/users/db"
 	service_users "getsturdy.com/api/pkg/users/service/module"
@@ -59,6 +66,13 @@ func Module(c *di.Container) {
 	c.Import(service_sync.Module)
 	c.Import(service_codebases.Module)
 	c.Import(service_auth.Module)
+	c.Import(service_oidc.Module)
+	c.Import(service_scim.Module)
+	c.Import(service_ldap.Module)
+	c.Import(service_personaltokens.Module)
+	c.Import(service_organization.Module)
+	c.Import(service_audit.Module)
+	c.Import(service_twofactor.Module)
 	c.Import(service_blobs.Module)
 	c.Import(uploader_avatars.Module)
 	c.Import(routes_file.Module)
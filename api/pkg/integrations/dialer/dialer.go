@@ -0,0 +1,90 @@
+// Package dialer provides an http client for requests to user supplied urls, such as webhooks and CI instances, that
+// can't be used to reach the internal network.
+package dialer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("requests can not be sent to loopback, link-local or private addresses")
+
+// sharedAddressSpace is 100.64.0.0/10, which is used for carrier-grade NAT and by some cloud providers.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func forbidden(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// Control rejects connections to forbidden addresses. It runs after the hostname has been resolved, for every
+// connection, so that a hostname that resolves to a different address later on, or a redirect, can't be used to reach
+// the internal network or the instance metadata service.
+func Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+	if forbidden(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// NewClient returns a client that refuses to connect to forbidden addresses, unless allowPrivate is set.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = Control
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// no proxy, the addresses that are dialed are checked, and a proxy would make the request on our behalf
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   timeout,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// ValidateURL returns ErrForbiddenAddress if the host of the url resolves to a forbidden address. Connections are
+// checked when they are made as well, this is to give early feedback when the url is saved.
+func ValidateURL(ctx context.Context, u *url.URL) error {
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if forbidden(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if forbidden(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
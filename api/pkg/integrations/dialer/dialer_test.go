@@ -0,0 +1,49 @@
+package dialer
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControl(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1:80":       false,
+		"[::1]:80":           false,
+		"169.254.169.254:80": false,
+		"[fe80::1]:80":       false,
+		"10.0.0.1:80":        false,
+		"172.16.0.1:443":     false,
+		"192.168.1.1:443":    false,
+		"100.64.0.1:443":     false,
+		"0.0.0.0:80":         false,
+		"[fd00::1]:80":       false,
+		"224.0.0.1:80":       false,
+		"93.184.216.34:443":  true,
+		"[2606:4700::1]:443": true,
+	}
+	for address, allowed := range cases {
+		err := Control("tcp", address, nil)
+		if allowed {
+			assert.NoError(t, err, address)
+		} else {
+			assert.True(t, errors.Is(err, ErrForbiddenAddress), address)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, rawURL := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "https://[::1]/"} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.True(t, errors.Is(ValidateURL(context.Background(), u), ErrForbiddenAddress), rawURL)
+	}
+
+	u, err := url.Parse("https://93.184.216.34/hook")
+	require.NoError(t, err)
+	assert.NoError(t, ValidateURL(context.Background(), u))
+}
@@ -6,7 +6,9 @@ import (
 	service_change "getsturdy.com/api/pkg/changes/service"
 	service_ci "getsturdy.com/api/pkg/ci/service"
 	"getsturdy.com/api/pkg/di"
+	graphql_gitlab "getsturdy.com/api/pkg/gitlab/graphql/module"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	graphql_webhook "getsturdy.com/api/pkg/integrations/webhook/graphql"
 	graphql_statuses "getsturdy.com/api/pkg/statuses/graphql/module"
 	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
 )
@@ -18,6 +20,8 @@ func Module(c *di.Container) {
 	c.Import(service_workspaces.Module)
 	c.Import(graphql_statuses.Module)
 	c.Import(graphql_buildkite.Module)
+	c.Import(graphql_webhook.Module)
+	c.Import(graphql_gitlab.Module)
 	c.Register(NewRootResolver)
 
 	// populate cyclic resolver
@@ -49,6 +49,12 @@ func (ir *instantIntegrationProvider) Provider() (resolvers.InstantIntegrationPr
 	switch ir.integration.Provider {
 	case providers.ProviderNameBuildkite:
 		return resolvers.InstantIntegrationProviderBuildkite, nil
+	case providers.ProviderNameWebhook:
+		return resolvers.InstantIntegrationProviderWebhook, nil
+	case providers.ProviderNameGitLab:
+		return resolvers.InstantIntegrationProviderGitLab, nil
+	case providers.ProviderNameRunner:
+		return resolvers.InstantIntegrationProviderRunner, nil
 	default:
 		return resolvers.InstantIntegrationProviderUndefined, fmt.Errorf("invalid provider: %s", ir.integration.Provider)
 	}
@@ -61,6 +67,27 @@ func (ir *instantIntegrationProvider) ToBuildkiteIntegration() (resolvers.Buildk
 	return &buildkiteProviderResolver{ir}, true
 }
 
+func (ir *instantIntegrationProvider) ToWebhookIntegration() (resolvers.WebhookIntegration, bool) {
+	if ir.integration.Provider != providers.ProviderNameWebhook {
+		return nil, false
+	}
+	return &webhookProviderResolver{ir}, true
+}
+
+func (ir *instantIntegrationProvider) ToGitLabIntegration() (resolvers.GitLabIntegration, bool) {
+	if ir.integration.Provider != providers.ProviderNameGitLab {
+		return nil, false
+	}
+	return &gitLabProviderResolver{ir}, true
+}
+
+func (ir *instantIntegrationProvider) ToRunnerIntegration() (resolvers.RunnerIntegration, bool) {
+	if ir.integration.Provider != providers.ProviderNameRunner {
+		return nil, false
+	}
+	return ir, true
+}
+
 type buildkiteProviderResolver struct {
 	*instantIntegrationProvider
 }
@@ -68,3 +95,23 @@ type buildkiteProviderResolver struct {
 func (br *buildkiteProviderResolver) Configuration(ctx context.Context) (resolvers.BuildkiteConfigurationResolver, error) {
 	return br.root.buildkiteRootResolver.InternalBuildkiteConfigurationByIntegrationID(ctx, br.integration.ID)
 }
+
+type webhookProviderResolver struct {
+	*instantIntegrationProvider
+}
+
+func (wr *webhookProviderResolver) Configuration(ctx context.Context) (resolvers.WebhookConfigurationResolver, error) {
+	return wr.root.webhookRootResolver.InternalWebhookConfigurationByIntegrationID(ctx, wr.integration.ID)
+}
+
+func (wr *webhookProviderResolver) Deliveries(ctx context.Context, args resolvers.WebhookDeliveriesArgs) ([]resolvers.WebhookDeliveryResolver, error) {
+	return wr.root.webhookRootResolver.InternalWebhookDeliveriesByIntegrationID(ctx, wr.integration.ID, args)
+}
+
+type gitLabProviderResolver struct {
+	*instantIntegrationProvider
+}
+
+func (gr *gitLabProviderResolver) Configuration(ctx context.Context) (resolvers.GitLabConfigurationResolver, error) {
+	return gr.root.gitLabRootResolver.InternalGitLabConfigurationByIntegrationID(ctx, gr.integration.ID)
+}
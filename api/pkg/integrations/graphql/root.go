@@ -23,6 +23,8 @@ type rootResolver struct {
 	workspaceService *service_workspaces.Service
 
 	buildkiteRootResolver resolvers.BuildkiteInstantIntegrationRootResolver
+	webhookRootResolver   resolvers.WebhookInstantIntegrationRootResolver
+	gitLabRootResolver    resolvers.GitLabInstantIntegrationRootResolver
 	statusesRootResolver  resolvers.StatusesRootResolver
 }
 
@@ -33,6 +35,8 @@ func NewRootResolver(
 	workspaceService *service_workspaces.Service,
 
 	buildkiteRootResolver resolvers.BuildkiteInstantIntegrationRootResolver,
+	webhookRootResolver resolvers.WebhookInstantIntegrationRootResolver,
+	gitLabRootResolver resolvers.GitLabInstantIntegrationRootResolver,
 	statusesRootResolver resolvers.StatusesRootResolver,
 ) resolvers.IntegrationRootResolver {
 	return &rootResolver{
@@ -42,6 +46,8 @@ func NewRootResolver(
 		workspaceService: workspaceService,
 
 		buildkiteRootResolver: buildkiteRootResolver,
+		webhookRootResolver:   webhookRootResolver,
+		gitLabRootResolver:    gitLabRootResolver,
 		statusesRootResolver:  statusesRootResolver,
 	}
 }
@@ -129,7 +135,7 @@ func (r *rootResolver) DeleteIntegration(ctx context.Context, args resolvers.Del
 		return nil, gqlerrors.Error(err)
 	}
 
-	if err := r.authService.CanWrite(ctx, &codebases.Codebase{ID: cfg.CodebaseID}); err != nil {
+	if err := r.authService.CanAdmin(ctx, &codebases.Codebase{ID: cfg.CodebaseID}); err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -144,6 +150,12 @@ func convertProvider(in resolvers.InstantIntegrationProviderType) (providers.Pro
 	switch in {
 	case resolvers.InstantIntegrationProviderBuildkite:
 		return providers.ProviderNameBuildkite, nil
+	case resolvers.InstantIntegrationProviderWebhook:
+		return providers.ProviderNameWebhook, nil
+	case resolvers.InstantIntegrationProviderGitLab:
+		return providers.ProviderNameGitLab, nil
+	case resolvers.InstantIntegrationProviderRunner:
+		return providers.ProviderNameRunner, nil
 	default:
 		return providers.ProviderNameUndefined, fmt.Errorf("invalid provider: %s", in)
 	}
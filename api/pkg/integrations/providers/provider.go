@@ -13,4 +13,7 @@ const (
 	ProviderNameUndefined ProviderName = ""
 	ProviderNameBuildkite ProviderName = "buildkite"
 	ProviderNameGithub    ProviderName = "github"
+	ProviderNameWebhook   ProviderName = "webhook"
+	ProviderNameGitLab    ProviderName = "gitlab"
+	ProviderNameRunner    ProviderName = "runner"
 )
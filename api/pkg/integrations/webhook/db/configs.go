@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/integrations/webhook"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ ConfigRepository = &configRepository{}
+
+type configRepository struct {
+	db *sqlx.DB
+}
+
+func NewConfigRepository(db *sqlx.DB) ConfigRepository {
+	return &configRepository{db: db}
+}
+
+func (r *configRepository) Create(ctx context.Context, cfg *webhook.Config) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO ci_configurations_webhook
+		(id, codebase_id, integration_id, url, secret, created_at, updated_at)
+		VALUES
+		(:id, :codebase_id, :integration_id, :url, :secret, :created_at, :updated_at)`, cfg); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *configRepository) Update(ctx context.Context, cfg *webhook.Config) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE ci_configurations_webhook
+		SET url = :url,
+		    secret = :secret,
+		    updated_at = :updated_at
+		WHERE id = :id`, cfg); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *configRepository) GetByIntegrationID(ctx context.Context, integrationID string) (*webhook.Config, error) {
+	var cfg webhook.Config
+	if err := r.db.GetContext(ctx, &cfg, `SELECT id, codebase_id, integration_id, url, secret, created_at, updated_at
+		FROM ci_configurations_webhook
+		WHERE integration_id = $1`, integrationID); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/integrations/webhook"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ DeliveryRepository = &deliveryRepository{}
+
+type deliveryRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeliveryRepository(db *sqlx.DB) DeliveryRepository {
+	return &deliveryRepository{db: db}
+}
+
+func (r *deliveryRepository) Create(ctx context.Context, delivery *webhook.Delivery) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO ci_webhook_deliveries
+		(id, integration_id, codebase_id, event, commit_sha, attempts, status_code, error, created_at, finished_at)
+		VALUES
+		(:id, :integration_id, :codebase_id, :event, :commit_sha, :attempts, :status_code, :error, :created_at, :finished_at)`, delivery); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryRepository) Update(ctx context.Context, delivery *webhook.Delivery) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE ci_webhook_deliveries
+		SET attempts = :attempts,
+		    status_code = :status_code,
+		    error = :error,
+		    finished_at = :finished_at
+		WHERE id = :id`, delivery); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *deliveryRepository) Get(ctx context.Context, id string) (*webhook.Delivery, error) {
+	var delivery webhook.Delivery
+	if err := r.db.GetContext(ctx, &delivery, `SELECT id, integration_id, codebase_id, event, commit_sha, attempts, status_code, error, created_at, finished_at
+		FROM ci_webhook_deliveries
+		WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *deliveryRepository) ListByIntegrationID(ctx context.Context, integrationID string, limit int) ([]*webhook.Delivery, error) {
+	var deliveries []*webhook.Delivery
+	if err := r.db.SelectContext(ctx, &deliveries, `SELECT id, integration_id, codebase_id, event, commit_sha, attempts, status_code, error, created_at, finished_at
+		FROM ci_webhook_deliveries
+		WHERE integration_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, integrationID, limit); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return deliveries, nil
+}
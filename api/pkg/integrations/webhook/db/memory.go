@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/integrations/webhook"
+)
+
+var _ ConfigRepository = &memoryConfigRepository{}
+
+type memoryConfigRepository struct {
+	mu      sync.RWMutex
+	configs []webhook.Config
+}
+
+func NewMemoryConfigRepository() ConfigRepository {
+	return &memoryConfigRepository{}
+}
+
+func (r *memoryConfigRepository) Create(_ context.Context, cfg *webhook.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs = append(r.configs, *cfg)
+	return nil
+}
+
+func (r *memoryConfigRepository) Update(_ context.Context, cfg *webhook.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, c := range r.configs {
+		if c.ID == cfg.ID {
+			r.configs[k] = *cfg
+		}
+	}
+	return nil
+}
+
+func (r *memoryConfigRepository) GetByIntegrationID(_ context.Context, integrationID string) (*webhook.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.configs {
+		if c.IntegrationID == integrationID {
+			return &c, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+var _ DeliveryRepository = &memoryDeliveryRepository{}
+
+type memoryDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries []webhook.Delivery
+}
+
+func NewMemoryDeliveryRepository() DeliveryRepository {
+	return &memoryDeliveryRepository{}
+}
+
+func (r *memoryDeliveryRepository) Create(_ context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+func (r *memoryDeliveryRepository) Update(_ context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, d := range r.deliveries {
+		if d.ID == delivery.ID {
+			r.deliveries[k] = *delivery
+		}
+	}
+	return nil
+}
+
+func (r *memoryDeliveryRepository) Get(_ context.Context, id string) (*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryDeliveryRepository) ListByIntegrationID(_ context.Context, integrationID string, limit int) ([]*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var deliveries []*webhook.Delivery
+	for _, d := range r.deliveries {
+		if d.IntegrationID == integrationID {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
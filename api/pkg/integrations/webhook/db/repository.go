@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/integrations/webhook"
+)
+
+type ConfigRepository interface {
+	Create(context.Context, *webhook.Config) error
+	Update(context.Context, *webhook.Config) error
+	GetByIntegrationID(ctx context.Context, integrationID string) (*webhook.Config, error)
+}
+
+type DeliveryRepository interface {
+	Create(context.Context, *webhook.Delivery) error
+	Update(context.Context, *webhook.Delivery) error
+	Get(ctx context.Context, id string) (*webhook.Delivery, error)
+	// ListByIntegrationID returns the latest deliveries of the integration, newest first.
+	ListByIntegrationID(ctx context.Context, integrationID string, limit int) ([]*webhook.Delivery, error)
+}
@@ -0,0 +1,23 @@
+package graphql
+
+import (
+	"getsturdy.com/api/pkg/integrations/webhook"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type configurationResolver struct {
+	cfg *webhook.Config
+}
+
+func (r *configurationResolver) ID() graphql.ID {
+	return graphql.ID(r.cfg.ID)
+}
+
+func (r *configurationResolver) URL() string {
+	return r.cfg.URL
+}
+
+func (r *configurationResolver) Secret() string {
+	return r.cfg.Secret
+}
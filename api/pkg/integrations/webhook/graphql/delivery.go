@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"getsturdy.com/api/pkg/integrations/webhook"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type deliveryResolver struct {
+	delivery *webhook.Delivery
+}
+
+func (r *deliveryResolver) ID() graphql.ID {
+	return graphql.ID(r.delivery.ID)
+}
+
+func (r *deliveryResolver) Event() string {
+	return string(r.delivery.Event)
+}
+
+func (r *deliveryResolver) CommitSHA() string {
+	return r.delivery.CommitSHA
+}
+
+func (r *deliveryResolver) Attempts() int32 {
+	return int32(r.delivery.Attempts)
+}
+
+func (r *deliveryResolver) StatusCode() *int32 {
+	if r.delivery.StatusCode == nil {
+		return nil
+	}
+	code := int32(*r.delivery.StatusCode)
+	return &code
+}
+
+func (r *deliveryResolver) Error() *string {
+	return r.delivery.Error
+}
+
+func (r *deliveryResolver) Succeeded() bool {
+	return r.delivery.Succeeded()
+}
+
+func (r *deliveryResolver) CreatedAt() int32 {
+	return int32(r.delivery.CreatedAt.Unix())
+}
+
+func (r *deliveryResolver) FinishedAt() *int32 {
+	if r.delivery.FinishedAt == nil {
+		return nil
+	}
+	ts := int32(r.delivery.FinishedAt.Unix())
+	return &ts
+}
@@ -0,0 +1,173 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_ci "getsturdy.com/api/pkg/ci/service"
+	"getsturdy.com/api/pkg/codebases"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/integrations"
+	"getsturdy.com/api/pkg/integrations/dialer"
+	"getsturdy.com/api/pkg/integrations/providers"
+	"getsturdy.com/api/pkg/integrations/webhook"
+	service_webhook "getsturdy.com/api/pkg/integrations/webhook/service"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveries = 20
+	maxDeliveries     = 100
+)
+
+type rootResolver struct {
+	authService                    *service_auth.Service
+	webhookService                 *service_webhook.Service
+	instantIntegrationService      *service_ci.Service
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver
+}
+
+func New(
+	authService *service_auth.Service,
+	webhookService *service_webhook.Service,
+	instantIntegrationService *service_ci.Service,
+	instantIntegrationRootResolver *resolvers.IntegrationRootResolver,
+) resolvers.WebhookInstantIntegrationRootResolver {
+	return &rootResolver{
+		authService:                    authService,
+		webhookService:                 webhookService,
+		instantIntegrationService:      instantIntegrationService,
+		instantIntegrationRootResolver: instantIntegrationRootResolver,
+	}
+}
+
+func (r *rootResolver) CreateOrUpdateWebhookIntegration(ctx context.Context, args resolvers.CreateOrUpdateWebhookIntegrationArgs) (resolvers.IntegrationResolver, error) {
+	codebaseID := codebases.ID(args.Input.CodebaseID)
+	if err := r.authService.CanAdmin(ctx, &codebases.Codebase{ID: codebaseID}); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	webhookURL := strings.TrimSpace(args.Input.URL)
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "url", "must be a http or https url")
+	}
+	switch err := r.webhookService.ValidateURL(ctx, u); {
+	case errors.Is(err, dialer.ErrForbiddenAddress):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "url", "must not be a loopback, link-local or private address")
+	case err != nil:
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "url", "could not be resolved")
+	}
+	if args.Input.Secret == "" {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "secret", "must not be empty")
+	}
+
+	if args.Input.IntegrationID == nil {
+		integration, err := r.createConfiguration(ctx, codebaseID, webhookURL, args.Input.Secret)
+		if err != nil {
+			return nil, gqlerrors.Error(fmt.Errorf("failed to create new configuration: %w", err))
+		}
+		return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+	}
+
+	integration, err := r.instantIntegrationService.GetByID(ctx, string(*args.Input.IntegrationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if integration.CodebaseID != codebaseID || integration.Provider != providers.ProviderNameWebhook {
+		return nil, gqlerrors.ErrNotFound
+	}
+
+	if err := r.updateConfiguration(ctx, integration, webhookURL, args.Input.Secret); err != nil {
+		return nil, gqlerrors.Error(fmt.Errorf("failed to update existing configuration: %w", err))
+	}
+	return (*r.instantIntegrationRootResolver).InternalIntegrationProvider(integration), nil
+}
+
+func (r *rootResolver) createConfiguration(ctx context.Context, codebaseID codebases.ID, webhookURL, secret string) (*integrations.Integration, error) {
+	integration := &integrations.Integration{
+		ID:           uuid.NewString(),
+		CodebaseID:   codebaseID,
+		Provider:     providers.ProviderNameWebhook,
+		ProviderType: providers.ProviderTypeBuild,
+		SeedFiles:    []string{},
+		CreatedAt:    time.Now(),
+	}
+	if err := r.instantIntegrationService.CreateIntegration(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	if err := r.webhookService.CreateIntegration(ctx, &webhook.Config{
+		ID:            uuid.NewString(),
+		CodebaseID:    codebaseID,
+		IntegrationID: integration.ID,
+		URL:           webhookURL,
+		Secret:        secret,
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create configuration: %w", err)
+	}
+
+	return integration, nil
+}
+
+func (r *rootResolver) updateConfiguration(ctx context.Context, integration *integrations.Integration, webhookURL, secret string) error {
+	cfg, err := r.webhookService.GetConfigurationByIntegrationID(ctx, integration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if cfg.URL == webhookURL && cfg.Secret == secret {
+		return nil
+	}
+
+	now := time.Now()
+	cfg.URL = webhookURL
+	cfg.Secret = secret
+	cfg.UpdatedAt = &now
+	if err := r.webhookService.UpdateIntegration(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+
+	integration.UpdatedAt = now
+	if err := r.instantIntegrationService.UpdateIntegration(ctx, integration); err != nil {
+		return fmt.Errorf("failed to update integration: %w", err)
+	}
+	return nil
+}
+
+func (r *rootResolver) InternalWebhookConfigurationByIntegrationID(ctx context.Context, integrationID string) (resolvers.WebhookConfigurationResolver, error) {
+	cfg, err := r.webhookService.GetConfigurationByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &configurationResolver{cfg: cfg}, nil
+}
+
+func (r *rootResolver) InternalWebhookDeliveriesByIntegrationID(ctx context.Context, integrationID string, args resolvers.WebhookDeliveriesArgs) ([]resolvers.WebhookDeliveryResolver, error) {
+	limit := defaultDeliveries
+	if args.First != nil {
+		limit = int(*args.First)
+	}
+	if limit <= 0 || limit > maxDeliveries {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "first", fmt.Sprintf("must be between 1 and %d", maxDeliveries))
+	}
+
+	deliveries, err := r.webhookService.ListDeliveries(ctx, integrationID, limit)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	rr := make([]resolvers.WebhookDeliveryResolver, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rr = append(rr, &deliveryResolver{delivery: delivery})
+	}
+	return rr, nil
+}
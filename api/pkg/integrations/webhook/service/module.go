@@ -0,0 +1,17 @@
+package service
+
+import (
+	configuration "getsturdy.com/api/pkg/configuration/module"
+	"getsturdy.com/api/pkg/di"
+	db_webhook "getsturdy.com/api/pkg/integrations/webhook/db"
+	"getsturdy.com/api/pkg/logger"
+	queue "getsturdy.com/api/pkg/queue/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(configuration.Module)
+	c.Import(db_webhook.Module)
+	c.Import(queue.Module)
+	c.Register(New)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"getsturdy.com/api/pkg/ci/service/configuration"
+	"getsturdy.com/api/pkg/integrations/dialer"
+	"getsturdy.com/api/pkg/integrations/webhook"
+	db_webhook "getsturdy.com/api/pkg/integrations/webhook/db"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+const (
+	requestTimeout = 10 * time.Second
+	userAgent      = "Sturdy-Webhook"
+)
+
+type Service struct {
+	logger       *zap.Logger
+	configRepo   db_webhook.ConfigRepository
+	deliveryRepo db_webhook.DeliveryRepository
+	queue        queue.Queue
+	client       *http.Client
+	allowPrivate bool
+
+	// backoff is how long to wait before each retry, a delivery is attempted len(backoff)+1 times
+	backoff []time.Duration
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *configuration.Configuration,
+	configRepo db_webhook.ConfigRepository,
+	deliveryRepo db_webhook.DeliveryRepository,
+	queue queue.Queue,
+) *Service {
+	return &Service{
+		logger:       logger.Named("ciWebhooks"),
+		configRepo:   configRepo,
+		deliveryRepo: deliveryRepo,
+		queue:        queue,
+		client:       dialer.NewClient(requestTimeout, cfg.AllowPrivateWebhooks),
+		allowPrivate: cfg.AllowPrivateWebhooks,
+		backoff:      []time.Duration{time.Second, 5 * time.Second},
+	}
+}
+
+func (s *Service) CreateIntegration(ctx context.Context, cfg *webhook.Config) error {
+	return s.configRepo.Create(ctx, cfg)
+}
+
+func (s *Service) UpdateIntegration(ctx context.Context, cfg *webhook.Config) error {
+	return s.configRepo.Update(ctx, cfg)
+}
+
+func (s *Service) GetConfigurationByIntegrationID(ctx context.Context, integrationID string) (*webhook.Config, error) {
+	return s.configRepo.GetByIntegrationID(ctx, integrationID)
+}
+
+// ListDeliveries returns the latest deliveries of the integration, newest first.
+func (s *Service) ListDeliveries(ctx context.Context, integrationID string, limit int) ([]*webhook.Delivery, error) {
+	return s.deliveryRepo.ListByIntegrationID(ctx, integrationID, limit)
+}
+
+// Enqueue records a delivery of the payload to the url of the integration, and publishes it to the queue. The
+// delivery is sent in the background by Deliver, so that a slow or unavailable ci system doesn't block the caller.
+func (s *Service) Enqueue(ctx context.Context, integrationID string, payload *webhook.Payload) (*webhook.Delivery, error) {
+	cfg, err := s.configRepo.GetByIntegrationID(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	delivery := &webhook.Delivery{
+		ID:            uuid.NewString(),
+		IntegrationID: cfg.IntegrationID,
+		CodebaseID:    cfg.CodebaseID,
+		Event:         payload.Event,
+		CommitSHA:     payload.CommitSHA,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	if err := s.queue.Publish(ctx, names.CIWebhookDeliveries, &webhook.Message{
+		DeliveryID: delivery.ID,
+		Payload:    payload,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to publish to queue: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Deliver sends the payload of an enqueued delivery to the url of the integration. Network errors, 429 and 5xx
+// responses are retried, other responses are final. The result is recorded on the delivery, and ErrDeliveryFailed is
+// returned if it did not succeed.
+func (s *Service) Deliver(ctx context.Context, msg *webhook.Message) (*webhook.Delivery, error) {
+	delivery, err := s.deliveryRepo.Get(ctx, msg.DeliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+	if delivery.FinishedAt != nil {
+		// the message has been delivered more than once by the queue
+		return delivery, nil
+	}
+
+	cfg, err := s.configRepo.GetByIntegrationID(ctx, delivery.IntegrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	body, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	logger := s.logger.With(
+		zap.String("integration_id", cfg.IntegrationID),
+		zap.String("delivery_id", delivery.ID),
+	)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			logger.Warn("webhook delivery failed, retrying", zap.Int("attempts", attempt), zap.Stringp("error", delivery.Error))
+			if err := sleep(ctx, s.backoff[attempt-1]); err != nil {
+				msg := err.Error()
+				delivery.Error = &msg
+				break
+			}
+		}
+
+		statusCode, err := s.send(ctx, cfg, delivery, body)
+		delivery.Attempts++
+		delivery.StatusCode, delivery.Error = nil, nil
+		if statusCode != 0 {
+			delivery.StatusCode = &statusCode
+		}
+		if err != nil {
+			msg := err.Error()
+			delivery.Error = &msg
+		}
+
+		if err == nil || errors.Is(err, dialer.ErrForbiddenAddress) || !retryable(statusCode) || attempt == len(s.backoff) {
+			break
+		}
+	}
+
+	now := time.Now()
+	delivery.FinishedAt = &now
+	// the delivery is recorded even if the request has been canceled
+	if err := s.deliveryRepo.Update(context.Background(), delivery); err != nil {
+		return nil, fmt.Errorf("failed to update delivery: %w", err)
+	}
+
+	if !delivery.Succeeded() {
+		logger.Warn("webhook delivery failed", zap.Int("attempts", delivery.Attempts), zap.Stringp("error", delivery.Error))
+		return delivery, fmt.Errorf("%w: %s", ErrDeliveryFailed, *delivery.Error)
+	}
+
+	return delivery, nil
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// send makes one attempt to deliver the body, and returns the status code of the response. The status code is 0 if
+// there was no response.
+func (s *Service) send(ctx context.Context, cfg *webhook.Config, delivery *webhook.Delivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(webhook.HeaderEvent, string(delivery.Event))
+	req.Header.Set(webhook.HeaderDelivery, delivery.ID)
+	req.Header.Set(webhook.HeaderSignature, webhook.Signature(cfg.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	// read some of the body, so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// ValidateURL returns dialer.ErrForbiddenAddress if the host of the url resolves to an address that webhooks can't be
+// sent to. Deliveries are checked when they are sent as well, this is to give early feedback when the integration is
+// saved.
+func (s *Service) ValidateURL(ctx context.Context, u *url.URL) error {
+	if s.allowPrivate {
+		return nil
+	}
+	return dialer.ValidateURL(ctx, u)
+}
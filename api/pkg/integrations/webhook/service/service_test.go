@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/ci/service/configuration"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/integrations/dialer"
+	"getsturdy.com/api/pkg/integrations/webhook"
+	db_webhook "getsturdy.com/api/pkg/integrations/webhook/db"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const secret = "secret"
+
+func setup(t *testing.T, handler http.HandlerFunc) (*Service, *webhook.Config) {
+	// the test server listens on 127.0.0.1
+	return setupWithConfiguration(t, &configuration.Configuration{AllowPrivateWebhooks: true}, handler)
+}
+
+func setupWithConfiguration(t *testing.T, ciCfg *configuration.Configuration, handler http.HandlerFunc) (*Service, *webhook.Config) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	svc := New(zap.NewNop(), ciCfg, db_webhook.NewMemoryConfigRepository(), db_webhook.NewMemoryDeliveryRepository(), queue.NewInMemory(zap.NewNop()))
+	svc.backoff = []time.Duration{time.Millisecond, time.Millisecond}
+
+	cfg := &webhook.Config{
+		ID:            uuid.NewString(),
+		CodebaseID:    codebases.ID(uuid.NewString()),
+		IntegrationID: uuid.NewString(),
+		URL:           server.URL,
+		Secret:        secret,
+		CreatedAt:     time.Now(),
+	}
+	require.NoError(t, svc.CreateIntegration(context.Background(), cfg))
+	return svc, cfg
+}
+
+// deliver enqueues the payload, and delivers the message that is published to the queue.
+func deliver(t *testing.T, svc *Service, cfg *webhook.Config, p *webhook.Payload) (*webhook.Delivery, error) {
+	enqueued, err := svc.Enqueue(context.Background(), cfg.IntegrationID, p)
+	require.NoError(t, err)
+	assert.Nil(t, enqueued.FinishedAt)
+	assert.Equal(t, 0, enqueued.Attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages := make(chan queue.Message)
+	go func() { _ = svc.queue.Subscribe(ctx, names.CIWebhookDeliveries, messages) }()
+
+	msg := &webhook.Message{}
+	require.NoError(t, (<-messages).As(msg))
+	assert.Equal(t, enqueued.ID, msg.DeliveryID)
+
+	return svc.Deliver(context.Background(), msg)
+}
+
+func payload(codebaseID codebases.ID) *webhook.Payload {
+	workspaceID := uuid.NewString()
+	return &webhook.Payload{
+		Event:       webhook.EventBuildRequested,
+		CodebaseID:  codebaseID,
+		WorkspaceID: &workspaceID,
+		Title:       "Workspace",
+		CommitSHA:   "abc123",
+		JWT:         "token",
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var received webhook.Payload
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, string(webhook.EventBuildRequested), r.Header.Get(webhook.HeaderEvent))
+		assert.NotEmpty(t, r.Header.Get(webhook.HeaderDelivery))
+
+		var timestamp int64
+		var signature string
+		_, err = fmt.Sscanf(strings.Replace(r.Header.Get(webhook.HeaderSignature), ",", " ", 1), "timestamp=%d signature=%s", &timestamp, &signature)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+		mac.Write(body)
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature)
+
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	sent := payload(cfg.CodebaseID)
+	delivery, err := deliver(t, svc, cfg, sent)
+	require.NoError(t, err)
+	assert.Equal(t, *sent, received)
+	assert.True(t, delivery.Succeeded())
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Nil(t, delivery.Error)
+	assert.NotNil(t, delivery.FinishedAt)
+
+	deliveries, err := svc.ListDeliveries(context.Background(), cfg.IntegrationID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, delivery.ID, deliveries[0].ID)
+	assert.Equal(t, "abc123", deliveries[0].CommitSHA)
+	assert.Equal(t, http.StatusNoContent, *deliveries[0].StatusCode)
+}
+
+func TestDeliver_retries(t *testing.T) {
+	var requests int32
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.True(t, delivery.Succeeded())
+}
+
+func TestDeliver_gives_up(t *testing.T) {
+	var requests int32
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	assert.True(t, errors.Is(err, ErrDeliveryFailed))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.StatusCode)
+
+	deliveries, err := svc.ListDeliveries(context.Background(), cfg.IntegrationID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Succeeded())
+	assert.NotNil(t, deliveries[0].Error)
+}
+
+func TestDeliver_client_errors_are_not_retried(t *testing.T) {
+	var requests int32
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	assert.True(t, errors.Is(err, ErrDeliveryFailed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDeliver_only_once(t *testing.T) {
+	var requests int32
+	svc, cfg := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	require.NoError(t, err)
+
+	// the queue delivers the same message again
+	again, err := svc.Deliver(context.Background(), &webhook.Message{DeliveryID: delivery.ID, Payload: payload(cfg.CodebaseID)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, 1, again.Attempts)
+}
+
+func TestDeliver_private_addresses_are_forbidden(t *testing.T) {
+	var requests int32
+	svc, cfg := setupWithConfiguration(t, &configuration.Configuration{}, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	assert.True(t, errors.Is(err, ErrDeliveryFailed))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	assert.Equal(t, 1, delivery.Attempts, "forbidden addresses are not retried")
+	assert.Contains(t, *delivery.Error, dialer.ErrForbiddenAddress.Error())
+}
+
+func TestDeliver_redirects_to_private_addresses_are_forbidden(t *testing.T) {
+	var requests int32
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(private.Close)
+
+	svc, cfg := setupWithConfiguration(t, &configuration.Configuration{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, private.URL, http.StatusTemporaryRedirect)
+	})
+	// the first server is reachable, every connection after it is checked
+	svc.client = dialer.NewClient(requestTimeout, false)
+	allowed := svc.client.Transport.(*http.Transport)
+	d := &net.Dialer{Control: func(network, address string, c syscall.RawConn) error {
+		if address == strings.TrimPrefix(cfg.URL, "http://") {
+			return nil
+		}
+		return dialer.Control(network, address, c)
+	}}
+	allowed.DialContext = d.DialContext
+
+	delivery, err := deliver(t, svc, cfg, payload(cfg.CodebaseID))
+	assert.True(t, errors.Is(err, ErrDeliveryFailed))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	assert.Contains(t, *delivery.Error, dialer.ErrForbiddenAddress.Error())
+}
+
+func TestValidateURL(t *testing.T) {
+	svc := New(zap.NewNop(), &configuration.Configuration{}, db_webhook.NewMemoryConfigRepository(), db_webhook.NewMemoryDeliveryRepository(), queue.NewInMemory(zap.NewNop()))
+
+	for _, rawURL := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "https://[::1]/"} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.True(t, errors.Is(svc.ValidateURL(context.Background(), u), dialer.ErrForbiddenAddress), rawURL)
+	}
+
+	u, err := url.Parse("https://93.184.216.34/hook")
+	require.NoError(t, err)
+	assert.NoError(t, svc.ValidateURL(context.Background(), u))
+
+	svc.allowPrivate = true
+	u, err = url.Parse("http://127.0.0.1:8080/hook")
+	require.NoError(t, err)
+	assert.NoError(t, svc.ValidateURL(context.Background(), u))
+}
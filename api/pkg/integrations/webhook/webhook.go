@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/snapshots"
+)
+
+// Config is the configuration of a webhook integration, builds are triggered by sending a request to URL.
+type Config struct {
+	ID            string       `db:"id"`
+	CodebaseID    codebases.ID `db:"codebase_id"`
+	IntegrationID string       `db:"integration_id"`
+	URL           string       `db:"url"`
+	// Secret is used to sign the payloads, so that the receiver can verify that they are sent by Sturdy.
+	Secret    string     `db:"secret"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+// Headers that are sent with every request.
+const (
+	HeaderEvent     = "X-Sturdy-Event"
+	HeaderDelivery  = "X-Sturdy-Delivery"
+	HeaderSignature = "X-Sturdy-Signature"
+)
+
+type Event string
+
+const (
+	EventBuildRequested Event = "build.requested"
+)
+
+// Payload is the body of a webhook request.
+type Payload struct {
+	Event       Event         `json:"event"`
+	CodebaseID  codebases.ID  `json:"codebase_id"`
+	WorkspaceID *string       `json:"workspace_id,omitempty"`
+	SnapshotID  *snapshots.ID `json:"snapshot_id,omitempty"`
+	ChangeID    *changes.ID   `json:"change_id,omitempty"`
+	Title       string        `json:"title"`
+	// GitURL is the url of the ci repository, it's authenticated with a service token of the codebase.
+	GitURL string `json:"git_url,omitempty"`
+	// CommitSHA is the commit to build in the ci repository. Statuses are reported for this commit.
+	CommitSHA string `json:"commit_sha"`
+	// JWT can be used to download the contents of the workspace or the change from the API.
+	JWT string `json:"jwt"`
+}
+
+// Message is a delivery on the queue, the payload is only kept on the queue until it has been delivered.
+type Message struct {
+	DeliveryID string   `json:"delivery_id"`
+	Payload    *Payload `json:"payload"`
+}
+
+// Delivery is a record of a payload that has been sent, the payload itself is not stored because it contains a
+// token.
+type Delivery struct {
+	ID            string       `db:"id"`
+	IntegrationID string       `db:"integration_id"`
+	CodebaseID    codebases.ID `db:"codebase_id"`
+	Event         Event        `db:"event"`
+	CommitSHA     string       `db:"commit_sha"`
+	Attempts      int          `db:"attempts"`
+	// StatusCode and Error are from the last attempt.
+	StatusCode *int      `db:"status_code"`
+	Error      *string   `db:"error"`
+	CreatedAt  time.Time `db:"created_at"`
+	// FinishedAt is nil while the delivery is queued, or being retried.
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+// Succeeded is true if the receiver responded with a 2xx status code.
+func (d *Delivery) Succeeded() bool {
+	return d.StatusCode != nil && *d.StatusCode >= 200 && *d.StatusCode < 300
+}
+
+// Signature returns the value of the signature header. Like Buildkite webhooks, it contains a timestamp and an
+// HMAC-SHA256 of the timestamp and the body, joined by a dot, with the secret as the key.
+//
+//	timestamp=1637075221,signature=dbdabe3596995f7bd1f39f50f135df4c48e4291f5368c0eb5c5a02664ae536e9
+func Signature(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	_, _ = mac.Write(body)
+	return fmt.Sprintf("timestamp=%d,signature=%x", timestamp.Unix(), mac.Sum(nil))
+}
@@ -0,0 +1,15 @@
+package worker
+
+import (
+	"getsturdy.com/api/pkg/di"
+	service_webhook "getsturdy.com/api/pkg/integrations/webhook/service"
+	"getsturdy.com/api/pkg/logger"
+	queue "getsturdy.com/api/pkg/queue/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(queue.Module)
+	c.Import(service_webhook.Module)
+	c.Register(New)
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/integrations/webhook"
+	service_webhook "getsturdy.com/api/pkg/integrations/webhook/service"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"go.uber.org/zap"
+)
+
+// Worker sends webhook deliveries that have been enqueued with the webhook service.
+type Worker struct {
+	logger *zap.Logger
+
+	queue queue.Queue
+	name  names.IncompleteQueueName
+
+	webhookService *service_webhook.Service
+}
+
+func New(logger *zap.Logger, queue queue.Queue, webhookService *service_webhook.Service) *Worker {
+	return &Worker{
+		logger:         logger.Named("ciWebhooksWorker"),
+		queue:          queue,
+		name:           names.CIWebhookDeliveries,
+		webhookService: webhookService,
+	}
+}
+
+// Start starts the worker.
+func (w *Worker) Start(ctx context.Context) error {
+	messages := make(chan queue.Message)
+	go func() {
+		for msg := range messages {
+			m := &webhook.Message{}
+			if err := msg.As(m); err != nil {
+				w.logger.Error("failed to decode message", zap.Error(err), zap.Any("message", msg))
+				continue
+			}
+
+			// deliveries are retried with a backoff, don't block the queue while they are sent
+			go w.deliver(ctx, m, msg)
+		}
+	}()
+
+	w.logger.Info("starting queue", zap.Stringer("queue_name", w.name))
+	if err := w.queue.Subscribe(ctx, w.name, messages); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	w.logger.Info("queue stopped", zap.Stringer("queue_name", w.name))
+
+	return nil
+}
+
+// deliver sends a delivery. The message is acked even if the delivery fails, the failure is recorded on the delivery.
+func (w *Worker) deliver(ctx context.Context, m *webhook.Message, msg queue.Message) {
+	logger := w.logger.With(zap.String("delivery_id", m.DeliveryID))
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Error("panic in delivery", zap.String("panic", fmt.Sprintf("%v", rec)))
+		}
+	}()
+
+	if _, err := w.webhookService.Deliver(ctx, m); err != nil {
+		logger.Warn("failed to deliver webhook", zap.Error(err))
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.Error("failed to ack message", zap.Error(err))
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/jwt/revocations"
+)
+
+var _ Repository = &cache{}
+
+// cacheTTL is how long revocations are cached for. Revocations made by other instances of the api take up to this
+// long to take effect.
+const cacheTTL = 10 * time.Second
+
+type cacheEntry struct {
+	revocation *revocations.Revocation // nil if the subject has no revocation
+	expiresAt  time.Time
+}
+
+// cache caches revocations in memory, so that verifying a token doesn't query the database every time.
+type cache struct {
+	db  Repository
+	ttl time.Duration
+
+	cache      map[string]cacheEntry
+	cacheGuard *sync.RWMutex
+}
+
+func NewCache(db Repository) *cache {
+	return &cache{
+		db:  db,
+		ttl: cacheTTL,
+
+		cache:      make(map[string]cacheEntry),
+		cacheGuard: &sync.RWMutex{},
+	}
+}
+
+func (c *cache) Upsert(ctx context.Context, revocation *revocations.Revocation) error {
+	if err := c.db.Upsert(ctx, revocation); err != nil {
+		return err
+	}
+	c.set(revocation.Subject, revocation)
+	return nil
+}
+
+func (c *cache) Get(ctx context.Context, subject string) (*revocations.Revocation, error) {
+	c.cacheGuard.RLock()
+	cached, foundInCache := c.cache[subject]
+	c.cacheGuard.RUnlock()
+
+	if foundInCache && time.Now().Before(cached.expiresAt) {
+		if cached.revocation == nil {
+			return nil, sql.ErrNoRows
+		}
+		return cached.revocation, nil
+	}
+
+	revocation, err := c.db.Get(ctx, subject)
+	switch {
+	case err == nil:
+		c.set(subject, revocation)
+		return revocation, nil
+	case errors.Is(err, sql.ErrNoRows):
+		c.set(subject, nil)
+		return nil, sql.ErrNoRows
+	default:
+		return nil, err
+	}
+}
+
+func (c *cache) set(subject string, revocation *revocations.Revocation) {
+	c.cacheGuard.Lock()
+	defer c.cacheGuard.Unlock()
+
+	// drop expired entries once in a while, so that the cache doesn't grow with every subject ever seen
+	if len(c.cache) >= 10_000 {
+		now := time.Now()
+		for s, e := range c.cache {
+			if now.After(e.expiresAt) {
+				delete(c.cache, s)
+			}
+		}
+	}
+
+	c.cache[subject] = cacheEntry{revocation: revocation, expiresAt: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"getsturdy.com/api/pkg/jwt/revocations"
+)
+
+type countingRepo struct {
+	Repository
+	gets int
+}
+
+func (r *countingRepo) Get(ctx context.Context, subject string) (*revocations.Revocation, error) {
+	r.gets++
+	return r.Repository.Get(ctx, subject)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepo{Repository: NewInMemory()}
+	c := NewCache(repo)
+
+	_, err := c.Get(ctx, "subject")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = c.Get(ctx, "subject")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, 1, repo.gets, "missing revocations are cached")
+
+	// revocations made through the cache are seen right away
+	revocation := &revocations.Revocation{Subject: "subject", RevokedAt: time.Now()}
+	require.NoError(t, c.Upsert(ctx, revocation))
+	got, err := c.Get(ctx, "subject")
+	require.NoError(t, err)
+	assert.Equal(t, revocation, got)
+	assert.Equal(t, 1, repo.gets)
+}
+
+func TestCache_expires(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepo{Repository: NewInMemory()}
+	c := NewCache(repo)
+	c.ttl = time.Millisecond
+
+	_, err := c.Get(ctx, "subject")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a revocation made by another instance
+	revocation := &revocations.Revocation{Subject: "subject", RevokedAt: time.Now()}
+	require.NoError(t, repo.Upsert(ctx, revocation))
+
+	time.Sleep(2 * time.Millisecond)
+
+	got, err := c.Get(ctx, "subject")
+	require.NoError(t, err)
+	assert.Equal(t, revocation, got)
+	assert.Equal(t, 2, repo.gets)
+}
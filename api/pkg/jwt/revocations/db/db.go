@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/jwt/revocations"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *database {
+	return &database{
+		db: db,
+	}
+}
+
+func (db *database) Upsert(ctx context.Context, revocation *revocations.Revocation) error {
+	if _, err := db.db.NamedExecContext(ctx, `
+	INSERT INTO jwt_revocations (
+		subject,
+		revoked_at
+	) VALUES (
+		:subject, :revoked_at
+	)
+	ON CONFLICT (subject) DO UPDATE SET revoked_at = EXCLUDED.revoked_at
+	`, revocation); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
+
+func (db *database) Get(ctx context.Context, subject string) (*revocations.Revocation, error) {
+	revocation := &revocations.Revocation{}
+	if err := db.db.GetContext(ctx, revocation, `
+	SELECT
+		subject, revoked_at
+	FROM
+		jwt_revocations
+	WHERE
+		subject = $1
+	`, subject); err != nil {
+		return nil, err
+	}
+	return revocation, nil
+}
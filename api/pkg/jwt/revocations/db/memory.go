@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"getsturdy.com/api/pkg/jwt/revocations"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	mu        sync.RWMutex
+	bySubject map[string]*revocations.Revocation
+}
+
+func NewInMemory() *memory {
+	return &memory{
+		bySubject: map[string]*revocations.Revocation{},
+	}
+}
+
+func (db *memory) Upsert(ctx context.Context, revocation *revocations.Revocation) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.bySubject[revocation.Subject] = revocation
+	return nil
+}
+
+func (db *memory) Get(ctx context.Context, subject string) (*revocations.Revocation, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	revocation, found := db.bySubject[subject]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return revocation, nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"getsturdy.com/api/pkg/db"
+	"getsturdy.com/api/pkg/di"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Register(func(db *sqlx.DB) Repository {
+		return New(db)
+	})
+}
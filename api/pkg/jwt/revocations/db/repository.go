@@ -0,0 +1,13 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/jwt/revocations"
+)
+
+type Repository interface {
+	// Upsert creates the revocation, or replaces the existing revocation of the subject.
+	Upsert(context.Context, *revocations.Revocation) error
+	Get(ctx context.Context, subject string) (*revocations.Revocation, error)
+}
@@ -0,0 +1,9 @@
+package revocations
+
+import "time"
+
+// Revocation revokes all tokens of the subject that were issued before RevokedAt.
+type Revocation struct {
+	Subject   string    `db:"subject"`
+	RevokedAt time.Time `db:"revoked_at"`
+}
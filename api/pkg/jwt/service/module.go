@@ -3,11 +3,13 @@ package service
 import (
 	"getsturdy.com/api/pkg/di"
 	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 	"getsturdy.com/api/pkg/logger"
 )
 
 func Module(c *di.Container) {
 	c.Import(logger.Module)
 	c.Import(db_keys.Module)
+	c.Import(db_revocations.Module)
 	c.Register(NewService)
 }
@@ -15,6 +15,8 @@ import (
 	"getsturdy.com/api/pkg/jwt"
 	"getsturdy.com/api/pkg/jwt/keys"
 	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	"getsturdy.com/api/pkg/jwt/revocations"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -33,17 +35,19 @@ const (
 )
 
 type Service struct {
-	logger   *zap.Logger
-	keysRepo db_keys.Repository
+	logger          *zap.Logger
+	keysRepo        db_keys.Repository
+	revocationsRepo db_revocations.Repository
 
 	signerInitOnce *sync.Once
 	signer         jose.Signer
 }
 
-func NewService(logger *zap.Logger, keysRepo db_keys.Repository) *Service {
+func NewService(logger *zap.Logger, keysRepo db_keys.Repository, revocationsRepo db_revocations.Repository) *Service {
 	return &Service{
-		logger:   logger,
-		keysRepo: db_keys.NewCache(keysRepo),
+		logger:          logger,
+		keysRepo:        db_keys.NewCache(keysRepo),
+		revocationsRepo: db_revocations.NewCache(revocationsRepo),
 
 		signerInitOnce: &sync.Once{},
 	}
@@ -127,6 +131,35 @@ func (s *Service) IssueToken(ctx context.Context, subject string, validFor time.
 	}, nil
 }
 
+// Revoke revokes all tokens of the subject that have been issued so far.
+func (s *Service) Revoke(ctx context.Context, subject string) error {
+	if err := s.revocationsRepo.Upsert(ctx, &revocations.Revocation{
+		Subject:   subject,
+		RevokedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// isRevoked returns true if the token was issued before the tokens of it's subject were revoked. Tokens only
+// have second precision, so tokens issued in the same second as the revocation are revoked as well.
+func (s *Service) isRevoked(ctx context.Context, claims *jose_jwt.Claims) (bool, error) {
+	revocation, err := s.revocationsRepo.Get(ctx, claims.Subject)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get revocation: %w", err)
+	}
+
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return !claims.IssuedAt.Time().After(revocation.RevokedAt.Truncate(time.Second)), nil
+}
+
 // Verify checks token signature and returns it's meaningful content.
 func (s *Service) Verify(ctx context.Context, rawToken string, expectedTypes ...jwt.TokenType) (*jwt.Token, error) {
 	jwtoken, err := jose_jwt.ParseSigned(rawToken)
@@ -203,6 +236,11 @@ func (s *Service) verify(ctx context.Context, rawToken string, jwtoken *jose_jwt
 		if !validType(sturdyClaims.Type, expectedTypes...) {
 			return nil, ErrInvalidToken
 		}
+		if revoked, err := s.isRevoked(ctx, stdClaims); err != nil {
+			return nil, err
+		} else if revoked {
+			return nil, ErrInvalidToken
+		}
 		return &jwt.Token{
 			Token:     rawToken,
 			Subject:   stdClaims.Subject,
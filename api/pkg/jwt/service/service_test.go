@@ -7,6 +7,7 @@ import (
 
 	"getsturdy.com/api/pkg/jwt"
 	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 	"getsturdy.com/api/pkg/jwt/service"
 
 	"github.com/stretchr/testify/assert"
@@ -14,7 +15,7 @@ import (
 )
 
 func TestVerify_shouldVerifyIssuedKey(t *testing.T) {
-	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory())
+	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
 
 	token, err := svc.IssueToken(context.Background(), "user-id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
@@ -24,3 +25,21 @@ func TestVerify_shouldVerifyIssuedKey(t *testing.T) {
 		assert.Equal(t, token, verifiedToken)
 	}
 }
+
+func TestVerify_shouldNotVerifyRevokedKey(t *testing.T) {
+	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+	ctx := context.Background()
+
+	token, err := svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+	otherToken, err := svc.IssueToken(ctx, "other-user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Revoke(ctx, "user-id"))
+
+	_, err = svc.Verify(ctx, token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+
+	_, err = svc.Verify(ctx, otherToken.Token, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+}
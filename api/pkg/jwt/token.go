@@ -12,6 +12,9 @@ const (
 	TokenTypeAuth TokenType = "auth"
 	// TokenTypeCI is the token type for CI authentication. It must have change_id as a subject.
 	TokenTypeCI TokenType = "ci"
+	// TokenTypeTwoFactor is the token type for users that have logged in with a password, but not yet with their
+	// second factor. It must have user_id as a subject.
+	TokenTypeTwoFactor TokenType = "two_factor"
 )
 
 type Token struct {
@@ -55,6 +55,12 @@ func (r *LandRootResolver) LandWorkspaceChange(ctx context.Context, args resolve
 	switch {
 	case errors.Is(err, service_land.ErrNotAllowedUnhealthyWorkspace):
 		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "This draft has unhealthy statuses and cannot be merged")
+	case errors.Is(err, service_land.ErrNotAllowedStackedWorkspace):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "This draft is stacked on a draft that has not been merged yet")
+	case errors.Is(err, service_land.ErrNotAllowedMissingApprovals):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "This draft has not been approved by the code owners of the files it changes")
+	case errors.Is(err, service_land.ErrNotAllowedToLand):
+		return nil, gqlerrors.Error(gqlerrors.ErrForbidden, "message", "You are not allowed to merge changes to this codebase")
 	case err != nil:
 		return nil, gqlerrors.Error(fmt.Errorf("failed to land change: %w", err))
 	}
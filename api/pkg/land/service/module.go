@@ -4,9 +4,13 @@ import (
 	"getsturdy.com/api/pkg/activity/sender"
 	service_activity "getsturdy.com/api/pkg/activity/service"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
 	service_comments "getsturdy.com/api/pkg/comments/service"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/events"
@@ -14,6 +18,7 @@ import (
 	"getsturdy.com/api/pkg/logger"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
+	service_sync "getsturdy.com/api/pkg/sync/service"
 	service_users "getsturdy.com/api/pkg/users/service/module"
 	service_view "getsturdy.com/api/pkg/views/service"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
@@ -41,5 +46,10 @@ func Module(c *di.Container) {
 	c.Import(workers_ci.Module)
 	c.Import(sender.Module)
 	c.Import(service_workspace_statuses.Module)
+	c.Import(service_sync.Module)
+	c.Import(service_codesearch.Module)
+	c.Import(service_auth.Module)
+	c.Import(service_codeowners.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 }
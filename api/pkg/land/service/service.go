@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,17 +13,23 @@ import (
 	service_activity "getsturdy.com/api/pkg/activity/service"
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/changes/message"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
 	service_comments "getsturdy.com/api/pkg/comments/service"
 	"getsturdy.com/api/pkg/events"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/snapshots"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
+	service_sync "getsturdy.com/api/pkg/sync/service"
 	service_users "getsturdy.com/api/pkg/users/service"
 	service_view "getsturdy.com/api/pkg/views/service"
 	vcs_view "getsturdy.com/api/pkg/views/vcs"
@@ -38,6 +45,9 @@ import (
 
 var (
 	ErrNotAllowedUnhealthyWorkspace = fmt.Errorf("not allowed to land workspace, it has unhealthy statuses")
+	ErrNotAllowedStackedWorkspace   = fmt.Errorf("not allowed to land workspace, the workspace it is stacked on has not landed")
+	ErrNotAllowedToLand             = fmt.Errorf("not allowed to land workspace, the acl of the codebase does not allow it")
+	ErrNotAllowedMissingApprovals   = fmt.Errorf("not allowed to land workspace, it has not been approved by the code owners")
 )
 
 type Service struct {
@@ -55,6 +65,11 @@ type Service struct {
 	activityService          *service_activity.Service
 	codebaseService          *service_codebase.Service
 	workspaceStatusesService *service_workspace_statuses.Service
+	syncService              *service_sync.Service
+	codesearchService        *service_codesearch.Service
+	authService              *service_auth.Service
+	codeOwnersService        *service_codeowners.Service
+	auditService             *service_audit.Service
 
 	activitySender   sender.ActivitySender
 	snapshotterQueue worker_snapshots.Queue
@@ -79,6 +94,11 @@ func New(
 	activityService *service_activity.Service,
 	codebaseService *service_codebase.Service,
 	workspaceStatusesService *service_workspace_statuses.Service,
+	syncService *service_sync.Service,
+	codesearchService *service_codesearch.Service,
+	authService *service_auth.Service,
+	codeOwnersService *service_codeowners.Service,
+	auditService *service_audit.Service,
 
 	activitySender sender.ActivitySender,
 	snapshotterQueue worker_snapshots.Queue,
@@ -102,6 +122,11 @@ func New(
 		activityService:          activityService,
 		codebaseService:          codebaseService,
 		workspaceStatusesService: workspaceStatusesService,
+		syncService:              syncService,
+		codesearchService:        codesearchService,
+		authService:              authService,
+		codeOwnersService:        codeOwnersService,
+		auditService:             auditService,
 
 		activitySender:   activitySender,
 		snapshotterQueue: snapshotterQueue,
@@ -119,19 +144,44 @@ func (s *Service) LandChange(ctx context.Context, ws *workspaces.Workspace, diff
 	}
 
 	// check if the workspace is allowed to be landed
+	if err := s.authService.CanLand(ctx, ws); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotAllowedToLand, err)
+	}
+
 	cb, err := s.codebaseService.GetByID(ctx, ws.CodebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get codebase: %w", err)
 	}
 
-	// make sure that all statuses are healthy and not stale
-	if cb.RequireHealthyStatus {
-		healthy, err := s.workspaceStatusesService.HealthyStatus(ctx, ws)
+	// make sure that the required status checks have passed
+	if service_workspace_statuses.RequiresChecks(cb) {
+		checks, err := s.workspaceStatusesService.Checks(ctx, cb, ws)
 		switch {
 		case err != nil:
-			return nil, fmt.Errorf("failed to get workspace status: %w", err)
-		case !healthy:
-			return nil, ErrNotAllowedUnhealthyWorkspace
+			return nil, fmt.Errorf("failed to get workspace status checks: %w", err)
+		case !checks.Passed():
+			return nil, fmt.Errorf("%w: %s", ErrNotAllowedUnhealthyWorkspace, checks.Reason)
+		}
+	}
+
+	// changes to owned files must be approved by the code owners
+	missingApprovals, err := s.codeOwnersService.MissingApprovals(ctx, ws)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed to get missing code owner approvals: %w", err)
+	case len(missingApprovals) > 0:
+		return nil, ErrNotAllowedMissingApprovals
+	}
+
+	// stacked workspaces can only be landed after the workspace they are stacked on
+	if ws.ParentWorkspaceID != nil {
+		parent, err := s.workspaceService.GetByID(ctx, *ws.ParentWorkspaceID)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("failed to get parent workspace: %w", err)
+		case !hasLandedSince(parent, ws.CreatedAt):
+			// an archived parent that never landed doesn't count, its changes are not on the trunk
+			return nil, ErrNotAllowedStackedWorkspace
 		}
 	}
 
@@ -203,12 +253,33 @@ func (s *Service) LandChange(ctx context.Context, ws *workspaces.Workspace, diff
 		ws.SetSnapshot(nil)
 	}
 
+	if err := s.changeService.IndexPaths(ctx, change); err != nil {
+		s.logger.Error("failed to index paths of change", zap.Error(err))
+		// don't fail
+	}
+
+	s.codesearchService.UpdateInBackground(ws.CodebaseID)
+
 	s.analyticsService.Capture(ctx, "create change",
 		analytics.CodebaseID(ws.CodebaseID),
 		analytics.Property("workspace_id", ws.ID),
 		analytics.Property("change_id", change.ID),
 	)
 
+	if err := s.auditService.Record(ctx, &audit.Entry{
+		CodebaseID: &ws.CodebaseID,
+		Action:     audit.ActionChangeLanded,
+		TargetType: audit.TargetChange,
+		TargetID:   string(change.ID),
+		After: audit.Metadata{
+			"workspace_id": ws.ID,
+			"commit_id":    change.CommitID,
+		},
+	}); err != nil {
+		s.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
 	if ws.ViewID != nil {
 		if err := s.snapshotterQueue.Enqueue(ctx, ws.CodebaseID, *ws.ViewID, ws.ID, ws.UserID, snapshots.ActionChangeLand); err != nil {
 			return nil, fmt.Errorf("failed to enqueue snapshot: %w", err)
@@ -289,5 +360,35 @@ func (s *Service) LandChange(ctx context.Context, ws *workspaces.Workspace, diff
 		return nil, fmt.Errorf("failed to archive workspace: %w", err)
 	}
 
+	s.restackChildren(ctx, ws)
+
 	return change, nil
 }
+
+// hasLandedSince returns true if the workspace has landed after t, or at all if t is not known.
+func hasLandedSince(ws *workspaces.Workspace, t *time.Time) bool {
+	if ws.LastLandedAt == nil {
+		return false
+	}
+	return t == nil || ws.LastLandedAt.After(*t)
+}
+
+// restackChildren moves the workspaces that are stacked on top of the landed workspace to the trunk.
+func (s *Service) restackChildren(ctx context.Context, ws *workspaces.Workspace) {
+	children, err := s.workspaceService.ListChildren(ctx, ws)
+	if err != nil {
+		s.logger.Error("failed to list stacked workspaces", zap.Error(err))
+		return
+	}
+
+	for _, child := range children {
+		logger := s.logger.With(zap.String("workspace_id", child.ID), zap.String("parent_workspace_id", ws.ID))
+		switch err := s.syncService.Restack(ctx, child); {
+		case errors.Is(err, service_sync.ErrRestackConflicts):
+			// the user has to sync the workspace manually
+			logger.Info("stacked workspace conflicts with trunk, not restacked")
+		case err != nil:
+			logger.Error("failed to restack workspace", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+
+	module_api "getsturdy.com/api/pkg/api/module"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/codebases/acl"
+	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/internal/dbtest"
+	service_land "getsturdy.com/api/pkg/land/service"
+	queue "getsturdy.com/api/pkg/queue/module"
+	"getsturdy.com/api/pkg/review"
+	db_review "getsturdy.com/api/pkg/review/db"
+	"getsturdy.com/api/pkg/snapshots"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	"getsturdy.com/api/pkg/users"
+	db_user "getsturdy.com/api/pkg/users/db"
+	service_view "getsturdy.com/api/pkg/views/service"
+	"getsturdy.com/api/pkg/workspaces"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+)
+
+func module(t *testing.T) di.Module {
+	return func(c *di.Container) {
+		// TODO: reduce scope
+		c.Import(module_api.Module)
+		c.ImportWithForce(configuration.TestModule)
+		c.ImportWithForce(queue.TestModule(t))
+		c.Register(func() *testing.T { return t })
+		c.RegisterWithForce(dbtest.DB)
+	}
+}
+
+type deps struct {
+	dig.In
+	UserRepo         db_user.Repository
+	ReviewRepo       db_review.ReviewRepository
+	AclProvider      *provider_acl.Provider
+	LandService      *service_land.Service
+	CodebaseService  *service_codebase.Service
+	WorkspaceService *service_workspace.Service
+	ViewService      *service_view.Service
+	SnapshotService  *service_snapshots.Service
+	ExecutorProvider executor.Provider
+}
+
+type testCase struct {
+	deps
+
+	ctx        context.Context
+	userID     users.ID
+	codebaseID codebases.ID
+}
+
+func setup(t *testing.T) *testCase {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	tc := &testCase{}
+	require.NoError(t, di.Init(module(t)).To(&tc.deps))
+
+	user := tc.createUser(t)
+	tc.userID = user.ID
+	tc.ctx = auth.NewContext(context.Background(), &auth.Subject{Type: auth.SubjectUser, ID: user.ID.String()})
+
+	cb, err := tc.CodebaseService.Create(tc.ctx, tc.userID, "test", nil)
+	require.NoError(t, err)
+	tc.codebaseID = cb.ID
+
+	return tc
+}
+
+func (tc *testCase) createUser(t *testing.T) *users.User {
+	user := &users.User{ID: users.ID(uuid.NewString()), Name: "Test", Email: uuid.NewString() + "@getsturdy.com"}
+	require.NoError(t, tc.UserRepo.Create(user))
+	return user
+}
+
+// workspaceWithChanges returns a workspace with the file changed in its latest snapshot, stacked on top of parent if
+// it's not nil.
+func (tc *testCase) workspaceWithChanges(t *testing.T, parent *workspaces.Workspace, filename, contents string) *workspaces.Workspace {
+	req := service_workspace.CreateWorkspaceRequest{UserID: tc.userID, CodebaseID: tc.codebaseID, Name: filename}
+	if parent != nil {
+		req.ParentWorkspaceID = &parent.ID
+	}
+	ws, err := tc.WorkspaceService.Create(tc.ctx, req)
+	require.NoError(t, err)
+
+	vw, err := tc.ViewService.Create(tc.ctx, tc.userID, ws, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, tc.ExecutorProvider.New().Write(func(repo vcs.RepoWriter) error {
+		return os.WriteFile(path.Join(repo.Path(), filename), []byte(contents), 0o644)
+	}).ExecView(tc.codebaseID, vw.ID, "workspaceWithChanges"))
+
+	_, err = tc.SnapshotService.Snapshot(tc.ctx, tc.codebaseID, ws.ID, snapshots.Action("testing"),
+		service_snapshots.WithOnView(vw.ID),
+		service_snapshots.WithMarkAsLatestInWorkspace(),
+	)
+	require.NoError(t, err)
+
+	return tc.get(t, ws.ID)
+}
+
+// get returns the workspace as it's stored, without a view, so that it's landed from the latest snapshot
+func (tc *testCase) get(t *testing.T, workspaceID string) *workspaces.Workspace {
+	ws, err := tc.WorkspaceService.GetByID(tc.ctx, workspaceID)
+	require.NoError(t, err)
+	ws.ViewID = nil
+	return ws
+}
+
+func TestLandChange_stacked(t *testing.T) {
+	tc := setup(t)
+
+	parent := tc.workspaceWithChanges(t, nil, "a.txt", "parent\n")
+	child := tc.workspaceWithChanges(t, parent, "b.txt", "child\n")
+
+	_, err := tc.LandService.LandChange(tc.ctx, child)
+	assert.ErrorIs(t, err, service_land.ErrNotAllowedStackedWorkspace)
+
+	_, err = tc.LandService.LandChange(tc.ctx, parent)
+	require.NoError(t, err)
+
+	// the child has been moved to the trunk when the parent landed
+	child = tc.get(t, child.ID)
+	assert.Nil(t, child.ParentWorkspaceID)
+
+	change, err := tc.LandService.LandChange(tc.ctx, child)
+	require.NoError(t, err)
+	assert.NotEmpty(t, change.ID)
+}
+
+func TestLandChange_stacked_archivedParent(t *testing.T) {
+	tc := setup(t)
+
+	parent := tc.workspaceWithChanges(t, nil, "a.txt", "parent\n")
+	child := tc.workspaceWithChanges(t, parent, "b.txt", "child\n")
+
+	// the changes of an archived parent are not on the trunk
+	require.NoError(t, tc.WorkspaceService.Archive(tc.ctx, parent))
+
+	_, err := tc.LandService.LandChange(tc.ctx, tc.get(t, child.ID))
+	assert.ErrorIs(t, err, service_land.ErrNotAllowedStackedWorkspace)
+}
+
+func TestLandChange_missingApprovals(t *testing.T) {
+	tc := setup(t)
+
+	owner := tc.createUser(t)
+	_, err := tc.CodebaseService.AddUser(tc.ctx, tc.codebaseID, owner, tc.userID)
+	require.NoError(t, err)
+
+	a, err := tc.AclProvider.GetByCodebaseID(tc.ctx, tc.codebaseID)
+	require.NoError(t, err)
+	a.Policy.CodeOwners = []*acl.CodeOwners{{
+		ID:         "owned",
+		Principals: []*acl.Identifier{{Type: acl.Users, Pattern: owner.Email}},
+		Resources:  []*acl.Identifier{{Type: acl.Files, Pattern: "a.txt"}},
+	}}
+	policy, err := json.Marshal(a.Policy)
+	require.NoError(t, err)
+	a.RawPolicy = string(policy)
+	require.NoError(t, tc.AclProvider.Update(tc.ctx, a))
+
+	ws := tc.workspaceWithChanges(t, nil, "a.txt", "owned\n")
+
+	_, err = tc.LandService.LandChange(tc.ctx, ws)
+	assert.ErrorIs(t, err, service_land.ErrNotAllowedMissingApprovals)
+
+	require.NoError(t, tc.ReviewRepo.Create(tc.ctx, review.Review{
+		ID:          uuid.NewString(),
+		UserID:      owner.ID,
+		CodebaseID:  tc.codebaseID,
+		WorkspaceID: ws.ID,
+		SnapshotID:  ws.LatestSnapshotID,
+		Grade:       review.ReviewGradeApprove,
+		CreatedAt:   time.Now(),
+	}))
+
+	_, err = tc.LandService.LandChange(tc.ctx, ws)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,102 @@
+// Package client connects to the directory. Connections are always encrypted, either with ldaps:// or with StartTLS.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"getsturdy.com/api/pkg/ldap/configuration"
+
+	go_ldap "github.com/go-ldap/ldap/v3"
+)
+
+const timeout = 10 * time.Second
+
+// Dialer connects to the directory.
+type Dialer interface {
+	Dial(ctx context.Context) (go_ldap.Client, error)
+}
+
+type tlsDialer struct {
+	cfg *configuration.Configuration
+}
+
+func New(cfg *configuration.Configuration) Dialer {
+	return &tlsDialer{cfg: cfg}
+}
+
+// Dial connects to the server with TLS. An ldap:// url is upgraded with StartTLS, and the connection fails if the
+// server doesn't support it, so that passwords are never sent in plain text.
+func (d *tlsDialer) Dial(ctx context.Context) (go_ldap.Client, error) {
+	u, err := url.Parse(d.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	tlsConfig, err := d.tlsConfig(u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn *go_ldap.Conn
+	switch u.Scheme {
+	case "ldaps":
+		netConn, err := (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", hostPort(u, go_ldap.DefaultLdapsPort))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		conn = go_ldap.NewConn(netConn, true)
+		conn.Start()
+	case "ldap":
+		netConn, err := dialer.DialContext(ctx, "tcp", hostPort(u, go_ldap.DefaultLdapPort))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		conn = go_ldap.NewConn(netConn, false)
+		conn.Start()
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start tls: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, the url must be ldaps:// or ldap:// (with StartTLS)", u.Scheme)
+	}
+
+	conn.SetTimeout(timeout)
+	return conn, nil
+}
+
+func (d *tlsDialer) tlsConfig(serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+	if d.cfg.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(d.cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca file: %w", err)
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", d.cfg.CAFile)
+	}
+	return tlsConfig, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
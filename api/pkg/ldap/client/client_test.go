@@ -0,0 +1,99 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"getsturdy.com/api/pkg/ldap/client"
+	"getsturdy.com/api/pkg/ldap/configuration"
+	"getsturdy.com/api/pkg/ldap/ldaptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	go_ldap "github.com/go-ldap/ldap/v3"
+)
+
+func TestDial_unsupportedScheme(t *testing.T) {
+	_, err := client.New(&configuration.Configuration{URL: "ldapi:///var/run/slapd/ldapi"}).Dial(context.Background())
+	assert.ErrorContains(t, err, "unsupported scheme")
+}
+
+func TestDial_requiresStartTLS(t *testing.T) {
+	// the server closes all connections, without responding to StartTLS
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	_, err = client.New(&configuration.Configuration{URL: "ldap://" + listener.Addr().String()}).Dial(context.Background())
+	assert.ErrorContains(t, err, "failed to start tls")
+}
+
+func newServer(t *testing.T) *ldaptest.Server {
+	return ldaptest.NewServer(t, ldaptest.NewDirectory(
+		&ldaptest.Entry{DN: "cn=sturdy,dc=example,dc=com", Attributes: map[string][]string{"userPassword": {"password"}}},
+		&ldaptest.Entry{DN: "uid=alice,ou=people,dc=example,dc=com", Attributes: map[string][]string{"uid": {"alice"}, "mail": {"alice@example.com"}}},
+	))
+}
+
+// bindAndSearch binds as the service account, and returns the emails of the people in the directory
+func bindAndSearch(t *testing.T, conn go_ldap.Client) []string {
+	require.NoError(t, conn.Bind("cn=sturdy,dc=example,dc=com", "password"))
+
+	result, err := conn.Search(&go_ldap.SearchRequest{
+		BaseDN:     "ou=people,dc=example,dc=com",
+		Scope:      go_ldap.ScopeWholeSubtree,
+		Filter:     "(uid=alice)",
+		Attributes: []string{"mail"},
+	})
+	require.NoError(t, err)
+
+	var emails []string
+	for _, entry := range result.Entries {
+		emails = append(emails, entry.GetAttributeValue("mail"))
+	}
+	return emails
+}
+
+func TestDial_ldaps(t *testing.T) {
+	server := newServer(t)
+
+	conn, err := client.New(&configuration.Configuration{URL: server.LDAPSURL, CAFile: server.CAFile}).Dial(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, []string{"alice@example.com"}, bindAndSearch(t, conn))
+}
+
+func TestDial_startTLS(t *testing.T) {
+	server := newServer(t)
+
+	conn, err := client.New(&configuration.Configuration{URL: server.URL, CAFile: server.CAFile}).Dial(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, []string{"alice@example.com"}, bindAndSearch(t, conn))
+
+	err = conn.Bind("cn=sturdy,dc=example,dc=com", "wrong")
+	assert.True(t, go_ldap.IsErrorWithCode(err, go_ldap.LDAPResultInvalidCredentials))
+}
+
+func TestDial_untrustedCertificate(t *testing.T) {
+	server := newServer(t)
+
+	_, err := client.New(&configuration.Configuration{URL: server.LDAPSURL}).Dial(context.Background())
+	assert.ErrorContains(t, err, "failed to connect")
+
+	_, err = client.New(&configuration.Configuration{URL: server.URL}).Dial(context.Background())
+	assert.ErrorContains(t, err, "failed to start tls")
+}
@@ -0,0 +1,27 @@
+package configuration
+
+import "time"
+
+type Configuration struct {
+	URL                  string        `long:"url" description:"URL of the LDAP server, either ldaps:// or ldap:// (which is upgraded with StartTLS), LDAP login is enabled if it's set"`
+	CAFile               string        `long:"ca-file" description:"PEM file with the CA certificates that the certificate of the server is verified with, the system CAs are used if it's not set"`
+	BindDN               string        `long:"bind-dn" description:"DN of the service account that searches the directory"`
+	BindPassword         string        `long:"bind-password" description:"Password of the service account"`
+	UserBaseDN           string        `long:"user-base-dn" description:"DN to search for users under"`
+	UserFilter           string        `long:"user-filter" description:"Filter that finds a user, {username} is replaced with the escaped username" default:"(uid={username})"`
+	EmailAttribute       string        `long:"email-attribute" description:"Attribute with the email of a user" default:"mail"`
+	NameAttribute        string        `long:"name-attribute" description:"Attribute with the name of a user" default:"cn"`
+	GroupBaseDN          string        `long:"group-base-dn" description:"DN to search for groups under, groups are synced if it's set, and can be used as groups::<name> in access control lists"`
+	GroupFilter          string        `long:"group-filter" description:"Filter that finds the groups to sync" default:"(objectClass=groupOfNames)"`
+	GroupNameAttribute   string        `long:"group-name-attribute" description:"Attribute with the name of a group" default:"cn"`
+	GroupMemberAttribute string        `long:"group-member-attribute" description:"Attribute with the DNs of the members of a group" default:"member"`
+	GroupSyncInterval    time.Duration `long:"group-sync-interval" description:"How often groups are synced" default:"15m"`
+}
+
+func (c *Configuration) Enabled() bool {
+	return c != nil && c.URL != ""
+}
+
+func (c *Configuration) GroupSyncEnabled() bool {
+	return c.Enabled() && c.GroupBaseDN != ""
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ GroupRepository = &groupRepository{}
+
+type groupRepository struct {
+	db *sqlx.DB
+}
+
+func NewGroupRepository(db *sqlx.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) Create(ctx context.Context, group *ldap.Group) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO ldap_groups
+		(id, dn, name, unmatched_members, added_user_ids, removed_user_ids, created_at, synced_at)
+		VALUES
+		(:id, :dn, :name, :unmatched_members, :added_user_ids, :removed_user_ids, :created_at, :synced_at)`, group); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) Update(ctx context.Context, group *ldap.Group) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE ldap_groups
+		SET name = :name,
+		    unmatched_members = :unmatched_members,
+		    added_user_ids = :added_user_ids,
+		    removed_user_ids = :removed_user_ids,
+		    synced_at = :synced_at
+		WHERE id = :id`, group); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM ldap_group_members WHERE group_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete members: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM ldap_groups WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) List(ctx context.Context) ([]*ldap.Group, error) {
+	var groups []*ldap.Group
+	if err := r.db.SelectContext(ctx, &groups, `SELECT id, dn, name, unmatched_members, added_user_ids, removed_user_ids, created_at, synced_at
+		FROM ldap_groups
+		ORDER BY name, dn`); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) ListByUserID(ctx context.Context, userID users.ID) ([]*ldap.Group, error) {
+	var groups []*ldap.Group
+	if err := r.db.SelectContext(ctx, &groups, `SELECT g.id, g.dn, g.name, g.unmatched_members, g.added_user_ids, g.removed_user_ids, g.created_at, g.synced_at
+		FROM ldap_groups g
+		JOIN ldap_group_members m ON m.group_id = g.id
+		WHERE m.user_id = $1
+		ORDER BY g.name, g.dn`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) AddMember(ctx context.Context, groupID string, userID users.ID) error {
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO ldap_group_members (group_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, groupID, userID); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) RemoveMember(ctx context.Context, groupID string, userID users.ID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM ldap_group_members
+		WHERE group_id = $1
+		  AND user_id = $2`, groupID, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) ListMembers(ctx context.Context, groupID string) ([]users.ID, error) {
+	var userIDs []users.ID
+	if err := r.db.SelectContext(ctx, &userIDs, `SELECT user_id
+		FROM ldap_group_members
+		WHERE group_id = $1
+		ORDER BY user_id`, groupID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/ldap"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ IdentityRepository = &identityRepository{}
+
+type identityRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepository(db *sqlx.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *ldap.Identity) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO ldap_identities
+		(id, dn, user_id, created_at, last_login_at)
+		VALUES
+		(:id, :dn, :user_id, :created_at, :last_login_at)`, identity); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) Update(ctx context.Context, identity *ldap.Identity) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE ldap_identities
+		SET user_id = :user_id,
+		    last_login_at = :last_login_at
+		WHERE id = :id`, identity); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) GetByDN(ctx context.Context, dn string) (*ldap.Identity, error) {
+	var identity ldap.Identity
+	if err := r.db.GetContext(ctx, &identity, `SELECT id, dn, user_id, created_at, last_login_at
+		FROM ldap_identities
+		WHERE dn = $1`, dn); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &identity, nil
+}
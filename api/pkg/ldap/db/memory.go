@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ IdentityRepository = &memoryIdentityRepository{}
+
+type memoryIdentityRepository struct {
+	mu         sync.RWMutex
+	identities []ldap.Identity
+}
+
+func NewMemoryIdentityRepository() IdentityRepository {
+	return &memoryIdentityRepository{}
+}
+
+func (r *memoryIdentityRepository) Create(_ context.Context, identity *ldap.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryIdentityRepository) Update(_ context.Context, identity *ldap.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, i := range r.identities {
+		if i.ID == identity.ID {
+			r.identities[k] = *identity
+		}
+	}
+	return nil
+}
+
+func (r *memoryIdentityRepository) GetByDN(_ context.Context, dn string) (*ldap.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, i := range r.identities {
+		if i.DN == dn {
+			return &i, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+var _ GroupRepository = &memoryGroupRepository{}
+
+type memoryGroupRepository struct {
+	mu      sync.RWMutex
+	groups  []ldap.Group
+	members map[string]map[users.ID]struct{}
+}
+
+func NewMemoryGroupRepository() GroupRepository {
+	return &memoryGroupRepository{members: make(map[string]map[users.ID]struct{})}
+}
+
+func (r *memoryGroupRepository) Create(_ context.Context, group *ldap.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups = append(r.groups, *group)
+	return nil
+}
+
+func (r *memoryGroupRepository) Update(_ context.Context, group *ldap.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, g := range r.groups {
+		if g.ID == group.ID {
+			r.groups[k] = *group
+		}
+	}
+	return nil
+}
+
+func (r *memoryGroupRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members, id)
+	for k, g := range r.groups {
+		if g.ID == id {
+			r.groups = append(r.groups[:k], r.groups[k+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryGroupRepository) List(_ context.Context) ([]*ldap.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*ldap.Group, 0, len(r.groups))
+	for _, g := range r.groups {
+		g2 := g
+		res = append(res, &g2)
+	}
+	sortGroups(res)
+	return res, nil
+}
+
+func (r *memoryGroupRepository) ListByUserID(_ context.Context, userID users.ID) ([]*ldap.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var res []*ldap.Group
+	for _, g := range r.groups {
+		if _, ok := r.members[g.ID][userID]; ok {
+			g2 := g
+			res = append(res, &g2)
+		}
+	}
+	sortGroups(res)
+	return res, nil
+}
+
+func (r *memoryGroupRepository) AddMember(_ context.Context, groupID string, userID users.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.members[groupID] == nil {
+		r.members[groupID] = make(map[users.ID]struct{})
+	}
+	r.members[groupID][userID] = struct{}{}
+	return nil
+}
+
+func (r *memoryGroupRepository) RemoveMember(_ context.Context, groupID string, userID users.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members[groupID], userID)
+	return nil
+}
+
+func (r *memoryGroupRepository) ListMembers(_ context.Context, groupID string) ([]users.ID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]users.ID, 0, len(r.members[groupID]))
+	for userID := range r.members[groupID] {
+		res = append(res, userID)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+func sortGroups(groups []*ldap.Group) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Name != groups[j].Name {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].DN < groups[j].DN
+	})
+}
+
+var _ SyncRepository = &memorySyncRepository{}
+
+type memorySyncRepository struct {
+	mu    sync.RWMutex
+	syncs []ldap.Sync
+}
+
+func NewMemorySyncRepository() SyncRepository {
+	return &memorySyncRepository{}
+}
+
+func (r *memorySyncRepository) Create(_ context.Context, sync *ldap.Sync) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.syncs = append(r.syncs, *sync)
+	return nil
+}
+
+func (r *memorySyncRepository) Update(_ context.Context, sync *ldap.Sync) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, s := range r.syncs {
+		if s.ID == sync.ID {
+			r.syncs[k] = *sync
+		}
+	}
+	return nil
+}
+
+func (r *memorySyncRepository) GetLatest(_ context.Context) (*ldap.Sync, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest *ldap.Sync
+	for _, s := range r.syncs {
+		if latest == nil || !s.StartedAt.Before(latest.StartedAt) {
+			s2 := s
+			latest = &s2
+		}
+	}
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+	return latest, nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/users"
+)
+
+type IdentityRepository interface {
+	Create(context.Context, *ldap.Identity) error
+	Update(context.Context, *ldap.Identity) error
+	GetByDN(ctx context.Context, dn string) (*ldap.Identity, error)
+}
+
+type GroupRepository interface {
+	Create(context.Context, *ldap.Group) error
+	Update(context.Context, *ldap.Group) error
+	Delete(ctx context.Context, id string) error
+	List(context.Context) ([]*ldap.Group, error)
+	// ListByUserID lists the groups that the user is a member of.
+	ListByUserID(context.Context, users.ID) ([]*ldap.Group, error)
+
+	AddMember(ctx context.Context, groupID string, userID users.ID) error
+	RemoveMember(ctx context.Context, groupID string, userID users.ID) error
+	ListMembers(ctx context.Context, groupID string) ([]users.ID, error)
+}
+
+type SyncRepository interface {
+	Create(context.Context, *ldap.Sync) error
+	Update(context.Context, *ldap.Sync) error
+	// GetLatest returns the sync that was started last.
+	GetLatest(context.Context) (*ldap.Sync, error)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/ldap"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ SyncRepository = &syncRepository{}
+
+type syncRepository struct {
+	db *sqlx.DB
+}
+
+func NewSyncRepository(db *sqlx.DB) SyncRepository {
+	return &syncRepository{db: db}
+}
+
+func (r *syncRepository) Create(ctx context.Context, sync *ldap.Sync) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO ldap_syncs
+		(id, started_at, finished_at, error, added_groups, removed_groups)
+		VALUES
+		(:id, :started_at, :finished_at, :error, :added_groups, :removed_groups)`, sync); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *syncRepository) Update(ctx context.Context, sync *ldap.Sync) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE ldap_syncs
+		SET finished_at = :finished_at,
+		    error = :error,
+		    added_groups = :added_groups,
+		    removed_groups = :removed_groups
+		WHERE id = :id`, sync); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *syncRepository) GetLatest(ctx context.Context) (*ldap.Sync, error) {
+	var sync ldap.Sync
+	if err := r.db.GetContext(ctx, &sync, `SELECT id, started_at, finished_at, error, added_groups, removed_groups
+		FROM ldap_syncs
+		ORDER BY started_at DESC
+		LIMIT 1`); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &sync, nil
+}
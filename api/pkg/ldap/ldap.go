@@ -0,0 +1,63 @@
+package ldap
+
+import (
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+// Identity links a user to their entry in the directory.
+type Identity struct {
+	ID string `db:"id"`
+	// DN is the normalized DN of the entry, see NormalizeDN.
+	DN          string    `db:"dn"`
+	UserID      users.ID  `db:"user_id"`
+	CreatedAt   time.Time `db:"created_at"`
+	LastLoginAt time.Time `db:"last_login_at"`
+}
+
+// Group is a group in the directory, that is synced periodically. Groups can be used as groups::<name> in the acls
+// of codebases.
+type Group struct {
+	ID string `db:"id"`
+	// DN is the normalized DN of the group, see NormalizeDN.
+	DN   string `db:"dn"`
+	Name string `db:"name"`
+	// UnmatchedMembers are the DNs of members that could not be matched to a user, as of the last sync.
+	UnmatchedMembers pq.StringArray `db:"unmatched_members"`
+	// AddedUserIDs and RemovedUserIDs are the members that were added and removed by the last sync.
+	AddedUserIDs   pq.StringArray `db:"added_user_ids"`
+	RemovedUserIDs pq.StringArray `db:"removed_user_ids"`
+	CreatedAt      time.Time      `db:"created_at"`
+	SyncedAt       time.Time      `db:"synced_at"`
+}
+
+// Sync is a run of the group sync.
+type Sync struct {
+	ID         string     `db:"id"`
+	StartedAt  time.Time  `db:"started_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+	// Error is set if the sync failed.
+	Error *string `db:"error"`
+	// AddedGroups and RemovedGroups are the names of the groups that were added to or removed from the directory
+	// since the previous sync.
+	AddedGroups   pq.StringArray `db:"added_groups"`
+	RemovedGroups pq.StringArray `db:"removed_groups"`
+}
+
+// NormalizeDN returns a DN that can be compared with other normalized DNs. Attribute types and values are lower
+// cased, and spaces around separators are removed.
+func NormalizeDN(dn string) string {
+	if dn == "" {
+		return ""
+	}
+	rdns := strings.Split(dn, ",")
+	for i, rdn := range rdns {
+		name, value, _ := strings.Cut(rdn, "=")
+		rdns[i] = strings.ToLower(strings.TrimSpace(name)) + "=" + strings.ToLower(strings.TrimSpace(value))
+	}
+	return strings.Join(rdns, ",")
+}
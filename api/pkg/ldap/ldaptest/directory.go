@@ -0,0 +1,248 @@
+// Package ldaptest provides an in-memory directory for tests, that implements the go-ldap client, and a Server that
+// serves it over the network.
+//
+// The directory supports simple binds and searches, which is what the ldap service uses. Passwords are stored in
+// plain text in the userPassword attribute of an entry, and every entry is readable by every bound user. DNs are
+// compared case-insensitively, escaped commas in DNs are not supported.
+package ldaptest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/ldap/client"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	go_ldap "github.com/go-ldap/ldap/v3"
+)
+
+const passwordAttribute = "userpassword"
+
+var _ client.Dialer = &Directory{}
+
+// Entry is an entry in the directory.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+type Directory struct {
+	mu      sync.RWMutex
+	entries map[string]*Entry
+	closed  bool
+}
+
+// NewDirectory returns a directory with the given entries.
+func NewDirectory(entries ...*Entry) *Directory {
+	d := &Directory{entries: make(map[string]*Entry)}
+	for _, entry := range entries {
+		d.Add(entry)
+	}
+	return d
+}
+
+// Add adds an entry, or replaces the entry with the same DN.
+func (d *Directory) Add(entry *Entry) {
+	attributes := make(map[string][]string, len(entry.Attributes))
+	for name, values := range entry.Attributes {
+		attributes[strings.ToLower(name)] = append(attributes[strings.ToLower(name)], values...)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[ldap.NormalizeDN(entry.DN)] = &Entry{DN: entry.DN, Attributes: attributes}
+}
+
+// Remove removes the entry with the given DN.
+func (d *Directory) Remove(dn string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, ldap.NormalizeDN(dn))
+}
+
+// Close makes the directory unreachable, new connections fail.
+func (d *Directory) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+}
+
+func (d *Directory) Dial(context.Context) (go_ldap.Client, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return nil, go_ldap.NewError(go_ldap.ErrorNetwork, errors.New("connection refused"))
+	}
+	return &conn{directory: d}, nil
+}
+
+// conn is a connection to the directory, operations that are not implemented panic.
+type conn struct {
+	go_ldap.Client
+
+	directory *Directory
+	boundDN   string
+}
+
+func (c *conn) Close() {}
+
+func (c *conn) Bind(dn, password string) error {
+	c.directory.mu.RLock()
+	entry, ok := c.directory.entries[ldap.NormalizeDN(dn)]
+	c.directory.mu.RUnlock()
+	if !ok || password == "" || !contains(entry.Attributes[passwordAttribute], password, false) {
+		c.boundDN = ""
+		return go_ldap.NewError(go_ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	c.boundDN = entry.DN
+	return nil
+}
+
+func (c *conn) Search(req *go_ldap.SearchRequest) (*go_ldap.SearchResult, error) {
+	if c.boundDN == "" {
+		return nil, go_ldap.NewError(go_ldap.LDAPResultInsufficientAccessRights, errors.New("bind required"))
+	}
+
+	filter, err := go_ldap.CompileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var requested []string
+	for _, attribute := range req.Attributes {
+		requested = append(requested, strings.ToLower(attribute))
+	}
+
+	c.directory.mu.RLock()
+	defer c.directory.mu.RUnlock()
+
+	baseDN := ldap.NormalizeDN(req.BaseDN)
+	result := &go_ldap.SearchResult{}
+	for dn, entry := range c.directory.entries {
+		if !inScope(dn, baseDN, req.Scope) || !matches(entry, filter) {
+			continue
+		}
+		if req.SizeLimit > 0 && len(result.Entries) == req.SizeLimit {
+			return result, go_ldap.NewError(go_ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+		}
+		result.Entries = append(result.Entries, searchResultEntry(entry, requested))
+	}
+	if len(result.Entries) == 0 && req.Scope != go_ldap.ScopeWholeSubtree {
+		if _, ok := c.directory.entries[baseDN]; !ok {
+			return result, go_ldap.NewError(go_ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+		}
+	}
+	return result, nil
+}
+
+func searchResultEntry(entry *Entry, requested []string) *go_ldap.Entry {
+	attributes := make(map[string][]string)
+	for name, values := range entry.Attributes {
+		if name == passwordAttribute {
+			continue
+		}
+		if len(requested) > 0 && !contains(requested, name, false) && !contains(requested, "*", false) {
+			continue
+		}
+		attributes[name] = values
+	}
+	return go_ldap.NewEntry(entry.DN, attributes)
+}
+
+func inScope(dn, baseDN string, scope int) bool {
+	switch scope {
+	case go_ldap.ScopeBaseObject:
+		return dn == baseDN
+	case go_ldap.ScopeSingleLevel:
+		_, parent, _ := strings.Cut(dn, ",")
+		return parent == baseDN
+	default:
+		return dn == baseDN || baseDN == "" || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+// matches evaluates a compiled filter, attribute values are compared case-insensitively.
+func matches(entry *Entry, filter *ber.Packet) bool {
+	if filter.ClassType != ber.ClassContext {
+		return false
+	}
+
+	switch filter.Tag {
+	case go_ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case go_ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case go_ldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case go_ldap.FilterPresent:
+		return len(entry.Attributes[strings.ToLower(value(filter))]) > 0
+	case go_ldap.FilterEqualityMatch, go_ldap.FilterApproxMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		return contains(entry.Attributes[strings.ToLower(value(filter.Children[0]))], value(filter.Children[1]), true)
+	case go_ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range entry.Attributes[strings.ToLower(value(filter.Children[0]))] {
+			if matchesSubstrings(strings.ToLower(v), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func matchesSubstrings(v string, substrings []*ber.Packet) bool {
+	for _, substring := range substrings {
+		part := strings.ToLower(value(substring))
+		switch substring.Tag {
+		case go_ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(v, part) {
+				return false
+			}
+			v = v[len(part):]
+		case go_ldap.FilterSubstringsAny:
+			i := strings.Index(v, part)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(part):]
+		case go_ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(v, part) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func value(p *ber.Packet) string {
+	s, _ := p.Value.(string)
+	return s
+}
+
+func contains(values []string, want string, ignoreCase bool) bool {
+	for _, v := range values {
+		if v == want || (ignoreCase && strings.EqualFold(v, want)) {
+			return true
+		}
+	}
+	return false
+}
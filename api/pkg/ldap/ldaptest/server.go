@@ -0,0 +1,301 @@
+package ldaptest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	go_ldap "github.com/go-ldap/ldap/v3"
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Server serves a directory over the network, with a self-signed certificate. It supports ldaps://, StartTLS, simple
+// binds and searches, which is what the ldap service uses.
+type Server struct {
+	// URL is the ldap:// url of the server, connections must be upgraded with StartTLS before binding.
+	URL string
+	// LDAPSURL is the ldaps:// url of the server.
+	LDAPSURL string
+	// CAFile is the path to the PEM encoded certificate of the server.
+	CAFile string
+
+	directory *Directory
+	tlsConfig *tls.Config
+	listeners []net.Listener
+	wg        sync.WaitGroup
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// NewServer starts a server for the directory on local ports, the server is closed when the test finishes. If the
+// directory is closed, the server closes new connections right away.
+func NewServer(t testing.TB, directory *Directory) *Server {
+	t.Helper()
+
+	certificate, caFile := newCertificate(t)
+	s := &Server{
+		CAFile:    caFile,
+		directory: directory,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12},
+		conns:     make(map[net.Conn]struct{}),
+	}
+
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ldaptest: failed to listen: %s", err)
+	}
+	encrypted, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ldaptest: failed to listen: %s", err)
+	}
+	s.URL = "ldap://" + plain.Addr().String()
+	s.LDAPSURL = "ldaps://" + encrypted.Addr().String()
+	s.listeners = []net.Listener{plain, tls.NewListener(encrypted, s.tlsConfig)}
+
+	for _, listener := range s.listeners {
+		s.wg.Add(1)
+		go s.serve(listener)
+	}
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server, and closes all open connections.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for _, listener := range s.listeners {
+		_ = listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serve(listener net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// handle serves the requests of a connection until it's closed, or the client unbinds.
+func (s *Server) handle(netConn net.Conn) {
+	defer netConn.Close()
+
+	client, err := s.directory.Dial(context.Background())
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	_, isTLS := netConn.(*tls.Conn)
+	for {
+		packet, err := ber.ReadPacket(netConn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+
+		op := packet.Children[1]
+		switch op.Tag {
+		case go_ldap.ApplicationBindRequest:
+			if !isTLS {
+				// passwords are never sent in plain text, StartTLS is required first
+				err = go_ldap.NewError(go_ldap.LDAPResultConfidentialityRequired, errors.New("tls is required"))
+			} else if len(op.Children) < 3 {
+				err = go_ldap.NewError(go_ldap.LDAPResultProtocolError, errors.New("invalid bind request"))
+			} else {
+				err = client.Bind(op.Children[1].Data.String(), op.Children[2].Data.String())
+			}
+			if !write(netConn, messageID, result(go_ldap.ApplicationBindResponse, err)) {
+				return
+			}
+		case go_ldap.ApplicationUnbindRequest:
+			return
+		case go_ldap.ApplicationSearchRequest:
+			var res *go_ldap.SearchResult
+			req, err := searchRequest(op)
+			if err == nil {
+				res, err = client.Search(req)
+			}
+			if res != nil {
+				for _, entry := range res.Entries {
+					if !write(netConn, messageID, searchResultEntryPacket(entry)) {
+						return
+					}
+				}
+			}
+			if !write(netConn, messageID, result(go_ldap.ApplicationSearchResultDone, err)) {
+				return
+			}
+		case go_ldap.ApplicationExtendedRequest:
+			if len(op.Children) == 0 || op.Children[0].Data.String() != startTLSOID || isTLS {
+				err := go_ldap.NewError(go_ldap.LDAPResultProtocolError, errors.New("unsupported extended operation"))
+				if !write(netConn, messageID, result(go_ldap.ApplicationExtendedResponse, err)) {
+					return
+				}
+				continue
+			}
+			if !write(netConn, messageID, result(go_ldap.ApplicationExtendedResponse, nil)) {
+				return
+			}
+			tlsConn := tls.Server(netConn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			netConn, isTLS = tlsConn, true
+		default:
+			return
+		}
+	}
+}
+
+func searchRequest(op *ber.Packet) (*go_ldap.SearchRequest, error) {
+	if len(op.Children) < 8 {
+		return nil, go_ldap.NewError(go_ldap.LDAPResultProtocolError, errors.New("invalid search request"))
+	}
+	scope, _ := op.Children[1].Value.(int64)
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter, err := go_ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return nil, go_ldap.NewError(go_ldap.LDAPResultProtocolError, err)
+	}
+	var attributes []string
+	for _, attribute := range op.Children[7].Children {
+		attributes = append(attributes, attribute.Data.String())
+	}
+	return &go_ldap.SearchRequest{
+		BaseDN:     op.Children[0].Data.String(),
+		Scope:      int(scope),
+		SizeLimit:  int(sizeLimit),
+		Filter:     filter,
+		Attributes: attributes,
+	}, nil
+}
+
+func searchResultEntryPacket(entry *go_ldap.Entry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, go_ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for _, attribute := range entry.Attributes {
+		a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute.Name, "Type"))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range attribute.Values {
+			values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		a.AppendChild(values)
+		attributes.AppendChild(a)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+// result returns a response with the result code of the error, which is a success if the error is nil.
+func result(tag ber.Tag, err error) *ber.Packet {
+	code, message := uint16(go_ldap.LDAPResultSuccess), ""
+	if err != nil {
+		code, message = go_ldap.LDAPResultOther, err.Error()
+		var ldapErr *go_ldap.Error
+		if errors.As(err, &ldapErr) {
+			code, message = ldapErr.ResultCode, ldapErr.Err.Error()
+		}
+	}
+
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return op
+}
+
+// write writes a message with the operation to the connection, it returns false if the connection is broken.
+func write(conn net.Conn, messageID int64, op *ber.Packet) bool {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	packet.AppendChild(op)
+	_, err := conn.Write(packet.Bytes())
+	return err == nil
+}
+
+// newCertificate returns a self-signed certificate for 127.0.0.1 and localhost, and the path of a file with the
+// certificate in PEM.
+func newCertificate(t testing.TB) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ldaptest: failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldaptest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("ldaptest: failed to create certificate: %s", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("ldaptest: failed to write certificate: %s", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
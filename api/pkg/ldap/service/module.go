@@ -0,0 +1,17 @@
+package service
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/ldap/client"
+	db_ldap "getsturdy.com/api/pkg/ldap/db"
+	"getsturdy.com/api/pkg/logger"
+	service_user "getsturdy.com/api/pkg/users/service/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(client.Module)
+	c.Import(db_ldap.Module)
+	c.Import(service_user.Module)
+	c.Register(New)
+}
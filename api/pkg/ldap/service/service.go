@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/ldap/client"
+	"getsturdy.com/api/pkg/ldap/configuration"
+	db_ldap "getsturdy.com/api/pkg/ldap/db"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	go_ldap "github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDisabled           = errors.New("ldap login is not enabled")
+	ErrGroupSyncDisabled  = errors.New("ldap group sync is not enabled")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrNoEmail            = errors.New("the user has no email in the directory")
+	ErrEmailTaken         = errors.New("a user with the same email already exists")
+)
+
+type Service struct {
+	logger       *zap.Logger
+	cfg          *configuration.Configuration
+	dialer       client.Dialer
+	identityRepo db_ldap.IdentityRepository
+	groupRepo    db_ldap.GroupRepository
+	syncRepo     db_ldap.SyncRepository
+	userService  service_user.Service
+
+	// syncMu makes sure that only one sync runs at a time
+	syncMu sync.Mutex
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *configuration.Configuration,
+	dialer client.Dialer,
+	identityRepo db_ldap.IdentityRepository,
+	groupRepo db_ldap.GroupRepository,
+	syncRepo db_ldap.SyncRepository,
+	userService service_user.Service,
+) *Service {
+	return &Service{
+		logger:       logger.Named("ldap"),
+		cfg:          cfg,
+		dialer:       dialer,
+		identityRepo: identityRepo,
+		groupRepo:    groupRepo,
+		syncRepo:     syncRepo,
+		userService:  userService,
+	}
+}
+
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+func (s *Service) GroupSyncEnabled() bool {
+	return s.cfg.GroupSyncEnabled()
+}
+
+func (s *Service) GroupSyncInterval() time.Duration {
+	return s.cfg.GroupSyncInterval
+}
+
+// connect connects to the server, and binds as the service account. Without a service account, the directory is
+// searched anonymously.
+func (s *Service) connect(ctx context.Context) (go_ldap.Client, error) {
+	conn, err := s.dialer.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.BindDN == "" {
+		return conn, nil
+	}
+	if err := conn.Bind(s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to bind as the service account: %w", err)
+	}
+	return conn, nil
+}
+
+// Login verifies the password of the user with the given username by binding as them, and returns the Sturdy user
+// that they are linked to.
+//
+// Users that have logged in before are found by their DN. Otherwise, a new user is created. The email in the directory
+// is not proof that the entry owns the email, so entries are never linked to existing users by their email, and the
+// login fails with ErrEmailTaken instead.
+func (s *Service) Login(ctx context.Context, username, password string) (*users.User, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// a size limit of 2 is enough to tell if the filter is ambiguous
+	result, err := conn.Search(&go_ldap.SearchRequest{
+		BaseDN:     s.cfg.UserBaseDN,
+		Scope:      go_ldap.ScopeWholeSubtree,
+		Filter:     strings.ReplaceAll(s.cfg.UserFilter, "{username}", go_ldap.EscapeFilter(username)),
+		Attributes: []string{s.cfg.EmailAttribute, s.cfg.NameAttribute},
+		SizeLimit:  2,
+	})
+	switch {
+	case go_ldap.IsErrorWithCode(err, go_ldap.LDAPResultSizeLimitExceeded):
+		s.logger.Warn("the user filter matches more than one entry", zap.String("username", username))
+		return nil, ErrInvalidCredentials
+	case err != nil:
+		return nil, fmt.Errorf("failed to search for the user: %w", err)
+	case len(result.Entries) == 0:
+		return nil, ErrInvalidCredentials
+	case len(result.Entries) > 1:
+		s.logger.Warn("the user filter matches more than one entry", zap.String("username", username))
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); go_ldap.IsErrorWithCode(err, go_ldap.LDAPResultInvalidCredentials) {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to bind as the user: %w", err)
+	}
+
+	dn := ldap.NormalizeDN(entry.DN)
+	identity, err := s.identityRepo.GetByDN(ctx, dn)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		identity = nil
+	default:
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	var user *users.User
+	if identity != nil {
+		if user, err = s.userService.GetByID(ctx, identity.UserID); err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	} else if user, err = s.createUser(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if identity == nil {
+		identity = &ldap.Identity{
+			ID:          uuid.NewString(),
+			DN:          dn,
+			UserID:      user.ID,
+			CreatedAt:   now,
+			LastLoginAt: now,
+		}
+		if err := s.identityRepo.Create(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to create identity: %w", err)
+		}
+	} else {
+		// the user id changes if the user has inherited another user since the last login
+		identity.UserID = user.ID
+		identity.LastLoginAt = now
+		if err := s.identityRepo.Update(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to update identity: %w", err)
+		}
+	}
+
+	if err := s.userService.Activate(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to activate user: %w", err)
+	}
+
+	return user, nil
+}
+
+// createUser creates a user for an entry that has never logged in before.
+func (s *Service) createUser(ctx context.Context, entry *go_ldap.Entry) (*users.User, error) {
+	email := strings.TrimSpace(entry.GetEqualFoldAttributeValue(s.cfg.EmailAttribute))
+	if email == "" {
+		return nil, ErrNoEmail
+	}
+
+	switch _, err := s.userService.GetByEmail(ctx, email); {
+	case err == nil:
+		return nil, ErrEmailTaken
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	name := entry.GetEqualFoldAttributeValue(s.cfg.NameAttribute)
+	if name == "" {
+		name = users.EmailToName(email)
+	}
+	user, err := s.userService.CreateVerified(ctx, name, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// Groups returns the names of the groups that the user was a member of, as of the last sync. Nothing is returned
+// if group sync is not configured.
+func (s *Service) Groups(ctx context.Context, userID users.ID) ([]string, error) {
+	if !s.GroupSyncEnabled() {
+		return nil, nil
+	}
+
+	groups, err := s.groupRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	// groups with the same name in different parts of the directory are the same acl group
+	unique := make(map[string]struct{})
+	for _, group := range groups {
+		unique[group.Name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListGroups returns the synced groups.
+func (s *Service) ListGroups(ctx context.Context) ([]*ldap.Group, error) {
+	if !s.GroupSyncEnabled() {
+		return nil, ErrGroupSyncDisabled
+	}
+	return s.groupRepo.List(ctx)
+}
+
+// ListMembers returns the users that are members of the group, as of the last sync.
+func (s *Service) ListMembers(ctx context.Context, groupID string) ([]users.ID, error) {
+	return s.groupRepo.ListMembers(ctx, groupID)
+}
+
+// LatestSync returns the last sync that was started, or sql.ErrNoRows if groups have never been synced.
+func (s *Service) LatestSync(ctx context.Context) (*ldap.Sync, error) {
+	if !s.GroupSyncEnabled() {
+		return nil, ErrGroupSyncDisabled
+	}
+	return s.syncRepo.GetLatest(ctx)
+}
@@ -0,0 +1,263 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"getsturdy.com/api/pkg/analytics/disabled"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/ldap/client"
+	"getsturdy.com/api/pkg/ldap/configuration"
+	db_ldap "getsturdy.com/api/pkg/ldap/db"
+	"getsturdy.com/api/pkg/ldap/ldaptest"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func person(uid, password string) *ldaptest.Entry {
+	attributes := map[string][]string{
+		"objectClass": {"inetOrgPerson"},
+		"uid":         {uid},
+		"cn":          {"User " + uid},
+		"mail":        {uid + "@example.com"},
+	}
+	if password != "" {
+		attributes["userPassword"] = []string{password}
+	}
+	return &ldaptest.Entry{DN: "uid=" + uid + ",ou=people,dc=example,dc=com", Attributes: attributes}
+}
+
+func group(cn string, memberUIDs ...string) *ldaptest.Entry {
+	var members []string
+	for _, uid := range memberUIDs {
+		members = append(members, "uid="+uid+",ou=people,dc=example,dc=com")
+	}
+	return &ldaptest.Entry{DN: "cn=" + cn + ",ou=groups,dc=example,dc=com", Attributes: map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {cn},
+		"member":      members,
+	}}
+}
+
+func newDirectory(entries ...*ldaptest.Entry) *ldaptest.Directory {
+	return ldaptest.NewDirectory(append([]*ldaptest.Entry{
+		{DN: "cn=sturdy,dc=example,dc=com", Attributes: map[string][]string{"userPassword": {"service-password"}}},
+	}, entries...)...)
+}
+
+func newConfiguration() *configuration.Configuration {
+	return &configuration.Configuration{
+		URL:                  "ldap://ldap.example.com",
+		BindDN:               "cn=sturdy,dc=example,dc=com",
+		BindPassword:         "service-password",
+		UserBaseDN:           "ou=people,dc=example,dc=com",
+		UserFilter:           "(&(objectClass=inetOrgPerson)(uid={username}))",
+		EmailAttribute:       "mail",
+		NameAttribute:        "cn",
+		GroupBaseDN:          "ou=groups,dc=example,dc=com",
+		GroupFilter:          "(objectClass=groupOfNames)",
+		GroupNameAttribute:   "cn",
+		GroupMemberAttribute: "member",
+	}
+}
+
+// newService returns a service that connects to a server for the directory over StartTLS. The url of the
+// configuration is replaced with the url of the server, unless it's empty.
+func newService(t *testing.T, cfg *configuration.Configuration, directory *ldaptest.Directory) (*service_ldap.Service, db_users.Repository) {
+	if cfg.URL != "" {
+		server := ldaptest.NewServer(t, directory)
+		cfg.URL = server.URL
+		cfg.CAFile = server.CAFile
+	}
+
+	logger := zap.NewNop()
+	analyticsService := service_analytics.New(logger, disabled.NewClient(logger))
+	userRepo := db_users.NewMemory()
+	userService := service_users.New(logger, userRepo, analyticsService)
+	return service_ldap.New(
+		logger,
+		cfg,
+		client.New(cfg),
+		db_ldap.NewMemoryIdentityRepository(),
+		db_ldap.NewMemoryGroupRepository(),
+		db_ldap.NewMemorySyncRepository(),
+		userService,
+	), userRepo
+}
+
+func TestLogin(t *testing.T) {
+	directory := newDirectory(
+		person("alice", "alice-password"),
+		person("bob", "bob-password"),
+		person("nopassword", ""),
+	)
+	service, userRepo := newService(t, newConfiguration(), directory)
+	ctx := context.Background()
+
+	t.Run("creates user", func(t *testing.T) {
+		user, err := service.Login(ctx, "alice", "alice-password")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, "User alice", user.Name)
+		assert.Equal(t, users.StatusActive, user.Status)
+
+		again, err := service.Login(ctx, " alice ", "alice-password")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, again.ID)
+	})
+
+	t.Run("does not link existing user", func(t *testing.T) {
+		existing := &users.User{ID: users.ID(uuid.NewString()), Email: "bob@example.com", Status: users.StatusActive}
+		require.NoError(t, userRepo.Create(existing))
+
+		_, err := service.Login(ctx, "bob", "bob-password")
+		assert.ErrorIs(t, err, service_ldap.ErrEmailTaken)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		for _, tc := range []struct{ username, password string }{
+			{"alice", "wrong"},
+			{"alice", ""},
+			{"nopassword", ""},
+			{"carol", "alice-password"},
+			{"*", "alice-password"},
+			{"alice)(uid=*", "alice-password"},
+		} {
+			_, err := service.Login(ctx, tc.username, tc.password)
+			assert.ErrorIs(t, err, service_ldap.ErrInvalidCredentials, tc.username)
+		}
+	})
+
+	t.Run("ambiguous filter", func(t *testing.T) {
+		cfg := newConfiguration()
+		cfg.UserFilter = "(|(uid={username})(objectClass=inetOrgPerson))"
+		service, _ := newService(t, cfg, directory)
+		_, err := service.Login(ctx, "alice", "alice-password")
+		assert.ErrorIs(t, err, service_ldap.ErrInvalidCredentials)
+	})
+
+	t.Run("wrong service account password", func(t *testing.T) {
+		cfg := newConfiguration()
+		cfg.BindPassword = "wrong"
+		service, _ := newService(t, cfg, directory)
+		_, err := service.Login(ctx, "alice", "alice-password")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, service_ldap.ErrInvalidCredentials)
+	})
+}
+
+func TestLogin_disabled(t *testing.T) {
+	service, _ := newService(t, &configuration.Configuration{}, newDirectory())
+	_, err := service.Login(context.Background(), "alice", "alice-password")
+	assert.ErrorIs(t, err, service_ldap.ErrDisabled)
+}
+
+func TestSync(t *testing.T) {
+	directory := newDirectory(
+		person("alice", "alice-password"),
+		person("bob", "bob-password"),
+		person("carol", "carol-password"),
+		group("engineering", "alice", "bob", "carol"),
+		group("admins", "alice"),
+	)
+	service, userRepo := newService(t, newConfiguration(), directory)
+	ctx := context.Background()
+
+	// alice and bob have logged in, carol has a user with the same email, but has never logged in with ldap
+	alice, err := service.Login(ctx, "alice", "alice-password")
+	require.NoError(t, err)
+	bob, err := service.Login(ctx, "bob", "bob-password")
+	require.NoError(t, err)
+	carol := &users.User{ID: users.ID(uuid.NewString()), Email: "carol@example.com", Status: users.StatusActive}
+	require.NoError(t, userRepo.Create(carol))
+
+	require.NoError(t, service.Sync(ctx))
+
+	groups, err := service.Groups(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins", "engineering"}, groups)
+
+	groups, err = service.Groups(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering"}, groups)
+
+	groups, err = service.Groups(ctx, carol.ID)
+	require.NoError(t, err)
+	assert.Empty(t, groups, "members are not matched by email")
+
+	synced, err := service.ListGroups(ctx)
+	require.NoError(t, err)
+	require.Len(t, synced, 2)
+	assert.Equal(t, "engineering", synced[1].Name)
+	assert.Equal(t, []string{"uid=carol,ou=people,dc=example,dc=com"}, []string(synced[1].UnmatchedMembers))
+	assert.ElementsMatch(t, []string{alice.ID.String(), bob.ID.String()}, []string(synced[1].AddedUserIDs))
+
+	latest, err := service.LatestSync(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, latest.FinishedAt)
+	assert.Nil(t, latest.Error)
+	assert.ElementsMatch(t, []string{"admins", "engineering"}, []string(latest.AddedGroups))
+
+	// alice leaves engineering, and the admins group is removed
+	directory.Add(group("engineering", "bob", "carol"))
+	directory.Remove("cn=admins,ou=groups,dc=example,dc=com")
+
+	require.NoError(t, service.Sync(ctx))
+
+	groups, err = service.Groups(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	synced, err = service.ListGroups(ctx)
+	require.NoError(t, err)
+	require.Len(t, synced, 1)
+	assert.Empty(t, synced[0].AddedUserIDs)
+	assert.Equal(t, []string{alice.ID.String()}, []string(synced[0].RemovedUserIDs))
+
+	members, err := service.ListMembers(ctx, synced[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []users.ID{bob.ID}, members)
+
+	latest, err = service.LatestSync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, latest.AddedGroups)
+	assert.Equal(t, []string{"admins"}, []string(latest.RemovedGroups))
+}
+
+func TestSync_error(t *testing.T) {
+	directory := newDirectory(group("engineering"))
+	service, _ := newService(t, newConfiguration(), directory)
+	ctx := context.Background()
+
+	require.NoError(t, service.Sync(ctx))
+
+	// groups are kept as they were if the directory can't be searched
+	directory.Close()
+	assert.Error(t, service.Sync(ctx))
+
+	latest, err := service.LatestSync(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, latest.Error)
+
+	groups, err := service.ListGroups(ctx)
+	require.NoError(t, err)
+	assert.Len(t, groups, 1)
+}
+
+func TestSync_disabled(t *testing.T) {
+	cfg := newConfiguration()
+	cfg.GroupBaseDN = ""
+	service, _ := newService(t, cfg, newDirectory())
+	assert.ErrorIs(t, service.Sync(context.Background()), service_ldap.ErrGroupSyncDisabled)
+
+	groups, err := service.Groups(context.Background(), users.ID(uuid.NewString()))
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/ldap"
+	"getsturdy.com/api/pkg/users"
+
+	go_ldap "github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Sync syncs the groups, and their members, from the directory. Every run is recorded, including the error if it
+// failed, so that admins can see if the groups are out of date.
+//
+// Members are matched to users by the DN that they logged in with. Members that can't be matched (because they have
+// never logged in with LDAP, or because they are nested groups) are recorded on the group.
+func (s *Service) Sync(ctx context.Context) error {
+	if !s.GroupSyncEnabled() {
+		return ErrGroupSyncDisabled
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	run := &ldap.Sync{
+		ID:        uuid.NewString(),
+		StartedAt: time.Now(),
+	}
+	if err := s.syncRepo.Create(ctx, run); err != nil {
+		return fmt.Errorf("failed to create sync: %w", err)
+	}
+
+	syncErr := s.sync(ctx, run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if syncErr != nil {
+		msg := syncErr.Error()
+		run.Error = &msg
+	}
+	if err := s.syncRepo.Update(ctx, run); err != nil {
+		return fmt.Errorf("failed to update sync: %w", err)
+	}
+
+	return syncErr
+}
+
+func (s *Service) sync(ctx context.Context, run *ldap.Sync) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := conn.Search(&go_ldap.SearchRequest{
+		BaseDN:     s.cfg.GroupBaseDN,
+		Scope:      go_ldap.ScopeWholeSubtree,
+		Filter:     s.cfg.GroupFilter,
+		Attributes: []string{s.cfg.GroupNameAttribute, s.cfg.GroupMemberAttribute},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search for groups: %w", err)
+	}
+
+	existing, err := s.groupRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list groups: %w", err)
+	}
+	existingByDN := make(map[string]*ldap.Group, len(existing))
+	for _, group := range existing {
+		existingByDN[group.DN] = group
+	}
+
+	resolver := &memberResolver{service: s, cache: make(map[string]*users.ID)}
+	seen := make(map[string]bool, len(result.Entries))
+	for _, entry := range result.Entries {
+		dn := ldap.NormalizeDN(entry.DN)
+		if seen[dn] {
+			continue
+		}
+		name := entry.GetEqualFoldAttributeValue(s.cfg.GroupNameAttribute)
+		if name == "" {
+			s.logger.Warn("skipping group without a name", zap.String("dn", entry.DN))
+			continue
+		}
+		seen[dn] = true
+
+		group, ok := existingByDN[dn]
+		if !ok {
+			group = &ldap.Group{
+				ID:        uuid.NewString(),
+				DN:        dn,
+				CreatedAt: time.Now(),
+			}
+			run.AddedGroups = append(run.AddedGroups, name)
+		}
+		group.Name = name
+
+		if err := s.syncGroup(ctx, resolver, group, entry.GetEqualFoldAttributeValues(s.cfg.GroupMemberAttribute), !ok); err != nil {
+			return fmt.Errorf("failed to sync group %s: %w", entry.DN, err)
+		}
+	}
+
+	for _, group := range existing {
+		if seen[group.DN] {
+			continue
+		}
+		if err := s.groupRepo.Delete(ctx, group.ID); err != nil {
+			return fmt.Errorf("failed to delete group %s: %w", group.DN, err)
+		}
+		run.RemovedGroups = append(run.RemovedGroups, group.Name)
+	}
+
+	return nil
+}
+
+// syncGroup makes the members of the group match memberDNs, and records what changed.
+func (s *Service) syncGroup(ctx context.Context, resolver *memberResolver, group *ldap.Group, memberDNs []string, isNew bool) error {
+	var members []users.ID
+	want := make(map[users.ID]bool, len(memberDNs))
+	unmatched := []string{}
+	for _, memberDN := range memberDNs {
+		userID, err := resolver.resolve(ctx, memberDN)
+		if err != nil {
+			return err
+		}
+		if userID == nil {
+			unmatched = append(unmatched, memberDN)
+			continue
+		}
+		if !want[*userID] {
+			want[*userID] = true
+			members = append(members, *userID)
+		}
+	}
+
+	var current []users.ID
+	if !isNew {
+		var err error
+		if current, err = s.groupRepo.ListMembers(ctx, group.ID); err != nil {
+			return fmt.Errorf("failed to list members: %w", err)
+		}
+	}
+
+	added, removed := []string{}, []string{}
+	have := make(map[users.ID]bool, len(current))
+	for _, userID := range current {
+		have[userID] = true
+		if want[userID] {
+			continue
+		}
+		if err := s.groupRepo.RemoveMember(ctx, group.ID, userID); err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		removed = append(removed, userID.String())
+	}
+	for _, userID := range members {
+		if have[userID] {
+			continue
+		}
+		if err := s.groupRepo.AddMember(ctx, group.ID, userID); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		added = append(added, userID.String())
+	}
+
+	group.UnmatchedMembers = unmatched
+	group.AddedUserIDs = added
+	group.RemovedUserIDs = removed
+	group.SyncedAt = time.Now()
+
+	if isNew {
+		if err := s.groupRepo.Create(ctx, group); err != nil {
+			return fmt.Errorf("failed to create group: %w", err)
+		}
+		return nil
+	}
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	return nil
+}
+
+// memberResolver matches the DNs of group members to users. Results are cached, as users are usually members of
+// many groups.
+type memberResolver struct {
+	service *Service
+	cache   map[string]*users.ID
+}
+
+// resolve returns the id of the user with the given DN, or nil if there is no such user.
+func (r *memberResolver) resolve(ctx context.Context, memberDN string) (*users.ID, error) {
+	dn := ldap.NormalizeDN(memberDN)
+	if userID, ok := r.cache[dn]; ok {
+		return userID, nil
+	}
+
+	userID, err := r.lookup(ctx, memberDN)
+	if err != nil {
+		return nil, err
+	}
+	r.cache[dn] = userID
+	return userID, nil
+}
+
+// lookup returns the user that has logged in with the DN. Members are not matched by the email in the directory, as
+// it's not proof that the member owns the email.
+func (r *memberResolver) lookup(ctx context.Context, memberDN string) (*users.ID, error) {
+	identity, err := r.service.identityRepo.GetByDN(ctx, ldap.NormalizeDN(memberDN))
+	switch {
+	case err == nil:
+		return &identity.UserID, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+}
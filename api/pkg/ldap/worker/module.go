@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"getsturdy.com/api/pkg/di"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	"getsturdy.com/api/pkg/logger"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(service_ldap.Module)
+	c.Register(New)
+}
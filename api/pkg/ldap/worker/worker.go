@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+
+	"go.uber.org/zap"
+)
+
+// Worker periodically syncs groups from the directory.
+type Worker struct {
+	logger      *zap.Logger
+	ldapService *service_ldap.Service
+}
+
+func New(
+	logger *zap.Logger,
+	ldapService *service_ldap.Service,
+) *Worker {
+	return &Worker{
+		logger:      logger.Named("ldap_worker"),
+		ldapService: ldapService,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	if !w.ldapService.GroupSyncEnabled() {
+		return nil
+	}
+
+	w.logger.Info("starting")
+
+	w.sync(ctx)
+
+	ticker := time.NewTicker(w.ldapService.GroupSyncInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.sync(ctx)
+		case <-ctx.Done():
+			w.logger.Info("stopping")
+			return nil
+		}
+	}
+}
+
+// sync syncs the groups, failures are recorded by the service, and retried on the next tick.
+func (w *Worker) sync(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.ldapService.GroupSyncInterval())
+	defer cancel()
+
+	if err := w.ldapService.Sync(ctx); err != nil {
+		w.logger.Error("failed to sync groups", zap.Error(err))
+	}
+}
@@ -0,0 +1,5 @@
+package configuration
+
+type Configuration struct {
+	SSHSecret string `long:"ssh-secret" description:"secret shared with the ssh server to sign requests from server-side mutagen, server-side mutagen requests are rejected if empty"`
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/auth/signed"
+	"getsturdy.com/api/pkg/mutagen/configuration"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ServerAuth authenticates requests from server-side mutagen. Mutagen agents are started by the ssh server, and sign
+// their requests on behalf of the user that the ssh connection was authenticated as.
+func ServerAuth(logger *zap.Logger, cfg *configuration.Configuration) gin.HandlerFunc {
+	logger = logger.Named("mutagenServerAuth")
+	return func(c *gin.Context) {
+		if cfg.SSHSecret == "" {
+			logger.Error("mutagen ssh secret is not configured")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		userID, err := signed.Verify(c.Request, []byte(cfg.SSHSecret), time.Now())
+		if err != nil {
+			logger.Warn("mutagen request verification failed", zap.Error(err))
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), &auth.Subject{
+			ID:   userID.String(),
+			Type: auth.SubjectMutagen,
+		}))
+
+		c.Next()
+	}
+}
+
+// isMutagenUser returns true if the request is made by server-side mutagen on behalf of userID.
+func isMutagenUser(ctx context.Context, userID users.ID) bool {
+	subject, ok := auth.FromContext(ctx)
+	return ok && subject.Type == auth.SubjectMutagen && subject.ID == userID.String()
+}
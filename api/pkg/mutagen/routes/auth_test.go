@@ -0,0 +1,83 @@
+//nolint:bodyclose
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/auth/signed"
+	"getsturdy.com/api/pkg/mutagen/configuration"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestServerAuth(t *testing.T) {
+	userID := users.ID(uuid.NewString())
+
+	tests := []struct {
+		name           string
+		cfg            *configuration.Configuration
+		sign           func(*http.Request)
+		expectedStatus int
+	}{
+		{
+			name: "signed",
+			cfg:  &configuration.Configuration{SSHSecret: "secret"},
+			sign: func(r *http.Request) {
+				assert.NoError(t, signed.Sign(r, []byte("secret"), userID.String(), time.Now()))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not signed",
+			cfg:            &configuration.Configuration{SSHSecret: "secret"},
+			sign:           func(r *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong secret",
+			cfg:  &configuration.Configuration{SSHSecret: "secret"},
+			sign: func(r *http.Request) {
+				assert.NoError(t, signed.Sign(r, []byte("wrong"), userID.String(), time.Now()))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "not configured",
+			cfg:  &configuration.Configuration{},
+			sign: func(r *http.Request) {
+				assert.NoError(t, signed.Sign(r, []byte(""), userID.String(), time.Now()))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/v3/mutagen/views/:id/allows", ServerAuth(zap.NewNop(), tc.cfg), func(c *gin.Context) {
+				assert.True(t, isMutagenUser(c.Request.Context(), userID))
+				assert.False(t, isMutagenUser(c.Request.Context(), users.ID(uuid.NewString())))
+
+				_, err := auth.UserID(c.Request.Context())
+				assert.ErrorIs(t, err, auth.ErrUnauthenticated, "mutagen requests must not be authenticated as the user")
+
+				c.Status(http.StatusOK)
+			})
+
+			r := httptest.NewRequest("GET", "/v3/mutagen/views/view-id/allows", nil)
+			tc.sign(r)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"net/http"
 
-	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/ctxlog"
 
@@ -41,6 +40,7 @@ func ListAllows(
 	}
 
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		viewID := c.Param("id")
 
 		viewObj, err := viewRepo.Get(viewID)
@@ -55,13 +55,12 @@ func ListAllows(
 			return
 		}
 
-		// TODO: Authenticate internal requests
-		ctx := auth.NewContext(c.Request.Context(), &auth.Subject{
-			ID:   viewObj.UserID.String(),
-			Type: auth.SubjectMutagen,
-		})
+		if !isMutagenUser(ctx, viewObj.UserID) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
 
-		allower, err := authService.GetAllower(ctx, &codebases.Codebase{ID: viewObj.CodebaseID})
+		allower, err := authService.GetWriteAllower(ctx, &codebases.Codebase{ID: viewObj.CodebaseID})
 		if err != nil {
 			ctxlog.ErrorOrWarn(logger, "failed to list allowed pattern", err)
 			c.AbortWithStatus(http.StatusInternalServerError)
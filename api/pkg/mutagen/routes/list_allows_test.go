@@ -23,6 +23,11 @@ import (
 	"getsturdy.com/api/pkg/codebases/acl"
 	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
 	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
+	configuration_ldap "getsturdy.com/api/pkg/ldap/configuration"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	configuration_oidc "getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
 	"getsturdy.com/api/pkg/users"
 	db_users "getsturdy.com/api/pkg/users/db"
 	service_user "getsturdy.com/api/pkg/users/service"
@@ -42,11 +47,16 @@ func TestListAllows(t *testing.T) {
 	)
 
 	aclProvider := provider_acl.New(
+		zap.NewNop(),
 		aclRepo,
 		nil,
 		nil,
+		nil,
 	)
 
+	oidcService := service_oidc.New(zap.NewNop(), &configuration_oidc.Configuration{}, db_oidc.NewMemory(), userService, nil)
+	ldapService := service_ldap.New(zap.NewNop(), &configuration_ldap.Configuration{}, nil, nil, nil, nil, nil)
+
 	authService := service_auth.New(
 		nil,
 		nil,
@@ -54,6 +64,10 @@ func TestListAllows(t *testing.T) {
 		nil,
 		aclProvider,
 		nil,
+		oidcService,
+		nil,
+		ldapService,
+		nil,
 	)
 
 	type listAllowsResponse struct {
@@ -185,7 +199,7 @@ func requestWithParams(t *testing.T, userID users.ID, route func(*gin.Context),
 	assert.NoError(t, err)
 
 	c.Request, err = http.NewRequest("POST", "/", bytes.NewReader(data))
-	c.Request = c.Request.WithContext(auth.NewContext(context.Background(), &auth.Subject{ID: userID.String(), Type: auth.SubjectUser}))
+	c.Request = c.Request.WithContext(auth.NewContext(context.Background(), &auth.Subject{ID: userID.String(), Type: auth.SubjectMutagen}))
 	assert.NoError(t, err)
 	route(c)
 	assert.Equal(t, http.StatusOK, res.Result().StatusCode)
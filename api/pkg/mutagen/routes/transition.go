@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
@@ -35,8 +37,6 @@ func SyncTransitions(
 	eventSender *eventsv2.Publisher,
 ) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		// TODO: Authenticate internal requests
-
 		ctx := c.Request.Context()
 
 		var req SyncTransitionsRequest
@@ -54,12 +54,20 @@ func SyncTransitions(
 		}
 
 		view, err := viewRepo.Get(req.ViewID)
-		if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		} else if err != nil {
 			logger.Error("could not get view", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
+		if !isMutagenUser(ctx, view.UserID) || view.CodebaseID != req.CodebaseID {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
 		if err := eventSender.ViewUpdated(ctx, eventsv2.Codebase(req.CodebaseID), view); err != nil {
 			logger.Error("failed to send view updated event", zap.Error(err))
 			// do not fail
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"getsturdy.com/api/pkg/auth"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/mutagen"
 	"getsturdy.com/api/pkg/mutagen/db"
@@ -42,12 +43,17 @@ type receiverStatus struct {
 	Total    uint64 `json:"total,omitempty"`
 }
 
-// This endpoint is unauthenticated
-// TODO: Find a way to propagate the auth context to the mutagen client
+// UpdateStatus is called by client-side mutagen, on behalf of the owner of the view.
 func UpdateStatus(logger *zap.Logger, viewStatusRepo db.ViewStatusRepository, viewRepo db_view.Repository, eventsSender *eventsv2.Publisher) func(*gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
+		userID, err := auth.UserID(ctx)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		var input stateStatus
 		if err := c.BindJSON(&input); err != nil {
 			logger.Warn("failed to read status", zap.Error(err))
@@ -64,6 +70,21 @@ func UpdateStatus(logger *zap.Logger, viewStatusRepo db.ViewStatusRepository, vi
 		}
 		viewID := input.Name[len(prefix):]
 
+		vw, err := viewRepo.Get(viewID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.Status(http.StatusNotFound)
+			return
+		} else if err != nil {
+			logger.Error("failed to get view", zap.Error(err))
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		if vw.UserID != userID {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
 		status, err := viewStatusRepo.GetByViewID(viewID)
 		if errors.Is(err, sql.ErrNoRows) {
 			status := mutagen.ViewStatus{ID: viewID}
@@ -91,16 +112,6 @@ func UpdateStatus(logger *zap.Logger, viewStatusRepo db.ViewStatusRepository, vi
 		}
 
 		// Send event
-		vw, err := viewRepo.Get(viewID)
-		if errors.Is(err, sql.ErrNoRows) {
-			c.Status(http.StatusNotFound)
-			return
-		} else if err != nil {
-			logger.Error("failed to get view", zap.Error(err))
-			c.Status(http.StatusInternalServerError)
-			return
-		}
-
 		if err := eventsSender.ViewStatusUpdated(ctx, eventsv2.Codebase(vw.CodebaseID), vw); err != nil {
 			logger.Error("failed to send event", zap.Error(err))
 			// do not fail
@@ -9,6 +9,7 @@ import (
 	"getsturdy.com/api/pkg/codebases"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/users"
+	db_user "getsturdy.com/api/pkg/users/db"
 	db_view "getsturdy.com/api/pkg/views/db"
 
 	"github.com/gin-gonic/gin"
@@ -22,7 +23,7 @@ type ValidateViewRequest struct {
 	IsNewConnection bool         `json:"is_new_connection"`
 }
 
-func ValidateView(logger *zap.Logger, viewRepo db_view.Repository, analyticsService *service_analytics.Service, eventsSender *eventsv2.Publisher) func(c *gin.Context) {
+func ValidateView(logger *zap.Logger, viewRepo db_view.Repository, userRepo db_user.Repository, analyticsService *service_analytics.Service, eventsSender *eventsv2.Publisher) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
@@ -35,6 +36,13 @@ func ValidateView(logger *zap.Logger, viewRepo db_view.Repository, analyticsServ
 
 		logger.Info("validate", zap.Any("req", req))
 
+		// the view can only be connected to on behalf of its owner
+		if !isMutagenUser(ctx, req.UserID) {
+			logger.Warn("user did not match the signed user", zap.Stringer("user_id", req.UserID))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user not found"})
+			return
+		}
+
 		viewObj, err := viewRepo.Get(req.ViewID)
 		if err != nil {
 			logger.Warn("view not found", zap.Error(err))
@@ -54,6 +62,19 @@ func ValidateView(logger *zap.Logger, viewRepo db_view.Repository, analyticsServ
 			return
 		}
 
+		// views of deactivated users can't be connected to
+		user, err := userRepo.Get(viewObj.UserID)
+		if err != nil {
+			logger.Error("failed to get user", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if user.Status == users.StatusDeactivated {
+			logger.Warn("user is deactivated", zap.Stringer("user_id", user.ID))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user is deactivated"})
+			return
+		}
+
 		// Set LastUsedAt
 		t := time.Now()
 		viewObj.LastUsedAt = &t
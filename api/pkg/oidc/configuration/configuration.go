@@ -0,0 +1,17 @@
+package configuration
+
+type Configuration struct {
+	IssuerURL         string   `long:"issuer-url" description:"OpenID Connect issuer URL, single sign-on is enabled if it's set"`
+	ClientID          string   `long:"client-id" description:"OpenID Connect client ID"`
+	ClientSecret      string   `long:"client-secret" description:"OpenID Connect client secret"`
+	RedirectURL       string   `long:"redirect-url" description:"URL that the provider redirects to after login, must point to /v3/auth/oidc/callback of the API"`
+	Scopes            []string `long:"scope" description:"Additional scope to request (can be provided multiple times)"`
+	AllowedDomains    []string `long:"allowed-domain" description:"Email domain that is allowed to sign in (can be provided multiple times), all domains are allowed if not set. Existing users are only linked to the provider by email if this is set"`
+	SigningAlgorithms []string `long:"signing-algorithm" description:"Algorithm that the provider signs id tokens with (can be provided multiple times)" default:"RS256"`
+	GroupsClaim       string   `long:"groups-claim" description:"ID token claim with the groups of the user, groups can be used as groups::<name> in access control lists"`
+	LoginRedirectURL  string   `long:"login-redirect-url" description:"URL to redirect to after a successful login" default:"/"`
+}
+
+func (c *Configuration) Enabled() bool {
+	return c != nil && c.IssuerURL != ""
+}
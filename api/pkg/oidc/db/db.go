@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type database struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repository {
+	return &database{db: db}
+}
+
+func (d *database) Create(ctx context.Context, identity *oidc.Identity) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO oidc_identities (
+			id,
+			issuer,
+			subject,
+			user_id,
+			groups,
+			created_at,
+			last_login_at
+		) VALUES (
+			:id,
+			:issuer,
+			:subject,
+			:user_id,
+			:groups,
+			:created_at,
+			:last_login_at
+		)
+	`, identity); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, identity *oidc.Identity) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE oidc_identities SET
+			user_id = :user_id,
+			groups = :groups,
+			last_login_at = :last_login_at
+		WHERE id = :id
+	`, identity); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) GetByIssuerAndSubject(ctx context.Context, issuer, subject string) (*oidc.Identity, error) {
+	var identity oidc.Identity
+	if err := d.db.GetContext(ctx, &identity, `
+		SELECT
+			id,
+			issuer,
+			subject,
+			user_id,
+			groups,
+			created_at,
+			last_login_at
+		FROM oidc_identities
+		WHERE issuer = $1 AND subject = $2
+	`, issuer, subject); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &identity, nil
+}
+
+func (d *database) ListByUserID(ctx context.Context, userID users.ID) ([]*oidc.Identity, error) {
+	var identities []*oidc.Identity
+	if err := d.db.SelectContext(ctx, &identities, `
+		SELECT
+			id,
+			issuer,
+			subject,
+			user_id,
+			groups,
+			created_at,
+			last_login_at
+		FROM oidc_identities
+		WHERE user_id = $1
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return identities, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ Repository = &Memory{}
+
+type Memory struct {
+	byID map[string]*oidc.Identity
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		byID: make(map[string]*oidc.Identity),
+	}
+}
+
+func (m *Memory) Create(_ context.Context, identity *oidc.Identity) error {
+	m.byID[identity.ID] = identity
+	return nil
+}
+
+func (m *Memory) Update(_ context.Context, identity *oidc.Identity) error {
+	m.byID[identity.ID] = identity
+	return nil
+}
+
+func (m *Memory) GetByIssuerAndSubject(_ context.Context, issuer, subject string) (*oidc.Identity, error) {
+	for _, identity := range m.byID {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *Memory) ListByUserID(_ context.Context, userID users.ID) ([]*oidc.Identity, error) {
+	var identities []*oidc.Identity
+	for _, identity := range m.byID {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	Create(context.Context, *oidc.Identity) error
+	Update(context.Context, *oidc.Identity) error
+	GetByIssuerAndSubject(ctx context.Context, issuer, subject string) (*oidc.Identity, error)
+	ListByUserID(context.Context, users.ID) ([]*oidc.Identity, error)
+}
@@ -0,0 +1,30 @@
+package oidc
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+// Identity links a user to their account at an OpenID Connect provider.
+type Identity struct {
+	ID      string   `db:"id"`
+	Issuer  string   `db:"issuer"`
+	Subject string   `db:"subject"`
+	UserID  users.ID `db:"user_id"`
+	// Groups are the groups of the user at the provider, as of the last login.
+	Groups      pq.StringArray `db:"groups"`
+	CreatedAt   time.Time      `db:"created_at"`
+	LastLoginAt time.Time      `db:"last_login_at"`
+}
+
+// Claims are the claims of a verified ID token that are used to sign in.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"getsturdy.com/api/pkg/auth"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Callback is where the OpenID Connect provider redirects the user after they have signed in. The user is logged in
+// to Sturdy, and redirected to the web app.
+func Callback(logger *zap.Logger, oidcService *service_oidc.Service, jwtService *service_jwt.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !oidcService.Enabled() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		state, nonce, verifier, err := loginCookie(c)
+		// the login can only be completed once
+		setLoginCookie(c, oidcService, "", -1)
+		if err != nil {
+			logger.Warn("failed to get login state", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "login has expired, please try again"})
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid state"})
+			return
+		}
+
+		if errCode := c.Query("error"); errCode != "" {
+			logger.Warn("login failed at the identity provider", zap.String("error", errCode), zap.String("description", c.Query("error_description")))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "login failed at the identity provider"})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		claims, err := oidcService.Exchange(ctx, c.Query("code"), nonce, verifier)
+		if errors.Is(err, service_oidc.ErrInvalidToken) {
+			logger.Warn("invalid id token", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid id token"})
+			return
+		} else if err != nil {
+			logger.Error("failed to exchange code", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "failed to login with the identity provider"})
+			return
+		}
+
+		user, err := oidcService.Login(ctx, claims)
+		switch {
+		case err == nil:
+		case errors.Is(err, service_oidc.ErrDomainNotAllowed):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "users with this email domain are not allowed to login"})
+			return
+		case errors.Is(err, service_oidc.ErrUserExists):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a user with this email address already exists, and can not be linked to the identity provider"})
+			return
+		case errors.Is(err, service_oidc.ErrEmailNotVerified):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the email address has not been verified by the identity provider"})
+			return
+		case errors.Is(err, service_user.ErrDeactivated):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user is deactivated"})
+			return
+		case errors.Is(err, service_user.ErrExceeded):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "This service is exceeding the number of users allowed. Please contact the server administrator or email support@getsturdy.com"})
+			return
+		default:
+			logger.Error("failed to login", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := auth.SetAuthCookieForUser(c, user.ID, jwtService); err != nil {
+			logger.Error("failed to set auth cookie", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Redirect(http.StatusFound, oidcService.LoginRedirectURL())
+	}
+}
@@ -0,0 +1,86 @@
+package routes
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// loginCookieName is the cookie that holds the state, nonce and pkce verifier of a login that is in progress
+	loginCookieName = "oidc_login"
+	loginMaxAge     = 10 * 60 // 10 minutes
+)
+
+// Login redirects the user to the OpenID Connect provider to sign in.
+func Login(logger *zap.Logger, oidcService *service_oidc.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !oidcService.Enabled() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		// state, nonce and pkce verifier
+		values := make([]string, 3)
+		for i := range values {
+			var err error
+			if values[i], err = randomString(); err != nil {
+				logger.Error("failed to generate login state", zap.Error(err))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+		state, nonce, verifier := values[0], values[1], values[2]
+
+		redirectURL, err := oidcService.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+		if err != nil {
+			logger.Error("failed to get auth code url", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "the identity provider is unavailable, try again later"})
+			return
+		}
+
+		setLoginCookie(c, oidcService, strings.Join(values, "."), loginMaxAge)
+
+		c.Redirect(http.StatusFound, redirectURL)
+	}
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func setLoginCookie(c *gin.Context, oidcService *service_oidc.Service, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     loginCookieName,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     "/",                  // the api might be served under a prefix
+		SameSite: http.SameSiteLaxMode, // must be sent when the provider redirects back
+		Secure:   c.Request.TLS != nil || oidcService.SecureCookies(),
+		HttpOnly: true,
+	})
+}
+
+func loginCookie(c *gin.Context) (state, nonce, verifier string, err error) {
+	cookie, err := c.Request.Cookie(loginCookieName)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get login cookie: %w", err)
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return "", "", "", errors.New("malformed login cookie")
+	}
+	return parts[0], parts[1], parts[2], nil
+}
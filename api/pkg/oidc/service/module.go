@@ -0,0 +1,17 @@
+package service
+
+import (
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_user "getsturdy.com/api/pkg/users/service/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(db_oidc.Module)
+	c.Import(service_user.Module)
+	c.Import(service_analytics.Module)
+	c.Register(New)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	jose_jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+// provider is an OpenID Connect provider, configured with it's discovery document.
+type provider struct {
+	httpClient *http.Client
+
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keysMu sync.Mutex
+	keys   *jose.JSONWebKeySet
+}
+
+func discover(ctx context.Context, httpClient *http.Client, issuerURL string) (*provider, error) {
+	p := &provider{httpClient: httpClient}
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, p); err != nil {
+		return nil, fmt.Errorf("failed to get discovery document: %w", err)
+	}
+
+	// the issuer must be exactly the same as in the id tokens
+	if p.Issuer != issuerURL {
+		return nil, fmt.Errorf("issuer %q does not match the configured issuer %q", p.Issuer, issuerURL)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing endpoints")
+	}
+
+	return p, nil
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// key returns the key with the given id, the keys are fetched again if it's not found, in case they have been
+// rotated.
+func (p *provider) key(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	if p.keys != nil {
+		if keys := p.keys.Key(keyID); len(keys) > 0 {
+			return &keys[0], nil
+		}
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := p.getJSON(ctx, p.JWKSURI, &keys); err != nil {
+		return nil, fmt.Errorf("failed to get keys: %w", err)
+	}
+	p.keys = &keys
+
+	if keys := p.keys.Key(keyID); len(keys) > 0 {
+		return &keys[0], nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, keyID)
+}
+
+type idTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// verify verifies the signature and the claims of the id token, and returns all of it's claims. The token must be
+// signed with one of the algorithms.
+func (p *provider) verify(ctx context.Context, rawIDToken, clientID, nonce string, algorithms []string) (*jose_jwt.Claims, *idTokenClaims, map[string]any, error) {
+	token, err := jose_jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	if len(token.Headers) != 1 {
+		return nil, nil, nil, fmt.Errorf("%w: unexpected number of signatures", ErrInvalidToken)
+	}
+	header := token.Headers[0]
+
+	// the algorithm is chosen by whoever created the token, it can't be trusted unless it's the expected one
+	if !contains(algorithms, header.Algorithm) {
+		return nil, nil, nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	key, err := p.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		return nil, nil, nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+	if key.Use != "" && key.Use != "sig" {
+		return nil, nil, nil, fmt.Errorf("%w: key %q is not a signing key", ErrInvalidToken, header.KeyID)
+	}
+
+	stdClaims := &jose_jwt.Claims{}
+	claims := &idTokenClaims{}
+	allClaims := map[string]any{}
+	if err := token.Claims(key.Public(), stdClaims, claims, &allClaims); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if err := stdClaims.ValidateWithLeeway(jose_jwt.Expected{
+		Issuer:   p.Issuer,
+		Audience: jose_jwt.Audience{clientID},
+		Time:     time.Now(),
+	}, time.Minute); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	if stdClaims.Expiry == nil {
+		return nil, nil, nil, fmt.Errorf("%w: token does not expire", ErrInvalidToken)
+	}
+	if stdClaims.Subject == "" {
+		return nil, nil, nil, fmt.Errorf("%w: subject is missing", ErrInvalidToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, nil, nil, fmt.Errorf("%w: nonce does not match", ErrInvalidToken)
+	}
+
+	return stdClaims, claims, allClaims, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// emailVerified returns true if the email_verified claim is true. Some providers send it as a string.
+func emailVerified(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// groups returns the groups in the claim, the claim can either be a list or a single string.
+func groups(claim any) ([]string, error) {
+	switch v := claim.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			s, ok := g.(string)
+			if !ok {
+				return nil, errors.New("groups must be strings")
+			}
+			groups = append(groups, s)
+		}
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("unexpected type of groups: %T", claim)
+	}
+}
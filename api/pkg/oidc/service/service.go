@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/analytics"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2"
+)
+
+var (
+	ErrDisabled         = errors.New("single sign-on is not enabled")
+	ErrInvalidToken     = errors.New("invalid id token")
+	ErrDomainNotAllowed = errors.New("email domain is not allowed")
+	ErrEmailNotVerified = errors.New("email is not verified")
+	ErrUserExists       = errors.New("a user with the email already exists")
+)
+
+type Service struct {
+	logger           *zap.Logger
+	cfg              *configuration.Configuration
+	httpClient       *http.Client
+	identityRepo     db_oidc.Repository
+	userService      service_user.Service
+	analyticsService *service_analytics.Service
+
+	providerMu sync.Mutex
+	provider   *provider
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *configuration.Configuration,
+	identityRepo db_oidc.Repository,
+	userService service_user.Service,
+	analyticsService *service_analytics.Service,
+) *Service {
+	return &Service{
+		logger:           logger.Named("oidc"),
+		cfg:              cfg,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		identityRepo:     identityRepo,
+		userService:      userService,
+		analyticsService: analyticsService,
+	}
+}
+
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// LoginRedirectURL is where users are redirected to after they have logged in.
+func (s *Service) LoginRedirectURL() string {
+	return s.cfg.LoginRedirectURL
+}
+
+// SecureCookies returns true if the redirect url is https. The redirect url is the public address of the api, so it's
+// https even if tls is terminated by a proxy in front of the api.
+func (s *Service) SecureCookies() bool {
+	u, err := url.Parse(s.cfg.RedirectURL)
+	return err == nil && u.Scheme == "https"
+}
+
+// getProvider returns the configured provider. The discovery document is fetched on first use, and again if it
+// failed, so that the api can start while the provider is unavailable.
+func (s *Service) getProvider(ctx context.Context) (*provider, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	s.providerMu.Lock()
+	defer s.providerMu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+
+	p, err := discover(ctx, s.httpClient, s.cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+	s.provider = p
+	return p, nil
+}
+
+func (s *Service) oauth2Config(p *provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.AuthorizationEndpoint,
+			TokenURL: p.TokenEndpoint,
+		},
+		Scopes: append([]string{"openid", "email", "profile"}, s.cfg.Scopes...),
+	}
+}
+
+// AuthCodeURL returns the url of the provider to redirect the user to. The verifier is used for PKCE, and must be
+// passed to Exchange together with the nonce.
+func (s *Service) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return s.oauth2Config(p).AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange exchanges the authorization code for an id token, and returns it's verified claims.
+func (s *Service) Exchange(ctx context.Context, code, nonce, verifier string) (*oidc.Claims, error) {
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.oauth2Config(p).Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, s.httpClient),
+		code,
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no id token in the response", ErrInvalidToken)
+	}
+
+	stdClaims, idClaims, allClaims, err := p.verify(ctx, rawIDToken, s.cfg.ClientID, nonce, s.signingAlgorithms())
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidc.Claims{
+		Subject:       stdClaims.Subject,
+		Email:         idClaims.Email,
+		EmailVerified: emailVerified(idClaims.EmailVerified),
+		Name:          idClaims.Name,
+	}
+
+	if s.cfg.GroupsClaim != "" {
+		if claims.Groups, err = groups(allClaims[s.cfg.GroupsClaim]); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s claim: %s", ErrInvalidToken, s.cfg.GroupsClaim, err)
+		}
+	}
+
+	return claims, nil
+}
+
+// Login returns the user that the claims belong to.
+//
+// Users that have logged in before are found by their subject. Otherwise, the user is linked to an existing user
+// with the same (verified) email, or a new user is created. Invited users that have never logged in are shadow
+// users, they are activated.
+//
+// Existing users are only linked if the allowed domains are configured, which means that the provider is trusted to
+// own the email addresses of those domains. Otherwise, anyone that can set their email at the provider could take
+// over the user, and ErrUserExists is returned.
+func (s *Service) Login(ctx context.Context, claims *oidc.Claims) (*users.User, error) {
+	if !s.isAllowedDomain(claims.Email) {
+		return nil, ErrDomainNotAllowed
+	}
+
+	identity, err := s.identityRepo.GetByIssuerAndSubject(ctx, s.cfg.IssuerURL, claims.Subject)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		identity = nil
+	default:
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	var user *users.User
+	if identity != nil {
+		if user, err = s.userService.GetByID(ctx, identity.UserID); err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	} else if user, err = s.getOrCreateUser(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if identity == nil {
+		identity = &oidc.Identity{
+			ID:          uuid.NewString(),
+			Issuer:      s.cfg.IssuerURL,
+			Subject:     claims.Subject,
+			UserID:      user.ID,
+			Groups:      claims.Groups,
+			CreatedAt:   now,
+			LastLoginAt: now,
+		}
+		if err := s.identityRepo.Create(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to create identity: %w", err)
+		}
+	} else {
+		// the user id changes if the user has inherited another user since the last login
+		identity.UserID = user.ID
+		identity.Groups = claims.Groups
+		identity.LastLoginAt = now
+		if err := s.identityRepo.Update(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to update identity: %w", err)
+		}
+	}
+
+	if err := s.userService.Activate(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to activate user: %w", err)
+	}
+
+	s.analyticsService.IdentifyUser(ctx, user)
+	s.analyticsService.CaptureUser(ctx, user.ID, "logged in", analytics.Property("type", "oidc"))
+
+	return user, nil
+}
+
+func (s *Service) getOrCreateUser(ctx context.Context, claims *oidc.Claims) (*users.User, error) {
+	if !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	// shadow users that have been inherited by another user resolve to the real user
+	user, err := s.userService.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// the domain is allowed, Login has checked it
+		if len(s.cfg.AllowedDomains) == 0 {
+			return nil, ErrUserExists
+		}
+		return user, nil
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = users.EmailToName(claims.Email)
+	}
+	user, err = s.userService.CreateVerified(ctx, name, claims.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// signingAlgorithms returns the algorithms that id tokens can be signed with, RS256 is required to be supported by
+// all providers.
+func (s *Service) signingAlgorithms() []string {
+	if len(s.cfg.SigningAlgorithms) == 0 {
+		return []string{string(jose.RS256)}
+	}
+	return s.cfg.SigningAlgorithms
+}
+
+func (s *Service) isAllowedDomain(email string) bool {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return false
+	}
+	if len(s.cfg.AllowedDomains) == 0 {
+		return true
+	}
+	for _, allowed := range s.cfg.AllowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Groups returns the groups that the user was in at the provider, as of their last login. Nothing is returned
+// if groups are not configured.
+func (s *Service) Groups(ctx context.Context, userID users.ID) ([]string, error) {
+	if !s.Enabled() || s.cfg.GroupsClaim == "" {
+		return nil, nil
+	}
+
+	identities, err := s.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	unique := make(map[string]struct{})
+	for _, identity := range identities {
+		for _, group := range identity.Groups {
+			unique[group] = struct{}{}
+		}
+	}
+
+	groups := make([]string, 0, len(unique))
+	for group := range unique {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
@@ -0,0 +1,307 @@
+package service_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/analytics/disabled"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/oidc/configuration"
+	db_oidc "getsturdy.com/api/pkg/oidc/db"
+	service_oidc "getsturdy.com/api/pkg/oidc/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/square/go-jose.v2"
+	jose_jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+type tokenClaims struct {
+	jose_jwt.Claims
+	Nonce         string   `json:"nonce,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name,omitempty"`
+	Groups        []string `json:"groups,omitempty"`
+}
+
+// testProvider is a minimal OpenID Connect provider, that issues an id token with the given claims for every code.
+type testProvider struct {
+	*httptest.Server
+
+	key       *rsa.PrivateKey
+	algorithm jose.SignatureAlgorithm
+	claims    tokenClaims
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &testProvider{key: key, algorithm: jose.RS256}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		// like with many providers, the key does not say which algorithm it's used with
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "key-1", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code_verifier") == "" {
+			http.Error(w, "missing code_verifier", http.StatusBadRequest)
+			return
+		}
+
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: p.algorithm, Key: jose.JSONWebKey{Key: key, KeyID: "key-1"}},
+			(&jose.SignerOptions{}).WithType("JWT"),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		idToken, err := jose_jwt.Signed(signer).Claims(p.claims).CompactSerialize()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// token sets the claims of the next id token.
+func (p *testProvider) token(subject, email, nonce string) *tokenClaims {
+	now := time.Now()
+	p.claims = tokenClaims{
+		Claims: jose_jwt.Claims{
+			Issuer:   p.URL,
+			Subject:  subject,
+			Audience: jose_jwt.Audience{"client-id"},
+			IssuedAt: jose_jwt.NewNumericDate(now),
+			Expiry:   jose_jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Nonce:         nonce,
+		Email:         email,
+		EmailVerified: true,
+	}
+	return &p.claims
+}
+
+func newService(t *testing.T, cfg *configuration.Configuration) (*service_oidc.Service, db_users.Repository) {
+	logger := zap.NewNop()
+	analyticsService := service_analytics.New(logger, disabled.NewClient(logger))
+	userRepo := db_users.NewMemory()
+	userService := service_users.New(logger, userRepo, analyticsService)
+	return service_oidc.New(logger, cfg, db_oidc.NewMemory(), userService, analyticsService), userRepo
+}
+
+func newConfiguration(p *testProvider) *configuration.Configuration {
+	return &configuration.Configuration{
+		IssuerURL:   p.URL,
+		ClientID:    "client-id",
+		RedirectURL: "http://localhost/v3/auth/oidc/callback",
+		GroupsClaim: "groups",
+	}
+}
+
+func TestExchange(t *testing.T) {
+	p := newTestProvider(t)
+	service, _ := newService(t, newConfiguration(p))
+	ctx := context.Background()
+
+	authCodeURL, err := service.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	assert.NoError(t, err)
+	assert.Contains(t, authCodeURL, p.URL+"/authorize?")
+	assert.Contains(t, authCodeURL, "code_challenge_method=S256")
+	assert.Contains(t, authCodeURL, "nonce=nonce")
+
+	t.Run("valid", func(t *testing.T) {
+		token := p.token("subject", "user@example.com", "nonce")
+		token.Name = "User"
+		token.Groups = []string{"engineering", "admins"}
+
+		claims, err := service.Exchange(ctx, "code", "nonce", "verifier")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "subject", claims.Subject)
+			assert.Equal(t, "user@example.com", claims.Email)
+			assert.True(t, claims.EmailVerified)
+			assert.Equal(t, "User", claims.Name)
+			assert.Equal(t, []string{"engineering", "admins"}, claims.Groups)
+		}
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		p.token("subject", "user@example.com", "other-nonce")
+		_, err := service.Exchange(ctx, "code", "nonce", "verifier")
+		assert.ErrorIs(t, err, service_oidc.ErrInvalidToken)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		token := p.token("subject", "user@example.com", "nonce")
+		token.Audience = jose_jwt.Audience{"other-client-id"}
+		_, err := service.Exchange(ctx, "code", "nonce", "verifier")
+		assert.ErrorIs(t, err, service_oidc.ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token := p.token("subject", "user@example.com", "nonce")
+		token.Expiry = jose_jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		_, err := service.Exchange(ctx, "code", "nonce", "verifier")
+		assert.ErrorIs(t, err, service_oidc.ErrInvalidToken)
+	})
+}
+
+func TestExchange_algorithm(t *testing.T) {
+	p := newTestProvider(t)
+	p.algorithm = jose.PS256
+	cfg := newConfiguration(p)
+	ctx := context.Background()
+
+	// only RS256 is accepted by default
+	service, _ := newService(t, cfg)
+	p.token("subject", "user@example.com", "nonce")
+	_, err := service.Exchange(ctx, "code", "nonce", "verifier")
+	assert.ErrorIs(t, err, service_oidc.ErrInvalidToken)
+
+	cfg.SigningAlgorithms = []string{string(jose.PS256)}
+	service, _ = newService(t, cfg)
+	_, err = service.Exchange(ctx, "code", "nonce", "verifier")
+	assert.NoError(t, err)
+}
+
+func TestExchange_disabled(t *testing.T) {
+	service, _ := newService(t, &configuration.Configuration{})
+	_, err := service.Exchange(context.Background(), "code", "nonce", "verifier")
+	assert.ErrorIs(t, err, service_oidc.ErrDisabled)
+}
+
+func TestSecureCookies(t *testing.T) {
+	p := newTestProvider(t)
+	cfg := newConfiguration(p)
+
+	service, _ := newService(t, cfg)
+	assert.False(t, service.SecureCookies())
+
+	cfg.RedirectURL = "https://sturdy.example.com/api/v3/auth/oidc/callback"
+	service, _ = newService(t, cfg)
+	assert.True(t, service.SecureCookies())
+}
+
+func TestLogin(t *testing.T) {
+	p := newTestProvider(t)
+	cfg := newConfiguration(p)
+	cfg.AllowedDomains = []string{"example.com"}
+	service, userRepo := newService(t, cfg)
+	ctx := context.Background()
+
+	login := func(subject, email string, verified bool, groups ...string) (*users.User, error) {
+		token := p.token(subject, email, "nonce")
+		token.EmailVerified = verified
+		token.Groups = groups
+		claims, err := service.Exchange(ctx, "code", "nonce", "verifier")
+		if err != nil {
+			return nil, err
+		}
+		return service.Login(ctx, claims)
+	}
+
+	t.Run("creates user", func(t *testing.T) {
+		subject := uuid.NewString()
+		user, err := login(subject, subject+"@example.com", true, "engineering")
+		if assert.NoError(t, err) {
+			assert.Equal(t, subject+"@example.com", user.Email)
+			assert.True(t, user.EmailVerified)
+			assert.Equal(t, users.StatusActive, user.Status)
+		}
+
+		again, err := login(subject, subject+"@example.com", true, "engineering", "admins")
+		if assert.NoError(t, err) {
+			assert.Equal(t, user.ID, again.ID)
+		}
+
+		groups, err := service.Groups(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"admins", "engineering"}, groups)
+	})
+
+	t.Run("links existing user", func(t *testing.T) {
+		existing := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com", Status: users.StatusShadow}
+		assert.NoError(t, userRepo.Create(existing))
+
+		user, err := login(uuid.NewString(), existing.Email, true)
+		if assert.NoError(t, err) {
+			assert.Equal(t, existing.ID, user.ID)
+			assert.Equal(t, users.StatusActive, user.Status)
+		}
+	})
+
+	t.Run("does not link unverified email", func(t *testing.T) {
+		existing := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com", Status: users.StatusActive}
+		assert.NoError(t, userRepo.Create(existing))
+
+		_, err := login(uuid.NewString(), existing.Email, false)
+		assert.ErrorIs(t, err, service_oidc.ErrEmailNotVerified)
+	})
+
+	t.Run("domain not allowed", func(t *testing.T) {
+		_, err := login(uuid.NewString(), uuid.NewString()+"@example.org", true)
+		assert.ErrorIs(t, err, service_oidc.ErrDomainNotAllowed)
+	})
+}
+
+func TestLogin_existingUser(t *testing.T) {
+	p := newTestProvider(t)
+	cfg := newConfiguration(p)
+	service, userRepo := newService(t, cfg)
+	ctx := context.Background()
+
+	existing := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com", Status: users.StatusActive}
+	assert.NoError(t, userRepo.Create(existing))
+
+	// without allowed domains, the provider is not trusted with the emails of existing users
+	p.token(uuid.NewString(), existing.Email, "nonce")
+	claims, err := service.Exchange(ctx, "code", "nonce", "verifier")
+	require.NoError(t, err)
+	_, err = service.Login(ctx, claims)
+	assert.ErrorIs(t, err, service_oidc.ErrUserExists)
+
+	// new users can still be created
+	p.token(uuid.NewString(), uuid.NewString()+"@example.com", "nonce")
+	claims, err = service.Exchange(ctx, "code", "nonce", "verifier")
+	require.NoError(t, err)
+	user, err := service.Login(ctx, claims)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, existing.ID, user.ID)
+	}
+}
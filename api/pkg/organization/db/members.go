@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -10,12 +11,17 @@ import (
 	"getsturdy.com/api/pkg/users"
 )
 
+var ErrLastOwner = errors.New("an organization must have at least one owner")
+
 type MemberRepository interface {
 	GetByUserIDAndOrganizationID(ctx context.Context, userID users.ID, organizationID string) (*organization.Member, error)
 	ListByOrganizationID(ctx context.Context, id string) ([]*organization.Member, error)
 	ListByUserID(context.Context, users.ID) ([]*organization.Member, error)
 	Create(ctx context.Context, org *organization.Member) error
 	Update(ctx context.Context, org *organization.Member) error
+	// UpdateOwner updates a member that is an owner, ErrLastOwner is returned if the organization would be left
+	// without owners.
+	UpdateOwner(ctx context.Context, owner *organization.Member) error
 	GetByID(ctx context.Context, id string) (*organization.Member, error)
 }
 
@@ -29,7 +35,7 @@ func NewMember(db *sqlx.DB) MemberRepository {
 
 func (r *memberRepository) GetByID(ctx context.Context, id string) (*organization.Member, error) {
 	var mem organization.Member
-	if err := r.db.GetContext(ctx, &mem, `SELECT id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by
+	if err := r.db.GetContext(ctx, &mem, `SELECT id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by
 		FROM organization_members
 		WHERE id = $1`, id); err != nil {
 		return nil, fmt.Errorf("failed to get organization_member by id: %w", err)
@@ -39,7 +45,7 @@ func (r *memberRepository) GetByID(ctx context.Context, id string) (*organizatio
 
 func (r *memberRepository) GetByUserIDAndOrganizationID(ctx context.Context, userID users.ID, organizationID string) (*organization.Member, error) {
 	var mem organization.Member
-	if err := r.db.GetContext(ctx, &mem, `SELECT id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by
+	if err := r.db.GetContext(ctx, &mem, `SELECT id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by
 		FROM organization_members
 		WHERE user_id = $1
 		  AND organization_id = $2
@@ -51,7 +57,7 @@ func (r *memberRepository) GetByUserIDAndOrganizationID(ctx context.Context, use
 
 func (r *memberRepository) ListByOrganizationID(ctx context.Context, id string) ([]*organization.Member, error) {
 	var res []*organization.Member
-	if err := r.db.SelectContext(ctx, &res, `SELECT id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by
+	if err := r.db.SelectContext(ctx, &res, `SELECT id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by
 		FROM organization_members
 		WHERE organization_id = $1
 		  AND deleted_at IS NULL`, id); err != nil {
@@ -62,7 +68,7 @@ func (r *memberRepository) ListByOrganizationID(ctx context.Context, id string)
 
 func (r *memberRepository) ListByUserID(ctx context.Context, id users.ID) ([]*organization.Member, error) {
 	var res []*organization.Member
-	if err := r.db.SelectContext(ctx, &res, `SELECT id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by
+	if err := r.db.SelectContext(ctx, &res, `SELECT id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by
 		FROM organization_members
 		WHERE user_id = $1
 		  AND deleted_at IS NULL`, id); err != nil {
@@ -72,25 +78,73 @@ func (r *memberRepository) ListByUserID(ctx context.Context, id users.ID) ([]*or
 }
 
 func (r *memberRepository) Create(ctx context.Context, mem *organization.Member) error {
-	if err := r.db.GetContext(ctx, mem, `INSERT INTO organization_members (id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by)
-		VALUES ($1, $2, $3, $4, $5, NULL, NULL)
+	if err := r.db.GetContext(ctx, mem, `INSERT INTO organization_members (id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULL, NULL)
 		ON CONFLICT (user_id, organization_id) DO UPDATE
-		SET deleted_at = NULL, deleted_by = NULL
-		RETURNING id, user_id, organization_id, created_at, created_by, deleted_at, deleted_by`,
-		mem.ID, mem.UserID, mem.OrganizationID, mem.CreatedAt, mem.CreatedBy); err != nil {
+		SET deleted_at = NULL, deleted_by = NULL, role = EXCLUDED.role
+		RETURNING id, user_id, organization_id, role, created_at, created_by, deleted_at, deleted_by`,
+		mem.ID, mem.UserID, mem.OrganizationID, mem.Role, mem.CreatedAt, mem.CreatedBy); err != nil {
 		return fmt.Errorf("failed to create organization_member: %w", err)
 	}
 
 	return nil
 }
 
-func (r *memberRepository) Update(ctx context.Context, org *organization.Member) error {
-	if _, err := r.db.NamedExecContext(ctx, `UPDATE organization_members
+const updateMemberQuery = `UPDATE organization_members
 		SET deleted_at = :deleted_at,
-		    deleted_by = :deleted_by
+		    deleted_by = :deleted_by,
+		    role = :role
 		WHERE id = :id
-`, org); err != nil {
+`
+
+func (r *memberRepository) Update(ctx context.Context, org *organization.Member) error {
+	if _, err := r.db.NamedExecContext(ctx, updateMemberQuery, org); err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+func (r *memberRepository) UpdateOwner(ctx context.Context, owner *organization.Member) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	// the owners are locked until the transaction ends, so that concurrent updates can't remove the last two owners
+	// at the same time. they are locked in the same order everywhere to not deadlock.
+	var ownerIDs []string
+	if err := tx.SelectContext(ctx, &ownerIDs, `SELECT id
+		FROM organization_members
+		WHERE organization_id = $1
+		  AND role = $2
+		  AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`, owner.OrganizationID, organization.RoleOwner); err != nil {
+		return fmt.Errorf("failed to lock owners: %w", err)
+	}
+
+	if owner.Role != organization.RoleOwner || owner.DeletedAt != nil {
+		if !containsOtherThan(ownerIDs, owner.ID) {
+			return ErrLastOwner
+		}
+	}
+
+	if _, err := tx.NamedExecContext(ctx, updateMemberQuery, owner); err != nil {
 		return fmt.Errorf("failed to update organization: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
+
+func containsOtherThan(ids []string, id string) bool {
+	for _, other := range ids {
+		if other != id {
+			return true
+		}
+	}
+	return false
+}
@@ -82,3 +82,112 @@ func TestAddTwoSameMembers_returns_same_id(t *testing.T) {
 
 	assert.Equal(t, oldID, member.ID)
 }
+
+func TestUpdateRole(t *testing.T) {
+	d := dbtest.DB(t)
+	repo := db.NewMember(d)
+	ctx := context.Background()
+
+	member := &organization.Member{
+		ID:             uuid.NewString(),
+		OrganizationID: uuid.NewString(),
+		UserID:         users.ID(uuid.NewString()),
+		Role:           organization.RoleMember,
+		CreatedAt:      time.Now(),
+		CreatedBy:      users.ID(uuid.NewString()),
+	}
+	assert.NoError(t, repo.Create(ctx, member))
+
+	member.Role = organization.RoleAdmin
+	assert.NoError(t, repo.Update(ctx, member))
+
+	updated, err := repo.GetByID(ctx, member.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, organization.RoleAdmin, updated.Role)
+}
+
+func TestUpdateOwner(t *testing.T) {
+	d := dbtest.DB(t)
+	repo := db.NewMember(d)
+	ctx := context.Background()
+
+	orgID := uuid.NewString()
+	owner := func() *organization.Member {
+		member := &organization.Member{
+			ID:             uuid.NewString(),
+			OrganizationID: orgID,
+			UserID:         users.ID(uuid.NewString()),
+			Role:           organization.RoleOwner,
+			CreatedAt:      time.Now(),
+			CreatedBy:      users.ID(uuid.NewString()),
+		}
+		assert.NoError(t, repo.Create(ctx, member))
+		return member
+	}
+	first, second := owner(), owner()
+
+	first.Role = organization.RoleAdmin
+	assert.NoError(t, repo.UpdateOwner(ctx, first))
+
+	now := time.Now()
+	second.DeletedAt = &now
+	assert.ErrorIs(t, repo.UpdateOwner(ctx, second), db.ErrLastOwner)
+
+	// updates that keep the member as an owner are allowed
+	second.DeletedAt = nil
+	assert.NoError(t, repo.UpdateOwner(ctx, second))
+
+	updated, err := repo.GetByID(ctx, second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, organization.RoleOwner, updated.Role)
+	assert.Nil(t, updated.DeletedAt)
+}
+
+func TestUpdateOwner_concurrent(t *testing.T) {
+	d := dbtest.DB(t)
+	repo := db.NewMember(d)
+	ctx := context.Background()
+
+	orgID := uuid.NewString()
+	owners := make([]*organization.Member, 0, 2)
+	for i := 0; i < 2; i++ {
+		member := &organization.Member{
+			ID:             uuid.NewString(),
+			OrganizationID: orgID,
+			UserID:         users.ID(uuid.NewString()),
+			Role:           organization.RoleOwner,
+			CreatedAt:      time.Now(),
+			CreatedBy:      users.ID(uuid.NewString()),
+		}
+		assert.NoError(t, repo.Create(ctx, member))
+		owners = append(owners, member)
+	}
+
+	// the owners demote each other at the same time, only one of them can succeed
+	errs := make(chan error, len(owners))
+	for _, owner := range owners {
+		owner.Role = organization.RoleMember
+		go func(owner *organization.Member) {
+			errs <- repo.UpdateOwner(ctx, owner)
+		}(owner)
+	}
+
+	var lastOwnerErrs int
+	for range owners {
+		if err := <-errs; err != nil {
+			assert.ErrorIs(t, err, db.ErrLastOwner)
+			lastOwnerErrs++
+		}
+	}
+	assert.Equal(t, 1, lastOwnerErrs)
+
+	members, err := repo.ListByOrganizationID(ctx, orgID)
+	assert.NoError(t, err)
+	var remainingOwners int
+	for _, member := range members {
+		if member.Role == organization.RoleOwner {
+			remainingOwners++
+		}
+	}
+	assert.Equal(t, 1, remainingOwners)
+}
@@ -27,7 +27,7 @@ func New(db *sqlx.DB) Repository {
 
 func (r *repository) GetFirst(ctx context.Context) (*organization.Organization, error) {
 	var org organization.Organization
-	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, created_at, deleted_at FROM organizations`); err != nil {
+	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, require_two_factor, created_at, deleted_at FROM organizations`); err != nil {
 		return nil, fmt.Errorf("could not get organization: %w", err)
 	}
 	return &org, nil
@@ -35,7 +35,7 @@ func (r *repository) GetFirst(ctx context.Context) (*organization.Organization,
 
 func (r *repository) Get(ctx context.Context, id string) (*organization.Organization, error) {
 	var org organization.Organization
-	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, created_at, deleted_at FROM organizations WHERE id = $1`, id); err != nil {
+	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, require_two_factor, created_at, deleted_at FROM organizations WHERE id = $1`, id); err != nil {
 		return nil, fmt.Errorf("could not get organization: %w", err)
 	}
 	return &org, nil
@@ -43,15 +43,15 @@ func (r *repository) Get(ctx context.Context, id string) (*organization.Organiza
 
 func (r *repository) GetByShortID(ctx context.Context, shortID organization.ShortOrganizationID) (*organization.Organization, error) {
 	var org organization.Organization
-	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, created_at, deleted_at FROM organizations WHERE short_id = $1`, shortID); err != nil {
+	if err := r.db.GetContext(ctx, &org, `SELECT id, short_id, name, require_two_factor, created_at, deleted_at FROM organizations WHERE short_id = $1`, shortID); err != nil {
 		return nil, fmt.Errorf("could not get organization: %w", err)
 	}
 	return &org, nil
 }
 
 func (r *repository) Create(ctx context.Context, org organization.Organization) error {
-	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO organizations (id, short_id, name, created_at, created_by, deleted_at, deleted_by)
-		VALUES (:id, :short_id, :name, :created_at, :created_by, :deleted_at, :deleted_by)`, org); err != nil {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO organizations (id, short_id, name, require_two_factor, created_at, created_by, deleted_at, deleted_by)
+		VALUES (:id, :short_id, :name, :require_two_factor, :created_at, :created_by, :deleted_at, :deleted_by)`, org); err != nil {
 		return fmt.Errorf("failed to create organization: %w", err)
 	}
 	return nil
@@ -60,6 +60,7 @@ func (r *repository) Create(ctx context.Context, org organization.Organization)
 func (r *repository) Update(ctx context.Context, org *organization.Organization) error {
 	if _, err := r.db.NamedExecContext(ctx, `UPDATE organizations
 		SET name = :name,
+		    require_two_factor = :require_two_factor,
 	    	deleted_at = :deleted_at,
 		    deleted_by = :deleted_by
 		WHERE id = :id
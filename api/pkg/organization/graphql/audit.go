@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func (r *organizationResolver) AuditLog(ctx context.Context, args resolvers.AuditLogArgs) ([]resolvers.AuditLogEntryResolver, error) {
+	if err := r.root.authService.CanWrite(ctx, r.org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	filter := audit.Filter{OrganizationID: &r.org.ID}
+	if args.Input != nil {
+		if args.Input.CodebaseID != nil {
+			codebaseID := codebases.ID(*args.Input.CodebaseID)
+			filter.CodebaseID = &codebaseID
+		}
+		if args.Input.ActorID != nil {
+			actorID := string(*args.Input.ActorID)
+			filter.ActorID = &actorID
+		}
+		if args.Input.Actions != nil {
+			for _, action := range *args.Input.Actions {
+				filter.Actions = append(filter.Actions, audit.Action(action))
+			}
+		}
+		if args.Input.Since != nil {
+			since := time.Unix(int64(*args.Input.Since), 0)
+			filter.Since = &since
+		}
+		if args.Input.Until != nil {
+			until := time.Unix(int64(*args.Input.Until), 0)
+			filter.Until = &until
+		}
+		if args.Input.Limit != nil {
+			if *args.Input.Limit < 0 {
+				return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "limit can not be negative")
+			}
+			filter.Limit = int(*args.Input.Limit)
+		}
+	}
+
+	entries, err := r.root.auditService.List(ctx, filter)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.AuditLogEntryResolver, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, &auditLogEntryResolver{root: r.root, entry: entry})
+	}
+	return res, nil
+}
+
+type auditLogEntryResolver struct {
+	root  *organizationRootResolver
+	entry *audit.Entry
+}
+
+func (r *auditLogEntryResolver) ID() graphql.ID {
+	return graphql.ID(r.entry.ID)
+}
+
+func (r *auditLogEntryResolver) CreatedAt() int32 {
+	return int32(r.entry.CreatedAt.Unix())
+}
+
+func (r *auditLogEntryResolver) Action() string {
+	return string(r.entry.Action)
+}
+
+func (r *auditLogEntryResolver) ActorType() string {
+	return string(r.entry.ActorType)
+}
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (resolvers.AuthorResolver, error) {
+	if r.entry.ActorType != auth.SubjectUser || r.entry.ActorID == nil {
+		return nil, nil
+	}
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(*r.entry.ActorID))
+}
+
+func (r *auditLogEntryResolver) IP() *string {
+	return r.entry.IP
+}
+
+func (r *auditLogEntryResolver) CodebaseID() *graphql.ID {
+	if r.entry.CodebaseID == nil {
+		return nil
+	}
+	id := graphql.ID(*r.entry.CodebaseID)
+	return &id
+}
+
+func (r *auditLogEntryResolver) TargetType() string {
+	return string(r.entry.TargetType)
+}
+
+func (r *auditLogEntryResolver) TargetID() graphql.ID {
+	return graphql.ID(r.entry.TargetID)
+}
+
+func (r *auditLogEntryResolver) Before() (*string, error) {
+	return marshalMetadata(r.entry.Before)
+}
+
+func (r *auditLogEntryResolver) After() (*string, error) {
+	return marshalMetadata(r.entry.After)
+}
+
+func marshalMetadata(md audit.Metadata) (*string, error) {
+	if md == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return nil, gqlerrors.Error(fmt.Errorf("failed to marshal metadata: %w", err))
+	}
+	str := string(data)
+	return &str, nil
+}
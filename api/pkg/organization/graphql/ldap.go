@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/ldap"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func (r *organizationResolver) LdapSync(ctx context.Context) (resolvers.LdapSyncResolver, error) {
+	if err := r.root.authService.CanWrite(ctx, r.org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if !r.root.ldapService.GroupSyncEnabled() {
+		return nil, nil
+	}
+
+	latest, err := r.root.ldapService.LatestSync(ctx)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		latest = nil
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &ldapSyncResolver{root: r.root, sync: latest}, nil
+}
+
+type ldapSyncResolver struct {
+	root *organizationRootResolver
+	sync *ldap.Sync
+}
+
+func (r *ldapSyncResolver) LastStartedAt() *int32 {
+	if r.sync == nil {
+		return nil
+	}
+	t := int32(r.sync.StartedAt.Unix())
+	return &t
+}
+
+func (r *ldapSyncResolver) LastFinishedAt() *int32 {
+	if r.sync == nil || r.sync.FinishedAt == nil {
+		return nil
+	}
+	t := int32(r.sync.FinishedAt.Unix())
+	return &t
+}
+
+func (r *ldapSyncResolver) Error() *string {
+	if r.sync == nil {
+		return nil
+	}
+	return r.sync.Error
+}
+
+func (r *ldapSyncResolver) AddedGroups() []string {
+	if r.sync == nil {
+		return []string{}
+	}
+	return append([]string{}, r.sync.AddedGroups...)
+}
+
+func (r *ldapSyncResolver) RemovedGroups() []string {
+	if r.sync == nil {
+		return []string{}
+	}
+	return append([]string{}, r.sync.RemovedGroups...)
+}
+
+func (r *ldapSyncResolver) Groups(ctx context.Context) ([]resolvers.LdapGroupResolver, error) {
+	groups, err := r.root.ldapService.ListGroups(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.LdapGroupResolver, 0, len(groups))
+	for _, group := range groups {
+		res = append(res, &ldapGroupResolver{root: r.root, group: group})
+	}
+	return res, nil
+}
+
+type ldapGroupResolver struct {
+	root  *organizationRootResolver
+	group *ldap.Group
+}
+
+func (r *ldapGroupResolver) ID() graphql.ID {
+	return graphql.ID(r.group.ID)
+}
+
+func (r *ldapGroupResolver) Name() string {
+	return r.group.Name
+}
+
+func (r *ldapGroupResolver) Dn() string {
+	return r.group.DN
+}
+
+func (r *ldapGroupResolver) Members(ctx context.Context) ([]resolvers.AuthorResolver, error) {
+	userIDs, err := r.root.ldapService.ListMembers(ctx, r.group.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		ids = append(ids, userID.String())
+	}
+	return r.authors(ctx, ids)
+}
+
+func (r *ldapGroupResolver) UnmatchedMembers() []string {
+	return append([]string{}, r.group.UnmatchedMembers...)
+}
+
+func (r *ldapGroupResolver) AddedMembers(ctx context.Context) ([]resolvers.AuthorResolver, error) {
+	return r.authors(ctx, r.group.AddedUserIDs)
+}
+
+func (r *ldapGroupResolver) RemovedMembers(ctx context.Context) ([]resolvers.AuthorResolver, error) {
+	return r.authors(ctx, r.group.RemovedUserIDs)
+}
+
+func (r *ldapGroupResolver) SyncedAt() int32 {
+	return int32(r.group.SyncedAt.Unix())
+}
+
+func (r *ldapGroupResolver) authors(ctx context.Context, userIDs []string) ([]resolvers.AuthorResolver, error) {
+	res := make([]resolvers.AuthorResolver, 0, len(userIDs))
+	for _, userID := range userIDs {
+		author, err := r.root.authorRootResolver.Author(ctx, graphql.ID(userID))
+		if err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		res = append(res, author)
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/organization"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+var roleFromGraphQL = map[resolvers.OrganizationMemberRole]organization.Role{
+	resolvers.OrganizationMemberRoleOwner:  organization.RoleOwner,
+	resolvers.OrganizationMemberRoleAdmin:  organization.RoleAdmin,
+	resolvers.OrganizationMemberRoleMember: organization.RoleMember,
+	resolvers.OrganizationMemberRoleGuest:  organization.RoleGuest,
+}
+
+type memberResolver struct {
+	root   *organizationRootResolver
+	member *organization.Member
+}
+
+func (r *memberResolver) ID() graphql.ID {
+	return graphql.ID(r.member.ID)
+}
+
+func (r *memberResolver) Author(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.member.UserID))
+}
+
+func (r *memberResolver) Role() (resolvers.OrganizationMemberRole, error) {
+	switch r.member.Role {
+	case organization.RoleOwner:
+		return resolvers.OrganizationMemberRoleOwner, nil
+	case organization.RoleAdmin:
+		return resolvers.OrganizationMemberRoleAdmin, nil
+	case organization.RoleMember:
+		return resolvers.OrganizationMemberRoleMember, nil
+	case organization.RoleGuest:
+		return resolvers.OrganizationMemberRoleGuest, nil
+	default:
+		return resolvers.OrganizationMemberRoleUndefined, gqlerrors.Error(fmt.Errorf("unknown role: %s", r.member.Role))
+	}
+}
@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	graphql_author "getsturdy.com/api/pkg/author/graphql"
 	graphql_codebases "getsturdy.com/api/pkg/codebases/graphql"
@@ -8,9 +9,12 @@ import (
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
 	graphql_licenses "getsturdy.com/api/pkg/licenses/graphql"
 	"getsturdy.com/api/pkg/logger"
 	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	service_user "getsturdy.com/api/pkg/users/service"
 )
 
@@ -19,6 +23,10 @@ func Module(c *di.Container) {
 	c.Import(service_auth.Module)
 	c.Import(service_user.Module)
 	c.Import(service_codebase.Module)
+	c.Import(service_scim.Module)
+	c.Import(service_audit.Module)
+	c.Import(service_twofactor.Module)
+	c.Import(service_ldap.Module)
 	c.Import(graphql_author.Module)
 	c.Import(graphql_licenses.Module)
 	c.Import(graphql_codebases.Module)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
@@ -15,8 +16,11 @@ import (
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
 	"getsturdy.com/api/pkg/organization"
 	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
 
@@ -26,10 +30,14 @@ import (
 )
 
 type organizationRootResolver struct {
-	service         *service_organization.Service
-	authService     *service_auth.Service
-	userService     service_user.Service
-	codebaseService *service_codebase.Service
+	service          *service_organization.Service
+	authService      *service_auth.Service
+	userService      service_user.Service
+	codebaseService  *service_codebase.Service
+	scimService      *service_scim.Service
+	auditService     *service_audit.Service
+	twoFactorService *service_twofactor.Service
+	ldapService      *service_ldap.Service
 
 	authorRootResolver    resolvers.AuthorRootResolver
 	licensesRootResolver  resolvers.LicenseRootResolver
@@ -44,6 +52,10 @@ func New(
 	authService *service_auth.Service,
 	userService service_user.Service,
 	codebaseService *service_codebase.Service,
+	scimService *service_scim.Service,
+	auditService *service_audit.Service,
+	twoFactorService *service_twofactor.Service,
+	ldapService *service_ldap.Service,
 
 	authorRootResolver resolvers.AuthorRootResolver,
 	licensesRootResolver resolvers.LicenseRootResolver,
@@ -54,10 +66,14 @@ func New(
 
 ) resolvers.OrganizationRootResolver {
 	return &organizationRootResolver{
-		service:         service,
-		authService:     authService,
-		userService:     userService,
-		codebaseService: codebaseService,
+		service:          service,
+		authService:      authService,
+		userService:      userService,
+		codebaseService:  codebaseService,
+		scimService:      scimService,
+		auditService:     auditService,
+		twoFactorService: twoFactorService,
+		ldapService:      ldapService,
 
 		authorRootResolver:    authorRootResolver,
 		licensesRootResolver:  licensesRootResolver,
@@ -235,7 +251,43 @@ func (r *organizationRootResolver) RemoveUserFromOrganization(ctx context.Contex
 		return nil, gqlerrors.Error(err)
 	}
 
-	if err := r.service.RemoveMember(ctx, org.ID, users.ID(args.Input.UserID), removedByUserID); err != nil {
+	if err := r.service.RemoveMember(ctx, org.ID, users.ID(args.Input.UserID), removedByUserID); errors.Is(err, service_organization.ErrLastOwner) {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "the last owner of the organization can not be removed")
+	} else if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &organizationResolver{root: r, org: org}, nil
+}
+
+func (r *organizationRootResolver) UpdateOrganizationMemberRole(ctx context.Context, args resolvers.UpdateOrganizationMemberRoleArgs) (resolvers.OrganizationResolver, error) {
+	org, err := r.service.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	changedByUserID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	role, ok := roleFromGraphQL[args.Input.Role]
+	if !ok {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "role", "unknown role")
+	}
+
+	_, err = r.service.SetRole(ctx, org.ID, users.ID(args.Input.UserID), role, changedByUserID)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_organization.ErrLastOwner):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "the organization must have at least one owner")
+	case errors.Is(err, service_organization.ErrInvalidRole):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "role", "unknown role")
+	default:
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -318,6 +370,19 @@ func (r *organizationResolver) Members(ctx context.Context) ([]resolvers.AuthorR
 	return res, nil
 }
 
+func (r *organizationResolver) Memberships(ctx context.Context) ([]resolvers.OrganizationMemberResolver, error) {
+	members, err := r.root.service.Members(ctx, r.org.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.OrganizationMemberResolver, 0, len(members))
+	for _, m := range members {
+		res = append(res, &memberResolver{root: r.root, member: m})
+	}
+	return res, nil
+}
+
 func (r *organizationResolver) Codebases(ctx context.Context) ([]resolvers.CodebaseResolver, error) {
 	userID, err := auth.UserID(ctx)
 	if err != nil {
@@ -326,13 +391,9 @@ func (r *organizationResolver) Codebases(ctx context.Context) ([]resolvers.Codeb
 
 	var isMemberOfOrganization bool
 
-	_, err = r.root.service.GetMember(ctx, r.org.ID, userID)
-	switch {
-	case err == nil:
-		isMemberOfOrganization = true
-	case errors.Is(err, sql.ErrNoRows):
-		isMemberOfOrganization = false
-	case err != nil:
+	// guests can only see the codebases that they are members of
+	isMemberOfOrganization, err = r.root.service.HasRole(ctx, r.org.ID, userID, organization.RoleMember)
+	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/scim"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func (r *organizationRootResolver) CreateScimToken(ctx context.Context, args resolvers.CreateScimTokenArgs) (resolvers.ScimTokenResolver, error) {
+	org, err := r.service.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	plainTextToken, token, err := r.scimService.CreateToken(ctx, org.ID, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &scimTokenResolver{root: r, token: token, plainTextToken: &plainTextToken}, nil
+}
+
+func (r *organizationRootResolver) DeleteScimToken(ctx context.Context, args resolvers.DeleteScimTokenArgs) (resolvers.OrganizationResolver, error) {
+	token, err := r.scimService.GetToken(ctx, string(args.Input.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, gqlerrors.Error(gqlerrors.ErrNotFound)
+	} else if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	org, err := r.service.GetByID(ctx, token.OrganizationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.scimService.DeleteToken(ctx, token); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &organizationResolver{root: r, org: org}, nil
+}
+
+func (r *organizationResolver) ScimTokens(ctx context.Context) ([]resolvers.ScimTokenResolver, error) {
+	if err := r.root.authService.CanWrite(ctx, r.org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	tokens, err := r.root.scimService.ListTokens(ctx, r.org.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.ScimTokenResolver, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, &scimTokenResolver{root: r.root, token: token})
+	}
+	return res, nil
+}
+
+type scimTokenResolver struct {
+	root           *organizationRootResolver
+	plainTextToken *string
+	token          *scim.Token
+}
+
+func (r *scimTokenResolver) ID() graphql.ID {
+	return graphql.ID(r.token.ID)
+}
+
+func (r *scimTokenResolver) Token() *string {
+	return r.plainTextToken
+}
+
+func (r *scimTokenResolver) CreatedBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.token.CreatedBy))
+}
+
+func (r *scimTokenResolver) CreatedAt() int32 {
+	return int32(r.token.CreatedAt.Unix())
+}
+
+func (r *scimTokenResolver) LastUsedAt() *int32 {
+	if r.token.LastUsedAt == nil {
+		return nil
+	}
+	luat := int32(r.token.LastUsedAt.Unix())
+	return &luat
+}
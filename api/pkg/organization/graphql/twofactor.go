@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+func (r *organizationRootResolver) UpdateOrganizationTwoFactorRequirement(ctx context.Context, args resolvers.UpdateOrganizationTwoFactorRequirementArgs) (resolvers.OrganizationResolver, error) {
+	org, err := r.service.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	// don't let owners lock themselves out
+	if args.Input.RequireTwoFactor {
+		enabled, err := r.twoFactorService.IsEnabled(ctx, userID)
+		if err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		if !enabled {
+			return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "you must enable two-factor authentication before requiring it for the organization")
+		}
+	}
+
+	org, err = r.service.SetRequireTwoFactor(ctx, org.ID, args.Input.RequireTwoFactor, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &organizationResolver{root: r, org: org}, nil
+}
+
+func (r *organizationResolver) RequireTwoFactor() bool {
+	return r.org.RequireTwoFactor
+}
@@ -16,14 +16,49 @@ type Organization struct {
 	CreatedBy users.ID            `db:"created_by"`
 	DeletedAt *time.Time          `db:"deleted_at"`
 	DeletedBy *string             `db:"deleted_by"`
+
+	// RequireTwoFactor blocks members without two-factor authentication from accessing the organization
+	RequireTwoFactor bool `db:"require_two_factor"`
 }
 
 type Member struct {
 	ID             string     `db:"id"`
 	UserID         users.ID   `db:"user_id"`
 	OrganizationID string     `db:"organization_id"`
+	Role           Role       `db:"role"`
 	CreatedAt      time.Time  `db:"created_at"`
 	CreatedBy      users.ID   `db:"created_by"`
 	DeletedAt      *time.Time `db:"deleted_at"`
 	DeletedBy      *users.ID  `db:"deleted_by"`
 }
+
+// Role is the role of a member in an organization. Each role can do everything that the roles below it can do.
+type Role string
+
+const (
+	// RoleOwner can do everything, including managing other owners
+	RoleOwner Role = "owner"
+	// RoleAdmin can update the organization, and manage members that are not owners
+	RoleAdmin Role = "admin"
+	// RoleMember can access all codebases in the organization, and create new ones
+	RoleMember Role = "member"
+	// RoleGuest can only access the codebases that they have been invited to
+	RoleGuest Role = "guest"
+)
+
+var roleRanks = map[Role]int{
+	RoleGuest:  1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast returns true if r is the same or a higher role than other.
+func (r Role) AtLeast(other Role) bool {
+	return r.IsValid() && roleRanks[r] >= roleRanks[other]
+}
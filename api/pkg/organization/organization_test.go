@@ -0,0 +1,17 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	assert.True(t, RoleOwner.AtLeast(RoleAdmin))
+	assert.True(t, RoleAdmin.AtLeast(RoleAdmin))
+	assert.True(t, RoleMember.AtLeast(RoleGuest))
+	assert.False(t, RoleMember.AtLeast(RoleAdmin))
+	assert.False(t, RoleGuest.AtLeast(RoleMember))
+	assert.False(t, Role("").AtLeast(RoleGuest))
+	assert.False(t, Role("superuser").AtLeast(RoleGuest))
+}
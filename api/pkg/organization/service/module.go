@@ -2,6 +2,7 @@ package service
 
 import (
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/events/v2"
 	sender_notifications "getsturdy.com/api/pkg/notification/sender"
@@ -13,5 +14,6 @@ func Module(c *di.Container) {
 	c.Import(db_organization.Module)
 	c.Import(service_analytics.Module)
 	c.Import(sender_notifications.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 }
@@ -9,6 +9,9 @@ import (
 
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/notification"
 	service_notifications "getsturdy.com/api/pkg/notification/sender"
@@ -21,6 +24,11 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrLastOwner   = db_organization.ErrLastOwner
+	ErrInvalidRole = errors.New("invalid role")
+)
+
 type Service struct {
 	logger                       *zap.Logger
 	eventsSender                 *events.Publisher
@@ -28,6 +36,7 @@ type Service struct {
 	organizationMemberRepository db_organization.MemberRepository
 	analyticsService             *service_analytics.Service
 	notificationsSender          service_notifications.NotificationSender
+	auditService                 *service_audit.Service
 }
 
 func New(
@@ -37,6 +46,7 @@ func New(
 	organizationMemberRepository db_organization.MemberRepository,
 	analyticsService *service_analytics.Service,
 	notificationsSender service_notifications.NotificationSender,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
 		logger:                       logger.Named("organizationService"),
@@ -45,6 +55,7 @@ func New(
 		organizationMemberRepository: organizationMemberRepository,
 		analyticsService:             analyticsService,
 		notificationsSender:          notificationsSender,
+		auditService:                 auditService,
 	}
 }
 
@@ -108,8 +119,8 @@ func (svc *Service) Create(ctx context.Context, userID users.ID, name string) (*
 		return nil, fmt.Errorf("failed to create organization: %w", err)
 	}
 
-	// add the creator as a member
-	if _, err := svc.AddMember(ctx, org.ID, userID, userID); err != nil {
+	// add the creator as the owner
+	if _, err := svc.addMember(ctx, org.ID, userID, userID, organization.RoleOwner); err != nil {
 		return nil, fmt.Errorf("failed to invite creator to organization: %w", err)
 	}
 
@@ -139,7 +150,59 @@ func (svc *Service) Update(ctx context.Context, organizationID string, newName s
 	return org, nil
 }
 
+// SetRequireTwoFactor changes if members of the organization must have two-factor authentication enabled to access
+// it. Only owners can change this.
+func (svc *Service) SetRequireTwoFactor(ctx context.Context, orgID string, require bool, changedByUserID users.ID) (*organization.Organization, error) {
+	if err := svc.requireRole(ctx, orgID, changedByUserID, organization.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	org, err := svc.organizationRepository.Get(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get organization: %w", err)
+	}
+
+	if org.RequireTwoFactor == require {
+		return org, nil
+	}
+
+	org.RequireTwoFactor = require
+	if err := svc.organizationRepository.Update(ctx, org); err != nil {
+		return nil, fmt.Errorf("could not update organization: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &orgID,
+		ActorType:      auth.SubjectUser,
+		ActorID:        (*string)(&changedByUserID),
+		Action:         audit.ActionOrganizationTwoFactorChanged,
+		TargetType:     audit.TargetOrganization,
+		TargetID:       orgID,
+		Before:         audit.Metadata{"require_two_factor": !require},
+		After:          audit.Metadata{"require_two_factor": require},
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
+	if err := svc.eventsSender.OrganizationUpdated(ctx, events.Organization(orgID), org); err != nil {
+		return nil, fmt.Errorf("failed to send event: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "set organization two-factor requirement",
+		analytics.OrganizationID(orgID),
+		analytics.Property("require_two_factor", require),
+	)
+
+	return org, nil
+}
+
+// AddMember adds the user to the organization as a member, if they are not in it already.
 func (svc *Service) AddMember(ctx context.Context, orgID string, userID, addedByUserID users.ID) (*organization.Member, error) {
+	return svc.addMember(ctx, orgID, userID, addedByUserID, organization.RoleMember)
+}
+
+func (svc *Service) addMember(ctx context.Context, orgID string, userID, addedByUserID users.ID, role organization.Role) (*organization.Member, error) {
 	if existing, err := svc.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, orgID); errors.Is(err, sql.ErrNoRows) {
 		// go on
 	} else if err != nil {
@@ -152,6 +215,7 @@ func (svc *Service) AddMember(ctx context.Context, orgID string, userID, addedBy
 		ID:             uuid.NewString(),
 		OrganizationID: orgID,
 		UserID:         userID,
+		Role:           role,
 		CreatedAt:      time.Now(),
 		CreatedBy:      addedByUserID,
 	}
@@ -160,6 +224,19 @@ func (svc *Service) AddMember(ctx context.Context, orgID string, userID, addedBy
 		return nil, fmt.Errorf("failed to create member: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &orgID,
+		ActorType:      auth.SubjectUser,
+		ActorID:        (*string)(&addedByUserID),
+		Action:         audit.ActionOrganizationMemberAdded,
+		TargetType:     audit.TargetUser,
+		TargetID:       userID.String(),
+		After:          audit.Metadata{"role": role},
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
 	if addedByUserID != userID {
 		if err := svc.notificationsSender.User(ctx, userID, notification.InvitedToOrganization, member.ID); err != nil {
 			svc.logger.Error("failed to send notification", zap.Error(err))
@@ -175,18 +252,40 @@ func (svc *Service) AddMember(ctx context.Context, orgID string, userID, addedBy
 	return member, nil
 }
 
+// RemoveMember removes the user from the organization. Owners can only be removed by other owners, and the last
+// owner can not be removed.
 func (svc *Service) RemoveMember(ctx context.Context, orgID string, userID users.ID, deletedByUserID users.ID) error {
 	member, err := svc.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, orgID)
 	if err != nil {
 		return fmt.Errorf("could not get member: %w", err)
 	}
 
+	wasOwner := member.Role == organization.RoleOwner
+	if wasOwner {
+		if err := svc.requireRole(ctx, orgID, deletedByUserID, organization.RoleOwner); err != nil {
+			return err
+		}
+	}
+
 	t := time.Now()
 	member.DeletedAt = &t
 	member.DeletedBy = &deletedByUserID
 
-	if err := svc.organizationMemberRepository.Update(ctx, member); err != nil {
-		return fmt.Errorf("could not update member: %w", err)
+	if err := svc.updateMember(ctx, member, wasOwner); err != nil {
+		return err
+	}
+
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &orgID,
+		ActorType:      auth.SubjectUser,
+		ActorID:        (*string)(&deletedByUserID),
+		Action:         audit.ActionOrganizationMemberRemoved,
+		TargetType:     audit.TargetUser,
+		TargetID:       userID.String(),
+		Before:         audit.Metadata{"role": member.Role},
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
 	}
 
 	svc.analyticsService.Capture(ctx, "remove member from organization",
@@ -197,6 +296,113 @@ func (svc *Service) RemoveMember(ctx context.Context, orgID string, userID users
 	return nil
 }
 
+// SetRole changes the role of a member. Admins can change the roles of members and guests, and owners can change
+// the role of anyone.
+func (svc *Service) SetRole(ctx context.Context, orgID string, userID users.ID, role organization.Role, changedByUserID users.ID) (*organization.Member, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	member, err := svc.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get member: %w", err)
+	}
+
+	if member.Role == role {
+		return member, nil
+	}
+
+	requiredRole := organization.RoleAdmin
+	if member.Role == organization.RoleOwner || role == organization.RoleOwner {
+		requiredRole = organization.RoleOwner
+	}
+	if err := svc.requireRole(ctx, orgID, changedByUserID, requiredRole); err != nil {
+		return nil, err
+	}
+
+	previousRole := member.Role
+	member.Role = role
+	if err := svc.updateMember(ctx, member, previousRole == organization.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := svc.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &orgID,
+		ActorType:      auth.SubjectUser,
+		ActorID:        (*string)(&changedByUserID),
+		Action:         audit.ActionOrganizationMemberRoleChanged,
+		TargetType:     audit.TargetUser,
+		TargetID:       userID.String(),
+		Before:         audit.Metadata{"role": previousRole},
+		After:          audit.Metadata{"role": role},
+	}); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
+	org, err := svc.organizationRepository.Get(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get organization: %w", err)
+	}
+
+	if err := svc.eventsSender.OrganizationUpdated(ctx, events.Organization(orgID), org); err != nil {
+		return nil, fmt.Errorf("failed to send event: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "change role in organization",
+		analytics.OrganizationID(orgID),
+		analytics.Property("user_id", userID),
+		analytics.Property("role", role),
+	)
+
+	return member, nil
+}
+
+// HasRole returns true if the user is a member of the organization, with at least the given role.
+func (svc *Service) HasRole(ctx context.Context, orgID string, userID users.ID, role organization.Role) (bool, error) {
+	member, err := svc.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, orgID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not get member: %w", err)
+	default:
+		return member.Role.AtLeast(role), nil
+	}
+}
+
+func (svc *Service) requireRole(ctx context.Context, orgID string, userID users.ID, role organization.Role) error {
+	ok, err := svc.HasRole(ctx, orgID, userID, role)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("only %ss can do this: %w", role, auth.ErrForbidden)
+	default:
+		return nil
+	}
+}
+
+// updateMember updates the member. Owners are updated in a way that is safe from concurrent updates of other owners,
+// so that the last owner can never be removed.
+func (svc *Service) updateMember(ctx context.Context, member *organization.Member, wasOwner bool) error {
+	if !wasOwner {
+		if err := svc.organizationMemberRepository.Update(ctx, member); err != nil {
+			return fmt.Errorf("could not update member: %w", err)
+		}
+		return nil
+	}
+
+	switch err := svc.organizationMemberRepository.UpdateOwner(ctx, member); {
+	case errors.Is(err, db_organization.ErrLastOwner):
+		return ErrLastOwner
+	case err != nil:
+		return fmt.Errorf("could not update member: %w", err)
+	default:
+		return nil
+	}
+}
+
 func (svc *Service) GetByID(ctx context.Context, organizationID string) (*organization.Organization, error) {
 	member, err := svc.organizationRepository.Get(ctx, organizationID)
 	if err != nil {
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	mu   sync.RWMutex
+	byID map[string]personaltokens.Token
+}
+
+func NewMemory() Repository {
+	return &memory{
+		byID: map[string]personaltokens.Token{},
+	}
+}
+
+func (m *memory) Create(_ context.Context, token *personaltokens.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byID[token.ID] = *token
+	return nil
+}
+
+func (m *memory) Get(_ context.Context, id string) (*personaltokens.Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	token, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &token, nil
+}
+
+func (m *memory) ListByUserID(_ context.Context, userID users.ID) ([]*personaltokens.Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var tokens []*personaltokens.Token
+	for _, token := range m.byID {
+		if token.UserID == userID {
+			token := token
+			tokens = append(tokens, &token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+func (m *memory) Update(_ context.Context, token *personaltokens.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.byID[token.ID]; !found {
+		return sql.ErrNoRows
+	}
+	m.byID[token.ID] = *token
+	return nil
+}
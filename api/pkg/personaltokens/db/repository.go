@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	Create(context.Context, *personaltokens.Token) error
+	Get(ctx context.Context, id string) (*personaltokens.Token, error)
+	ListByUserID(ctx context.Context, userID users.ID) ([]*personaltokens.Token, error)
+	Update(context.Context, *personaltokens.Token) error
+}
@@ -0,0 +1,11 @@
+package graphql
+
+import (
+	"getsturdy.com/api/pkg/di"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(service_personaltokens.Module)
+	c.Register(New)
+}
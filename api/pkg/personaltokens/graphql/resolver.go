@@ -0,0 +1,175 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/personaltokens"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+var scopeFromGraphQL = map[resolvers.PersonalAccessTokenScope]auth.Scope{
+	resolvers.PersonalAccessTokenScopeReadCodebase:   auth.ScopeReadCodebase,
+	resolvers.PersonalAccessTokenScopeWriteWorkspace: auth.ScopeWriteWorkspace,
+	resolvers.PersonalAccessTokenScopeLand:           auth.ScopeLand,
+	resolvers.PersonalAccessTokenScopeAdmin:          auth.ScopeAdmin,
+}
+
+var scopeToGraphQL = map[auth.Scope]resolvers.PersonalAccessTokenScope{
+	auth.ScopeReadCodebase:   resolvers.PersonalAccessTokenScopeReadCodebase,
+	auth.ScopeWriteWorkspace: resolvers.PersonalAccessTokenScopeWriteWorkspace,
+	auth.ScopeLand:           resolvers.PersonalAccessTokenScopeLand,
+	auth.ScopeAdmin:          resolvers.PersonalAccessTokenScopeAdmin,
+}
+
+type rootResolver struct {
+	personalTokensService *service_personaltokens.Service
+}
+
+func New(personalTokensService *service_personaltokens.Service) resolvers.PersonalAccessTokensRootResolver {
+	return &rootResolver{
+		personalTokensService: personalTokensService,
+	}
+}
+
+func (r *rootResolver) PersonalAccessTokens(ctx context.Context) ([]resolvers.PersonalAccessTokenResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	tokens, err := r.personalTokensService.List(ctx, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.PersonalAccessTokenResolver, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, &resolver{token: token})
+	}
+	return res, nil
+}
+
+func (r *rootResolver) CreatePersonalAccessToken(ctx context.Context, args resolvers.CreatePersonalAccessTokenArgs) (resolvers.PersonalAccessTokenResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	scopes := make([]auth.Scope, 0, len(args.Input.Scopes))
+	for _, scope := range args.Input.Scopes {
+		s, ok := scopeFromGraphQL[scope]
+		if !ok {
+			return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "scopes", fmt.Sprintf("unknown scope: %s", scope))
+		}
+		scopes = append(scopes, s)
+	}
+
+	var expiresAt *time.Time
+	if args.Input.ExpiresAt != nil {
+		t := time.Unix(int64(*args.Input.ExpiresAt), 0)
+		expiresAt = &t
+	}
+
+	plainTextToken, token, err := r.personalTokensService.Create(ctx, userID, args.Input.Name, scopes, expiresAt)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_personaltokens.ErrInvalidScopes):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "scopes", err.Error())
+	case errors.Is(err, service_personaltokens.ErrInvalidExpiry):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "expiresAt", err.Error())
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &resolver{token: token, plainTextToken: &plainTextToken}, nil
+}
+
+func (r *rootResolver) RevokePersonalAccessToken(ctx context.Context, args resolvers.RevokePersonalAccessTokenArgs) (resolvers.PersonalAccessTokenResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	token, err := r.personalTokensService.Get(ctx, string(args.Input.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, gqlerrors.Error(gqlerrors.ErrNotFound)
+	} else if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if token.UserID != userID {
+		return nil, gqlerrors.Error(gqlerrors.ErrNotFound)
+	}
+
+	if err := r.personalTokensService.Revoke(ctx, token); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &resolver{token: token}, nil
+}
+
+type resolver struct {
+	plainTextToken *string
+	token          *personaltokens.Token
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.token.ID)
+}
+
+func (r *resolver) Name() string {
+	return r.token.Name
+}
+
+func (r *resolver) Scopes() ([]resolvers.PersonalAccessTokenScope, error) {
+	scopes := make([]resolvers.PersonalAccessTokenScope, 0, len(r.token.Scopes))
+	for _, scope := range r.token.AuthScopes() {
+		s, ok := scopeToGraphQL[scope]
+		if !ok {
+			return nil, gqlerrors.Error(fmt.Errorf("unknown scope: %s", scope))
+		}
+		scopes = append(scopes, s)
+	}
+	return scopes, nil
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.token.CreatedAt.Unix())
+}
+
+func (r *resolver) ExpiresAt() *int32 {
+	return unix(r.token.ExpiresAt)
+}
+
+func (r *resolver) LastUsedAt() *int32 {
+	return unix(r.token.LastUsedAt)
+}
+
+func (r *resolver) LastUsedIP() *string {
+	return r.token.LastUsedIP
+}
+
+func (r *resolver) RevokedAt() *int32 {
+	return unix(r.token.RevokedAt)
+}
+
+func (r *resolver) Token() *string {
+	return r.plainTextToken
+}
+
+func unix(t *time.Time) *int32 {
+	if t == nil {
+		return nil
+	}
+	u := int32(t.Unix())
+	return &u
+}
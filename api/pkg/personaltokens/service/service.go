@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/ip"
+	"getsturdy.com/api/pkg/personaltokens"
+	db_personaltokens "getsturdy.com/api/pkg/personaltokens/db"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidScopes = errors.New("invalid scopes")
+	ErrInvalidExpiry = errors.New("expiry must be in the future")
+)
+
+const (
+	// lastUsedInterval is how often the last use of a token is recorded, to not write to the database on every
+	// request.
+	lastUsedInterval = time.Minute
+
+	// tokenSeparator separates the token id from the secret in plaintext tokens.
+	tokenSeparator = "."
+)
+
+var _ auth.TokenAuthenticator = &Service{}
+
+type Service struct {
+	logger           *zap.Logger
+	repo             db_personaltokens.Repository
+	userService      service_user.Service
+	analyticsService *service_analytics.Service
+}
+
+func New(
+	logger *zap.Logger,
+	repo db_personaltokens.Repository,
+	userService service_user.Service,
+	analyticsService *service_analytics.Service,
+) *Service {
+	return &Service{
+		logger:           logger.Named("personaltokens"),
+		repo:             repo,
+		userService:      userService,
+		analyticsService: analyticsService,
+	}
+}
+
+// Create creates a new personal access token for the user. It returns the token in plaintext, which is not stored and
+// can not be recovered. If expiresAt is nil, the token never expires.
+func (s *Service) Create(ctx context.Context, userID users.ID, name string, scopes []auth.Scope, expiresAt *time.Time) (string, *personaltokens.Token, error) {
+	if len(scopes) == 0 {
+		return "", nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScopes)
+	}
+
+	scopeNames := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return "", nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidScopes, scope)
+		}
+		scopeNames = append(scopeNames, scope.String())
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, ErrInvalidExpiry
+	}
+
+	secret := uuid.NewString()
+	token := &personaltokens.Token{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      name,
+		Hash:      personaltokens.HashSecret(secret),
+		Scopes:    scopeNames,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.repo.Create(ctx, token); err != nil {
+		return "", nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, userID, "created personal access token")
+
+	return auth.PersonalAccessTokenPrefix + token.ID + tokenSeparator + secret, token, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*personaltokens.Token, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List returns all tokens of the user, including expired and revoked ones.
+func (s *Service) List(ctx context.Context, userID users.ID) ([]*personaltokens.Token, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// Revoke revokes the token, it can not be used after this.
+func (s *Service) Revoke(ctx context.Context, token *personaltokens.Token) error {
+	if token.IsRevoked() {
+		return nil
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+	if err := s.repo.Update(ctx, token); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, token.UserID, "revoked personal access token")
+
+	return nil
+}
+
+// Authenticate returns the subject of the plaintext token, restricted to the scopes of the token. Expired and revoked
+// tokens, and tokens owned by deactivated users are not valid.
+func (s *Service) Authenticate(ctx context.Context, plainTextToken string) (*auth.Subject, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(plainTextToken, auth.PersonalAccessTokenPrefix), tokenSeparator)
+	if !ok {
+		return nil, fmt.Errorf("malformed token: %w", auth.ErrUnauthenticated)
+	}
+
+	token, err := s.repo.Get(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("token not found: %w", auth.ErrUnauthenticated)
+	default:
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if err := token.Verify(secret); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", auth.ErrUnauthenticated)
+	}
+
+	if token.IsRevoked() {
+		return nil, fmt.Errorf("token is revoked: %w", auth.ErrUnauthenticated)
+	}
+
+	if token.IsExpired() {
+		return nil, fmt.Errorf("token is expired: %w", auth.ErrUnauthenticated)
+	}
+
+	user, err := s.userService.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Status == users.StatusDeactivated {
+		return nil, fmt.Errorf("user is deactivated: %w", auth.ErrUnauthenticated)
+	}
+
+	s.recordUse(ctx, token)
+
+	return &auth.Subject{
+		ID:     token.UserID.String(),
+		Type:   auth.SubjectUser,
+		Scopes: token.AuthScopes(),
+	}, nil
+}
+
+// recordUse records when and from where the token was last used. It's recorded at most once every lastUsedInterval.
+func (s *Service) recordUse(ctx context.Context, token *personaltokens.Token) {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < lastUsedInterval {
+		return
+	}
+
+	token.LastUsedAt = &now
+	if remoteIP, ok := ip.FromContext(ctx); ok && remoteIP != nil && *remoteIP != nil {
+		lastUsedIP := remoteIP.String()
+		token.LastUsedIP = &lastUsedIP
+	}
+	if err := s.repo.Update(ctx, token); err != nil {
+		s.logger.Error("failed to update token", zap.Error(err))
+		// do not fail
+	}
+}
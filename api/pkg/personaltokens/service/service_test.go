@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/analytics/disabled"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/ip"
+	db_personaltokens "getsturdy.com/api/pkg/personaltokens/db"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newService(t *testing.T) (*service_personaltokens.Service, db_users.Repository) {
+	logger := zap.NewNop()
+	analyticsService := service_analytics.New(logger, disabled.NewClient(logger))
+	userRepo := db_users.NewMemory()
+	userService := service_users.New(logger, userRepo, analyticsService)
+	return service_personaltokens.New(logger, db_personaltokens.NewMemory(), userService, analyticsService), userRepo
+}
+
+func newUser(t *testing.T, userRepo db_users.Repository) *users.User {
+	user := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@getsturdy.com", Status: users.StatusActive}
+	require.NoError(t, userRepo.Create(user))
+	return user
+}
+
+func TestAuthenticate(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+
+	ctx := ip.NewContext(context.Background(), net.ParseIP("10.0.0.1"))
+
+	plainText, token, err := svc.Create(ctx, user.ID, "ci", []auth.Scope{auth.ScopeReadCodebase, auth.ScopeLand}, nil)
+	require.NoError(t, err)
+	assert.True(t, auth.IsPersonalAccessToken(plainText))
+
+	subject, err := svc.Authenticate(ctx, plainText)
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Subject{
+		ID:     user.ID.String(),
+		Type:   auth.SubjectUser,
+		Scopes: []auth.Scope{auth.ScopeReadCodebase, auth.ScopeLand},
+	}, subject)
+
+	token, err = svc.Get(ctx, token.ID)
+	require.NoError(t, err)
+	if assert.NotNil(t, token.LastUsedAt) && assert.NotNil(t, token.LastUsedIP) {
+		assert.Equal(t, "10.0.0.1", *token.LastUsedIP)
+	}
+
+	for _, invalid := range []string{
+		"",
+		auth.PersonalAccessTokenPrefix,
+		auth.PersonalAccessTokenPrefix + token.ID,
+		auth.PersonalAccessTokenPrefix + token.ID + ".secret",
+		auth.PersonalAccessTokenPrefix + uuid.NewString() + ".secret",
+	} {
+		_, err := svc.Authenticate(ctx, invalid)
+		assert.ErrorIs(t, err, auth.ErrUnauthenticated, invalid)
+	}
+}
+
+func TestAuthenticate_revoked(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+	ctx := context.Background()
+
+	plainText, token, err := svc.Create(ctx, user.ID, "ci", []auth.Scope{auth.ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Revoke(ctx, token))
+
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+
+	tokens, err := svc.List(ctx, user.ID)
+	require.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.True(t, tokens[0].IsRevoked())
+	}
+}
+
+func TestAuthenticate_expired(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Second)
+	plainText, _, err := svc.Create(ctx, user.ID, "ci", []auth.Scope{auth.ScopeReadCodebase}, &expiresAt)
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate(ctx, plainText)
+	require.NoError(t, err)
+
+	time.Sleep(time.Until(expiresAt))
+
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestAuthenticate_deactivatedUser(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+	ctx := context.Background()
+
+	plainText, _, err := svc.Create(ctx, user.ID, "ci", []auth.Scope{auth.ScopeReadCodebase}, nil)
+	require.NoError(t, err)
+
+	user.Status = users.StatusDeactivated
+	require.NoError(t, userRepo.Update(user))
+
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestCreate_invalid(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+	ctx := context.Background()
+
+	_, _, err := svc.Create(ctx, user.ID, "ci", nil, nil)
+	assert.True(t, errors.Is(err, service_personaltokens.ErrInvalidScopes))
+
+	_, _, err = svc.Create(ctx, user.ID, "ci", []auth.Scope{"everything"}, nil)
+	assert.True(t, errors.Is(err, service_personaltokens.ErrInvalidScopes))
+
+	expiresAt := time.Now().Add(-time.Hour)
+	_, _, err = svc.Create(ctx, user.ID, "ci", []auth.Scope{auth.ScopeReadCodebase}, &expiresAt)
+	assert.True(t, errors.Is(err, service_personaltokens.ErrInvalidExpiry))
+}
+
+func TestAuthenticate_lastUsedThrottled(t *testing.T) {
+	svc, userRepo := newService(t)
+	user := newUser(t, userRepo)
+
+	plainText, token, err := svc.Create(context.Background(), user.ID, "ci", []auth.Scope{auth.ScopeReadCodebase}, nil)
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate(ip.NewContext(context.Background(), net.ParseIP("10.0.0.1")), plainText)
+	require.NoError(t, err)
+	first, err := svc.Get(context.Background(), token.ID)
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate(ip.NewContext(context.Background(), net.ParseIP("10.0.0.2")), plainText)
+	require.NoError(t, err)
+	second, err := svc.Get(context.Background(), token.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.LastUsedAt, second.LastUsedAt, "the last use is not recorded on every request")
+	if assert.NotNil(t, second.LastUsedIP) {
+		assert.Equal(t, "10.0.0.1", *second.LastUsedIP)
+	}
+}
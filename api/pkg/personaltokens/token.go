@@ -0,0 +1,60 @@
+package personaltokens
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+var ErrInvalidSecret = errors.New("invalid secret")
+
+// Token is a personal access token. It's owned by a user, and can be used in place of the user's session by
+// automation, restricted to the scopes of the token.
+type Token struct {
+	ID         string         `db:"id"`
+	UserID     users.ID       `db:"user_id"`
+	Name       string         `db:"name"`
+	Hash       []byte         `db:"hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  *time.Time     `db:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	LastUsedIP *string        `db:"last_used_ip"`
+	RevokedAt  *time.Time     `db:"revoked_at"`
+}
+
+// HashSecret returns the hash of the secret of a token, that's stored in place of the secret. The secrets are random,
+// so they don't need a slow password hash, and tokens are verified on every request.
+func HashSecret(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+func (t *Token) Verify(secret string) error {
+	if subtle.ConstantTimeCompare(t.Hash, HashSecret(secret)) != 1 {
+		return ErrInvalidSecret
+	}
+	return nil
+}
+
+func (t *Token) IsExpired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now())
+}
+
+func (t *Token) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *Token) AuthScopes() []auth.Scope {
+	scopes := make([]auth.Scope, 0, len(t.Scopes))
+	for _, scope := range t.Scopes {
+		scopes = append(scopes, auth.Scope(scope))
+	}
+	return scopes
+}
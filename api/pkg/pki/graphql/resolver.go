@@ -26,7 +26,7 @@ func NewResolver(repo db.Repository, users resolvers.UserRootResolver) resolvers
 }
 
 func (p *pkiRootResolver) AddPublicKey(ctx context.Context, args resolvers.AddPublicKeyArgs) (resolvers.UserResolver, error) {
-	userID, err := auth.UserID(ctx)
+	userID, err := auth.UnscopedUserID(ctx)
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"crypto/subtle"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -29,8 +30,12 @@ func AddPublicKey(repo db.Repository) func(c *gin.Context) {
 			return
 		}
 
-		userID, err := auth.UserID(c.Request.Context())
-		if err != nil {
+		userID, err := auth.UnscopedUserID(c.Request.Context())
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		case err != nil:
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
@@ -29,6 +29,7 @@ const (
 	GithubWebhooks                    IncompleteQueueName = "github_webhooks"
 	ViewSnapshot                      IncompleteQueueName = "view_snapshot"
 	CITriggerQueue                    IncompleteQueueName = "ci_trigger"
+	CIWebhookDeliveries               IncompleteQueueName = "ci_webhookDeliveries"
 	longestAllowedName                IncompleteQueueName = "xxxxxXXXXXxxxxxXXXXXxxxx" // To highlight how long a name can be
 )
 
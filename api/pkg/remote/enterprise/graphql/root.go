@@ -67,7 +67,7 @@ func (r *remoteRootResolver) CreateOrUpdateCodebaseRemote(ctx context.Context, a
 		return nil, gqlerror.Error(err)
 	}
 
-	if err := r.authService.CanWrite(ctx, cb); err != nil {
+	if err := r.authService.CanAdmin(ctx, cb); err != nil {
 		return nil, gqlerror.Error(err)
 	}
 
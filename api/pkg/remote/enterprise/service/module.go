@@ -2,7 +2,9 @@ package service
 
 import (
 	analytics_service "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	service_change "getsturdy.com/api/pkg/changes/service"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
 	db_crypto "getsturdy.com/api/pkg/crypto/db"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/logger"
@@ -22,8 +24,10 @@ func Module(c *di.Container) {
 	c.Import(meta_workspaces.Module)
 	c.Import(service_snapshots.Module)
 	c.Import(service_change.Module)
+	c.Import(service_codesearch.Module)
 	c.Import(analytics_service.Module)
 	c.Import(db_crypto.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 	c.Register(func(e *EnterpriseService) remote_service.Service {
 		return e
@@ -19,10 +19,13 @@ import (
 
 	"getsturdy.com/api/pkg/analytics"
 	analytics_service "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/changes/message"
 	service_change "getsturdy.com/api/pkg/changes/service"
 	vcs_change "getsturdy.com/api/pkg/changes/vcs"
 	"getsturdy.com/api/pkg/codebases"
+	service_codesearch "getsturdy.com/api/pkg/codesearch/service"
 	"getsturdy.com/api/pkg/crypto"
 	db_crypto "getsturdy.com/api/pkg/crypto/db"
 	"getsturdy.com/api/pkg/remote"
@@ -44,8 +47,10 @@ type EnterpriseService struct {
 	workspaceWriter   db_workspaces.WorkspaceWriter
 	snap              *service_snapshotter.Service
 	changeService     *service_change.Service
+	codesearchService *service_codesearch.Service
 	analyticsService  *analytics_service.Service
 	keyPairRepository db_crypto.KeyPairRepository
+	auditService      *service_audit.Service
 }
 
 var _ service.Service = (*EnterpriseService)(nil)
@@ -58,8 +63,10 @@ func New(
 	workspaceWriter db_workspaces.WorkspaceWriter,
 	snap *service_snapshotter.Service,
 	changeService *service_change.Service,
+	codesearchService *service_codesearch.Service,
 	analyticsService *analytics_service.Service,
 	keyPairRepository db_crypto.KeyPairRepository,
+	auditService *service_audit.Service,
 ) *EnterpriseService {
 	return &EnterpriseService{
 		repo:              repo,
@@ -69,8 +76,10 @@ func New(
 		workspaceWriter:   workspaceWriter,
 		snap:              snap,
 		changeService:     changeService,
+		codesearchService: codesearchService,
 		analyticsService:  analyticsService,
 		keyPairRepository: keyPairRepository,
+		auditService:      auditService,
 	}
 }
 
@@ -133,6 +142,7 @@ func (svc *EnterpriseService) SetRemote(ctx context.Context, codebaseID codebase
 	switch {
 	case err == nil:
 		// update
+		previous := *rep
 		rep.Name = input.Name
 		rep.URL = input.URL
 		rep.TrackedBranch = input.TrackedBranch
@@ -148,6 +158,8 @@ func (svc *EnterpriseService) SetRemote(ctx context.Context, codebaseID codebase
 
 		svc.analyticsService.Capture(ctx, "updated remote integration", analytics.CodebaseID(codebaseID), analytics.Property("remote_name", rep.Name))
 
+		svc.recordAudit(ctx, &previous, rep)
+
 		return rep, nil
 	case errors.Is(err, sql.ErrNoRows):
 		// create
@@ -171,12 +183,62 @@ func (svc *EnterpriseService) SetRemote(ctx context.Context, codebaseID codebase
 
 		svc.analyticsService.Capture(ctx, "created remote integration", analytics.CodebaseID(codebaseID), analytics.Property("remote_name", r.Name))
 
+		svc.recordAudit(ctx, nil, &r)
+
 		return &r, nil
 	default:
 		return nil, fmt.Errorf("failed to set remote: %w", err)
 	}
 }
 
+// auditMetadata returns the parts of the remote that are safe to store in the audit log. Credentials are never
+// included, only which kind of authentication is configured.
+func auditMetadata(r *remote.Remote) audit.Metadata {
+	md := audit.Metadata{
+		"name":           r.Name,
+		"url":            r.URL,
+		"tracked_branch": r.TrackedBranch,
+		"enabled":        r.Enabled,
+	}
+	switch {
+	case r.KeyPairID != nil:
+		md["auth"] = "keypair"
+		md["key_pair_id"] = string(*r.KeyPairID)
+	case r.BasicAuthUsername != nil:
+		md["auth"] = "basic"
+		md["basic_auth_username"] = *r.BasicAuthUsername
+	}
+	return md
+}
+
+// recordAudit records the change of the remote in the audit log. Failures are logged, the remote has already been saved.
+func (svc *EnterpriseService) recordAudit(ctx context.Context, previous, r *remote.Remote) {
+	entry := &audit.Entry{
+		CodebaseID: &r.CodebaseID,
+		Action:     audit.ActionRemoteUpdated,
+		TargetType: audit.TargetRemote,
+		TargetID:   r.ID,
+		After:      auditMetadata(r),
+	}
+	if previous != nil {
+		entry.Before = auditMetadata(previous)
+		// the credentials themselves are never logged, only if they were changed
+		entry.After["credentials_changed"] = !equalStringPtr(previous.BasicAuthUsername, r.BasicAuthUsername) ||
+			!equalStringPtr(previous.BasicAuthPassword, r.BasicAuthPassword) ||
+			!equalStringPtr((*string)(previous.KeyPairID), (*string)(r.KeyPairID))
+	}
+	if err := svc.auditService.Record(ctx, entry); err != nil {
+		svc.logger.Error("failed to record audit entry", zap.Error(err))
+	}
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 var ErrRemoteDisabled = errors.New("this remote is disabled")
 
 func (svc *EnterpriseService) Push(ctx context.Context, user *users.User, ws *workspaces.Workspace) error {
@@ -299,6 +361,8 @@ func (svc *EnterpriseService) Pull(ctx context.Context, codebaseID codebases.ID)
 		return fmt.Errorf("failed to unset head: %w", err)
 	}
 
+	svc.codesearchService.UpdateInBackground(codebaseID)
+
 	// Allow all workspaces to be rebased/synced on the latest head
 	if err := svc.workspaceWriter.UnsetUpToDateWithTrunkForAllInCodebase(codebaseID); err != nil {
 		return fmt.Errorf("failed to unset up to date with trunk for all in codebase: %w", err)
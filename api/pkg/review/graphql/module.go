@@ -11,6 +11,7 @@ import (
 	"getsturdy.com/api/pkg/logger"
 	"getsturdy.com/api/pkg/notification/sender"
 	db_review "getsturdy.com/api/pkg/review/db"
+	service_review "getsturdy.com/api/pkg/review/service"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
 	service_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/service"
 )
@@ -27,5 +28,6 @@ func Module(c *di.Container) {
 	c.Import(sender.Module)
 	c.Import(service_analytics.Module)
 	c.Import(service_workspace_watchers.Module)
+	c.Import(service_review.Module)
 	c.Register(New)
 }
@@ -21,6 +21,7 @@ import (
 	"getsturdy.com/api/pkg/notification/sender"
 	"getsturdy.com/api/pkg/review"
 	db_review "getsturdy.com/api/pkg/review/db"
+	service_review "getsturdy.com/api/pkg/review/service"
 	"getsturdy.com/api/pkg/users"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
 	service_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/service"
@@ -35,6 +36,7 @@ type reviewRootResolver struct {
 	reviewRepo      db_review.ReviewRepository
 	workspaceReader db_workspaces.WorkspaceReader
 	authService     *service_auth.Service
+	reviewService   *service_review.Service
 
 	authorRootResolver    resolvers.AuthorRootResolver
 	workspaceRootResolver *resolvers.WorkspaceRootResolver
@@ -55,6 +57,7 @@ func New(
 	reviewRepo db_review.ReviewRepository,
 	workspaceReader db_workspaces.WorkspaceReader,
 	authService *service_auth.Service,
+	reviewService *service_review.Service,
 
 	authorRootResolver resolvers.AuthorRootResolver,
 	workspaceRootResolver *resolvers.WorkspaceRootResolver,
@@ -75,6 +78,7 @@ func New(
 		reviewRepo:      reviewRepo,
 		workspaceReader: workspaceReader,
 		authService:     authService,
+		reviewService:   reviewService,
 
 		authorRootResolver:    authorRootResolver,
 		workspaceRootResolver: workspaceRootResolver,
@@ -125,7 +129,7 @@ func (r *reviewRootResolver) CreateOrUpdateReview(ctx context.Context, args reso
 		return nil, gqlerrors.Error(fmt.Errorf("failed to get workspace: %w", err))
 	}
 
-	if err := r.authService.CanWrite(ctx, ws); err != nil {
+	if err := r.authService.CanReview(ctx, ws); err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
@@ -173,6 +177,7 @@ func (r *reviewRootResolver) CreateOrUpdateReview(ctx context.Context, args reso
 		WorkspaceID: workspaceID,
 		Grade:       inputGrade,
 		CreatedAt:   time.Now(),
+		SnapshotID:  ws.LatestSnapshotID,
 	}
 
 	if err := r.reviewRepo.Create(ctx, rev); err != nil {
@@ -225,75 +230,12 @@ func (r *reviewRootResolver) RequestReview(ctx context.Context, args resolvers.R
 		return nil, gqlerrors.Error(err)
 	}
 
-	// requester starts watching the workspace
-	if _, err := r.workspaceWatchersService.Watch(ctx, userID, ws.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to watch workspace: %w", err))
-	}
-
-	// user requested review from starts watching the workspace
-	if _, err := r.workspaceWatchersService.Watch(ctx, users.ID(args.Input.UserID), ws.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to watch workspace: %w", err))
-	}
-
-	if existing, err := r.reviewRepo.GetLatestByUserAndWorkspace(ctx, users.ID(args.Input.UserID), workspaceID); err == nil {
-		// Don't request a review if this user already has a approved or rejected review
-		if existing.DismissedAt == nil && !existing.IsReplaced {
-			return &reviewResolver{root: r, rev: existing}, nil
-		}
-
-		// Mark as replaced, and create a new review
-		existing.IsReplaced = true
-		if err := r.reviewRepo.Update(ctx, existing); err != nil {
-			return nil, gqlerrors.Error(err)
-		}
-
-		// Keep going
-	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return nil, gqlerrors.Error(err)
-	}
-
-	// Create new
-	rev := review.Review{
-		ID:          uuid.NewString(),
-		UserID:      users.ID(args.Input.UserID),
-		CodebaseID:  ws.CodebaseID,
-		WorkspaceID: workspaceID,
-		Grade:       review.ReviewGradeRequested,
-		CreatedAt:   time.Now(),
-		RequestedBy: &userID,
-	}
-
-	if err := r.reviewRepo.Create(ctx, rev); err != nil {
+	rev, err := r.reviewService.RequestReview(ctx, ws, userID, users.ID(args.Input.UserID))
+	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
-	if err := r.activitySender.Codebase(ctx, ws.CodebaseID, ws.ID, userID, activity.TypeRequestedReview, rev.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to create activity: %w", err))
-	}
-
-	// Send notification to the user that the review was requested from
-	if err := r.notificationSender.User(ctx, users.ID(args.Input.UserID), notification.RequestedReviewNotificationType, rev.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to send notification: %w", err))
-	}
-
-	// Send events
-	if err := r.eventsSender.Codebase(ws.CodebaseID, events.WorkspaceUpdatedReviews, ws.ID); err != nil {
-		r.logger.Error("failed to send codebase event", zap.Error(err))
-		// do not fail
-	}
-
-	if err := r.eventPublisher.ReviewUpdated(ctx, eventsv2.Workspace(ws.ID), &rev); err != nil {
-		r.logger.Error("failed to send workspace event", zap.Error(err))
-		// do not fail
-	}
-
-	r.analyticsService.Capture(ctx, "review requested",
-		analytics.CodebaseID(ws.CodebaseID),
-		analytics.Property("workspace_id", ws.ID),
-		analytics.Property("user_id", rev.UserID),
-	)
-
-	return &reviewResolver{root: r, rev: &rev}, nil
+	return &reviewResolver{root: r, rev: rev}, nil
 }
 
 func (r *reviewRootResolver) DismissReview(ctx context.Context, args resolvers.DismissReviewArgs) (resolvers.ReviewResolver, error) {
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"getsturdy.com/api/pkg/activity/sender"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/events"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/logger"
+	notification_sender "getsturdy.com/api/pkg/notification/sender"
+	db_review "getsturdy.com/api/pkg/review/db"
+	service_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(db_review.Module)
+	c.Import(events.Module)
+	c.Import(eventsv2.Module)
+	c.Import(notification_sender.Module)
+	c.Import(sender.Module)
+	c.Import(service_analytics.Module)
+	c.Import(service_workspace_watchers.Module)
+	c.Register(New)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/activity"
+	activity_sender "getsturdy.com/api/pkg/activity/sender"
+	"getsturdy.com/api/pkg/analytics"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/events"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/notification"
+	"getsturdy.com/api/pkg/notification/sender"
+	"getsturdy.com/api/pkg/review"
+	db_review "getsturdy.com/api/pkg/review/db"
+	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/pkg/workspaces"
+	service_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type Service struct {
+	logger *zap.Logger
+
+	reviewRepo db_review.ReviewRepository
+
+	eventsSender       events.EventSender
+	eventPublisher     *eventsv2.Publisher
+	notificationSender sender.NotificationSender
+	activitySender     activity_sender.ActivitySender
+
+	analyticsService         *service_analytics.Service
+	workspaceWatchersService *service_workspace_watchers.Service
+}
+
+func New(
+	logger *zap.Logger,
+
+	reviewRepo db_review.ReviewRepository,
+
+	eventsSender events.EventSender,
+	eventPublisher *eventsv2.Publisher,
+	notificationSender sender.NotificationSender,
+	activitySender activity_sender.ActivitySender,
+
+	analyticsService *service_analytics.Service,
+	workspaceWatchersService *service_workspace_watchers.Service,
+) *Service {
+	return &Service{
+		logger: logger.Named("reviewService"),
+
+		reviewRepo: reviewRepo,
+
+		eventsSender:       eventsSender,
+		eventPublisher:     eventPublisher,
+		notificationSender: notificationSender,
+		activitySender:     activitySender,
+
+		analyticsService:         analyticsService,
+		workspaceWatchersService: workspaceWatchersService,
+	}
+}
+
+// ListLatestByWorkspace returns the latest, non dismissed, review of each reviewer of the workspace.
+func (s *Service) ListLatestByWorkspace(ctx context.Context, workspaceID string) ([]*review.Review, error) {
+	return s.reviewRepo.ListLatestByWorkspace(ctx, workspaceID)
+}
+
+// RequestReview requests userID to review the workspace. If the user has already reviewed the workspace, or a
+// review is already requested, the existing review is returned.
+func (s *Service) RequestReview(ctx context.Context, ws *workspaces.Workspace, requestedBy, userID users.ID) (*review.Review, error) {
+	// requester starts watching the workspace
+	if _, err := s.workspaceWatchersService.Watch(ctx, requestedBy, ws.ID); err != nil {
+		return nil, fmt.Errorf("failed to watch workspace: %w", err)
+	}
+
+	// user requested review from starts watching the workspace
+	if _, err := s.workspaceWatchersService.Watch(ctx, userID, ws.ID); err != nil {
+		return nil, fmt.Errorf("failed to watch workspace: %w", err)
+	}
+
+	if existing, err := s.reviewRepo.GetLatestByUserAndWorkspace(ctx, userID, ws.ID); err == nil {
+		// Don't request a review if this user already has a approved or rejected review
+		if existing.DismissedAt == nil && !existing.IsReplaced {
+			return existing, nil
+		}
+
+		// Mark as replaced, and create a new review
+		existing.IsReplaced = true
+		if err := s.reviewRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+
+		// Keep going
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Create new
+	rev := review.Review{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		CodebaseID:  ws.CodebaseID,
+		WorkspaceID: ws.ID,
+		Grade:       review.ReviewGradeRequested,
+		CreatedAt:   time.Now(),
+		RequestedBy: &requestedBy,
+	}
+
+	if err := s.reviewRepo.Create(ctx, rev); err != nil {
+		return nil, err
+	}
+
+	if err := s.activitySender.Codebase(ctx, ws.CodebaseID, ws.ID, requestedBy, activity.TypeRequestedReview, rev.ID); err != nil {
+		return nil, fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	// Send notification to the user that the review was requested from
+	if err := s.notificationSender.User(ctx, userID, notification.RequestedReviewNotificationType, rev.ID); err != nil {
+		return nil, fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	// Send events
+	if err := s.eventsSender.Codebase(ws.CodebaseID, events.WorkspaceUpdatedReviews, ws.ID); err != nil {
+		s.logger.Error("failed to send codebase event", zap.Error(err))
+		// do not fail
+	}
+
+	if err := s.eventPublisher.ReviewUpdated(ctx, eventsv2.Workspace(ws.ID), &rev); err != nil {
+		s.logger.Error("failed to send workspace event", zap.Error(err))
+		// do not fail
+	}
+
+	s.analyticsService.Capture(ctx, "review requested",
+		analytics.CodebaseID(ws.CodebaseID),
+		analytics.Property("workspace_id", ws.ID),
+		analytics.Property("user_id", rev.UserID),
+	)
+
+	return &rev, nil
+}
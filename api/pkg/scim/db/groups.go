@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ GroupRepository = &groupRepository{}
+
+type groupRepository struct {
+	db *sqlx.DB
+}
+
+func NewGroupRepository(db *sqlx.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) Create(ctx context.Context, group *scim.Group) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO scim_groups
+		(id, organization_id, display_name, external_id, created_at, updated_at)
+		VALUES
+		(:id, :organization_id, :display_name, :external_id, :created_at, :updated_at)`, group); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) Get(ctx context.Context, id string) (*scim.Group, error) {
+	var group scim.Group
+	if err := r.db.GetContext(ctx, &group, `SELECT id, organization_id, display_name, external_id, created_at, updated_at
+		FROM scim_groups
+		WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Group, error) {
+	var groups []*scim.Group
+	if err := r.db.SelectContext(ctx, &groups, `SELECT id, organization_id, display_name, external_id, created_at, updated_at
+		FROM scim_groups
+		WHERE organization_id = $1
+		ORDER BY created_at`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) ListByOrganizationIDAndUserID(ctx context.Context, organizationID string, userID users.ID) ([]*scim.Group, error) {
+	var groups []*scim.Group
+	if err := r.db.SelectContext(ctx, &groups, `SELECT g.id, g.organization_id, g.display_name, g.external_id, g.created_at, g.updated_at
+		FROM scim_groups g
+		JOIN scim_group_members m ON m.group_id = g.id
+		WHERE g.organization_id = $1
+		  AND m.user_id = $2
+		ORDER BY g.created_at`, organizationID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) Update(ctx context.Context, group *scim.Group) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE scim_groups
+		SET display_name = :display_name,
+		    external_id = :external_id,
+		    updated_at = :updated_at
+		WHERE id = :id`, group); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM scim_group_members WHERE group_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete members: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM scim_groups WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) AddMember(ctx context.Context, groupID string, userID users.ID) error {
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO scim_group_members (group_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, groupID, userID); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) RemoveMember(ctx context.Context, groupID string, userID users.ID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM scim_group_members
+		WHERE group_id = $1
+		  AND user_id = $2`, groupID, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) ListMembers(ctx context.Context, groupID string) ([]users.ID, error) {
+	var userIDs []users.ID
+	if err := r.db.SelectContext(ctx, &userIDs, `SELECT user_id
+		FROM scim_group_members
+		WHERE group_id = $1
+		ORDER BY user_id`, groupID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return userIDs, nil
+}
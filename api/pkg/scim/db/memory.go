@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ TokenRepository = &memoryTokenRepository{}
+
+type memoryTokenRepository struct {
+	mu     sync.RWMutex
+	tokens []scim.Token
+}
+
+func NewMemoryTokenRepository() TokenRepository {
+	return &memoryTokenRepository{}
+}
+
+func (r *memoryTokenRepository) Create(_ context.Context, token *scim.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *memoryTokenRepository) Get(_ context.Context, id string) (*scim.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, t := range r.tokens {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryTokenRepository) ListByOrganizationID(_ context.Context, organizationID string) ([]*scim.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var res []*scim.Token
+	for _, t := range r.tokens {
+		if t.OrganizationID == organizationID {
+			t2 := t
+			res = append(res, &t2)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryTokenRepository) Update(_ context.Context, token *scim.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, t := range r.tokens {
+		if t.ID == token.ID {
+			r.tokens[k] = *token
+		}
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, t := range r.tokens {
+		if t.ID == id {
+			r.tokens = append(r.tokens[:k], r.tokens[k+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+var _ GroupRepository = &memoryGroupRepository{}
+
+type memoryGroupRepository struct {
+	mu      sync.RWMutex
+	groups  []scim.Group
+	members map[string]map[users.ID]struct{}
+}
+
+func NewMemoryGroupRepository() GroupRepository {
+	return &memoryGroupRepository{members: make(map[string]map[users.ID]struct{})}
+}
+
+func (r *memoryGroupRepository) Create(_ context.Context, group *scim.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups = append(r.groups, *group)
+	return nil
+}
+
+func (r *memoryGroupRepository) Get(_ context.Context, id string) (*scim.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, g := range r.groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryGroupRepository) ListByOrganizationID(_ context.Context, organizationID string) ([]*scim.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var res []*scim.Group
+	for _, g := range r.groups {
+		if g.OrganizationID == organizationID {
+			g2 := g
+			res = append(res, &g2)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryGroupRepository) ListByOrganizationIDAndUserID(_ context.Context, organizationID string, userID users.ID) ([]*scim.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var res []*scim.Group
+	for _, g := range r.groups {
+		if _, ok := r.members[g.ID][userID]; ok && g.OrganizationID == organizationID {
+			g2 := g
+			res = append(res, &g2)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryGroupRepository) Update(_ context.Context, group *scim.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, g := range r.groups {
+		if g.ID == group.ID {
+			r.groups[k] = *group
+		}
+	}
+	return nil
+}
+
+func (r *memoryGroupRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members, id)
+	for k, g := range r.groups {
+		if g.ID == id {
+			r.groups = append(r.groups[:k], r.groups[k+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryGroupRepository) AddMember(_ context.Context, groupID string, userID users.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.members[groupID] == nil {
+		r.members[groupID] = make(map[users.ID]struct{})
+	}
+	r.members[groupID][userID] = struct{}{}
+	return nil
+}
+
+func (r *memoryGroupRepository) RemoveMember(_ context.Context, groupID string, userID users.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members[groupID], userID)
+	return nil
+}
+
+func (r *memoryGroupRepository) ListMembers(_ context.Context, groupID string) ([]users.ID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]users.ID, 0, len(r.members[groupID]))
+	for userID := range r.members[groupID] {
+		res = append(res, userID)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+var _ UserRepository = &memoryUserRepository{}
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users []scim.User
+}
+
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{}
+}
+
+func (r *memoryUserRepository) Create(_ context.Context, user *scim.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = append(r.users, *user)
+	return nil
+}
+
+func (r *memoryUserRepository) Get(_ context.Context, organizationID string, userID users.ID) (*scim.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, u := range r.users {
+		if u.OrganizationID == organizationID && u.UserID == userID {
+			return &u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryUserRepository) ListByOrganizationID(_ context.Context, organizationID string) ([]*scim.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var res []*scim.User
+	for _, u := range r.users {
+		if u.OrganizationID == organizationID {
+			u2 := u
+			res = append(res, &u2)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryUserRepository) Update(_ context.Context, user *scim.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, u := range r.users {
+		if u.OrganizationID == user.OrganizationID && u.UserID == user.UserID {
+			r.users[k] = *user
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(_ context.Context, organizationID string, userID users.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, u := range r.users {
+		if u.OrganizationID == organizationID && u.UserID == userID {
+			r.users = append(r.users[:k], r.users[k+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+type TokenRepository interface {
+	Create(context.Context, *scim.Token) error
+	Get(ctx context.Context, id string) (*scim.Token, error)
+	ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Token, error)
+	Update(context.Context, *scim.Token) error
+	Delete(ctx context.Context, id string) error
+}
+
+type GroupRepository interface {
+	Create(context.Context, *scim.Group) error
+	Get(ctx context.Context, id string) (*scim.Group, error)
+	ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Group, error)
+	// ListByOrganizationIDAndUserID lists the groups in the organization that the user is a member of.
+	ListByOrganizationIDAndUserID(ctx context.Context, organizationID string, userID users.ID) ([]*scim.Group, error)
+	Update(context.Context, *scim.Group) error
+	Delete(ctx context.Context, id string) error
+
+	AddMember(ctx context.Context, groupID string, userID users.ID) error
+	RemoveMember(ctx context.Context, groupID string, userID users.ID) error
+	ListMembers(ctx context.Context, groupID string) ([]users.ID, error)
+}
+
+type UserRepository interface {
+	Create(context.Context, *scim.User) error
+	Get(ctx context.Context, organizationID string, userID users.ID) (*scim.User, error)
+	ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.User, error)
+	Update(context.Context, *scim.User) error
+	Delete(ctx context.Context, organizationID string, userID users.ID) error
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/scim"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ TokenRepository = &tokenRepository{}
+
+type tokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewTokenRepository(db *sqlx.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) Create(ctx context.Context, token *scim.Token) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO scim_tokens
+		(id, organization_id, hash, created_by, created_at, last_used_at)
+		VALUES
+		(:id, :organization_id, :hash, :created_by, :created_at, :last_used_at)`, token); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) Get(ctx context.Context, id string) (*scim.Token, error) {
+	var token scim.Token
+	if err := r.db.GetContext(ctx, &token, `SELECT id, organization_id, hash, created_by, created_at, last_used_at
+		FROM scim_tokens
+		WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Token, error) {
+	var tokens []*scim.Token
+	if err := r.db.SelectContext(ctx, &tokens, `SELECT id, organization_id, hash, created_by, created_at, last_used_at
+		FROM scim_tokens
+		WHERE organization_id = $1
+		ORDER BY created_at`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *tokenRepository) Update(ctx context.Context, token *scim.Token) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE scim_tokens
+		SET last_used_at = :last_used_at
+		WHERE id = :id`, token); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM scim_tokens WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
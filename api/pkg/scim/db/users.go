@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ UserRepository = &userRepository{}
+
+type userRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *scim.User) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO scim_users
+		(organization_id, user_id, active, created_at, updated_at)
+		VALUES
+		(:organization_id, :user_id, :active, :created_at, :updated_at)`, user); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) Get(ctx context.Context, organizationID string, userID users.ID) (*scim.User, error) {
+	var user scim.User
+	if err := r.db.GetContext(ctx, &user, `SELECT organization_id, user_id, active, created_at, updated_at
+		FROM scim_users
+		WHERE organization_id = $1
+		  AND user_id = $2`, organizationID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.User, error) {
+	var uu []*scim.User
+	if err := r.db.SelectContext(ctx, &uu, `SELECT organization_id, user_id, active, created_at, updated_at
+		FROM scim_users
+		WHERE organization_id = $1
+		ORDER BY created_at`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return uu, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *scim.User) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE scim_users
+		SET active = :active,
+			updated_at = :updated_at
+		WHERE organization_id = :organization_id
+		  AND user_id = :user_id`, user); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, organizationID string, userID users.ID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM scim_users
+		WHERE organization_id = $1
+		  AND user_id = $2`, organizationID, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/scim"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type groupResource struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  *string  `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []member `json:"members"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+type member struct {
+	Value string `json:"value"`
+}
+
+func memberIDs(members []member) []users.ID {
+	ids := make([]users.ID, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, users.ID(m.Value))
+	}
+	return ids
+}
+
+func toGroupResource(ctx context.Context, scimService *service_scim.Service, group *scim.Group) (*groupResource, error) {
+	userIDs, err := scimService.GroupMembers(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]member, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, member{Value: userID.String()})
+	}
+
+	return &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          group.ID,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     members,
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt.Format(time.RFC3339),
+			LastModified: group.UpdatedAt.Format(time.RFC3339),
+		},
+	}, nil
+}
+
+func respondWithGroup(c *gin.Context, logger *zap.Logger, scimService *service_scim.Service, status int, group *scim.Group) {
+	resource, err := toGroupResource(c.Request.Context(), scimService, group)
+	if err != nil {
+		abortWithServiceError(c, logger, err)
+		return
+	}
+	respond(c, status, resource)
+}
+
+func ListGroups(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f, err := parseFilter(c.Query("filter"))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+
+		ctx := c.Request.Context()
+		groups, err := scimService.ListGroups(ctx, tokenFromContext(c).OrganizationID)
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		resources := []any{}
+		for _, group := range groups {
+			if f != nil {
+				switch f.attribute {
+				case "displayname":
+					if group.DisplayName != f.value {
+						continue
+					}
+				case "externalid":
+					if group.ExternalID == nil || *group.ExternalID != f.value {
+						continue
+					}
+				default:
+					abortWithError(c, http.StatusBadRequest, "invalidFilter", fmt.Sprintf("filtering on %s is not supported", f.attribute))
+					return
+				}
+			}
+
+			resource, err := toGroupResource(ctx, scimService, group)
+			if err != nil {
+				abortWithServiceError(c, logger, err)
+				return
+			}
+			resources = append(resources, resource)
+		}
+
+		respondWithList(c, resources)
+	}
+}
+
+func GetGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group, err := scimService.GetGroup(c.Request.Context(), tokenFromContext(c).OrganizationID, c.Param("id"))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+		respondWithGroup(c, logger, scimService, http.StatusOK, group)
+	}
+}
+
+func CreateGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req groupResource
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		if req.DisplayName == "" {
+			abortWithError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+			return
+		}
+
+		group, err := scimService.CreateGroup(c.Request.Context(), tokenFromContext(c), req.DisplayName, req.ExternalID, memberIDs(req.Members))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		respondWithGroup(c, logger, scimService, http.StatusCreated, group)
+	}
+}
+
+func ReplaceGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req groupResource
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		if req.DisplayName == "" {
+			abortWithError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+			return
+		}
+
+		ctx := c.Request.Context()
+		token := tokenFromContext(c)
+		group, err := scimService.GetGroup(ctx, token.OrganizationID, c.Param("id"))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		group.DisplayName = req.DisplayName
+		group.ExternalID = req.ExternalID
+		if err := scimService.UpdateGroup(ctx, group); err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		if err := scimService.SetGroupMembers(ctx, token, group, memberIDs(req.Members)); err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		respondWithGroup(c, logger, scimService, http.StatusOK, group)
+	}
+}
+
+func PatchGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req patchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		ctx := c.Request.Context()
+		token := tokenFromContext(c)
+		group, err := scimService.GetGroup(ctx, token.OrganizationID, c.Param("id"))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		for _, op := range req.Operations {
+			if err := patchGroup(ctx, scimService, token, group, op); err != nil {
+				if errors.Is(err, errInvalidPatch) {
+					abortWithError(c, http.StatusBadRequest, "invalidValue", err.Error())
+					return
+				}
+				abortWithServiceError(c, logger, err)
+				return
+			}
+		}
+
+		respondWithGroup(c, logger, scimService, http.StatusOK, group)
+	}
+}
+
+var errInvalidPatch = errors.New("invalid patch operation")
+
+func patchGroup(ctx context.Context, scimService *service_scim.Service, token *scim.Token, group *scim.Group, op patchOperation) error {
+	path := strings.ToLower(op.Path)
+	switch {
+	// {"op": "add", "path": "members", "value": [{"value": "id"}]}
+	case path == "members":
+		var members []member
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return fmt.Errorf("%w: members must be a list", errInvalidPatch)
+			}
+		}
+		switch strings.ToLower(op.Op) {
+		case "add":
+			return scimService.AddGroupMembers(ctx, token, group, memberIDs(members))
+		case "replace":
+			return scimService.SetGroupMembers(ctx, token, group, memberIDs(members))
+		case "remove":
+			if len(op.Value) == 0 {
+				return scimService.SetGroupMembers(ctx, token, group, nil)
+			}
+			return scimService.RemoveGroupMembers(ctx, group, memberIDs(members))
+		}
+
+	// {"op": "remove", "path": "members[value eq \"id\"]"}
+	case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]"):
+		f, err := parseFilter(op.Path[len("members[") : len(op.Path)-1])
+		if err != nil || f == nil || f.attribute != "value" {
+			return fmt.Errorf("%w: unsupported path %s", errInvalidPatch, op.Path)
+		}
+		if strings.EqualFold(op.Op, "remove") {
+			return scimService.RemoveGroupMembers(ctx, group, []users.ID{users.ID(f.value)})
+		}
+
+	// {"op": "replace", "path": "displayName", "value": "name"}
+	case path == "displayname":
+		if err := json.Unmarshal(op.Value, &group.DisplayName); err != nil {
+			return fmt.Errorf("%w: displayName must be a string", errInvalidPatch)
+		}
+		return scimService.UpdateGroup(ctx, group)
+
+	case path == "externalid":
+		if err := json.Unmarshal(op.Value, &group.ExternalID); err != nil {
+			return fmt.Errorf("%w: externalId must be a string", errInvalidPatch)
+		}
+		return scimService.UpdateGroup(ctx, group)
+
+	// {"op": "replace", "value": {"displayName": "name"}}
+	case path == "":
+		var value map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return fmt.Errorf("%w: value must be an object", errInvalidPatch)
+		}
+		for k, v := range value {
+			if err := patchGroup(ctx, scimService, token, group, patchOperation{Op: op.Op, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func DeleteGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		group, err := scimService.GetGroup(ctx, tokenFromContext(c).OrganizationID, c.Param("id"))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		if err := scimService.DeleteGroup(ctx, group); err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"getsturdy.com/api/pkg/auth"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/scim"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	contentType = "application/scim+json"
+
+	schemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	tokenContextKey = "scim-token"
+)
+
+// Register registers the SCIM 2.0 api (RFC 7644) on the router group. All requests must be authenticated with an
+// organization scoped bearer token.
+func Register(rg *gin.RouterGroup, logger *zap.Logger, scimService *service_scim.Service) {
+	logger = logger.With(zap.String("handler", "routes/scim"))
+	rg.Use(Authenticate(logger, scimService))
+	rg.GET("/Users", ListUsers(logger, scimService))
+	rg.POST("/Users", CreateUser(logger, scimService))
+	rg.GET("/Users/:id", GetUser(logger, scimService))
+	rg.PUT("/Users/:id", ReplaceUser(logger, scimService))
+	rg.PATCH("/Users/:id", PatchUser(logger, scimService))
+	rg.DELETE("/Users/:id", DeleteUser(logger, scimService))
+	rg.GET("/Groups", ListGroups(logger, scimService))
+	rg.POST("/Groups", CreateGroup(logger, scimService))
+	rg.GET("/Groups/:id", GetGroup(logger, scimService))
+	rg.PUT("/Groups/:id", ReplaceGroup(logger, scimService))
+	rg.PATCH("/Groups/:id", PatchGroup(logger, scimService))
+	rg.DELETE("/Groups/:id", DeleteGroup(logger, scimService))
+}
+
+// Authenticate is a middleware that authenticates the bearer token of the request.
+func Authenticate(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer := c.GetHeader("Authorization")
+		if !strings.HasPrefix(bearer, "Bearer ") {
+			abortWithError(c, http.StatusUnauthorized, "", "missing bearer token")
+			return
+		}
+
+		token, err := scimService.Authenticate(c.Request.Context(), strings.TrimPrefix(bearer, "Bearer "))
+		if errors.Is(err, service_scim.ErrUnauthenticated) {
+			abortWithError(c, http.StatusUnauthorized, "", "invalid bearer token")
+			return
+		} else if err != nil {
+			logger.Error("failed to authenticate", zap.Error(err))
+			abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+
+		c.Set(tokenContextKey, token)
+		c.Next()
+	}
+}
+
+func tokenFromContext(c *gin.Context) *scim.Token {
+	return c.MustGet(tokenContextKey).(*scim.Token)
+}
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func abortWithError(c *gin.Context, status int, scimType, detail string) {
+	c.Header("Content-Type", contentType)
+	c.AbortWithStatusJSON(status, errorResponse{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func abortWithServiceError(c *gin.Context, logger *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, service_scim.ErrNotFound):
+		abortWithError(c, http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, service_scim.ErrExists):
+		abortWithError(c, http.StatusConflict, "uniqueness", "resource already exists")
+	case errors.Is(err, service_scim.ErrInvalidMember):
+		abortWithError(c, http.StatusBadRequest, "invalidValue", err.Error())
+	case errors.Is(err, auth.ErrForbidden), errors.Is(err, service_organization.ErrLastOwner):
+		abortWithError(c, http.StatusForbidden, "", err.Error())
+	default:
+		logger.Error("scim request failed", zap.Error(err))
+		abortWithError(c, http.StatusInternalServerError, "", "")
+	}
+}
+
+func respond(c *gin.Context, status int, v any) {
+	c.Header("Content-Type", contentType)
+	c.JSON(status, v)
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// respondWithList responds with the page of the resources that is requested with the startIndex and count
+// parameters.
+func respondWithList(c *gin.Context, resources []any) {
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(len(resources))))
+	if err != nil || count < 0 {
+		count = 0
+	}
+
+	page := []any{}
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[startIndex-1 : end]
+	}
+
+	respond(c, http.StatusOK, listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+type filter struct {
+	attribute string
+	value     string
+}
+
+// parseFilter parses filters on the form `attribute eq "value"`, which is the only kind of filter that is supported.
+func parseFilter(raw string) (*filter, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(raw), " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, errors.New("only eq filters are supported")
+	}
+
+	value, err := strconv.Unquote(parts[2])
+	if err != nil {
+		return nil, errors.New("filter value must be a string")
+	}
+
+	return &filter{attribute: strings.ToLower(parts[0]), value: value}, nil
+}
+
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
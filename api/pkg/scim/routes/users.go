@@ -0,0 +1,288 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type userResource struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+type name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+func toUserResource(user *service_scim.User) *userResource {
+	active := user.Active && user.Status != users.StatusDeactivated
+	resource := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          user.ID.String(),
+		UserName:    user.Email,
+		Name:        &name{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []email{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta:        &meta{ResourceType: "User"},
+	}
+	if user.CreatedAt != nil {
+		resource.Meta.Created = user.CreatedAt.Format(time.RFC3339)
+	}
+	return resource
+}
+
+// email returns the email address of the user. The userName is used if it's an email address, otherwise the
+// primary email.
+func (r *userResource) email() string {
+	if strings.Contains(r.UserName, "@") {
+		return r.UserName
+	}
+	for _, e := range r.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(r.Emails) > 0 {
+		return r.Emails[0].Value
+	}
+	return ""
+}
+
+func (r *userResource) name() string {
+	switch {
+	case r.Name != nil && r.Name.Formatted != "":
+		return r.Name.Formatted
+	case r.Name != nil && (r.Name.GivenName != "" || r.Name.FamilyName != ""):
+		return strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+	default:
+		return r.DisplayName
+	}
+}
+
+func ListUsers(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f, err := parseFilter(c.Query("filter"))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+			return
+		}
+
+		uu, err := scimService.ListUsers(c.Request.Context(), tokenFromContext(c).OrganizationID)
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		resources := []any{}
+		for _, user := range uu {
+			if f != nil {
+				switch f.attribute {
+				case "username", "emails.value", "emails":
+					if !strings.EqualFold(user.Email, f.value) {
+						continue
+					}
+				default:
+					abortWithError(c, http.StatusBadRequest, "invalidFilter", fmt.Sprintf("filtering on %s is not supported", f.attribute))
+					return
+				}
+			}
+			resources = append(resources, toUserResource(user))
+		}
+
+		respondWithList(c, resources)
+	}
+}
+
+func GetUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := scimService.GetUser(c.Request.Context(), tokenFromContext(c).OrganizationID, users.ID(c.Param("id")))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, toUserResource(user))
+	}
+}
+
+func CreateUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req userResource
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		email := req.email()
+		if email == "" {
+			abortWithError(c, http.StatusBadRequest, "invalidValue", "userName or emails must contain an email address")
+			return
+		}
+
+		active := req.Active == nil || *req.Active
+		user, err := scimService.CreateUser(c.Request.Context(), tokenFromContext(c), email, req.name(), active)
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		respond(c, http.StatusCreated, toUserResource(user))
+	}
+}
+
+// ReplaceUser updates the user. Only the active attribute can be changed, the other attributes of the user are
+// owned by the user themselves.
+func ReplaceUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req userResource
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		ctx := c.Request.Context()
+		token := tokenFromContext(c)
+		user, err := scimService.GetUser(ctx, token.OrganizationID, users.ID(c.Param("id")))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		if req.Active != nil {
+			if err := scimService.SetActive(ctx, token, user, *req.Active); err != nil {
+				abortWithServiceError(c, logger, err)
+				return
+			}
+		}
+
+		respond(c, http.StatusOK, toUserResource(user))
+	}
+}
+
+// PatchUser updates the user. Only the active attribute can be changed, operations on other attributes are
+// ignored.
+func PatchUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req patchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+			return
+		}
+
+		ctx := c.Request.Context()
+		token := tokenFromContext(c)
+		user, err := scimService.GetUser(ctx, token.OrganizationID, users.ID(c.Param("id")))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		for _, op := range req.Operations {
+			if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+				continue
+			}
+
+			active, ok, err := patchActive(op)
+			if err != nil {
+				abortWithError(c, http.StatusBadRequest, "invalidValue", err.Error())
+				return
+			}
+			if !ok {
+				continue
+			}
+
+			if err := scimService.SetActive(ctx, token, user, active); err != nil {
+				abortWithServiceError(c, logger, err)
+				return
+			}
+		}
+
+		respond(c, http.StatusOK, toUserResource(user))
+	}
+}
+
+// patchActive returns the value of the active attribute, if the operation sets it.
+func patchActive(op patchOperation) (bool, bool, error) {
+	switch {
+	case strings.EqualFold(op.Path, "active"):
+		active, err := parseBool(op.Value)
+		return active, err == nil, err
+	case op.Path == "":
+		var value map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return false, false, fmt.Errorf("invalid value: %w", err)
+		}
+		for k, v := range value {
+			if strings.EqualFold(k, "active") {
+				active, err := parseBool(v)
+				return active, err == nil, err
+			}
+		}
+		return false, false, nil
+	default:
+		return false, false, nil
+	}
+}
+
+// parseBool parses booleans, and strings containing booleans, as some identity providers send "False" instead of
+// false.
+func parseBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, fmt.Errorf("invalid boolean: %s", raw)
+	}
+	b, err := strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean: %s", raw)
+	}
+	return b, nil
+}
+
+// DeleteUser removes the user from the organization, and deactivates them if the organization owns their account.
+func DeleteUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		token := tokenFromContext(c)
+		user, err := scimService.GetUser(ctx, token.OrganizationID, users.ID(c.Param("id")))
+		if err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		if err := scimService.RemoveUser(ctx, token, user); err != nil {
+			abortWithServiceError(c, logger, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,44 @@
+package scim
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Token authenticates an identity provider that provisions the users of an organization.
+type Token struct {
+	ID             string     `db:"id"`
+	OrganizationID string     `db:"organization_id"`
+	Hash           []byte     `db:"hash"`
+	CreatedBy      users.ID   `db:"created_by"`
+	CreatedAt      time.Time  `db:"created_at"`
+	LastUsedAt     *time.Time `db:"last_used_at"`
+}
+
+func (t *Token) Verify(secret string) error {
+	return bcrypt.CompareHashAndPassword(t.Hash, []byte(secret))
+}
+
+// Group is a group of users in an organization, provisioned by an identity provider. Groups can be used as
+// groups::<display name> in the acls of the codebases in the organization.
+type Group struct {
+	ID             string    `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	DisplayName    string    `db:"display_name"`
+	ExternalID     *string   `db:"external_id"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// User is a user that has been provisioned by the identity provider of an organization. Users that are deactivated by
+// the identity provider are removed from the organization, but stay provisioned so that they can be reactivated.
+type User struct {
+	OrganizationID string    `db:"organization_id"`
+	UserID         users.ID  `db:"user_id"`
+	Active         bool      `db:"active"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
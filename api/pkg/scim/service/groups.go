@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+func (s *Service) ListGroups(ctx context.Context, organizationID string) ([]*scim.Group, error) {
+	return s.groupRepo.ListByOrganizationID(ctx, organizationID)
+}
+
+// GetGroup returns the group, if it's in the organization.
+func (s *Service) GetGroup(ctx context.Context, organizationID, id string) (*scim.Group, error) {
+	group, err := s.groupRepo.Get(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	if group.OrganizationID != organizationID {
+		return nil, ErrNotFound
+	}
+	return group, nil
+}
+
+// CreateGroup creates a group in the organization of the token.
+func (s *Service) CreateGroup(ctx context.Context, token *scim.Token, displayName string, externalID *string, memberIDs []users.ID) (*scim.Group, error) {
+	if err := s.requireUniqueDisplayName(ctx, token.OrganizationID, "", displayName); err != nil {
+		return nil, err
+	}
+	if err := s.requireUsers(ctx, token.OrganizationID, memberIDs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	group := &scim.Group{
+		ID:             uuid.NewString(),
+		OrganizationID: token.OrganizationID,
+		DisplayName:    displayName,
+		ExternalID:     externalID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if err := s.AddGroupMembers(ctx, token, group, memberIDs); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// UpdateGroup saves the display name and the external id of the group.
+func (s *Service) UpdateGroup(ctx context.Context, group *scim.Group) error {
+	if err := s.requireUniqueDisplayName(ctx, group.OrganizationID, group.ID, group.DisplayName); err != nil {
+		return err
+	}
+
+	group.UpdatedAt = time.Now()
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) requireUniqueDisplayName(ctx context.Context, organizationID, groupID, displayName string) error {
+	groups, err := s.groupRepo.ListByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to list groups: %w", err)
+	}
+	for _, group := range groups {
+		if group.ID != groupID && group.DisplayName == displayName {
+			return ErrExists
+		}
+	}
+	return nil
+}
+
+func (s *Service) DeleteGroup(ctx context.Context, group *scim.Group) error {
+	if err := s.groupRepo.Delete(ctx, group.ID); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) GroupMembers(ctx context.Context, group *scim.Group) ([]users.ID, error) {
+	return s.groupRepo.ListMembers(ctx, group.ID)
+}
+
+// AddGroupMembers adds the users to the group. Only users that are members of the organization, or that have been
+// provisioned by its identity provider, can be added.
+func (s *Service) AddGroupMembers(ctx context.Context, token *scim.Token, group *scim.Group, userIDs []users.ID) error {
+	if err := s.requireUsers(ctx, token.OrganizationID, userIDs); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := s.groupRepo.AddMember(ctx, group.ID, userID); err != nil {
+			return fmt.Errorf("failed to add group member: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) requireUsers(ctx context.Context, organizationID string, userIDs []users.ID) error {
+	for _, userID := range userIDs {
+		if _, err := s.GetUser(ctx, organizationID, userID); errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: user %s not found", ErrInvalidMember, userID)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveGroupMembers removes the users from the group. They stay members of the organization.
+func (s *Service) RemoveGroupMembers(ctx context.Context, group *scim.Group, userIDs []users.ID) error {
+	for _, userID := range userIDs {
+		if err := s.groupRepo.RemoveMember(ctx, group.ID, userID); err != nil {
+			return fmt.Errorf("failed to remove group member: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetGroupMembers replaces the members of the group.
+func (s *Service) SetGroupMembers(ctx context.Context, token *scim.Token, group *scim.Group, userIDs []users.ID) error {
+	current, err := s.groupRepo.ListMembers(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list group members: %w", err)
+	}
+
+	wanted := make(map[users.ID]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = struct{}{}
+	}
+
+	var removed []users.ID
+	for _, userID := range current {
+		if _, ok := wanted[userID]; !ok {
+			removed = append(removed, userID)
+		}
+	}
+
+	if err := s.RemoveGroupMembers(ctx, group, removed); err != nil {
+		return err
+	}
+	return s.AddGroupMembers(ctx, token, group, userIDs)
+}
+
+// Groups returns the display names of the groups in the organization that the user is a member of.
+func (s *Service) Groups(ctx context.Context, organizationID string, userID users.ID) ([]string, error) {
+	groups, err := s.groupRepo.ListByOrganizationIDAndUserID(ctx, organizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.DisplayName)
+	}
+	return names, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/di"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/logger"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	db_scim "getsturdy.com/api/pkg/scim/db"
+	service_user "getsturdy.com/api/pkg/users/service/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(db_scim.Module)
+	c.Import(db_codebases.Module)
+	c.Import(service_user.Module)
+	c.Import(service_organization.Module)
+	c.Import(service_jwt.Module)
+	c.Import(service_analytics.Module)
+	c.Import(service_audit.Module)
+	c.Register(New)
+}
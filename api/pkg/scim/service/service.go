@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/organization"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/scim"
+	db_scim "getsturdy.com/api/pkg/scim/db"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnauthenticated = errors.New("invalid token")
+	ErrNotFound        = errors.New("not found")
+	ErrExists          = errors.New("already exists")
+	ErrInvalidMember   = errors.New("invalid group member")
+)
+
+const (
+	bcryptCost = bcrypt.DefaultCost
+
+	// tokenSeparator separates the token id from the secret in plaintext tokens.
+	tokenSeparator = "."
+)
+
+type Service struct {
+	logger              *zap.Logger
+	tokenRepo           db_scim.TokenRepository
+	groupRepo           db_scim.GroupRepository
+	userRepo            db_scim.UserRepository
+	codebaseRepo        db_codebases.CodebaseRepository
+	codebaseUserRepo    db_codebases.CodebaseUserRepository
+	userService         service_user.Service
+	organizationService *service_organization.Service
+	jwtService          *service_jwt.Service
+	analyticsService    *service_analytics.Service
+	auditService        *service_audit.Service
+}
+
+func New(
+	logger *zap.Logger,
+	tokenRepo db_scim.TokenRepository,
+	groupRepo db_scim.GroupRepository,
+	userRepo db_scim.UserRepository,
+	codebaseRepo db_codebases.CodebaseRepository,
+	codebaseUserRepo db_codebases.CodebaseUserRepository,
+	userService service_user.Service,
+	organizationService *service_organization.Service,
+	jwtService *service_jwt.Service,
+	analyticsService *service_analytics.Service,
+	auditService *service_audit.Service,
+) *Service {
+	return &Service{
+		logger:              logger.Named("scim"),
+		tokenRepo:           tokenRepo,
+		groupRepo:           groupRepo,
+		userRepo:            userRepo,
+		codebaseRepo:        codebaseRepo,
+		codebaseUserRepo:    codebaseUserRepo,
+		userService:         userService,
+		organizationService: organizationService,
+		jwtService:          jwtService,
+		analyticsService:    analyticsService,
+		auditService:        auditService,
+	}
+}
+
+// CreateToken creates a new token for the organization. It returns the token in plaintext, which is not stored and
+// can not be recovered.
+func (s *Service) CreateToken(ctx context.Context, organizationID string, createdBy users.ID) (string, *scim.Token, error) {
+	secret := uuid.NewString()
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcryptCost)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token hash: %w", err)
+	}
+
+	token := &scim.Token{
+		ID:             uuid.NewString(),
+		OrganizationID: organizationID,
+		Hash:           hash,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, createdBy, "created scim token")
+
+	if err := s.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &organizationID,
+		Action:         audit.ActionScimTokenCreated,
+		TargetType:     audit.TargetScimToken,
+		TargetID:       token.ID,
+	}); err != nil {
+		s.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
+	return token.ID + tokenSeparator + secret, token, nil
+}
+
+func (s *Service) GetToken(ctx context.Context, id string) (*scim.Token, error) {
+	return s.tokenRepo.Get(ctx, id)
+}
+
+func (s *Service) ListTokens(ctx context.Context, organizationID string) ([]*scim.Token, error) {
+	return s.tokenRepo.ListByOrganizationID(ctx, organizationID)
+}
+
+func (s *Service) DeleteToken(ctx context.Context, token *scim.Token) error {
+	if err := s.tokenRepo.Delete(ctx, token.ID); err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, &audit.Entry{
+		OrganizationID: &token.OrganizationID,
+		Action:         audit.ActionScimTokenDeleted,
+		TargetType:     audit.TargetScimToken,
+		TargetID:       token.ID,
+	}); err != nil {
+		s.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
+	return nil
+}
+
+// Authenticate returns the token that the plaintext token belongs to. Tokens stop working if the user that created
+// them is no longer an admin of the organization.
+func (s *Service) Authenticate(ctx context.Context, plainTextToken string) (*scim.Token, error) {
+	id, secret, ok := strings.Cut(plainTextToken, tokenSeparator)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	token, err := s.tokenRepo.Get(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrUnauthenticated
+	default:
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if err := token.Verify(secret); err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	isAdmin, err := s.organizationService.HasRole(ctx, token.OrganizationID, token.CreatedBy, organization.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check role: %w", err)
+	}
+	if !isAdmin {
+		return nil, ErrUnauthenticated
+	}
+
+	now := time.Now()
+	token.LastUsedAt = &now
+	if err := s.tokenRepo.Update(ctx, token); err != nil {
+		s.logger.Error("failed to update token", zap.Error(err))
+		// do not fail
+	}
+
+	return token, nil
+}
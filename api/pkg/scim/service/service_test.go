@@ -0,0 +1,334 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/analytics/disabled"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/jwt"
+	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	sender_notifications "getsturdy.com/api/pkg/notification/sender"
+	"getsturdy.com/api/pkg/organization"
+	db_organization "getsturdy.com/api/pkg/organization/db"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	db_scim "getsturdy.com/api/pkg/scim/db"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+	"getsturdy.com/api/pkg/version"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testService struct {
+	*service_scim.Service
+
+	userRepo            db_users.Repository
+	codebaseRepo        db_codebases.CodebaseRepository
+	codebaseUserRepo    db_codebases.CodebaseUserRepository
+	organizationService *service_organization.Service
+	jwtService          *service_jwt.Service
+}
+
+func newService(t *testing.T) *testService {
+	logger := zap.NewNop()
+	analyticsService := service_analytics.New(logger, disabled.NewClient(logger))
+	userRepo := db_users.NewMemory()
+	userService := service_users.New(logger, userRepo, analyticsService)
+	organizationMemberRepo := db_organization.NewInMemoryOrganizationMemberRepository()
+	organizationService := service_organization.New(
+		logger,
+		events.NewPublisher(events.New(logger), nil, nil, organizationMemberRepo),
+		db_organization.NewInMemoryOrganizationRepo(),
+		organizationMemberRepo,
+		analyticsService,
+		sender_notifications.NewNoopNotificationSender(),
+		service_audit.New(db_audit.NewMemory(), nil),
+	)
+	jwtService := service_jwt.NewService(logger, db_keys.NewInMemory(), db_revocations.NewInMemory())
+	codebaseRepo := db_codebases.NewMemory()
+	codebaseUserRepo := db_codebases.NewInMemoryCodebaseUserRepo()
+
+	return &testService{
+		Service: service_scim.New(
+			logger,
+			db_scim.NewMemoryTokenRepository(),
+			db_scim.NewMemoryGroupRepository(),
+			db_scim.NewMemoryUserRepository(),
+			codebaseRepo,
+			codebaseUserRepo,
+			userService,
+			organizationService,
+			jwtService,
+			analyticsService,
+			service_audit.New(db_audit.NewMemory(), nil),
+		),
+		userRepo:            userRepo,
+		codebaseRepo:        codebaseRepo,
+		codebaseUserRepo:    codebaseUserRepo,
+		organizationService: organizationService,
+		jwtService:          jwtService,
+	}
+}
+
+// newOrganization creates an organization, and returns it together with it's owner.
+func (s *testService) newOrganization(t *testing.T) (*organization.Organization, *users.User) {
+	owner := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com", Status: users.StatusActive}
+	require.NoError(t, s.userRepo.Create(owner))
+
+	org, err := s.organizationService.Create(context.Background(), owner.ID, "org")
+	require.NoError(t, err)
+	return org, owner
+}
+
+func TestAuthenticate(t *testing.T) {
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+
+	plainText, token, err := svc.CreateToken(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+
+	authenticated, err := svc.Authenticate(ctx, plainText)
+	if assert.NoError(t, err) {
+		assert.Equal(t, token.ID, authenticated.ID)
+		assert.Equal(t, org.ID, authenticated.OrganizationID)
+		assert.NotNil(t, authenticated.LastUsedAt)
+	}
+
+	_, err = svc.Authenticate(ctx, token.ID+".wrong")
+	assert.ErrorIs(t, err, service_scim.ErrUnauthenticated)
+
+	_, err = svc.Authenticate(ctx, "malformed")
+	assert.ErrorIs(t, err, service_scim.ErrUnauthenticated)
+
+	require.NoError(t, svc.DeleteToken(ctx, token))
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, service_scim.ErrUnauthenticated)
+}
+
+func TestAuthenticate_creatorNotAdmin(t *testing.T) {
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+
+	admin := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@example.com"}
+	require.NoError(t, svc.userRepo.Create(admin))
+	_, err := svc.organizationService.AddMember(ctx, org.ID, admin.ID, owner.ID)
+	require.NoError(t, err)
+	_, err = svc.organizationService.SetRole(ctx, org.ID, admin.ID, organization.RoleAdmin, owner.ID)
+	require.NoError(t, err)
+
+	plainText, _, err := svc.CreateToken(ctx, org.ID, admin.ID)
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate(ctx, plainText)
+	require.NoError(t, err)
+
+	_, err = svc.organizationService.SetRole(ctx, org.ID, admin.ID, organization.RoleMember, owner.ID)
+	require.NoError(t, err)
+
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, service_scim.ErrUnauthenticated)
+}
+
+func TestUsers(t *testing.T) {
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+
+	_, token, err := svc.CreateToken(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+
+	user, err := svc.CreateUser(ctx, token, "new@example.com", "New User", true)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.Equal(t, users.StatusShadow, user.Status)
+
+	_, err = svc.CreateUser(ctx, token, "new@example.com", "New User", true)
+	assert.ErrorIs(t, err, service_scim.ErrExists)
+
+	uu, err := svc.ListUsers(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Len(t, uu, 2)
+
+	got, err := svc.GetUser(ctx, org.ID, user.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.ID, got.ID)
+		assert.True(t, got.Active)
+	}
+
+	authToken, err := svc.jwtService.IssueToken(ctx, user.ID.String(), time.Hour, jwt.TokenTypeAuth)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SetActive(ctx, token, user, false))
+	assert.False(t, user.Active)
+	assert.Equal(t, users.StatusDeactivated, user.Status, "self-hosted installations own the accounts of their users")
+
+	_, err = svc.jwtService.Verify(ctx, authToken.Token, jwt.TokenTypeAuth)
+	assert.Error(t, err, "tokens of deactivated users must be revoked")
+
+	_, err = svc.organizationService.GetMember(ctx, org.ID, user.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "deactivated users are removed from the organization")
+
+	got, err = svc.GetUser(ctx, org.ID, user.ID)
+	if assert.NoError(t, err, "deactivated users can be reactivated") {
+		assert.False(t, got.Active)
+	}
+
+	require.NoError(t, svc.SetActive(ctx, token, got, true))
+	assert.True(t, got.Active)
+	assert.Equal(t, users.StatusShadow, got.Status)
+
+	_, err = svc.organizationService.GetMember(ctx, org.ID, user.ID)
+	assert.NoError(t, err)
+
+	group, err := svc.CreateGroup(ctx, token, "engineering", nil, []users.ID{user.ID})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RemoveUser(ctx, token, got))
+	assert.Equal(t, users.StatusDeactivated, got.Status)
+
+	_, err = svc.GetUser(ctx, org.ID, user.ID)
+	assert.ErrorIs(t, err, service_scim.ErrNotFound)
+
+	members, err := svc.GroupMembers(ctx, group)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestCreateUser_existingAccount(t *testing.T) {
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+
+	_, token, err := svc.CreateToken(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+
+	outsider := &users.User{ID: users.ID(uuid.NewString()), Email: "outsider@example.com", Status: users.StatusActive}
+	require.NoError(t, svc.userRepo.Create(outsider))
+
+	_, err = svc.CreateUser(ctx, token, outsider.Email, "", true)
+	assert.ErrorIs(t, err, service_scim.ErrExists, "existing accounts are not attached by email")
+
+	_, err = svc.organizationService.GetMember(ctx, org.ID, outsider.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	member := &users.User{ID: users.ID(uuid.NewString()), Email: "member@example.com", Status: users.StatusActive}
+	require.NoError(t, svc.userRepo.Create(member))
+	_, err = svc.organizationService.AddMember(ctx, org.ID, member.ID, owner.ID)
+	require.NoError(t, err)
+
+	provisioned, err := svc.CreateUser(ctx, token, member.Email, "", true)
+	if assert.NoError(t, err, "members of the organization can be provisioned") {
+		assert.Equal(t, member.ID, provisioned.ID)
+	}
+}
+
+func TestSetActive_cloud(t *testing.T) {
+	distributionType := version.Type
+	version.Type = version.DistributionTypeCloud
+	t.Cleanup(func() { version.Type = distributionType })
+
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+	otherOrg, otherOwner := svc.newOrganization(t)
+
+	_, token, err := svc.CreateToken(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+
+	orgID := org.ID
+	cb := codebases.Codebase{ID: codebases.ID(uuid.NewString()), OrganizationID: &orgID}
+	require.NoError(t, svc.codebaseRepo.Create(cb))
+
+	user, err := svc.CreateUser(ctx, token, "shared@example.com", "", true)
+	require.NoError(t, err)
+	require.NoError(t, svc.codebaseUserRepo.Create(codebases.CodebaseUser{ID: uuid.NewString(), UserID: user.ID, CodebaseID: cb.ID}))
+	_, err = svc.organizationService.AddMember(ctx, otherOrg.ID, user.ID, otherOwner.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SetActive(ctx, token, user, false))
+	assert.False(t, user.Active)
+	assert.Equal(t, users.StatusShadow, user.Status, "the account is not deactivated while the user is in other organizations")
+
+	_, err = svc.organizationService.GetMember(ctx, org.ID, user.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = svc.codebaseUserRepo.GetByUserAndCodebase(user.ID, cb.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "access to the codebases of the organization is removed")
+	_, err = svc.organizationService.GetMember(ctx, otherOrg.ID, user.ID)
+	assert.NoError(t, err, "the user stays in other organizations")
+
+	require.NoError(t, svc.SetActive(ctx, token, user, true))
+	require.NoError(t, svc.organizationService.RemoveMember(ctx, otherOrg.ID, user.ID, otherOwner.ID))
+
+	require.NoError(t, svc.SetActive(ctx, token, user, false))
+	assert.Equal(t, users.StatusDeactivated, user.Status, "the account is deactivated if the user is in no other organization")
+}
+
+func TestGroups(t *testing.T) {
+	svc := newService(t)
+	ctx := context.Background()
+	org, owner := svc.newOrganization(t)
+
+	_, token, err := svc.CreateToken(ctx, org.ID, owner.ID)
+	require.NoError(t, err)
+
+	alice, err := svc.CreateUser(ctx, token, "alice@example.com", "", true)
+	require.NoError(t, err)
+	bob, err := svc.CreateUser(ctx, token, "bob@example.com", "", true)
+	require.NoError(t, err)
+
+	outsider := &users.User{ID: users.ID(uuid.NewString()), Email: "outsider@example.com"}
+	require.NoError(t, svc.userRepo.Create(outsider))
+	_, err = svc.CreateGroup(ctx, token, "outsiders", nil, []users.ID{outsider.ID})
+	assert.ErrorIs(t, err, service_scim.ErrInvalidMember, "only users in the organization can be added to groups")
+
+	group, err := svc.CreateGroup(ctx, token, "engineering", nil, []users.ID{alice.ID})
+	require.NoError(t, err)
+
+	_, err = svc.CreateGroup(ctx, token, "engineering", nil, nil)
+	assert.ErrorIs(t, err, service_scim.ErrExists)
+
+	groups, err := svc.Groups(ctx, org.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering"}, groups)
+
+	require.NoError(t, svc.SetGroupMembers(ctx, token, group, []users.ID{bob.ID}))
+
+	members, err := svc.GroupMembers(ctx, group)
+	require.NoError(t, err)
+	assert.Equal(t, []users.ID{bob.ID}, members)
+
+	groups, err = svc.Groups(ctx, org.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	group.DisplayName = "platform"
+	require.NoError(t, svc.UpdateGroup(ctx, group))
+
+	groups, err = svc.Groups(ctx, org.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, groups)
+
+	_, err = svc.GetGroup(ctx, "other-organization", group.ID)
+	assert.ErrorIs(t, err, service_scim.ErrNotFound)
+
+	require.NoError(t, svc.DeleteGroup(ctx, group))
+	_, err = svc.GetGroup(ctx, org.ID, group.ID)
+	assert.ErrorIs(t, err, service_scim.ErrNotFound)
+}
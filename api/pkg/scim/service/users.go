@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+	"getsturdy.com/api/pkg/version"
+
+	"go.uber.org/zap"
+)
+
+// User is a user as seen by the identity provider of an organization.
+type User struct {
+	*users.User
+	// Active is false if the identity provider has deactivated the user in the organization.
+	Active bool
+}
+
+// ListUsers lists the members of the organization, and the users that have been provisioned and deactivated by the
+// identity provider of the organization.
+func (s *Service) ListUsers(ctx context.Context, organizationID string) ([]*User, error) {
+	members, err := s.organizationService.Members(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	provisioned, err := s.userRepo.ListByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provisioned users: %w", err)
+	}
+
+	active := make(map[users.ID]bool, len(members)+len(provisioned))
+	userIDs := make([]users.ID, 0, len(members)+len(provisioned))
+	for _, member := range members {
+		active[member.UserID] = true
+		userIDs = append(userIDs, member.UserID)
+	}
+	for _, p := range provisioned {
+		if _, isMember := active[p.UserID]; !isMember {
+			active[p.UserID] = false
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	uu, err := s.userService.GetByIDs(ctx, userIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	res := make([]*User, 0, len(uu))
+	for _, u := range uu {
+		res = append(res, &User{User: u, Active: active[u.ID]})
+	}
+	return res, nil
+}
+
+// GetUser returns the user, if they are a member of the organization, or if they have been provisioned by the
+// identity provider of the organization.
+func (s *Service) GetUser(ctx context.Context, organizationID string, userID users.ID) (*User, error) {
+	var active bool
+	if provisioned, err := s.userRepo.Get(ctx, organizationID, userID); err == nil {
+		active = provisioned.Active
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get provisioned user: %w", err)
+	} else if _, err := s.organizationService.GetMember(ctx, organizationID, userID); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	} else {
+		active = true
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &User{User: user, Active: active}, nil
+}
+
+// CreateUser provisions the user with the email in the organization of the token. Users that don't exist are created
+// as shadow users, that become active when they log in for the first time.
+//
+// Existing users are only provisioned if they are already members of the organization. The identity provider of an
+// organization does not own the accounts of users outside of it, so they must be invited to the organization first.
+func (s *Service) CreateUser(ctx context.Context, token *scim.Token, email, name string, active bool) (*User, error) {
+	user, err := s.userService.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if _, err := s.userRepo.Get(ctx, token.OrganizationID, user.ID); err == nil {
+			return nil, ErrExists
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get provisioned user: %w", err)
+		}
+		if _, err := s.organizationService.GetMember(ctx, token.OrganizationID, user.ID); errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExists
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get member: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		var namePtr *string
+		if name != "" {
+			namePtr = &name
+		}
+		if user, err = s.userService.CreateShadow(ctx, email, service_user.UserReferer(token.CreatedBy), namePtr); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		if _, err := s.organizationService.AddMember(ctx, token.OrganizationID, user.ID, token.CreatedBy); err != nil {
+			return nil, fmt.Errorf("failed to add member: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.userRepo.Create(ctx, &scim.User{
+		OrganizationID: token.OrganizationID,
+		UserID:         user.ID,
+		Active:         true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create provisioned user: %w", err)
+	}
+
+	provisioned := &User{User: user, Active: true}
+	if !active {
+		if err := s.SetActive(ctx, token, provisioned, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return provisioned, nil
+}
+
+// SetActive deactivates or reactivates the user in the organization of the token. Deactivated users are removed from
+// the organization and from its codebases.
+//
+// The account of the user is only deactivated if the organization owns it, that is on self-hosted installations, or
+// if the user is not a member of any other organization. Deactivated accounts can't log in, all of their tokens are
+// revoked, and they can't connect to their views.
+func (s *Service) SetActive(ctx context.Context, token *scim.Token, user *User, active bool) error {
+	ownsAccount, err := s.ownsAccount(ctx, token.OrganizationID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if active {
+		if _, err := s.organizationService.AddMember(ctx, token.OrganizationID, user.ID, token.CreatedBy); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		if ownsAccount && user.Status == users.StatusDeactivated {
+			if err := s.userService.Reactivate(ctx, user.User); err != nil {
+				return fmt.Errorf("failed to reactivate user: %w", err)
+			}
+		}
+	} else {
+		if err := s.removeMember(ctx, token, user.ID); err != nil {
+			return err
+		}
+		if ownsAccount {
+			if err := s.userService.Deactivate(ctx, user.User); err != nil {
+				return fmt.Errorf("failed to deactivate user: %w", err)
+			}
+			if err := s.jwtService.Revoke(ctx, user.ID.String()); err != nil {
+				return fmt.Errorf("failed to revoke tokens: %w", err)
+			}
+		}
+	}
+
+	if err := s.setProvisioned(ctx, token.OrganizationID, user.ID, active); err != nil {
+		return err
+	}
+	user.Active = active
+	return nil
+}
+
+// RemoveUser removes the user from the organization of the token and from it's groups, and deactivates them.
+func (s *Service) RemoveUser(ctx context.Context, token *scim.Token, user *User) error {
+	groups, err := s.groupRepo.ListByOrganizationIDAndUserID(ctx, token.OrganizationID, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list groups: %w", err)
+	}
+	for _, group := range groups {
+		if err := s.groupRepo.RemoveMember(ctx, group.ID, user.ID); err != nil {
+			return fmt.Errorf("failed to remove group member: %w", err)
+		}
+	}
+
+	if err := s.SetActive(ctx, token, user, false); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(ctx, token.OrganizationID, user.ID); err != nil {
+		return fmt.Errorf("failed to delete provisioned user: %w", err)
+	}
+	return nil
+}
+
+// ownsAccount returns true if the organization owns the account of the user, and can deactivate it.
+func (s *Service) ownsAccount(ctx context.Context, organizationID string, userID users.ID) (bool, error) {
+	if version.Type != version.DistributionTypeCloud {
+		// self-hosted installations belong to a single company
+		return true, nil
+	}
+
+	orgs, err := s.organizationService.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	for _, org := range orgs {
+		if org.ID != organizationID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// removeMember removes the user from the organization, and from the codebases in it.
+func (s *Service) removeMember(ctx context.Context, token *scim.Token, userID users.ID) error {
+	if err := s.organizationService.RemoveMember(ctx, token.OrganizationID, userID, token.CreatedBy); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	cbs, err := s.codebaseRepo.ListByOrganization(ctx, token.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to list codebases: %w", err)
+	}
+	for _, cb := range cbs {
+		member, err := s.codebaseUserRepo.GetByUserAndCodebase(userID, cb.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to get codebase member: %w", err)
+		}
+
+		if err := s.codebaseUserRepo.DeleteByID(ctx, member.ID); err != nil {
+			return fmt.Errorf("failed to remove codebase member: %w", err)
+		}
+
+		codebaseID := cb.ID
+		if err := s.auditService.Record(ctx, &audit.Entry{
+			OrganizationID: &token.OrganizationID,
+			CodebaseID:     &codebaseID,
+			Action:         audit.ActionCodebaseMemberRemoved,
+			TargetType:     audit.TargetUser,
+			TargetID:       userID.String(),
+		}); err != nil {
+			s.logger.Error("failed to record audit entry", zap.Error(err))
+			// do not fail
+		}
+	}
+	return nil
+}
+
+func (s *Service) setProvisioned(ctx context.Context, organizationID string, userID users.ID, active bool) error {
+	provisioned, err := s.userRepo.Get(ctx, organizationID, userID)
+	switch {
+	case err == nil:
+		provisioned.Active = active
+		provisioned.UpdatedAt = time.Now()
+		if err := s.userRepo.Update(ctx, provisioned); err != nil {
+			return fmt.Errorf("failed to update provisioned user: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		// members that were added to the organization before it was connected to the identity provider
+		now := time.Now()
+		if err := s.userRepo.Create(ctx, &scim.User{
+			OrganizationID: organizationID,
+			UserID:         userID,
+			Active:         active,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}); err != nil {
+			return fmt.Errorf("failed to create provisioned user: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to get provisioned user: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
@@ -34,12 +35,17 @@ func New(
 }
 
 func (r *rootResolver) CreateServiceToken(ctx context.Context, args resolvers.CreateServiceTokenArgs) (resolvers.ServiceTokenResovler, error) {
+	// service tokens are not restricted to scopes, and don't expire
+	if _, err := auth.AdminUserID(ctx); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
 	codebase, err := r.codebaseService.GetByShortID(ctx, codebases.ShortCodebaseID(args.Input.ShortCodebaseID))
 	if err != nil {
 		return nil, gqlerror.Error(fmt.Errorf("codebase not found: %w", err))
 	}
 
-	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+	if err := r.authService.CanAdmin(ctx, codebase); err != nil {
 		return nil, gqlerror.Error(err)
 	}
 
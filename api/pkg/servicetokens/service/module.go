@@ -1,11 +1,15 @@
 package service
 
 import (
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
 	db_servicetokens "getsturdy.com/api/pkg/servicetokens/db"
 )
 
 func Module(c *di.Container) {
+	c.Import(logger.Module)
 	c.Import(db_servicetokens.Module)
+	c.Import(service_audit.Module)
 	c.Register(New)
 }
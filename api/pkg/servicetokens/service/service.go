@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"time"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/servicetokens"
 	db_servicetokens "getsturdy.com/api/pkg/servicetokens/db"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,14 +21,20 @@ const (
 )
 
 type Service struct {
-	repo db_servicetokens.Repository
+	logger       *zap.Logger
+	repo         db_servicetokens.Repository
+	auditService *service_audit.Service
 }
 
 func New(
+	logger *zap.Logger,
 	repo db_servicetokens.Repository,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
-		repo: repo,
+		logger:       logger,
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
@@ -50,6 +59,17 @@ func (s *Service) Create(ctx context.Context, codebaseID codebases.ID, name stri
 		return "", nil, fmt.Errorf("failed to create: %w", err)
 	}
 
+	if err := s.auditService.Record(ctx, &audit.Entry{
+		CodebaseID: &codebaseID,
+		Action:     audit.ActionServiceTokenCreated,
+		TargetType: audit.TargetServiceToken,
+		TargetID:   token.ID,
+		After:      audit.Metadata{"name": token.Name},
+	}); err != nil {
+		s.logger.Error("failed to record audit entry", zap.Error(err))
+		// do not fail
+	}
+
 	return plainTextToken, token, nil
 }
 
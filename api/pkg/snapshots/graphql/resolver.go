@@ -61,6 +61,8 @@ func (r *resolver) Description(context.Context) (*string, error) {
 		return p("undo patch"), nil
 	case snapshots.ActionSuggestionApply:
 		return p("suggestion apply"), nil
+	case snapshots.ActionFileCherryPick:
+		return p("cherry pick"), nil
 	default:
 		return nil, nil
 	}
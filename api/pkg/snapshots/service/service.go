@@ -163,6 +163,39 @@ func (s *Service) GetByID(_ context.Context, id snapshots.ID) (*snapshots.Snapsh
 	return s.snapshotsRepo.Get(id)
 }
 
+// ChangedPaths returns the paths of the files that are different between the two snapshots. Both the old and the new
+// path of moved files are returned.
+func (s *Service) ChangedPaths(ctx context.Context, from, to *snapshots.Snapshot) ([]string, error) {
+	var paths []string
+	if err := s.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		diff, err := repo.DiffCommits(from.CommitSHA, to.CommitSHA)
+		if err != nil {
+			return fmt.Errorf("failed to get diff: %w", err)
+		}
+		defer diff.Free()
+
+		numDeltas, err := diff.NumDeltas()
+		if err != nil {
+			return fmt.Errorf("failed to get number of deltas: %w", err)
+		}
+
+		for i := 0; i < numDeltas; i++ {
+			delta, err := diff.Delta(i)
+			if err != nil {
+				return fmt.Errorf("failed to get delta: %w", err)
+			}
+			paths = append(paths, delta.NewFile.Path)
+			if delta.OldFile.Path != delta.NewFile.Path {
+				paths = append(paths, delta.OldFile.Path)
+			}
+		}
+		return nil
+	}).ExecTrunk(to.CodebaseID, "snapshotChangedPaths"); err != nil {
+		return nil, fmt.Errorf("failed to get changed paths: %w", err)
+	}
+	return paths, nil
+}
+
 // Previous returns a snapshot that was made before this one.
 func (s *Service) Previous(ctx context.Context, snapshot *snapshots.Snapshot) (*snapshots.Snapshot, error) {
 	if snapshot.PreviousSnapshotID == nil {
@@ -328,6 +361,32 @@ func (s *Service) Snapshot(ctx context.Context, codebaseID codebases.ID, workspa
 		return nil
 	}
 
+	countCommitDiffs := func(repo vcs.RepoGitReader, commitSHA string) error {
+		parents, err := repo.GetCommitParents(commitSHA)
+		if err != nil {
+			return fmt.Errorf("can't get commit parents: %w", err)
+		}
+		if len(parents) != 1 {
+			return fmt.Errorf("unexpected number of commit parents: %d, expected %d", len(parents), 1)
+		}
+
+		gitDiffs, err := repo.DiffCommits(parents[0], commitSHA)
+		if err != nil {
+			return fmt.Errorf("can't get git diffs: %w", err)
+		}
+		defer gitDiffs.Free()
+
+		diffs, err := unidiff.NewUnidiff(unidiff.NewGitPatchReader(gitDiffs), s.logger).
+			WithExpandedHunks().
+			Decorate()
+		if err != nil {
+			return fmt.Errorf("can't decorate git diffs: %w", err)
+		}
+		diffsCount = int32(len(diffs))
+
+		return nil
+	}
+
 	gitSignature := git.Signature{
 		Name:  "Sturdy",
 		Email: "support@getsturdy.com",
@@ -359,6 +418,10 @@ func (s *Service) Snapshot(ctx context.Context, codebaseID codebases.ID, workspa
 		if err := compareTreeIDs(latest, snapshotCommitSHA)(options.onRepo); err != nil {
 			return nil, fmt.Errorf("can't compare trees: %w", err)
 		}
+
+		if err := countCommitDiffs(options.onRepo, snapshotCommitSHA); err != nil {
+			return nil, fmt.Errorf("can't count diffs: %w", err)
+		}
 	} else if options.onRepo != nil && options.onView != nil {
 		if err := countDiffs(options.onRepo); err != nil {
 			return nil, fmt.Errorf("can't count diffs: %w", err)
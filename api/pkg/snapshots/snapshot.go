@@ -54,6 +54,7 @@ const (
 	ActionFileUndoChange            Action = "file_undo_change"
 	ActionFileIgnore                Action = "file_ignore"
 	ActionFileRevert                Action = "file_revert"
+	ActionFileCherryPick            Action = "file_cherry_pick"
 	ActionChangeLand                Action = "change_land"
 	ActionPreChangeLand             Action = "pre_change_land"
 	ActionPreCheckoutOtherView      Action = "pre_checkout_other_view"
@@ -62,4 +63,5 @@ const (
 	ActionChangeReverted            Action = "change_reverted"
 	ActionSuggestionApply           Action = "suggestion_apply"
 	ActionCITrigger                 Action = "ci_trigger"
+	ActionGitPush                   Action = "git_push"
 )
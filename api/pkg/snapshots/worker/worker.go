@@ -4,19 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"getsturdy.com/api/pkg/codebases"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
 	"getsturdy.com/api/pkg/queue"
 	"getsturdy.com/api/pkg/queue/names"
 	"getsturdy.com/api/pkg/snapshots"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
 	"getsturdy.com/api/pkg/users"
 	service_users "getsturdy.com/api/pkg/users/service"
+	service_workspaces "getsturdy.com/api/pkg/workspaces/service"
 
 	"go.uber.org/zap"
 )
 
+// codeOwnerReviewsDelay is how long to wait after a snapshot before requesting reviews from the code owners. The
+// changes of the workspace are diffed once for all snapshots that are made in the meantime.
+const codeOwnerReviewsDelay = 30 * time.Second
+
 type Queue interface {
 	Enqueue(ctx context.Context, codebaseID codebases.ID, viewID, workspaceID string, userID users.ID, action snapshots.Action) error
 	Start(ctx context.Context) error
@@ -27,8 +34,13 @@ type q struct {
 	queue  queue.Queue
 	name   names.IncompleteQueueName
 
-	snapshotter *service_snapshots.Service
-	userService service_users.Service
+	snapshotter       *service_snapshots.Service
+	userService       service_users.Service
+	workspaceService  *service_workspaces.Service
+	codeOwnersService *service_codeowners.Service
+
+	pendingCodeOwnerReviewsMu sync.Mutex
+	pendingCodeOwnerReviews   map[string]struct{}
 }
 
 func New(
@@ -36,13 +48,19 @@ func New(
 	queue queue.Queue,
 	snapshotter *service_snapshots.Service,
 	userService service_users.Service,
+	workspaceService *service_workspaces.Service,
+	codeOwnersService *service_codeowners.Service,
 ) Queue {
 	return &q{
-		logger:      logger.Named("snapshotterQueue"),
-		queue:       queue,
-		name:        names.ViewSnapshot,
-		snapshotter: snapshotter,
-		userService: userService,
+		logger:            logger.Named("snapshotterQueue"),
+		queue:             queue,
+		name:              names.ViewSnapshot,
+		snapshotter:       snapshotter,
+		userService:       userService,
+		workspaceService:  workspaceService,
+		codeOwnersService: codeOwnersService,
+
+		pendingCodeOwnerReviews: make(map[string]struct{}),
 	}
 }
 
@@ -114,6 +132,10 @@ func (q *q) Start(ctx context.Context) error {
 				options...,
 			)
 
+			if err == nil {
+				q.requestCodeOwnerReviewsLater(logger, m.WorkspaceID)
+			}
+
 			cancelTimeout()
 
 			if errors.Is(err, service_snapshots.ErrCantSnapshotRebasing) {
@@ -144,3 +166,46 @@ func (q *q) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// requestCodeOwnerReviewsLater requests reviews from the code owners after codeOwnerReviewsDelay, unless a request is
+// already pending for the workspace. The pending request covers all changes made until it runs.
+func (q *q) requestCodeOwnerReviewsLater(logger *zap.Logger, workspaceID string) {
+	q.pendingCodeOwnerReviewsMu.Lock()
+	defer q.pendingCodeOwnerReviewsMu.Unlock()
+
+	if _, pending := q.pendingCodeOwnerReviews[workspaceID]; pending {
+		return
+	}
+	q.pendingCodeOwnerReviews[workspaceID] = struct{}{}
+
+	time.AfterFunc(codeOwnerReviewsDelay, func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.Error("panic when requesting code owner reviews", zap.String("panic", fmt.Sprintf("%v", rec)))
+			}
+		}()
+
+		// snapshots made from now on need a new request
+		q.pendingCodeOwnerReviewsMu.Lock()
+		delete(q.pendingCodeOwnerReviews, workspaceID)
+		q.pendingCodeOwnerReviewsMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		q.requestCodeOwnerReviews(ctx, logger, workspaceID)
+	})
+}
+
+// requestCodeOwnerReviews requests reviews from the owners of the files that are changed in the workspace
+func (q *q) requestCodeOwnerReviews(ctx context.Context, logger *zap.Logger, workspaceID string) {
+	ws, err := q.workspaceService.GetByID(ctx, workspaceID)
+	if err != nil {
+		logger.Error("failed to get workspace", zap.Error(err))
+		return
+	}
+
+	if err := q.codeOwnersService.RequestReviews(ctx, ws); err != nil {
+		logger.Error("failed to request code owner reviews", zap.Error(err))
+		// don't fail
+	}
+}
@@ -0,0 +1,170 @@
+package statuses
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	doublestar "github.com/bmatcuk/doublestar/v4"
+)
+
+const maxCheckPatternLength = 255
+
+type CheckState string
+
+const (
+	CheckStatePassed  CheckState = "passed"
+	CheckStatePending CheckState = "pending"
+	CheckStateFailing CheckState = "failing"
+	// CheckStateMissing is the state of checks that no status has been reported for yet
+	CheckStateMissing CheckState = "missing"
+)
+
+// Check is a status check that must pass before a workspace can be landed.
+type Check struct {
+	// Pattern is a status title, or a glob that matches status titles. The glob is matched like a path, so "*" does
+	// not match "/", use "**" to match any title.
+	Pattern  string
+	State    CheckState
+	Statuses []*Status
+}
+
+// Checks is the result of evaluating the status checks of a codebase.
+type Checks struct {
+	Required []*Check
+	// Reason is why the statuses block landing, for example "Waiting for ci/test". It's empty if they don't.
+	Reason string
+}
+
+func (c *Checks) Passed() bool {
+	return c.Reason == ""
+}
+
+// ValidateCheckPattern returns an error if the pattern can't be used as a required status check.
+func ValidateCheckPattern(pattern string) error {
+	switch {
+	case strings.TrimSpace(pattern) == "":
+		return errors.New("the status check must not be empty")
+	case len(pattern) > maxCheckPatternLength:
+		return fmt.Errorf("the status check must be at most %d characters", maxCheckPatternLength)
+	}
+	if _, err := doublestar.Match(pattern, "a"); err != nil {
+		return fmt.Errorf("%q is not a valid glob", pattern)
+	}
+	return nil
+}
+
+// EvaluateChecks evaluates the statuses of the latest snapshot of a workspace against the required status checks of
+// its codebase. Statuses that don't match any of the patterns are optional, and don't block landing.
+//
+// If no patterns are given, every status is required, and at least one must have been reported. This is the behaviour
+// of codebases that require healthy statuses, but that have not listed the checks that are required.
+func EvaluateChecks(patterns []string, statusList []*Status) *Checks {
+	if len(patterns) == 0 {
+		return evaluateAll(statusList)
+	}
+
+	checks := &Checks{Required: make([]*Check, 0, len(patterns))}
+	for _, pattern := range patterns {
+		check := &Check{Pattern: pattern}
+		for _, status := range statusList {
+			if matchCheck(pattern, status.Title) {
+				check.Statuses = append(check.Statuses, status)
+			}
+		}
+		check.State = checkState(check.Statuses)
+		checks.Required = append(checks.Required, check)
+	}
+	checks.Reason = reason(checks.Required)
+	return checks
+}
+
+func evaluateAll(statusList []*Status) *Checks {
+	checks := &Checks{Required: make([]*Check, 0, len(statusList))}
+	for _, status := range statusList {
+		checks.Required = append(checks.Required, &Check{
+			Pattern:  status.Title,
+			State:    checkState([]*Status{status}),
+			Statuses: []*Status{status},
+		})
+	}
+	if len(statusList) == 0 {
+		checks.Reason = "Waiting for statuses to be reported"
+		return checks
+	}
+	checks.Reason = reason(checks.Required)
+	return checks
+}
+
+func matchCheck(pattern, title string) bool {
+	if pattern == title {
+		return true
+	}
+	match, _ := doublestar.Match(pattern, title)
+	return match
+}
+
+func checkState(statusList []*Status) CheckState {
+	if len(statusList) == 0 {
+		return CheckStateMissing
+	}
+	state := CheckStatePassed
+	for _, status := range statusList {
+		switch status.Type {
+		case TypeFailing:
+			return CheckStateFailing
+		case TypeHealthy:
+		default:
+			state = CheckStatePending
+		}
+	}
+	return state
+}
+
+// reason describes the checks that have not passed, failing checks are listed before the ones that are waited for.
+func reason(checks []*Check) string {
+	var failing, waiting []string
+	for _, check := range checks {
+		switch check.State {
+		case CheckStateFailing:
+			for _, status := range check.Statuses {
+				if status.Type == TypeFailing {
+					failing = append(failing, status.Title)
+				}
+			}
+		case CheckStatePending:
+			for _, status := range check.Statuses {
+				if status.Type != TypeHealthy {
+					waiting = append(waiting, status.Title)
+				}
+			}
+		case CheckStateMissing:
+			waiting = append(waiting, check.Pattern)
+		}
+	}
+
+	failing, waiting = unique(failing), unique(waiting)
+	switch {
+	case len(failing) == 1:
+		return fmt.Sprintf("%s is failing", failing[0])
+	case len(failing) > 1:
+		return fmt.Sprintf("%s are failing", strings.Join(failing, ", "))
+	case len(waiting) > 0:
+		return fmt.Sprintf("Waiting for %s", strings.Join(waiting, ", "))
+	}
+	return ""
+}
+
+func unique(titles []string) []string {
+	unique := make([]string, 0, len(titles))
+	seen := make(map[string]bool, len(titles))
+	for _, title := range titles {
+		if !seen[title] {
+			seen[title] = true
+			unique = append(unique, title)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
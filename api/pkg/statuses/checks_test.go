@@ -0,0 +1,99 @@
+package statuses
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func status(title string, t Type) *Status {
+	return &Status{ID: title, Title: title, Type: t}
+}
+
+func TestEvaluateChecks(t *testing.T) {
+	lint := status("ci/lint", TypeHealthy)
+	unit := status("ci/test/unit", TypePending)
+	e2e := status("ci/test/e2e", TypeFailing)
+	flaky := status("optional/flaky", TypeFailing)
+
+	cases := []struct {
+		name     string
+		patterns []string
+		statuses []*Status
+		states   []CheckState
+		reason   string
+	}{
+		{
+			name:     "passed",
+			patterns: []string{"ci/lint"},
+			statuses: []*Status{lint, flaky},
+			states:   []CheckState{CheckStatePassed},
+		},
+		{
+			name:     "missing",
+			patterns: []string{"ci/lint", "ci/build"},
+			statuses: []*Status{lint},
+			states:   []CheckState{CheckStatePassed, CheckStateMissing},
+			reason:   "Waiting for ci/build",
+		},
+		{
+			name:     "pending glob",
+			patterns: []string{"ci/test/*"},
+			statuses: []*Status{lint, unit},
+			states:   []CheckState{CheckStatePending},
+			reason:   "Waiting for ci/test/unit",
+		},
+		{
+			name:     "failing before waiting",
+			patterns: []string{"ci/test/*", "ci/build"},
+			statuses: []*Status{unit, e2e},
+			states:   []CheckState{CheckStateFailing, CheckStateMissing},
+			reason:   "ci/test/e2e is failing",
+		},
+		{
+			name:     "double star",
+			patterns: []string{"ci/**"},
+			statuses: []*Status{lint, e2e, flaky},
+			states:   []CheckState{CheckStateFailing},
+			reason:   "ci/test/e2e is failing",
+		},
+		{
+			name:     "same status in several checks",
+			patterns: []string{"ci/**", "ci/test/*"},
+			statuses: []*Status{lint, unit},
+			states:   []CheckState{CheckStatePending, CheckStatePending},
+			reason:   "Waiting for ci/test/unit",
+		},
+		{
+			name:     "all statuses",
+			statuses: []*Status{lint, e2e, flaky},
+			states:   []CheckState{CheckStatePassed, CheckStateFailing, CheckStateFailing},
+			reason:   "ci/test/e2e, optional/flaky are failing",
+		},
+		{
+			name:   "all statuses without statuses",
+			states: []CheckState{},
+			reason: "Waiting for statuses to be reported",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checks := EvaluateChecks(tc.patterns, tc.statuses)
+			states := make([]CheckState, 0, len(checks.Required))
+			for _, check := range checks.Required {
+				states = append(states, check.State)
+			}
+			assert.Equal(t, tc.states, states)
+			assert.Equal(t, tc.reason, checks.Reason)
+			assert.Equal(t, tc.reason == "", checks.Passed())
+		})
+	}
+}
+
+func TestValidateCheckPattern(t *testing.T) {
+	assert.NoError(t, ValidateCheckPattern("Sturdy CI: *"))
+	assert.NoError(t, ValidateCheckPattern("buildkite/**"))
+	assert.Error(t, ValidateCheckPattern(" "))
+	assert.Error(t, ValidateCheckPattern("ci/[test"))
+}
@@ -0,0 +1,181 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	service_ci "getsturdy.com/api/pkg/ci/service"
+	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+	"getsturdy.com/api/pkg/statuses"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxContextLength     = 255
+	maxDescriptionLength = 1024
+)
+
+// stateToType maps the states that can be reported to status types. Besides the status types themselves, the
+// states that are used by other CI systems are accepted, to make reporting from existing scripts easier.
+var stateToType = map[string]statuses.Type{
+	"pending":   statuses.TypePending,
+	"running":   statuses.TypePending,
+	"scheduled": statuses.TypePending,
+
+	"healthy": statuses.TypeHealthy,
+	"success": statuses.TypeHealthy,
+	"passed":  statuses.TypeHealthy,
+
+	"failing":  statuses.TypeFailing,
+	"failure":  statuses.TypeFailing,
+	"failed":   statuses.TypeFailing,
+	"error":    statuses.TypeFailing,
+	"canceled": statuses.TypeFailing,
+}
+
+type SetStatusRequest struct {
+	// CommitSHA is the commit in the CI repository that the status is for.
+	CommitSHA string `json:"commit_sha" binding:"required"`
+	// Context is the name of the status, a new status with the same context replaces the previous one.
+	Context     string `json:"context" binding:"required"`
+	State       string `json:"state" binding:"required"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// SetStatus creates or updates the status of a commit, it can be used by any CI system to report back to Sturdy.
+//
+//	POST /v3/statuses
+//	Authorization: Basic base64(<service token id>:<service token>)
+//
+//	{
+//		"commit_sha": "<the commit that was built>",
+//		"context": "tests",
+//		"state": "pending" | "healthy" | "failing",
+//		"url": "https://ci.example.com/builds/1",
+//		"description": "Running tests"
+//	}
+//
+// The request is authenticated with the service token of the codebase, the same credentials that the CI system uses
+// to fetch the code. The commit is the commit that Sturdy pushed to the CI system, and the status is shown on the
+// workspace or change that it was created from. The states success, failure and error are accepted as well.
+//
+// Statuses are never modified, reporting a status with the same context as an earlier one for the same commit
+// replaces it. The created status is returned.
+func SetStatus(
+	logger *zap.Logger,
+	statusesService *service_statuses.Service,
+	ciService *service_ci.Service,
+	serviceTokensService *service_servicetokens.Service,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		tokenID, tokenSecret, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", "Basic realm=Authorization Required")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		serviceToken, err := serviceTokensService.Get(c.Request.Context(), tokenID)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			logger.Error("failed to get service token", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := serviceToken.Verify(tokenSecret); err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		var req SetStatusRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to parse or validate input"})
+			return
+		}
+
+		status, err := req.status()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		logger := logger.With(
+			zap.Stringer("codebase_id", serviceToken.CodebaseID),
+			zap.String("ci_commit_sha", req.CommitSHA),
+		)
+
+		trunkCommitSHA, err := ciService.GetTrunkCommitSHA(c.Request.Context(), serviceToken.CodebaseID, req.CommitSHA)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "unknown commit"})
+			return
+		} else if err != nil {
+			logger.Error("could not find trunk commit", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		status.CommitSHA = trunkCommitSHA
+		status.CodebaseID = serviceToken.CodebaseID
+
+		if err := statusesService.Set(c.Request.Context(), status); err != nil {
+			logger.Error("failed to set status", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+// status validates the request, and returns the status without a commit or a codebase.
+func (r *SetStatusRequest) status() (*statuses.Status, error) {
+	title := strings.TrimSpace(r.Context)
+	if title == "" {
+		return nil, errors.New("context is required")
+	}
+	if len(title) > maxContextLength {
+		return nil, errors.New("context is too long")
+	}
+
+	statusType, ok := stateToType[strings.ToLower(strings.TrimSpace(r.State))]
+	if !ok {
+		return nil, errors.New("invalid state")
+	}
+
+	status := &statuses.Status{
+		ID:        uuid.NewString(),
+		Type:      statusType,
+		Title:     title,
+		Timestamp: time.Now(),
+	}
+
+	if detailsURL := strings.TrimSpace(r.URL); detailsURL != "" {
+		// the url is rendered as a link, only allow web urls
+		u, err := url.Parse(detailsURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("invalid url")
+		}
+		status.DetailsURL = &detailsURL
+	}
+
+	if description := strings.TrimSpace(r.Description); description != "" {
+		if len(description) > maxDescriptionLength {
+			return nil, errors.New("description is too long")
+		}
+		status.Description = &description
+	}
+
+	return status, nil
+}
@@ -0,0 +1,240 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	module_api "getsturdy.com/api/pkg/api/module"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/changes"
+	db_changes "getsturdy.com/api/pkg/changes/db"
+	"getsturdy.com/api/pkg/ci"
+	db_ci "getsturdy.com/api/pkg/ci/db"
+	service_ci "getsturdy.com/api/pkg/ci/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/internal/dbtest"
+	queue "getsturdy.com/api/pkg/queue/module"
+	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+	"getsturdy.com/api/pkg/statuses"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
+	"getsturdy.com/api/pkg/users"
+	db_user "getsturdy.com/api/pkg/users/db"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+)
+
+func TestSetStatusRequest_status(t *testing.T) {
+	detailsURL := "https://ci.example.com/builds/1"
+	description := "Running tests"
+
+	status, err := (&SetStatusRequest{
+		CommitSHA:   "abc",
+		Context:     " tests ",
+		State:       "Success",
+		URL:         detailsURL,
+		Description: description,
+	}).status()
+	require.NoError(t, err)
+	assert.NotEmpty(t, status.ID)
+	assert.Equal(t, statuses.TypeHealthy, status.Type)
+	assert.Equal(t, "tests", status.Title)
+	assert.Equal(t, &detailsURL, status.DetailsURL)
+	assert.Equal(t, &description, status.Description)
+
+	status, err = (&SetStatusRequest{CommitSHA: "abc", Context: "tests", State: "pending"}).status()
+	require.NoError(t, err)
+	assert.Equal(t, statuses.TypePending, status.Type)
+	assert.Nil(t, status.DetailsURL)
+	assert.Nil(t, status.Description)
+}
+
+func TestSetStatusRequest_status_invalid(t *testing.T) {
+	cases := map[string]*SetStatusRequest{
+		"state":      {Context: "tests", State: "unknown"},
+		"context":    {Context: " ", State: "pending"},
+		"javascript": {Context: "tests", State: "pending", URL: "javascript:alert(1)"},
+		"relative":   {Context: "tests", State: "pending", URL: "/builds/1"},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := req.status()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func module(t *testing.T) di.Module {
+	return func(c *di.Container) {
+		// TODO: reduce scope
+		c.Import(module_api.Module)
+		c.ImportWithForce(configuration.TestModule)
+		c.ImportWithForce(queue.TestModule(t))
+		c.Register(func() *testing.T { return t })
+		c.RegisterWithForce(dbtest.DB)
+	}
+}
+
+type deps struct {
+	dig.In
+	UserRepo             db_user.Repository
+	ChangeRepo           db_changes.Repository
+	CiCommitRepo         db_ci.CommitRepository
+	CodebaseService      *service_codebase.Service
+	CiService            *service_ci.Service
+	StatusesService      *service_statuses.Service
+	ServiceTokensService *service_servicetokens.Service
+}
+
+type testCase struct {
+	deps
+
+	ctx    context.Context
+	user   *users.User
+	router *gin.Engine
+}
+
+func setup(t *testing.T) *testCase {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	tc := &testCase{}
+	require.NoError(t, di.Init(module(t)).To(&tc.deps))
+
+	tc.user = &users.User{ID: users.ID(uuid.NewString()), Name: "Test", Email: uuid.NewString() + "@getsturdy.com"}
+	require.NoError(t, tc.UserRepo.Create(tc.user))
+	tc.ctx = auth.NewContext(context.Background(), &auth.Subject{Type: auth.SubjectUser, ID: tc.user.ID.String()})
+
+	tc.router = gin.New()
+	tc.router.POST("/v3/statuses", SetStatus(zap.NewNop(), tc.StatusesService, tc.CiService, tc.ServiceTokensService))
+	return tc
+}
+
+// codebase creates a codebase, and a service token for it
+func (tc *testCase) codebase(t *testing.T) (codebases.ID, string, string) {
+	cb, err := tc.CodebaseService.Create(tc.ctx, tc.user.ID, "test", nil)
+	require.NoError(t, err)
+	secret, token, err := tc.ServiceTokensService.Create(tc.ctx, cb.ID, "ci")
+	require.NoError(t, err)
+	return cb.ID, token.ID, secret
+}
+
+// ciCommit creates a commit in the ci repository of the codebase, that was created from the trunk commit
+func (tc *testCase) ciCommit(t *testing.T, codebaseID codebases.ID, trunkCommitSHA string) string {
+	ciCommitSHA := uuid.NewString()
+	require.NoError(t, tc.CiCommitRepo.Create(tc.ctx, &ci.Commit{
+		ID:              uuid.NewString(),
+		CodebaseID:      codebaseID,
+		CiRepoCommitSHA: ciCommitSHA,
+		TrunkCommitSHA:  trunkCommitSHA,
+		CreatedAt:       time.Now(),
+	}))
+	return ciCommitSHA
+}
+
+func (tc *testCase) setStatus(t *testing.T, tokenID, secret string, req SetStatusRequest) *httptest.ResponseRecorder {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	r, err := http.NewRequest(http.MethodPost, "/v3/statuses", bytes.NewReader(body))
+	require.NoError(t, err)
+	if tokenID != "" {
+		r.SetBasicAuth(tokenID, secret)
+	}
+	w := httptest.NewRecorder()
+	tc.router.ServeHTTP(w, r)
+	return w
+}
+
+func TestSetStatus(t *testing.T) {
+	tc := setup(t)
+
+	codebaseID, tokenID, secret := tc.codebase(t)
+	trunkCommitSHA := uuid.NewString()
+	ciCommitSHA := tc.ciCommit(t, codebaseID, trunkCommitSHA)
+
+	changeID := changes.ID(uuid.NewString())
+	require.NoError(t, tc.ChangeRepo.Insert(tc.ctx, changes.Change{ID: changeID, CodebaseID: codebaseID, CommitID: &trunkCommitSHA}))
+
+	w := tc.setStatus(t, tokenID, secret, SetStatusRequest{CommitSHA: ciCommitSHA, Context: "tests", State: "success"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var status statuses.Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, trunkCommitSHA, status.CommitSHA, "the status is for the trunk commit that the ci commit was created from")
+	assert.Equal(t, codebaseID, status.CodebaseID)
+
+	// the status is shown on the change of the trunk commit
+	change, err := tc.ChangeRepo.Get(tc.ctx, changeID)
+	require.NoError(t, err)
+	ss, err := tc.StatusesService.List(tc.ctx, change.CodebaseID, *change.CommitID)
+	require.NoError(t, err)
+	if assert.Len(t, ss, 1) {
+		assert.Equal(t, status.ID, ss[0].ID)
+		assert.Equal(t, statuses.TypeHealthy, ss[0].Type)
+		assert.Equal(t, "tests", ss[0].Title)
+	}
+}
+
+func TestSetStatus_unauthenticated(t *testing.T) {
+	tc := setup(t)
+
+	codebaseID, tokenID, _ := tc.codebase(t)
+	req := SetStatusRequest{CommitSHA: tc.ciCommit(t, codebaseID, uuid.NewString()), Context: "tests", State: "success"}
+
+	cases := map[string]struct {
+		tokenID, secret string
+	}{
+		"no credentials": {},
+		"unknown token":  {tokenID: uuid.NewString(), secret: uuid.NewString()},
+		"wrong secret":   {tokenID: tokenID, secret: uuid.NewString()},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := tc.setStatus(t, c.tokenID, c.secret, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestSetStatus_unknownCommit(t *testing.T) {
+	tc := setup(t)
+
+	codebaseID, tokenID, secret := tc.codebase(t)
+	tc.ciCommit(t, codebaseID, uuid.NewString())
+
+	w := tc.setStatus(t, tokenID, secret, SetStatusRequest{CommitSHA: uuid.NewString(), Context: "tests", State: "success"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetStatus_otherCodebase(t *testing.T) {
+	tc := setup(t)
+
+	codebaseID, _, _ := tc.codebase(t)
+	trunkCommitSHA := uuid.NewString()
+	ciCommitSHA := tc.ciCommit(t, codebaseID, trunkCommitSHA)
+
+	// the token of another codebase can't set statuses on the commits of this one
+	otherCodebaseID, tokenID, secret := tc.codebase(t)
+	w := tc.setStatus(t, tokenID, secret, SetStatusRequest{CommitSHA: ciCommitSHA, Context: "tests", State: "success"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	for _, id := range []codebases.ID{codebaseID, otherCodebaseID} {
+		ss, err := tc.StatusesService.List(tc.ctx, id, trunkCommitSHA)
+		require.NoError(t, err)
+		assert.Empty(t, ss)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	routes_buildkite "getsturdy.com/api/pkg/buildkite/enterprise/routes"
 	service_buildkite_enterprise "getsturdy.com/api/pkg/buildkite/enterprise/service"
 	service_ci "getsturdy.com/api/pkg/ci/service"
+	routes_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/routes"
+	service_gitlab_enterprise "getsturdy.com/api/pkg/gitlab/enterprise/service"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
 	service_statuses "getsturdy.com/api/pkg/statuses/service"
 
@@ -17,14 +19,20 @@ func WebhookHandler(
 	ciService *service_ci.Service,
 	serviceTokensService *service_servicetokens.Service,
 	enterpriseBuildkiteService *service_buildkite_enterprise.Service,
+	enterpriseGitLabService *service_gitlab_enterprise.Service,
 ) func(c *gin.Context) {
 	isBuildkite := func(c *gin.Context) bool {
 		return c.GetHeader("X-Buildkite-Event") != ""
 	}
+	isGitLab := func(c *gin.Context) bool {
+		return c.GetHeader("X-Gitlab-Event") != ""
+	}
 	return func(c *gin.Context) {
 		switch {
 		case isBuildkite(c):
 			routes_buildkite.WebhookHandler(logger, statusesService, ciService, serviceTokensService, enterpriseBuildkiteService)(c)
+		case isGitLab(c):
+			routes_gitlab.WebhookHandler(logger, statusesService, ciService, enterpriseGitLabService)(c)
 		default:
 			c.AbortWithStatus(404)
 			return
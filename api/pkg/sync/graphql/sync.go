@@ -2,78 +2,79 @@ package graphql
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strconv"
+	stdsync "sync"
 
 	gqlerror "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	"getsturdy.com/api/pkg/sync"
 	"getsturdy.com/api/pkg/sync/service"
 	service_workspace "getsturdy.com/api/pkg/workspaces/service"
-	"getsturdy.com/api/vcs"
-	"getsturdy.com/api/vcs/executor"
+
 	"github.com/graph-gophers/graphql-go"
 	"go.uber.org/zap"
 )
 
 type rootResolver struct {
 	fileDiffRootResolver resolvers.FileDiffRootResolver
-	executorProvider     executor.Provider
 	logger               *zap.Logger
 	workspaceService     *service_workspace.Service
+	syncService          *service.Service
 }
 
 func NewRootResolver(
 	fileDiffRootResolver resolvers.FileDiffRootResolver,
-	executorProvider executor.Provider,
 	logger *zap.Logger,
 	workspaceService *service_workspace.Service,
+	syncService *service.Service,
 ) resolvers.RebaseStatusRootResolver {
 	return &rootResolver{
 		fileDiffRootResolver: fileDiffRootResolver,
-		executorProvider:     executorProvider,
 		logger:               logger,
 		workspaceService:     workspaceService,
+		syncService:          syncService,
 	}
 }
 
+// InternalWorkspaceRebaseStatus returns the rebase status of the workspace. Getting the status might require trying
+// out a sync of the workspace, so it's only done once a field that needs it is resolved.
 func (r *rootResolver) InternalWorkspaceRebaseStatus(ctx context.Context, workspaceID string) (resolvers.RebaseStatusResolver, error) {
-	workspace, _ := r.workspaceService.GetByID(ctx, workspaceID)
-	if workspace.ViewID == nil {
-		return nil, nil
-	}
-	var status *sync.RebaseStatusResponse
-	if err := r.executorProvider.New().
-		AllowRebasingState(). // allowed to be able to get the status if rebasing is in progress
-		Write(func(repo vcs.RepoWriter) error {
-			rebasing, err := repo.OpenRebase()
-			if err != nil {
-				if errors.Is(err, vcs.ErrNoRebaseInProgress) {
-					status = &sync.RebaseStatusResponse{}
-					return nil
-				}
-				return fmt.Errorf("failed to open rebase: %w", err)
-			}
-			rebaseStatus, err := service.Status(r.logger, rebasing)
-			if err != nil {
-				return fmt.Errorf("failed to get status: %w", err)
-			}
-			status = rebaseStatus
-			return nil
-		}).ExecView(workspace.CodebaseID, *workspace.ViewID, "rebaseStatus"); err != nil {
-		return nil, gqlerror.Error(fmt.Errorf("failed to get status: %w", err))
+	workspace, err := r.workspaceService.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
 	}
 	return &resolver{
-		id:                   workspaceID,
-		status:               status,
+		id: workspaceID,
+		loadStatus: func(ctx context.Context) (*sync.RebaseStatusResponse, error) {
+			return r.syncService.WorkspaceStatus(ctx, workspace)
+		},
 		fileDiffRootResolver: &r.fileDiffRootResolver,
 	}, nil
 }
 
 type resolver struct {
 	id                   string
-	status               *sync.RebaseStatusResponse
 	fileDiffRootResolver *resolvers.FileDiffRootResolver
+
+	// status is loaded with loadStatus, if it's not set
+	status     *sync.RebaseStatusResponse
+	loadStatus func(context.Context) (*sync.RebaseStatusResponse, error)
+	loadOnce   stdsync.Once
+	loadErr    error
+}
+
+func (r *resolver) getStatus(ctx context.Context) (*sync.RebaseStatusResponse, error) {
+	r.loadOnce.Do(func() {
+		if r.status != nil {
+			return
+		}
+		r.status, r.loadErr = r.loadStatus(ctx)
+	})
+	if r.loadErr != nil {
+		return nil, gqlerror.Error(fmt.Errorf("failed to get status: %w", r.loadErr))
+	}
+	return r.status, nil
 }
 
 type ConflictingFileResolver struct {
@@ -86,13 +87,21 @@ func (r *resolver) ID() graphql.ID {
 	return graphql.ID(r.id)
 }
 
-func (r *resolver) IsRebasing() bool {
-	return r.status.IsRebasing
+func (r *resolver) IsRebasing(ctx context.Context) (bool, error) {
+	status, err := r.getStatus(ctx)
+	if err != nil {
+		return false, err
+	}
+	return status.IsRebasing, nil
 }
 
-func (r *resolver) ConflictingFiles() ([]resolvers.ConflictingFileResolver, error) {
+func (r *resolver) ConflictingFiles(ctx context.Context) ([]resolvers.ConflictingFileResolver, error) {
+	status, err := r.getStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var elements []resolvers.ConflictingFileResolver
-	for _, element := range r.status.ConflictingFiles {
+	for _, element := range status.ConflictingFiles {
 		elements = append(elements, &ConflictingFileResolver{
 			id:                   r.id,
 			conflictingFile:      element,
@@ -117,3 +126,41 @@ func (c *ConflictingFileResolver) WorkspaceDiff() (resolvers.FileDiffResolver, e
 func (c *ConflictingFileResolver) TrunkDiff() (resolvers.FileDiffResolver, error) {
 	return (*c.fileDiffRootResolver).InternalFileDiff(string(c.ID())+"Trunk", &c.conflictingFile.TrunkDiff), nil
 }
+
+func (c *ConflictingFileResolver) Hunks() []resolvers.ConflictHunkResolver {
+	hunks := make([]resolvers.ConflictHunkResolver, 0, len(c.conflictingFile.Hunks))
+	for i, hunk := range c.conflictingFile.Hunks {
+		hunks = append(hunks, &conflictHunkResolver{
+			id:    string(c.ID()) + "Hunk" + strconv.Itoa(i),
+			hunk:  hunk,
+			index: int32(i),
+		})
+	}
+	return hunks
+}
+
+type conflictHunkResolver struct {
+	id    string
+	hunk  sync.ConflictHunk
+	index int32
+}
+
+func (h *conflictHunkResolver) ID() graphql.ID {
+	return graphql.ID(h.id)
+}
+
+func (h *conflictHunkResolver) Index() int32 {
+	return h.index
+}
+
+func (h *conflictHunkResolver) Base() string {
+	return h.hunk.Base
+}
+
+func (h *conflictHunkResolver) Workspace() string {
+	return h.hunk.Workspace
+}
+
+func (h *conflictHunkResolver) Trunk() string {
+	return h.hunk.Trunk
+}
@@ -1,10 +1,12 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 
 	service_sync "getsturdy.com/api/pkg/sync/service"
 	vcsvcs "getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/merge"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -21,6 +23,10 @@ type ResolveRequest struct {
 type ResolveFileRequest struct {
 	FilePath string `json:"file_path" binding:"required"`
 	Version  string `json:"version" binding:"required"`
+	// Hunks is used together with the "hunks" version, one of "workspace", "trunk" or "both" per conflicting hunk
+	Hunks []merge.Choice `json:"hunks"`
+	// Contents is used together with the "custom" version, to upload the resolved file
+	Contents *string `json:"contents"`
 }
 
 func ResolveV2(
@@ -39,10 +45,18 @@ func ResolveV2(
 
 		var resolves []vcsvcs.SturdyRebaseResolve
 		for _, rf := range req.Files {
-			resolves = append(resolves, vcsvcs.SturdyRebaseResolve{Path: rf.FilePath, Version: rf.Version})
+			resolve := vcsvcs.SturdyRebaseResolve{Path: rf.FilePath, Version: rf.Version, Hunks: rf.Hunks}
+			if rf.Contents != nil {
+				contents := []byte(*rf.Contents)
+				resolve.Contents = &contents
+			}
+			resolves = append(resolves, resolve)
 		}
 
-		if status, err := syncService.Resolve(c.Request.Context(), viewID, resolves); err != nil {
+		if status, err := syncService.Resolve(c.Request.Context(), viewID, resolves); errors.Is(err, merge.ErrUnresolvedHunks) || errors.Is(err, merge.ErrUnknownChoice) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
 			logger.Error("failed to sync on trunk", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
@@ -42,6 +42,7 @@ import (
 	service_workspace "getsturdy.com/api/pkg/workspaces/service"
 	vcsvcs "getsturdy.com/api/vcs"
 	"getsturdy.com/api/vcs/executor"
+	"getsturdy.com/api/vcs/merge"
 	"getsturdy.com/api/vcs/provider"
 
 	"github.com/gin-gonic/gin"
@@ -174,6 +175,27 @@ func TestResolveHighLevelV2(t *testing.T) {
 			resolves:                     []routes_v3_sync.ResolveFileRequest{{FilePath: "foo.txt", Version: "custom"}},
 			expectedContentsAfterResolve: []nameContents{{path: "foo.txt", contents: str("foo-custom")}},
 		},
+		{
+			name:                         "pick-custom-uploaded-contents",
+			trunkFiles:                   []nameContents{{path: "foo.txt", contents: str("foo-trunk")}},
+			workspaceFiles:               []nameContents{{path: "foo.txt", contents: str("foo-workspace")}},
+			expectedConflicts:            true,
+			resolves:                     []routes_v3_sync.ResolveFileRequest{{FilePath: "foo.txt", Version: "custom", Contents: str("foo-uploaded")}},
+			expectedContentsAfterResolve: []nameContents{{path: "foo.txt", contents: str("foo-uploaded")}},
+		},
+		{
+			name:               "pick-hunks",
+			commonHistoryFiles: []nameContents{{path: "foo.txt", contents: str("a\nb\nc\nd\ne\n")}},
+			trunkFiles:         []nameContents{{path: "foo.txt", contents: str("a\ntrunk\nc\nd\ntrunk\n")}},
+			workspaceFiles:     []nameContents{{path: "foo.txt", contents: str("a\nworkspace\nc\nd\nworkspace\n")}},
+			expectedConflicts:  true,
+			resolves: []routes_v3_sync.ResolveFileRequest{{
+				FilePath: "foo.txt",
+				Version:  "hunks",
+				Hunks:    []merge.Choice{merge.ChoiceTrunk, merge.ChoiceBoth},
+			}},
+			expectedContentsAfterResolve: []nameContents{{path: "foo.txt", contents: str("a\ntrunk\nc\nd\nworkspace\ntrunk\n")}},
+		},
 		{
 			name: "pick-mixed-resolutions",
 			trunkFiles: []nameContents{
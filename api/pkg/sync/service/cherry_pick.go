@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/changes"
+	change_vcs "getsturdy.com/api/pkg/changes/vcs"
+	"getsturdy.com/api/pkg/snapshots"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	"getsturdy.com/api/pkg/workspaces"
+	vcsvcs "getsturdy.com/api/vcs"
+
+	git "github.com/libgit2/git2go/v33"
+)
+
+var (
+	ErrChangeNotLanded     = errors.New("change has not been landed")
+	ErrCherryPickConflicts = errors.New("change conflicts with the workspace")
+)
+
+const cherryPickUnsavedCommitMessage = "Unsaved workspace changes before cherry-pick"
+
+// CherryPick applies the diff of a landed change on top of the current changes in the workspace.
+//
+// If the change conflicts with the workspace, nothing is applied, and ErrCherryPickConflicts is returned with the
+// conflicting files.
+func (svc *Service) CherryPick(ctx context.Context, ws *workspaces.Workspace, ch *changes.Change) error {
+	if ch.CommitID == nil {
+		return ErrChangeNotLanded
+	}
+	if ch.CodebaseID != ws.CodebaseID {
+		return fmt.Errorf("change and workspace belong to different codebases")
+	}
+
+	cherryPick := func(repo vcsvcs.RepoWriter) error {
+		if err := repo.FetchBranch("sturdytrunk"); err != nil {
+			return fmt.Errorf("failed to fetch trunk: %w", err)
+		}
+
+		head, err := repo.HeadCommit()
+		if err != nil {
+			return fmt.Errorf("failed to get head commit: %w", err)
+		}
+		headCommitID := head.Id().String()
+		head.Free()
+
+		// Commit the unsaved changes, so that the change can be picked on top of them
+		ontoCommitID := headCommitID
+		treeID, err := change_vcs.CreateChangesTreeFromPatches(ctx, svc.logger, repo, ws.CodebaseID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create tree from patches: %w", err)
+		}
+		if treeID != nil {
+			sig := git.Signature{
+				Name:  "Sturdy",
+				Email: "support@getsturdy.com",
+				When:  time.Now(),
+			}
+			if ontoCommitID, err = repo.CommitIndexTree(treeID, cherryPickUnsavedCommitMessage, sig); err != nil {
+				return fmt.Errorf("failed to commit unsaved changes: %w", err)
+			}
+		}
+
+		newCommitID, conflicted, conflictingFiles, err := repo.CherryPickOnto(*ch.CommitID, ontoCommitID)
+		if err != nil {
+			return fmt.Errorf("failed to cherry-pick: %w", err)
+		}
+
+		if conflicted {
+			// Leave the workspace as it was
+			if err := repo.ResetMixed(headCommitID); err != nil {
+				return fmt.Errorf("failed to reset: %w", err)
+			}
+			return fmt.Errorf("%w: %s", ErrCherryPickConflicts, strings.Join(conflictingFiles, ", "))
+		}
+
+		// Checkout the result, and put the workspace back on it's base commit
+		if err := repo.ResetHard(newCommitID); err != nil {
+			return fmt.Errorf("failed to reset to cherry-picked commit: %w", err)
+		}
+		if err := repo.ResetMixed(headCommitID); err != nil {
+			return fmt.Errorf("failed to reset to workspace base: %w", err)
+		}
+
+		if _, err := svc.snap.Snapshot(
+			ctx,
+			ws.CodebaseID,
+			ws.ID,
+			snapshots.ActionFileCherryPick,
+			service_snapshots.WithOnView(*repo.ViewID()),
+			service_snapshots.WithOnRepo(repo),
+			service_snapshots.WithMarkAsLatestInWorkspace(),
+		); err != nil {
+			return fmt.Errorf("failed to snapshot: %w", err)
+		}
+
+		return nil
+	}
+
+	if ws.ViewID != nil {
+		return svc.executorProvider.New().
+			Write(cherryPick).
+			ExecView(ws.CodebaseID, *ws.ViewID, "cherryPickChange")
+	}
+
+	exec, err := svc.workspaceSnapshotExecutor(ctx, ws)
+	if err != nil {
+		return err
+	}
+
+	return exec.Write(cherryPick).ExecTemporaryView(ws.CodebaseID, "cherryPickChange")
+}
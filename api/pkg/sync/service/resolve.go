@@ -2,18 +2,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/snapshots"
 	"getsturdy.com/api/pkg/sync"
+	vcs_view "getsturdy.com/api/pkg/views/vcs"
+	"getsturdy.com/api/pkg/workspaces"
 	vcsvcs "getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+var ErrUnresolvedConflicts = errors.New("not all conflicts have been resolved")
+
 // Resolve resolves the conflicts in viewID with the resolutions in resolves
 //
 // For each conflicting file in the index, resolves contains the the file path and if the resolution should be
 // * use the version from trunk
 // * use the version from the workspace
-// * use the current version of the file on disk (called "custom")
+// * use the current version of the file on disk, or the uploaded contents (called "custom")
+// * pick the version from the trunk or the workspace for each of the conflicting hunks (called "hunks")
 func (svc *Service) Resolve(ctx context.Context, viewID string, resolves []vcsvcs.SturdyRebaseResolve) (*sync.RebaseStatusResponse, error) {
 	view, err := svc.viewRepo.Get(viewID)
 	if err != nil {
@@ -28,42 +40,231 @@ func (svc *Service) Resolve(ctx context.Context, viewID string, resolves []vcsvc
 			return err
 		}
 
-		if err := rb.ResolveFiles(resolves); err != nil {
+		if err := svc.resolveAndComplete(ctx, repo, rb, view.CodebaseID, view.WorkspaceID, view.ID, resolves); err != nil {
 			return err
 		}
 
-		conflicts, rebasedCommits, err := rb.Continue()
+		rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
+		return nil
+	}
+
+	err = svc.executorProvider.New().
+		AllowRebasingState(). // allowed to get the state of existing conflicts
+		Write(resolveSyncFunc).
+		ExecView(view.CodebaseID, view.ID, "syncResolve2")
+	if err != nil {
+		return nil, err
+	}
+
+	if rebaseStatusResponse == nil {
+		return nil, fmt.Errorf("no rebase status found")
+	}
+
+	return rebaseStatusResponse, nil
+}
+
+// ResolveWorkspace syncs the workspace with the trunk, and resolves the conflicts with the resolutions in resolves.
+//
+// If the workspace is open in a view, this is the same as Resolve. Otherwise, the changes are restored from the latest
+// snapshot to a temporary view, and rebased on top of the trunk. All conflicts must be resolved, if some are left,
+// ErrUnresolvedConflicts is returned and the workspace is left untouched.
+func (svc *Service) ResolveWorkspace(ctx context.Context, ws *workspaces.Workspace, resolves []vcsvcs.SturdyRebaseResolve) (*sync.RebaseStatusResponse, error) {
+	if ws.ViewID != nil {
+		return svc.Resolve(ctx, *ws.ViewID, resolves)
+	}
+
+	branchName := fmt.Sprintf("sync-%s", uuid.NewString())
+
+	exec, err := svc.workspaceSnapshotExecutor(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exec.Write(func(repo vcsvcs.RepoWriter) error {
+		unsavedCommitID, rb, rebasedCommits, err := svc.startRebase(ctx, repo, ws, branchName)
 		if err != nil {
 			return err
 		}
-		if conflicts {
-			return fmt.Errorf("unexpected conflict after conflict resolution")
+
+		// no changes
+		if unsavedCommitID == nil {
+			if err := repo.CheckoutBranchWithForce(branchName); err != nil {
+				return fmt.Errorf("failed to checkout branch: %w", err)
+			}
+			return svc.complete(ctx, repo, ws.CodebaseID, ws.ID, *repo.ViewID(), nil, nil)
+		}
+
+		// there is no one to continue the resolution in a temporary view
+		defer func() {
+			if repo.IsRebasing() {
+				if err := rb.Abort(); err != nil {
+					svc.logger.Error("failed to abort rebase", zap.Error(err))
+				}
+			}
+		}()
+
+		rebaseStatus, err := rb.Status()
+		if err != nil {
+			return err
 		}
-		if len(rebasedCommits) != 1 {
-			return fmt.Errorf("unexpected number of rebased commits")
+
+		// the conflicts might have been resolved on the trunk in the meantime
+		if rebaseStatus != vcsvcs.RebaseHaveConflicts {
+			if err := repo.MoveBranchToHEAD(branchName); err != nil {
+				return fmt.Errorf("branch to head failed: %w", err)
+			}
+			return svc.complete(ctx, repo, ws.CodebaseID, ws.ID, *repo.ViewID(), unsavedCommitID, rebasedCommits)
 		}
 
-		// No conflicts
+		return svc.resolveAndComplete(ctx, repo, rb, ws.CodebaseID, ws.ID, *repo.ViewID(), resolves)
+	}).ExecTemporaryView(ws.CodebaseID, "syncResolveWorkspace"); err != nil {
+		return nil, err
+	}
+
+	return &sync.RebaseStatusResponse{HaveConflicts: false}, nil
+}
+
+type workspaceStatusKey struct {
+	workspaceID   string
+	snapshotID    snapshots.ID
+	trunkCommitID string
+}
+
+// WorkspaceStatus returns the conflicts that the workspace would have with the trunk, if it was synced.
+//
+// If the workspace is open in a view, the status of the ongoing sync is returned. Otherwise, the sync is tried out in a
+// temporary view, without changing the workspace. Trying out the sync is expensive, so the result is cached until
+// the workspace or the trunk changes.
+func (svc *Service) WorkspaceStatus(ctx context.Context, ws *workspaces.Workspace) (*sync.RebaseStatusResponse, error) {
+	var status *sync.RebaseStatusResponse
+
+	if ws.ViewID != nil {
+		if err := svc.executorProvider.New().
+			AllowRebasingState(). // allowed to be able to get the status if rebasing is in progress
+			Write(func(repo vcsvcs.RepoWriter) error {
+				rebasing, err := repo.OpenRebase()
+				if err != nil {
+					if errors.Is(err, vcsvcs.ErrNoRebaseInProgress) {
+						status = &sync.RebaseStatusResponse{}
+						return nil
+					}
+					return fmt.Errorf("failed to open rebase: %w", err)
+				}
+				status, err = Status(svc.logger, rebasing)
+				if err != nil {
+					return fmt.Errorf("failed to get status: %w", err)
+				}
+				return nil
+			}).ExecView(ws.CodebaseID, *ws.ViewID, "rebaseStatus"); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
 
-		if err := svc.complete(ctx, repo, view.CodebaseID, view.WorkspaceID, view.ID, &rebasedCommits[0].OldCommitID, rebasedCommits); err != nil {
+	// without changes, there is nothing to conflict with
+	if ws.LatestSnapshotID == nil {
+		return &sync.RebaseStatusResponse{}, nil
+	}
+
+	var trunkCommitID string
+	if err := svc.executorProvider.New().GitRead(func(repo vcsvcs.RepoGitReader) error {
+		var err error
+		trunkCommitID, err = repo.BranchCommitID("sturdytrunk")
+		return err
+	}).ExecTrunk(ws.CodebaseID, "workspaceStatusTrunk"); err != nil {
+		return nil, fmt.Errorf("failed to get trunk commit: %w", err)
+	}
+
+	key := workspaceStatusKey{workspaceID: ws.ID, snapshotID: *ws.LatestSnapshotID, trunkCommitID: trunkCommitID}
+	if cached, ok := svc.workspaceStatuses.Get(key); ok {
+		return cached.(*sync.RebaseStatusResponse), nil
+	}
+
+	branchName := fmt.Sprintf("sync-%s", uuid.NewString())
+
+	exec, err := svc.workspaceSnapshotExecutor(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exec.Write(func(repo vcsvcs.RepoWriter) error {
+		unsavedCommitID, rb, _, err := svc.startRebase(ctx, repo, ws, branchName)
+		if err != nil {
 			return err
 		}
 
-		rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
+		status = &sync.RebaseStatusResponse{}
+
+		if unsavedCommitID == nil {
+			return nil
+		}
+
+		// the temporary view is only used to try out the sync, it's never left rebasing
+		defer func() {
+			if repo.IsRebasing() {
+				if err := rb.Abort(); err != nil {
+					svc.logger.Error("failed to abort rebase", zap.Error(err))
+				}
+			}
+		}()
+
+		rebaseStatus, err := rb.Status()
+		if err != nil {
+			return err
+		}
+		if rebaseStatus != vcsvcs.RebaseHaveConflicts {
+			return nil
+		}
+
+		if status, err = Status(svc.logger, rb); err != nil {
+			return fmt.Errorf("failed to get conflict status: %w", err)
+		}
+
 		return nil
+	}).ExecTemporaryView(ws.CodebaseID, "syncWorkspaceStatus"); err != nil {
+		return nil, err
 	}
 
-	err = svc.executorProvider.New().
-		AllowRebasingState(). // allowed to get the state of existing conflicts
-		Write(resolveSyncFunc).
-		ExecView(view.CodebaseID, view.ID, "syncResolve2")
+	svc.workspaceStatuses.Add(key, status)
+
+	return status, nil
+}
+
+// workspaceSnapshotExecutor returns an executor that checks out the workspace, and restores it's latest snapshot
+func (svc *Service) workspaceSnapshotExecutor(ctx context.Context, ws *workspaces.Workspace) (executor.Executor, error) {
+	exec := svc.executorProvider.New().Write(vcs_view.CheckoutBranch(ws.ID))
+	if ws.LatestSnapshotID == nil {
+		return exec, nil
+	}
+
+	snapshot, err := svc.snap.GetByID(ctx, *ws.LatestSnapshotID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
 	}
 
-	if rebaseStatusResponse == nil {
-		return nil, fmt.Errorf("no rebase status found")
+	return exec.Write(func(repo vcsvcs.RepoWriter) error {
+		return svc.snap.Restore(snapshot, repo)
+	}), nil
+}
+
+// resolveAndComplete resolves the conflicts of an ongoing rebase, and completes the sync
+func (svc *Service) resolveAndComplete(ctx context.Context, repo vcsvcs.RepoWriter, rb *vcsvcs.SturdyRebase, codebaseID codebases.ID, workspaceID, viewID string, resolves []vcsvcs.SturdyRebaseResolve) error {
+	if err := rb.ResolveFiles(resolves); err != nil {
+		return err
 	}
 
-	return rebaseStatusResponse, nil
+	conflicts, rebasedCommits, err := rb.Continue()
+	if err != nil {
+		return err
+	}
+	if conflicts {
+		return ErrUnresolvedConflicts
+	}
+	if len(rebasedCommits) != 1 {
+		return fmt.Errorf("unexpected number of rebased commits")
+	}
+
+	// No conflicts
+
+	return svc.complete(ctx, repo, codebaseID, workspaceID, viewID, &rebasedCommits[0].OldCommitID, rebasedCommits)
 }
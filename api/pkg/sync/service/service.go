@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"getsturdy.com/api/vcs/executor"
 
 	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru"
 	git "github.com/libgit2/git2go/v33"
 	"go.uber.org/zap"
 )
@@ -34,8 +36,13 @@ type Service struct {
 	snap             *service_snapshots.Service
 
 	eventsPublisher *events.Publisher
+
+	// workspaceStatuses caches the results of WorkspaceStatus for workspaces that are not open in a view
+	workspaceStatuses *lru.Cache
 }
 
+const workspaceStatusesCacheSize = 1024
+
 func New(
 	logger *zap.Logger,
 	executorProvider executor.Provider,
@@ -45,14 +52,16 @@ func New(
 	snap *service_snapshots.Service,
 	eventsPublisher *events.Publisher,
 ) *Service {
+	workspaceStatuses, _ := lru.New(workspaceStatusesCacheSize)
 	return &Service{
-		logger:           logger.Named("syncService"),
-		executorProvider: executorProvider,
-		viewRepo:         viewRepo,
-		workspaceReader:  workspaceReader,
-		workspaceWriter:  workspaceWriter,
-		snap:             snap,
-		eventsPublisher:  eventsPublisher,
+		logger:            logger.Named("syncService"),
+		executorProvider:  executorProvider,
+		viewRepo:          viewRepo,
+		workspaceReader:   workspaceReader,
+		workspaceWriter:   workspaceWriter,
+		snap:              snap,
+		eventsPublisher:   eventsPublisher,
+		workspaceStatuses: workspaceStatuses,
 	}
 }
 
@@ -65,91 +74,127 @@ const unsavedCommitMessage = "Unsaved workspace changes"
 // The current work in progress will be added to a commit, that is rebased on top of the trunk.
 // After the syncing is done, the commit is "git reset --mixed HEAD^1"-ed, to restore it to the WIP.
 func (svc *Service) OnTrunk(ctx context.Context, ws *workspaces.Workspace) (*sync.RebaseStatusResponse, error) {
-	syncID := uuid.NewString()
+	var rebaseStatusResponse *sync.RebaseStatusResponse
+	rebaseFunc := svc.rebaseOnTrunk(ctx, ws, &rebaseStatusResponse)
 
-	branchName := fmt.Sprintf("sync-%s", syncID)
+	if ws.ViewID != nil {
+		if err := svc.executorProvider.New().
+			AssertBranchName(ws.ID).
+			AllowRebasingState(). // allowed to get the state of existing conflicts
+			Write(rebaseFunc).
+			ExecView(ws.CodebaseID, *ws.ViewID, "syncOnTrunk"); err != nil {
+			return nil, err
+		}
+		vw, err := svc.viewRepo.Get(*ws.ViewID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get view: %w", err)
+		}
+
+		if err := svc.eventsPublisher.ViewUpdated(ctx, events.Codebase(vw.CodebaseID), vw); err != nil {
+			svc.logger.Error("failed to send workspace updated event", zap.Error(err))
+			// do not fail
+		}
+	} else {
+		if err := svc.executorProvider.New().
+			Write(vcs_view.CheckoutBranch(ws.ID)).
+			Write(rebaseFunc).
+			ExecTemporaryView(ws.CodebaseID, "syncOnTrunk"); err != nil {
+			return nil, err
+		}
+	}
+
+	if rebaseStatusResponse == nil {
+		return nil, fmt.Errorf("no rebase status found")
+	}
+
+	return rebaseStatusResponse, nil
+}
+
+var ErrRestackConflicts = errors.New("workspace conflicts with trunk")
+
+// Restack moves a workspace that is stacked on top of another workspace to the trunk, after the parent has landed.
+// The changes of the parent are already on the trunk, so only the changes of the workspace itself are rebased.
+//
+// If the workspace is open in a view, this is the same as OnTrunk, and conflicts have to be resolved by the user.
+// Otherwise, the changes are restored from the latest snapshot. If they conflict with the trunk, ErrRestackConflicts
+// is returned and the workspace is left untouched.
+func (svc *Service) Restack(ctx context.Context, ws *workspaces.Workspace) error {
+	if ws.ViewID != nil {
+		if _, err := svc.OnTrunk(ctx, ws); err != nil {
+			return err
+		}
+		return nil
+	}
 
 	var rebaseStatusResponse *sync.RebaseStatusResponse
+	rebaseFunc := svc.rebaseOnTrunk(ctx, ws, &rebaseStatusResponse)
+
+	exec, err := svc.workspaceSnapshotExecutor(ctx, ws)
+	if err != nil {
+		return err
+	}
+
+	if err := exec.Write(func(repo vcsvcs.RepoWriter) error {
+		if err := rebaseFunc(repo); err != nil {
+			return err
+		}
+		if rebaseStatusResponse == nil || !rebaseStatusResponse.HaveConflicts {
+			return nil
+		}
+		// there is no one to resolve the conflicts in a temporary view
+		rb, err := repo.OpenRebase()
+		if err != nil {
+			return fmt.Errorf("failed to open rebase: %w", err)
+		}
+		if err := rb.Abort(); err != nil {
+			return err
+		}
+		return ErrRestackConflicts
+	}).ExecTemporaryView(ws.CodebaseID, "syncRestack"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rebaseOnTrunk returns a function that rebases the work in progress changes of the workspace on top of the trunk, the
+// result of the rebase is written to rebaseStatusResponse.
+func (svc *Service) rebaseOnTrunk(ctx context.Context, ws *workspaces.Workspace, rebaseStatusResponse **sync.RebaseStatusResponse) func(vcsvcs.RepoWriter) error {
+	syncID := uuid.NewString()
 
-	rebaseFunc := func(repo vcsvcs.RepoWriter) error {
+	branchName := fmt.Sprintf("sync-%s", syncID)
+
+	return func(repo vcsvcs.RepoWriter) error {
 		// Already rebasing, exit
 		if repo.IsRebasing() {
 			rb, err := repo.OpenRebase()
 			if err != nil {
 				return fmt.Errorf("failed to open previous rebase: %w", err)
 			}
-			rebaseStatusResponse, err = Status(svc.logger, rb)
+			*rebaseStatusResponse, err = Status(svc.logger, rb)
 			if err != nil {
 				return fmt.Errorf("failed to get conflict status: %w", err)
 			}
 			return nil
 		}
 
-		if err := repo.FetchBranch("sturdytrunk"); err != nil {
-			return err
-		}
-
-		trunkHeadCommit, err := repo.RemoteBranchCommit("origin", "sturdytrunk")
+		unsavedCommitID, rb, rebasedCommits, err := svc.startRebase(ctx, repo, ws, branchName)
 		if err != nil {
 			return err
 		}
 
-		if err := repo.CreateNewBranchOnHEAD(branchName + "_withunsaved"); err != nil {
-			return fmt.Errorf("failed to create new branch during Syncer start: %w", err)
-		}
-
-		if err := repo.CheckoutBranchSafely(branchName + "_withunsaved"); err != nil {
-			return fmt.Errorf("failed to safely checkout new branch during Syncer start: %w", err)
-		}
-
-		if err := svc.logFiles(ws.ID, "before", repo); err != nil {
-			return fmt.Errorf("failed to log changed files before sync: %w", err)
-		}
-
-		treeID, err := change_vcs.CreateChangesTreeFromPatches(ctx, svc.logger, repo, ws.CodebaseID, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create tree from patches during sync: %w", err)
-		}
-
 		// no changes, early return
-		if treeID == nil {
-			if err := repo.MoveBranchToCommit(branchName, trunkHeadCommit.Id().String()); err != nil {
-				return fmt.Errorf("failed to move branch to commit in early return: %w", err)
-			}
+		if unsavedCommitID == nil {
 			if err := repo.CheckoutBranchWithForce(branchName); err != nil {
 				return fmt.Errorf("failed to checkout branch in early return: %w", err)
 			}
 			if err := svc.complete(ctx, repo, ws.CodebaseID, ws.ID, *repo.ViewID(), nil, nil); err != nil {
 				return fmt.Errorf("failed to complete in early return: %w", err)
 			}
-			rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
+			*rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
 			return nil
 		}
 
-		sig := git.Signature{
-			Name:  "Sturdy",
-			Email: "support@getsturdy.com",
-			When:  time.Now(),
-		}
-
-		unsavedCommitID, err := repo.CommitIndexTree(treeID, unsavedCommitMessage, sig)
-		if err != nil {
-			return fmt.Errorf("failed to create commit with unsave changes: %w", err)
-		}
-
-		if err := repo.CreateAndCheckoutBranchAtCommit(trunkHeadCommit.Id().String(), branchName); err != nil {
-			return fmt.Errorf("create and checkout branch failed: %w", err)
-		}
-
-		// Apply our unsaved changes
-		rb, rebasedCommits, err := repo.InitRebaseRaw(
-			unsavedCommitID,
-			trunkHeadCommit.Id().String(),
-		)
-		if err != nil {
-			return err
-		}
-
 		rebaseStatus, err := rb.Status()
 		if err != nil {
 			return err
@@ -163,7 +208,7 @@ func (svc *Service) OnTrunk(ctx context.Context, ws *workspaces.Workspace) (*syn
 				svc.logger.Error("failed to restore large files", zap.Error(err))
 			}
 
-			rebaseStatusResponse, err = Status(svc.logger, rb)
+			*rebaseStatusResponse, err = Status(svc.logger, rb)
 			if err != nil {
 				return fmt.Errorf("failed to get conflict status: %w", err)
 			}
@@ -177,45 +222,76 @@ func (svc *Service) OnTrunk(ctx context.Context, ws *workspaces.Workspace) (*syn
 			return fmt.Errorf("branch to head failed: %w", err)
 		}
 
-		if err := svc.complete(ctx, repo, ws.CodebaseID, ws.ID, *repo.ViewID(), &unsavedCommitID, rebasedCommits); err != nil {
+		if err := svc.complete(ctx, repo, ws.CodebaseID, ws.ID, *repo.ViewID(), unsavedCommitID, rebasedCommits); err != nil {
 			return err
 		}
 
-		rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
+		*rebaseStatusResponse = &sync.RebaseStatusResponse{HaveConflicts: false}
 		return nil
 	}
+}
 
-	if ws.ViewID != nil {
-		if err := svc.executorProvider.New().
-			AssertBranchName(ws.ID).
-			AllowRebasingState(). // allowed to get the state of existing conflicts
-			Write(rebaseFunc).
-			ExecView(ws.CodebaseID, *ws.ViewID, "syncOnTrunk"); err != nil {
-			return nil, err
-		}
-		vw, err := svc.viewRepo.Get(*ws.ViewID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get view: %w", err)
-		}
+// startRebase commits the work in progress changes of the workspace, and starts to rebase them on top of the trunk.
+//
+// If the workspace has no changes, unsavedCommitID is nil, no rebase is started, and branchName points to the trunk.
+func (svc *Service) startRebase(ctx context.Context, repo vcsvcs.RepoWriter, ws *workspaces.Workspace, branchName string) (unsavedCommitID *string, rb *vcsvcs.SturdyRebase, rebasedCommits []vcsvcs.RebasedCommit, err error) {
+	if err := repo.FetchBranch("sturdytrunk"); err != nil {
+		return nil, nil, nil, err
+	}
 
-		if err := svc.eventsPublisher.ViewUpdated(ctx, events.Codebase(vw.CodebaseID), vw); err != nil {
-			svc.logger.Error("failed to send workspace updated event", zap.Error(err))
-			// do not fail
-		}
-	} else {
-		if err := svc.executorProvider.New().
-			Write(vcs_view.CheckoutBranch(ws.ID)).
-			Write(rebaseFunc).
-			ExecTemporaryView(ws.CodebaseID, "syncOnTrunk"); err != nil {
-			return nil, err
+	trunkHeadCommit, err := repo.RemoteBranchCommit("origin", "sturdytrunk")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	trunkHeadCommitID := trunkHeadCommit.Id().String()
+
+	if err := repo.CreateNewBranchOnHEAD(branchName + "_withunsaved"); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create new branch during Syncer start: %w", err)
+	}
+
+	if err := repo.CheckoutBranchSafely(branchName + "_withunsaved"); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to safely checkout new branch during Syncer start: %w", err)
+	}
+
+	if err := svc.logFiles(ws.ID, "before", repo); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to log changed files before sync: %w", err)
+	}
+
+	treeID, err := change_vcs.CreateChangesTreeFromPatches(ctx, svc.logger, repo, ws.CodebaseID, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create tree from patches during sync: %w", err)
+	}
+
+	// no changes
+	if treeID == nil {
+		if err := repo.MoveBranchToCommit(branchName, trunkHeadCommitID); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to move branch to commit in early return: %w", err)
 		}
+		return nil, nil, nil, nil
 	}
 
-	if rebaseStatusResponse == nil {
-		return nil, fmt.Errorf("no rebase status found")
+	sig := git.Signature{
+		Name:  "Sturdy",
+		Email: "support@getsturdy.com",
+		When:  time.Now(),
 	}
 
-	return rebaseStatusResponse, nil
+	commitID, err := repo.CommitIndexTree(treeID, unsavedCommitMessage, sig)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create commit with unsave changes: %w", err)
+	}
+
+	if err := repo.CreateAndCheckoutBranchAtCommit(trunkHeadCommitID, branchName); err != nil {
+		return nil, nil, nil, fmt.Errorf("create and checkout branch failed: %w", err)
+	}
+
+	// Apply our unsaved changes
+	rb, rebasedCommits, err = repo.InitRebaseRaw(commitID, trunkHeadCommitID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &commitID, rb, rebasedCommits, nil
 }
 
 // complete is called by OnTrunk (if there where no conflicts) and Resolve (when all conflicts have been resolved)
@@ -274,6 +350,11 @@ func (svc *Service) complete(ctx context.Context, repo vcsvcs.RepoWriter, codeba
 		// Don't fail
 	}
 
+	// The workspace is now based on the trunk, if it was stacked on top of another workspace it's not anymore
+	if err := svc.workspaceWriter.UpdateFields(ctx, workspaceID, db_workspaces.SetParentWorkspaceID(nil)); err != nil {
+		return fmt.Errorf("failed to unset parent workspace: %w", err)
+	}
+
 	// Update workspace
 	if err := ws_meta.Updated(ctx, svc.workspaceReader, svc.workspaceWriter, workspaceID); err != nil {
 		return fmt.Errorf("failed to send event: %w", err)
@@ -320,10 +401,25 @@ func Status(logger *zap.Logger, rebasing *vcsvcs.SturdyRebase) (*sync.RebaseStat
 			return nil, fmt.Errorf("failed to decorate workspace diff: %w", err)
 		}
 
+		hunks, err := rebasing.ConflictHunks(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get conflict hunks for %s: %w", p, err)
+		}
+
+		conflictHunks := make([]sync.ConflictHunk, 0, len(hunks))
+		for _, hunk := range hunks {
+			conflictHunks = append(conflictHunks, sync.ConflictHunk{
+				Base:      hunk.Base,
+				Workspace: hunk.Workspace,
+				Trunk:     hunk.Trunk,
+			})
+		}
+
 		cf = append(cf, sync.ConflictingFile{
 			Path:          p,
 			WorkspaceDiff: workspaceDiff,
 			TrunkDiff:     trunkDiff,
+			Hunks:         conflictHunks,
 		})
 	}
 
@@ -0,0 +1,326 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/di"
+	db_installations "getsturdy.com/api/pkg/installations/db"
+	"getsturdy.com/api/pkg/logger"
+	module_queue "getsturdy.com/api/pkg/queue/module"
+	"getsturdy.com/api/pkg/snapshots"
+	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
+	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
+	db_statuses "getsturdy.com/api/pkg/statuses/db"
+	db_suggestions "getsturdy.com/api/pkg/suggestions/db"
+	service_sync "getsturdy.com/api/pkg/sync/service"
+	"getsturdy.com/api/pkg/users"
+	db_view "getsturdy.com/api/pkg/views/db"
+	service_view "getsturdy.com/api/pkg/views/service"
+	"getsturdy.com/api/pkg/workspaces"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+	"getsturdy.com/api/vcs/testutil"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testModule(t *testing.T) di.Module {
+	return func(c *di.Container) {
+		c.Import(service_sync.Module)
+		c.Import(service_snapshots.Module)
+		c.Import(service_codebase.Module)
+		c.Import(service_workspace.Module)
+		c.Import(service_view.Module)
+
+		c.ImportWithForce(db_snapshots.TestModule)
+		c.ImportWithForce(db_view.TestModule)
+		c.ImportWithForce(db_workspaces.TestModule)
+		c.ImportWithForce(db_suggestions.TestModule)
+		c.ImportWithForce(db_codebases.TestModule)
+		c.ImportWithForce(db_installations.TestModule)
+		c.ImportWithForce(db_statuses.TestModule)
+		c.ImportWithForce(module_queue.TestModule(t))
+		c.ImportWithForce(configuration.TestModule)
+		c.RegisterWithForce(logger.NewTest)
+
+		c.RegisterWithForce(func() *sqlx.DB { return nil }) // make sure db is not used
+		c.Register(func() *testing.T { return t })
+		c.RegisterWithForce(testutil.TestingRepoProvider)
+	}
+}
+
+type testCase struct {
+	syncService      *service_sync.Service
+	snapshotService  *service_snapshots.Service
+	workspaceService *service_workspace.Service
+	codebaseService  *service_codebase.Service
+	viewService      *service_view.Service
+	executorProvider executor.Provider
+
+	userID     users.ID
+	codebaseID codebases.ID
+}
+
+func setup(t *testing.T) *testCase {
+	tc := &testCase{}
+	require.NoError(t, di.Init(testModule(t)).To(
+		&tc.syncService, &tc.snapshotService, &tc.workspaceService, &tc.codebaseService, &tc.viewService, &tc.executorProvider,
+	))
+
+	tc.userID = users.ID(uuid.NewString())
+
+	cb, err := tc.codebaseService.Create(context.Background(), tc.userID, "test", nil)
+	require.NoError(t, err)
+	tc.codebaseID = cb.ID
+
+	return tc
+}
+
+// commitToTrunk commits the file to the trunk, as if a change had been landed, and returns the id of the commit
+func (tc *testCase) commitToTrunk(t *testing.T, filename, contents string) string {
+	var commitID string
+	require.NoError(t, tc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		var err error
+		commitID, err = repo.CreateCommitWithFiles([]vcs.FileContents{{Path: filename, Contents: []byte(contents)}}, "sturdytrunk")
+		return err
+	}).ExecTrunk(tc.codebaseID, "commitToTrunk"))
+	return commitID
+}
+
+// workspaceWithChanges returns a workspace with the file changed in its latest snapshot. The workspace is not open in
+// a view.
+func (tc *testCase) workspaceWithChanges(t *testing.T, filename, contents string) *workspaces.Workspace {
+	return tc.stackedWorkspaceWithChanges(t, nil, filename, contents)
+}
+
+// stackedWorkspaceWithChanges is like workspaceWithChanges, the workspace is stacked on top of parent if it's not nil.
+func (tc *testCase) stackedWorkspaceWithChanges(t *testing.T, parent *workspaces.Workspace, filename, contents string) *workspaces.Workspace {
+	ctx := context.Background()
+
+	req := service_workspace.CreateWorkspaceRequest{UserID: tc.userID, CodebaseID: tc.codebaseID}
+	if parent != nil {
+		req.ParentWorkspaceID = &parent.ID
+	}
+	ws, err := tc.workspaceService.Create(ctx, req)
+	require.NoError(t, err)
+
+	vw, err := tc.viewService.Create(ctx, tc.userID, ws, nil, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, tc.executorProvider.New().Write(writeFile(filename, contents)).ExecView(tc.codebaseID, vw.ID, "make some changes"))
+
+	_, err = tc.snapshotService.Snapshot(ctx, tc.codebaseID, ws.ID, snapshots.Action("testing"),
+		service_snapshots.WithOnView(vw.ID),
+		service_snapshots.WithMarkAsLatestInWorkspace(),
+	)
+	require.NoError(t, err)
+
+	ws, err = tc.workspaceService.GetByID(ctx, ws.ID)
+	require.NoError(t, err)
+	ws.ViewID = nil
+	return ws
+}
+
+// latestContents returns the contents of the file in the latest snapshot of the workspace
+func (tc *testCase) latestContents(t *testing.T, workspaceID, filename string) string {
+	ws, err := tc.workspaceService.GetByID(context.Background(), workspaceID)
+	require.NoError(t, err)
+	require.NotNil(t, ws.LatestSnapshotID)
+
+	snapshot, err := tc.snapshotService.GetByID(context.Background(), *ws.LatestSnapshotID)
+	require.NoError(t, err)
+
+	var contents []byte
+	require.NoError(t, tc.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		contents, err = repo.FileContentsAtCommit(snapshot.CommitSHA, filename)
+		return err
+	}).ExecTrunk(tc.codebaseID, "latestContents"))
+	return string(contents)
+}
+
+func TestWorkspaceStatus(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	ws := tc.workspaceWithChanges(t, "a.txt", "workspace\n")
+	tc.commitToTrunk(t, "a.txt", "trunk\n")
+
+	status, err := tc.syncService.WorkspaceStatus(ctx, ws)
+	require.NoError(t, err)
+	require.Len(t, status.ConflictingFiles, 1)
+	assert.Equal(t, "a.txt", status.ConflictingFiles[0].Path)
+
+	// the status is cached until the workspace or the trunk changes
+	cached, err := tc.syncService.WorkspaceStatus(ctx, ws)
+	require.NoError(t, err)
+	assert.Same(t, status, cached)
+
+	tc.commitToTrunk(t, "b.txt", "unrelated\n")
+	recomputed, err := tc.syncService.WorkspaceStatus(ctx, ws)
+	require.NoError(t, err)
+	assert.NotSame(t, status, recomputed)
+	assert.Len(t, recomputed.ConflictingFiles, 1)
+
+	// the workspace is left untouched
+	assert.Equal(t, "workspace\n", tc.latestContents(t, ws.ID, "a.txt"))
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	ws := tc.workspaceWithChanges(t, "a.txt", "workspace\n")
+	tc.commitToTrunk(t, "a.txt", "trunk\n")
+
+	// all conflicts must be resolved
+	_, err := tc.syncService.ResolveWorkspace(ctx, ws, nil)
+	assert.ErrorIs(t, err, service_sync.ErrUnresolvedConflicts)
+	unresolved, err := tc.workspaceService.GetByID(ctx, ws.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ws.LatestSnapshotID, unresolved.LatestSnapshotID, "the workspace must be left untouched")
+
+	// a failed resolution doesn't leave anything behind, the status can still be computed
+	status, err := tc.syncService.WorkspaceStatus(ctx, ws)
+	require.NoError(t, err)
+	assert.Len(t, status.ConflictingFiles, 1)
+
+	_, err = tc.syncService.ResolveWorkspace(ctx, ws, []vcs.SturdyRebaseResolve{{Path: "a.txt", Version: "workspace"}})
+	require.NoError(t, err)
+	assert.Equal(t, "workspace\n", tc.latestContents(t, ws.ID, "a.txt"))
+
+	resolved, err := tc.workspaceService.GetByID(ctx, ws.ID)
+	require.NoError(t, err)
+	resolved.ViewID = nil
+	status, err = tc.syncService.WorkspaceStatus(ctx, resolved)
+	require.NoError(t, err)
+	assert.Empty(t, status.ConflictingFiles)
+}
+
+func TestResolveWorkspace_custom(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	ws := tc.workspaceWithChanges(t, "a.txt", "workspace\n")
+	tc.commitToTrunk(t, "a.txt", "trunk\n")
+
+	contents := []byte("trunk and workspace\n")
+	_, err := tc.syncService.ResolveWorkspace(ctx, ws, []vcs.SturdyRebaseResolve{{Path: "a.txt", Version: "custom", Contents: &contents}})
+	require.NoError(t, err)
+	assert.Equal(t, "trunk and workspace\n", tc.latestContents(t, ws.ID, "a.txt"))
+}
+
+func TestRestack(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	parent := tc.workspaceWithChanges(t, "a.txt", "parent\n")
+	child := tc.stackedWorkspaceWithChanges(t, parent, "b.txt", "child\n")
+
+	// the parent lands
+	tc.commitToTrunk(t, "a.txt", "parent\n")
+
+	require.NoError(t, tc.syncService.Restack(ctx, child))
+
+	restacked, err := tc.workspaceService.GetByID(ctx, child.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restacked.ParentWorkspaceID)
+	assert.Equal(t, "parent\n", tc.latestContents(t, child.ID, "a.txt"))
+	assert.Equal(t, "child\n", tc.latestContents(t, child.ID, "b.txt"))
+
+	// only the changes of the child are left in the workspace
+	require.NotNil(t, restacked.LatestSnapshotID)
+	diffs, err := tc.snapshotService.Diffs(ctx, *restacked.LatestSnapshotID)
+	require.NoError(t, err)
+	if assert.Len(t, diffs, 1) {
+		assert.Equal(t, "b.txt", diffs[0].NewName)
+	}
+}
+
+func TestRestack_conflicts(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	parent := tc.workspaceWithChanges(t, "a.txt", "parent\n")
+	child := tc.stackedWorkspaceWithChanges(t, parent, "a.txt", "child\n")
+
+	// the parent lands with other changes than the child is based on
+	tc.commitToTrunk(t, "a.txt", "landed\n")
+
+	assert.ErrorIs(t, tc.syncService.Restack(ctx, child), service_sync.ErrRestackConflicts)
+
+	// the workspace is left untouched
+	unchanged, err := tc.workspaceService.GetByID(ctx, child.ID)
+	require.NoError(t, err)
+	if assert.NotNil(t, unchanged.ParentWorkspaceID) {
+		assert.Equal(t, parent.ID, *unchanged.ParentWorkspaceID)
+	}
+	assert.Equal(t, child.LatestSnapshotID, unchanged.LatestSnapshotID)
+	assert.Equal(t, "child\n", tc.latestContents(t, child.ID, "a.txt"))
+}
+
+func TestCherryPick(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	ws := tc.workspaceWithChanges(t, "b.txt", "workspace\n")
+	commitID := tc.commitToTrunk(t, "a.txt", "picked\n")
+
+	require.NoError(t, tc.syncService.CherryPick(ctx, ws, &changes.Change{CodebaseID: tc.codebaseID, CommitID: &commitID}))
+
+	// the change is applied on top of the changes in the workspace
+	assert.Equal(t, "picked\n", tc.latestContents(t, ws.ID, "a.txt"))
+	assert.Equal(t, "workspace\n", tc.latestContents(t, ws.ID, "b.txt"))
+}
+
+func TestCherryPick_conflicts(t *testing.T) {
+	tc := setup(t)
+	ctx := context.Background()
+
+	tc.commitToTrunk(t, "a.txt", "history\n")
+	ws := tc.workspaceWithChanges(t, "a.txt", "workspace\n")
+	commitID := tc.commitToTrunk(t, "a.txt", "picked\n")
+
+	err := tc.syncService.CherryPick(ctx, ws, &changes.Change{CodebaseID: tc.codebaseID, CommitID: &commitID})
+	assert.ErrorIs(t, err, service_sync.ErrCherryPickConflicts)
+	assert.ErrorContains(t, err, "a.txt")
+
+	// nothing is applied, and there is no rebase in progress
+	unchanged, err := tc.workspaceService.GetByID(ctx, ws.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ws.LatestSnapshotID, unchanged.LatestSnapshotID)
+	assert.Equal(t, "workspace\n", tc.latestContents(t, ws.ID, "a.txt"))
+}
+
+func TestCherryPick_notLanded(t *testing.T) {
+	tc := setup(t)
+
+	ws := tc.workspaceWithChanges(t, "a.txt", "workspace\n")
+
+	err := tc.syncService.CherryPick(context.Background(), ws, &changes.Change{CodebaseID: tc.codebaseID})
+	assert.ErrorIs(t, err, service_sync.ErrChangeNotLanded)
+}
+
+func writeFile(filename, contents string) func(vcs.RepoWriter) error {
+	return func(repo vcs.RepoWriter) error {
+		return os.WriteFile(path.Join(repo.Path(), filename), []byte(contents), 0o644)
+	}
+}
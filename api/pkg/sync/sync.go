@@ -34,4 +34,12 @@ type ConflictingFile struct {
 	Path          string           `json:"path"`
 	WorkspaceDiff unidiff.FileDiff `json:"workspace_diff"`
 	TrunkDiff     unidiff.FileDiff `json:"trunk_diff"`
+	Hunks         []ConflictHunk   `json:"hunks"`
+}
+
+// ConflictHunk is a part of a conflicting file that has been changed both in the workspace and on the trunk
+type ConflictHunk struct {
+	Base      string `json:"base"`
+	Workspace string `json:"workspace"`
+	Trunk     string `json:"trunk"`
 }
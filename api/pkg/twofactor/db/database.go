@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ EnrollmentRepository   = &enrollmentDatabase{}
+	_ RecoveryCodeRepository = &recoveryCodeDatabase{}
+)
+
+type enrollmentDatabase struct {
+	db *sqlx.DB
+}
+
+func NewEnrollmentDatabase(db *sqlx.DB) EnrollmentRepository {
+	return &enrollmentDatabase{db: db}
+}
+
+func (d *enrollmentDatabase) Create(ctx context.Context, enrollment *twofactor.Enrollment) error {
+	if _, err := d.db.NamedExecContext(ctx, `INSERT INTO user_two_factor
+		(user_id, secret, last_used_step, failed_attempts, locked_until, created_at, confirmed_at)
+		VALUES
+		(:user_id, :secret, :last_used_step, :failed_attempts, :locked_until, :created_at, :confirmed_at)`, enrollment); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *enrollmentDatabase) Get(ctx context.Context, userID users.ID) (*twofactor.Enrollment, error) {
+	var enrollment twofactor.Enrollment
+	if err := d.db.GetContext(ctx, &enrollment, `SELECT user_id, secret, last_used_step, failed_attempts, locked_until, created_at, confirmed_at
+		FROM user_two_factor
+		WHERE user_id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &enrollment, nil
+}
+
+func (d *enrollmentDatabase) Update(ctx context.Context, enrollment *twofactor.Enrollment) error {
+	if _, err := d.db.NamedExecContext(ctx, `UPDATE user_two_factor
+		SET last_used_step = :last_used_step,
+			failed_attempts = :failed_attempts,
+			locked_until = :locked_until,
+			confirmed_at = :confirmed_at
+		WHERE user_id = :user_id`, enrollment); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *enrollmentDatabase) Delete(ctx context.Context, userID users.ID) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM user_two_factor WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+type recoveryCodeDatabase struct {
+	db *sqlx.DB
+}
+
+func NewRecoveryCodeDatabase(db *sqlx.DB) RecoveryCodeRepository {
+	return &recoveryCodeDatabase{db: db}
+}
+
+func (d *recoveryCodeDatabase) Create(ctx context.Context, code *twofactor.RecoveryCode) error {
+	if _, err := d.db.NamedExecContext(ctx, `INSERT INTO user_two_factor_recovery_codes
+		(id, user_id, hash, created_at, used_at)
+		VALUES
+		(:id, :user_id, :hash, :created_at, :used_at)`, code); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *recoveryCodeDatabase) ListByUserID(ctx context.Context, userID users.ID) ([]*twofactor.RecoveryCode, error) {
+	var codes []*twofactor.RecoveryCode
+	if err := d.db.SelectContext(ctx, &codes, `SELECT id, user_id, hash, created_at, used_at
+		FROM user_two_factor_recovery_codes
+		WHERE user_id = $1
+		ORDER BY created_at`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list: %w", err)
+	}
+	return codes, nil
+}
+
+func (d *recoveryCodeDatabase) Update(ctx context.Context, code *twofactor.RecoveryCode) error {
+	if _, err := d.db.NamedExecContext(ctx, `UPDATE user_two_factor_recovery_codes
+		SET used_at = :used_at
+		WHERE id = :id`, code); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *recoveryCodeDatabase) DeleteByUserID(ctx context.Context, userID users.ID) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM user_two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
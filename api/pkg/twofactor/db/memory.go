@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+)
+
+var (
+	_ EnrollmentRepository   = &enrollmentMemory{}
+	_ RecoveryCodeRepository = &recoveryCodeMemory{}
+)
+
+type enrollmentMemory struct {
+	mu       sync.RWMutex
+	byUserID map[users.ID]twofactor.Enrollment
+}
+
+func NewEnrollmentMemory() EnrollmentRepository {
+	return &enrollmentMemory{
+		byUserID: map[users.ID]twofactor.Enrollment{},
+	}
+}
+
+func (m *enrollmentMemory) Create(_ context.Context, enrollment *twofactor.Enrollment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byUserID[enrollment.UserID] = *enrollment
+	return nil
+}
+
+func (m *enrollmentMemory) Get(_ context.Context, userID users.ID) (*twofactor.Enrollment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	enrollment, found := m.byUserID[userID]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &enrollment, nil
+}
+
+func (m *enrollmentMemory) Update(_ context.Context, enrollment *twofactor.Enrollment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.byUserID[enrollment.UserID]; !found {
+		return sql.ErrNoRows
+	}
+	m.byUserID[enrollment.UserID] = *enrollment
+	return nil
+}
+
+func (m *enrollmentMemory) Delete(_ context.Context, userID users.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.byUserID, userID)
+	return nil
+}
+
+type recoveryCodeMemory struct {
+	mu   sync.RWMutex
+	byID map[string]twofactor.RecoveryCode
+}
+
+func NewRecoveryCodeMemory() RecoveryCodeRepository {
+	return &recoveryCodeMemory{
+		byID: map[string]twofactor.RecoveryCode{},
+	}
+}
+
+func (m *recoveryCodeMemory) Create(_ context.Context, code *twofactor.RecoveryCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byID[code.ID] = *code
+	return nil
+}
+
+func (m *recoveryCodeMemory) ListByUserID(_ context.Context, userID users.ID) ([]*twofactor.RecoveryCode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var codes []*twofactor.RecoveryCode
+	for _, code := range m.byID {
+		if code.UserID == userID {
+			code := code
+			codes = append(codes, &code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].CreatedAt.Before(codes[j].CreatedAt)
+	})
+	return codes, nil
+}
+
+func (m *recoveryCodeMemory) Update(_ context.Context, code *twofactor.RecoveryCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.byID[code.ID]; !found {
+		return sql.ErrNoRows
+	}
+	m.byID[code.ID] = *code
+	return nil
+}
+
+func (m *recoveryCodeMemory) DeleteByUserID(_ context.Context, userID users.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, code := range m.byID {
+		if code.UserID == userID {
+			delete(m.byID, id)
+		}
+	}
+	return nil
+}
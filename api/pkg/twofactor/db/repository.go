@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+)
+
+type EnrollmentRepository interface {
+	Create(context.Context, *twofactor.Enrollment) error
+	Get(ctx context.Context, userID users.ID) (*twofactor.Enrollment, error)
+	Update(context.Context, *twofactor.Enrollment) error
+	Delete(ctx context.Context, userID users.ID) error
+}
+
+type RecoveryCodeRepository interface {
+	Create(context.Context, *twofactor.RecoveryCode) error
+	ListByUserID(ctx context.Context, userID users.ID) ([]*twofactor.RecoveryCode, error)
+	Update(context.Context, *twofactor.RecoveryCode) error
+	DeleteByUserID(ctx context.Context, userID users.ID) error
+}
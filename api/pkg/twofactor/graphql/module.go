@@ -0,0 +1,11 @@
+package graphql
+
+import (
+	"getsturdy.com/api/pkg/di"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(service_twofactor.Module)
+	c.Register(New)
+}
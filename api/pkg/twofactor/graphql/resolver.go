@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
+)
+
+type rootResolver struct {
+	twoFactorService *service_twofactor.Service
+}
+
+func New(twoFactorService *service_twofactor.Service) resolvers.TwoFactorRootResolver {
+	return &rootResolver{
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (r *rootResolver) TwoFactor(ctx context.Context) (resolvers.TwoFactorResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return r.status(ctx, userID)
+}
+
+func (r *rootResolver) EnrollTwoFactor(ctx context.Context) (resolvers.TwoFactorEnrollmentResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	secret, uri, err := r.twoFactorService.Enroll(ctx, userID)
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+
+	return &enrollmentResolver{secret: secret, uri: uri}, nil
+}
+
+func (r *rootResolver) ConfirmTwoFactor(ctx context.Context, args resolvers.TwoFactorCodeArgs) ([]string, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	recoveryCodes, err := r.twoFactorService.Confirm(ctx, userID, args.Input.Code)
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+
+	return recoveryCodes, nil
+}
+
+func (r *rootResolver) DisableTwoFactor(ctx context.Context, args resolvers.TwoFactorCodeArgs) (resolvers.TwoFactorResolver, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.twoFactorService.Disable(ctx, userID, args.Input.Code); err != nil {
+		return nil, toGraphQLError(err)
+	}
+
+	return r.status(ctx, userID)
+}
+
+func (r *rootResolver) RegenerateTwoFactorRecoveryCodes(ctx context.Context, args resolvers.TwoFactorCodeArgs) ([]string, error) {
+	userID, err := auth.AdminUserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	recoveryCodes, err := r.twoFactorService.RegenerateRecoveryCodes(ctx, userID, args.Input.Code)
+	if err != nil {
+		return nil, toGraphQLError(err)
+	}
+
+	return recoveryCodes, nil
+}
+
+func (r *rootResolver) status(ctx context.Context, userID users.ID) (resolvers.TwoFactorResolver, error) {
+	enabled, err := r.twoFactorService.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	recoveryCodesLeft, err := r.twoFactorService.RecoveryCodesLeft(ctx, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &statusResolver{enabled: enabled, recoveryCodesLeft: recoveryCodesLeft}, nil
+}
+
+func toGraphQLError(err error) error {
+	switch {
+	case errors.Is(err, service_twofactor.ErrInvalidCode),
+		errors.Is(err, service_twofactor.ErrLocked):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "code", err.Error())
+	case errors.Is(err, service_twofactor.ErrAlreadyEnabled),
+		errors.Is(err, service_twofactor.ErrNotEnabled):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "message", err.Error())
+	default:
+		return gqlerrors.Error(err)
+	}
+}
+
+type statusResolver struct {
+	enabled           bool
+	recoveryCodesLeft int
+}
+
+func (r *statusResolver) Enabled() bool {
+	return r.enabled
+}
+
+func (r *statusResolver) RecoveryCodesLeft() int32 {
+	return int32(r.recoveryCodesLeft)
+}
+
+type enrollmentResolver struct {
+	secret string
+	uri    string
+}
+
+func (r *enrollmentResolver) Secret() string {
+	return r.secret
+}
+
+func (r *enrollmentResolver) URI() string {
+	return r.uri
+}
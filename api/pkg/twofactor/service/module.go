@@ -0,0 +1,17 @@
+package service
+
+import (
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/logger"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	service_users "getsturdy.com/api/pkg/users/service/module"
+)
+
+func Module(c *di.Container) {
+	c.Import(logger.Module)
+	c.Import(db_twofactor.Module)
+	c.Import(service_users.Module)
+	c.Import(service_analytics.Module)
+	c.Register(New)
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode    = errors.New("invalid code")
+	ErrLocked         = errors.New("too many invalid codes, try again later")
+)
+
+const (
+	bcryptCost = bcrypt.DefaultCost
+
+	issuer = "Sturdy"
+
+	recoveryCodesCount = 10
+	// recoveryCodeLength is the number of characters in a recovery code, not counting the separator
+	recoveryCodeLength = 10
+
+	// maxFailedAttempts is the number of invalid codes in a row after which verification is locked for lockDuration
+	maxFailedAttempts = 5
+	lockDuration      = 15 * time.Minute
+)
+
+type Service struct {
+	logger           *zap.Logger
+	enrollmentRepo   db_twofactor.EnrollmentRepository
+	recoveryCodeRepo db_twofactor.RecoveryCodeRepository
+	userService      service_user.Service
+	analyticsService *service_analytics.Service
+}
+
+func New(
+	logger *zap.Logger,
+	enrollmentRepo db_twofactor.EnrollmentRepository,
+	recoveryCodeRepo db_twofactor.RecoveryCodeRepository,
+	userService service_user.Service,
+	analyticsService *service_analytics.Service,
+) *Service {
+	return &Service{
+		logger:           logger.Named("twofactor"),
+		enrollmentRepo:   enrollmentRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		userService:      userService,
+		analyticsService: analyticsService,
+	}
+}
+
+// Enroll starts the enrollment of a new second factor for the user. It returns the secret, and an otpauth uri that
+// can be shown as a QR code. The second factor is not enabled until it's confirmed with Confirm.
+func (s *Service) Enroll(ctx context.Context, userID users.ID) (string, string, error) {
+	existing, err := s.enrollmentRepo.Get(ctx, userID)
+	switch {
+	case err == nil && existing.IsConfirmed():
+		return "", "", ErrAlreadyEnabled
+	case err == nil:
+		// replace the unconfirmed enrollment
+		if err := s.enrollmentRepo.Delete(ctx, userID); err != nil {
+			return "", "", fmt.Errorf("failed to delete enrollment: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		return "", "", fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := twofactor.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.enrollmentRepo.Create(ctx, &twofactor.Enrollment{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return "", "", fmt.Errorf("failed to create enrollment: %w", err)
+	}
+
+	return secret, twofactor.URI(issuer, user.Email, secret), nil
+}
+
+// Confirm enables two-factor authentication for the user if the code is valid for the pending enrollment. It returns
+// a new set of recovery codes in plaintext, they are not stored and can not be recovered.
+func (s *Service) Confirm(ctx context.Context, userID users.ID, code string) ([]string, error) {
+	enrollment, err := s.enrollmentRepo.Get(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotEnabled
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	if enrollment.IsConfirmed() {
+		return nil, ErrAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := twofactor.Validate(enrollment.Secret, code, now, enrollment.LastUsedStep)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	enrollment.LastUsedStep = step
+	enrollment.ConfirmedAt = &now
+	if err := s.enrollmentRepo.Update(ctx, enrollment); err != nil {
+		return nil, fmt.Errorf("failed to update enrollment: %w", err)
+	}
+
+	recoveryCodes, err := s.createRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.analyticsService.CaptureUser(ctx, userID, "enabled two-factor authentication")
+
+	return recoveryCodes, nil
+}
+
+// IsEnabled returns true if the user has a confirmed second factor.
+func (s *Service) IsEnabled(ctx context.Context, userID users.ID) (bool, error) {
+	enrollment, err := s.enrollmentRepo.Get(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+	return enrollment.IsConfirmed(), nil
+}
+
+// RecoveryCodesLeft returns the number of unused recovery codes of the user.
+func (s *Service) RecoveryCodesLeft(ctx context.Context, userID users.ID) (int, error) {
+	recoveryCodes, err := s.recoveryCodeRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list recovery codes: %w", err)
+	}
+	left := 0
+	for _, recoveryCode := range recoveryCodes {
+		if !recoveryCode.IsUsed() {
+			left++
+		}
+	}
+	return left, nil
+}
+
+// Verify returns nil if the code is a valid TOTP code, or an unused recovery code of the user. Recovery codes can only
+// be used once. After too many invalid codes, verification is locked for a while.
+func (s *Service) Verify(ctx context.Context, userID users.ID, code string) error {
+	enrollment, err := s.enrollmentRepo.Get(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotEnabled
+	} else if err != nil {
+		return fmt.Errorf("failed to get enrollment: %w", err)
+	}
+
+	if !enrollment.IsConfirmed() {
+		return ErrNotEnabled
+	}
+
+	now := time.Now()
+	if enrollment.IsLocked(now) {
+		return ErrLocked
+	}
+
+	var verifyErr error
+	if step, ok := twofactor.Validate(enrollment.Secret, code, now, enrollment.LastUsedStep); ok {
+		enrollment.LastUsedStep = step
+		verifyErr = nil
+	} else {
+		verifyErr = s.useRecoveryCode(ctx, userID, code)
+	}
+
+	switch {
+	case verifyErr == nil:
+		enrollment.FailedAttempts = 0
+		enrollment.LockedUntil = nil
+	case errors.Is(verifyErr, ErrInvalidCode):
+		enrollment.FailedAttempts++
+		if enrollment.FailedAttempts >= maxFailedAttempts {
+			lockedUntil := now.Add(lockDuration)
+			enrollment.FailedAttempts = 0
+			enrollment.LockedUntil = &lockedUntil
+			s.logger.Warn("too many invalid two-factor codes, locking", zap.Stringer("user_id", userID))
+		}
+	default:
+		return verifyErr
+	}
+
+	if err := s.enrollmentRepo.Update(ctx, enrollment); err != nil {
+		return fmt.Errorf("failed to update enrollment: %w", err)
+	}
+
+	return verifyErr
+}
+
+// Disable removes the second factor and the recovery codes of the user. The code must be valid.
+func (s *Service) Disable(ctx context.Context, userID users.ID, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := s.enrollmentRepo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete enrollment: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, userID, "disabled two-factor authentication")
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user with new ones. The code must be valid.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID users.ID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	return s.createRecoveryCodes(ctx, userID)
+}
+
+func (s *Service) createRecoveryCodes(ctx context.Context, userID users.ID) ([]string, error) {
+	plainTextCodes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		plainTextCode, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(plainTextCode)), bcryptCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code hash: %w", err)
+		}
+
+		if err := s.recoveryCodeRepo.Create(ctx, &twofactor.RecoveryCode{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			Hash:      hash,
+			CreatedAt: time.Now(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create recovery code: %w", err)
+		}
+
+		plainTextCodes = append(plainTextCodes, plainTextCode)
+	}
+	return plainTextCodes, nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, userID users.ID, code string) error {
+	code = normalizeRecoveryCode(code)
+	if len(code) != recoveryCodeLength {
+		return ErrInvalidCode
+	}
+
+	recoveryCodes, err := s.recoveryCodeRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list recovery codes: %w", err)
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if recoveryCode.IsUsed() {
+			continue
+		}
+		if err := bcrypt.CompareHashAndPassword(recoveryCode.Hash, []byte(code)); err != nil {
+			continue
+		}
+
+		now := time.Now()
+		recoveryCode.UsedAt = &now
+		if err := s.recoveryCodeRepo.Update(ctx, recoveryCode); err != nil {
+			return fmt.Errorf("failed to update recovery code: %w", err)
+		}
+
+		s.analyticsService.CaptureUser(ctx, userID, "used two-factor recovery code")
+
+		return nil
+	}
+
+	return ErrInvalidCode
+}
+
+// recoveryCodeAlphabet doesn't contain characters that are easy to confuse, like 0 and o
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	random := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	var b strings.Builder
+	for i, r := range random {
+		if i == recoveryCodeLength/2 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(recoveryCodeAlphabet[int(r)%len(recoveryCodeAlphabet)])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
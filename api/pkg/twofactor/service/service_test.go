@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/analytics/disabled"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newService(t *testing.T) (*service_twofactor.Service, *users.User) {
+	logger := zap.NewNop()
+	analyticsService := service_analytics.New(logger, disabled.NewClient(logger))
+	userRepo := db_users.NewMemory()
+	userService := service_users.New(logger, userRepo, analyticsService)
+
+	user := &users.User{ID: users.ID(uuid.NewString()), Email: uuid.NewString() + "@getsturdy.com", Status: users.StatusActive}
+	require.NoError(t, userRepo.Create(user))
+
+	return service_twofactor.New(logger, db_twofactor.NewEnrollmentMemory(), db_twofactor.NewRecoveryCodeMemory(), userService, analyticsService), user
+}
+
+func code(t *testing.T, secret string, at time.Time) string {
+	c, err := twofactor.Code(secret, twofactor.Step(at))
+	require.NoError(t, err)
+	return c
+}
+
+func enable(t *testing.T, svc *service_twofactor.Service, userID users.ID) (string, []string) {
+	secret, uri, err := svc.Enroll(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Contains(t, uri, secret)
+
+	// use the code from the previous step, so that the current one can be used in the test
+	recoveryCodes, err := svc.Confirm(context.Background(), userID, code(t, secret, time.Now().Add(-30*time.Second)))
+	require.NoError(t, err)
+	return secret, recoveryCodes
+}
+
+func TestConfirm(t *testing.T) {
+	svc, user := newService(t)
+	ctx := context.Background()
+
+	secret, _, err := svc.Enroll(ctx, user.ID)
+	require.NoError(t, err)
+
+	enabled, err := svc.IsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled, "unconfirmed enrollments must not be enabled")
+
+	_, err = svc.Confirm(ctx, user.ID, "000000")
+	assert.ErrorIs(t, err, service_twofactor.ErrInvalidCode)
+
+	recoveryCodes, err := svc.Confirm(ctx, user.ID, code(t, secret, time.Now()))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	enabled, err = svc.IsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, _, err = svc.Enroll(ctx, user.ID)
+	assert.ErrorIs(t, err, service_twofactor.ErrAlreadyEnabled)
+}
+
+func TestVerify(t *testing.T) {
+	svc, user := newService(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, "123456"), service_twofactor.ErrNotEnabled)
+
+	secret, _ := enable(t, svc, user.ID)
+
+	current := code(t, secret, time.Now())
+	assert.NoError(t, svc.Verify(ctx, user.ID, current))
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, current), service_twofactor.ErrInvalidCode, "codes must not be reusable")
+}
+
+func TestVerify_recoveryCode(t *testing.T) {
+	svc, user := newService(t)
+	ctx := context.Background()
+
+	_, recoveryCodes := enable(t, svc, user.ID)
+
+	assert.NoError(t, svc.Verify(ctx, user.ID, recoveryCodes[0]))
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, recoveryCodes[0]), service_twofactor.ErrInvalidCode, "recovery codes must not be reusable")
+
+	left, err := svc.RecoveryCodesLeft(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, left)
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, "aaaaa-aaaaa"), service_twofactor.ErrInvalidCode)
+}
+
+func TestVerify_locked(t *testing.T) {
+	svc, user := newService(t)
+	ctx := context.Background()
+
+	secret, _ := enable(t, svc, user.ID)
+
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(t, svc.Verify(ctx, user.ID, "000000"), service_twofactor.ErrInvalidCode)
+	}
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, code(t, secret, time.Now())), service_twofactor.ErrLocked)
+}
+
+func TestDisable(t *testing.T) {
+	svc, user := newService(t)
+	ctx := context.Background()
+
+	secret, recoveryCodes := enable(t, svc, user.ID)
+
+	assert.ErrorIs(t, svc.Disable(ctx, user.ID, "000000"), service_twofactor.ErrInvalidCode)
+	require.NoError(t, svc.Disable(ctx, user.ID, code(t, secret, time.Now())))
+
+	enabled, err := svc.IsEnabled(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	// enabling again, doesn't make the old recovery codes valid
+	enable(t, svc, user.ID)
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, recoveryCodes[1]), service_twofactor.ErrInvalidCode)
+}
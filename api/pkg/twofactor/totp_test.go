@@ -0,0 +1,75 @@
+package twofactor_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/twofactor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secret from the test vectors in RFC 6238
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+	for _, tc := range tests {
+		code, err := twofactor.Code(rfcSecret, twofactor.Step(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tc.code, code, "at %d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := twofactor.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	step := twofactor.Step(now)
+
+	code, err := twofactor.Code(secret, step)
+	require.NoError(t, err)
+
+	validStep, ok := twofactor.Validate(secret, code, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, validStep)
+
+	// the previous code is accepted to allow for clock drift
+	previous, err := twofactor.Code(secret, step-1)
+	require.NoError(t, err)
+	_, ok = twofactor.Validate(secret, previous, now, 0)
+	assert.True(t, ok)
+
+	// codes can't be used twice
+	_, ok = twofactor.Validate(secret, code, now, step)
+	assert.False(t, ok)
+
+	// codes too far in the past are rejected
+	old, err := twofactor.Code(secret, step-5)
+	require.NoError(t, err)
+	_, ok = twofactor.Validate(secret, old, now, 0)
+	assert.False(t, ok)
+
+	_, ok = twofactor.Validate(secret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := twofactor.URI("Sturdy", "alice@getsturdy.com", "ABCDEF")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Sturdy:alice@getsturdy.com?"), uri)
+	assert.Contains(t, uri, "secret=ABCDEF")
+	assert.Contains(t, uri, "issuer=Sturdy")
+}
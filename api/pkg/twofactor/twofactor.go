@@ -0,0 +1,44 @@
+package twofactor
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+// Enrollment is the TOTP second factor of a user. It's not required for logins until it has been confirmed with a
+// valid code.
+type Enrollment struct {
+	UserID users.ID `db:"user_id"`
+	// Secret is the base32 encoded TOTP secret
+	Secret string `db:"secret"`
+	// LastUsedStep is the time step of the last accepted code, codes can not be used more than once
+	LastUsedStep int64 `db:"last_used_step"`
+	// FailedAttempts is the number of invalid codes since the last valid one, the enrollment is locked for a while
+	// after too many of them.
+	FailedAttempts int        `db:"failed_attempts"`
+	LockedUntil    *time.Time `db:"locked_until"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ConfirmedAt    *time.Time `db:"confirmed_at"`
+}
+
+func (e *Enrollment) IsConfirmed() bool {
+	return e.ConfirmedAt != nil
+}
+
+func (e *Enrollment) IsLocked(now time.Time) bool {
+	return e.LockedUntil != nil && now.Before(*e.LockedUntil)
+}
+
+// RecoveryCode can be used instead of a TOTP code if the user has lost their device. Each code can only be used once.
+type RecoveryCode struct {
+	ID        string     `db:"id"`
+	UserID    users.ID   `db:"user_id"`
+	Hash      []byte     `db:"hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+func (c *RecoveryCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
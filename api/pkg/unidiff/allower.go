@@ -134,11 +134,14 @@ func deduplicate(ss []string) []string {
 	return noDuplicates
 }
 
+// gitPatterns are added to all allowers, the .git directory is never allowed.
+var gitPatterns = []string{"!.git", "!.git/**/*"}
+
 // NewAllower creates a new allower given a list of user-provided allow
 // patterns.
 func NewAllower(patterns ...string) (*Allower, error) {
 	patterns = deduplicate(patterns)
-	patterns = append(patterns, "!.git", "!.git/**/*")
+	patterns = append(patterns, gitPatterns...)
 	// Parse patterns.
 	allowPatterns := make([]*allowPattern, len(patterns))
 	for i, p := range patterns {
@@ -175,3 +178,19 @@ func (i *Allower) IsAllowed(path string, directory bool) bool {
 	// Done.
 	return allowed
 }
+
+// AllowsAll returns true if all paths, except for the .git directory, are allowed. It's conservative, it returns false
+// for all allowers with exceptions, even if the exceptions don't match anything.
+func (i *Allower) AllowsAll() bool {
+	all := false
+	for _, p := range i.patterns[:len(i.patterns)-len(gitPatterns)] {
+		switch {
+		case p.negated:
+			all = false
+		case p.directoryOnly:
+		case p.pattern == "**", p.pattern == "*" && p.matchLeaf:
+			all = true
+		}
+	}
+	return all
+}
@@ -259,3 +259,27 @@ func TestAllower_GitIsAlwaysHidden_evenIfAllowed(t *testing.T) {
 	}
 	test.run(t)
 }
+
+func TestAllower_AllowsAll(t *testing.T) {
+	cases := []struct {
+		allows   []string
+		expected bool
+	}{
+		{allows: nil, expected: false},
+		{allows: []string{"*"}, expected: true},
+		{allows: []string{"**"}, expected: true},
+		{allows: []string{"/**"}, expected: true},
+		{allows: []string{"!secret", "*"}, expected: true},
+		{allows: []string{"/*"}, expected: false},
+		{allows: []string{"src/**"}, expected: false},
+		{allows: []string{"*", "!secret"}, expected: false},
+		{allows: []string{"*/"}, expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprint(tc.allows), func(t *testing.T) {
+			allower, err := unidiff.NewAllower(tc.allows...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, allower.AllowsAll())
+		})
+	}
+}
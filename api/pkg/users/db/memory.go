@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	"getsturdy.com/api/pkg/users"
 )
@@ -27,6 +28,11 @@ func (f *inMemoryUserRepo) Create(newUser *users.User) error {
 }
 
 func (f *inMemoryUserRepo) Get(id users.ID) (*users.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
 	return &users.User{
 		ID:    id,
 		Name:  "Test Testsson",
@@ -35,15 +41,34 @@ func (f *inMemoryUserRepo) Get(id users.ID) (*users.User, error) {
 }
 
 func (f *inMemoryUserRepo) GetByIDs(_ context.Context, ids ...users.ID) ([]*users.User, error) {
-	return nil, nil
+	var res []*users.User
+	for _, u := range f.users {
+		for _, id := range ids {
+			if u.ID == id {
+				res = append(res, u)
+			}
+		}
+	}
+	return res, nil
 }
 
 func (f *inMemoryUserRepo) GetByEmail(email string) (*users.User, error) {
-	panic("not implemented")
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
 }
 
 func (f *inMemoryUserRepo) Update(u *users.User) error {
-	panic("not implemented")
+	for i, existing := range f.users {
+		if existing.ID == u.ID {
+			f.users[i] = u
+			return nil
+		}
+	}
+	return sql.ErrNoRows
 }
 
 func (f *inMemoryUserRepo) UpdatePassword(u *users.User) error {
@@ -8,6 +8,7 @@ import (
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/onetime/service"
 	service_user "getsturdy.com/api/pkg/users/enterprise/cloud/service"
+	service_users "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -46,7 +47,10 @@ func VerifyMagicLink(logger *zap.Logger, userService *service_user.Service, jwtS
 			return
 		}
 
-		if err := userService.Activate(c.Request.Context(), user); err != nil {
+		if err := userService.Activate(c.Request.Context(), user); errors.Is(err, service_users.ErrDeactivated) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "user is deactivated"})
+			return
+		} else if err != nil {
 			logger.Error("failed to update user's status", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
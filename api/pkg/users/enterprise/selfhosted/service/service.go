@@ -58,3 +58,16 @@ func (s *Service) CreateWithPassword(ctx context.Context, name, password, email
 
 	return usr, nil
 }
+
+func (s *Service) CreateVerified(ctx context.Context, name, email string) (*users.User, error) {
+	if err := s.ValidateUserCount(ctx); err != nil {
+		return nil, err
+	}
+
+	usr, err := s.Service.CreateVerified(ctx, name, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return usr, nil
+}
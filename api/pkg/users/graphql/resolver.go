@@ -69,7 +69,8 @@ func (r *userRootResolver) InternalUser(_ context.Context, id users.ID) (resolve
 }
 
 func (r *userRootResolver) UpdateUser(ctx context.Context, args resolvers.UpdateUserArgs) (resolvers.UserResolver, error) {
-	userID, err := auth.UserID(ctx)
+	// the email and the password are credentials, and can't be changed with a scoped token
+	userID, err := auth.AdminUserID(ctx)
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
@@ -33,19 +33,39 @@ func (s *Service) CreateWithPassword(ctx context.Context, name, password, email
 		return nil, err
 	}
 
+	if err := s.addToFirstOrganization(ctx, usr); err != nil {
+		return nil, err
+	}
+
+	return usr, nil
+}
+
+func (s *Service) CreateVerified(ctx context.Context, name, email string) (*users.User, error) {
+	usr, err := s.UserService.CreateVerified(ctx, name, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addToFirstOrganization(ctx, usr); err != nil {
+		return nil, err
+	}
+
+	return usr, nil
+}
+
+func (s *Service) addToFirstOrganization(ctx context.Context, usr *users.User) error {
 	// If this instance has an organization, auto-add this user
 	first, err := s.organizationService.GetFirst(ctx)
 	switch {
 	case err == nil:
 		// add this user
 		if _, err := s.organizationService.AddMember(ctx, first.ID, usr.ID, usr.ID); err != nil {
-			return nil, fmt.Errorf("failed to add member to existing org: %w", err)
+			return fmt.Errorf("failed to add member to existing org: %w", err)
 		}
 	case errors.Is(err, sql.ErrNoRows):
 	// first org has not been created yet, this user will create it later
 	case err != nil:
-		return nil, fmt.Errorf("failed to check if an organization already exists: %w", err)
+		return fmt.Errorf("failed to check if an organization already exists: %w", err)
 	}
-
-	return usr, nil
+	return nil
 }
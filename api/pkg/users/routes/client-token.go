@@ -23,8 +23,12 @@ var (
 
 func ClientToken(db db.Repository, jwtService *service_jwt.Service) func(*gin.Context) {
 	return func(c *gin.Context) {
-		userID, err := auth.UserID(c.Request.Context())
-		if err != nil {
+		userID, err := auth.UnscopedUserID(c.Request.Context())
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		case err != nil:
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
@@ -52,8 +56,12 @@ func ClientToken(db db.Repository, jwtService *service_jwt.Service) func(*gin.Co
 func RenewToken(logger *zap.Logger, db db.Repository, jwtService *service_jwt.Service) func(*gin.Context) {
 	return func(c *gin.Context) {
 
-		userID, err := auth.UserID(c.Request.Context())
-		if err != nil {
+		userID, err := auth.UnscopedUserID(c.Request.Context())
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		case err != nil:
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
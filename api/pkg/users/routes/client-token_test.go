@@ -0,0 +1,58 @@
+//nolint:bodyclose
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"getsturdy.com/api/pkg/auth"
+	db_jwt_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_jwt_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/users"
+	db_users "getsturdy.com/api/pkg/users/db"
+	"getsturdy.com/api/pkg/users/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestClientToken_scopedSubject(t *testing.T) {
+	userRepo := db_users.NewMemory()
+	require.NoError(t, userRepo.Create(&users.User{ID: "user-id", Email: "user@getsturdy.com"}))
+	jwtService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+
+	cases := []struct {
+		name     string
+		scopes   []auth.Scope
+		expected int
+	}{
+		{name: "session", scopes: nil, expected: http.StatusOK},
+		{name: "personal access token", scopes: []auth.Scope{auth.ScopeReadCodebase}, expected: http.StatusForbidden},
+		{name: "admin personal access token", scopes: []auth.Scope{auth.ScopeAdmin}, expected: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), &auth.Subject{
+					ID:     "user-id",
+					Type:   auth.SubjectUser,
+					Scopes: tc.scopes,
+				}))
+			})
+			router.POST("/v3/auth/client-token", routes.ClientToken(userRepo, jwtService))
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/v3/auth/client-token", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
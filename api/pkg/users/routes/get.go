@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -13,8 +14,12 @@ import (
 
 func GetSelf(userService service_users.Service, jwtService *service_jwt.Service) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		userID, err := auth.UserID(c.Request.Context())
-		if err != nil {
+		userID, err := auth.UnscopedUserID(c.Request.Context())
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		case err != nil:
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
@@ -1,13 +1,18 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
 	service_users "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +20,17 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func Login(logger *zap.Logger, userService service_users.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service) func(c *gin.Context) {
+// twoFactorTokenValidFor is how long users have to enter their second factor after logging in with a password
+const twoFactorTokenValidFor = 5 * time.Minute
+
+// TwoFactorRequiredResponse is returned instead of the user when the user has two-factor authentication enabled. The
+// login is completed by posting the token together with a code to LoginTwoFactor.
+type TwoFactorRequiredResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	TwoFactorToken    string `json:"two_factor_token"`
+}
+
+func Login(logger *zap.Logger, userService service_users.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service, twoFactorService *service_twofactor.Service) func(c *gin.Context) {
 	type request struct {
 		Email    string `json:"email" binding:"required"`
 		Password string `json:"password" binding:"required"`
@@ -48,23 +63,108 @@ func Login(logger *zap.Logger, userService service_users.Service, analyticsServi
 			return
 		}
 
-		if err := auth.SetAuthCookieForUser(c, getUser.ID, jwtService); err != nil {
-			logger.Error("failed to set auth cookie", zap.Error(err))
+		if err := userService.Activate(c.Request.Context(), getUser); errors.Is(err, service_users.ErrDeactivated) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User is deactivated"})
+			return
+		} else if err != nil {
+			logger.Error("failed to activate user", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
-		if err := userService.Activate(c.Request.Context(), getUser); err != nil {
-			logger.Error("failed to activate user", zap.Error(err))
-			c.AbortWithStatus(http.StatusInternalServerError)
+		completeOrRequireTwoFactor(c, logger, getUser, "password", analyticsService, jwtService, twoFactorService)
+	}
+}
+
+// LoginTwoFactor completes a password login of a user with two-factor authentication enabled. The code can be either
+// a TOTP code, or one of the users recovery codes.
+func LoginTwoFactor(logger *zap.Logger, userService service_users.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service, twoFactorService *service_twofactor.Service) func(c *gin.Context) {
+	type request struct {
+		TwoFactorToken string `json:"two_factor_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warn("failed to bind input", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Something went wrong, please check the input and try again"})
 			return
 		}
 
 		ctx := c.Request.Context()
-		analyticsService.IdentifyUser(ctx, getUser)
-		analyticsService.CaptureUser(ctx, getUser.ID, "logged in", analytics.Property("type", "password"))
 
-		// Send the user object in the response
-		c.JSON(http.StatusOK, getUser)
+		token, err := jwtService.Verify(ctx, req.TwoFactorToken, jwt.TokenTypeTwoFactor)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "The login has expired, please log in again"})
+			return
+		}
+
+		getUser, err := userService.GetByID(ctx, users.ID(token.Subject))
+		if err != nil {
+			logger.Error("failed to get user", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if getUser.Status == users.StatusDeactivated {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User is deactivated"})
+			return
+		}
+
+		if err := twoFactorService.Verify(ctx, getUser.ID, req.Code); errors.Is(err, service_twofactor.ErrInvalidCode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid code, please check the input and try again"})
+			return
+		} else if errors.Is(err, service_twofactor.ErrLocked) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many invalid codes, please try again later"})
+			return
+		} else if err != nil {
+			logger.Error("failed to verify two-factor code", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		completeLogin(c, logger, getUser, "password", analyticsService, jwtService)
+	}
+}
+
+// completeOrRequireTwoFactor completes the login of a user that has verified their password, unless they have
+// two-factor authentication enabled.
+func completeOrRequireTwoFactor(c *gin.Context, logger *zap.Logger, user *users.User, loginType string, analyticsService *service_analytics.Service, jwtService *service_jwt.Service, twoFactorService *service_twofactor.Service) {
+	ctx := c.Request.Context()
+
+	twoFactorEnabled, err := twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		logger.Error("failed to check two-factor authentication", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if twoFactorEnabled {
+		token, err := jwtService.IssueToken(ctx, user.ID.String(), twoFactorTokenValidFor, jwt.TokenTypeTwoFactor)
+		if err != nil {
+			logger.Error("failed to issue two-factor token", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, TwoFactorRequiredResponse{TwoFactorRequired: true, TwoFactorToken: token.Token})
+		return
 	}
+
+	completeLogin(c, logger, user, loginType, analyticsService, jwtService)
+}
+
+func completeLogin(c *gin.Context, logger *zap.Logger, user *users.User, loginType string, analyticsService *service_analytics.Service, jwtService *service_jwt.Service) {
+	if err := auth.SetAuthCookieForUser(c, user.ID, jwtService); err != nil {
+		logger.Error("failed to set auth cookie", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx := c.Request.Context()
+	analyticsService.IdentifyUser(ctx, user)
+	analyticsService.CaptureUser(ctx, user.ID, "logged in", analytics.Property("type", loginType))
+
+	// Send the user object in the response
+	c.JSON(http.StatusOK, user)
 }
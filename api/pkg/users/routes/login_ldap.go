@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_ldap "getsturdy.com/api/pkg/ldap/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LoginLDAP logs in a user with their username and password in the directory. Users with two-factor authentication
+// enabled complete the login with LoginTwoFactor, just like password logins.
+func LoginLDAP(logger *zap.Logger, ldapService *service_ldap.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service, twoFactorService *service_twofactor.Service) func(c *gin.Context) {
+	type request struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		if !ldapService.Enabled() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Warn("failed to bind input", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Something went wrong, please check the input and try again"})
+			return
+		}
+
+		user, err := ldapService.Login(c.Request.Context(), req.Username, req.Password)
+		switch {
+		case err == nil:
+		case errors.Is(err, service_ldap.ErrInvalidCredentials):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid username or password, please check the input and try again"})
+			return
+		case errors.Is(err, service_ldap.ErrNoEmail):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Your account in the directory has no email address, please contact the server administrator"})
+			return
+		case errors.Is(err, service_ldap.ErrEmailTaken):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A user with the email address of your account in the directory already exists, please contact the server administrator"})
+			return
+		case errors.Is(err, service_users.ErrDeactivated):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User is deactivated"})
+			return
+		case errors.Is(err, service_users.ErrExceeded):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "This service is exceeding the number of users allowed. Please contact the server administrator or email support@getsturdy.com"})
+			return
+		default:
+			logger.Error("failed to login with ldap", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		completeOrRequireTwoFactor(c, logger, user, "ldap", analyticsService, jwtService, twoFactorService)
+	}
+}
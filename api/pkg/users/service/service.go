@@ -31,6 +31,7 @@ type UserService struct {
 
 type Service interface {
 	CreateWithPassword(ctx context.Context, name, password, email string) (*users.User, error)
+	CreateVerified(ctx context.Context, name, email string) (*users.User, error)
 	GetByIDs(context.Context, ...users.ID) ([]*users.User, error)
 	GetByID(context.Context, users.ID) (*users.User, error)
 	GetByEmail(_ context.Context, email string) (*users.User, error)
@@ -38,6 +39,8 @@ type Service interface {
 	GetFirstUser(ctx context.Context) (*users.User, error)
 	GetAsAuthor(context.Context, users.ID) (*author.Author, error)
 	Activate(context.Context, *users.User) error
+	Deactivate(context.Context, *users.User) error
+	Reactivate(context.Context, *users.User) error
 	Inherit(context.Context, users.ID, *users.User) error
 	CreateShadow(ctx context.Context, email string, referer Referer, name *string) (*users.User, error)
 }
@@ -55,8 +58,9 @@ func New(
 }
 
 var (
-	ErrExists   = fmt.Errorf("user already exists")
-	ErrNotFound = fmt.Errorf("user not found")
+	ErrExists      = fmt.Errorf("user already exists")
+	ErrNotFound    = fmt.Errorf("user not found")
+	ErrDeactivated = fmt.Errorf("user is deactivated")
 )
 
 func (s *UserService) GetFirstUser(ctx context.Context) (*users.User, error) {
@@ -97,6 +101,9 @@ func (s *UserService) Activate(ctx context.Context, user *users.User) error {
 	if user.Status == users.StatusActive {
 		return nil
 	}
+	if user.Status == users.StatusDeactivated {
+		return ErrDeactivated
+	}
 
 	user.Status = users.StatusActive
 	if err := s.userRepo.Update(user); err != nil {
@@ -109,6 +116,38 @@ func (s *UserService) Activate(ctx context.Context, user *users.User) error {
 	return nil
 }
 
+// Deactivate deactivates the user, deactivated users can't log in until they are reactivated.
+func (s *UserService) Deactivate(ctx context.Context, user *users.User) error {
+	if user.Status == users.StatusDeactivated {
+		return nil
+	}
+
+	user.Status = users.StatusDeactivated
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, user.ID, "user deactivated")
+
+	return nil
+}
+
+// Reactivate reactivates a deactivated user. They are a shadow user until they log in again.
+func (s *UserService) Reactivate(ctx context.Context, user *users.User) error {
+	if user.Status != users.StatusDeactivated {
+		return nil
+	}
+
+	user.Status = users.StatusShadow
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.analyticsService.CaptureUser(ctx, user.ID, "user reactivated")
+
+	return nil
+}
+
 func (s *UserService) CreateShadow(ctx context.Context, email string, referer Referer, name *string) (*users.User, error) {
 	if _, err := s.userRepo.GetByEmail(email); errors.Is(err, sql.ErrNoRows) {
 		// all good
@@ -177,6 +216,36 @@ func (s *UserService) CreateWithPassword(ctx context.Context, name, password, em
 	return newUser, nil
 }
 
+// CreateVerified creates an active user without a password, whose email has been verified by an identity provider.
+func (s *UserService) CreateVerified(ctx context.Context, name, email string) (*users.User, error) {
+	if _, err := s.userRepo.GetByEmail(email); errors.Is(err, sql.ErrNoRows) {
+		// all good
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	} else {
+		return nil, ErrExists
+	}
+
+	t := time.Now()
+	newUser := &users.User{
+		ID:            users.ID(uuid.New().String()),
+		Name:          name,
+		Email:         email,
+		EmailVerified: true,
+		CreatedAt:     &t,
+		Status:        users.StatusActive,
+	}
+
+	if err := s.userRepo.Create(newUser); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.analyticsService.IdentifyUser(ctx, newUser)
+	s.analyticsService.CaptureUser(ctx, newUser.ID, "created account")
+
+	return newUser, nil
+}
+
 func (s *UserService) GetByIDs(ctx context.Context, ids ...users.ID) ([]*users.User, error) {
 	uu, err := s.userRepo.GetByIDs(ctx, ids...)
 	if err != nil {
@@ -31,6 +31,7 @@ type WorkspaceReader interface {
 	ListByUserID(context.Context, users.ID) ([]*workspaces.Workspace, error)
 	GetByViewID(viewID string, includeArchived bool) (*workspaces.Workspace, error)
 	GetBySnapshotID(snapshots.ID) (*workspaces.Workspace, error)
+	ListByParentWorkspaceID(ctx context.Context, parentWorkspaceID string) ([]*workspaces.Workspace, error)
 }
 
 type UpdateOptions struct {
@@ -75,6 +76,9 @@ type UpdateOptions struct {
 
 	userID    users.ID
 	userIDSet bool
+
+	parentWorkspaceID    *string
+	parentWorkspaceIDSet bool
 }
 
 type UpdateOption func(*UpdateOptions)
@@ -186,3 +190,10 @@ func SetUserID(userID users.ID) UpdateOption {
 		opts.userIDSet = true
 	}
 }
+
+func SetParentWorkspaceID(parentWorkspaceID *string) UpdateOption {
+	return func(opts *UpdateOptions) {
+		opts.parentWorkspaceID = parentWorkspaceID
+		opts.parentWorkspaceIDSet = true
+	}
+}
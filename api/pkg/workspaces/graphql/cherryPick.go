@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/changes"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_sync "getsturdy.com/api/pkg/sync/service"
+)
+
+func (r *WorkspaceRootResolver) CherryPickChange(ctx context.Context, args resolvers.CherryPickChangeArgs) (resolvers.WorkspaceResolver, error) {
+	ws, err := r.workspaceService.GetByID(ctx, string(args.Input.WorkspaceID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if err := r.authService.CanWrite(ctx, ws); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	ch, err := r.changeService.GetChangeByID(ctx, changes.ID(args.Input.ChangeID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if ch.CodebaseID != ws.CodebaseID {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "The change belongs to a different codebase")
+	}
+
+	switch err := r.syncService.CherryPick(ctx, ws, ch); {
+	case errors.Is(err, service_sync.ErrChangeNotLanded):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "The change has not been landed")
+	case errors.Is(err, service_sync.ErrCherryPickConflicts):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "The change conflicts with the workspace")
+	case err != nil:
+		return nil, gqlerrors.Error(fmt.Errorf("failed to cherry-pick change: %w", err))
+	}
+
+	ws, err = r.workspaceService.GetByID(ctx, ws.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &WorkspaceResolver{w: ws, root: r}, nil
+}
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/codebases/acl"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+func (r *WorkspaceResolver) MissingCodeOwnerApprovals(ctx context.Context) ([]resolvers.CodeOwnersResolver, error) {
+	missing, err := r.root.codeOwnersService.MissingApprovals(ctx, r.w)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	res := make([]resolvers.CodeOwnersResolver, 0, len(missing))
+	for _, owners := range missing {
+		res = append(res, &codeOwnersResolver{owners: owners})
+	}
+	return res, nil
+}
+
+type codeOwnersResolver struct {
+	owners *acl.CodeOwners
+}
+
+func (r *codeOwnersResolver) ID() string {
+	return r.owners.ID
+}
+
+func (r *codeOwnersResolver) Principals() []string {
+	return identifiersToStrings(r.owners.Principals)
+}
+
+func (r *codeOwnersResolver) Resources() []string {
+	return identifiersToStrings(r.owners.Resources)
+}
+
+func identifiersToStrings(identifiers []*acl.Identifier) []string {
+	res := make([]string, 0, len(identifiers))
+	for _, i := range identifiers {
+		res = append(res, i.String())
+	}
+	return res
+}
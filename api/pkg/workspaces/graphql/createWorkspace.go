@@ -38,11 +38,31 @@ func (r *WorkspaceRootResolver) CreateWorkspace(ctx context.Context, args resolv
 		)
 	}
 
+	if args.Input.OnTopOfWorkspace != nil && (args.Input.OnTopOfChange != nil || args.Input.OnTopOfChangeWithRevert != nil) {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest,
+			"onTopOfWorkspace", "can't be set together with onTopOfChange or onTopOfChangeWithRevert",
+		)
+	}
+
 	// Create request to pass to the old REST API route handler
 	req := service.CreateWorkspaceRequest{
 		CodebaseID: codebaseID,
 		UserID:     userID,
 	}
+	if args.Input.OnTopOfWorkspace != nil {
+		parent, err := r.workspaceService.GetByID(ctx, string(*args.Input.OnTopOfWorkspace))
+		if err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		if err := r.authService.CanRead(ctx, parent); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		if parent.CodebaseID != codebaseID {
+			return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "onTopOfWorkspace", "must be in the same codebase")
+		}
+		req.ParentWorkspaceID = &parent.ID
+		req.Name = "On " + parent.NameOrFallback()
+	}
 	if args.Input.OnTopOfChange != nil || args.Input.OnTopOfChangeWithRevert != nil {
 		var id *graphql.ID
 		if args.Input.OnTopOfChange != nil {
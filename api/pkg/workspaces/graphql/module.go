@@ -6,6 +6,7 @@ import (
 	graphql_changes "getsturdy.com/api/pkg/changes/graphql"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
 	graphql_codebases "getsturdy.com/api/pkg/codebases/graphql"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
 	db_comments "getsturdy.com/api/pkg/comments/db"
 	graphql_comments "getsturdy.com/api/pkg/comments/graphql"
 	"getsturdy.com/api/pkg/di"
@@ -20,6 +21,7 @@ import (
 	db_view "getsturdy.com/api/pkg/views/db"
 	graphql_view "getsturdy.com/api/pkg/views/graphql"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	service_workspace_statuses "getsturdy.com/api/pkg/workspaces/statuses/service"
 	graphql_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/graphql"
 )
 
@@ -42,6 +44,8 @@ func Module(c *di.Container) {
 	c.Import(graphql_workspace_watchers.Module)
 	c.Import(graphql_rebase.Module)
 	c.Import(graphql_snapshots.Module)
+	c.Import(service_codeowners.Module)
+	c.Import(service_workspace_statuses.Module)
 
 	c.Register(NewResolver)
 
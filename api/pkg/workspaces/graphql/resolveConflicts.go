@@ -0,0 +1,64 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_sync "getsturdy.com/api/pkg/sync/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/merge"
+)
+
+func (r *WorkspaceRootResolver) ResolveWorkspaceConflicts(ctx context.Context, args resolvers.ResolveWorkspaceConflictsArgs) (resolvers.WorkspaceResolver, error) {
+	ws, err := r.workspaceService.GetByID(ctx, string(args.Input.WorkspaceID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if err := r.authService.CanWrite(ctx, ws); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	resolves := make([]vcs.SturdyRebaseResolve, 0, len(args.Input.Files))
+	for _, file := range args.Input.Files {
+		resolve := vcs.SturdyRebaseResolve{Path: file.Path, Version: file.Version}
+		switch file.Version {
+		case "workspace", "trunk":
+		case "custom":
+			if file.Contents == nil {
+				return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "contents are required for custom resolutions", "path", file.Path)
+			}
+			contents := []byte(*file.Contents)
+			resolve.Contents = &contents
+		case "hunks":
+			if file.Hunks == nil {
+				return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "hunks are required for hunk resolutions", "path", file.Path)
+			}
+			for _, choice := range *file.Hunks {
+				c := merge.Choice(choice)
+				if !c.Valid() {
+					return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "unknown hunk choice", "path", file.Path)
+				}
+				resolve.Hunks = append(resolve.Hunks, c)
+			}
+		default:
+			return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", "unknown version", "path", file.Path)
+		}
+		resolves = append(resolves, resolve)
+	}
+
+	switch _, err := r.syncService.ResolveWorkspace(ctx, ws, resolves); {
+	case errors.Is(err, service_sync.ErrUnresolvedConflicts), errors.Is(err, merge.ErrUnresolvedHunks):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", err.Error())
+	case err != nil:
+		return nil, gqlerrors.Error(err)
+	}
+
+	ws, err = r.workspaceService.GetByID(ctx, ws.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &WorkspaceResolver{w: ws, root: r}, nil
+}
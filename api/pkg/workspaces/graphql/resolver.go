@@ -13,6 +13,7 @@ import (
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	"getsturdy.com/api/pkg/snapshots"
+	"getsturdy.com/api/pkg/statuses"
 	"getsturdy.com/api/pkg/unidiff"
 	"getsturdy.com/api/pkg/workspaces"
 	"getsturdy.com/api/pkg/workspaces/db"
@@ -32,6 +33,10 @@ type WorkspaceResolver struct {
 	latestSnapshot     *snapshots.Snapshot
 	latestSnapshotErr  error
 	latestSnapshotOnce sync.Once
+
+	statusChecks     *statuses.Checks
+	statusChecksErr  error
+	statusChecksOnce sync.Once
 }
 
 func (r *WorkspaceResolver) ID() graphql.ID {
@@ -376,6 +381,36 @@ func (r *WorkspaceResolver) Change(ctx context.Context) (resolvers.ChangeResolve
 	})
 }
 
+func (r *WorkspaceResolver) ParentWorkspace(ctx context.Context) (resolvers.WorkspaceResolver, error) {
+	if r.w.ParentWorkspaceID == nil {
+		return nil, nil
+	}
+	allowArchived := true
+	parent, err := r.root.Workspace(ctx, resolvers.WorkspaceArgs{ID: graphql.ID(*r.w.ParentWorkspaceID), AllowArchived: &allowArchived})
+	switch {
+	case errors.Is(err, gqlerrors.ErrNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return parent, nil
+}
+
+func (r *WorkspaceResolver) ChildWorkspaces(ctx context.Context) ([]resolvers.WorkspaceResolver, error) {
+	children, err := r.root.workspaceService.ListChildren(ctx, r.w)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	res := make([]resolvers.WorkspaceResolver, 0, len(children))
+	for _, child := range children {
+		if err := r.root.authService.CanRead(ctx, child); err != nil {
+			continue
+		}
+		res = append(res, &WorkspaceResolver{w: child, root: r.root})
+	}
+	return res, nil
+}
+
 func (r *WorkspaceResolver) RebaseStatus(ctx context.Context) (resolvers.RebaseStatusResolver, error) {
 	return r.root.rebaseStatusRootResolver.InternalWorkspaceRebaseStatus(ctx, r.w.ID)
 }
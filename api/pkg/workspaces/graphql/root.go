@@ -8,6 +8,7 @@ import (
 	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_codeowners "getsturdy.com/api/pkg/codeowners/service"
 	db_comments "getsturdy.com/api/pkg/comments/db"
 	"getsturdy.com/api/pkg/events"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
@@ -17,11 +18,13 @@ import (
 	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
 	service_suggestions "getsturdy.com/api/pkg/suggestions/service"
+	service_sync "getsturdy.com/api/pkg/sync/service"
 	service_user "getsturdy.com/api/pkg/users/service"
 	db_view "getsturdy.com/api/pkg/views/db"
 	"getsturdy.com/api/pkg/workspaces"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
 	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	service_workspace_statuses "getsturdy.com/api/pkg/workspaces/statuses/service"
 	"getsturdy.com/api/vcs/executor"
 
 	"go.uber.org/zap"
@@ -51,11 +54,14 @@ type WorkspaceRootResolver struct {
 	downloadsResolver             resolvers.ContentsDownloadUrlRootResolver
 	snapshotsResolver             resolvers.SnapshotsRootResolver
 
-	suggestionsService *service_suggestions.Service
-	workspaceService   *service_workspace.Service
-	authService        *service_auth.Service
-	changeService      *service_change.Service
-	userService        service_user.Service
+	suggestionsService       *service_suggestions.Service
+	workspaceService         *service_workspace.Service
+	authService              *service_auth.Service
+	changeService            *service_change.Service
+	userService              service_user.Service
+	syncService              *service_sync.Service
+	codeOwnersService        *service_codeowners.Service
+	workspaceStatusesService *service_workspace_statuses.Service
 
 	logger           *zap.Logger
 	viewEvents       events.EventReadWriter
@@ -95,6 +101,9 @@ func NewResolver(
 	authService *service_auth.Service,
 	changeService *service_change.Service,
 	userService service_user.Service,
+	syncService *service_sync.Service,
+	codeOwnersService *service_codeowners.Service,
+	workspaceStatusesService *service_workspace_statuses.Service,
 
 	logger *zap.Logger,
 	viewEventsWriter events.EventReadWriter,
@@ -128,11 +137,14 @@ func NewResolver(
 		downloadsResolver:             downloadsResolver,
 		snapshotsResolver:             snapshotsResolver,
 
-		suggestionsService: suggestionsService,
-		workspaceService:   workspaceService,
-		authService:        authService,
-		changeService:      changeService,
-		userService:        userService,
+		suggestionsService:       suggestionsService,
+		workspaceService:         workspaceService,
+		authService:              authService,
+		changeService:            changeService,
+		userService:              userService,
+		syncService:              syncService,
+		codeOwnersService:        codeOwnersService,
+		workspaceStatusesService: workspaceStatusesService,
 
 		logger:           logger.Named("workspaceRootResolver"),
 		viewEvents:       viewEventsWriter,
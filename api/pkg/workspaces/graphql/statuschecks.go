@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/statuses"
+)
+
+func (r *WorkspaceResolver) getStatusChecks(ctx context.Context) (*statuses.Checks, error) {
+	r.statusChecksOnce.Do(func() {
+		cb, err := r.root.codebaseRepo.GetAllowArchived(r.w.CodebaseID)
+		if err != nil {
+			r.statusChecksErr = fmt.Errorf("failed to get codebase: %w", err)
+			return
+		}
+		r.statusChecks, r.statusChecksErr = r.root.workspaceStatusesService.Checks(ctx, cb, r.w)
+	})
+	return r.statusChecks, r.statusChecksErr
+}
+
+func (r *WorkspaceResolver) RequiredStatusChecks(ctx context.Context) ([]resolvers.RequiredStatusCheckResolver, error) {
+	checks, err := r.getStatusChecks(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	res := make([]resolvers.RequiredStatusCheckResolver, 0, len(checks.Required))
+	for _, check := range checks.Required {
+		res = append(res, &requiredStatusCheckResolver{root: r.root, check: check})
+	}
+	return res, nil
+}
+
+func (r *WorkspaceResolver) StatusChecksReason(ctx context.Context) (*string, error) {
+	checks, err := r.getStatusChecks(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	if checks.Passed() {
+		return nil, nil
+	}
+	return &checks.Reason, nil
+}
+
+type requiredStatusCheckResolver struct {
+	root  *WorkspaceRootResolver
+	check *statuses.Check
+}
+
+func (r *requiredStatusCheckResolver) Name() string {
+	return r.check.Pattern
+}
+
+func (r *requiredStatusCheckResolver) State() (resolvers.RequiredStatusCheckState, error) {
+	switch r.check.State {
+	case statuses.CheckStatePassed:
+		return resolvers.RequiredStatusCheckStatePassed, nil
+	case statuses.CheckStatePending:
+		return resolvers.RequiredStatusCheckStatePending, nil
+	case statuses.CheckStateFailing:
+		return resolvers.RequiredStatusCheckStateFailing, nil
+	case statuses.CheckStateMissing:
+		return resolvers.RequiredStatusCheckStateMissing, nil
+	default:
+		return resolvers.RequiredStatusCheckStateUndefined, fmt.Errorf("undefined check state: %s", r.check.State)
+	}
+}
+
+func (r *requiredStatusCheckResolver) Statuses() []resolvers.WorkspaceStatusResolver {
+	res := make([]resolvers.WorkspaceStatusResolver, 0, len(r.check.Statuses))
+	for _, status := range r.check.Statuses {
+		if workspaceStatus, ok := r.root.statusRootResolver.InternalStatus(status).ToWorkspaceStatus(); ok {
+			res = append(res, workspaceStatus)
+		}
+	}
+	return res
+}
@@ -43,6 +43,9 @@ type CreateWorkspaceRequest struct {
 
 	BaseChangeID *changes.ID
 	Revert       bool
+
+	// ParentWorkspaceID is set to stack the new workspace on top of the latest snapshot of another workspace
+	ParentWorkspaceID *string
 }
 
 type Service struct {
@@ -382,13 +385,48 @@ func (s *Service) Create(ctx context.Context, req CreateWorkspaceRequest) (*work
 		}
 	}
 
+	var parentSnapshot *snapshots.Snapshot
+	if req.ParentWorkspaceID != nil {
+		parent, err := s.workspaceReader.Get(*req.ParentWorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get parent workspace: %w", err)
+		}
+		if parent.CodebaseID != ws.CodebaseID {
+			return nil, fmt.Errorf("parent workspace does not belong to this codebase")
+		}
+		if parent.IsArchived() {
+			return nil, fmt.Errorf("parent workspace is archived")
+		}
+		if parent.LatestSnapshotID != nil {
+			parentSnapshot, err = s.snap.GetByID(ctx, *parent.LatestSnapshotID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get parent snapshot: %w", err)
+			}
+		}
+		ws.ParentWorkspaceID = &parent.ID
+	}
+
 	if err := s.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
 		// Ensure codebase status
 		if err := EnsureCodebaseStatus(repo); err != nil {
 			return err
 		}
 
-		if req.BaseChangeID != nil && baseCommitSha != "" {
+		if parentSnapshot != nil {
+			// Create workspace on top of the changes in the parent workspace
+			if err := vcs_workspace.CreateOnCommitID(repo, ws.ID, parentSnapshot.CommitSHA); err != nil {
+				return fmt.Errorf("failed to create workspace at parent snapshot: %w", err)
+			}
+		} else if req.ParentWorkspaceID != nil {
+			// The parent doesn't have any changes yet, create workspace at the base of the parent
+			parentCommitID, err := repo.BranchCommitID(*req.ParentWorkspaceID)
+			if err != nil {
+				return fmt.Errorf("failed to get parent workspace commit: %w", err)
+			}
+			if err := vcs_workspace.CreateOnCommitID(repo, ws.ID, parentCommitID); err != nil {
+				return fmt.Errorf("failed to create workspace at parent: %w", err)
+			}
+		} else if req.BaseChangeID != nil && baseCommitSha != "" {
 			// Create workspace at the change that we want to revert
 			if err := vcs_workspace.CreateOnCommitID(repo, ws.ID, baseCommitSha); err != nil {
 				return fmt.Errorf("failed to create workspace at change: %w", err)
@@ -427,6 +465,7 @@ func (s *Service) Create(ctx context.Context, req CreateWorkspaceRequest) (*work
 		analytics.CodebaseID(req.CodebaseID),
 		analytics.Property("id", ws.ID),
 		analytics.Property("at_existing_change", req.BaseChangeID != nil),
+		analytics.Property("stacked", req.ParentWorkspaceID != nil),
 		analytics.Property("name", ws.Name),
 	)
 
@@ -804,3 +843,8 @@ func (s *Service) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID,
 func (s *Service) ListByIDs(ctx context.Context, ids ...string) ([]*workspaces.Workspace, error) {
 	return s.workspaceReader.ListByIDs(ctx, ids...)
 }
+
+// ListChildren returns the workspaces that are stacked on top of the workspace.
+func (s *Service) ListChildren(ctx context.Context, ws *workspaces.Workspace) ([]*workspaces.Workspace, error) {
+	return s.workspaceReader.ListByParentWorkspaceID(ctx, ws.ID)
+}
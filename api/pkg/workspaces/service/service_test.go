@@ -116,6 +116,52 @@ func TestCreate_with_name(t *testing.T) {
 	assert.Equal(t, "test", *ws.Name)
 }
 
+func TestCreate_on_top_of_workspace(t *testing.T) {
+	tc := setup(t)
+
+	ctx := context.Background()
+
+	parent, err := tc.workspaceService.Create(ctx, service_workspace.CreateWorkspaceRequest{UserID: tc.userID, CodebaseID: tc.codebaseID})
+	assert.NoError(t, err)
+
+	vw, err := tc.viewService.Create(ctx, tc.userID, parent, nil, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tc.executorProvider.New().Write(writeFile("test.txt", []byte("test"))).ExecView(tc.codebaseID, vw.ID, "make some changes"))
+
+	parentSnapshot, err := tc.snapshotService.Snapshot(ctx, tc.codebaseID, parent.ID, snapshots.Action("testing"),
+		service_snapshots.WithOnView(vw.ID),
+		service_snapshots.WithMarkAsLatestInWorkspace(),
+	)
+	assert.NoError(t, err)
+
+	child, err := tc.workspaceService.Create(ctx, service_workspace.CreateWorkspaceRequest{
+		UserID:            tc.userID,
+		CodebaseID:        tc.codebaseID,
+		ParentWorkspaceID: &parent.ID,
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, child.ParentWorkspaceID) {
+		assert.Equal(t, parent.ID, *child.ParentWorkspaceID)
+	}
+
+	// the child is based on the changes of the parent
+	assert.NoError(t, tc.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		childCommitID, err := repo.BranchCommitID(child.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, parentSnapshot.CommitSHA, childCommitID)
+		return nil
+	}).ExecTrunk(tc.codebaseID, "verify child"))
+
+	children, err := tc.workspaceService.ListChildren(ctx, parent)
+	assert.NoError(t, err)
+	if assert.Len(t, children, 1) {
+		assert.Equal(t, child.ID, children[0].ID)
+	}
+}
+
 func TestWorkspace_SetSnapshot(t *testing.T) {
 	tc := setup(t)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"getsturdy.com/api/pkg/codebases"
 	service_snapshots "getsturdy.com/api/pkg/snapshots/service"
 	"getsturdy.com/api/pkg/statuses"
 	service_statuses "getsturdy.com/api/pkg/statuses/service"
@@ -25,34 +26,35 @@ func New(
 	}
 }
 
-func (s *Service) HealthyStatus(ctx context.Context, ws *workspaces.Workspace) (bool, error) {
-	statusList, err := s.statusesService.ListByWorkspaceID(ctx, ws.ID)
-	if err != nil {
-		return false, err
+// RequiresChecks returns true if the statuses of the workspaces in the codebase must pass before they can be landed.
+func RequiresChecks(cb *codebases.Codebase) bool {
+	return cb.RequireHealthyStatus || len(cb.RequiredStatusChecks) > 0
+}
+
+// Checks evaluates the statuses of the workspace against the required status checks of the codebase. Stale statuses
+// are ignored, so required checks that only have stale statuses are waited for.
+func (s *Service) Checks(ctx context.Context, cb *codebases.Codebase, ws *workspaces.Workspace) (*statuses.Checks, error) {
+	if !RequiresChecks(cb) {
+		return &statuses.Checks{}, nil
 	}
 
-	// no status => is unhealthy
-	if len(statusList) == 0 {
-		return false, nil
+	statusList, err := s.statusesService.ListByWorkspaceID(ctx, ws.ID)
+	if err != nil {
+		return nil, err
 	}
 
+	fresh := make([]*statuses.Status, 0, len(statusList))
 	for _, status := range statusList {
-		if status.Type != statuses.TypeHealthy {
-			return false, nil
-		}
-
 		isStale, err := s.StatusIsStaleForWorkspace(ctx, ws, status)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-
-		if isStale {
-			return false, nil
+		if !isStale {
+			fresh = append(fresh, status)
 		}
 	}
 
-	// have statuses, and all statuses are healthy
-	return true, nil
+	return statuses.EvaluateChecks(cb.RequiredStatusChecks, fresh), nil
 }
 
 func (s *Service) StatusIsStaleForWorkspace(ctx context.Context, ws *workspaces.Workspace, status *statuses.Status) (bool, error) {
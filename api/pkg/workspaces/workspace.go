@@ -44,6 +44,10 @@ type Workspace struct {
 
 	// ChangeID is the last change id that was landed from this workspace.
 	ChangeID *changes.ID `db:"change_id" json:"-"`
+
+	// ParentWorkspaceID is set if the workspace is stacked on top of another workspace.
+	// The changes of the workspace are relative to the parent, until the parent has landed.
+	ParentWorkspaceID *string `db:"parent_workspace_id" json:"-"`
 }
 
 func (w *Workspace) SetSnapshot(snapshot *snapshots.Snapshot) {
@@ -0,0 +1,22 @@
+package configuration
+
+import "time"
+
+const (
+	// LocksLocal locks repositories with in-process mutexes and file locks. It's only safe to use when a single
+	// instance of the API is accessing the repositories.
+	LocksLocal = "local"
+	// LocksPostgres locks repositories with Postgres advisory locks, and can be used when multiple instances of the API
+	// share the same repositories.
+	LocksPostgres = "postgres"
+)
+
+type Configuration struct {
+	Locks           string        `long:"locks" description:"Backend used to lock repositories" choice:"local" choice:"postgres" default:"local"`
+	LockTimeout     time.Duration `long:"lock-timeout" description:"Maximum time to wait for a repository lock, only used by the postgres backend (0 waits forever)" default:"0"`
+	LockConnections int           `long:"lock-connections" description:"Maximum number of database connections used for repository locks, only used by the postgres backend. Each held lock uses a connection" default:"64"`
+
+	TemporaryViewsMin     int           `long:"temporary-views-min" description:"Number of temporary views to keep per codebase" default:"3"`
+	TemporaryViewsMax     int           `long:"temporary-views-max" description:"Maximum number of temporary views in use per codebase, idle views above this are evicted (0 is unlimited)" default:"10"`
+	TemporaryViewsTimeout time.Duration `long:"temporary-views-timeout" description:"Maximum time to wait for a temporary view when the maximum number is in use (0 waits forever)" default:"1m"`
+}
@@ -1,17 +1,14 @@
 package executor
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"os"
-	"path"
 	"runtime/debug"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
@@ -122,23 +119,23 @@ type executor struct {
 
 	allowRebasing bool
 
-	logger           *zap.Logger
-	repoProvider     provider.RepoProvider
-	locks            *locker
-	minTmpBufferSize int
+	logger         *zap.Logger
+	repoProvider   provider.RepoProvider
+	locks          Locker
+	temporaryViews *temporaryViews
 }
 
 func newExecutor(
 	logger *zap.Logger,
 	repoProvider provider.RepoProvider,
-	locks *locker,
-	minTmpBufferSize int,
+	locks Locker,
+	temporaryViews *temporaryViews,
 ) *executor {
 	return &executor{
-		logger:           logger,
-		repoProvider:     repoProvider,
-		locks:            locks,
-		minTmpBufferSize: minTmpBufferSize,
+		logger:         logger,
+		repoProvider:   repoProvider,
+		locks:          locks,
+		temporaryViews: temporaryViews,
 	}
 }
 
@@ -246,99 +243,18 @@ func (e *executor) AssertBranchName(name string) Executor {
 var ErrIsRebasing = fmt.Errorf("unexpected git executor state, is rebasing")
 var ErrUnexpectedBranch = fmt.Errorf("unexpected git executor state, on unexpected branch")
 
-const (
-	inUsePrefix = "using-"
-	tmpPrefix   = "tmp-"
-)
-
-func filter[T any](slice []T, f func(T) bool) []T {
-	filtered := make([]T, 0, len(slice))
-	for _, s := range slice {
-		if f(s) {
-			filtered = append(filtered, s)
-		}
-	}
-	return filtered
-}
-
-func isTemporaryView(name string) bool {
-	return strings.HasPrefix(name, tmpPrefix)
-}
-
-func random[T any](slice []T) T {
-	randomIndex := rand.Intn(len(slice))
-	return slice[randomIndex]
-}
-
-func (e *executor) getTemporaryViewID(codebaseID codebases.ID) (string, error) {
-	codebasePath := path.Dir(e.repoProvider.TrunkPath(codebaseID))
-	codebaseDir, err := os.Open(codebasePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open codebase path: %w", err)
-	}
-
-	// get all existing paths
-	viewPaths, err := codebaseDir.Readdirnames(-1)
-	if err != nil {
-		return "", fmt.Errorf("failed to list views: %w", err)
-	}
-
-	// find temporary views among them
-	temporaryViews := filter(viewPaths, isTemporaryView)
-	if shouldAddNewView := len(temporaryViews) < e.minTmpBufferSize; shouldAddNewView {
-		// add a new tmp view to the pool
-		return fmt.Sprintf("%s%s%s", inUsePrefix, tmpPrefix, uuid.NewString()), nil
-	}
-
-	// re-use existing view from the pool
-	viewID := random(temporaryViews)
-	return e.temporaryViewMarkUsing(codebaseID, viewID)
-}
-
-func (e *executor) temporaryViewMarkUsing(codebaseID codebases.ID, viewID string) (string, error) {
-	if strings.HasPrefix(viewID, inUsePrefix) {
-		return "", fmt.Errorf("view already in use")
-	}
-
-	codebasePath := path.Dir(e.repoProvider.TrunkPath(codebaseID))
-	inUseID := fmt.Sprintf("%s%s", inUsePrefix, viewID)
-	if err := os.Rename(
-		path.Join(codebasePath, viewID),
-		path.Join(codebasePath, inUseID),
-	); err != nil {
-		return "", fmt.Errorf("failed to mark view as in use: %w", err)
-	}
-	return inUseID, nil
-}
-
-func (e *executor) temporaryViewMarkNotUsing(codebaseID codebases.ID, viewID string) (string, error) {
-	if !strings.HasPrefix(viewID, inUsePrefix) {
-		return viewID, nil
-	}
-
-	codebasesDir := path.Dir(e.repoProvider.TrunkPath(codebaseID))
-	notInUse := strings.TrimLeft(viewID, inUsePrefix)
-	if err := os.Rename(
-		path.Join(codebasesDir, viewID),
-		path.Join(codebasesDir, notInUse),
-	); err != nil {
-		return "", fmt.Errorf("failed to mark view as not using: %w", err)
-	}
-	return notInUse, nil
-}
-
 func (e *executor) ExecTemporaryView(codebaseID codebases.ID, actionName string) error {
 	e.allowRebasing = true
 
-	viewID, err := e.getTemporaryViewID(codebaseID)
+	viewID, err := e.temporaryViews.Acquire(context.Background(), codebaseID)
 	if err != nil {
 		return err
 	}
 
 	codebasePath := e.repoProvider.TrunkPath(codebaseID)
 	defer func() {
-		if _, err := e.temporaryViewMarkNotUsing(codebaseID, viewID); err != nil {
-			e.logger.Warn("failed to return tmp view to the pool: %w", zap.Error(err))
+		if err := e.temporaryViews.Release(codebaseID, viewID); err != nil {
+			e.logger.Warn("failed to return tmp view to the pool", zap.Error(err))
 		}
 	}()
 	return e.prepend(&executeFunc{
@@ -434,7 +350,7 @@ func (e *executor) exec(codebaseID codebases.ID, viewID *string, actionName stri
 
 	if e.writeLock {
 		lock := e.locks.Get(codebaseID, viewID)
-		if err := lock.Lock(); err != nil {
+		if err := meterLockWait(codebaseID, "write", lock.Lock); err != nil {
 			return fmt.Errorf("failed to acquire write lock: %w", err)
 		}
 		defer func() {
@@ -444,7 +360,7 @@ func (e *executor) exec(codebaseID codebases.ID, viewID *string, actionName stri
 		}()
 	} else if e.readLock {
 		lock := e.locks.Get(codebaseID, viewID)
-		if err := lock.RLock(); err != nil {
+		if err := meterLockWait(codebaseID, "read", lock.RLock); err != nil {
 			return fmt.Errorf("failed to acquire read lock: %w", err)
 		}
 		defer func() {
@@ -456,7 +372,7 @@ func (e *executor) exec(codebaseID codebases.ID, viewID *string, actionName stri
 
 	if e.inMemoryWriteLock {
 		lock := e.locks.GetInMemory(codebaseID, viewID)
-		if err := lock.Lock(); err != nil {
+		if err := meterLockWait(codebaseID, "in_memory_write", lock.Lock); err != nil {
 			return fmt.Errorf("failed to acquire in-memory write lock: %w", err)
 		}
 		defer func() {
@@ -466,7 +382,7 @@ func (e *executor) exec(codebaseID codebases.ID, viewID *string, actionName stri
 		}()
 	} else if e.inMemoryReadLock {
 		lock := e.locks.GetInMemory(codebaseID, viewID)
-		if err := lock.RLock(); err != nil {
+		if err := meterLockWait(codebaseID, "in_memory_read", lock.RLock); err != nil {
 			return fmt.Errorf("failed to acquire in-memory read lock: %w", err)
 		}
 		defer func() {
@@ -537,6 +453,38 @@ var (
 		}, []string{"action"})
 )
 
+var (
+	lockWait = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "sturdy_executor_lock_wait_millis",
+			Help: "Time spent waiting to acquire repository locks",
+			Buckets: []float64{
+				1, 2.5, 5,
+				10, 25, 50,
+				100, 250, 500,
+				1000, 2500, 5000,
+				10000, 25000, 50000,
+			},
+		}, []string{"codebase_id", "mode"})
+	lockTimeouts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sturdy_executor_lock_timeouts_total",
+			Help: "Number of times acquiring a repository lock timed out",
+		}, []string{"codebase_id", "mode"})
+)
+
+// meterLockWait acquires a lock with acquireFn, and records how long it took.
+func meterLockWait(codebaseID codebases.ID, mode string, acquireFn func() error) error {
+	t0 := time.Now()
+	err := acquireFn()
+	labels := prometheus.Labels{"codebase_id": codebaseID.String(), "mode": mode}
+	if errors.Is(err, ErrLockTimeout) {
+		lockTimeouts.With(labels).Inc()
+	}
+	lockWait.With(labels).Observe(float64(time.Since(t0).Milliseconds()))
+	return err
+}
+
 func getMeterFunc(action string) func() {
 	t0 := time.Now()
 	return func() {
@@ -1,6 +1,11 @@
 package executor
 
 import (
+	"fmt"
+
+	"getsturdy.com/api/pkg/db"
+	configuration_db "getsturdy.com/api/pkg/db/configuration"
+	"getsturdy.com/api/vcs/executor/configuration"
 	"getsturdy.com/api/vcs/provider"
 
 	"go.uber.org/zap"
@@ -11,26 +16,59 @@ type Provider interface {
 }
 
 type executorProvider struct {
-	logger           *zap.Logger
-	repoProvider     provider.RepoProvider
-	minTmpBufferSize int
+	logger         *zap.Logger
+	repoProvider   provider.RepoProvider
+	temporaryViews *temporaryViews
 
-	locks *locker
+	locks Locker
 }
 
 func NewProvider(logger *zap.Logger, repoProvider provider.RepoProvider) Provider {
 	return newProvider(logger, repoProvider, 3)
 }
 
+func FromConfiguration(
+	logger *zap.Logger,
+	repoProvider provider.RepoProvider,
+	cfg *configuration.Configuration,
+	dbCfg *configuration_db.Configuration,
+) (Provider, error) {
+	temporaryViews := newTemporaryViews(logger, repoProvider, cfg.TemporaryViewsMin, cfg.TemporaryViewsMax, cfg.TemporaryViewsTimeout)
+
+	switch cfg.Locks {
+	case "", configuration.LocksLocal:
+		return newProviderWithLocker(logger, repoProvider, temporaryViews, newLocker(repoProvider)), nil
+	case configuration.LocksPostgres:
+		if cfg.LockConnections < 1 {
+			return nil, fmt.Errorf("invalid number of lock connections: %d", cfg.LockConnections)
+		}
+		// locks are held on their own connections, use a separate pool so that they don't starve the rest of the api
+		database, err := db.SetupWithTimeout(dbCfg.URL.String(), dbCfg.ConnectTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup locks database: %w", err)
+		}
+		database.SetMaxOpenConns(cfg.LockConnections)
+		database.SetMaxIdleConns(cfg.LockConnections)
+		return newProviderWithLocker(logger, repoProvider, temporaryViews, newPostgresLocker(database, repoProvider, cfg.LockTimeout)), nil
+	default:
+		return nil, fmt.Errorf("unknown locks backend: %q", cfg.Locks)
+	}
+}
+
 func newProvider(logger *zap.Logger, repoProvider provider.RepoProvider, minTmpBufferSize int) Provider {
+	temporaryViews := newTemporaryViews(logger, repoProvider, minTmpBufferSize, 0, 0)
+	return newProviderWithLocker(logger, repoProvider, temporaryViews, newLocker(repoProvider))
+}
+
+func newProviderWithLocker(logger *zap.Logger, repoProvider provider.RepoProvider, temporaryViews *temporaryViews, locks Locker) Provider {
 	return &executorProvider{
-		logger:           logger.Named("gitExecutor"),
-		repoProvider:     repoProvider,
-		minTmpBufferSize: minTmpBufferSize,
-		locks:            newLocker(repoProvider),
+		logger:         logger.Named("gitExecutor"),
+		repoProvider:   repoProvider,
+		temporaryViews: temporaryViews,
+		locks:          locks,
 	}
 }
 
 func (p *executorProvider) New() Executor {
-	return newExecutor(p.logger, p.repoProvider, p.locks, p.minTmpBufferSize)
+	return newExecutor(p.logger, p.repoProvider, p.locks, p.temporaryViews)
 }
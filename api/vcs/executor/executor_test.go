@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -132,3 +133,43 @@ func TestExecutor_AllowRebasingState(t *testing.T) {
 	}).ExecView("cb", "vw", "tryToOpenWithAllowed")
 	assert.NoError(t, err)
 }
+
+func TestExecutor_trunkReadsWaitForWriters(t *testing.T) {
+	exec := newProvider(zap.NewNop(), testutil.TestingRepoProvider(t), 1)
+
+	codebaseID := codebases.ID("cb")
+	assert.NoError(t, exec.New().
+		AllowRebasingState().
+		Schedule(vcs_codebases.Create(codebaseID)).
+		ExecTrunk(codebaseID, "createTrunk"), "failed to create trunk")
+
+	writing, write := make(chan struct{}), make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		assert.NoError(t, exec.New().GitWrite(func(vcs.RepoGitWriter) error {
+			close(writing)
+			<-write
+			return nil
+		}).ExecTrunk(codebaseID, "write"))
+		close(written)
+	}()
+	<-writing
+
+	read := make(chan struct{})
+	go func() {
+		assert.NoError(t, exec.New().GitRead(func(vcs.RepoGitReader) error {
+			return nil
+		}).ExecTrunk(codebaseID, "read"))
+		close(read)
+	}()
+
+	select {
+	case <-read:
+		t.Fatal("read the trunk while it was being written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(write)
+	<-written
+	<-read
+}
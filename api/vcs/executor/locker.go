@@ -11,20 +11,31 @@ import (
 	"github.com/gofrs/flock"
 )
 
-type locker struct {
-	provider provider.RepoProvider
-
-	locksGuard *sync.Mutex
-	locks      map[string]lock
+// Locker hands out the locks that are used by the executor to get exclusive access to repositories.
+type Locker interface {
+	// Get returns the lock guarding the files of a repository.
+	Get(codebaseID codebases.ID, viewID *string) Lock
+	// GetInMemory returns the lock guarding the .git directory of a repository.
+	GetInMemory(codebaseID codebases.ID, viewID *string) Lock
 }
 
-type lock interface {
+// Lock is a readers-writer lock.
+type Lock interface {
 	Lock() error
 	Unlock() error
 	RLock() error
 	RUnlock() error
 }
 
+// locker is a Locker that only synchronizes access to repositories within this process, and with the mutagen
+// processes that are using the views.
+type locker struct {
+	provider provider.RepoProvider
+
+	locksGuard *sync.Mutex
+	locks      map[string]Lock
+}
+
 type mutexLock struct {
 	mu *sync.RWMutex
 }
@@ -54,7 +65,7 @@ func newLocker(provider provider.RepoProvider) *locker {
 		provider: provider,
 
 		locksGuard: &sync.Mutex{},
-		locks:      map[string]lock{},
+		locks:      map[string]Lock{},
 	}
 }
 
@@ -62,8 +73,8 @@ func newLocker(provider provider.RepoProvider) *locker {
 const lockFileName = ".git/sturdy.lock"
 
 // Returns a mutex for the given codebase and view.
-func (l *locker) Get(codebaseID codebases.ID, viewID *string) lock {
-	key := l.key(codebaseID, viewID)
+func (l *locker) Get(codebaseID codebases.ID, viewID *string) Lock {
+	key := lockKey(codebaseID, viewID)
 
 	l.locksGuard.Lock()
 	defer l.locksGuard.Unlock()
@@ -86,15 +97,15 @@ func (l *locker) Get(codebaseID codebases.ID, viewID *string) lock {
 	return lock
 }
 
-func (l *locker) key(codebaseID codebases.ID, viewID *string) string {
+func lockKey(codebaseID codebases.ID, viewID *string) string {
 	if viewID == nil {
 		return fmt.Sprintf("%s/trunk", codebaseID)
 	}
 	return fmt.Sprintf("%s/%s", codebaseID, *viewID)
 }
 
-func (l *locker) GetInMemory(codebaseID codebases.ID, viewID *string) lock {
-	key := l.key(codebaseID, viewID) + "-inmemory"
+func (l *locker) GetInMemory(codebaseID codebases.ID, viewID *string) Lock {
+	key := lockKey(codebaseID, viewID) + "-inmemory"
 
 	l.locksGuard.Lock()
 	defer l.locksGuard.Unlock()
@@ -1,6 +1,7 @@
 package executor
 
 import (
+	configuration "getsturdy.com/api/pkg/configuration/module"
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/logger"
 	"getsturdy.com/api/vcs/provider"
@@ -8,6 +9,7 @@ import (
 
 func Module(c *di.Container) {
 	c.Import(logger.Module)
+	c.Import(configuration.Module)
 	c.Import(provider.Module)
-	c.Register(NewProvider)
+	c.Register(FromConfiguration)
 }
@@ -0,0 +1,255 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/vcs/provider"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// postgresLocker is a Locker that uses Postgres advisory locks, which makes it safe to use from multiple instances of
+// the API sharing the same repositories.
+//
+// Views are additionally locked with file locks, to synchronize with the mutagen processes that are using them.
+type postgresLocker struct {
+	db      *sqlx.DB
+	timeout time.Duration
+	local   *locker
+
+	locksGuard *sync.Mutex
+	locks      map[string]*advisoryLock
+}
+
+func newPostgresLocker(db *sqlx.DB, provider provider.RepoProvider, timeout time.Duration) *postgresLocker {
+	return &postgresLocker{
+		db:      db,
+		timeout: timeout,
+		local:   newLocker(provider),
+
+		locksGuard: &sync.Mutex{},
+		locks:      map[string]*advisoryLock{},
+	}
+}
+
+func (l *postgresLocker) Get(codebaseID codebases.ID, viewID *string) Lock {
+	lock := l.advisoryLock(lockKey(codebaseID, viewID))
+	if viewID == nil {
+		return lock
+	}
+	return multiLock{lock, l.local.Get(codebaseID, viewID)}
+}
+
+func (l *postgresLocker) GetInMemory(codebaseID codebases.ID, viewID *string) Lock {
+	return l.advisoryLock(lockKey(codebaseID, viewID) + "-inmemory")
+}
+
+func (l *postgresLocker) advisoryLock(key string) *advisoryLock {
+	l.locksGuard.Lock()
+	defer l.locksGuard.Unlock()
+
+	if lock, ok := l.locks[key]; ok {
+		return lock
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	lock := &advisoryLock{
+		db:      l.db,
+		key:     int64(h.Sum64()),
+		timeout: l.timeout,
+	}
+	l.locks[key] = lock
+	return lock
+}
+
+const (
+	advisoryLockMinBackoff = 10 * time.Millisecond
+	advisoryLockMaxBackoff = 250 * time.Millisecond
+)
+
+// advisoryLock is a readers-writer lock backed by a session level Postgres advisory lock.
+//
+// A session level lock is owned by the connection that acquired it, so a connection is held for as long as the lock
+// is. To not hold connections while waiting, the lock is polled with pg_try_advisory_lock.
+type advisoryLock struct {
+	db      *sqlx.DB
+	key     int64
+	timeout time.Duration
+
+	mu      sync.Mutex
+	writer  *sql.Conn
+	readers []*sql.Conn
+}
+
+func (l *advisoryLock) Lock() error {
+	conn, err := l.acquire("pg_try_advisory_lock")
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.writer = conn
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *advisoryLock) Unlock() error {
+	l.mu.Lock()
+	conn := l.writer
+	l.writer = nil
+	l.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("unlock of unlocked lock")
+	}
+	return release(conn, "pg_advisory_unlock", l.key)
+}
+
+func (l *advisoryLock) RLock() error {
+	conn, err := l.acquire("pg_try_advisory_lock_shared")
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.readers = append(l.readers, conn)
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *advisoryLock) RUnlock() error {
+	// all shared locks on the key are equal, so it doesn't matter which one is released
+	l.mu.Lock()
+	if len(l.readers) == 0 {
+		l.mu.Unlock()
+		return fmt.Errorf("runlock of unlocked lock")
+	}
+	conn := l.readers[len(l.readers)-1]
+	l.readers = l.readers[:len(l.readers)-1]
+	l.mu.Unlock()
+
+	return release(conn, "pg_advisory_unlock_shared", l.key)
+}
+
+func (l *advisoryLock) acquire(tryLockFunc string) (*sql.Conn, error) {
+	// the pool of connections is bounded, waiting for a connection counts towards the timeout
+	ctx := context.Background()
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	backoff := advisoryLockMinBackoff
+	for {
+		conn, err := l.db.Conn(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrLockTimeout
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get connection: %w", err)
+		}
+
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT "+tryLockFunc+"($1)", l.key).Scan(&locked); err != nil {
+			discard(conn)
+			return nil, fmt.Errorf("failed to lock: %w", err)
+		}
+		if locked {
+			return conn, nil
+		}
+
+		if err := conn.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close connection: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockTimeout
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > advisoryLockMaxBackoff {
+			backoff = advisoryLockMaxBackoff
+		}
+	}
+}
+
+func release(conn *sql.Conn, unlockFunc string, key int64) error {
+	var unlocked bool
+	if err := conn.QueryRowContext(context.Background(), "SELECT "+unlockFunc+"($1)", key).Scan(&unlocked); err != nil {
+		// the lock is released when the session ends
+		discard(conn)
+		return fmt.Errorf("failed to unlock: %w", err)
+	}
+
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
+	}
+
+	if !unlocked {
+		return fmt.Errorf("lock %d was not held", key)
+	}
+	return nil
+}
+
+// discard closes the connection, instead of returning it to the pool. This makes sure that locks are not left behind
+// in the pool if the state of the connection is unknown.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+// multiLock acquires all of the locks, in order.
+type multiLock []Lock
+
+func (ml multiLock) Lock() error {
+	for i, lock := range ml {
+		if err := lock.Lock(); err != nil {
+			_ = multiLock(ml[:i]).Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (ml multiLock) Unlock() error {
+	var firstErr error
+	for i := len(ml) - 1; i >= 0; i-- {
+		if err := ml[i].Unlock(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (ml multiLock) RLock() error {
+	for i, lock := range ml {
+		if err := lock.RLock(); err != nil {
+			_ = multiLock(ml[:i]).RUnlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (ml multiLock) RUnlock() error {
+	var firstErr error
+	for i := len(ml) - 1; i >= 0; i-- {
+		if err := ml[i].RUnlock(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
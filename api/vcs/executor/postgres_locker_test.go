@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/db"
+	"getsturdy.com/api/vcs/testutil"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func testingPostgresDB(t *testing.T) *sqlx.DB {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	host := "127.0.0.1:5432"
+	if overrideHost := os.Getenv("E2E_PSQL_HOST"); overrideHost != "" {
+		host = overrideHost
+	}
+
+	database, err := db.SetupWithTimeout("postgres://mash:mash@"+host+"/mash?sslmode=disable", 5*time.Second)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+func TestPostgresLocker_exclusive(t *testing.T) {
+	database := testingPostgresDB(t)
+	repoProvider := testutil.TestingRepoProvider(t)
+
+	// two lockers, as if they were running in different instances of the api
+	first := newPostgresLocker(database, repoProvider, 100*time.Millisecond)
+	second := newPostgresLocker(database, repoProvider, 100*time.Millisecond)
+
+	codebaseID := codebases.ID(t.Name())
+
+	assert.NoError(t, first.Get(codebaseID, nil).Lock())
+
+	assert.ErrorIs(t, second.Get(codebaseID, nil).Lock(), ErrLockTimeout)
+	assert.ErrorIs(t, second.Get(codebaseID, nil).RLock(), ErrLockTimeout)
+
+	// in-memory locks are independent
+	assert.NoError(t, second.GetInMemory(codebaseID, nil).Lock())
+	assert.NoError(t, second.GetInMemory(codebaseID, nil).Unlock())
+
+	assert.NoError(t, first.Get(codebaseID, nil).Unlock())
+
+	assert.NoError(t, second.Get(codebaseID, nil).Lock())
+	assert.NoError(t, second.Get(codebaseID, nil).Unlock())
+}
+
+func TestPostgresLocker_shared(t *testing.T) {
+	database := testingPostgresDB(t)
+	repoProvider := testutil.TestingRepoProvider(t)
+
+	first := newPostgresLocker(database, repoProvider, 100*time.Millisecond)
+	second := newPostgresLocker(database, repoProvider, 100*time.Millisecond)
+
+	codebaseID := codebases.ID(t.Name())
+
+	assert.NoError(t, first.Get(codebaseID, nil).RLock())
+	assert.NoError(t, first.Get(codebaseID, nil).RLock())
+	assert.NoError(t, second.Get(codebaseID, nil).RLock())
+
+	assert.ErrorIs(t, second.Get(codebaseID, nil).Lock(), ErrLockTimeout)
+
+	assert.NoError(t, first.Get(codebaseID, nil).RUnlock())
+	assert.NoError(t, first.Get(codebaseID, nil).RUnlock())
+	assert.NoError(t, second.Get(codebaseID, nil).RUnlock())
+
+	assert.NoError(t, second.Get(codebaseID, nil).Lock())
+	assert.NoError(t, second.Get(codebaseID, nil).Unlock())
+}
+
+func TestPostgresLocker_waits(t *testing.T) {
+	database := testingPostgresDB(t)
+	repoProvider := testutil.TestingRepoProvider(t)
+
+	first := newPostgresLocker(database, repoProvider, 0)
+	second := newPostgresLocker(database, repoProvider, 0)
+
+	codebaseID := codebases.ID(t.Name())
+
+	assert.NoError(t, first.Get(codebaseID, nil).Lock())
+
+	locked := make(chan error)
+	go func() {
+		locked <- second.Get(codebaseID, nil).Lock()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock acquired while held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.NoError(t, first.Get(codebaseID, nil).Unlock())
+	assert.NoError(t, <-locked)
+	assert.NoError(t, second.Get(codebaseID, nil).Unlock())
+}
+
+func TestPostgresLocker_connectionsInUse(t *testing.T) {
+	database := testingPostgresDB(t)
+	database.SetMaxOpenConns(1)
+	repoProvider := testutil.TestingRepoProvider(t)
+
+	locker := newPostgresLocker(database, repoProvider, 100*time.Millisecond)
+	first, second := codebases.ID(t.Name()+"-first"), codebases.ID(t.Name()+"-second")
+
+	assert.NoError(t, locker.Get(first, nil).Lock())
+
+	// the only connection is held by the first lock, the second one times out waiting for it
+	assert.ErrorIs(t, locker.Get(second, nil).Lock(), ErrLockTimeout)
+
+	assert.NoError(t, locker.Get(first, nil).Unlock())
+
+	assert.NoError(t, locker.Get(second, nil).Lock())
+	assert.NoError(t, locker.Get(second, nil).Unlock())
+}
@@ -0,0 +1,303 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/provider"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	inUsePrefix = "using-"
+	tmpPrefix   = "tmp-"
+)
+
+var (
+	temporaryViewsGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sturdy_executor_temporary_views",
+			Help: "Number of temporary views per codebase, by state",
+		}, []string{"codebase_id", "state"})
+	temporaryViewWait = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "sturdy_executor_temporary_view_wait_millis",
+			Help: "Time spent waiting for a temporary view to become available",
+			Buckets: []float64{
+				1, 2.5, 5,
+				10, 25, 50,
+				100, 250, 500,
+				1000, 2500, 5000,
+				10000, 25000, 50000,
+			},
+		}, []string{"codebase_id"})
+)
+
+// temporaryViews is a pool of temporary views per codebase.
+//
+// Temporary views are stored next to the other views of the codebase, and the name of the directory is used to keep
+// track of them: idle views are named tmp-<id>, and views that are in use are named using-tmp-<id>. Renaming is atomic,
+// so the views can be shared with other processes using the same repositories.
+//
+// At most maxSize views per codebase are used at the same time by this process, if all of them are busy, Acquire waits
+// for one to be released, for at most timeout. At least minSize views per codebase are kept, they are created in the background once the
+// first view of the codebase has been released. Idle views exceeding maxSize are evicted.
+//
+// A maxSize of 0 means that there is no limit, and a timeout of 0 waits forever.
+type temporaryViews struct {
+	logger       *zap.Logger
+	repoProvider provider.RepoProvider
+	minSize      int
+	maxSize      int
+	timeout      time.Duration
+
+	guard  sync.Mutex
+	pools  map[codebases.ID]chan struct{}
+	warmed map[codebases.ID]bool
+}
+
+var ErrTemporaryViewTimeout = errors.New("timed out waiting for a temporary view")
+
+func newTemporaryViews(logger *zap.Logger, repoProvider provider.RepoProvider, minSize, maxSize int, timeout time.Duration) *temporaryViews {
+	if maxSize > 0 && maxSize < minSize {
+		maxSize = minSize
+	}
+	return &temporaryViews{
+		logger:       logger,
+		repoProvider: repoProvider,
+		minSize:      minSize,
+		maxSize:      maxSize,
+		timeout:      timeout,
+		pools:        map[codebases.ID]chan struct{}{},
+		warmed:       map[codebases.ID]bool{},
+	}
+}
+
+// slots returns a semaphore limiting the number of views in use for the codebase, or nil if there is no limit.
+func (tv *temporaryViews) slots(codebaseID codebases.ID) chan struct{} {
+	if tv.maxSize <= 0 {
+		return nil
+	}
+
+	tv.guard.Lock()
+	defer tv.guard.Unlock()
+
+	if slots, ok := tv.pools[codebaseID]; ok {
+		return slots
+	}
+	slots := make(chan struct{}, tv.maxSize)
+	tv.pools[codebaseID] = slots
+	return slots
+}
+
+// Acquire returns the id of a temporary view that is marked as in use. The view might not exist yet, in which case it's
+// up to the caller to create it.
+//
+// If all views are in use, it waits until one is released, the timeout is reached, or ctx is done. On timeout,
+// ErrTemporaryViewTimeout is returned.
+func (tv *temporaryViews) Acquire(ctx context.Context, codebaseID codebases.ID) (string, error) {
+	if slots := tv.slots(codebaseID); slots != nil {
+		var deadline <-chan time.Time
+		if tv.timeout > 0 {
+			timer := time.NewTimer(tv.timeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+
+		t0 := time.Now()
+		select {
+		case slots <- struct{}{}:
+		case <-deadline:
+			return "", ErrTemporaryViewTimeout
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		temporaryViewWait.With(prometheus.Labels{"codebase_id": codebaseID.String()}).Observe(float64(time.Since(t0).Milliseconds()))
+	}
+
+	viewID, err := tv.acquire(codebaseID)
+	if err != nil {
+		tv.releaseSlot(codebaseID)
+		return "", err
+	}
+	return viewID, nil
+}
+
+func (tv *temporaryViews) acquire(codebaseID codebases.ID) (string, error) {
+	idle, total, err := tv.list(codebaseID)
+	if err != nil {
+		return "", err
+	}
+	defer tv.updateGauges(codebaseID)
+
+	if total < tv.minSize {
+		// add a new view to the pool
+		return newTemporaryViewID(), nil
+	}
+
+	// re-use an existing view from the pool, another process might be faster to pick the same view, so try them all
+	rand.Shuffle(len(idle), func(i, j int) { idle[i], idle[j] = idle[j], idle[i] })
+	for _, viewID := range idle {
+		if inUseID, err := tv.markUsing(codebaseID, viewID); err == nil {
+			return inUseID, nil
+		}
+	}
+
+	// all views are in use
+	return newTemporaryViewID(), nil
+}
+
+// Release marks a view acquired with Acquire as not in use, and evicts idle views that exceed the size of the pool.
+func (tv *temporaryViews) Release(codebaseID codebases.ID, viewID string) error {
+	defer tv.releaseSlot(codebaseID)
+	defer tv.updateGauges(codebaseID)
+
+	if _, err := tv.markNotUsing(codebaseID, viewID); err != nil {
+		return err
+	}
+
+	tv.prewarm(codebaseID)
+
+	return tv.evict(codebaseID)
+}
+
+func (tv *temporaryViews) releaseSlot(codebaseID codebases.ID) {
+	if slots := tv.slots(codebaseID); slots != nil {
+		<-slots
+	}
+}
+
+func (tv *temporaryViews) evict(codebaseID codebases.ID) error {
+	if tv.maxSize <= 0 {
+		return nil
+	}
+
+	idle, _, err := tv.list(codebaseID)
+	if err != nil {
+		return err
+	}
+
+	for i := tv.maxSize; i < len(idle); i++ {
+		// take ownership of the view before removing it, so that no one else starts using it
+		inUseID, err := tv.markUsing(codebaseID, idle[i])
+		if err != nil {
+			continue
+		}
+		if err := os.RemoveAll(tv.repoProvider.ViewPath(codebaseID, inUseID)); err != nil {
+			return fmt.Errorf("failed to evict view: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// prewarm creates views in the background, until the codebase has at least minSize views. It runs once per codebase
+// and process, after the first view has been released.
+func (tv *temporaryViews) prewarm(codebaseID codebases.ID) {
+	tv.guard.Lock()
+	defer tv.guard.Unlock()
+	if tv.warmed[codebaseID] {
+		return
+	}
+	tv.warmed[codebaseID] = true
+
+	go func() {
+		_, total, err := tv.list(codebaseID)
+		if err != nil {
+			tv.logger.Warn("failed to list temporary views", zap.Error(err))
+			return
+		}
+
+		for i := total; i < tv.minSize; i++ {
+			viewID := newTemporaryViewID()
+			if _, err := vcs.CloneRepo(tv.repoProvider.TrunkPath(codebaseID), tv.repoProvider.ViewPath(codebaseID, viewID)); err != nil {
+				tv.logger.Warn("failed to prewarm temporary view", zap.Stringer("codebase_id", codebaseID), zap.Error(err))
+				_ = os.RemoveAll(tv.repoProvider.ViewPath(codebaseID, viewID))
+				return
+			}
+			if _, err := tv.markNotUsing(codebaseID, viewID); err != nil {
+				tv.logger.Warn("failed to prewarm temporary view", zap.Stringer("codebase_id", codebaseID), zap.Error(err))
+				return
+			}
+		}
+
+		tv.updateGauges(codebaseID)
+	}()
+}
+
+// list returns the idle temporary views of the codebase, and the total number of temporary views.
+func (tv *temporaryViews) list(codebaseID codebases.ID) ([]string, int, error) {
+	entries, err := os.ReadDir(path.Dir(tv.repoProvider.TrunkPath(codebaseID)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	var idle []string
+	var total int
+	for _, entry := range entries {
+		switch {
+		case strings.HasPrefix(entry.Name(), tmpPrefix):
+			idle = append(idle, entry.Name())
+			total++
+		case strings.HasPrefix(entry.Name(), inUsePrefix+tmpPrefix):
+			total++
+		}
+	}
+	return idle, total, nil
+}
+
+func (tv *temporaryViews) updateGauges(codebaseID codebases.ID) {
+	idle, total, err := tv.list(codebaseID)
+	if err != nil {
+		return
+	}
+	temporaryViewsGauge.With(prometheus.Labels{"codebase_id": codebaseID.String(), "state": "idle"}).Set(float64(len(idle)))
+	temporaryViewsGauge.With(prometheus.Labels{"codebase_id": codebaseID.String(), "state": "in_use"}).Set(float64(total - len(idle)))
+}
+
+func newTemporaryViewID() string {
+	return fmt.Sprintf("%s%s%s", inUsePrefix, tmpPrefix, uuid.NewString())
+}
+
+func (tv *temporaryViews) markUsing(codebaseID codebases.ID, viewID string) (string, error) {
+	if strings.HasPrefix(viewID, inUsePrefix) {
+		return "", fmt.Errorf("view already in use")
+	}
+
+	inUseID := inUsePrefix + viewID
+	if err := os.Rename(
+		tv.repoProvider.ViewPath(codebaseID, viewID),
+		tv.repoProvider.ViewPath(codebaseID, inUseID),
+	); err != nil {
+		return "", fmt.Errorf("failed to mark view as in use: %w", err)
+	}
+	return inUseID, nil
+}
+
+func (tv *temporaryViews) markNotUsing(codebaseID codebases.ID, viewID string) (string, error) {
+	if !strings.HasPrefix(viewID, inUsePrefix) {
+		return viewID, nil
+	}
+
+	notInUse := strings.TrimPrefix(viewID, inUsePrefix)
+	if err := os.Rename(
+		tv.repoProvider.ViewPath(codebaseID, viewID),
+		tv.repoProvider.ViewPath(codebaseID, notInUse),
+	); err != nil {
+		return "", fmt.Errorf("failed to mark view as not using: %w", err)
+	}
+	return notInUse, nil
+}
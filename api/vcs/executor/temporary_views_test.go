@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/vcs/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestTemporaryViews_evicts(t *testing.T) {
+	repoProvider := testutil.TestingRepoProvider(t)
+	views := newTemporaryViews(zap.NewNop(), repoProvider, 0, 2, 0)
+
+	codebaseID := codebases.ID("cb")
+	for _, viewID := range []string{"tmp-1", "tmp-2", "tmp-3"} {
+		assert.NoError(t, os.MkdirAll(repoProvider.ViewPath(codebaseID, viewID), 0o755))
+	}
+
+	viewID, err := views.Acquire(context.Background(), codebaseID)
+	assert.NoError(t, err)
+	assert.NoError(t, views.Release(codebaseID, viewID))
+
+	idle, total, err := views.list(codebaseID)
+	assert.NoError(t, err)
+	assert.Len(t, idle, 2)
+	assert.Equal(t, 2, total)
+}
+
+func TestTemporaryViews_bounded(t *testing.T) {
+	repoProvider := testutil.TestingRepoProvider(t)
+	views := newTemporaryViews(zap.NewNop(), repoProvider, 0, 1, 0)
+
+	codebaseID := codebases.ID("cb")
+	assert.NoError(t, os.MkdirAll(repoProvider.ViewPath(codebaseID, "tmp-1"), 0o755))
+
+	first, err := views.Acquire(context.Background(), codebaseID)
+	assert.NoError(t, err)
+	assert.Equal(t, "using-tmp-1", first)
+
+	acquired := make(chan string)
+	go func() {
+		second, err := views.Acquire(context.Background(), codebaseID)
+		assert.NoError(t, err)
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more views than the size of the pool")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, views.Release(codebaseID, first))
+	second := <-acquired
+	assert.Equal(t, "using-tmp-1", second, "expected the released view to be reused")
+	assert.NoError(t, views.Release(codebaseID, second))
+}
+
+func TestTemporaryViews_timeout(t *testing.T) {
+	repoProvider := testutil.TestingRepoProvider(t)
+	views := newTemporaryViews(zap.NewNop(), repoProvider, 0, 1, 50*time.Millisecond)
+
+	codebaseID := codebases.ID("cb")
+	first, err := views.Acquire(context.Background(), codebaseID)
+	assert.NoError(t, err)
+
+	_, err = views.Acquire(context.Background(), codebaseID)
+	assert.ErrorIs(t, err, ErrTemporaryViewTimeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = views.Acquire(ctx, codebaseID)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the views that timed out have not taken a slot
+	assert.NoError(t, views.Release(codebaseID, first))
+	second, err := views.Acquire(context.Background(), codebaseID)
+	assert.NoError(t, err)
+	assert.NoError(t, views.Release(codebaseID, second))
+}
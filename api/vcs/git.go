@@ -566,6 +566,40 @@ func (r *repository) CreateNewCommitBasedOnCommit(newBranchName string, existing
 	return newCommit.String(), nil
 }
 
+// CreateNewCommitBasedOnCommitWithParent creates a new commit using the tree of existingCommitID, with parentCommitID
+// as the only parent.
+func (r *repository) CreateNewCommitBasedOnCommitWithParent(newBranchName string, existingCommitID, parentCommitID string, signature git.Signature, message string) (string, error) {
+	defer getMeterFunc("CreateNewCommitBasedOnCommitWithParent")()
+
+	// delete branch if already exists
+	_ = r.DeleteBranch(newBranchName)
+
+	existing, err := r.Commit(existingCommitID)
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup existing commit: %w", err)
+	}
+	defer existing.Free()
+
+	parent, err := r.Commit(parentCommitID)
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup parent commit: %w", err)
+	}
+	defer parent.Free()
+
+	tree, err := existing.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+	defer tree.Free()
+
+	newCommit, err := r.r.CreateCommit("refs/heads/"+newBranchName, &signature, &signature, message, tree, parent)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	return newCommit.String(), nil
+}
+
 func (r *repository) Push(logger *zap.Logger, branchName string) error {
 	defer getMeterFunc("Push")()
 
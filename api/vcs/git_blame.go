@@ -0,0 +1,51 @@
+package vcs
+
+import (
+	"fmt"
+
+	git "github.com/libgit2/git2go/v33"
+)
+
+// BlameHunk is a range of lines in a file, that were last changed in the same commit.
+type BlameHunk struct {
+	// StartLine is the first line of the hunk, starting at 1.
+	StartLine int
+	Lines     int
+	CommitID  string
+}
+
+// BlameFile returns the commits that last changed each of the lines in the file, as it looks at commitID.
+func (r *repository) BlameFile(commitID, filePath string) ([]BlameHunk, error) {
+	defer getMeterFunc("BlameFile")()
+	oid, err := git.NewOid(commitID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := git.DefaultBlameOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default blame options: %w", err)
+	}
+	opts.NewestCommit = oid
+
+	blame, err := r.r.BlameFile(filePath, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file: %w", err)
+	}
+	defer blame.Free()
+
+	hunks := make([]BlameHunk, 0, blame.HunkCount())
+	for i := 0; i < blame.HunkCount(); i++ {
+		hunk, err := blame.HunkByIndex(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blame hunk: %w", err)
+		}
+		hunks = append(hunks, BlameHunk{
+			StartLine: int(hunk.FinalStartLineNumber),
+			Lines:     int(hunk.LinesInHunk),
+			CommitID:  hunk.FinalCommitId.String(),
+		})
+	}
+
+	return hunks, nil
+}
@@ -0,0 +1,47 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlameFile(t *testing.T) {
+	tmpBase := t.TempDir()
+
+	pathBase := tmpBase + "base"
+	clientA := tmpBase + "client-a"
+	_, err := CreateBareRepoWithRootCommit(pathBase)
+	if err != nil {
+		panic(err)
+	}
+	repoA, err := CloneRepo(pathBase, clientA)
+	if err != nil {
+		panic(err)
+	}
+
+	err = ioutil.WriteFile(path.Join(clientA, "a.txt"), []byte("a\nb\nc\n"), 0o666)
+	assert.NoError(t, err)
+	firstCommitID, err := repoA.AddAndCommit("first")
+	assert.NoError(t, err)
+
+	err = ioutil.WriteFile(path.Join(clientA, "a.txt"), []byte("a\nB\nc\nd\n"), 0o666)
+	assert.NoError(t, err)
+	secondCommitID, err := repoA.AddAndCommit("second")
+	assert.NoError(t, err)
+
+	hunks, err := repoA.BlameFile(secondCommitID, "a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []BlameHunk{
+		{StartLine: 1, Lines: 1, CommitID: firstCommitID},
+		{StartLine: 2, Lines: 1, CommitID: secondCommitID},
+		{StartLine: 3, Lines: 1, CommitID: firstCommitID},
+		{StartLine: 4, Lines: 1, CommitID: secondCommitID},
+	}, hunks)
+
+	hunks, err = repoA.BlameFile(firstCommitID, "a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []BlameHunk{{StartLine: 1, Lines: 3, CommitID: firstCommitID}}, hunks)
+}
@@ -0,0 +1,41 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCherryPickOnto_conflicts(t *testing.T) {
+	tmpBase := t.TempDir()
+
+	pathBase := tmpBase + "base"
+	clientA := tmpBase + "client-a"
+	_, err := CreateBareRepoWithRootCommit(pathBase)
+	require.NoError(t, err)
+	repoA, err := CloneRepo(pathBase, clientA)
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(clientA, "a.txt"), []byte("a\nb\nc\n"), 0o666))
+	require.NoError(t, ioutil.WriteFile(path.Join(clientA, "b.txt"), []byte("b\n"), 0o666))
+	baseCommitID, err := repoA.AddAndCommit("base")
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path.Join(clientA, "a.txt"), []byte("a\npicked\nc\n"), 0o666))
+	require.NoError(t, ioutil.WriteFile(path.Join(clientA, "b.txt"), []byte("b\nb\n"), 0o666))
+	pickedCommitID, err := repoA.AddAndCommit("picked")
+	require.NoError(t, err)
+
+	require.NoError(t, repoA.CreateAndCheckoutBranchAtCommit(baseCommitID, "onto"))
+	require.NoError(t, ioutil.WriteFile(path.Join(clientA, "a.txt"), []byte("a\nonto\nc\n"), 0o666))
+	ontoCommitID, err := repoA.AddAndCommit("onto")
+	require.NoError(t, err)
+
+	_, conflicted, conflictingFiles, err := repoA.CherryPickOnto(pickedCommitID, ontoCommitID)
+	require.NoError(t, err)
+	assert.True(t, conflicted)
+	assert.Equal(t, []string{"a.txt"}, conflictingFiles)
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"getsturdy.com/api/vcs/merge"
+
 	git "github.com/libgit2/git2go/v33"
 )
 
@@ -63,6 +65,14 @@ func (r *repository) OpenRebase() (*SturdyRebase, error) {
 type SturdyRebaseResolve struct {
 	Path    string
 	Version string
+
+	// Contents is used together with the "custom" version, to resolve the conflict with the given contents instead of
+	// the contents of the file on disk.
+	Contents *[]byte
+
+	// Hunks is used together with the "hunks" version, it contains one choice per conflicting hunk of the file, see
+	// ConflictHunks.
+	Hunks []merge.Choice
 }
 
 func (rebase *SturdyRebase) ResolveFiles(resolves []SturdyRebaseResolve) error {
@@ -73,7 +83,7 @@ func (rebase *SturdyRebase) ResolveFiles(resolves []SturdyRebaseResolve) error {
 	defer idx.Free()
 
 	for _, resolve := range resolves {
-		err := rebase.resolveFile(idx, resolve.Path, resolve.Version)
+		err := rebase.resolveFile(idx, resolve)
 		if err != nil {
 			return err
 		}
@@ -87,7 +97,8 @@ func (rebase *SturdyRebase) ResolveFiles(resolves []SturdyRebaseResolve) error {
 	return nil
 }
 
-func (rebase *SturdyRebase) resolveFile(idx *git.Index, filePath string, version string) error {
+func (rebase *SturdyRebase) resolveFile(idx *git.Index, resolve SturdyRebaseResolve) error {
+	filePath := resolve.Path
 	conflict, err := idx.Conflict(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to get conflict: %w", err)
@@ -95,8 +106,13 @@ func (rebase *SturdyRebase) resolveFile(idx *git.Index, filePath string, version
 
 	// The perspective when rebasing is from the new bases pov
 	var use *git.IndexEntry
-	switch version {
+	switch resolve.Version {
 	case "custom":
+		if resolve.Contents != nil {
+			if err := rebase.writeResolution(filePath, *resolve.Contents, conflict); err != nil {
+				return err
+			}
+		}
 		// Add the file as is
 		err = idx.RemoveConflict(filePath)
 		if err != nil {
@@ -107,12 +123,31 @@ func (rebase *SturdyRebase) resolveFile(idx *git.Index, filePath string, version
 			return fmt.Errorf("failed to add resolved file: %w", err)
 		}
 		return nil
+	case "hunks":
+		base, workspace, trunk, err := rebase.conflictContents(conflict)
+		if err != nil {
+			return err
+		}
+		merged, err := merge.Resolve(base, workspace, trunk, resolve.Hunks)
+		if err != nil {
+			return fmt.Errorf("failed to resolve hunks of %s: %w", filePath, err)
+		}
+		if err := rebase.writeResolution(filePath, merged, conflict); err != nil {
+			return err
+		}
+		if err := idx.RemoveConflict(filePath); err != nil {
+			return fmt.Errorf("failed to remove conflict from index: %w", err)
+		}
+		if err := idx.AddByPath(filePath); err != nil {
+			return fmt.Errorf("failed to add resolved file: %w", err)
+		}
+		return nil
 	case "workspace":
 		use = conflict.Their
 	case "trunk":
 		use = conflict.Our
 	default:
-		return fmt.Errorf("unknown version: %s", version)
+		return fmt.Errorf("unknown version: %s", resolve.Version)
 	}
 
 	fullFilePath := path.Join(rebase.repo.path, filePath)
@@ -153,6 +188,82 @@ func (rebase *SturdyRebase) resolveFile(idx *git.Index, filePath string, version
 	return nil
 }
 
+// writeResolution writes the resolved contents of a conflicting file to disk
+func (rebase *SturdyRebase) writeResolution(filePath string, contents []byte, conflict git.IndexConflict) error {
+	mode := os.FileMode(0o644)
+	if entry := firstIndexEntry(conflict.Their, conflict.Our, conflict.Ancestor); entry != nil && entry.Mode == git.FilemodeBlobExecutable {
+		mode = 0o755
+	}
+
+	fullFilePath := path.Join(rebase.repo.path, filePath)
+	if err := os.MkdirAll(path.Dir(fullFilePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := ioutil.WriteFile(fullFilePath, contents, mode); err != nil {
+		return fmt.Errorf("failed to write resolution: %w", err)
+	}
+	return nil
+}
+
+func firstIndexEntry(entries ...*git.IndexEntry) *git.IndexEntry {
+	for _, entry := range entries {
+		if entry != nil {
+			return entry
+		}
+	}
+	return nil
+}
+
+// conflictContents returns the contents of the common ancestor, the workspace and the trunk versions of a conflicting
+// file. A version is empty if the file doesn't exist in it.
+func (rebase *SturdyRebase) conflictContents(conflict git.IndexConflict) (base, workspace, trunk []byte, err error) {
+	if base, err = rebase.repo.indexEntryContents(conflict.Ancestor); err != nil {
+		return nil, nil, nil, err
+	}
+	if workspace, err = rebase.repo.indexEntryContents(conflict.Their); err != nil {
+		return nil, nil, nil, err
+	}
+	if trunk, err = rebase.repo.indexEntryContents(conflict.Our); err != nil {
+		return nil, nil, nil, err
+	}
+	return base, workspace, trunk, nil
+}
+
+// indexEntryContents returns the contents of the entry, or nil if the entry is nil (the file does not exist)
+func (r *repository) indexEntryContents(entry *git.IndexEntry) ([]byte, error) {
+	if entry == nil {
+		return nil, nil
+	}
+	blb, err := r.r.LookupBlob(entry.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+	defer blb.Free()
+	return blb.Contents(), nil
+}
+
+// ConflictHunks returns the hunks of the file that have been changed in different ways in the workspace and on the
+// trunk.
+func (rebase *SturdyRebase) ConflictHunks(filePath string) ([]merge.Hunk, error) {
+	idx, err := rebase.repo.r.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index: %w", err)
+	}
+	defer idx.Free()
+
+	conflict, err := idx.Conflict(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflict: %w", err)
+	}
+
+	base, workspace, trunk, err := rebase.conflictContents(conflict)
+	if err != nil {
+		return nil, err
+	}
+
+	return merge.Conflicts(base, workspace, trunk), nil
+}
+
 func (rebase *SturdyRebase) ConflictingFiles() ([]string, error) {
 	idx, err := rebase.repo.r.Index()
 	if err != nil {
@@ -301,6 +412,14 @@ func (rebase *SturdyRebase) Continue() (conflicts bool, rebasedCommits []Rebased
 	return false, rebasedCommits, nil
 }
 
+// Abort the rebase, and reset the repository to the state it was in before the rebase started
+func (rebase *SturdyRebase) Abort() error {
+	if err := rebase.gitRebase.Abort(); err != nil {
+		return fmt.Errorf("failed to abort rebase: %w", err)
+	}
+	return nil
+}
+
 func (rebase *SturdyRebase) LastCompletedCommit() string {
 	return rebase.lastCompletedCommit
 }
@@ -357,12 +476,12 @@ func (rebase *SturdyRebase) ConflictDiff(filePath string) (ConflictDiffs, error)
 
 	var diffs ConflictDiffs
 
-	diffs.WorkspacePatch, err = rebase.patchBetweenIndexEntries(conflict.Ancestor, conflict.Their)
+	diffs.WorkspacePatch, err = rebase.repo.patchBetweenIndexEntries(conflict.Ancestor, conflict.Their)
 	if err != nil {
 		return ConflictDiffs{}, fmt.Errorf("failed to create workspace patch: %w", err)
 	}
 
-	diffs.TrunkPatch, err = rebase.patchBetweenIndexEntries(conflict.Ancestor, conflict.Our)
+	diffs.TrunkPatch, err = rebase.repo.patchBetweenIndexEntries(conflict.Ancestor, conflict.Our)
 	if err != nil {
 		return ConflictDiffs{}, fmt.Errorf("failed to create trunk patch: %w", err)
 	}
@@ -370,7 +489,7 @@ func (rebase *SturdyRebase) ConflictDiff(filePath string) (ConflictDiffs, error)
 	return diffs, nil
 }
 
-func (rebase *SturdyRebase) patchBetweenIndexEntries(entryA, entryB *git.IndexEntry) (string, error) {
+func (r *repository) patchBetweenIndexEntries(entryA, entryB *git.IndexEntry) (string, error) {
 	var blobA, blobB *git.Blob
 	var blobApath, blobBpath string
 	var err error
@@ -379,7 +498,7 @@ func (rebase *SturdyRebase) patchBetweenIndexEntries(entryA, entryB *git.IndexEn
 
 	if entryA != nil {
 		blobApath = entryA.Path
-		blobA, err = rebase.repo.r.LookupBlob(entryA.Id)
+		blobA, err = r.r.LookupBlob(entryA.Id)
 		if err != nil {
 			return "", fmt.Errorf("failed to get blob: %w", err)
 		}
@@ -388,7 +507,7 @@ func (rebase *SturdyRebase) patchBetweenIndexEntries(entryA, entryB *git.IndexEn
 
 	if entryB != nil {
 		blobBpath = entryB.Path
-		blobB, err = rebase.repo.r.LookupBlob(entryB.Id)
+		blobB, err = r.r.LookupBlob(entryB.Id)
 		if err != nil {
 			return "", fmt.Errorf("failed to get blob: %w", err)
 		}
@@ -0,0 +1,64 @@
+package vcs
+
+import (
+	"fmt"
+
+	git "github.com/libgit2/git2go/v33"
+)
+
+// References returns the commit ids of all direct references that start with prefix, keyed by the full
+// reference name. Symbolic references are skipped.
+func (r *repository) References(prefix string) (map[string]string, error) {
+	defer getMeterFunc("References")()
+
+	iter, err := r.r.NewReferenceIteratorGlob(prefix + "*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reference iterator: %w", err)
+	}
+	defer iter.Free()
+
+	refs := map[string]string{}
+	for {
+		ref, err := iter.Next()
+		if git.IsErrorCode(err, git.ErrorCodeIterOver) {
+			return refs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to iterate references: %w", err)
+		}
+
+		if ref.Type() == git.ReferenceOid {
+			refs[ref.Name()] = ref.Target().String()
+		}
+		ref.Free()
+	}
+}
+
+func (r *repository) DeleteRef(name string) error {
+	defer getMeterFunc("DeleteRef")()
+
+	ref, err := r.r.References.Lookup(name)
+	switch {
+	case err == nil:
+	case isGitNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("failed to find reference %s: %w", name, err)
+	}
+	defer ref.Free()
+
+	if err := ref.Delete(); err != nil {
+		return fmt.Errorf("failed to delete reference %s: %w", name, err)
+	}
+	return nil
+}
+
+func (r *repository) CreateSymbolicRef(name, target string) error {
+	defer getMeterFunc("CreateSymbolicRef")()
+
+	ref, err := r.r.References.CreateSymbolic(name, target, true, "create-symbolic-ref-"+name)
+	if err != nil {
+		return fmt.Errorf("failed to create symbolic reference %s: %w", name, err)
+	}
+	ref.Free()
+	return nil
+}
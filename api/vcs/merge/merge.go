@@ -0,0 +1,222 @@
+// Package merge implements a line based three-way merge, that is used to resolve conflicts hunk by hunk.
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Choice is the resolution of a single conflicting hunk.
+//
+// The choices are named after the sides of the conflict, and not "ours" and "theirs", as git swaps their meaning when
+// rebasing.
+type Choice string
+
+const (
+	// ChoiceWorkspace uses the changes made in the workspace
+	ChoiceWorkspace Choice = "workspace"
+	// ChoiceTrunk uses the changes made on the trunk
+	ChoiceTrunk Choice = "trunk"
+	// ChoiceBoth uses the changes made in the workspace, followed by the changes made on the trunk
+	ChoiceBoth Choice = "both"
+)
+
+// Valid returns true if the choice is one of the known choices.
+func (c Choice) Valid() bool {
+	switch c {
+	case ChoiceWorkspace, ChoiceTrunk, ChoiceBoth:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	ErrUnresolvedHunks = errors.New("number of choices does not match the number of conflicting hunks")
+	ErrUnknownChoice   = errors.New("unknown choice")
+)
+
+// Hunk is a region of the file that has been changed in both versions, in different ways.
+type Hunk struct {
+	Base      string
+	Workspace string
+	Trunk     string
+}
+
+// chunk is a part of the merged file, it's either resolved or conflicting.
+type chunk struct {
+	resolved string
+	conflict *Hunk
+}
+
+// Conflicts returns the conflicting hunks of a merge of the workspace and the trunk versions of a file, in the order
+// they appear in the file.
+func Conflicts(base, workspace, trunk []byte) []Hunk {
+	var hunks []Hunk
+	for _, c := range merge(base, workspace, trunk) {
+		if c.conflict != nil {
+			hunks = append(hunks, *c.conflict)
+		}
+	}
+	return hunks
+}
+
+// Resolve merges the workspace and the trunk versions of a file, using one choice for each of the conflicting hunks
+// returned by Conflicts. ErrUnresolvedHunks is returned if there are fewer or more choices than hunks.
+func Resolve(base, workspace, trunk []byte, choices []Choice) ([]byte, error) {
+	for _, choice := range choices {
+		if !choice.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownChoice, choice)
+		}
+	}
+
+	chunks := merge(base, workspace, trunk)
+
+	var result strings.Builder
+	var conflict int
+	for _, c := range chunks {
+		if c.conflict == nil {
+			result.WriteString(c.resolved)
+			continue
+		}
+
+		if conflict >= len(choices) {
+			return nil, ErrUnresolvedHunks
+		}
+
+		switch choices[conflict] {
+		case ChoiceWorkspace:
+			result.WriteString(c.conflict.Workspace)
+		case ChoiceTrunk:
+			result.WriteString(c.conflict.Trunk)
+		case ChoiceBoth:
+			result.WriteString(c.conflict.Workspace)
+			result.WriteString(c.conflict.Trunk)
+		}
+		conflict++
+	}
+
+	if conflict != len(choices) {
+		return nil, ErrUnresolvedHunks
+	}
+
+	return []byte(result.String()), nil
+}
+
+// change is a non-equal opcode, a replacement of base[baseStart:baseEnd] with version[start:end].
+type change struct {
+	baseStart, baseEnd int
+	start, end         int
+}
+
+func changes(base, version []string) []change {
+	var cc []change
+	for _, op := range difflib.NewMatcherWithJunk(base, version, false, nil).GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		cc = append(cc, change{baseStart: op.I1, baseEnd: op.I2, start: op.J1, end: op.J2})
+	}
+	return cc
+}
+
+func merge(baseContents, workspaceContents, trunkContents []byte) []chunk {
+	base, workspace, trunk := lines(baseContents), lines(workspaceContents), lines(trunkContents)
+	workspaceChanges, trunkChanges := changes(base, workspace), changes(base, trunk)
+
+	var chunks []chunk
+	var pos int                        // position in base
+	var workspaceDelta, trunkDelta int // offset between base and the versions, at pos
+	var nextWorkspace, nextTrunk int   // index of the next change to process
+	for nextWorkspace < len(workspaceChanges) || nextTrunk < len(trunkChanges) {
+		workspaceFrom, trunkFrom := nextWorkspace, nextTrunk
+
+		// start with the first change in either version
+		var start, end int
+		if nextTrunk >= len(trunkChanges) || (nextWorkspace < len(workspaceChanges) && workspaceChanges[nextWorkspace].baseStart <= trunkChanges[nextTrunk].baseStart) {
+			start, end = workspaceChanges[nextWorkspace].baseStart, workspaceChanges[nextWorkspace].baseEnd
+			nextWorkspace++
+		} else {
+			start, end = trunkChanges[nextTrunk].baseStart, trunkChanges[nextTrunk].baseEnd
+			nextTrunk++
+		}
+
+		// and extend it with all of the changes that overlap with it, or are adjacent to it
+		for {
+			if nextWorkspace < len(workspaceChanges) && workspaceChanges[nextWorkspace].baseStart <= end {
+				end = max(end, workspaceChanges[nextWorkspace].baseEnd)
+				nextWorkspace++
+			} else if nextTrunk < len(trunkChanges) && trunkChanges[nextTrunk].baseStart <= end {
+				end = max(end, trunkChanges[nextTrunk].baseEnd)
+				nextTrunk++
+			} else {
+				break
+			}
+		}
+
+		// unchanged lines before the changes
+		chunks = append(chunks, chunk{resolved: join(base[pos:start])})
+
+		workspaceRegion, workspaceRegionDelta := region(workspace, workspaceChanges[workspaceFrom:nextWorkspace], start, end, workspaceDelta)
+		trunkRegion, trunkRegionDelta := region(trunk, trunkChanges[trunkFrom:nextTrunk], start, end, trunkDelta)
+
+		switch {
+		case workspaceFrom == nextWorkspace:
+			// only changed on the trunk
+			chunks = append(chunks, chunk{resolved: trunkRegion})
+		case trunkFrom == nextTrunk, workspaceRegion == trunkRegion:
+			// only changed in the workspace, or changed in the same way in both
+			chunks = append(chunks, chunk{resolved: workspaceRegion})
+		default:
+			chunks = append(chunks, chunk{conflict: &Hunk{
+				Base:      join(base[start:end]),
+				Workspace: workspaceRegion,
+				Trunk:     trunkRegion,
+			}})
+		}
+
+		pos = end
+		workspaceDelta += workspaceRegionDelta
+		trunkDelta += trunkRegionDelta
+	}
+
+	// unchanged lines after the last change
+	chunks = append(chunks, chunk{resolved: join(base[pos:])})
+
+	return chunks
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// region returns the contents of version that replaces base[start:end], and how much longer it is than the base.
+func region(version []string, cc []change, start, end, delta int) (string, int) {
+	var regionDelta int
+	for _, c := range cc {
+		regionDelta += (c.end - c.start) - (c.baseEnd - c.baseStart)
+	}
+	return join(version[start+delta : end+delta+regionDelta]), regionDelta
+}
+
+// lines splits contents into lines, keeping the line endings.
+func lines(contents []byte) []string {
+	if len(contents) == 0 {
+		return nil
+	}
+	ll := strings.SplitAfter(string(contents), "\n")
+	if ll[len(ll)-1] == "" {
+		ll = ll[:len(ll)-1]
+	}
+	return ll
+}
+
+func join(lines []string) string {
+	return strings.Join(lines, "")
+}
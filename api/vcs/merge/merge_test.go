@@ -0,0 +1,129 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflicts(t *testing.T) {
+	cases := []struct {
+		name      string
+		base      string
+		workspace string
+		trunk     string
+		expected  []Hunk
+	}{
+		{
+			name:      "no conflicts",
+			base:      "a\nb\nc\nd\ne\n",
+			workspace: "A\nb\nc\nd\ne\n",
+			trunk:     "a\nb\nc\nd\nE\n",
+		},
+		{
+			name:      "same change",
+			base:      "a\nb\nc\n",
+			workspace: "a\nB\nc\n",
+			trunk:     "a\nB\nc\n",
+		},
+		{
+			name:      "one conflict",
+			base:      "a\nb\nc\n",
+			workspace: "a\nworkspace\nc\n",
+			trunk:     "a\ntrunk\nc\n",
+			expected:  []Hunk{{Base: "b\n", Workspace: "workspace\n", Trunk: "trunk\n"}},
+		},
+		{
+			name:      "two conflicts",
+			base:      "a\nb\nc\nd\ne\n",
+			workspace: "workspace\nb\nc\nd\nworkspace\n",
+			trunk:     "trunk\nb\nc\nd\ntrunk\n",
+			expected: []Hunk{
+				{Base: "a\n", Workspace: "workspace\n", Trunk: "trunk\n"},
+				{Base: "e\n", Workspace: "workspace\n", Trunk: "trunk\n"},
+			},
+		},
+		{
+			name:      "both added",
+			base:      "",
+			workspace: "workspace\n",
+			trunk:     "trunk\n",
+			expected:  []Hunk{{Base: "", Workspace: "workspace\n", Trunk: "trunk\n"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Conflicts([]byte(tc.base), []byte(tc.workspace), []byte(tc.trunk)))
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base := "a\nb\nc\nd\ne\nf\ng\n"
+	workspace := "a\nworkspace\nc\nd\ne\nworkspace\ng\nh\n"
+	trunk := "A\ntrunk\nc\nd\ne\ntrunk\ng\n"
+
+	cases := []struct {
+		name     string
+		choices  []Choice
+		expected string
+	}{
+		{
+			name:     "workspace",
+			choices:  []Choice{ChoiceWorkspace, ChoiceWorkspace},
+			expected: "a\nworkspace\nc\nd\ne\nworkspace\ng\nh\n",
+		},
+		{
+			name:     "trunk",
+			choices:  []Choice{ChoiceTrunk, ChoiceTrunk},
+			expected: "A\ntrunk\nc\nd\ne\ntrunk\ng\nh\n",
+		},
+		{
+			name:     "mixed",
+			choices:  []Choice{ChoiceTrunk, ChoiceBoth},
+			expected: "A\ntrunk\nc\nd\ne\nworkspace\ntrunk\ng\nh\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Resolve([]byte(base), []byte(workspace), []byte(trunk), tc.choices)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestResolve_wrongNumberOfChoices(t *testing.T) {
+	_, err := Resolve([]byte("a\n"), []byte("b\n"), []byte("c\n"), nil)
+	assert.ErrorIs(t, err, ErrUnresolvedHunks)
+
+	_, err = Resolve([]byte("a\n"), []byte("b\n"), []byte("c\n"), []Choice{ChoiceWorkspace, ChoiceWorkspace})
+	assert.ErrorIs(t, err, ErrUnresolvedHunks)
+}
+
+func TestResolve_unknownChoice(t *testing.T) {
+	_, err := Resolve([]byte("a\n"), []byte("b\n"), []byte("c\n"), []Choice{"ours"})
+	assert.ErrorIs(t, err, ErrUnknownChoice)
+}
+
+// TestResolve_sides pins which version is used by each choice, the workspace version is the one passed first.
+func TestResolve_sides(t *testing.T) {
+	base, workspace, trunk := []byte("a\n"), []byte("workspace\n"), []byte("trunk\n")
+
+	hunks := Conflicts(base, workspace, trunk)
+	assert.Equal(t, []Hunk{{Base: "a\n", Workspace: "workspace\n", Trunk: "trunk\n"}}, hunks)
+
+	result, err := Resolve(base, workspace, trunk, []Choice{ChoiceWorkspace})
+	assert.NoError(t, err)
+	assert.Equal(t, "workspace\n", string(result))
+
+	result, err = Resolve(base, workspace, trunk, []Choice{ChoiceTrunk})
+	assert.NoError(t, err)
+	assert.Equal(t, "trunk\n", string(result))
+
+	result, err = Resolve(base, workspace, trunk, []Choice{ChoiceBoth})
+	assert.NoError(t, err)
+	assert.Equal(t, "workspace\ntrunk\n", string(result))
+}
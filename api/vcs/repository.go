@@ -33,6 +33,7 @@ type RepoGitReader interface {
 	Branches() ([]string, error)
 
 	BranchCommitID(branchName string) (string, error)
+	References(prefix string) (map[string]string, error)
 
 	GetCommitParents(commitID string) ([]string, error)
 	CommitMessage(id string) (author *git.Signature, message string, err error)
@@ -44,6 +45,7 @@ type RepoGitReader interface {
 	FileContentsAtCommit(commitID, filePath string) ([]byte, error)
 	FileBlobAtCommit(commitID, filePath string) (*git.Blob, error)
 	DirectoryChildrenAtCommit(commitID, directoryPath string) ([]string, error)
+	BlameFile(commitID, filePath string) ([]BlameHunk, error)
 
 	LogHead(limit int) ([]*LogEntry, error)
 	LogBranch(branchName string, limit int) ([]*LogEntry, error)
@@ -64,6 +66,11 @@ type RepoGitWriter interface {
 	CreateNewBranchOnHEAD(name string) error
 	CreateNewBranchAt(name string, targetSha string) error
 	CreateNewCommitBasedOnCommit(newBranchName string, existingCommitID string, signature git.Signature, message string) (string, error)
+	CreateNewCommitBasedOnCommitWithParent(newBranchName string, existingCommitID, parentCommitID string, signature git.Signature, message string) (string, error)
+
+	CreateRef(name, commitSha string) error
+	CreateSymbolicRef(name, target string) error
+	DeleteRef(name string) error
 
 	CleanStaged() error
 	Push(logger *zap.Logger, branchName string) error
@@ -142,5 +149,4 @@ type RepoWriter interface {
 	ResetHard(commitID string) error
 
 	AddNamedRemote(name, url string) error
-	CreateRef(name, commitSha string) error
 }
@@ -13,6 +13,7 @@ import (
 	"getsturdy.com/client/pkg/api"
 
 	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/term"
 )
 
 func auth(conf *config.Config, configPath string) {
@@ -30,13 +31,53 @@ func auth(conf *config.Config, configPath string) {
 		os.Exit(1)
 	}
 
-	err = config.SetAuth(configPath, code)
+	saveAuth(conf, configPath, code)
+}
+
+// authPassword authenticates with an email and a password, instead of a token from the browser. If the user has
+// two-factor authentication enabled, they are asked for a code as well.
+func authPassword(conf *config.Config, configPath, email string) {
+	fmt.Print("🔑 Password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		fmt.Println("Failed read the password. Please try running this command again.")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	res, err := api.Login(conf.APIRemote, email, string(password))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	token := res.Token
+	if res.TwoFactorToken != "" {
+		fmt.Print("📱 Enter the code from your authenticator app, or one of your recovery codes, and press enter")
+		token, err = readUntilValidTwoFactorCode(os.Stdin, func(code string) (string, error) {
+			return api.LoginTwoFactor(conf.APIRemote, res.TwoFactorToken, code)
+		})
+		if errors.Is(err, io.EOF) {
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	saveAuth(conf, configPath, token)
+}
+
+func saveAuth(conf *config.Config, configPath, token string) {
+	err := config.SetAuth(configPath, token)
 	if err != nil {
 		fmt.Println("Failed to update config")
 		fmt.Println(err)
 		return
 	}
-	conf.Auth = code
+	conf.Auth = token
 
 	// Create a new API client
 	apiClient := api.NewHttpApiClient(conf)
@@ -83,6 +124,45 @@ func readUntilValidTokenInput(conf *config.Config, termReadWriter io.ReadWriter,
 	return "", errOutOfTries
 }
 
+type twoFactorLoginFunc func(code string) (string, error)
+
+// readUntilValidTwoFactorCode reads codes until loginFunc accepts one, and returns the token that it returned.
+func readUntilValidTwoFactorCode(termReader io.Reader, loginFunc twoFactorLoginFunc) (string, error) {
+	fmt.Println()
+
+	reader := bufio.NewReader(termReader)
+	for attempt := 0; attempt < 5; attempt++ {
+		fmt.Print(" > ")
+		codeBytes, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return "", err
+		}
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("❌ Something went wrong reading the input. Please try again.")
+			continue
+		}
+
+		code := strings.TrimSpace(codeBytes)
+		if code == "" {
+			continue
+		}
+
+		token, err := loginFunc(code)
+		if errors.Is(err, api.ErrInvalidTwoFactorCode) {
+			fmt.Println("❌ Invalid code. Please try again.")
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return token, nil
+	}
+
+	return "", errOutOfTries
+}
+
 var errOutOfTries = fmt.Errorf("❌ Maximum attempts reached, aborting!")
 
 type validateTokenFunc func(conf *config.Config, checkToken string) error
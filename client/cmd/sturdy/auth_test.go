@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"getsturdy.com/client/cmd/sturdy/config"
+	"getsturdy.com/client/pkg/api"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -77,6 +78,57 @@ func TestReadUntilValidTokenInput(t *testing.T) {
 	}
 }
 
+func TestReadUntilValidTwoFactorCode(t *testing.T) {
+	loginFunc := func(code string) (string, error) {
+		if code == "123456" {
+			return "token", nil
+		}
+		return "", api.ErrInvalidTwoFactorCode
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		loginFunc twoFactorLoginFunc
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "valid on first try",
+			input:     "123456\n",
+			loginFunc: loginFunc,
+			want:      "token",
+		},
+		{
+			name:      "valid on second try",
+			input:     "000000\r\n123456\r\n",
+			loginFunc: loginFunc,
+			want:      "token",
+		},
+		{
+			name:      "out of attempts",
+			input:     "1\n2\n3\n4\n5\n123456\n",
+			loginFunc: loginFunc,
+			wantErr:   errOutOfTries,
+		},
+		{
+			name:  "other errors are not retried",
+			input: "123456\n123456\n",
+			loginFunc: func(code string) (string, error) {
+				return "", io.ErrUnexpectedEOF
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readUntilValidTwoFactorCode(bytes.NewBufferString(tt.input), tt.loginFunc)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 type scheduledReader struct {
 	i    int
 	strs []string
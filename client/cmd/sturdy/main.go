@@ -22,7 +22,7 @@ func printHelpAndExit() {
 	fmt.Println("  stop     Stop all connections and stop the daemon")
 	fmt.Println("  restart  Restart and re-configure all connections")
 	fmt.Println("  status   Get the current status of each codebase")
-	fmt.Println("  auth     Authenticate yourself with Sturdy")
+	fmt.Println("  auth     Authenticate yourself with Sturdy, run 'sturdy auth <email>' to log in with a password")
 	fmt.Println("  init     Configure a new codebase to be used from this computer")
 	fmt.Println("  import   Import a Git repository to Sturdy")
 	fmt.Println("  version  Display Sturdy version information")
@@ -77,7 +77,11 @@ func main() {
 	switch os.Args[1] {
 	case "auth":
 		// It's important to not attempt to require auth, or renew auth _before_ calling auth()
-		auth(conf, *configPath)
+		if len(args) == 1 {
+			authPassword(conf, *configPath, args[0])
+		} else {
+			auth(conf, *configPath)
+		}
 	case "status":
 		status(conf)
 	case "init":
@@ -48,6 +48,9 @@ func startMutagen(dotSturdyConfigPath string, conf *config.Config, apiClient api
 		log.Fatalf("failed to get user: %s", err)
 	}
 
+	// sturdy-sync reports the status of the views on behalf of the user
+	mutagen.SetAuthToken(conf.Auth)
+
 	authorizedKey, privateKeyPath, err := generateKey(mutagenAgentDirPath, user.ID)
 	if err != nil {
 		log.Fatalf("failed to generate keypair: %s", err)
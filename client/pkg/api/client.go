@@ -52,3 +52,66 @@ func Request(host, method, path, authToken string, request, response interface{}
 	}
 	return nil
 }
+
+// ResponseError is returned by RequestAuthCookie when the server responds with an error.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected response code %d", e.StatusCode)
+	}
+	return e.Message
+}
+
+// RequestAuthCookie makes an unauthenticated POST request, and returns the auth cookie that the server set in the
+// response, if any.
+func RequestAuthCookie(host, path string, request, response interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", host+path, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-Name", "sturdy-cli")
+	req.Header.Set("X-Client-Version", version.Version)
+
+	client := http.DefaultClient
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respContent, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		var errResponse struct {
+			Error string `json:"error"`
+		}
+		// the body is not always json, fallback to only the status code
+		_ = json.Unmarshal(respContent, &errResponse)
+		return "", &ResponseError{StatusCode: resp.StatusCode, Message: errResponse.Error}
+	}
+
+	if err := json.Unmarshal(respContent, response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "auth" {
+			return cookie.Value, nil
+		}
+	}
+	return "", nil
+}
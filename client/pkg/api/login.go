@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+type LoginResponse struct {
+	// Token is set if the login is completed
+	Token string
+	// TwoFactorToken is set if the user has two-factor authentication enabled. The login is completed with
+	// LoginTwoFactor.
+	TwoFactorToken string
+}
+
+// Login logs in with an email and a password.
+func Login(host, email, password string) (LoginResponse, error) {
+	req := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}{
+		Email:    email,
+		Password: password,
+	}
+
+	var res struct {
+		TwoFactorRequired bool   `json:"two_factor_required"`
+		TwoFactorToken    string `json:"two_factor_token"`
+	}
+
+	token, err := RequestAuthCookie(host, "/v3/auth", req, &res)
+	if err != nil {
+		return LoginResponse{}, fmt.Errorf("failed to log in: %w", err)
+	}
+
+	if res.TwoFactorRequired {
+		return LoginResponse{TwoFactorToken: res.TwoFactorToken}, nil
+	}
+	if token == "" {
+		return LoginResponse{}, fmt.Errorf("failed to log in: no token in response")
+	}
+	return LoginResponse{Token: token}, nil
+}
+
+// LoginTwoFactor completes a login with a code from an authenticator app, or a recovery code.
+func LoginTwoFactor(host, twoFactorToken, code string) (string, error) {
+	req := struct {
+		TwoFactorToken string `json:"two_factor_token"`
+		Code           string `json:"code"`
+	}{
+		TwoFactorToken: twoFactorToken,
+		Code:           code,
+	}
+
+	var res struct{}
+
+	token, err := RequestAuthCookie(host, "/v3/auth/two-factor", req, &res)
+	var respErr *ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusBadRequest {
+		return "", ErrInvalidTwoFactorCode
+	} else if err != nil {
+		return "", fmt.Errorf("failed to log in: %w", err)
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("failed to log in: no token in response")
+	}
+	return token, nil
+}
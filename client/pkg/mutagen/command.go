@@ -0,0 +1,25 @@
+package mutagen
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// authTokenEnv is read by the sturdy-sync daemon, which authenticates its status updates to the API as the user.
+const authTokenEnv = "STURDY_AUTH_TOKEN"
+
+var authToken string
+
+// SetAuthToken sets the token that is passed on to sturdy-sync. The daemon picks up the token when it's started.
+func SetAuthToken(token string) {
+	authToken = token
+}
+
+func command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sturdy-sync", args...)
+	if authToken != "" {
+		cmd.Env = append(os.Environ(), authTokenEnv+"="+authToken)
+	}
+	return cmd
+}
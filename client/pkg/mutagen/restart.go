@@ -5,14 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os/exec"
 	"time"
 )
 
 func RestartDaemon() error {
 	stopCtx, stopCancelFunc := context.WithTimeout(context.Background(), time.Second*10)
 	defer stopCancelFunc()
-	stopOutput, err := exec.CommandContext(stopCtx, "sturdy-sync", "daemon", "stop").CombinedOutput()
+	stopOutput, err := command(stopCtx, "daemon", "stop").CombinedOutput()
 
 	if errors.Is(stopCtx.Err(), context.DeadlineExceeded) {
 		log.Println("Timeout exceeded, trying to restart...")
@@ -23,7 +22,7 @@ func RestartDaemon() error {
 
 	startCtx, startCancelFunc := context.WithTimeout(context.Background(), time.Second*10)
 	defer startCancelFunc()
-	startOutput, err := exec.CommandContext(startCtx, "sturdy-sync", "daemon", "start").CombinedOutput()
+	startOutput, err := command(startCtx, "daemon", "start").CombinedOutput()
 
 	if errors.Is(startCtx.Err(), context.DeadlineExceeded) {
 		log.Println("Timeout exceeded, was not able to start the daemon")
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os/exec"
 	"time"
 )
 
@@ -14,7 +13,7 @@ func RunMutagenCommandWithRestart(args ...string) ([]byte, error) {
 	firstExecCtx, firstExecCancelFunc := context.WithTimeout(context.Background(), time.Second*10)
 	defer firstExecCancelFunc()
 
-	firstOutput, err := exec.CommandContext(firstExecCtx, "sturdy-sync", args...).CombinedOutput()
+	firstOutput, err := command(firstExecCtx, args...).CombinedOutput()
 
 	if errors.Is(firstExecCtx.Err(), context.DeadlineExceeded) {
 		log.Println("Command was too slow, trying again...")
@@ -32,7 +31,7 @@ func RunMutagenCommandWithRestart(args ...string) ([]byte, error) {
 	// Try again
 	secondExecCtx, secondExecCancelFunc := context.WithTimeout(context.Background(), time.Second*10)
 	defer secondExecCancelFunc()
-	secondOutput, err := exec.CommandContext(secondExecCtx, "sturdy-sync", args...).CombinedOutput()
+	secondOutput, err := command(secondExecCtx, args...).CombinedOutput()
 
 	if errors.Is(secondExecCtx.Err(), context.DeadlineExceeded) {
 		log.Println("Command was too slow again, giving up...")
@@ -9,6 +9,8 @@ log() {
 SSH_KEYS_DIR="/var/data/ssh/keys"
 SSH_KEY_NAME="ed25519"
 SSH_KEY_PATH="${SSH_KEYS_DIR}/${SSH_KEY_NAME}"
+GIT_SECRET_PATH="/var/data/ssh/git-secret"
+MUTAGEN_SECRET_PATH="/var/data/ssh/mutagen-secret"
 
 generate_keys() {
 	mkdir -p "${SSH_KEYS_DIR}"
@@ -19,4 +21,22 @@ generate_keys() {
 	fi
 }
 
+generate_git_secret() {
+	if [[ ! -f ${GIT_SECRET_PATH} ]]; then
+		log "Generating git over SSH secret"
+		head -c 32 /dev/urandom | od -An -tx1 | tr -d ' \n' >"${GIT_SECRET_PATH}"
+		chmod 600 "${GIT_SECRET_PATH}"
+	fi
+}
+
+generate_mutagen_secret() {
+	if [[ ! -f ${MUTAGEN_SECRET_PATH} ]]; then
+		log "Generating mutagen secret"
+		head -c 32 /dev/urandom | od -An -tx1 | tr -d ' \n' >"${MUTAGEN_SECRET_PATH}"
+		chmod 600 "${MUTAGEN_SECRET_PATH}"
+	fi
+}
+
 generate_keys
+generate_git_secret
+generate_mutagen_secret
@@ -18,6 +18,9 @@ import (
 func main() {
 	sshListenAddr := flag.String("ssh-listen-addr", "127.0.0.1:2222", "")
 	sturdyApiAddr := flag.String("sturdy-api-addr", "http://host.docker.internal:3000", "")
+	sturdyGitAddr := flag.String("sturdy-git-addr", "", "address of the API gitserver, git over ssh is disabled if empty")
+	sturdyGitSecret := flag.String("sturdy-git-secret", "", "secret shared with the API gitserver, used to sign git over ssh requests")
+	sturdyApiSecret := flag.String("sturdy-api-secret", "", "secret shared with the API, used to sign requests of mutagen agents on behalf of the user")
 	mutagenAgentBinaryDir := flag.String("mutagen-agent-binary-dir", "/usr/bin/", "")
 	keyHostPath := flag.String("ssh-key-path", "id_ed25519", "")
 	httpPprofListenAddr := flag.String("http-pprof-listen-addr", "127.0.0.1:6060", "")
@@ -30,6 +33,9 @@ func main() {
 		KeyHostPath:           *keyHostPath,
 		MutagenAgentBinaryDir: *mutagenAgentBinaryDir,
 		SturdyApiAddr:         *sturdyApiAddr,
+		SturdyGitAddr:         *sturdyGitAddr,
+		SturdyGitSecret:       *sturdyGitSecret,
+		SturdyApiSecret:       *sturdyApiSecret,
 	})
 
 	// Pprof server
@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"go.uber.org/zap"
+)
+
+// git-upload-pack and git-receive-pack sessions are forwarded to the gitserver of the API as upgraded http
+// connections. The requests are signed with a secret shared with the API, on behalf of the authenticated user.
+const gitUpgradeProtocol = "git"
+
+func isGitCommand(command []string) bool {
+	if len(command) != 2 {
+		return false
+	}
+	return command[0] == "git-upload-pack" || command[0] == "git-receive-pack"
+}
+
+// gitCodebaseID returns the codebase id from the repository path given by the git client. All of "<id>", "/<id>",
+// "<id>.git" and "/<id>.git/" are accepted.
+func gitCodebaseID(repoPath string) string {
+	return strings.TrimSuffix(path.Base(path.Clean("/"+repoPath)), ".git")
+}
+
+func (srv *Server) sshGitHandler(logger *zap.Logger, s ssh.Session) {
+	if srv.cfg.SturdyGitAddr == "" || srv.cfg.SturdyGitSecret == "" {
+		fmt.Fprintln(s.Stderr(), "git over ssh is not enabled on this server")
+		_ = s.Exit(1)
+		return
+	}
+
+	service, codebaseID := s.Command()[0], gitCodebaseID(s.Command()[1])
+	logger = logger.With(zap.String("codebase_id", codebaseID))
+
+	t0 := time.Now()
+
+	conn, err := srv.dialGit(s.User(), codebaseID, service)
+	if err != nil {
+		logger.Warn("failed to connect to gitserver", zap.Error(err))
+		switch {
+		case errors.Is(err, errGitNotFound):
+			fmt.Fprintf(s.Stderr(), "sturdy: codebase %s not found\n", codebaseID)
+		case errors.Is(err, errGitForbidden):
+			fmt.Fprintf(s.Stderr(), "sturdy: you don't have access to %s\n", codebaseID)
+		default:
+			fmt.Fprintln(s.Stderr(), "sturdy: internal error")
+		}
+		_ = s.Exit(1)
+		return
+	}
+	defer conn.Close()
+
+	// stdin
+	go func() {
+		if _, err := io.Copy(conn, s); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) && !errIsConnectionClosed(err) {
+			logger.Error("stdin copy failed", zap.Error(err))
+		}
+	}()
+
+	// stdout, the gitserver closes the connection once git is done
+	if _, err := io.Copy(s, conn); err != nil && !errIsConnectionClosed(err) {
+		logger.Error("stdout copy failed", zap.Error(err))
+		_ = s.Exit(1)
+		return
+	}
+
+	logger.Info("git session done", zap.Duration("duration", time.Since(t0)))
+
+	_ = s.Exit(0)
+}
+
+var (
+	errGitNotFound  = errors.New("not found")
+	errGitForbidden = errors.New("forbidden")
+)
+
+// dialGit opens a connection to the gitserver, running the git service on behalf of the user.
+func (srv *Server) dialGit(userID, codebaseID, service string) (io.ReadWriteCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/ssh/%s/%s", srv.cfg.SturdyGitAddr, codebaseID, service), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", gitUpgradeProtocol)
+	if err := signRequest(req, srv.cfg.SturdyGitSecret, userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusSwitchingProtocols:
+		return resp.Body.(io.ReadWriteCloser), nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errGitNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		resp.Body.Close()
+		return nil, errGitForbidden
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,108 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxProxyBodySize is the largest request body that an agent can send to the API
+const maxProxyBodySize = 32 << 20
+
+// apiProxy forwards the requests of a mutagen agent to the API, signed on behalf of the user that the ssh session is
+// authenticated as. The secret that is shared with the API never leaves the ssh server, the agent only gets the
+// address of the proxy.
+//
+// The proxy listens on loopback for as long as the session is open. The address contains a random prefix, so that
+// other processes on the host can't make requests as the user, and only the mutagen routes of the API are forwarded.
+type apiProxy struct {
+	listener net.Listener
+	server   *http.Server
+	prefix   string
+}
+
+func (srv *Server) startAPIProxy(logger *zap.Logger, userID string) (*apiProxy, error) {
+	target, err := url.Parse(srv.cfg.SturdyApiAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api address: %w", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate prefix: %w", err)
+	}
+	prefix := "/" + hex.EncodeToString(random)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	reverseProxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.Host = target.Host
+			req.URL.Path = strings.TrimSuffix(target.Path, "/") + strings.TrimPrefix(req.URL.Path, prefix)
+			req.URL.RawPath = ""
+			// signRequest can't fail here, the body has already been read into memory by the handler
+			_ = signRequest(req, srv.cfg.SturdyApiSecret, userID, time.Now())
+		},
+		ErrorLog: zap.NewStdLog(logger),
+	}
+
+	p := &apiProxy{
+		listener: listener,
+		prefix:   prefix,
+		server: &http.Server{
+			ReadHeaderTimeout: 10 * time.Second,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.URL.Path, prefix+"/v3/mutagen/") {
+					http.NotFound(w, r)
+					return
+				}
+				for _, header := range []string{userIDHeader, timestampHeader, signatureHeader} {
+					r.Header.Del(header)
+				}
+				body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxProxyBodySize))
+				if err != nil {
+					http.Error(w, "failed to read body", http.StatusBadRequest)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				reverseProxy.ServeHTTP(w, r)
+			}),
+		},
+	}
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("api proxy failed", zap.Error(err))
+		}
+	}()
+
+	return p, nil
+}
+
+// Addr is the address that the agent makes its requests to, instead of the address of the API.
+func (p *apiProxy) Addr() string {
+	return "http://" + p.listener.Addr().String() + p.prefix
+}
+
+func (p *apiProxy) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
@@ -0,0 +1,47 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Requests to the API are signed with a secret shared with the API, on behalf of the authenticated user. The
+// signature covers the method, the path and query, and the body of the request.
+const (
+	userIDHeader    = "X-Sturdy-User-Id"
+	timestampHeader = "X-Sturdy-Timestamp"
+	signatureHeader = "X-Sturdy-Signature"
+)
+
+// signRequest signs the request on behalf of userID. The body of the request is read, and replaced.
+func signRequest(req *http.Request, secret, userID string, now time.Time) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", userID, timestamp, req.Method, req.URL.RequestURI(), hex.EncodeToString(bodyHash[:]))
+
+	req.Header.Set(userIDHeader, userID)
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
@@ -24,6 +24,9 @@ type Config struct {
 	SturdyApiAddr         string
 	KeyHostPath           string
 	MutagenAgentBinaryDir string
+	SturdyGitAddr         string
+	SturdyGitSecret       string
+	SturdyApiSecret       string
 }
 
 type Server struct {
@@ -103,9 +106,11 @@ func (srv *Server) sshHandler(s ssh.Session) {
 		zap.String("connection_id", uuid.NewString()),
 	)
 
-	switch s.User() {
-	case "ping":
+	switch {
+	case s.User() == "ping":
 		srv.sshPingHandler(logger, s)
+	case isGitCommand(s.Command()):
+		srv.sshGitHandler(logger, s)
 	default:
 		srv.sshMutagenHandler(logger, s)
 	}
@@ -143,9 +148,21 @@ func (srv *Server) sshMutagenHandler(logger *zap.Logger, s ssh.Session) {
 
 	cmd := exec.Command(binary, "synchronizer")
 
+	// The agent makes its requests to the API through a proxy, that signs them on behalf of the authenticated user
+	proxy, err := srv.startAPIProxy(logger, s.User())
+	if err != nil {
+		logger.Error("failed to start api proxy", zap.Error(err))
+		return
+	}
+	defer func() {
+		if err := proxy.Close(); err != nil {
+			logger.Error("failed to close api proxy", zap.Error(err))
+		}
+	}()
+
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, fmt.Sprintf("STURDY_AUTHENTICATED_USER_ID=%s", s.User()))
-	cmd.Env = append(cmd.Env, fmt.Sprintf("STURDY_API_ADDR=%s", srv.cfg.SturdyApiAddr))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("STURDY_API_ADDR=%s", proxy.Addr()))
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {